@@ -0,0 +1,80 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLauncher_CompositeCheckPreview checks that a composite check gets a
+// single status per group, even when each of its queries returns several
+// series per group.
+func TestLauncher_CompositeCheckPreview(t *testing.T) {
+	l := launcher.RunAndSetupNewLauncherOrFail(ctx, t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	ts := start.Add(30 * time.Second).UnixNano()
+	l.WritePointsOrFail(t, fmt.Sprintf(`cpu,host=A,core=0 usage=95 %[1]d
+cpu,host=A,core=1 usage=97 %[1]d
+mem,host=A,node=0 used=85 %[1]d
+mem,host=A,node=1 used=90 %[1]d`, ts))
+
+	every, err := notification.FromTimeDuration(time.Minute)
+	require.NoError(t, err)
+	query := func(measurement string) influxdb.DashboardQuery {
+		return influxdb.DashboardQuery{
+			Text: fmt.Sprintf(`from(bucket: %q) |> range(start: -1m) |> filter(fn: (r) => r._measurement == %q)`, l.Bucket.Name, measurement),
+		}
+	}
+	chk := &check.Composite{
+		Base: check.Base{
+			Name:                  "cpu and mem",
+			OrgID:                 l.Org.ID,
+			Every:                 &every,
+			StatusMessageTemplate: "busy",
+		},
+		Queries: []check.CompositeQuery{
+			{Name: "cpu", Query: query("cpu")},
+			{Name: "mem", Query: query("mem")},
+		},
+		GroupBy:   []string{"host"},
+		Condition: "cpu > 90.0 and mem > 80.0",
+		Level:     notification.Critical,
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"check": chk,
+		"start": start,
+		"stop":  start.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	req := l.NewHTTPRequestOrFail(t, "POST", "/api/v2/previews/checks", l.Auth.Token, string(body))
+	resp, err := nethttp.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	var got struct {
+		Evaluations int                      `json:"evaluations"`
+		Statuses    []map[string]interface{} `json:"statuses"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+	// Both series of each query are reduced to a single row of host A
+	// before the queries are joined, instead of joining every pair of them.
+	assert.Equal(t, 1, got.Evaluations)
+	require.Len(t, got.Statuses, 1)
+	assert.Equal(t, "A", got.Statuses[0]["host"])
+	assert.Equal(t, "crit", got.Statuses[0]["_level"])
+}
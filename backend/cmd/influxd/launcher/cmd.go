@@ -18,6 +18,8 @@ import (
 	"github.com/influxdata/influxdb/v2/sqlite"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/graphite"
+	"github.com/influxdata/influxdb/v2/v1/services/opentsdb"
 	"github.com/influxdata/influxdb/v2/vault"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -191,6 +193,10 @@ type InfluxdOpts struct {
 	// Storage options.
 	StorageConfig storage.Config
 
+	// Legacy input options.
+	GraphiteConfig graphite.Config
+	OpenTSDBConfig opentsdb.Config
+
 	Viper *viper.Viper
 
 	// HardeningEnabled toggles multiple best-practice hardening options on.
@@ -211,6 +217,8 @@ func NewOpts(viper *viper.Viper) *InfluxdOpts {
 		Viper:             viper,
 		StorageConfig:     storage.NewConfig(),
 		CoordinatorConfig: coordinator.NewConfig(),
+		GraphiteConfig:    graphite.NewConfig(),
+		OpenTSDBConfig:    opentsdb.NewConfig(),
 
 		LogLevel:          zapcore.InfoLevel,
 		FluxLogEnabled:    false,
@@ -505,7 +513,7 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			DestP:   &o.InstanceID,
 			Flag:    "instance-id",
 			Default: "",
-			Desc:    "add an instance id for replications to prevent collisions and allow querying by edge node",
+			Desc:    "add an instance id for replications to prevent collisions, allow querying by edge node, and forward replicated writes without looping",
 		},
 
 		// storage configuration
@@ -597,6 +605,17 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Flag:  "storage-tsm-use-madv-willneed",
 			Desc:  "Controls whether we hint to the kernel that we intend to page in mmap'd sections of TSM files.",
 		},
+		{
+			DestP:   &o.StorageConfig.Data.TSMStringCodec,
+			Flag:    "storage-tsm-string-codec",
+			Default: o.StorageConfig.Data.TSMStringCodec,
+			Desc:    "The codec used to compress string blocks in TSM files. One of snappy, zstd or dictionary. Existing blocks are transcoded during full compactions.",
+		},
+		{
+			DestP: &o.StorageConfig.Data.TSMBucketStringCodecs,
+			Flag:  "storage-tsm-bucket-string-codecs",
+			Desc:  "Per-bucket overrides of storage-tsm-string-codec, given as comma-separated bucket ID=codec pairs with codecs snappy, zstd or dictionary, e.g. 0123456789abcdef=zstd,fedcba9876543210=dictionary.",
+		},
 		{
 			DestP: &o.StorageConfig.RetentionService.CheckInterval,
 			Flag:  "storage-retention-check-interval",
@@ -630,6 +649,106 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Desc:  "The maximum number of group by time bucket a SELECT can create. A value of zero will max the maximum number of buckets unlimited.",
 		},
 
+		// Graphite input config
+		{
+			DestP:   &o.GraphiteConfig.Enabled,
+			Flag:    "graphite-enabled",
+			Default: o.GraphiteConfig.Enabled,
+			Desc:    "Enable the Graphite plaintext listener",
+		},
+		{
+			DestP:   &o.GraphiteConfig.BindAddress,
+			Flag:    "graphite-bind-address",
+			Default: o.GraphiteConfig.BindAddress,
+			Desc:    "The address the Graphite listener binds to",
+		},
+		{
+			DestP:   &o.GraphiteConfig.Protocol,
+			Flag:    "graphite-protocol",
+			Default: o.GraphiteConfig.Protocol,
+			Desc:    "The protocol of the Graphite listener. One of tcp or udp.",
+		},
+		{
+			DestP: &o.GraphiteConfig.BucketID,
+			Flag:  "graphite-bucket-id",
+			Desc:  "The ID of the bucket Graphite metrics are written to",
+		},
+		{
+			DestP: &o.GraphiteConfig.Templates,
+			Flag:  "graphite-templates",
+			Desc:  "Templates mapping Graphite metric names to measurements, tags and fields, in the form \"[filter] template [tag1=value1,...]\", e.g. \"servers.* .host.measurement*\"",
+		},
+		{
+			DestP: &o.GraphiteConfig.Tags,
+			Flag:  "graphite-tags",
+			Desc:  "Default tags added to all Graphite metrics, e.g. region=us-east",
+		},
+		{
+			DestP:   &o.GraphiteConfig.Separator,
+			Flag:    "graphite-separator",
+			Default: o.GraphiteConfig.Separator,
+			Desc:    "The separator used to join multiple measurement, field or tag parts of a Graphite metric name",
+		},
+		{
+			DestP:   &o.GraphiteConfig.BatchSize,
+			Flag:    "graphite-batch-size",
+			Default: o.GraphiteConfig.BatchSize,
+			Desc:    "The number of Graphite points written per batch",
+		},
+		{
+			DestP:   &o.GraphiteConfig.BatchPending,
+			Flag:    "graphite-batch-pending",
+			Default: o.GraphiteConfig.BatchPending,
+			Desc:    "The number of Graphite batches that may be pending in memory before the listener stops reading",
+		},
+		{
+			DestP: &o.GraphiteConfig.BatchTimeout,
+			Flag:  "graphite-batch-timeout",
+			Desc:  "The time after which a partial Graphite batch is written",
+		},
+		{
+			DestP:   &o.GraphiteConfig.UDPReadBuffer,
+			Flag:    "graphite-udp-read-buffer",
+			Default: o.GraphiteConfig.UDPReadBuffer,
+			Desc:    "The size of the UDP socket receive buffer. 0 uses the operating system default.",
+		},
+
+		// OpenTSDB input config
+		{
+			DestP:   &o.OpenTSDBConfig.Enabled,
+			Flag:    "opentsdb-enabled",
+			Default: o.OpenTSDBConfig.Enabled,
+			Desc:    "Enable the OpenTSDB telnet and HTTP listener",
+		},
+		{
+			DestP:   &o.OpenTSDBConfig.BindAddress,
+			Flag:    "opentsdb-bind-address",
+			Default: o.OpenTSDBConfig.BindAddress,
+			Desc:    "The address the OpenTSDB listener binds to",
+		},
+		{
+			DestP: &o.OpenTSDBConfig.BucketID,
+			Flag:  "opentsdb-bucket-id",
+			Desc:  "The ID of the bucket OpenTSDB metrics are written to",
+		},
+		{
+			DestP:   &o.OpenTSDBConfig.BatchSize,
+			Flag:    "opentsdb-batch-size",
+			Default: o.OpenTSDBConfig.BatchSize,
+			Desc:    "The number of OpenTSDB points written per batch",
+		},
+		{
+			DestP:   &o.OpenTSDBConfig.BatchPending,
+			Flag:    "opentsdb-batch-pending",
+			Default: o.OpenTSDBConfig.BatchPending,
+			Desc:    "The number of OpenTSDB batches that may be pending in memory before the listener stops reading",
+		},
+		{
+			DestP: &o.OpenTSDBConfig.BatchTimeout,
+			Flag:  "opentsdb-batch-timeout",
+			Desc:  "The time after which a partial OpenTSDB batch is written",
+		},
+
 		// NATS config
 		{
 			DestP:   &o.NatsPort,
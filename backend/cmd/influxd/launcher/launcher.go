@@ -50,7 +50,9 @@ import (
 	"github.com/influxdata/influxdb/v2/notebooks"
 	notebookTransport "github.com/influxdata/influxdb/v2/notebooks/transport"
 	endpointservice "github.com/influxdata/influxdb/v2/notification/endpoint/service"
+	"github.com/influxdata/influxdb/v2/notification/preview"
 	ruleservice "github.com/influxdata/influxdb/v2/notification/rule/service"
+	"github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/pkger"
 	infprom "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
@@ -75,6 +77,8 @@ import (
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
 	"github.com/influxdata/influxdb/v2/task/backend/middleware"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/backfill"
+	"github.com/influxdata/influxdb/v2/task/runhistory"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	telegrafservice "github.com/influxdata/influxdb/v2/telegraf/service"
 	"github.com/influxdata/influxdb/v2/telemetry"
@@ -88,7 +92,9 @@ import (
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
 	authv1 "github.com/influxdata/influxdb/v2/v1/authorization"
 	iqlcoordinator "github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/graphite"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/influxdata/influxdb/v2/v1/services/opentsdb"
 	storage2 "github.com/influxdata/influxdb/v2/v1/services/storage"
 	"github.com/influxdata/influxdb/v2/vault"
 	pzap "github.com/influxdata/influxdb/v2/zap"
@@ -374,11 +380,12 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		restoreService platform.RestoreService = m.engine
 	)
 
-	remotesSvc := remotes.NewService(m.sqlStore)
+	remotesSvc := remotes.NewService(m.sqlStore, secretSvc)
 	remotesServer := remotesTransport.NewInstrumentedRemotesHandler(
 		m.log.With(zap.String("handler", "remotes")), m.reg, m.kvStore, remotesSvc)
 
-	replicationSvc, replicationsMetrics := replications.NewService(m.sqlStore, ts, pointsWriter, m.log.With(zap.String("service", "replications")), opts.EnginePath, opts.InstanceID)
+	readsStore := storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())
+	replicationSvc, replicationsMetrics := replications.NewService(m.sqlStore, ts, pointsWriter, readsStore, secretSvc, m.log.With(zap.String("service", "replications")), opts.EnginePath, opts.InstanceID)
 	replicationServer := replicationTransport.NewInstrumentedReplicationHandler(
 		m.log.With(zap.String("handler", "replications")), m.reg, m.kvStore, replicationSvc)
 	ts.BucketService = replications.NewBucketService(
@@ -410,7 +417,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	}
 
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())),
+		storageflux.NewReader(readsStore),
 		pointsWriter,
 		authorizer.NewBucketService(ts.BucketService),
 		authorizer.NewOrgService(ts.OrganizationService),
@@ -451,8 +458,47 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
+	dbrpSvc := dbrp.NewAuthorizedService(dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore))
+
+	cm := iqlcontrol.NewControllerMetrics([]string{})
+	m.reg.MustRegister(cm.PrometheusCollectors()...)
+
+	mapper := &iqlcoordinator.LocalShardMapper{
+		MetaClient: metaClient,
+		TSDBStore:  m.engine.TSDBStore(),
+		DBRP:       dbrpSvc,
+	}
+
+	m.log.Info("Configuring InfluxQL statement executor (zeros indicate unlimited).",
+		zap.Int("max_select_point", opts.CoordinatorConfig.MaxSelectPointN),
+		zap.Int("max_select_series", opts.CoordinatorConfig.MaxSelectSeriesN),
+		zap.Int("max_select_buckets", opts.CoordinatorConfig.MaxSelectBucketsN))
+
+	qe := iqlquery.NewExecutor(m.log, cm)
+	se := &iqlcoordinator.StatementExecutor{
+		MetaClient:        metaClient,
+		TSDBStore:         m.engine.TSDBStore(),
+		ShardMapper:       mapper,
+		DBRP:              dbrpSvc,
+		MaxSelectPointN:   opts.CoordinatorConfig.MaxSelectPointN,
+		MaxSelectSeriesN:  opts.CoordinatorConfig.MaxSelectSeriesN,
+		MaxSelectBucketsN: opts.CoordinatorConfig.MaxSelectBucketsN,
+	}
+	qe.StatementExecutor = se
+	qe.StatementNormalizer = se
+
+	// SELECT INTO is only supported by InfluxQL tasks, not by the /query endpoint.
+	taskQE := iqlquery.NewExecutor(m.log, cm)
+	taskSE := *se
+	taskSE.IntoWriter = pointsWriter
+	taskQE.StatementExecutor = &taskSE
+	taskQE.StatementNormalizer = &taskSE
+
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
-	var taskSvc taskmodel.TaskService
+	var (
+		taskSvc     taskmodel.TaskService
+		backfillSvc *backfill.Service
+	)
 	{
 		// create the task stack
 		combinedTaskService := taskbackend.NewAnalyticalStorage(
@@ -471,6 +517,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			combinedTaskService,
 			combinedTaskService,
 			executor.WithFlagger(m.flagger),
+			executor.WithInfluxQLService(iqlquery.NewProxyExecutor(m.log.With(zap.String("service", "task-influxql")), taskQE)),
 		)
 		err = executor.LoadExistingScheduleRuns(ctx)
 		if err != nil {
@@ -480,7 +527,18 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
 		schLogger := m.log.With(zap.String("service", "task-scheduler"))
 
-		var sch stoppingScheduler = &scheduler.NoopScheduler{}
+		schOnErr := func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
+			schLogger.Info(
+				"error in scheduler run",
+				zap.String("taskID", platform2.ID(taskID).String()),
+				zap.Time("scheduledAt", scheduledAt),
+				zap.Error(err))
+		}
+
+		var (
+			sch       stoppingScheduler = &scheduler.NoopScheduler{}
+			coordOpts []coordinator.CoordinatorOption
+		)
 		if !opts.NoTasks {
 			var (
 				sm  *scheduler.SchedulerMetrics
@@ -489,13 +547,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			sch, sm, err = scheduler.NewScheduler(
 				executor,
 				taskbackend.NewSchedulableTaskService(m.kvService),
-				scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
-					schLogger.Info(
-						"error in scheduler run",
-						zap.String("taskID", platform2.ID(taskID).String()),
-						zap.Time("scheduledAt", scheduledAt),
-						zap.Error(err))
-				}),
+				scheduler.WithOnErrorFn(schOnErr),
 			)
 			if err != nil {
 				m.log.Fatal("could not start task scheduler", zap.Error(err))
@@ -508,6 +560,27 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 				},
 			})
 			m.reg.MustRegister(sm.PrometheusCollectors()...)
+
+			// tasks that depend on other tasks run when their upstream tasks succeed
+			depSch := scheduler.NewDependencyScheduler(
+				executor,
+				taskbackend.NewSchedulableTaskService(m.kvService),
+				taskbackend.RunSucceeded(combinedTaskService),
+				schOnErr,
+			)
+			executor.SetRunFinishedFunc(func(ctx context.Context, task *taskmodel.Task, run *taskmodel.Run, status taskmodel.RunStatus) {
+				if status == taskmodel.RunSuccess {
+					depSch.Succeeded(ctx, scheduler.ID(task.ID), run.ScheduledFor)
+				}
+			})
+			m.closers = append(m.closers, labeledCloser{
+				label: "task-dependencies",
+				closer: func(context.Context) error {
+					depSch.Stop()
+					return nil
+				},
+			})
+			coordOpts = append(coordOpts, coordinator.WithDependencyScheduler(depSch))
 		}
 
 		m.scheduler = sch
@@ -516,7 +589,8 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		taskCoord := coordinator.NewCoordinator(
 			coordLogger,
 			sch,
-			executor)
+			executor,
+			coordOpts...)
 
 		taskSvc = middleware.New(combinedTaskService, taskCoord)
 		if err := taskbackend.TaskNotifyCoordinatorOfExisting(
@@ -531,36 +605,45 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			coordLogger); err != nil {
 			m.log.Error("Failed to resume existing tasks", zap.Error(err))
 		}
-	}
-
-	dbrpSvc := dbrp.NewAuthorizedService(dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore))
-
-	cm := iqlcontrol.NewControllerMetrics([]string{})
-	m.reg.MustRegister(cm.PrometheusCollectors()...)
 
-	mapper := &iqlcoordinator.LocalShardMapper{
-		MetaClient: metaClient,
-		TSDBStore:  m.engine.TSDBStore(),
-		DBRP:       dbrpSvc,
-	}
-
-	m.log.Info("Configuring InfluxQL statement executor (zeros indicate unlimited).",
-		zap.Int("max_select_point", opts.CoordinatorConfig.MaxSelectPointN),
-		zap.Int("max_select_series", opts.CoordinatorConfig.MaxSelectSeriesN),
-		zap.Int("max_select_buckets", opts.CoordinatorConfig.MaxSelectBucketsN))
+		// backfills execute their runs directly, so that the coordinator does
+		// not schedule them as well.
+		backfillSvc = backfill.NewService(
+			m.log.With(zap.String("service", "task-backfill")),
+			m.kvStore,
+			combinedTaskService,
+			executor,
+			fluxlang.DefaultService,
+		)
+		if err := backfillSvc.Open(ctx); err != nil {
+			m.log.Error("Failed to fail interrupted backfills", zap.Error(err))
+		}
+		m.closers = append(m.closers, labeledCloser{
+			label: "task-backfill",
+			closer: func(context.Context) error {
+				return backfillSvc.Close()
+			},
+		})
 
-	qe := iqlquery.NewExecutor(m.log, cm)
-	se := &iqlcoordinator.StatementExecutor{
-		MetaClient:        metaClient,
-		TSDBStore:         m.engine.TSDBStore(),
-		ShardMapper:       mapper,
-		DBRP:              dbrpSvc,
-		MaxSelectPointN:   opts.CoordinatorConfig.MaxSelectPointN,
-		MaxSelectSeriesN:  opts.CoordinatorConfig.MaxSelectSeriesN,
-		MaxSelectBucketsN: opts.CoordinatorConfig.MaxSelectBucketsN,
+		// runs beyond the retention of the run history of their task are
+		// deleted from the system bucket.
+		historyPruner := runhistory.NewPruner(
+			m.log.With(zap.String("service", "task-run-history")),
+			combinedTaskService,
+			ts.BucketService,
+			deleteService,
+			runhistory.DefaultPruneInterval,
+		)
+		if err := historyPruner.Open(ctx); err != nil {
+			m.log.Error("Failed to start pruning task run history", zap.Error(err))
+		}
+		m.closers = append(m.closers, labeledCloser{
+			label: "task-run-history",
+			closer: func(context.Context) error {
+				return historyPruner.Close()
+			},
+		})
 	}
-	qe.StatementExecutor = se
-	qe.StatementNormalizer = se
 
 	var checkSvc platform.CheckService
 	{
@@ -574,13 +657,22 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		notificationEndpointSvc = endpointservice.New(endpointservice.NewStore(m.kvStore), secretSvc)
 	}
 
-	var notificationRuleSvc platform.NotificationRuleStore
+	var (
+		notificationRuleSvc platform.NotificationRuleStore
+		silenceSvc          silence.SilenceService
+	)
 	{
 		coordinator := coordinator.NewCoordinator(m.log, m.scheduler, m.executor)
-		notificationRuleSvc, err = ruleservice.New(m.log, m.kvStore, m.kvService, ts.OrganizationService, notificationEndpointSvc)
+		silences := silence.NewService(m.kvStore)
+		ruleSvc, err := ruleservice.New(m.log, m.kvStore, m.kvService, ts.OrganizationService, notificationEndpointSvc, silences)
 		if err != nil {
 			return err
 		}
+		notificationRuleSvc = ruleSvc
+
+		// silences are compiled into the tasks of notification rules, which are
+		// regenerated whenever the silences of their organization change.
+		silenceSvc = silence.NewRuleSyncService(silences, ruleSvc)
 
 		// tasks service notification middleware which keeps task service up to date
 		// with persisted changes to notification rules.
@@ -605,6 +697,50 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		},
 	})
 
+	if opts.GraphiteConfig.Enabled {
+		graphiteSvc, err := graphite.NewService(opts.GraphiteConfig)
+		if err != nil {
+			m.log.Error("Failed to create graphite service", zap.Error(err))
+			return err
+		}
+		graphiteSvc.WithLogger(m.log)
+		graphiteSvc.PointsWriter = pointsWriter
+		graphiteSvc.BucketService = ts.BucketService
+		if err := graphiteSvc.Open(ctx); err != nil {
+			m.log.Error("Failed to open graphite service", zap.Error(err))
+			return err
+		}
+		m.closers = append(m.closers, labeledCloser{
+			label: "graphite",
+			closer: func(context.Context) error {
+				return graphiteSvc.Close()
+			},
+		})
+		m.reg.MustRegister(graphiteSvc.PrometheusCollectors()...)
+	}
+
+	if opts.OpenTSDBConfig.Enabled {
+		opentsdbSvc, err := opentsdb.NewService(opts.OpenTSDBConfig)
+		if err != nil {
+			m.log.Error("Failed to create opentsdb service", zap.Error(err))
+			return err
+		}
+		opentsdbSvc.WithLogger(m.log)
+		opentsdbSvc.PointsWriter = pointsWriter
+		opentsdbSvc.BucketService = ts.BucketService
+		if err := opentsdbSvc.Open(ctx); err != nil {
+			m.log.Error("Failed to open opentsdb service", zap.Error(err))
+			return err
+		}
+		m.closers = append(m.closers, labeledCloser{
+			label: "opentsdb",
+			closer: func(context.Context) error {
+				return opentsdbSvc.Close()
+			},
+		})
+		m.reg.MustRegister(opentsdbSvc.PrometheusCollectors()...)
+	}
+
 	var sessionSvc platform.SessionService
 	{
 		sessionSvc = session.NewService(
@@ -688,6 +824,15 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		NotificationRuleFinder:     notificationRuleSvc,
 	}
 
+	// previews execute checks and notification rules directly with the query
+	// controller, without writing their output to the _monitoring bucket.
+	previewSvc := preview.NewService(
+		m.log.With(zap.String("service", "notification-preview")),
+		query.QueryServiceBridge{AsyncQueryService: m.queryController},
+		fluxlang.DefaultService,
+		notificationEndpointSvc,
+	)
+
 	errorHandler := kithttp.NewErrorHandler(m.log.With(zap.String("handler", "error_logger")))
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           opts.AssetsPath,
@@ -702,6 +847,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			BucketFinder:  ts.BucketService,
 			LogBucketName: platform.MonitoringSystemBucketName,
 		},
+		ReadsStore:              readsStore,
 		DeleteService:           deleteService,
 		BackupService:           backupService,
 		SqlBackupRestoreService: m.sqlStore,
@@ -738,9 +884,11 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		FluxService:                     storageQueryService,
 		FluxLanguageService:             fluxlang.DefaultService,
 		TaskService:                     taskSvc,
+		BackfillService:                 backfillSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
 		NotificationEndpointService:     notificationEndpointSvc,
+		PreviewService:                  previewSvc,
 		CheckService:                    checkSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
 		SecretService:                   secretSvc,
@@ -777,6 +925,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			pkger.WithNotificationRuleSVC(authorizer.NewNotificationRuleStore(b.NotificationRuleStore, authedUrmSVC, authedOrgSVC)),
 			pkger.WithOrganizationService(authorizer.NewOrgService(b.OrganizationService)),
 			pkger.WithSecretSVC(authorizer.NewSecretService(b.SecretService)),
+			pkger.WithSilenceSVC(silence.NewAuthedService(silenceSvc)),
 			pkger.WithTaskSVC(authorizer.NewTaskService(pkgerLogger, b.TaskService)),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
 			pkger.WithVariableSVC(authorizer.NewVariableService(b.VariableService)),
@@ -886,6 +1035,11 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		),
 	)
 
+	silenceServer := silence.NewHTTPHandler(
+		m.log.With(zap.String("handler", "silences")),
+		silence.NewAuthedService(silenceSvc),
+	)
+
 	annotationSvc := annotations.NewService(m.sqlStore)
 	annotationServer := annotationTransport.NewAnnotationHandler(
 		m.log.With(zap.String("handler", "annotations")),
@@ -919,6 +1073,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		http.WithResourceHandler(dashboardServer),
 		http.WithResourceHandler(notebookServer),
 		http.WithResourceHandler(annotationServer),
+		http.WithResourceHandler(silenceServer),
 		http.WithResourceHandler(remotesServer),
 		http.WithResourceHandler(replicationServer),
 		http.WithResourceHandler(configHandler),
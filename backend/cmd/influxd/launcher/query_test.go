@@ -169,6 +169,29 @@ func TestLauncher_WriteV2_Query(t *testing.T) {
 
 }
 
+// This test checks that SELECT INTO, which is only supported by InfluxQL
+// tasks, is rejected by the /query endpoint.
+func TestLauncher_Query_SelectIntoNotImplemented(t *testing.T) {
+	be := launcher.RunAndSetupNewLauncherOrFail(ctx, t)
+	defer be.ShutdownOrFail(t, ctx)
+
+	be.WritePointsOrFail(t, "ctr n=1i")
+
+	require.NoError(t, be.DBRPMappingService().Create(context2.SetAuthorizer(ctx, mock.NewMockAuthorizer(true, nil)), &influxdb.DBRPMapping{
+		Database:        "mydb",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  be.Org.ID,
+		BucketID:        be.Bucket.ID,
+	}))
+
+	queryReq := be.MustNewHTTPRequest("POST", "/query?db=mydb", "select * into ctr_copy from ctr")
+	phttp.SetToken(be.Auth.Token, queryReq)
+	queryReq.Header.Set("Content-Type", "application/vnd.influxql")
+	body := mustDoRequest(t, queryReq, nethttp.StatusOK)
+	assert.Equal(t, `{"results":[{"statement_id":0,"error":"not implemented: SELECT INTO"}]}`+"\n", string(body))
+}
+
 func getMemoryUnused(t *testing.T, reg *prom.Registry) int64 {
 	t.Helper()
 
@@ -7,6 +7,7 @@ import (
 	"math"
 	"mime"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
@@ -51,34 +52,55 @@ func (p *prometheusScraper) Gather(target influxdb.ScraperTarget) (collected Met
 }
 
 func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	ms, err := ParseExposition(r, header.Get("Content-Type"), target.MetricFormat, time.Now())
+	if err != nil {
+		return collected, err
+	}
+
+	collected = MetricsCollection{
+		MetricsSlice: ms,
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+	}
+
+	return collected, nil
+}
+
+func isDelimitedProtobuf(mediatype string, params map[string]string) bool {
+	return mediatype == "application/vnd.google.protobuf" &&
+		params["encoding"] == "delimited" &&
+		params["proto"] == "io.prometheus.client.MetricFamily"
+}
+
+// ParseExposition parses metrics in the Prometheus exposition format identified by
+// contentType, laying out histograms and summaries according to format. Metrics
+// without a timestamp are recorded at now.
+func ParseExposition(r io.Reader, contentType string, format influxdb.ScraperMetricFormat, now time.Time) (MetricsSlice, error) {
 	var parser expfmt.TextParser
-	now := time.Now()
 
-	mediatype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	mediatype, params, err := mime.ParseMediaType(contentType)
 	if err != nil && err.Error() == "mime: no media type" {
 		mediatype = "text/plain"
 	} else if err != nil {
-		return collected, err
+		return nil, err
 	}
 	// Prepare output
 	metricFamilies := make(map[string]*dto.MetricFamily)
-	if mediatype == "application/vnd.google.protobuf" &&
-		params["encoding"] == "delimited" &&
-		params["proto"] == "io.prometheus.client.MetricFamily" {
+	if isDelimitedProtobuf(mediatype, params) {
 		for {
 			mf := &dto.MetricFamily{}
 			if _, err := pbutil.ReadDelimited(r, mf); err != nil {
 				if err == io.EOF {
 					break
 				}
-				return collected, fmt.Errorf("reading metric family protocol buffer failed: %s", err)
+				return nil, fmt.Errorf("reading metric family protocol buffer failed: %s", err)
 			}
 			metricFamilies[mf.GetName()] = mf
 		}
 	} else {
 		metricFamilies, err = parser.TextToMetricFamilies(r)
 		if err != nil {
-			return collected, fmt.Errorf("reading text format failed: %s", err)
+			return nil, fmt.Errorf("reading text format failed: %s", err)
 		}
 	}
 	ms := make([]Metrics, 0)
@@ -86,6 +108,19 @@ func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influx
 	// read metrics
 	for name, family := range metricFamilies {
 		for _, m := range family.Metric {
+			tm := now
+			if m.TimestampMs != nil && *m.TimestampMs > 0 {
+				tm = time.Unix(0, *m.TimestampMs*1000000)
+			}
+
+			if format == influxdb.ScraperMetricFormatBounds {
+				switch family.GetType() {
+				case dto.MetricType_SUMMARY, dto.MetricType_HISTOGRAM:
+					ms = append(ms, makeBounds(name, family.GetType(), m, tm))
+					continue
+				}
+			}
+
 			// reading tags
 			tags := makeLabels(m)
 			// reading fields
@@ -116,10 +151,6 @@ func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influx
 			if len(fields) == 0 {
 				continue
 			}
-			tm := now
-			if m.TimestampMs != nil && *m.TimestampMs > 0 {
-				tm = time.Unix(0, *m.TimestampMs*1000000)
-			}
 			me := Metrics{
 				Timestamp: tm,
 				Tags:      tags,
@@ -132,13 +163,61 @@ func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influx
 
 	}
 
-	collected = MetricsCollection{
-		MetricsSlice: ms,
-		OrgID:        target.OrgID,
-		BucketID:     target.BucketID,
+	return ms, nil
+}
+
+// makeBounds lays out a histogram or summary sample in the bounds format: a
+// single series with one field per bucket upper bound or quantile plus count
+// and sum fields. Histogram buckets are cumulative and always include the
+// +Inf bucket, which is the shape expected by Flux's histogramQuantile once
+// the bounds are parsed from the field keys.
+func makeBounds(name string, typ dto.MetricType, m *dto.Metric, tm time.Time) Metrics {
+	var (
+		fields = make(map[string]interface{})
+		count  float64
+		sum    float64
+	)
+
+	switch typ {
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		count, sum = float64(h.GetSampleCount()), h.GetSampleSum()
+
+		for _, b := range h.Bucket {
+			fields[FormatBound(b.GetUpperBound())] = float64(b.GetCumulativeCount())
+		}
+		if _, ok := fields[FormatBound(math.Inf(1))]; !ok {
+			fields[FormatBound(math.Inf(1))] = count
+		}
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		count, sum = float64(s.GetSampleCount()), s.GetSampleSum()
+
+		for _, q := range s.Quantile {
+			if !math.IsNaN(q.GetValue()) {
+				fields[FormatBound(q.GetQuantile())] = q.GetValue()
+			}
+		}
 	}
 
-	return collected, nil
+	fields["count"] = count
+	if !math.IsNaN(sum) {
+		fields["sum"] = sum
+	}
+	return Metrics{
+		Name:      name,
+		Tags:      makeLabels(m),
+		Fields:    fields,
+		Timestamp: tm,
+		Type:      typ,
+	}
+}
+
+// FormatBound formats a histogram bucket upper bound or summary quantile as
+// the key of its field in the bounds format, such that Flux's float()
+// parses the key back into the bound.
+func FormatBound(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
 }
 
 // Get labels from metric
@@ -1,17 +1,21 @@
 package gather
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
 	dto "github.com/prometheus/client_model/go"
 )
 
@@ -157,6 +161,90 @@ func TestPrometheusScraper(t *testing.T) {
 	}
 }
 
+func TestParseExposition_BoundsFormat(t *testing.T) {
+	const resp = `# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{service="a",quantile="0.5"} 0.2
+rpc_duration_seconds{service="a",quantile="0.99"} 1.5
+rpc_duration_seconds_sum{service="a"} 12
+rpc_duration_seconds_count{service="a"} 30
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.5"} 4
+http_request_duration_seconds_bucket{le="1"} 7
+http_request_duration_seconds_bucket{le="+Inf"} 9
+http_request_duration_seconds_sum 6.5
+http_request_duration_seconds_count 9
+`
+	now := time.Unix(0, 0)
+	ms, err := ParseExposition(strings.NewReader(resp), "text/plain; version=0.0.4", influxdb.ScraperMetricFormatBounds, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Metrics{
+		{
+			Name:   "rpc_duration_seconds",
+			Type:   dto.MetricType_SUMMARY,
+			Tags:   map[string]string{"service": "a"},
+			Fields: map[string]interface{}{"0.5": 0.2, "0.99": 1.5, "count": float64(30), "sum": float64(12)},
+		},
+		{
+			Name:   "http_request_duration_seconds",
+			Type:   dto.MetricType_HISTOGRAM,
+			Tags:   map[string]string{},
+			Fields: map[string]interface{}{"0.5": float64(4), "1": float64(7), "+Inf": float64(9), "count": float64(9), "sum": 6.5},
+		},
+	}
+	if len(ms) != len(want) {
+		t.Fatalf("unexpected number of metrics, want %d, got %d: %v", len(want), len(ms), ms)
+	}
+	for _, w := range want {
+		var found bool
+		for _, m := range ms {
+			if cmp.Equal(m, w, metricsCmpOption) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing metric %v", w)
+		}
+	}
+}
+
+func TestParseExposition_BoundsFormatAddsInfBucket(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := pbutil.WriteDelimited(&buf, &dto.MetricFamily{
+		Name: proto.String("latency"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{
+			Histogram: &dto.Histogram{
+				SampleCount: proto.Uint64(3),
+				SampleSum:   proto.Float64(1),
+				Bucket: []*dto.Bucket{
+					{UpperBound: proto.Float64(0.25), CumulativeCount: proto.Uint64(2)},
+				},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contentType := "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited"
+	ms, err := ParseExposition(&buf, contentType, influxdb.ScraperMetricFormatBounds, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ms) != 1 {
+		t.Fatalf("expected a single series, got %v", ms)
+	}
+	want := map[string]interface{}{"0.25": float64(2), "+Inf": float64(3), "count": float64(3), "sum": float64(1)}
+	if diff := cmp.Diff(want, ms[0].Fields); diff != "" {
+		t.Fatalf("unexpected buckets -want/+got\n%s", diff)
+	}
+}
+
 const sampleResp = `
 # 	HELP go_gc_duration_seconds A summary of the GC invocation durations.
 # TYPE go_gc_duration_seconds summary
@@ -16,10 +16,13 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/notification/preview"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/static"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/task/backfill"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -63,6 +66,7 @@ type APIBackend struct {
 	AlgoWProxy FeatureProxyHandler
 
 	PointsWriter                    storage.PointsWriter
+	ReadsStore                      reads.Store
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	SqlBackupRestoreService         influxdb.SqlBackupRestoreService
@@ -92,6 +96,7 @@ type APIBackend struct {
 	FluxService                     query.ProxyQueryService
 	FluxLanguageService             fluxlang.FluxLanguageService
 	TaskService                     taskmodel.TaskService
+	BackfillService                 backfill.BackfillService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
@@ -101,6 +106,7 @@ type APIBackend struct {
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	PreviewService                  preview.PreviewService
 	Flagger                         feature.Flagger
 	FlagsHandler                    http.Handler
 }
@@ -166,6 +172,12 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		b.UserResourceMappingService, b.OrganizationService)
 	h.Mount(prefixNotificationRules, NewNotificationRuleHandler(b.Logger, notificationRuleBackend))
 
+	if b.PreviewService != nil {
+		previewBackend := NewPreviewBackend(b.Logger.With(zap.String("handler", "preview")), b)
+		previewBackend.PreviewService = preview.NewAuthedService(b.PreviewService)
+		h.Mount(prefixPreviews, NewPreviewHandler(b.Logger, previewBackend))
+	}
+
 	scraperBackend := NewScraperBackend(b.Logger.With(zap.String("handler", "scraper")), b)
 	scraperBackend.ScraperStorageService = authorizer.NewScraperTargetStoreService(b.ScraperTargetStoreService,
 		b.UserResourceMappingService,
@@ -182,6 +194,9 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	taskLogger := b.Logger.With(zap.String("handler", "bucket"))
 	taskBackend := NewTaskBackend(taskLogger, b)
 	taskBackend.TaskService = authorizer.NewTaskService(taskLogger, b.TaskService)
+	if b.BackfillService != nil {
+		taskBackend.BackfillService = backfill.NewAuthedService(b.BackfillService, b.TaskService)
+	}
 	taskHandler := NewTaskHandler(b.Logger, taskBackend)
 	h.Mount(prefixTasks, taskHandler)
 
@@ -211,14 +226,16 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService, b.OrganizationService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
-	h.Mount(prefixWrite, NewWriteHandler(b.Logger, writeBackend,
+	writeHandler := NewWriteHandler(b.Logger, writeBackend,
 		WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
 		// WithParserOptions(
 		//	models.WithParserMaxBytes(b.WriteParserMaxBytes),
 		//	models.WithParserMaxLines(b.WriteParserMaxLines),
 		//	models.WithParserMaxValues(b.WriteParserMaxValues),
 		// ),
-	))
+	)
+	h.Mount(prefixWrite, writeHandler)
+	h.Mount(prefixOTLP, writeHandler)
 
 	for _, o := range opts {
 		o(h)
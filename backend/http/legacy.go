@@ -15,6 +15,7 @@ func newLegacyBackend(b *APIBackend) *legacy.Backend {
 		OrganizationService:   b.OrganizationService,
 		BucketService:         b.BucketService,
 		PointsWriter:          b.PointsWriter,
+		ReadsStore:            b.ReadsStore,
 		DBRPMappingService:    b.DBRPService,
 		InfluxqldQueryService: b.InfluxqldService,
 		WriteEventRecorder:    b.WriteEventRecorder,
@@ -33,6 +34,9 @@ func newLegacyHandler(b *legacy.Backend, config legacy.HandlerConfig) *legacy.Ha
 	influxqlBackend := legacy.NewInfluxQLBackend(b)
 	h.InfluxQLHandler = legacy.NewInfluxQLHandler(influxqlBackend, config)
 
+	promBackend := legacy.NewPromBackend(b)
+	h.PromHandler = legacy.NewPromHandler(promBackend, b.MaxBatchSizeBytes)
+
 	h.PingHandler = legacy.NewPingHandler()
 	return h
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/cli"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -19,6 +20,7 @@ type Handler struct {
 	PointsWriterHandler *WriteHandler
 	PingHandler         *PingHandler
 	InfluxQLHandler     *InfluxqlHandler
+	PromHandler         *PromHandler
 }
 
 type Backend struct {
@@ -31,6 +33,7 @@ type Backend struct {
 	OrganizationService   influxdb.OrganizationService
 	BucketService         influxdb.BucketService
 	PointsWriter          storage.PointsWriter
+	ReadsStore            reads.Store
 	DBRPMappingService    influxdb.DBRPMappingService
 	InfluxqldQueryService influxql.ProxyQueryService
 }
@@ -73,6 +76,11 @@ func (h *Handler) ServeHTTP(w http2.ResponseWriter, r *http2.Request) {
 		return
 	}
 
+	if r.URL.Path == prefixPromWrite || r.URL.Path == prefixPromRead {
+		h.PromHandler.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http2.StatusNotFound)
 }
 
@@ -0,0 +1,393 @@
+package legacy
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/http/points"
+	io2 "github.com/influxdata/influxdb/v2/kit/io"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var _ http.Handler = (*PromHandler)(nil)
+
+const (
+	opPromWriteHandler = "http/v1PromWriteHandler"
+	opPromReadHandler  = "http/v1PromReadHandler"
+
+	prefixPromWrite = "/api/v1/prom/write"
+	prefixPromRead  = "/api/v1/prom/read"
+)
+
+// PromBackend contains all the services needed to run a PromHandler.
+type PromBackend struct {
+	errors.HTTPErrorHandler
+	Logger *zap.Logger
+
+	EventRecorder      metric.EventRecorder
+	BucketService      influxdb.BucketService
+	PointsWriter       storage.PointsWriter
+	ReadsStore         reads.Store
+	DBRPMappingService influxdb.DBRPMappingService
+}
+
+// NewPromBackend creates a new backend for the Prometheus remote read and write endpoints.
+func NewPromBackend(b *Backend) *PromBackend {
+	return &PromBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		Logger:             b.Logger.With(zap.String("handler", "prometheus")),
+		EventRecorder:      b.WriteEventRecorder,
+		BucketService:      b.BucketService,
+		PointsWriter:       b.PointsWriter,
+		ReadsStore:         b.ReadsStore,
+		DBRPMappingService: b.DBRPMappingService,
+	}
+}
+
+// PromHandler represents an HTTP API handler for the Prometheus remote write
+// and remote read protocols. The target bucket is selected with the db and rp
+// query parameters, using the same DBRP mappings as the v1 write endpoint.
+type PromHandler struct {
+	errors.HTTPErrorHandler
+	EventRecorder      metric.EventRecorder
+	BucketService      influxdb.BucketService
+	PointsWriter       storage.PointsWriter
+	ReadsStore         reads.Store
+	DBRPMappingService influxdb.DBRPMappingService
+
+	router            *httprouter.Router
+	logger            *zap.Logger
+	maxBatchSizeBytes int64
+}
+
+// NewPromHandler returns a new instance of PromHandler.
+func NewPromHandler(b *PromBackend, maxBatchSizeBytes int64) *PromHandler {
+	h := &PromHandler{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		EventRecorder:      b.EventRecorder,
+		BucketService:      b.BucketService,
+		PointsWriter:       b.PointsWriter,
+		ReadsStore:         b.ReadsStore,
+		DBRPMappingService: b.DBRPMappingService,
+
+		router:            NewRouter(b.HTTPErrorHandler),
+		logger:            b.Logger,
+		maxBatchSizeBytes: maxBatchSizeBytes,
+	}
+
+	h.router.HandlerFunc(http.MethodPost, prefixPromWrite, h.handlePromWrite)
+	h.router.HandlerFunc(http.MethodPost, prefixPromRead, h.handlePromRead)
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *PromHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+// handlePromWrite handles Prometheus remote write requests.
+func (h *PromHandler) handlePromWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := getAuthorization(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// As with the v1 write endpoint, write permissions are enough to read the
+	// DBRP mapping of a bucket.
+	extraPerms := []influxdb.Permission{}
+	for _, perm := range auth.Permissions {
+		if perm.Action == influxdb.WriteAction && perm.Resource.Type == influxdb.BucketsResourceType {
+			extraPerms = append(extraPerms, influxdb.Permission{
+				Action:   influxdb.ReadAction,
+				Resource: perm.Resource,
+			})
+		}
+	}
+	auth.Permissions = append(extraPerms, auth.Permissions...)
+
+	sw := kithttp.NewStatusResponseWriter(w)
+	recorder := newWriteUsageRecorder(sw, h.EventRecorder)
+	var requestBytes int
+	defer func() {
+		// Close around the requestBytes variable to placate the linter.
+		recorder.Record(ctx, requestBytes, auth.OrgID, r.URL.Path)
+	}()
+
+	req, err := decodePromRequest(r, h.maxBatchSizeBytes)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+
+	bucket, err := findBucket(ctx, h.DBRPMappingService, h.BucketService, auth.OrgID, req.Database, req.RetentionPolicy)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+	span.LogKV("bucket_id", bucket.ID)
+
+	if err := checkBucketWritePermissions(auth, bucket.OrgID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+
+	var wr remote.WriteRequest
+	requestBytes, err = req.decode(&wr)
+	if err != nil {
+		h.HandleHTTPError(ctx, decodeError(opPromWriteHandler, "unable to decode remote write request", err), sw)
+		return
+	}
+
+	pts, err := remote.WriteRequestToPoints(&wr)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPromWriteHandler,
+			Err:  err,
+		}, sw)
+		return
+	}
+
+	if err := h.PointsWriter.WritePoints(ctx, bucket.OrgID, bucket.ID, pts); err != nil {
+		if partialErr, ok := err.(tsdb.PartialWriteError); ok {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EUnprocessableEntity,
+				Op:   opPromWriteHandler,
+				Msg:  "failure writing points to database",
+				Err:  partialErr,
+			}, sw)
+			return
+		}
+
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromWriteHandler,
+			Msg:  "unexpected error writing points to database",
+			Err:  err,
+		}, sw)
+		return
+	}
+
+	sw.WriteHeader(http.StatusNoContent)
+}
+
+// handlePromRead handles Prometheus remote read requests. Only the sampled
+// response type is supported.
+func (h *PromHandler) handlePromRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := getAuthorization(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	req, err := decodePromRequest(r, h.maxBatchSizeBytes)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	bucket, err := findBucket(ctx, h.DBRPMappingService, h.BucketService, auth.OrgID, req.Database, req.RetentionPolicy)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	span.LogKV("bucket_id", bucket.ID)
+
+	if err := checkBucketReadPermissions(auth, bucket.OrgID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var rr remote.ReadRequest
+	if _, err := req.decode(&rr); err != nil {
+		h.HandleHTTPError(ctx, decodeError(opPromReadHandler, "unable to decode remote read request", err), w)
+		return
+	}
+
+	resp := &remote.ReadResponse{Results: make([]*remote.QueryResult, 0, len(rr.GetQueries()))}
+	for _, q := range rr.GetQueries() {
+		series, err := h.readQuery(ctx, bucket, q)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		resp.Results = append(resp.Results, &remote.QueryResult{Timeseries: series})
+	}
+
+	b, err := proto.Marshal(resp)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  "unable to encode remote read response",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	if _, err := w.Write(snappy.Encode(nil, b)); err != nil {
+		h.logger.Info("Error writing remote read response", zap.Error(err))
+	}
+}
+
+func (h *PromHandler) readQuery(ctx context.Context, bucket *influxdb.Bucket, q *remote.Query) ([]*remote.TimeSeries, error) {
+	pred, err := remote.QueryToPredicate(q)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPromReadHandler,
+			Err:  err,
+		}
+	}
+
+	src, err := anypb.New(h.ReadsStore.GetSource(uint64(bucket.OrgID), uint64(bucket.ID)))
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := h.ReadsStore.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+		ReadSource: src,
+		Range:      remote.QueryToTimestampRange(q),
+		Predicate:  pred,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  "unable to read series",
+			Err:  err,
+		}
+	}
+	return remote.ResultSetToTimeSeries(rs)
+}
+
+// checkBucketReadPermissions checks an Authorizer for read permissions to a
+// specific Bucket.
+func checkBucketReadPermissions(auth influxdb.Authorizer, orgID, bucketID platform.ID) error {
+	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+			Err:  err,
+		}
+	}
+	if pset, err := auth.PermissionSet(); err != nil || !pset.Allowed(*p) {
+		return &errors.Error{
+			Code: errors.EForbidden,
+			Op:   opPromReadHandler,
+			Msg:  "insufficient permissions for read",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// decodeError wraps an error decoding a remote read or write request body.
+func decodeError(op, msg string, err error) *errors.Error {
+	code := errors.EInvalid
+	if stderrors.Is(err, points.ErrMaxBatchSizeExceeded) {
+		code = errors.ETooLarge
+	}
+	return &errors.Error{
+		Code: code,
+		Op:   op,
+		Msg:  msg,
+		Err:  err,
+	}
+}
+
+// promRequest holds the inputs of a Prometheus remote read or write request.
+type promRequest struct {
+	Database          string
+	RetentionPolicy   string
+	Body              io.ReadCloser
+	maxBatchSizeBytes int64
+}
+
+// decodePromRequest extracts the target database and retention policy and the
+// body from an inbound remote read or write request.
+func decodePromRequest(r *http.Request, maxBatchSizeBytes int64) (*promRequest, error) {
+	qp := r.URL.Query()
+	db := qp.Get("db")
+	if db == "" {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "missing db",
+		}
+	}
+
+	// Remote read and write bodies are always snappy block compressed, which
+	// is handled by decode rather than a Content-Encoding aware reader.
+	body, err := points.BatchReadCloser(r.Body, "", maxBatchSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promRequest{
+		Database:          db,
+		RetentionPolicy:   qp.Get("rp"),
+		Body:              body,
+		maxBatchSizeBytes: maxBatchSizeBytes,
+	}, nil
+}
+
+// decode reads the snappy compressed protocol buffer body into m, returning the
+// number of bytes after decompression.
+func (r *promRequest) decode(m proto.Message) (int, error) {
+	compressed, err := io.ReadAll(r.Body)
+	if cerr := r.Body.Close(); cerr != nil && err == nil {
+		err = cerr
+		if stderrors.Is(cerr, io2.ErrReadLimitExceeded) {
+			err = points.ErrMaxBatchSizeExceeded
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return 0, err
+	}
+	if r.maxBatchSizeBytes > 0 && int64(n) > r.maxBatchSizeBytes {
+		return 0, points.ErrMaxBatchSizeExceeded
+	}
+
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), proto.Unmarshal(b, m)
+}
@@ -0,0 +1,311 @@
+package legacy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/http/mocks"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPromHandler_Write(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		// Mocked Services
+		eventRecorder  = mocks.NewMockEventRecorder(ctrl)
+		dbrpMappingSvc = mocks.NewMockDBRPMappingService(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+		pointsWriter   = mocks.NewMockPointsWriter(ctrl)
+
+		// Found Resources
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{
+			ID:                  generator.ID(),
+			OrgID:               orgID,
+			Name:                "prometheus/autogen",
+			RetentionPolicyName: "autogen",
+			RetentionPeriod:     72 * time.Hour,
+		}
+		mapping = &influxdb.DBRPMapping{
+			OrganizationID:  orgID,
+			BucketID:        bucket.ID,
+			Database:        "prometheus",
+			RetentionPolicy: "autogen",
+			Default:         true,
+		}
+	)
+
+	findAutogenMapping := dbrpMappingSvc.
+		EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilter{
+			OrgID:    &mapping.OrganizationID,
+			Database: &mapping.Database,
+			Default:  &mapping.Default,
+		}).Return([]*influxdb.DBRPMapping{mapping}, 1, nil)
+
+	findBucketByID := bucketService.
+		EXPECT().
+		FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil)
+
+	points := parseLineProtocol(t, "up,job=api value=1 1000000000")
+	writePoints := pointsWriter.
+		EXPECT().
+		WritePoints(gomock.Any(), orgID, bucket.ID, pointsMatcher{points}).Return(nil)
+
+	recordWriteEvent := eventRecorder.EXPECT().
+		Record(gomock.Any(), gomock.Any())
+
+	gomock.InOrder(
+		findAutogenMapping,
+		findBucketByID,
+		writePoints,
+		recordWriteEvent,
+	)
+
+	perms := newPermissions(influxdb.WriteAction, influxdb.BucketsResourceType, &orgID, nil)
+	auth := newAuthorization(orgID, perms...)
+	ctx := pcontext.SetAuthorizer(context.Background(), auth)
+	r := newPromRequest(t, ctx, prefixPromWrite+"?db=prometheus", &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels:  []*remote.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+				Samples: []*remote.Sample{{Timestamp: 1000, Value: 1}},
+			},
+		},
+	})
+
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrp.NewAuthorizedService(dbrpMappingSvc),
+		PointsWriter:       pointsWriter,
+		EventRecorder:      eventRecorder,
+	}, 0)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestPromHandler_WriteTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		eventRecorder  = mocks.NewMockEventRecorder(ctrl)
+		dbrpMappingSvc = mocks.NewMockDBRPMappingService(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+		pointsWriter   = mocks.NewMockPointsWriter(ctrl)
+
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{ID: generator.ID(), OrgID: orgID}
+	)
+
+	dbrpMappingSvc.EXPECT().FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMapping{{OrganizationID: orgID, BucketID: bucket.ID, Database: "prometheus"}}, 1, nil)
+	bucketService.EXPECT().FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil)
+	eventRecorder.EXPECT().Record(gomock.Any(), gomock.Any())
+
+	perms := newPermissions(influxdb.WriteAction, influxdb.BucketsResourceType, &orgID, nil)
+	ctx := pcontext.SetAuthorizer(context.Background(), newAuthorization(orgID, perms...))
+	r := newPromRequest(t, ctx, prefixPromWrite+"?db=prometheus", &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels:  []*remote.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+				Samples: []*remote.Sample{{Timestamp: 1000, Value: 1}},
+			},
+		},
+	})
+
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrp.NewAuthorizedService(dbrpMappingSvc),
+		PointsWriter:       pointsWriter,
+		EventRecorder:      eventRecorder,
+	}, 5)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestPromHandler_Read(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		dbrpMappingSvc = mocks.NewMockDBRPMappingService(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{ID: generator.ID(), OrgID: orgID}
+	)
+
+	dbrpMappingSvc.EXPECT().FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMapping{{OrganizationID: orgID, BucketID: bucket.ID, Database: "prometheus"}}, 1, nil)
+	bucketService.EXPECT().FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil)
+
+	store := &promReadsStore{
+		rs: &promResultSet{
+			tags: models.ParseTags([]byte("up,_measurement=up,_field=value,job=api")),
+			values: &cursors.FloatArray{
+				Timestamps: []int64{1000000000},
+				Values:     []float64{1},
+			},
+		},
+	}
+
+	perms := newPermissions(influxdb.ReadAction, influxdb.BucketsResourceType, &orgID, nil)
+	ctx := pcontext.SetAuthorizer(context.Background(), newAuthorization(orgID, perms...))
+	r := newPromRequest(t, ctx, prefixPromRead+"?db=prometheus", &remote.ReadRequest{
+		Queries: []*remote.Query{
+			{
+				StartTimestampMs: 0,
+				EndTimestampMs:   2000,
+				Matchers:         []*remote.LabelMatcher{{Type: remote.LabelMatcher_EQ, Name: "__name__", Value: "up"}},
+			},
+		},
+	})
+
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrp.NewAuthorizedService(dbrpMappingSvc),
+		ReadsStore:         store,
+	}, 0)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+
+	require.Equal(t, int64(2001000000), store.req.GetRange().GetEnd())
+	require.Equal(t, "'\xff' = \"value\" AND '\x00' = \"up\"", reads.PredicateToExprString(store.req.GetPredicate()))
+
+	b, err := snappy.Decode(nil, w.Body.Bytes())
+	require.NoError(t, err)
+	var resp remote.ReadResponse
+	require.NoError(t, proto.Unmarshal(b, &resp))
+	require.Len(t, resp.GetResults(), 1)
+	require.Len(t, resp.GetResults()[0].GetTimeseries(), 1)
+
+	ts := resp.GetResults()[0].GetTimeseries()[0]
+	require.Equal(t, "__name__", ts.GetLabels()[0].GetName())
+	require.Equal(t, "up", ts.GetLabels()[0].GetValue())
+	require.Equal(t, int64(1000), ts.GetSamples()[0].GetTimestamp())
+}
+
+func TestPromHandler_ReadNoPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		dbrpMappingSvc = mocks.NewMockDBRPMappingService(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{ID: generator.ID(), OrgID: orgID}
+	)
+
+	dbrpMappingSvc.EXPECT().FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMapping{{OrganizationID: orgID, BucketID: bucket.ID, Database: "prometheus"}}, 1, nil)
+	bucketService.EXPECT().FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil)
+
+	// Write permissions do not allow reading series.
+	perms := newPermissions(influxdb.WriteAction, influxdb.BucketsResourceType, &orgID, nil)
+	ctx := pcontext.SetAuthorizer(context.Background(), newAuthorization(orgID, perms...))
+	r := newPromRequest(t, ctx, prefixPromRead+"?db=prometheus", &remote.ReadRequest{})
+
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrpMappingSvc,
+		ReadsStore:         &promReadsStore{},
+	}, 0)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func newPromRequest(t *testing.T, ctx context.Context, target string, m proto.Message) *http.Request {
+	t.Helper()
+	b, err := proto.Marshal(m)
+	require.NoError(t, err)
+	var body io.Reader = bytes.NewReader(snappy.Encode(nil, b))
+	return httptest.NewRequest(http.MethodPost, "http://localhost:9999"+target, body).WithContext(ctx)
+}
+
+// promReadsStore is a reads.Store returning a single result set from ReadFilter.
+type promReadsStore struct {
+	reads.Store
+	rs  reads.ResultSet
+	req *datatypes.ReadFilterRequest
+}
+
+func (s *promReadsStore) ReadFilter(_ context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.req = req
+	return s.rs, nil
+}
+
+func (s *promReadsStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &datatypes.TimestampRange{}
+}
+
+// promResultSet is a reads.ResultSet holding a single float series.
+type promResultSet struct {
+	tags   models.Tags
+	values *cursors.FloatArray
+	done   bool
+}
+
+func (rs *promResultSet) Next() bool {
+	if rs.done {
+		return false
+	}
+	rs.done = true
+	return true
+}
+
+func (rs *promResultSet) Cursor() cursors.Cursor     { return &promFloatCursor{a: rs.values} }
+func (rs *promResultSet) Tags() models.Tags          { return rs.tags }
+func (rs *promResultSet) Close()                     {}
+func (rs *promResultSet) Err() error                 { return nil }
+func (rs *promResultSet) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type promFloatCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *promFloatCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+func (c *promFloatCursor) Close()                     {}
+func (c *promFloatCursor) Err() error                 { return nil }
+func (c *promFloatCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
@@ -138,7 +138,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bucket, err := h.findBucket(ctx, auth.OrgID, req.Database, req.RetentionPolicy)
+	bucket, err := findBucket(ctx, h.DBRPMappingService, h.BucketService, auth.OrgID, req.Database, req.RetentionPolicy)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -181,13 +181,13 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 
 // findBucket finds a bucket for the specified database and
 // retention policy combination.
-func (h *WriteHandler) findBucket(ctx context.Context, orgID platform.ID, db, rp string) (*influxdb.Bucket, error) {
-	mapping, err := h.findMapping(ctx, orgID, db, rp)
+func findBucket(ctx context.Context, dbrpSvc influxdb.DBRPMappingService, bucketSvc influxdb.BucketService, orgID platform.ID, db, rp string) (*influxdb.Bucket, error) {
+	mapping, err := findMapping(ctx, dbrpSvc, orgID, db, rp)
 	if err != nil {
 		return nil, err
 	}
 
-	return h.BucketService.FindBucketByID(ctx, mapping.BucketID)
+	return bucketSvc.FindBucketByID(ctx, mapping.BucketID)
 }
 
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
@@ -215,7 +215,7 @@ func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID platf
 
 // findMapping finds a DBRPMapping for the database and retention policy
 // combination.
-func (h *WriteHandler) findMapping(ctx context.Context, orgID platform.ID, db, rp string) (*influxdb.DBRPMapping, error) {
+func findMapping(ctx context.Context, dbrpSvc influxdb.DBRPMappingService, orgID platform.ID, db, rp string) (*influxdb.DBRPMapping, error) {
 	filter := influxdb.DBRPMappingFilter{
 		OrgID:    &orgID,
 		Database: &db,
@@ -227,7 +227,7 @@ func (h *WriteHandler) findMapping(ctx context.Context, orgID platform.ID, db, r
 		filter.Default = &b
 	}
 
-	mappings, count, err := h.DBRPMappingService.FindMany(ctx, filter)
+	mappings, count, err := dbrpSvc.FindMany(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +28,13 @@ type NotificationEndpointBackend struct {
 	UserResourceMappingService  influxdb.UserResourceMappingService
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
+
+	// EmailRelayEndpointService, EmailRelayRuleStore and SecretService are used by the
+	// email relay of SMTP endpoints, which authenticates requests with relay keys rather
+	// than tokens.
+	EmailRelayEndpointService influxdb.NotificationEndpointService
+	EmailRelayRuleStore       influxdb.NotificationRuleStore
+	SecretService             influxdb.SecretService
 }
 
 // NewNotificationEndpointBackend returns a new instance of NotificationEndpointBackend.
@@ -39,6 +46,9 @@ func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend) *Notificatio
 		UserResourceMappingService:  b.UserResourceMappingService,
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
+		EmailRelayEndpointService:   b.NotificationEndpointService,
+		EmailRelayRuleStore:         b.NotificationRuleStore,
+		SecretService:               b.SecretService,
 	}
 }
 
@@ -56,6 +66,9 @@ type NotificationEndpointHandler struct {
 	UserResourceMappingService  influxdb.UserResourceMappingService
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
+	EmailRelayEndpointService   influxdb.NotificationEndpointService
+	EmailRelayRuleStore         influxdb.NotificationRuleStore
+	SecretService               influxdb.SecretService
 }
 
 const (
@@ -67,6 +80,7 @@ const (
 	notificationEndpointsIDOwnersIDPath  = "/api/v2/notificationEndpoints/:id/owners/:userID"
 	notificationEndpointsIDLabelsPath    = "/api/v2/notificationEndpoints/:id/labels"
 	notificationEndpointsIDLabelsIDPath  = "/api/v2/notificationEndpoints/:id/labels/:lid"
+	notificationEndpointsIDEmailPath     = "/api/v2/notificationEndpoints/:id/email"
 )
 
 // NewNotificationEndpointHandler returns a new instance of NotificationEndpointHandler.
@@ -80,6 +94,9 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 		UserResourceMappingService:  b.UserResourceMappingService,
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
+		EmailRelayEndpointService:   b.EmailRelayEndpointService,
+		EmailRelayRuleStore:         b.EmailRelayRuleStore,
+		SecretService:               b.SecretService,
 	}
 	h.HandlerFunc("POST", prefixNotificationEndpoints, h.handlePostNotificationEndpoint)
 	h.HandlerFunc("GET", prefixNotificationEndpoints, h.handleGetNotificationEndpoints)
@@ -87,6 +104,7 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 	h.HandlerFunc("DELETE", notificationEndpointsIDPath, h.handleDeleteNotificationEndpoint)
 	h.HandlerFunc("PUT", notificationEndpointsIDPath, h.handlePutNotificationEndpoint)
 	h.HandlerFunc("PATCH", notificationEndpointsIDPath, h.handlePatchNotificationEndpoint)
+	h.HandlerFunc("POST", notificationEndpointsIDEmailPath, h.handlePostNotificationEndpointEmail)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -700,9 +718,12 @@ func (n *notificationEndpointEncoder) MarshalJSON() ([]byte, error) {
 
 	// this makes me queezy and altogether sad
 	fieldMap := map[string]string{
+		"-api-key":     "apiKey",
 		"-password":    "password",
+		"-relay-key":   "relayKey",
 		"-routing-key": "routingKey",
 		"-token":       "token",
+		"-url":         "url",
 		"-username":    "username",
 	}
 	for _, sec := range n.ne.SecretFields() {
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"go.uber.org/zap"
+)
+
+// emailRelayTimeout bounds the delivery of an email to the mail server of an SMTP endpoint.
+const emailRelayTimeout = 30 * time.Second
+
+// handlePostNotificationEndpointEmail is the email relay of SMTP endpoints. Flux can't send
+// email, so the tasks of SMTP notification rules post their emails here to be delivered to
+// the mail server of the endpoint.
+//
+// The route doesn't require a token; requests are authenticated with the relay key of the
+// endpoint instead, which the tasks read from the secrets of the organization. Emails are
+// only relayed to the recipients of the notification rules notifying the endpoint.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// Failures before the relay key is verified are reported the same way, so as to not
+	// reveal which endpoints exist.
+	key := r.Header.Get(endpoint.SMTPRelayKeyHeader)
+	if key == "" {
+		UnauthorizedError(ctx, h, w)
+		return
+	}
+	edp, err := h.EmailRelayEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.log.Debug("Failed to find email relay endpoint", zap.Error(err))
+		UnauthorizedError(ctx, h, w)
+		return
+	}
+	smtp, ok := edp.(*endpoint.SMTP)
+	if !ok || smtp.RelayKey.Key == "" {
+		UnauthorizedError(ctx, h, w)
+		return
+	}
+	want, err := h.SecretService.LoadSecret(ctx, smtp.GetOrgID(), smtp.RelayKey.Key)
+	if err != nil || subtle.ConstantTimeCompare([]byte(key), []byte(want)) != 1 {
+		UnauthorizedError(ctx, h, w)
+		return
+	}
+
+	var email endpoint.Email
+	if err := json.NewDecoder(r.Body).Decode(&email); err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid email",
+			Err:  err,
+		}, w)
+		return
+	}
+	if err := email.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	allowed, err := h.emailRelayRecipients(ctx, smtp)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	for _, to := range email.To {
+		if !allowed[to] {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("email recipient %q is not a recipient of the notification rules of the endpoint", to),
+			}, w)
+			return
+		}
+	}
+
+	var username, password string
+	if smtp.Username.Key != "" {
+		if username, err = h.SecretService.LoadSecret(ctx, smtp.GetOrgID(), smtp.Username.Key); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if smtp.Password.Key != "" {
+		if password, err = h.SecretService.LoadSecret(ctx, smtp.GetOrgID(), smtp.Password.Key); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, emailRelayTimeout)
+	defer cancel()
+	if err := smtp.Send(ctx, username, password, email); err != nil {
+		h.log.Info("Failed to send email", zap.Stringer("notificationEndpointID", id), zap.Error(err))
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EUnavailable,
+			Msg:  "failed to send email",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// emailRelayRecipients returns the recipients of the notification rules of the organization
+// of an SMTP endpoint that notify it: those of the SMTP rules of the endpoint, and those of
+// the escalation steps to it. Steps of SMTP rules mail the recipients of the rule.
+func (h *NotificationEndpointHandler) emailRelayRecipients(ctx context.Context, smtp *endpoint.SMTP) (map[string]bool, error) {
+	orgID := smtp.GetOrgID()
+	nrs, _, err := h.EmailRelayRuleStore.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool)
+	add := func(to []string) {
+		for _, addr := range to {
+			allowed[addr] = true
+		}
+	}
+	for _, nr := range nrs {
+		smtpRule, isSMTP := nr.(*rule.SMTP)
+		if isSMTP && nr.GetEndpointID() == smtp.GetID() {
+			add(smtpRule.To)
+		}
+		er, ok := nr.(interface{ GetEscalationSteps() []rule.EscalationStep })
+		if !ok {
+			continue
+		}
+		for _, step := range er.GetEscalationSteps() {
+			if step.EndpointID != smtp.GetID() {
+				continue
+			}
+			if isSMTP {
+				add(smtpRule.To)
+			} else {
+				add(step.To)
+			}
+		}
+	}
+	return allowed, nil
+}
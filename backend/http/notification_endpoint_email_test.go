@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_handlePostNotificationEndpointEmail(t *testing.T) {
+	var (
+		orgID      = influxTesting.MustIDBase16("50f7ba1150f7ba11")
+		endpointID = influxTesting.MustIDBase16("0b501e7e557ab1ed")
+		otherID    = influxTesting.MustIDBase16("c0175f0077a77005")
+	)
+
+	// Nothing listens on the port of the mail server, so emails that pass
+	// the checks of the relay fail to be delivered.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	smtp := &endpoint.SMTP{
+		Base: endpoint.Base{
+			ID:    &endpointID,
+			OrgID: &orgID,
+			Name:  "mail",
+		},
+		Host:     "127.0.0.1",
+		Port:     port,
+		From:     "influxdb@example.com",
+		RelayKey: influxdb.SecretField{Key: endpointID.String() + "-relay-key"},
+	}
+	rules := []influxdb.NotificationRule{
+		&rule.SMTP{
+			Base: rule.Base{OrgID: orgID, EndpointID: endpointID},
+			To:   []string{"ops@example.com"},
+		},
+		&rule.SMTP{
+			Base: rule.Base{OrgID: orgID, EndpointID: otherID},
+			To:   []string{"other@example.com"},
+		},
+		&rule.Slack{
+			Base: rule.Base{
+				OrgID:      orgID,
+				EndpointID: otherID,
+				EscalationSteps: []rule.EscalationStep{
+					{EndpointID: endpointID, Level: notification.Critical, To: []string{"oncall@example.com"}},
+				},
+			},
+		},
+	}
+
+	newHandler := func() *NotificationEndpointHandler {
+		b := NewMockNotificationEndpointBackend(t)
+		b.EmailRelayEndpointService = &mock.NotificationEndpointService{
+			FindNotificationEndpointByIDF: func(ctx context.Context, id platform.ID) (influxdb.NotificationEndpoint, error) {
+				return smtp, nil
+			},
+		}
+		b.EmailRelayRuleStore = &mock.NotificationRuleStore{
+			FindNotificationRulesF: func(ctx context.Context, filter influxdb.NotificationRuleFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+				return rules, len(rules), nil
+			},
+		}
+		b.SecretService = &mock.SecretService{
+			LoadSecretFn: func(ctx context.Context, orgID platform.ID, k string) (string, error) {
+				return "relay-key", nil
+			},
+		}
+		return NewNotificationEndpointHandler(zaptest.NewLogger(t), b)
+	}
+
+	tests := []struct {
+		name       string
+		to         string
+		statusCode int
+	}{
+		{
+			name:       "recipient of an SMTP rule of the endpoint",
+			to:         `"ops@example.com"`,
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "recipient of an escalation step to the endpoint",
+			to:         `"oncall@example.com"`,
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "recipient of an SMTP rule of another endpoint",
+			to:         `"other@example.com"`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown recipient among known ones",
+			to:         `"ops@example.com", "attacker@example.com"`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"to": [` + tt.to + `], "subject": "alert", "body": "crit"}`
+			r := httptest.NewRequest("POST", "/api/v2/notificationEndpoints/"+endpointID.String()+"/email", strings.NewReader(body))
+			r.Header.Set(endpoint.SMTPRelayKeyHeader, "relay-key")
+			w := httptest.NewRecorder()
+
+			newHandler().ServeHTTP(w, r)
+
+			assert.Equal(t, tt.statusCode, w.Code, w.Body.String())
+		})
+	}
+}
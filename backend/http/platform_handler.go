@@ -36,6 +36,8 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	// The email relay authenticates with the relay keys of SMTP endpoints.
+	h.RegisterNoAuthRoute("POST", notificationEndpointsIDEmailPath)
 
 	assetHandler := static.NewAssetHandler(b.AssetsPath)
 	if b.UIDisabled {
@@ -83,7 +85,9 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// TODO(affo): change this to be mounted prefixes: https://github.com/influxdata/idpe/issues/6689.
 	if r.URL.Path == "/write" ||
 		r.URL.Path == "/query" ||
-		r.URL.Path == "/ping" {
+		r.URL.Path == "/ping" ||
+		r.URL.Path == "/api/v1/prom/write" ||
+		r.URL.Path == "/api/v1/prom/read" {
 		h.LegacyHandler.ServeHTTP(w, r)
 		return
 	}
@@ -1,6 +1,7 @@
 package points
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -8,11 +9,14 @@ import (
 	"io"
 	"time"
 
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/gather"
 	io2 "github.com/influxdata/influxdb/v2/kit/io"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/otlp"
 	"github.com/opentracing/opentracing-go"
 )
 
@@ -38,6 +42,17 @@ type ParsedPoints struct {
 // Parser parses batches of Points.
 type Parser struct {
 	Precision string
+	// ContentType is the media type of the batch, which identifies the
+	// encoding of OTLP and Prometheus batches.
+	ContentType string
+	// Prometheus indicates the batch is in the Prometheus exposition format
+	// identified by ContentType, the text format by default. Histograms and
+	// summaries are stored in the bounds format, see
+	// influxdb.ScraperMetricFormatBounds.
+	Prometheus bool
+	// OTLP indicates the batch is an OTLP/HTTP metrics export request encoded
+	// as ContentType.
+	OTLP bool
 	//ParserOptions []models.ParserOption
 }
 
@@ -67,7 +82,14 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID,
 
 	span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
 
-	points, err := models.ParsePointsWithPrecision(data, time.Now().UTC(), pw.Precision)
+	var points models.Points
+	if pw.OTLP {
+		points, err = parseOTLPMetrics(data, pw.ContentType)
+	} else if pw.Prometheus {
+		points, err = parseExposition(data, pw.ContentType)
+	} else {
+		points, err = models.ParsePointsWithPrecision(data, time.Now().UTC(), pw.Precision)
+	}
 	span.LogKV("values_total", len(points))
 	span.Finish()
 	if err != nil {
@@ -95,6 +117,22 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID,
 	}, nil
 }
 
+func parseExposition(data []byte, contentType string) (models.Points, error) {
+	ms, err := gather.ParseExposition(bytes.NewReader(data), contentType, influxdb.ScraperMetricFormatBounds, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return ms.Points()
+}
+
+func parseOTLPMetrics(data []byte, contentType string) (models.Points, error) {
+	req, err := otlp.UnmarshalMetricsRequest(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return otlp.MetricsToPoints(req, time.Now().UTC())
+}
+
 func readAll(ctx context.Context, rc io.ReadCloser) (data []byte, err error) {
 	defer func() {
 		if cerr := rc.Close(); cerr != nil && err == nil {
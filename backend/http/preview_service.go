@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/preview"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"go.uber.org/zap"
+)
+
+// PreviewBackend is all services and associated parameters required to construct
+// the PreviewHandler.
+type PreviewBackend struct {
+	errors.HTTPErrorHandler
+	log *zap.Logger
+
+	PreviewService preview.PreviewService
+}
+
+// NewPreviewBackend returns a new instance of PreviewBackend.
+func NewPreviewBackend(log *zap.Logger, b *APIBackend) *PreviewBackend {
+	return &PreviewBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		PreviewService:   b.PreviewService,
+	}
+}
+
+// PreviewHandler is the handler for the previews of checks and notification rules.
+type PreviewHandler struct {
+	*httprouter.Router
+	errors.HTTPErrorHandler
+	log *zap.Logger
+
+	PreviewService preview.PreviewService
+}
+
+const (
+	prefixPreviews                = "/api/v2/previews"
+	previewsChecksPath            = "/api/v2/previews/checks"
+	previewsNotificationRulesPath = "/api/v2/previews/notificationRules"
+)
+
+// NewPreviewHandler returns a new instance of PreviewHandler.
+func NewPreviewHandler(log *zap.Logger, b *PreviewBackend) *PreviewHandler {
+	h := &PreviewHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		PreviewService: b.PreviewService,
+	}
+
+	h.HandlerFunc("POST", previewsChecksPath, h.handlePostCheckPreview)
+	h.HandlerFunc("POST", previewsNotificationRulesPath, h.handlePostNotificationRulePreview)
+	return h
+}
+
+type postCheckPreviewRequest struct {
+	Check json.RawMessage `json:"check"`
+	Start time.Time       `json:"start"`
+	Stop  time.Time       `json:"stop"`
+}
+
+func decodePostCheckPreviewRequest(r *http.Request) (preview.CheckPreview, error) {
+	var req postCheckPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return preview.CheckPreview{}, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+
+	cp := preview.CheckPreview{
+		Start: req.Start,
+		Stop:  req.Stop,
+	}
+	if len(req.Check) > 0 {
+		chk, err := check.UnmarshalJSON(req.Check)
+		if err != nil {
+			return preview.CheckPreview{}, &errors.Error{
+				Code: errors.EInvalid,
+				Err:  err,
+			}
+		}
+		cp.Check = chk
+	}
+	return cp, nil
+}
+
+type checkPreviewResponse struct {
+	Evaluations int              `json:"evaluations"`
+	Statuses    []preview.Record `json:"statuses"`
+}
+
+// handlePostCheckPreview is the HTTP handler for the POST /api/v2/previews/checks route.
+func (h *PreviewHandler) handlePostCheckPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cp, err := decodePostCheckPreviewRequest(r)
+	if err != nil {
+		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.PreviewService.PreviewCheck(ctx, cp)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	resp := checkPreviewResponse{
+		Evaluations: p.Evaluations,
+		Statuses:    p.Records,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type postNotificationRulePreviewRequest struct {
+	NotificationRule json.RawMessage `json:"notificationRule"`
+	Start            time.Time       `json:"start"`
+	Stop             time.Time       `json:"stop"`
+}
+
+func decodePostNotificationRulePreviewRequest(r *http.Request) (preview.NotificationRulePreview, error) {
+	var req postNotificationRulePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return preview.NotificationRulePreview{}, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+
+	np := preview.NotificationRulePreview{
+		Start: req.Start,
+		Stop:  req.Stop,
+	}
+	if len(req.NotificationRule) > 0 {
+		nr, err := rule.UnmarshalJSON(req.NotificationRule)
+		if err != nil {
+			return preview.NotificationRulePreview{}, &errors.Error{
+				Code: errors.EInvalid,
+				Err:  err,
+			}
+		}
+		np.NotificationRule = nr
+	}
+	return np, nil
+}
+
+type notificationRulePreviewResponse struct {
+	Evaluations   int              `json:"evaluations"`
+	Notifications []preview.Record `json:"notifications"`
+}
+
+// handlePostNotificationRulePreview is the HTTP handler for the POST /api/v2/previews/notificationRules route.
+func (h *PreviewHandler) handlePostNotificationRulePreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	np, err := decodePostNotificationRulePreviewRequest(r)
+	if err != nil {
+		h.log.Debug("Failed to decode request", zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.PreviewService.PreviewNotificationRule(ctx, np)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	resp := notificationRulePreviewResponse{
+		Evaluations:   p.Evaluations,
+		Notifications: p.Records,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/preview"
+	previewMock "github.com/influxdata/influxdb/v2/notification/preview/mock"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/influxdata/influxdb/v2/pkg/testttp"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewMockPreviewBackend returns a PreviewBackend with mock services.
+func NewMockPreviewBackend(t *testing.T) *PreviewBackend {
+	return &PreviewBackend{
+		HTTPErrorHandler: kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		log:              zaptest.NewLogger(t),
+
+		PreviewService: previewMock.NewPreviewService(),
+	}
+}
+
+func TestPreviewHandler_handlePostCheckPreview(t *testing.T) {
+	var (
+		start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		stop  = start.Add(2 * time.Minute)
+	)
+
+	tests := []struct {
+		name       string
+		body       string
+		previewFn  func(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error)
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name: "preview a check",
+			body: `{"check": {"type": "deadman", "name": "foo", "orgID": "020f755c3c082000", "every": "1m"}, "start": "2021-03-01T00:00:00Z", "stop": "2021-03-01T00:02:00Z"}`,
+			previewFn: func(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error) {
+				require.IsType(t, &check.Deadman{}, cp.Check)
+				assert.Equal(t, influxTesting.MustIDBase16("020f755c3c082000"), cp.Check.GetOrgID())
+				assert.Equal(t, start, cp.Start)
+				assert.Equal(t, stop, cp.Stop)
+				return &preview.Preview{
+					Evaluations: 2,
+					Records:     []preview.Record{{"_level": "crit"}},
+				}, nil
+			},
+			statusCode: http.StatusOK,
+			wantBody:   `{"evaluations": 2, "statuses": [{"_level": "crit"}]}`,
+		},
+		{
+			name: "invalid preview",
+			body: `{"start": "2021-03-01T00:00:00Z", "stop": "2021-03-01T00:02:00Z"}`,
+			previewFn: func(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error) {
+				return nil, cp.Valid()
+			},
+			statusCode: http.StatusBadRequest,
+			wantBody:   `{"code": "invalid", "message": "preview check is required"}`,
+		},
+		{
+			name:       "invalid check",
+			body:       `{"check": {"type": "unknown"}}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			body:       `{`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previewBackend := NewMockPreviewBackend(t)
+			if tt.previewFn != nil {
+				previewBackend.PreviewService = &previewMock.PreviewService{PreviewCheckFn: tt.previewFn}
+			}
+
+			testttp.
+				Post(t, previewsChecksPath, strings.NewReader(tt.body)).
+				Do(NewPreviewHandler(zaptest.NewLogger(t), previewBackend)).
+				ExpectStatus(tt.statusCode).
+				ExpectBody(func(body *bytes.Buffer) {
+					if tt.wantBody == "" {
+						return
+					}
+					if eq, diff, err := jsonEqual(body.String(), tt.wantBody); err != nil {
+						t.Errorf("jsonEqual error: %v", err)
+					} else if !eq {
+						t.Errorf("handlePostCheckPreview() = ***%s***", diff)
+					}
+				})
+		})
+	}
+}
+
+func TestPreviewHandler_handlePostNotificationRulePreview(t *testing.T) {
+	var (
+		start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		stop  = start.Add(time.Hour)
+	)
+
+	tests := []struct {
+		name       string
+		body       string
+		previewFn  func(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error)
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name: "preview a notification rule",
+			body: `{"notificationRule": {"type": "slack", "name": "foo", "orgID": "020f755c3c082000", "endpointID": "020f755c3c082001", "every": "1h", "channel": "bar", "messageTemplate": "blah"}, "start": "2021-03-01T00:00:00Z", "stop": "2021-03-01T01:00:00Z"}`,
+			previewFn: func(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error) {
+				require.IsType(t, &rule.Slack{}, np.NotificationRule)
+				assert.Equal(t, influxTesting.MustIDBase16("020f755c3c082001"), np.NotificationRule.GetEndpointID())
+				assert.Equal(t, start, np.Start)
+				assert.Equal(t, stop, np.Stop)
+				return &preview.Preview{
+					Evaluations: 1,
+					Records:     []preview.Record{{"_sent": "false"}},
+				}, nil
+			},
+			statusCode: http.StatusOK,
+			wantBody:   `{"evaluations": 1, "notifications": [{"_sent": "false"}]}`,
+		},
+		{
+			name: "endpoint not found",
+			body: `{"notificationRule": {"type": "slack", "name": "foo", "orgID": "020f755c3c082000", "endpointID": "020f755c3c082001", "every": "1h"}, "start": "2021-03-01T00:00:00Z", "stop": "2021-03-01T01:00:00Z"}`,
+			previewFn: func(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error) {
+				return nil, &errors.Error{
+					Code: errors.ENotFound,
+					Msg:  "notification endpoint not found",
+				}
+			},
+			statusCode: http.StatusNotFound,
+			wantBody:   `{"code": "not found", "message": "notification endpoint not found"}`,
+		},
+		{
+			name:       "invalid json",
+			body:       `{`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previewBackend := NewMockPreviewBackend(t)
+			if tt.previewFn != nil {
+				previewBackend.PreviewService = &previewMock.PreviewService{PreviewNotificationRuleFn: tt.previewFn}
+			}
+
+			testttp.
+				Post(t, previewsNotificationRulesPath, strings.NewReader(tt.body)).
+				Do(NewPreviewHandler(zaptest.NewLogger(t), previewBackend)).
+				ExpectStatus(tt.statusCode).
+				ExpectBody(func(body *bytes.Buffer) {
+					if tt.wantBody == "" {
+						return
+					}
+					if eq, diff, err := jsonEqual(body.String(), tt.wantBody); err != nil {
+						t.Errorf("jsonEqual error: %v", err)
+					} else if !eq {
+						t.Errorf("handlePostNotificationRulePreview() = ***%s***", diff)
+					}
+				})
+		})
+	}
+}
+
+// TestAPIHandler_Previews checks that the preview routes are mounted next to
+// the routes of checks and notification rules.
+func TestAPIHandler_Previews(t *testing.T) {
+	h := NewAPIHandler(&APIBackend{
+		HTTPErrorHandler: kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:           zaptest.NewLogger(t),
+		PreviewService:   previewMock.NewPreviewService(),
+	})
+
+	for _, path := range []string{previewsChecksPath, previewsNotificationRulesPath} {
+		t.Run(path, func(t *testing.T) {
+			// The authorizing preview service rejects the empty preview
+			// before authorizing it.
+			testttp.
+				Post(t, path, strings.NewReader(`{}`)).
+				Do(h).
+				ExpectStatus(http.StatusBadRequest)
+		})
+	}
+}
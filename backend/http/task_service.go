@@ -19,7 +19,10 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/task/backfill"
+	"github.com/influxdata/influxdb/v2/task/dag"
 	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/task/runhistory"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"go.uber.org/zap"
 )
@@ -38,6 +41,7 @@ type TaskBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	BackfillService            backfill.BackfillService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -53,6 +57,7 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		BackfillService:            b.BackfillService,
 	}
 }
 
@@ -69,6 +74,7 @@ type TaskHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	BackfillService            backfill.BackfillService
 }
 
 const (
@@ -85,6 +91,10 @@ const (
 	tasksIDRunsIDRetryPath = "/api/v2/tasks/:id/runs/:rid/retry"
 	tasksIDLabelsPath      = "/api/v2/tasks/:id/labels"
 	tasksIDLabelsIDPath    = "/api/v2/tasks/:id/labels/:lid"
+	tasksIDBackfillsPath   = "/api/v2/tasks/:id/backfills"
+	tasksIDBackfillsIDPath = "/api/v2/tasks/:id/backfills/:bid"
+	tasksIDDAGPath         = "/api/v2/tasks/:id/dag"
+	tasksIDStatsPath       = "/api/v2/tasks/:id/stats"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -101,6 +111,7 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		BackfillService:            b.BackfillService,
 	}
 
 	h.HandlerFunc("GET", prefixTasks, h.handleGetTasks)
@@ -143,6 +154,16 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("GET", tasksIDDAGPath, h.handleGetDAG)
+	h.HandlerFunc("GET", tasksIDStatsPath, h.handleGetRunStats)
+
+	if b.BackfillService != nil {
+		h.HandlerFunc("GET", tasksIDBackfillsPath, h.handleGetBackfills)
+		h.HandlerFunc("POST", tasksIDBackfillsPath, h.handlePostBackfill)
+		h.HandlerFunc("GET", tasksIDBackfillsIDPath, h.handleGetBackfill)
+		h.HandlerFunc("DELETE", tasksIDBackfillsIDPath, h.handleCancelBackfill)
+	}
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -167,9 +188,17 @@ type Task struct {
 	Description     string                 `json:"description,omitempty"`
 	Status          string                 `json:"status"`
 	Flux            string                 `json:"flux"`
+	Language        string                 `json:"language,omitempty"`
+	Query           string                 `json:"query,omitempty"`
+	Database        string                 `json:"database,omitempty"`
+	RetentionPolicy string                 `json:"retentionPolicy,omitempty"`
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
+	Location        string                 `json:"location,omitempty"`
 	Offset          string                 `json:"offset,omitempty"`
+	DependsOn       []platform.ID          `json:"dependsOn,omitempty"`
+	HistoryCount    int64                  `json:"historyCount,omitempty"`
+	HistoryAge      string                 `json:"historyAge,omitempty"`
 	LatestCompleted string                 `json:"latestCompleted,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
@@ -212,9 +241,17 @@ func NewFrontEndTask(t taskmodel.Task) Task {
 		Description:     t.Description,
 		Status:          t.Status,
 		Flux:            t.Flux,
+		Language:        t.Language,
+		Query:           t.Query,
+		Database:        t.Database,
+		RetentionPolicy: t.RetentionPolicy,
 		Every:           t.Every,
 		Cron:            t.Cron,
+		Location:        t.Location,
 		Offset:          offset,
+		DependsOn:       t.DependsOn,
+		HistoryCount:    t.HistoryCount,
+		HistoryAge:      t.HistoryAge,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
@@ -260,9 +297,17 @@ func convertTask(t Task) *taskmodel.Task {
 		Description:     t.Description,
 		Status:          t.Status,
 		Flux:            t.Flux,
+		Language:        t.Language,
+		Query:           t.Query,
+		Database:        t.Database,
+		RetentionPolicy: t.RetentionPolicy,
 		Every:           t.Every,
 		Cron:            t.Cron,
+		Location:        t.Location,
 		Offset:          offset,
+		DependsOn:       t.DependsOn,
+		HistoryCount:    t.HistoryCount,
+		HistoryAge:      t.HistoryAge,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
@@ -396,6 +441,9 @@ type httpRun struct {
 	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
 	RequestedAt  *time.Time      `json:"requestedAt,omitempty"`
 	Log          []taskmodel.Log `json:"log,omitempty"`
+	RetryOf      platform.ID     `json:"retryOf,omitempty"`
+	Attempt      int64           `json:"attempt,omitempty"`
+	ErrorClass   string          `json:"errorClass,omitempty"`
 }
 
 func newRunResponse(r taskmodel.Run) runResponse {
@@ -405,6 +453,9 @@ func newRunResponse(r taskmodel.Run) runResponse {
 		Status:       r.Status,
 		Log:          r.Log,
 		ScheduledFor: &r.ScheduledFor,
+		RetryOf:      r.RetryOf,
+		Attempt:      r.Attempt,
+		ErrorClass:   r.ErrorClass,
 	}
 
 	if !r.StartedAt.IsZero() {
@@ -417,23 +468,31 @@ func newRunResponse(r taskmodel.Run) runResponse {
 		run.RequestedAt = &r.RequestedAt
 	}
 
+	links := map[string]string{
+		"self":  fmt.Sprintf("/api/v2/tasks/%s/runs/%s", r.TaskID, r.ID),
+		"task":  fmt.Sprintf("/api/v2/tasks/%s", r.TaskID),
+		"logs":  fmt.Sprintf("/api/v2/tasks/%s/runs/%s/logs", r.TaskID, r.ID),
+		"retry": fmt.Sprintf("/api/v2/tasks/%s/runs/%s/retry", r.TaskID, r.ID),
+	}
+	if r.RetryOf.Valid() {
+		links["retryOf"] = fmt.Sprintf("/api/v2/tasks/%s/runs/%s", r.TaskID, r.RetryOf)
+	}
+
 	return runResponse{
-		Links: map[string]string{
-			"self":  fmt.Sprintf("/api/v2/tasks/%s/runs/%s", r.TaskID, r.ID),
-			"task":  fmt.Sprintf("/api/v2/tasks/%s", r.TaskID),
-			"logs":  fmt.Sprintf("/api/v2/tasks/%s/runs/%s/logs", r.TaskID, r.ID),
-			"retry": fmt.Sprintf("/api/v2/tasks/%s/runs/%s/retry", r.TaskID, r.ID),
-		},
+		Links:   links,
 		httpRun: run,
 	}
 }
 
 func convertRun(r httpRun) *taskmodel.Run {
 	run := &taskmodel.Run{
-		ID:     r.ID,
-		TaskID: r.TaskID,
-		Status: r.Status,
-		Log:    r.Log,
+		ID:         r.ID,
+		TaskID:     r.TaskID,
+		Status:     r.Status,
+		Log:        r.Log,
+		RetryOf:    r.RetryOf,
+		Attempt:    r.Attempt,
+		ErrorClass: r.ErrorClass,
 	}
 
 	if r.StartedAt != nil {
@@ -959,6 +1018,35 @@ func decodeGetLogsRequest(ctx context.Context, r *http.Request) (*getLogsRequest
 		req.filter.Run = id
 	}
 
+	qp := r.URL.Query()
+	req.filter.Status = qp.Get("status")
+	req.filter.ErrorClass = qp.Get("errorClass")
+	req.filter.Match = qp.Get("match")
+
+	var afterTime, beforeTime time.Time
+	if at := qp.Get("afterTime"); at != "" {
+		afterTime, err = time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.AfterTime = at
+	}
+
+	if bt := qp.Get("beforeTime"); bt != "" {
+		beforeTime, err = time.Parse(time.RFC3339, bt)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.BeforeTime = bt
+	}
+
+	if !afterTime.IsZero() && !beforeTime.IsZero() && !beforeTime.After(afterTime) {
+		return nil, &errors2.Error{
+			Code: errors2.EUnprocessableEntity,
+			Msg:  "beforeTime must be later than afterTime",
+		}
+	}
+
 	return req, nil
 }
 
@@ -1415,6 +1503,353 @@ func decodeRetryRunRequest(ctx context.Context, r *http.Request) (*retryRunReque
 	}, nil
 }
 
+type backfillResponse struct {
+	Links map[string]string `json:"links"`
+	*backfill.Backfill
+}
+
+func newBackfillResponse(b *backfill.Backfill) backfillResponse {
+	return backfillResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/backfills/%s", b.TaskID, b.ID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", b.TaskID),
+		},
+		Backfill: b,
+	}
+}
+
+type backfillsResponse struct {
+	Links     map[string]string  `json:"links"`
+	Backfills []backfillResponse `json:"backfills"`
+}
+
+func newBackfillsResponse(bs []*backfill.Backfill, taskID platform.ID) backfillsResponse {
+	r := backfillsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/backfills", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Backfills: make([]backfillResponse, 0, len(bs)),
+	}
+	for _, b := range bs {
+		r.Backfills = append(r.Backfills, newBackfillResponse(b))
+	}
+	return r
+}
+
+func (h *TaskHandler) handlePostBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePostBackfillRequest(ctx, r)
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.CreateBackfill(ctx, *req)
+	if err != nil {
+		err := &errors2.Error{
+			Err: err,
+			Msg: "failed to create backfill",
+		}
+		if err.Err == taskmodel.ErrTaskNotFound {
+			err.Code = errors2.ENotFound
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodePostBackfillRequest(ctx context.Context, r *http.Request) (*backfill.BackfillCreate, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var bc backfill.BackfillCreate
+	if err := json.NewDecoder(r.Body).Decode(&bc); err != nil {
+		return nil, err
+	}
+	bc.TaskID = taskID
+	return &bc, nil
+}
+
+func (h *TaskHandler) handleGetBackfills(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	backfills, err := h.BackfillService.FindBackfills(ctx, backfill.Filter{TaskID: &taskID})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillsResponse(backfills, taskID)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeBackfillRequest(ctx)
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.findTaskBackfill(ctx, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleCancelBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeBackfillRequest(ctx)
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if _, err := h.findTaskBackfill(ctx, req); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.CancelBackfill(ctx, req.BackfillID)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err: err,
+			Msg: "failed to cancel backfill",
+		}, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// findTaskBackfill returns the backfill of req, ensuring it is a backfill of the task of req.
+func (h *TaskHandler) findTaskBackfill(ctx context.Context, req *backfillRequest) (*backfill.Backfill, error) {
+	b, err := h.BackfillService.FindBackfillByID(ctx, req.BackfillID)
+	if err != nil {
+		return nil, err
+	}
+	if b.TaskID != req.TaskID {
+		return nil, backfill.ErrBackfillNotFound
+	}
+	return b, nil
+}
+
+type backfillRequest struct {
+	BackfillID, TaskID platform.ID
+}
+
+func decodeBackfillRequest(ctx context.Context) (*backfillRequest, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	backfillID, err := decodeIDFromCtx(ctx, "bid")
+	if err != nil {
+		return nil, err
+	}
+
+	return &backfillRequest{
+		BackfillID: backfillID,
+		TaskID:     taskID,
+	}, nil
+}
+
+type dagResponse struct {
+	Links map[string]string `json:"links"`
+	dag.DAG
+}
+
+func newDAGResponse(d *dag.DAG) dagResponse {
+	return dagResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/dag", d.TaskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", d.TaskID),
+		},
+		DAG: *d,
+	}
+}
+
+func (h *TaskHandler) handleGetDAG(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetDAGRequest(ctx, r)
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	d, err := dag.Find(ctx, h.TaskService, req.TaskID, req.Windows, time.Now().UTC())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newDAGResponse(d)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type getDAGRequest struct {
+	TaskID  platform.ID
+	Windows int
+}
+
+func decodeGetDAGRequest(ctx context.Context, r *http.Request) (*getDAGRequest, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &getDAGRequest{TaskID: taskID}
+	if windows := r.URL.Query().Get("windows"); windows != "" {
+		i, err := strconv.Atoi(windows)
+		if err != nil {
+			return nil, err
+		}
+		if i < 1 || i > dag.MaxWindows {
+			return nil, fmt.Errorf("windows must be between 1 and %d", dag.MaxWindows)
+		}
+		req.Windows = i
+	}
+	return req, nil
+}
+
+type runStatsResponse struct {
+	Links        map[string]string `json:"links"`
+	TaskID       platform.ID       `json:"taskID"`
+	Since        time.Time         `json:"since"`
+	Runs         int               `json:"runs"`
+	Succeeded    int               `json:"succeeded"`
+	Failed       int               `json:"failed"`
+	Canceled     int               `json:"canceled"`
+	SuccessRate  float64           `json:"successRate"`
+	MeanDuration string            `json:"meanDuration"`
+	P50Duration  string            `json:"p50Duration"`
+	P95Duration  string            `json:"p95Duration"`
+	MaxDuration  string            `json:"maxDuration"`
+}
+
+func newRunStatsResponse(s *runhistory.Stats) runStatsResponse {
+	return runStatsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/stats", s.TaskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", s.TaskID),
+			"runs": fmt.Sprintf("/api/v2/tasks/%s/runs", s.TaskID),
+		},
+		TaskID:       s.TaskID,
+		Since:        s.Since,
+		Runs:         s.Runs,
+		Succeeded:    s.Succeeded,
+		Failed:       s.Failed,
+		Canceled:     s.Canceled,
+		SuccessRate:  s.SuccessRate,
+		MeanDuration: s.MeanDuration.String(),
+		P50Duration:  s.P50Duration.String(),
+		P95Duration:  s.P95Duration.String(),
+		MaxDuration:  s.MaxDuration.String(),
+	}
+}
+
+func (h *TaskHandler) handleGetRunStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetRunStatsRequest(ctx, r)
+	if err != nil {
+		err = &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	s, err := runhistory.FindStats(ctx, h.TaskService, req.TaskID, req.Window, time.Now().UTC())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunStatsResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type getRunStatsRequest struct {
+	TaskID platform.ID
+	Window time.Duration
+}
+
+func decodeGetRunStatsRequest(ctx context.Context, r *http.Request) (*getRunStatsRequest, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &getRunStatsRequest{TaskID: taskID}
+	if window := r.URL.Query().Get("window"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 || d > runhistory.MaxWindow {
+			return nil, fmt.Errorf("window must be positive and at most %s", runhistory.MaxWindow)
+		}
+		req.Window = d
+	}
+	return req, nil
+}
+
 func (h *TaskHandler) populateTaskCreateOrg(ctx context.Context, tc *taskmodel.TaskCreate) error {
 	if tc.OrganizationID.Valid() && tc.Organization != "" {
 		return nil
@@ -1594,9 +2029,23 @@ func (t TaskService) FindLogs(ctx context.Context, filter taskmodel.LogFilter) (
 		urlPath = path.Join(taskIDRunIDPath(filter.Task, *filter.Run), "logs")
 	}
 
+	var params [][2]string
+	for _, p := range [][2]string{
+		{"status", filter.Status},
+		{"errorClass", filter.ErrorClass},
+		{"afterTime", filter.AfterTime},
+		{"beforeTime", filter.BeforeTime},
+		{"match", filter.Match},
+	} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+
 	var logs getLogsResponse
 	err := t.Client.
 		Get(urlPath).
+		QueryParams(params...).
 		DecodeJSON(&logs).
 		Do(ctx)
 
@@ -5,10 +5,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	"github.com/influxdata/influxdb/v2/http/points"
@@ -17,6 +19,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/otlp"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"go.uber.org/zap"
@@ -85,13 +88,29 @@ func (*WriteHandler) Prefix() string {
 
 const (
 	prefixWrite          = "/api/v2/write"
+	prefixOTLP           = "/api/v2/otlp"
+	prefixOTLPMetrics    = prefixOTLP + "/v1/metrics"
 	msgInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
 	msgInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
+	msgInvalidFormat     = "invalid format; valid formats are lineprotocol and prometheus"
 
 	opWriteHandler = "http/writeHandler"
 )
 
-// NewWriteHandler creates a new handler at /api/v2/write to receive line protocol.
+// Formats of the body of /api/v2/write requests, selected with the format
+// query parameter.
+const (
+	// writeFormatLineProtocol is the default format of writes.
+	writeFormatLineProtocol = "lineprotocol"
+	// writeFormatPrometheus is a Prometheus exposition format, either the
+	// text format or delimited protocol buffers as identified by the
+	// Content-Type of the request. Histograms and summaries are stored in
+	// the bounds format, see influxdb.ScraperMetricFormatBounds.
+	writeFormatPrometheus = "prometheus"
+)
+
+// NewWriteHandler creates a new handler at /api/v2/write to receive line protocol,
+// and at /api/v2/otlp/v1/metrics to receive OTLP/HTTP metrics.
 func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOption) *WriteHandler {
 	h := &WriteHandler{
 		HTTPErrorHandler:    b.HTTPErrorHandler,
@@ -109,6 +128,7 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 	}
 
 	h.router.HandlerFunc(http.MethodPost, prefixWrite, h.handleWrite)
+	h.router.HandlerFunc(http.MethodPost, prefixOTLPMetrics, h.handleOTLPMetrics)
 	return h
 }
 
@@ -135,8 +155,40 @@ func (h *WriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
 
+// handleWrite writes line protocol, or metrics in a Prometheus exposition
+// format when the format query parameter is "prometheus". The Content-Type of
+// the request is only used to tell the Prometheus formats apart, so clients
+// sending line protocol with any Content-Type are unaffected.
 func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
-	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
+	h.write(w, r, "WriteHandler", false, func(w http.ResponseWriter, _ *writeRequest) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// handleOTLPMetrics writes the metrics of an OTLP/HTTP export request, encoded as
+// protocol buffers or JSON, and responds with an empty export response.
+func (h *WriteHandler) handleOTLPMetrics(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, "WriteHandler.OTLPMetrics", true, func(w http.ResponseWriter, req *writeRequest) {
+		ctx := r.Context()
+		b, err := otlp.MarshalMetricsResponse(&otlp.ExportMetricsServiceResponse{}, req.ContentType)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+
+		mediatype, _, _ := mime.ParseMediaType(req.ContentType)
+		w.Header().Set("Content-Type", mediatype)
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(b); err != nil {
+			h.log.Debug("Failed to write OTLP response", zap.Error(err))
+		}
+	})
+}
+
+// write parses the request body into points and writes them to the requested
+// bucket. On success, respond is called to complete the response.
+func (h *WriteHandler) write(w http.ResponseWriter, r *http.Request, op string, otlpMetrics bool, respond func(http.ResponseWriter, *writeRequest)) {
+	span, r := tracing.ExtractFromHTTPRequest(r, op)
 	defer span.Finish()
 
 	ctx := r.Context()
@@ -182,13 +234,32 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	// TODO: Backport?
 	//opts := append([]models.ParserOption{}, h.parserOptions...)
 	//opts = append(opts, models.WithParserPrecision(req.Precision))
-	parsed, err := points.NewParser(req.Precision).Parse(ctx, org.ID, bucket.ID, req.Body)
+	parser := points.NewParser(req.Precision)
+	parser.ContentType = req.ContentType
+	parser.OTLP = otlpMetrics
+	parser.Prometheus = req.Format == writeFormatPrometheus
+	parsed, err := parser.Parse(ctx, org.ID, bucket.ID, req.Body)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
 	}
 	requestBytes = parsed.RawSize
 
+	// Points sent by a replication of another instance are replicated further along their
+	// path, which only the replications of the organization may set.
+	if path := influxdb.ParseReplicationPath(r.Header.Get(influxdb.ReplicationOriginHeader)); len(path) > 0 {
+		if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.ReplicationsResourceType, org.ID); err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EForbidden,
+				Op:   opWriteHandler,
+				Msg:  fmt.Sprintf("writes with the %s header require write permission for the replications of the organization", influxdb.ReplicationOriginHeader),
+				Err:  err,
+			}, sw)
+			return
+		}
+		ctx = influxdb.ContextWithReplicationPath(ctx, path)
+	}
+
 	if err := h.PointsWriter.WritePoints(ctx, org.ID, bucket.ID, parsed.Points); err != nil {
 		if partialErr, ok := err.(tsdb.PartialWriteError); ok {
 			h.HandleHTTPError(ctx, &errors.Error{
@@ -209,7 +280,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sw.WriteHeader(http.StatusNoContent)
+	respond(sw, req)
 }
 
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
@@ -238,10 +309,12 @@ func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID platf
 // writeRequest is a request object holding information about a batch of points
 // to be written to a Bucket.
 type writeRequest struct {
-	Org       string
-	Bucket    string
-	Precision string
-	Body      io.ReadCloser
+	Org         string
+	Bucket      string
+	Precision   string
+	Format      string
+	ContentType string
+	Body        io.ReadCloser
 }
 
 // decodeWriteRequest extracts information from an http.Request object to
@@ -261,6 +334,19 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		}
 	}
 
+	format := qp.Get("format")
+	switch format {
+	case "":
+		format = writeFormatLineProtocol
+	case writeFormatLineProtocol, writeFormatPrometheus:
+	default:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   "http/newWriteRequest",
+			Msg:  msgInvalidFormat,
+		}
+	}
+
 	bucket := qp.Get("bucket")
 	if bucket == "" {
 		return nil, &errors.Error{
@@ -277,10 +363,12 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 	}
 
 	return &writeRequest{
-		Bucket:    qp.Get("bucket"),
-		Org:       qp.Get("org"),
-		Precision: precision,
-		Body:      body,
+		Bucket:      qp.Get("bucket"),
+		Org:         qp.Get("org"),
+		Precision:   precision,
+		Format:      format,
+		ContentType: r.Header.Get("Content-Type"),
+		Body:        body,
 	}, nil
 }
 
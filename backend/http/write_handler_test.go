@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/stretchr/testify/require"
@@ -455,3 +456,218 @@ func testBucket(org, bucket string) *influxdb.Bucket {
 		OrgID: oid,
 	}
 }
+
+func TestWriteHandler_handleWrite_PrometheusExposition(t *testing.T) {
+	const body = `# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{handler="/api",le="0.1"} 3 1600000000000
+http_request_duration_seconds_bucket{handler="/api",le="1"} 5 1600000000000
+http_request_duration_seconds_bucket{handler="/api",le="+Inf"} 6 1600000000000
+http_request_duration_seconds_sum{handler="/api"} 4.5 1600000000000
+http_request_duration_seconds_count{handler="/api"} 6 1600000000000
+`
+	tests := []struct {
+		name   string
+		format string
+		status int
+		points []string
+	}{
+		{
+			name:   "prometheus format",
+			format: "&format=prometheus",
+			status: http.StatusNoContent,
+			points: []string{
+				`http_request_duration_seconds,handler=/api +Inf=6,0.1=3,1=5,count=6,sum=4.5 1600000000000000000`,
+			},
+		},
+		{
+			// The content type alone doesn't select the exposition parser.
+			name:   "line protocol by default",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid format",
+			format: "&format=json",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg("043e0780ee2b1000"), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+			}
+			pw := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pw,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000"+tt.format, strings.NewReader(body))
+			r.Header.Set("Content-Type", "text/plain; version=0.0.4")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var got []string
+			for _, p := range pw.Points {
+				got = append(got, p.String())
+			}
+			require.ElementsMatch(t, tt.points, got)
+		})
+	}
+}
+
+func TestWriteHandler_handleWrite_ReplicationOrigin(t *testing.T) {
+	orgID := influxtesting.MustIDBase16("043e0780ee2b1000")
+
+	replicationsWrite := bucketWritePermission("043e0780ee2b1000", "04504b356e23b000")
+	replicationsWrite.Permissions = append(replicationsWrite.Permissions, influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.ReplicationsResourceType, OrgID: &orgID},
+	})
+
+	tests := []struct {
+		name   string
+		auth   *influxdb.Authorization
+		status int
+		path   []string
+	}{
+		{
+			name:   "replication credentials",
+			auth:   replicationsWrite,
+			status: http.StatusNoContent,
+			path:   []string{"site-a", "site-b"},
+		},
+		{
+			// Other clients can't keep their writes from being replicated.
+			name:   "bucket write only",
+			auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg("043e0780ee2b1000"), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+			}
+			var path []string
+			pw := &mock.PointsWriter{WritePointsFn: func(ctx context.Context, _, _ platform.ID, _ []models.Point) error {
+				path, _ = influxdb.ReplicationPathFromContext(ctx)
+				return nil
+			}}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pw,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.auth)
+
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader("m1,t1=v1 f1=1"))
+			r.Header.Set(influxdb.ReplicationOriginHeader, "site-a,site-b")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			require.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestWriteHandler_handleOTLPMetrics(t *testing.T) {
+	const body = `{
+  "resourceMetrics": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "api"}}]},
+    "scopeMetrics": [{
+      "metrics": [{
+        "name": "requests",
+        "sum": {
+          "isMonotonic": true,
+          "aggregationTemporality": 2,
+          "dataPoints": [{"timeUnixNano": "1600000000000000000", "asInt": "42"}]
+        }
+      }]
+    }]
+  }]
+}`
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+	pw := &mock.PointsWriter{}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    kithttp.NewErrorHandler(zaptest.NewLogger(t)),
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+
+	t.Run("json", func(t *testing.T) {
+		pw.Points = nil
+		handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+		r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/otlp/v1/metrics?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Equal(t, "{}", w.Body.String())
+
+		require.Len(t, pw.Points, 1)
+		require.Equal(t, "requests,service.name=api counter=42 1600000000000000000", pw.Points[0].String())
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		pw.Points = nil
+		handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+		r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/otlp/v1/metrics?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader(body))
+		r.Header.Set("Content-Type", "text/plain")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		require.Empty(t, pw.Points)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		pw.Points = nil
+		handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "000000000000000a"))
+
+		r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/otlp/v1/metrics?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+		require.Empty(t, pw.Points)
+	})
+}
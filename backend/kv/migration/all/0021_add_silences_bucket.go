@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var silencesBucket = []byte("silencesv1")
+
+// Migration0021_AddSilencesBucket creates the bucket necessary for the silence service to operate.
+var Migration0021_AddSilencesBucket = migration.CreateBuckets(
+	"create silences bucket",
+	silencesBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var taskBackfillsBucket = []byte("taskbackfillsv1")
+
+// Migration0022_AddTaskBackfillsBucket creates the bucket necessary for the task backfill service to operate.
+var Migration0022_AddTaskBackfillsBucket = migration.CreateBuckets(
+	"create task backfills bucket",
+	taskBackfillsBucket,
+)
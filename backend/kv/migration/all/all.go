@@ -47,5 +47,9 @@ var Migrations = [...]migration.Spec{
 	Migration0019_AddRemotesReplicationsToTokens,
 	// add_remotes_replications_metrics_buckets
 	Migration0020_Add_remotes_replications_metrics_buckets,
+	// add silences bucket
+	Migration0021_AddSilencesBucket,
+	// add task backfills bucket
+	Migration0022_AddTaskBackfillsBucket,
 	// {{ do_not_edit . }}
 }
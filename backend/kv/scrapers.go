@@ -37,6 +37,13 @@ var (
 		Code: errors.EInvalid,
 		Msg:  "provided organization ID has invalid format",
 	}
+
+	// ErrInvalidScraperMetricFormat is used when the service was provided
+	// an unknown metric format.
+	ErrInvalidScraperMetricFormat = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided scraper metric format is invalid",
+	}
 )
 
 // UnexpectedScrapersBucketError is used when the error comes from an internal system.
@@ -165,6 +172,10 @@ func (s *Service) addTarget(ctx context.Context, tx Tx, target *influxdb.Scraper
 		return ErrInvalidScrapersBucketID
 	}
 
+	if !target.MetricFormat.Valid() {
+		return ErrInvalidScraperMetricFormat
+	}
+
 	target.ID = s.IDGenerator.ID()
 	if err := s.putTarget(ctx, tx, target); err != nil {
 		return err
@@ -227,6 +238,10 @@ func (s *Service) updateTarget(ctx context.Context, tx Tx, update *influxdb.Scra
 		return nil, ErrInvalidScraperID
 	}
 
+	if !update.MetricFormat.Valid() {
+		return nil, ErrInvalidScraperMetricFormat
+	}
+
 	target, err := s.findTargetByID(ctx, tx, update.ID)
 	if err != nil {
 		return nil, err
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -54,11 +55,18 @@ type basicKvTask struct {
 	Name            string            `json:"name"`
 	Description     string            `json:"description,omitempty"`
 	Status          string            `json:"status"`
+	Language        string            `json:"language,omitempty"`
+	Database        string            `json:"database,omitempty"`
+	RetentionPolicy string            `json:"retentionPolicy,omitempty"`
 	Every           string            `json:"every,omitempty"`
 	Cron            string            `json:"cron,omitempty"`
+	Location        string            `json:"location,omitempty"`
 	LastRunStatus   string            `json:"lastRunStatus,omitempty"`
 	LastRunError    string            `json:"lastRunError,omitempty"`
 	Offset          influxdb.Duration `json:"offset,omitempty"`
+	DependsOn       []platform.ID     `json:"dependsOn,omitempty"`
+	HistoryCount    int64             `json:"historyCount,omitempty"`
+	HistoryAge      string            `json:"historyAge,omitempty"`
 	LatestCompleted time.Time         `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time         `json:"latestScheduled,omitempty"`
 	LatestSuccess   time.Time         `json:"latestSuccess,omitempty"`
@@ -98,11 +106,18 @@ func (kv basicKvTask) ToInfluxDB() *taskmodel.Task {
 		Name:            kv.Name,
 		Description:     kv.Description,
 		Status:          kv.Status,
+		Language:        kv.Language,
+		Database:        kv.Database,
+		RetentionPolicy: kv.RetentionPolicy,
 		Every:           kv.Every,
 		Cron:            kv.Cron,
+		Location:        kv.Location,
 		LastRunStatus:   kv.LastRunStatus,
 		LastRunError:    kv.LastRunError,
 		Offset:          kv.Offset.Duration,
+		DependsOn:       kv.DependsOn,
+		HistoryCount:    kv.HistoryCount,
+		HistoryAge:      kv.HistoryAge,
 		LatestCompleted: kv.LatestCompleted,
 		LatestScheduled: kv.LatestScheduled,
 		LatestSuccess:   kv.LatestSuccess,
@@ -114,6 +129,7 @@ type kvTask struct {
 	basicKvTask
 	Organization string                 `json:"org"`
 	Flux         string                 `json:"flux"`
+	Query        string                 `json:"query,omitempty"`
 	CreatedAt    time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt    time.Time              `json:"updatedAt,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
@@ -123,6 +139,7 @@ func (kv kvTask) ToInfluxDB() *taskmodel.Task {
 	res := kv.basicKvTask.ToInfluxDB()
 	res.Organization = kv.Organization
 	res.Flux = kv.Flux
+	res.Query = kv.Query
 	res.CreatedAt = kv.CreatedAt
 	res.UpdatedAt = kv.UpdatedAt
 	res.Metadata = kv.Metadata
@@ -524,6 +541,12 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 		return nil, taskmodel.ErrTaskOptionParse(err)
 	}
 
+	if tc.Language == taskmodel.TaskLanguageInfluxQL {
+		if err := taskmodel.ValidateOptionsFlux(s.FluxLanguageService, tc.Flux); err != nil {
+			return nil, taskmodel.ErrInvalidInfluxQLTask(err)
+		}
+	}
+
 	if tc.Status == "" {
 		tc.Status = string(taskmodel.TaskActive)
 	}
@@ -540,13 +563,23 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 		Description:     tc.Description,
 		Status:          tc.Status,
 		Flux:            tc.Flux,
+		Language:        tc.Language,
+		Query:           tc.Query,
+		Database:        tc.Database,
+		RetentionPolicy: tc.RetentionPolicy,
 		Every:           opts.Every.String(),
 		Cron:            opts.Cron,
+		Location:        opts.Location,
+		DependsOn:       tc.DependsOn,
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
 	}
 
+	if err := s.validateDependencies(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
 	if opts.Offset != nil {
 		off, err := time.ParseDuration(opts.Offset.String())
 		if err != nil {
@@ -555,6 +588,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 		task.Offset = off
 
 	}
+	setTaskHistory(task, opts)
 
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
@@ -648,9 +682,15 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 		if err != nil {
 			return nil, taskmodel.ErrTaskOptionParse(err)
 		}
+		if task.IsInfluxQL() {
+			if err := taskmodel.ValidateOptionsFlux(s.FluxLanguageService, task.Flux); err != nil {
+				return nil, taskmodel.ErrInvalidInfluxQLTask(err)
+			}
+		}
 		task.Name = opts.Name
 		task.Every = opts.Every.String()
 		task.Cron = opts.Cron
+		task.Location = opts.Location
 
 		var off time.Duration
 		if opts.Offset != nil {
@@ -660,6 +700,7 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 			}
 		}
 		task.Offset = off
+		setTaskHistory(task, opts)
 		task.UpdatedAt = updatedAt
 	}
 
@@ -668,6 +709,33 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 		task.UpdatedAt = updatedAt
 	}
 
+	if upd.Query != nil || upd.Database != nil || upd.RetentionPolicy != nil {
+		if !task.IsInfluxQL() {
+			return nil, taskmodel.ErrInvalidInfluxQLTask(errors.New("query, database and retention policy are only supported by influxql tasks"))
+		}
+		if upd.Query != nil {
+			task.Query = *upd.Query
+		}
+		if upd.Database != nil {
+			task.Database = *upd.Database
+		}
+		if upd.RetentionPolicy != nil {
+			task.RetentionPolicy = *upd.RetentionPolicy
+		}
+		task.UpdatedAt = updatedAt
+	}
+
+	if upd.DependsOn != nil {
+		task.DependsOn = nil
+		if len(*upd.DependsOn) > 0 {
+			task.DependsOn = *upd.DependsOn
+		}
+		if err := s.validateDependencies(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		task.UpdatedAt = updatedAt
+	}
+
 	if upd.Status != nil && task.Status != *upd.Status {
 		task.Status = *upd.Status
 		task.UpdatedAt = updatedAt
@@ -767,6 +835,92 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 	return task, nil
 }
 
+// setTaskHistory sets the retention of the run history of task from its options.
+func setTaskHistory(task *taskmodel.Task, opts options.Options) {
+	task.HistoryCount = 0
+	if opts.HistoryCount != nil {
+		task.HistoryCount = *opts.HistoryCount
+	}
+	task.HistoryAge = ""
+	if opts.HistoryAge != nil {
+		task.HistoryAge = opts.HistoryAge.String()
+	}
+}
+
+// validateDependencies ensures the upstream tasks of task are in its
+// organization and that depending on them does not make it depend on itself.
+func (s *Service) validateDependencies(ctx context.Context, tx Tx, task *taskmodel.Task) error {
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+
+	seen := make(map[platform.ID]bool, len(task.DependsOn))
+	dependsOn := make([]platform.ID, 0, len(task.DependsOn))
+	for _, id := range task.DependsOn {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		dependsOn = append(dependsOn, id)
+
+		up, err := s.findTaskByID(ctx, tx, id, true)
+		if err == taskmodel.ErrTaskNotFound {
+			return taskmodel.ErrTaskDependencyNotFound(id)
+		}
+		if err != nil {
+			return err
+		}
+		if up.GetOrgID() != task.OrganizationID {
+			return taskmodel.ErrTaskDependencyNotFound(id)
+		}
+	}
+	task.DependsOn = dependsOn
+
+	// walk the tasks upstream of task; finding task among them is a cycle
+	visited := make(map[platform.ID]bool)
+	queue := append([]platform.ID(nil), task.DependsOn...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == task.ID {
+			return taskmodel.ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		up, err := s.findTaskByID(ctx, tx, id, true)
+		if err == taskmodel.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		queue = append(queue, up.ToInfluxDB().DependsOn...)
+	}
+	return nil
+}
+
+// findDependents returns the tasks of the organization that depend on the task with id.
+func (s *Service) findDependents(ctx context.Context, tx Tx, orgID, id platform.ID) ([]*taskmodel.Task, error) {
+	tasks, _, err := s.findTasksByOrg(ctx, tx, orgID, taskmodel.TaskFilter{Limit: math.MaxInt})
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []*taskmodel.Task
+	for _, t := range tasks {
+		for _, up := range t.DependsOn {
+			if up == id {
+				dependents = append(dependents, t)
+				break
+			}
+		}
+	}
+	return dependents, nil
+}
+
 // DeleteTask removes a task by ID and purges all associated data and scheduled runs.
 func (s *Service) DeleteTask(ctx context.Context, id platform.ID) error {
 	err := s.kv.Update(ctx, func(tx Tx) error {
@@ -805,6 +959,15 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id platform.ID) error {
 		return err
 	}
 
+	// the tasks depending on the task would never run again
+	dependents, err := s.findDependents(ctx, tx, task.GetOrgID(), task.GetID())
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 {
+		return taskmodel.ErrTaskHasDependents
+	}
+
 	// remove the orgs index
 	orgKey, err := taskOrgKey(task.GetOrgID(), task.GetID())
 	if err != nil {
@@ -886,6 +1049,13 @@ func (s *Service) findLogs(ctx context.Context, tx Tx, filter taskmodel.LogFilte
 		if err != nil {
 			return nil, 0, err
 		}
+		if filter.IsSearch() {
+			logs, err := filter.Search([]*taskmodel.Run{r})
+			if err != nil {
+				return nil, 0, taskmodel.ErrInvalidLogFilter(err)
+			}
+			return logs, len(logs), nil
+		}
 		rtn := make([]*taskmodel.Log, len(r.Log))
 		for i := 0; i < len(r.Log); i++ {
 			rtn[i] = &r.Log[i]
@@ -897,6 +1067,13 @@ func (s *Service) findLogs(ctx context.Context, tx Tx, filter taskmodel.LogFilte
 	if err != nil {
 		return nil, 0, err
 	}
+	if filter.IsSearch() {
+		logs, err := filter.Search(runs)
+		if err != nil {
+			return nil, 0, taskmodel.ErrInvalidLogFilter(err)
+		}
+		return logs, len(logs), nil
+	}
 	var logs []*taskmodel.Log
 	for _, run := range runs {
 		for i := 0; i < len(run.Log); i++ {
@@ -1100,11 +1277,14 @@ func (s *Service) retryRun(ctx context.Context, tx Tx, taskID, runID platform.ID
 		return nil, err
 	}
 
+	r.RetryOf = r.ID
+	r.Attempt = max(r.Attempt, 1) + 1
 	r.ID = s.IDGenerator.ID()
 	r.Status = taskmodel.RunScheduled.String()
 	r.StartedAt = time.Time{}
 	r.FinishedAt = time.Time{}
 	r.RequestedAt = time.Time{}
+	r.Log = []taskmodel.Log{}
 
 	// add a clean copy of the run to the manual runs
 	bucket, err := tx.Bucket(taskRunBucket)
@@ -1501,6 +1681,41 @@ func (s *Service) finishRun(ctx context.Context, tx Tx, taskID, runID platform.I
 	return r, nil
 }
 
+// UpdateRunErrorClass sets the class of the error of a failed run.
+func (s *Service) UpdateRunErrorClass(ctx context.Context, taskID, runID platform.ID, class string) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.updateRunErrorClass(ctx, tx, taskID, runID, class)
+	})
+}
+
+func (s *Service) updateRunErrorClass(ctx context.Context, tx Tx, taskID, runID platform.ID, class string) error {
+	run, err := s.findRunByID(ctx, tx, taskID, runID)
+	if err != nil {
+		return err
+	}
+	run.ErrorClass = class
+
+	b, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	runBytes, err := json.Marshal(run)
+	if err != nil {
+		return taskmodel.ErrInternalTaskServiceError(err)
+	}
+
+	runKey, err := taskRunKey(taskID, run.ID)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(runKey, runBytes); err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	return nil
+}
+
 // UpdateRunState sets the run state at the respective time.
 func (s *Service) UpdateRunState(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error {
 	err := s.kv.Update(ctx, func(tx Tx) error {
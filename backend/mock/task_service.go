@@ -131,13 +131,14 @@ func (s *TaskService) ForceRun(ctx context.Context, taskID platform.ID, schedule
 }
 
 type TaskControlService struct {
-	CreateRunFn        func(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error)
-	CurrentlyRunningFn func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
-	ManualRunsFn       func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
-	StartManualRunFn   func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
-	FinishRunFn        func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
-	UpdateRunStateFn   func(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error
-	AddRunLogFn        func(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
+	CreateRunFn           func(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error)
+	CurrentlyRunningFn    func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
+	ManualRunsFn          func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
+	StartManualRunFn      func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
+	FinishRunFn           func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
+	UpdateRunStateFn      func(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error
+	UpdateRunErrorClassFn func(ctx context.Context, taskID, runID platform.ID, class string) error
+	AddRunLogFn           func(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
 }
 
 func (tcs *TaskControlService) CreateRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
@@ -158,6 +159,9 @@ func (tcs *TaskControlService) FinishRun(ctx context.Context, taskID, runID plat
 func (tcs *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error {
 	return tcs.UpdateRunStateFn(ctx, taskID, runID, when, state)
 }
+func (tcs *TaskControlService) UpdateRunErrorClass(ctx context.Context, taskID, runID platform.ID, class string) error {
+	return tcs.UpdateRunErrorClassFn(ctx, taskID, runID, class)
+}
 func (tcs *TaskControlService) AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error {
 	return tcs.AddRunLogFn(ctx, taskID, runID, when, log)
 }
@@ -0,0 +1,211 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Absent)(nil)
+
+// Absent is the absent series check, a deadman check of each series. The
+// series are discovered from the values of the query over the stale time,
+// and a series is absent when it hasn't reported since TimeSince while
+// another series of its group has.
+type Absent struct {
+	Base
+	TimeSince *notification.Duration `json:"timeSince,omitempty"`
+	StaleTime *notification.Duration `json:"staleTime,omitempty"`
+	// GroupBy are the columns of the groups of series. All the series are
+	// in a single group without them.
+	GroupBy []string                `json:"groupBy,omitempty"`
+	Level   notification.CheckLevel `json:"level"`
+}
+
+// Type returns the type of the check.
+func (c Absent) Type() string {
+	return "absent"
+}
+
+// Valid returns error if something is invalid.
+func (c Absent) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := c.Base.Valid(lang); err != nil {
+		return err
+	}
+	if c.TimeSince == nil || len(c.TimeSince.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Absent TimeSince must exist",
+		}
+	}
+	if c.StaleTime == nil || len(c.StaleTime.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Absent StaleTime must exist",
+		}
+	}
+	if c.StaleTime.TimeDuration() <= c.TimeSince.TimeDuration() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Absent StaleTime should be greater than TimeSince",
+		}
+	}
+	for _, col := range c.GroupBy {
+		if col == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Absent GroupBy column can't be empty",
+			}
+		}
+	}
+	if c.Level == notification.Unknown {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Absent Level is invalid",
+		}
+	}
+	return nil
+}
+
+// GenerateFlux returns a flux script for the absent series check provided.
+func (c Absent) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	f, err := c.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return astutil.Format(f)
+}
+
+// GenerateFluxAST returns a flux AST for the absent series check provided. If
+// there are any errors in the flux that the user provided the function will
+// return an error for each error found when the script is parsed.
+func (c Absent) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.File, error) {
+	if c.TimeSince == nil || c.StaleTime == nil {
+		return nil, fmt.Errorf("absent check requires timeSince and staleTime")
+	}
+
+	p, err := query.Parse(lang, c.Query.Text)
+	if p == nil {
+		return nil, err
+	}
+	removeAggregateWindow(p)
+	replaceDurationsWithEvery(p, c.StaleTime)
+	removeStopFromRange(p)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// The statements of the check are appended to the file of the query, whose pipeline
+	// becomes the data searched for series that stopped reporting, so the query must be
+	// a single file.
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "experimental", "join")...)
+	f.Body = append(f.Body, c.generateFluxASTBody()...)
+
+	return f, nil
+}
+
+func (c Absent) generateFluxASTBody() []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("absent"))
+	statements = append(statements, c.generateLevelFn())
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements, c.generateFluxASTSeries()...)
+	return append(statements, c.generateFluxASTChecksFunction())
+}
+
+func (c Absent) generateLevelFn() ast.Statement {
+	fn := flux.Function(flux.FunctionParams("r"), flux.Member("r", "dead"))
+
+	lvl := strings.ToLower(c.Level.String())
+
+	return flux.DefineVariable(lvl, fn)
+}
+
+// generateFluxASTSeries marks each series of data as dead when it hasn't
+// reported since TimeSince, and counts the series of each group still alive.
+func (c Absent) generateFluxASTSeries() []ast.Statement {
+	cols := make([]ast.Expression, 0, len(c.GroupBy))
+	for _, col := range c.GroupBy {
+		cols = append(cols, flux.String(col))
+	}
+
+	dur := (*ast.DurationLiteral)(c.TimeSince)
+	now := flux.Call(flux.Identifier("now"), flux.Object())
+	sub := flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("from", now), flux.Property("d", dur)))
+
+	series := flux.Pipe(
+		flux.Identifier("data"),
+		flux.Call(flux.Member("monitor", "deadman"), flux.Object(flux.Property("t", sub))),
+		flux.Call(flux.Identifier("group"), flux.Object(flux.Property("columns", flux.Array(cols...)))),
+	)
+	alive := flux.Pipe(
+		flux.Identifier("series"),
+		filterCall(flux.Not(flux.Member("r", "dead"))),
+		flux.Call(flux.Identifier("count"), flux.Object()),
+	)
+
+	return []ast.Statement{
+		flux.DefineVariable("series", series),
+		flux.DefineVariable("alive", alive),
+	}
+}
+
+// generateFluxASTChecksFunction checks the series of the groups with at
+// least one series alive, so that a group that stopped reporting altogether
+// doesn't mark all its series as absent.
+func (c Absent) generateFluxASTChecksFunction() ast.Statement {
+	return flux.ExpressionStatement(flux.Pipe(
+		flux.Call(flux.Member("join", "inner"), flux.Object(
+			flux.Property("left", flux.Identifier("series")),
+			flux.Property("right", flux.Identifier("alive")),
+			flux.Property("on", flux.Function(flux.FunctionParams("l", "r"), flux.Bool(true))),
+			flux.Property("as", flux.Function(flux.FunctionParams("l", "r"), flux.ObjectWith("l",
+				flux.Property("_alive", flux.Member("r", "_value")),
+			))),
+		)),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+func (c Absent) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	lvl := strings.ToLower(c.Level.String())
+	objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+type absentAlias Absent
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Absent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			absentAlias
+			Type string `json:"type"`
+		}{
+			absentAlias: absentAlias(c),
+			Type:        c.Type(),
+		})
+}
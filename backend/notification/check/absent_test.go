@@ -0,0 +1,126 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbsent_GenerateFlux(t *testing.T) {
+	type args struct {
+		absent check.Absent
+	}
+	type wants struct {
+		script string
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "grouped by host with aggregateWindow",
+			args: args{
+				absent: check.Absent{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("1m"),
+						StatusMessageTemplate: "whoa! {r[\"cpu\"]} is gone",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(fn: mean, every: 1m) |> yield()`,
+						},
+					},
+					TimeSince: mustDuration("5m"),
+					StaleTime: mustDuration("1h"),
+					GroupBy:   []string{"host"},
+					Level:     notification.Critical,
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "experimental"
+import "join"
+
+data = from(bucket: "foo") |> range(start: -1h) |> filter(fn: (r) => r._field == "usage_user")
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "absent", tags: {aaa: "vaaa"}}
+crit = (r) => r["dead"]
+messageFn = (r) => "whoa! {r[\"cpu\"]} is gone"
+series =
+    data
+        |> monitor["deadman"](t: experimental["subDuration"](from: now(), d: 5m))
+        |> group(columns: ["host"])
+alive = series |> filter(fn: (r) => not r["dead"]) |> count()
+
+join["inner"](left: series, right: alive, on: (l, r) => true, as: (l, r) => ({l with _alive: r["_value"]}))
+    |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			},
+		},
+		{
+			name: "without group by",
+			args: args{
+				absent: check.Absent{
+					Base: check.Base{
+						ID:                    10,
+						Name:                  "moo",
+						Every:                 mustDuration("1m"),
+						StatusMessageTemplate: "whoa! {r[\"host\"]} is gone",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._measurement == "system" and r._field == "uptime")`,
+						},
+					},
+					TimeSince: mustDuration("90s"),
+					StaleTime: mustDuration("1d"),
+					Level:     notification.Warn,
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "experimental"
+import "join"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -1d)
+        |> filter(fn: (r) => r._measurement == "system" and r._field == "uptime")
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "absent", tags: {}}
+warn = (r) => r["dead"]
+messageFn = (r) => "whoa! {r[\"host\"]} is gone"
+series =
+    data
+        |> monitor["deadman"](t: experimental["subDuration"](from: now(), d: 90s))
+        |> group(columns: [])
+alive = series |> filter(fn: (r) => not r["dead"]) |> count()
+
+join["inner"](left: series, right: alive, on: (l, r) => true, as: (l, r) => ({l with _alive: r["_value"]}))
+    |> monitor["check"](data: check, messageFn: messageFn, warn: warn)
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.args.absent.GenerateFlux(fluxlang.DefaultService)
+			require.NoError(t, err)
+			assert.Equal(t, itesting.FormatFluxString(t, tt.wants.script), s)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Anomaly)(nil)
+
+// Methods of computing the baseline of an anomaly check.
+const (
+	// AnomalyMethodStdDev is the mean and standard deviation of the baseline.
+	AnomalyMethodStdDev = "stddev"
+	// AnomalyMethodMAD is the median and median absolute deviation of the
+	// baseline, which is less sensitive to outliers in the baseline.
+	AnomalyMethodMAD = "mad"
+)
+
+// madScale scales the median absolute deviation to be comparable with the
+// standard deviation of normally distributed data.
+const madScale = 1.4826
+
+// Anomaly is the anomaly detection check. It compares the most recent value
+// of each series with a baseline of its values over the lookback period, and
+// sets its level when the value deviates from the center of the baseline by
+// more than Deviations times its spread.
+type Anomaly struct {
+	Base
+	// Method is how the center and spread of the baseline are computed, one
+	// of stddev (the default) or mad.
+	Method string `json:"method,omitempty"`
+	// Lookback is how far back the values of the query are covered by the baseline.
+	Lookback *notification.Duration `json:"lookback,omitempty"`
+	// Seasonality restricts the baseline to the values at the same phase of
+	// each period, such as the same time of day with a seasonality of 1d.
+	Seasonality *notification.Duration  `json:"seasonality,omitempty"`
+	Deviations  float64                 `json:"deviations"`
+	Level       notification.CheckLevel `json:"level"`
+}
+
+// Type returns the type of the check.
+func (a Anomaly) Type() string {
+	return "anomaly"
+}
+
+// Valid returns error if something is invalid.
+func (a Anomaly) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := a.Base.Valid(lang); err != nil {
+		return err
+	}
+	if a.Method != "" && a.Method != AnomalyMethodStdDev && a.Method != AnomalyMethodMAD {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Anomaly Method must be one of %s or %s", AnomalyMethodStdDev, AnomalyMethodMAD),
+		}
+	}
+	if a.Lookback == nil || len(a.Lookback.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly Lookback must exist",
+		}
+	}
+	if a.Lookback.TimeDuration() <= a.Every.TimeDuration() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly Lookback should be greater than the interval",
+		}
+	}
+	if a.Seasonality != nil {
+		if len(a.Seasonality.Values) == 0 {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Seasonality can't be empty",
+			}
+		}
+		if s := a.Seasonality.TimeDuration(); s < a.Every.TimeDuration() || s >= a.Lookback.TimeDuration() {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Seasonality should be at least the interval and less than the lookback",
+			}
+		}
+	}
+	if a.Deviations <= 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly Deviations must be greater than 0",
+		}
+	}
+	if a.Level == notification.Unknown {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly Level is invalid",
+		}
+	}
+	return nil
+}
+
+// GenerateFlux returns a flux script for the anomaly check provided.
+func (a Anomaly) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	f, err := a.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return astutil.Format(f)
+}
+
+// GenerateFluxAST returns a flux AST for the anomaly check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (a Anomaly) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.File, error) {
+	if a.Every == nil || a.Lookback == nil {
+		return nil, fmt.Errorf("anomaly check requires every and lookback")
+	}
+
+	p, err := query.Parse(lang, a.Query.Text)
+	if p == nil {
+		return nil, err
+	}
+	replaceDurationsWithEvery(p, a.Every)
+	replaceRangeStart(p, a.Lookback)
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// The statements of the check are appended to the file of the query, whose pipeline
+	// becomes data, so the query must be a single file.
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "experimental", "join", "math")...)
+	f.Body = append(f.Body, a.generateFluxASTBody(fields[0])...)
+
+	return f, nil
+}
+
+// replaceRangeStart sets the start of the range of the query to -d.
+func replaceRangeStart(pkg *ast.Package, d *notification.Duration) {
+	ast.Visit(pkg, func(n ast.Node) {
+		if call, ok := n.(*ast.CallExpression); ok {
+			if id, ok := call.Callee.(*ast.Identifier); ok && id.Name == "range" {
+				for _, args := range call.Arguments {
+					if obj, ok := args.(*ast.ObjectExpression); ok {
+						for _, prop := range obj.Properties {
+							if prop.Key.Key() == "start" {
+								start := (ast.DurationLiteral)(*d)
+								prop.Value = flux.Negative(&start)
+							}
+						}
+					}
+				}
+			}
+		}
+	})
+}
+
+func (a Anomaly) generateFluxASTBody(field string) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, a.generateTaskOption())
+	statements = append(statements, a.generateFluxASTCheckDefinition("anomaly"))
+	statements = append(statements, a.generateLevelFn())
+	statements = append(statements, a.generateFluxASTMessageFunction())
+	statements = append(statements, a.generateFluxASTBaseline()...)
+	return append(statements, a.generateFluxASTChecksFunction(field))
+}
+
+func (a Anomaly) generateLevelFn() ast.Statement {
+	fn := flux.Function(flux.FunctionParams("r"), flux.GreaterThan(flux.Member("r", "_deviation"), flux.Float(a.Deviations)))
+
+	lvl := strings.ToLower(a.Level.String())
+
+	return flux.DefineVariable(lvl, fn)
+}
+
+// generateFluxASTBaseline splits data into the current values, after the
+// start of the last interval, and the baseline, before it. The center and
+// spread of the baseline are joined into stats.
+func (a Anomaly) generateFluxASTBaseline() []ast.Statement {
+	every := (*ast.DurationLiteral)(a.Every)
+	now := flux.Call(flux.Identifier("now"), flux.Object())
+	cutoff := flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("from", now), flux.Property("d", every)))
+
+	current := flux.Pipe(
+		flux.Identifier("data"),
+		filterCall(flux.GreaterThan(flux.Member("r", "_time"), flux.Identifier("cutoff"))),
+		flux.Call(flux.Identifier("last"), flux.Object()),
+	)
+
+	baselineCalls := []*ast.CallExpression{
+		filterCall(flux.LessThanEqual(flux.Member("r", "_time"), flux.Identifier("cutoff"))),
+	}
+	if a.Seasonality != nil {
+		// keep the values at the same phase of each season as now
+		phase := flux.Modulo(
+			flux.Subtract(intCall(now), intCall(flux.Member("r", "_time"))),
+			intCall((*ast.DurationLiteral)(a.Seasonality)),
+		)
+		baselineCalls = append(baselineCalls, filterCall(flux.LessThan(phase, intCall(every))))
+	}
+	baseline := flux.Pipe(flux.Identifier("data"), baselineCalls...)
+
+	var center, spread ast.Expression
+	switch a.Method {
+	case AnomalyMethodMAD:
+		center = flux.Pipe(flux.Identifier("baseline"), flux.Call(flux.Identifier("median"), flux.Object()))
+		absDev := flux.Call(flux.Member("math", "abs"), flux.Object(
+			flux.Property("x", flux.Subtract(flux.Member("l", "_value"), flux.Member("r", "_value"))),
+		))
+		spread = flux.Pipe(
+			joinCall("baseline", "center", flux.ObjectWith("l", flux.Property("_value", absDev))),
+			flux.Call(flux.Identifier("median"), flux.Object()),
+			mapCall(flux.ObjectWith("r", flux.Property("_value", flux.Multiply(flux.Member("r", "_value"), flux.Float(madScale))))),
+		)
+	default:
+		center = flux.Pipe(flux.Identifier("baseline"), flux.Call(flux.Identifier("mean"), flux.Object()))
+		spread = flux.Pipe(flux.Identifier("baseline"), flux.Call(flux.Identifier("stddev"), flux.Object()))
+	}
+
+	stats := joinCall("center", "spread", flux.ObjectWith("l",
+		flux.Property("_center", flux.Member("l", "_value")),
+		flux.Property("_spread", flux.Member("r", "_value")),
+	))
+
+	return []ast.Statement{
+		flux.DefineVariable("cutoff", cutoff),
+		flux.DefineVariable("current", current),
+		flux.DefineVariable("baseline", baseline),
+		flux.DefineVariable("center", center),
+		flux.DefineVariable("spread", spread),
+		flux.DefineVariable("stats", stats),
+	}
+}
+
+// generateFluxASTChecksFunction joins the current values with the stats of
+// their series, and checks their deviation from the center of the baseline
+// in units of its spread. The value is also set in a column named after the
+// field for the status message template.
+func (a Anomaly) generateFluxASTChecksFunction(field string) ast.Statement {
+	value := flux.Member("r", "_value")
+	center := flux.Member("r", "_center")
+	spread := flux.Member("r", "_spread")
+
+	// values off a baseline without spread deviate infinitely
+	deviation := flux.If(
+		flux.GreaterThan(spread, flux.Float(0)),
+		flux.Divide(
+			flux.Call(flux.Member("math", "abs"), flux.Object(flux.Property("x", flux.Subtract(value, center)))),
+			spread,
+		),
+		flux.If(
+			flux.Equal(value, center),
+			flux.Float(0),
+			flux.Call(flux.Member("math", "mInf"), flux.Object(flux.Property("sign", flux.Integer(1)))),
+		),
+	)
+
+	return flux.ExpressionStatement(flux.Pipe(
+		joinCall("current", "stats", flux.ObjectWith("l",
+			flux.Property("_center", flux.Member("r", "_center")),
+			flux.Property("_spread", flux.Member("r", "_spread")),
+		)),
+		mapCall(flux.ObjectWith("r",
+			flux.Dictionary(field, value),
+			flux.Property("_deviation", deviation),
+		)),
+		a.generateFluxASTChecksCall(),
+	))
+}
+
+func (a Anomaly) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	lvl := strings.ToLower(a.Level.String())
+	objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+// filterCall returns a call to filter with fn returning e for r.
+func filterCall(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("filter"), flux.Object(
+		flux.Property("fn", flux.Function(flux.FunctionParams("r"), e)),
+	))
+}
+
+// mapCall returns a call to map with fn returning o for r.
+func mapCall(o *ast.ObjectExpression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("map"), flux.Object(
+		flux.Property("fn", flux.Function(flux.FunctionParams("r"), o)),
+	))
+}
+
+// intCall returns a call converting e to an int.
+func intCall(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", e)))
+}
+
+// joinCall returns an inner join of the tables of left and right with the
+// same group key, with the record of l and r as o. Tables are matched on
+// _start, as both sides are derived from the same range of data.
+func joinCall(left, right string, o *ast.ObjectExpression) *ast.CallExpression {
+	return flux.Call(flux.Member("join", "inner"), flux.Object(
+		flux.Property("left", flux.Identifier(left)),
+		flux.Property("right", flux.Identifier(right)),
+		flux.Property("on", flux.Function(flux.FunctionParams("l", "r"), flux.Equal(flux.Member("l", "_start"), flux.Member("r", "_start")))),
+		flux.Property("as", flux.Function(flux.FunctionParams("l", "r"), o)),
+	))
+}
+
+type anomalyAlias Anomaly
+
+// MarshalJSON implement json.Marshaler interface.
+func (a Anomaly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			anomalyAlias
+			Type string `json:"type"`
+		}{
+			anomalyAlias: anomalyAlias(a),
+			Type:         a.Type(),
+		})
+}
@@ -0,0 +1,198 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomaly_GenerateFlux(t *testing.T) {
+	type args struct {
+		anomaly check.Anomaly
+	}
+	type wants struct {
+		script string
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "stddev with yield and stop",
+			args: args{
+				anomaly: check.Anomaly{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("1h"),
+						StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean) |> yield()`,
+						},
+					},
+					Lookback:   mustDuration("7d"),
+					Deviations: 3,
+					Level:      notification.Critical,
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "experimental"
+import "join"
+import "math"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -7d)
+        |> filter(fn: (r) => r._field == "usage_user")
+        |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 1h}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "anomaly", tags: {aaa: "vaaa"}}
+crit = (r) => r["_deviation"] > 3.0
+messageFn = (r) => "whoa! {r[\"usage_user\"]}"
+cutoff = experimental["subDuration"](from: now(), d: 1h)
+current = data |> filter(fn: (r) => r["_time"] > cutoff) |> last()
+baseline = data |> filter(fn: (r) => r["_time"] <= cutoff)
+center = baseline |> mean()
+spread = baseline |> stddev()
+stats =
+    join["inner"](
+        left: center,
+        right: spread,
+        on: (l, r) => l["_start"] == r["_start"],
+        as: (l, r) => ({l with _center: l["_value"], _spread: r["_value"]}),
+    )
+
+join["inner"](
+    left: current,
+    right: stats,
+    on: (l, r) => l["_start"] == r["_start"],
+    as: (l, r) => ({l with _center: r["_center"], _spread: r["_spread"]}),
+)
+    |> map(
+        fn: (r) =>
+            ({r with
+                "usage_user": r["_value"],
+                _deviation:
+                    if r["_spread"] > 0.0 then
+                        math["abs"](x: r["_value"] - r["_center"]) / r["_spread"]
+                    else if r["_value"] == r["_center"] then
+                        0.0
+                    else
+                        math["mInf"](sign: 1),
+            }),
+    )
+    |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			},
+		},
+		{
+			name: "mad with seasonality",
+			args: args{
+				anomaly: check.Anomaly{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("1h"),
+						StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean)`,
+						},
+					},
+					Method:      check.AnomalyMethodMAD,
+					Lookback:    mustDuration("28d"),
+					Seasonality: mustDuration("1d"),
+					Deviations:  2.5,
+					Level:       notification.Warn,
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "experimental"
+import "join"
+import "math"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -28d)
+        |> filter(fn: (r) => r._field == "usage_user")
+        |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 1h}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "anomaly", tags: {aaa: "vaaa"}}
+warn = (r) => r["_deviation"] > 2.5
+messageFn = (r) => "whoa! {r[\"usage_user\"]}"
+cutoff = experimental["subDuration"](from: now(), d: 1h)
+current = data |> filter(fn: (r) => r["_time"] > cutoff) |> last()
+baseline =
+    data
+        |> filter(fn: (r) => r["_time"] <= cutoff)
+        |> filter(fn: (r) => (int(v: now()) - int(v: r["_time"])) % int(v: 1d) < int(v: 1h))
+center = baseline |> median()
+spread =
+    join["inner"](
+        left: baseline,
+        right: center,
+        on: (l, r) => l["_start"] == r["_start"],
+        as: (l, r) => ({l with _value: math["abs"](x: l["_value"] - r["_value"])}),
+    )
+        |> median()
+        |> map(fn: (r) => ({r with _value: r["_value"] * 1.4826}))
+stats =
+    join["inner"](
+        left: center,
+        right: spread,
+        on: (l, r) => l["_start"] == r["_start"],
+        as: (l, r) => ({l with _center: l["_value"], _spread: r["_value"]}),
+    )
+
+join["inner"](
+    left: current,
+    right: stats,
+    on: (l, r) => l["_start"] == r["_start"],
+    as: (l, r) => ({l with _center: r["_center"], _spread: r["_spread"]}),
+)
+    |> map(
+        fn: (r) =>
+            ({r with
+                "usage_user": r["_value"],
+                _deviation:
+                    if r["_spread"] > 0.0 then
+                        math["abs"](x: r["_value"] - r["_center"]) / r["_spread"]
+                    else if r["_value"] == r["_center"] then
+                        0.0
+                    else
+                        math["mInf"](sign: 1),
+            }),
+    )
+    |> monitor["check"](data: check, messageFn: messageFn, warn: warn)
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.args.anomaly.GenerateFlux(fluxlang.DefaultService)
+			require.NoError(t, err)
+			assert.Equal(t, itesting.FormatFluxString(t, tt.wants.script), s)
+		})
+	}
+}
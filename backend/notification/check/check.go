@@ -202,6 +202,10 @@ var typeToCheck = map[string](func() influxdb.Check){
 	"deadman":   func() influxdb.Check { return &Deadman{} },
 	"threshold": func() influxdb.Check { return &Threshold{} },
 	"custom":    func() influxdb.Check { return &Custom{} },
+	"anomaly":   func() influxdb.Check { return &Anomaly{} },
+	"composite": func() influxdb.Check { return &Composite{} },
+	"rate":      func() influxdb.Check { return &Rate{} },
+	"absent":    func() influxdb.Check { return &Absent{} },
 }
 
 // UnmarshalJSON will convert
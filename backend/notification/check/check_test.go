@@ -144,6 +144,177 @@ func TestValidCheck(t *testing.T) {
 				Msg:  "range threshold min can't be larger than max",
 			},
 		},
+		{
+			name: "anomaly lookback within interval",
+			src: &check.Anomaly{
+				Base:       goodBase,
+				Lookback:   mustDuration("1m"),
+				Deviations: 3,
+				Level:      notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Lookback should be greater than the interval",
+			},
+		},
+		{
+			name: "anomaly seasonality longer than lookback",
+			src: &check.Anomaly{
+				Base:        goodBase,
+				Lookback:    mustDuration("1d"),
+				Seasonality: mustDuration("7d"),
+				Deviations:  3,
+				Level:       notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Seasonality should be at least the interval and less than the lookback",
+			},
+		},
+		{
+			name: "bad anomaly method",
+			src: &check.Anomaly{
+				Base:       goodBase,
+				Method:     "iqr",
+				Lookback:   mustDuration("1d"),
+				Deviations: 3,
+				Level:      notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Method must be one of stddev or mad",
+			},
+		},
+		{
+			name: "anomaly without deviations",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Lookback: mustDuration("1d"),
+				Level:    notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Deviations must be greater than 0",
+			},
+		},
+		{
+			name: "composite with a single query",
+			src: &check.Composite{
+				Base: goodBase,
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+				},
+				Condition: "errors > 0.05",
+				Level:     notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Composite check must have at least 2 queries",
+			},
+		},
+		{
+			name: "composite query with a reserved name",
+			src: &check.Composite{
+				Base: goodBase,
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+					{Name: "check", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+				},
+				Condition: "errors > 0.05",
+				Level:     notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `Composite query name "check" is invalid`,
+			},
+		},
+		{
+			name: "composite query with a duplicated name",
+			src: &check.Composite{
+				Base: goodBase,
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "bar")`}},
+				},
+				Condition: "errors > 0.05",
+				Level:     notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `Composite query name "errors" is duplicated`,
+			},
+		},
+		{
+			name: "composite without condition",
+			src: &check.Composite{
+				Base: goodBase,
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+					{Name: "requests", Query: influxdb.DashboardQuery{Text: `from(bucket: "bar")`}},
+				},
+				Level: notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Composite Condition can't be empty",
+			},
+		},
+		{
+			name: "composite with invalid reducer",
+			src: &check.Composite{
+				Base: goodBase,
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`}},
+					{Name: "requests", Query: influxdb.DashboardQuery{Text: `from(bucket: "bar")`}},
+				},
+				Reducer:   "mean",
+				Condition: "errors > 0.05",
+				Level:     notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `Composite Reducer "mean" is invalid`,
+			},
+		},
+		{
+			name: "rate window within interval",
+			src: &check.Rate{
+				Base:   goodBase,
+				Window: mustDuration("1m"),
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{Value: 20},
+				},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Rate Window should be greater than the interval",
+			},
+		},
+		{
+			name: "rate without thresholds",
+			src: &check.Rate{
+				Base:   goodBase,
+				Window: mustDuration("10m"),
+				Method: check.RateMethodPercent,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Rate check must have at least 1 threshold",
+			},
+		},
+		{
+			name: "absent stale time within time since",
+			src: &check.Absent{
+				Base:      goodBase,
+				TimeSince: mustDuration("10m"),
+				StaleTime: mustDuration("5m"),
+				Level:     notification.Critical,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Absent StaleTime should be greater than TimeSince",
+			},
+		},
 	}
 	for _, c := range cases {
 		got := c.src.Valid(fluxlang.DefaultService)
@@ -163,6 +334,19 @@ func mustDuration(d string) *notification.Duration {
 	return (*notification.Duration)(dur)
 }
 
+// emptyBuilderConfig is the builder config of queries after a JSON round trip.
+var emptyBuilderConfig = influxdb.BuilderConfig{
+	Buckets: []string{},
+	Tags: []struct {
+		Key                   string   `json:"key"`
+		Values                []string `json:"values"`
+		AggregateFunctionType string   `json:"aggregateFunctionType"`
+	}{},
+	Functions: []struct {
+		Name string `json:"name"`
+	}{},
+}
+
 func TestJSON(t *testing.T) {
 	cases := []struct {
 		name string
@@ -254,6 +438,121 @@ func TestJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple anomaly",
+			src: &check.Anomaly{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1h"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key                   string   `json:"key"`
+								Values                []string `json:"values"`
+								AggregateFunctionType string   `json:"aggregateFunctionType"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{
+						{Key: "k1", Value: "v1"},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Method:      check.AnomalyMethodMAD,
+				Lookback:    mustDuration("7d"),
+				Seasonality: mustDuration("1d"),
+				Deviations:  3,
+				Level:       notification.Critical,
+			},
+		},
+		{
+			name: "simple composite",
+			src: &check.Composite{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1m"),
+					Query:   influxdb.DashboardQuery{BuilderConfig: emptyBuilderConfig},
+					Tags: []influxdb.Tag{
+						{Key: "k1", Value: "v1"},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Queries: []check.CompositeQuery{
+					{Name: "errors", Query: influxdb.DashboardQuery{Text: `from(bucket: "foo")`, BuilderConfig: emptyBuilderConfig}},
+					{Name: "requests", Query: influxdb.DashboardQuery{Text: `from(bucket: "bar")`, BuilderConfig: emptyBuilderConfig}},
+				},
+				GroupBy:   []string{"host"},
+				Condition: "errors > 0.05 and requests > 100.0",
+				Level:     notification.Critical,
+			},
+		},
+		{
+			name: "simple rate",
+			src: &check.Rate{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1m"),
+					Query:   influxdb.DashboardQuery{BuilderConfig: emptyBuilderConfig},
+					Tags: []influxdb.Tag{
+						{Key: "k1", Value: "v1"},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Window: mustDuration("10m"),
+				Method: check.RateMethodDerivative,
+				Unit:   mustDuration("1m"),
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 20},
+					&check.Range{Min: -5, Max: 5, Within: true},
+				},
+			},
+		},
+		{
+			name: "simple absent",
+			src: &check.Absent{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1m"),
+					Query:   influxdb.DashboardQuery{BuilderConfig: emptyBuilderConfig},
+					Tags: []influxdb.Tag{
+						{Key: "k1", Value: "v1"},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				TimeSince: mustDuration("5m"),
+				StaleTime: mustDuration("1h"),
+				GroupBy:   []string{"host"},
+				Level:     notification.Warn,
+			},
+		},
 	}
 	for _, c := range cases {
 		fn := func(t *testing.T) {
@@ -0,0 +1,371 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Composite)(nil)
+
+// compositeQueryName is the format of the names of the queries of a
+// composite check, which are Flux identifiers.
+var compositeQueryName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// reservedCompositeQueryNames are the identifiers of the generated Flux that
+// the names of the queries of a composite check can't shadow.
+var reservedCompositeQueryNames = map[string]bool{
+	"check":     true,
+	"messageFn": true,
+	"monitor":   true,
+	"join":      true,
+	"r":         true,
+	"ok":        true,
+	"info":      true,
+	"warn":      true,
+	"crit":      true,
+}
+
+// compositeReducers are the selectors that can reduce the rows of each group
+// of the queries of a composite check to a single row.
+var compositeReducers = map[string]bool{
+	"":      true,
+	"last":  true,
+	"first": true,
+	"min":   true,
+	"max":   true,
+}
+
+// Composite is the composite check. It reduces each of its queries to a
+// single value per group, joins the values by group, and sets the level of
+// each group for which its condition over the values of the queries is true.
+type Composite struct {
+	Base
+	Queries []CompositeQuery `json:"queries"`
+	// GroupBy are the columns the results of the queries are joined on.
+	// Each group gets a single status.
+	GroupBy []string `json:"groupBy,omitempty"`
+	// Reducer is the selector reducing the rows of all the series of a
+	// group of each query to a single row: last, the default, first, min
+	// or max.
+	Reducer string `json:"reducer,omitempty"`
+	// Condition is a Flux boolean expression over the values of the queries
+	// referenced by their names, such as `errors > 0.05 and requests > 100`.
+	Condition string                  `json:"condition"`
+	Level     notification.CheckLevel `json:"level"`
+}
+
+// CompositeQuery is a named query of a composite check.
+type CompositeQuery struct {
+	Name  string                  `json:"name"`
+	Query influxdb.DashboardQuery `json:"query"`
+}
+
+// Type returns the type of the check.
+func (c Composite) Type() string {
+	return "composite"
+}
+
+// Valid returns error if something is invalid.
+func (c Composite) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := c.Base.Valid(lang); err != nil {
+		return err
+	}
+	if len(c.Queries) < 2 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Composite check must have at least 2 queries",
+		}
+	}
+	names := make(map[string]bool, len(c.Queries))
+	for _, q := range c.Queries {
+		if !compositeQueryName.MatchString(q.Name) || reservedCompositeQueryNames[q.Name] {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Composite query name %q is invalid", q.Name),
+			}
+		}
+		if names[q.Name] {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Composite query name %q is duplicated", q.Name),
+			}
+		}
+		names[q.Name] = true
+		if q.Query.Text == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Composite query %q can't be empty", q.Name),
+			}
+		}
+	}
+	for _, col := range c.GroupBy {
+		if col == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Composite GroupBy column can't be empty",
+			}
+		}
+	}
+	if !compositeReducers[c.Reducer] {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Composite Reducer %q is invalid", c.Reducer),
+		}
+	}
+	if c.Condition == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Composite Condition can't be empty",
+		}
+	}
+	if _, err := c.parseCondition(lang); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Composite Condition is invalid",
+			Err:  err,
+		}
+	}
+	if c.Level == notification.Unknown {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Composite Level is invalid",
+		}
+	}
+	return nil
+}
+
+// parseCondition returns the expression of the condition of the check.
+func (c Composite) parseCondition(lang fluxlang.FluxLanguageService) (ast.Expression, error) {
+	p, err := query.Parse(lang, c.Condition)
+	if p == nil {
+		return nil, err
+	}
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+	if len(p.Files) != 1 || len(p.Files[0].Body) != 1 {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+	stmt, ok := p.Files[0].Body[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, fmt.Errorf("expected an expression, received %T", p.Files[0].Body[0])
+	}
+	return stmt.Expression, nil
+}
+
+// GenerateFlux returns a flux script for the composite check provided.
+func (c Composite) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	f, err := c.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return astutil.Format(f)
+}
+
+// GenerateFluxAST returns a flux AST for the composite check provided. Each
+// query is assigned to a variable with its name. If there are any errors in
+// the flux that the user provided the function will return an error for each
+// error found when the queries are parsed.
+func (c Composite) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.File, error) {
+	if len(c.Queries) == 0 {
+		return nil, fmt.Errorf("composite check requires queries")
+	}
+
+	var (
+		imports    []*ast.ImportDeclaration
+		statements []ast.Statement
+	)
+	seen := make(map[string]bool)
+	addImports := func(is ...*ast.ImportDeclaration) {
+		for _, i := range is {
+			if !seen[i.Path.Value] {
+				seen[i.Path.Value] = true
+				imports = append(imports, i)
+			}
+		}
+	}
+
+	names := make(map[string]bool, len(c.Queries))
+	for _, q := range c.Queries {
+		p, err := query.Parse(lang, q.Query.Text)
+		if p == nil {
+			return nil, err
+		}
+		replaceDurationsWithEvery(p, c.Every)
+		removeStopFromRange(p)
+		addCreateEmptyFalseToAggregateWindow(p)
+
+		if errs := ast.GetErrors(p); len(errs) != 0 {
+			return nil, multiError(errs)
+		}
+		if len(p.Files) != 1 {
+			return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+		}
+
+		f := p.Files[0]
+		if err := assignPipeline(f, q.Name); err != nil {
+			return nil, fmt.Errorf("composite query %q: %v", q.Name, err)
+		}
+		addImports(f.Imports...)
+		statements = append(statements, f.Body...)
+		names[q.Name] = true
+	}
+
+	cond, err := c.parseCondition(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	addImports(flux.Imports("influxdata/influxdb/monitor", "join")...)
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("composite"))
+	statements = append(statements, c.generateLevelFn(referenceQueries(cond, names)))
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements, c.generateFluxASTChecksFunction())
+
+	return flux.File("", imports, statements), nil
+}
+
+// referenceQueries returns e with the identifiers of the names of the queries
+// replaced by the columns of their values in r.
+func referenceQueries(e ast.Expression, names map[string]bool) ast.Expression {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		if names[e.Name] {
+			return flux.Member("r", e.Name)
+		}
+	case *ast.BinaryExpression:
+		e.Left = referenceQueries(e.Left, names)
+		e.Right = referenceQueries(e.Right, names)
+	case *ast.LogicalExpression:
+		e.Left = referenceQueries(e.Left, names)
+		e.Right = referenceQueries(e.Right, names)
+	case *ast.UnaryExpression:
+		e.Argument = referenceQueries(e.Argument, names)
+	case *ast.ParenExpression:
+		e.Expression = referenceQueries(e.Expression, names)
+	case *ast.ConditionalExpression:
+		e.Test = referenceQueries(e.Test, names)
+		e.Consequent = referenceQueries(e.Consequent, names)
+		e.Alternate = referenceQueries(e.Alternate, names)
+	case *ast.CallExpression:
+		for _, arg := range e.Arguments {
+			if obj, ok := arg.(*ast.ObjectExpression); ok {
+				for _, prop := range obj.Properties {
+					prop.Value = referenceQueries(prop.Value, names)
+				}
+			}
+		}
+	}
+	return e
+}
+
+func (c Composite) generateLevelFn(cond ast.Expression) ast.Statement {
+	fn := flux.Function(flux.FunctionParams("r"), cond)
+
+	lvl := strings.ToLower(c.Level.String())
+
+	return flux.DefineVariable(lvl, fn)
+}
+
+// reduceCalls returns the calls reducing the rows of each group of a query
+// to a single row. The rows of the series of a group are in no particular
+// order, so they are sorted by time for first and last.
+func (c Composite) reduceCalls() []*ast.CallExpression {
+	reducer := c.Reducer
+	if reducer == "" {
+		reducer = "last"
+	}
+	call := flux.Call(flux.Identifier(reducer), flux.Object())
+	if reducer == "first" || reducer == "last" {
+		return []*ast.CallExpression{
+			flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", flux.Array(flux.String("_time"))))),
+			call,
+		}
+	}
+	return []*ast.CallExpression{call}
+}
+
+// generateFluxASTChecksFunction groups each query by the group by columns,
+// reduces each group to a single row and joins the groups of the queries,
+// with the value of each query in a column named after it. Each group gets
+// a single row, whatever the number of series in it.
+func (c Composite) generateFluxASTChecksFunction() ast.Statement {
+	cols := make([]ast.Expression, 0, len(c.GroupBy))
+	for _, col := range c.GroupBy {
+		cols = append(cols, flux.String(col))
+	}
+
+	tables := make([]ast.Expression, 0, len(c.Queries))
+	for _, q := range c.Queries {
+		calls := []*ast.CallExpression{
+			flux.Call(flux.Identifier("group"), flux.Object(flux.Property("columns", flux.Array(cols...)))),
+		}
+		calls = append(calls, c.reduceCalls()...)
+		calls = append(calls, mapCall(flux.ObjectWith("r", flux.Property(q.Name, flux.Member("r", "_value")))))
+		tables = append(tables, flux.Pipe(flux.Identifier(q.Name), calls...))
+	}
+
+	// rows of groups with the same values of the group by columns match
+	on := func() ast.Expression {
+		var e ast.Expression = flux.Bool(true)
+		for i := len(c.GroupBy) - 1; i >= 0; i-- {
+			eq := flux.Equal(flux.Member("l", c.GroupBy[i]), flux.Member("r", c.GroupBy[i]))
+			if i == len(c.GroupBy)-1 {
+				e = eq
+				continue
+			}
+			e = flux.And(eq, e)
+		}
+		return e
+	}
+
+	joined := tables[0]
+	for i, right := range tables[1:] {
+		name := c.Queries[i+1].Name
+		joined = flux.Call(flux.Member("join", "inner"), flux.Object(
+			flux.Property("left", joined),
+			flux.Property("right", right),
+			flux.Property("on", flux.Function(flux.FunctionParams("l", "r"), on())),
+			flux.Property("as", flux.Function(flux.FunctionParams("l", "r"), flux.ObjectWith("l",
+				flux.Property(name, flux.Member("r", name)),
+			))),
+		))
+	}
+
+	lvl := strings.ToLower(c.Level.String())
+	return flux.ExpressionStatement(flux.Pipe(
+		joined,
+		flux.Call(flux.Member("monitor", "check"), flux.Object(
+			flux.Property("data", flux.Identifier("check")),
+			flux.Property("messageFn", flux.Identifier("messageFn")),
+			flux.Property(lvl, flux.Identifier(lvl)),
+		)),
+	))
+}
+
+type compositeAlias Composite
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Composite) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			compositeAlias
+			Type string `json:"type"`
+		}{
+			compositeAlias: compositeAlias(c),
+			Type:           c.Type(),
+		})
+}
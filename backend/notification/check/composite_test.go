@@ -0,0 +1,204 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposite_GenerateFlux(t *testing.T) {
+	type args struct {
+		composite check.Composite
+	}
+	type wants struct {
+		script string
+	}
+
+	base := check.Base{
+		ID:   10,
+		Name: "moo",
+		Tags: []influxdb.Tag{
+			{Key: "aaa", Value: "vaaa"},
+		},
+		Every:                 mustDuration("1m"),
+		StatusMessageTemplate: "whoa! {r[\"errors\"]}",
+	}
+	queries := []check.CompositeQuery{
+		{
+			Name: "errors",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1h, stop: now()) |> filter(fn: (r) => r._field == "error_rate") |> aggregateWindow(every: 10s, fn: mean) |> yield()`,
+			},
+		},
+		{
+			Name: "requests",
+			Query: influxdb.DashboardQuery{
+				Text: `import "strings"
+
+from(bucket: "foo") |> range(start: -1h) |> filter(fn: (r) => r._field == "count" and strings.hasPrefix(v: r.path, prefix: "/api")) |> aggregateWindow(every: 10s, fn: sum)`,
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "two queries grouped by host",
+			args: args{
+				composite: check.Composite{
+					Base:      base,
+					Queries:   queries,
+					GroupBy:   []string{"host"},
+					Condition: "errors > 0.05 and requests > 100.0",
+					Level:     notification.Critical,
+				},
+			},
+			wants: wants{
+				script: `import "strings"
+import "influxdata/influxdb/monitor"
+import "join"
+
+errors =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "error_rate")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+requests =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "count" and strings.hasPrefix(v: r.path, prefix: "/api"))
+        |> aggregateWindow(every: 1m, fn: sum, createEmpty: false)
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "composite", tags: {aaa: "vaaa"}}
+crit = (r) => r["errors"] > 0.05 and r["requests"] > 100.0
+messageFn = (r) => "whoa! {r[\"errors\"]}"
+
+join["inner"](
+    left: errors |> group(columns: ["host"]) |> sort(columns: ["_time"]) |> last() |> map(fn: (r) => ({r with errors: r["_value"]})),
+    right: requests |> group(columns: ["host"]) |> sort(columns: ["_time"]) |> last() |> map(fn: (r) => ({r with requests: r["_value"]})),
+    on: (l, r) => l["host"] == r["host"],
+    as: (l, r) => ({l with requests: r["requests"]}),
+)
+    |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			},
+		},
+		{
+			name: "three queries without group by",
+			args: args{
+				composite: check.Composite{
+					Base: base,
+					Queries: append(queries, check.CompositeQuery{
+						Name: "latency",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1h) |> filter(fn: (r) => r._field == "p99")`,
+						},
+					}),
+					Condition: "(errors > 0.05 or latency > 2.0) and requests > 100.0",
+					Level:     notification.Warn,
+				},
+			},
+			wants: wants{
+				script: `import "strings"
+import "influxdata/influxdb/monitor"
+import "join"
+
+errors =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "error_rate")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+requests =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "count" and strings.hasPrefix(v: r.path, prefix: "/api"))
+        |> aggregateWindow(every: 1m, fn: sum, createEmpty: false)
+latency = from(bucket: "foo") |> range(start: -1m) |> filter(fn: (r) => r._field == "p99")
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "composite", tags: {aaa: "vaaa"}}
+warn = (r) => (r["errors"] > 0.05 or r["latency"] > 2.0) and r["requests"] > 100.0
+messageFn = (r) => "whoa! {r[\"errors\"]}"
+
+join["inner"](
+    left:
+        join["inner"](
+            left: errors |> group(columns: []) |> sort(columns: ["_time"]) |> last() |> map(fn: (r) => ({r with errors: r["_value"]})),
+            right: requests |> group(columns: []) |> sort(columns: ["_time"]) |> last() |> map(fn: (r) => ({r with requests: r["_value"]})),
+            on: (l, r) => true,
+            as: (l, r) => ({l with requests: r["requests"]}),
+        ),
+    right: latency |> group(columns: []) |> sort(columns: ["_time"]) |> last() |> map(fn: (r) => ({r with latency: r["_value"]})),
+    on: (l, r) => true,
+    as: (l, r) => ({l with latency: r["latency"]}),
+)
+    |> monitor["check"](data: check, messageFn: messageFn, warn: warn)
+`,
+			},
+		},
+		{
+			name: "max of each group",
+			args: args{
+				composite: check.Composite{
+					Base:      base,
+					Queries:   queries,
+					GroupBy:   []string{"host", "region"},
+					Reducer:   "max",
+					Condition: "errors > 0.05",
+					Level:     notification.Info,
+				},
+			},
+			wants: wants{
+				script: `import "strings"
+import "influxdata/influxdb/monitor"
+import "join"
+
+errors =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "error_rate")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+requests =
+    from(bucket: "foo")
+        |> range(start: -1m)
+        |> filter(fn: (r) => r._field == "count" and strings.hasPrefix(v: r.path, prefix: "/api"))
+        |> aggregateWindow(every: 1m, fn: sum, createEmpty: false)
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "composite", tags: {aaa: "vaaa"}}
+info = (r) => r["errors"] > 0.05
+messageFn = (r) => "whoa! {r[\"errors\"]}"
+
+join["inner"](
+    left: errors |> group(columns: ["host", "region"]) |> max() |> map(fn: (r) => ({r with errors: r["_value"]})),
+    right: requests |> group(columns: ["host", "region"]) |> max() |> map(fn: (r) => ({r with requests: r["_value"]})),
+    on: (l, r) => l["host"] == r["host"] and l["region"] == r["region"],
+    as: (l, r) => ({l with requests: r["requests"]}),
+)
+    |> monitor["check"](data: check, messageFn: messageFn, info: info)
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.args.composite.GenerateFlux(fluxlang.DefaultService)
+			require.NoError(t, err)
+			assert.Equal(t, itesting.FormatFluxString(t, tt.wants.script), s)
+		})
+	}
+}
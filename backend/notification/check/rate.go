@@ -0,0 +1,278 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Rate)(nil)
+
+// Methods of computing the change of a rate check.
+const (
+	// RateMethodDifference is the difference of the last and first values
+	// of the window.
+	RateMethodDifference = "difference"
+	// RateMethodPercent is the difference relative to the first value of the
+	// window, in percent.
+	RateMethodPercent = "percent"
+	// RateMethodDerivative is the difference per unit of time.
+	RateMethodDerivative = "derivative"
+)
+
+// Rate is the rate of change check. It sets the level of each series by
+// comparing the change of its values over the window with thresholds, such
+// as disk usage growing by more than 20 percent in 10m.
+type Rate struct {
+	Base
+	// Window is how far back the change of the values is computed.
+	Window *notification.Duration `json:"window,omitempty"`
+	// Method is how the change is computed, one of difference (the default),
+	// percent or derivative.
+	Method string `json:"method,omitempty"`
+	// Unit is the unit of time of the derivative, 1s by default.
+	Unit       *notification.Duration `json:"unit,omitempty"`
+	Thresholds []ThresholdConfig      `json:"thresholds"`
+}
+
+// Type returns the type of the check.
+func (c Rate) Type() string {
+	return "rate"
+}
+
+// Valid returns error if something is invalid.
+func (c Rate) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := c.Base.Valid(lang); err != nil {
+		return err
+	}
+	switch c.Method {
+	case "", RateMethodDifference, RateMethodPercent, RateMethodDerivative:
+	default:
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Rate Method must be one of %s, %s or %s", RateMethodDifference, RateMethodPercent, RateMethodDerivative),
+		}
+	}
+	if c.Window == nil || len(c.Window.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Rate Window must exist",
+		}
+	}
+	if c.Window.TimeDuration() <= c.Every.TimeDuration() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Rate Window should be greater than the interval",
+		}
+	}
+	if c.Unit != nil && (len(c.Unit.Values) == 0 || c.Unit.TimeDuration() <= 0) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Rate Unit should be a positive duration",
+		}
+	}
+	if len(c.Thresholds) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Rate check must have at least 1 threshold",
+		}
+	}
+	for _, cc := range c.Thresholds {
+		if err := cc.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rateDecode struct {
+	Base
+	Window     *notification.Duration  `json:"window,omitempty"`
+	Method     string                  `json:"method,omitempty"`
+	Unit       *notification.Duration  `json:"unit,omitempty"`
+	Thresholds []thresholdConfigDecode `json:"thresholds"`
+}
+
+// UnmarshalJSON implement json.Unmarshaler interface.
+func (c *Rate) UnmarshalJSON(b []byte) error {
+	raw := new(rateDecode)
+	if err := json.Unmarshal(b, raw); err != nil {
+		return err
+	}
+	thresholds, err := decodeThresholds(raw.Thresholds)
+	if err != nil {
+		return err
+	}
+	c.Base = raw.Base
+	c.Window = raw.Window
+	c.Method = raw.Method
+	c.Unit = raw.Unit
+	c.Thresholds = thresholds
+	return nil
+}
+
+// GenerateFlux returns a flux script for the rate check provided.
+func (c Rate) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	f, err := c.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return astutil.Format(f)
+}
+
+// GenerateFluxAST returns a flux AST for the rate check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (c Rate) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.File, error) {
+	if c.Every == nil || c.Window == nil {
+		return nil, fmt.Errorf("rate check requires every and window")
+	}
+
+	p, err := query.Parse(lang, c.Query.Text)
+	if p == nil {
+		return nil, err
+	}
+	replaceDurationsWithEvery(p, c.Every)
+	replaceRangeStart(p, c.Window)
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// The statements of the check are appended to the file of the query, whose pipeline
+	// becomes the data the rate is computed from, so the query must be a single file.
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "join", "math")...)
+	f.Body = append(f.Body, c.generateFluxASTBody(fields[0])...)
+
+	return f, nil
+}
+
+func (c Rate) generateFluxASTBody(field string) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("rate"))
+	for _, th := range c.Thresholds {
+		statements = append(statements, th.generateFluxASTThresholdFunction("_change"))
+	}
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements,
+		flux.DefineVariable("initial", flux.Pipe(flux.Identifier("data"), flux.Call(flux.Identifier("first"), flux.Object()))),
+		flux.DefineVariable("current", flux.Pipe(flux.Identifier("data"), flux.Call(flux.Identifier("last"), flux.Object()))),
+	)
+	return append(statements, c.generateFluxASTChecksFunction(field))
+}
+
+// generateFluxASTChange returns the change of the value of r from the
+// initial value of the window.
+func (c Rate) generateFluxASTChange() ast.Expression {
+	value := flux.Member("r", "_value")
+	initial := flux.Member("r", "_initial")
+	diff := flux.Subtract(value, initial)
+
+	switch c.Method {
+	case RateMethodPercent:
+		// changes from zero are infinite
+		return flux.If(
+			flux.NotEqual(initial, flux.Float(0)),
+			flux.Multiply(
+				flux.Divide(diff, flux.Call(flux.Member("math", "abs"), flux.Object(flux.Property("x", initial)))),
+				flux.Float(100),
+			),
+			flux.If(
+				flux.Equal(value, initial),
+				flux.Float(0),
+				flux.If(
+					flux.GreaterThan(value, initial),
+					flux.Call(flux.Member("math", "mInf"), flux.Object(flux.Property("sign", flux.Integer(1)))),
+					flux.Call(flux.Member("math", "mInf"), flux.Object(flux.Property("sign", flux.Integer(-1)))),
+				),
+			),
+		)
+	case RateMethodDerivative:
+		unit := c.Unit
+		if unit == nil {
+			unit = (*notification.Duration)(flux.Duration(1, "s"))
+		}
+		elapsed := flux.Subtract(intCall(flux.Member("r", "_time")), intCall(flux.Member("r", "_initial_time")))
+		return flux.If(
+			flux.GreaterThan(flux.Member("r", "_time"), flux.Member("r", "_initial_time")),
+			flux.Divide(diff, flux.Divide(floatCall(elapsed), floatCall(intCall((*ast.DurationLiteral)(unit))))),
+			flux.Float(0),
+		)
+	default:
+		return diff
+	}
+}
+
+// generateFluxASTChecksFunction joins the last value of each series with
+// its first value in the window, and checks their change. The value is also
+// set in a column named after the field for the status message template.
+func (c Rate) generateFluxASTChecksFunction(field string) ast.Statement {
+	return flux.ExpressionStatement(flux.Pipe(
+		joinCall("current", "initial", flux.ObjectWith("l",
+			flux.Property("_initial", flux.Member("r", "_value")),
+			flux.Property("_initial_time", flux.Member("r", "_time")),
+		)),
+		mapCall(flux.ObjectWith("r",
+			flux.Dictionary(field, flux.Member("r", "_value")),
+			flux.Property("_change", c.generateFluxASTChange()),
+		)),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+func (c Rate) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	// This assumes that the ThresholdConfigs we've been provided do not have duplicates.
+	for _, th := range c.Thresholds {
+		lvl := strings.ToLower(th.GetLevel().String())
+		objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+	}
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+// floatCall returns a call converting e to a float.
+func floatCall(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("float"), flux.Object(flux.Property("v", e)))
+}
+
+type rateAlias Rate
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			rateAlias
+			Type string `json:"type"`
+		}{
+			rateAlias: rateAlias(c),
+			Type:      c.Type(),
+		})
+}
@@ -0,0 +1,226 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRate_GenerateFlux(t *testing.T) {
+	type args struct {
+		rate check.Rate
+	}
+	type wants struct {
+		script string
+	}
+
+	base := check.Base{
+		ID:   10,
+		Name: "moo",
+		Tags: []influxdb.Tag{
+			{Key: "aaa", Value: "vaaa"},
+		},
+		Every:                 mustDuration("1m"),
+		StatusMessageTemplate: "whoa! {r[\"used_percent\"]}",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "foo") |> range(start: -1h, stop: now()) |> filter(fn: (r) => r._field == "used_percent") |> aggregateWindow(every: 10s, fn: mean) |> yield()`,
+		},
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "percent",
+			args: args{
+				rate: check.Rate{
+					Base:   base,
+					Window: mustDuration("10m"),
+					Method: check.RateMethodPercent,
+					Thresholds: []check.ThresholdConfig{
+						check.Greater{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Critical,
+							},
+							Value: 20,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "join"
+import "math"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -10m)
+        |> filter(fn: (r) => r._field == "used_percent")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "rate", tags: {aaa: "vaaa"}}
+crit = (r) => r["_change"] > 20.0
+messageFn = (r) => "whoa! {r[\"used_percent\"]}"
+initial = data |> first()
+current = data |> last()
+
+join["inner"](
+    left: current,
+    right: initial,
+    on: (l, r) => l["_start"] == r["_start"],
+    as: (l, r) => ({l with _initial: r["_value"], _initial_time: r["_time"]}),
+)
+    |> map(
+        fn: (r) =>
+            ({r with
+                "used_percent": r["_value"],
+                _change:
+                    if r["_initial"] != 0.0 then
+                        (r["_value"] - r["_initial"]) / math["abs"](x: r["_initial"]) * 100.0
+                    else if r["_value"] == r["_initial"] then
+                        0.0
+                    else if r["_value"] > r["_initial"] then
+                        math["mInf"](sign: 1)
+                    else
+                        math["mInf"](sign: -1),
+            }),
+    )
+    |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			},
+		},
+		{
+			name: "difference",
+			args: args{
+				rate: check.Rate{
+					Base:   base,
+					Window: mustDuration("10m"),
+					Thresholds: []check.ThresholdConfig{
+						check.Lesser{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Info,
+							},
+							Value: 0,
+						},
+						check.Range{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Warn,
+							},
+							Min:    -5,
+							Max:    5,
+							Within: false,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "join"
+import "math"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -10m)
+        |> filter(fn: (r) => r._field == "used_percent")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "rate", tags: {aaa: "vaaa"}}
+info = (r) => r["_change"] < 0.0
+warn = (r) => r["_change"] < -5.0 or r["_change"] > 5.0
+messageFn = (r) => "whoa! {r[\"used_percent\"]}"
+initial = data |> first()
+current = data |> last()
+
+join["inner"](
+    left: current,
+    right: initial,
+    on: (l, r) => l["_start"] == r["_start"],
+    as: (l, r) => ({l with _initial: r["_value"], _initial_time: r["_time"]}),
+)
+    |> map(fn: (r) => ({r with "used_percent": r["_value"], _change: r["_value"] - r["_initial"]}))
+    |> monitor["check"](data: check, messageFn: messageFn, info: info, warn: warn)
+`,
+			},
+		},
+		{
+			name: "derivative",
+			args: args{
+				rate: check.Rate{
+					Base:   base,
+					Window: mustDuration("10m"),
+					Method: check.RateMethodDerivative,
+					Unit:   mustDuration("1m"),
+					Thresholds: []check.ThresholdConfig{
+						check.Greater{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Critical,
+							},
+							Value: 2,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `import "influxdata/influxdb/monitor"
+import "join"
+import "math"
+
+data =
+    from(bucket: "foo")
+        |> range(start: -10m)
+        |> filter(fn: (r) => r._field == "used_percent")
+        |> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 1m}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "rate", tags: {aaa: "vaaa"}}
+crit = (r) => r["_change"] > 2.0
+messageFn = (r) => "whoa! {r[\"used_percent\"]}"
+initial = data |> first()
+current = data |> last()
+
+join["inner"](
+    left: current,
+    right: initial,
+    on: (l, r) => l["_start"] == r["_start"],
+    as: (l, r) => ({l with _initial: r["_value"], _initial_time: r["_time"]}),
+)
+    |> map(
+        fn: (r) =>
+            ({r with
+                "used_percent": r["_value"],
+                _change:
+                    if r["_time"] > r["_initial_time"] then
+                        (r["_value"] - r["_initial"])
+                            /
+                            (float(v: int(v: r["_time"]) - int(v: r["_initial_time"])) / float(v: int(v: 1m)))
+                    else
+                        0.0,
+            }),
+    )
+    |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.args.rate.GenerateFlux(fluxlang.DefaultService)
+			require.NoError(t, err)
+			assert.Equal(t, itesting.FormatFluxString(t, tt.wants.script), s)
+		})
+	}
+}
@@ -63,20 +63,32 @@ func (t *Threshold) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	t.Base = tdRaws.Base
-	for _, tdRaw := range tdRaws.Thresholds {
+	thresholds, err := decodeThresholds(tdRaws.Thresholds)
+	if err != nil {
+		return err
+	}
+	t.Thresholds = thresholds
+
+	return nil
+}
+
+// decodeThresholds returns the threshold configs of their decoded JSON.
+func decodeThresholds(tdRaws []thresholdConfigDecode) ([]ThresholdConfig, error) {
+	var thresholds []ThresholdConfig
+	for _, tdRaw := range tdRaws {
 		switch tdRaw.Type {
 		case "lesser":
 			td := &Lesser{
 				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
 				Value:               tdRaw.Value,
 			}
-			t.Thresholds = append(t.Thresholds, td)
+			thresholds = append(thresholds, td)
 		case "greater":
 			td := &Greater{
 				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
 				Value:               tdRaw.Value,
 			}
-			t.Thresholds = append(t.Thresholds, td)
+			thresholds = append(thresholds, td)
 		case "range":
 			td := &Range{
 				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
@@ -84,15 +96,15 @@ func (t *Threshold) UnmarshalJSON(b []byte) error {
 				Max:                 tdRaw.Max,
 				Within:              tdRaw.Within,
 			}
-			t.Thresholds = append(t.Thresholds, td)
+			thresholds = append(thresholds, td)
 		default:
-			return &errors.Error{
+			return nil, &errors.Error{
 				Msg: fmt.Sprintf("invalid threshold type %s", tdRaw.Type),
 			}
 		}
 	}
 
-	return nil
+	return thresholds, nil
 }
 
 func multiError(errs []error) error {
@@ -247,6 +259,12 @@ func getFields(pkg *ast.Package) []string {
 }
 
 func assignPipelineToData(f *ast.File) error {
+	return assignPipeline(f, "data")
+}
+
+// assignPipeline replaces the pipeline of the single statement of f with a
+// variable assignment of it to id, without its final yield.
+func assignPipeline(f *ast.File, id string) error {
 	if len(f.Body) != 1 {
 		return fmt.Errorf("expected there to be a single statement in the flux script body, received %d", len(f.Body))
 	}
@@ -269,7 +287,7 @@ func assignPipelineToData(f *ast.File) error {
 		exp = pipe.Argument
 	}
 
-	f.Body[0] = flux.DefineVariable("data", exp)
+	f.Body[0] = flux.DefineVariable(id, exp)
 	return nil
 }
 
@@ -15,6 +15,9 @@ const (
 	PagerDutyType = "pagerduty"
 	HTTPType      = "http"
 	TelegramType  = "telegram"
+	SMTPType      = "smtp"
+	TeamsType     = "teams"
+	OpsgenieType  = "opsgenie"
 )
 
 var typeToEndpoint = map[string]func() influxdb.NotificationEndpoint{
@@ -22,6 +25,9 @@ var typeToEndpoint = map[string]func() influxdb.NotificationEndpoint{
 	PagerDutyType: func() influxdb.NotificationEndpoint { return &PagerDuty{} },
 	HTTPType:      func() influxdb.NotificationEndpoint { return &HTTP{} },
 	TelegramType:  func() influxdb.NotificationEndpoint { return &Telegram{} },
+	SMTPType:      func() influxdb.NotificationEndpoint { return &SMTP{} },
+	TeamsType:     func() influxdb.NotificationEndpoint { return &Teams{} },
+	OpsgenieType:  func() influxdb.NotificationEndpoint { return &Opsgenie{} },
 }
 
 // UnmarshalJSON will convert the bytes to notification endpoint.
@@ -197,6 +197,120 @@ func TestValidEndpoint(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "empty smtp host",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				From: "influxdb@example.com",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint host must be provided",
+			},
+		},
+		{
+			name: "invalid smtp port",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Host: "smtp.example.com",
+				Port: 70000,
+				From: "influxdb@example.com",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint port 70000 is invalid",
+			},
+		},
+		{
+			name: "invalid smtp from address",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Host: "smtp.example.com",
+				From: "influxdb",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint from address is invalid: mail: missing '@' or angle-addr",
+			},
+		},
+		{
+			name: "empty smtp relay key",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Host: "smtp.example.com",
+				From: "influxdb@example.com",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint relay key is invalid",
+			},
+		},
+		{
+			name: "valid smtp",
+			src: &endpoint.SMTP{
+				Base:     goodBase,
+				Host:     "smtp.example.com",
+				Port:     587,
+				From:     "InfluxDB <influxdb@example.com>",
+				RelayKey: influxdb.SecretField{Key: id1.String() + "-relay-key"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty teams url",
+			src: &endpoint.Teams{
+				Base: goodBase,
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "empty teams webhook URL",
+			},
+		},
+		{
+			name: "valid teams url",
+			src: &endpoint.Teams{
+				Base: goodBase,
+				URL:  influxdb.SecretField{Key: id1.String() + "-url"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty opsgenie api key",
+			src: &endpoint.Opsgenie{
+				Base: goodBase,
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "opsgenie API key is invalid",
+			},
+		},
+		{
+			name: "invalid opsgenie url",
+			src: &endpoint.Opsgenie{
+				Base:   goodBase,
+				URL:    "posts://er:{DEf1=ghi@:5432/db?ssl",
+				APIKey: influxdb.SecretField{Key: id1.String() + "-api-key"},
+			},
+			errFn: func(t *testing.T) error {
+				err := url.Error{
+					Op:  "parse",
+					URL: "posts://er:{DEf1=ghi@:5432/db?ssl",
+					Err: errors.New("net/url: invalid userinfo"),
+				}
+				return &errors2.Error{
+					Code: errors2.EInvalid,
+					Msg:  fmt.Sprintf("opsgenie endpoint URL is invalid: %s", err.Error()),
+				}
+			},
+		},
+		{
+			name: "valid opsgenie",
+			src: &endpoint.Opsgenie{
+				Base:   goodBase,
+				APIKey: influxdb.SecretField{Key: id1.String() + "-api-key"},
+			},
+			err: nil,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -306,6 +420,62 @@ func TestJSON(t *testing.T) {
 				Token: influxdb.SecretField{Key: "token-key-1"},
 			},
 		},
+		{
+			name: "simple smtp",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host:     "smtp.example.com",
+				Port:     587,
+				From:     "influxdb@example.com",
+				Username: influxdb.SecretField{Key: "username-key"},
+				Password: influxdb.SecretField{Key: "password-key"},
+				RelayURL: "http://influxdb:8086",
+				RelayKey: influxdb.SecretField{Key: "relay-key"},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: influxdb.SecretField{Key: "url-key"},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL:    "https://api.eu.opsgenie.com/v2/alerts",
+				APIKey: influxdb.SecretField{Key: "api-key"},
+				Entity: "server1",
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
@@ -479,6 +649,126 @@ func TestBackFill(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "smtp with credentials",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				From: "influxdb@example.com",
+				Username: influxdb.SecretField{
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Value: strPtr("password1"),
+				},
+				RelayKey: influxdb.SecretField{
+					Value: strPtr("relay-key-value"),
+				},
+			},
+			target: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				From: "influxdb@example.com",
+				Username: influxdb.SecretField{
+					Key:   id1.String() + "-username",
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Key:   id1.String() + "-password",
+					Value: strPtr("password1"),
+				},
+				RelayKey: influxdb.SecretField{
+					Key:   id1.String() + "-relay-key",
+					Value: strPtr("relay-key-value"),
+				},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: influxdb.SecretField{
+					Value: strPtr("https://example.webhook.office.com/webhookb2/x"),
+				},
+			},
+			target: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: influxdb.SecretField{
+					Key:   id1.String() + "-url",
+					Value: strPtr("https://example.webhook.office.com/webhookb2/x"),
+				},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Value: strPtr("api-key-value"),
+				},
+			},
+			target: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		c.src.BackfillSecretKeys()
@@ -606,6 +896,83 @@ func TestSecretFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "smtp without credentials",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				From: "influxdb@example.com",
+				RelayKey: influxdb.SecretField{
+					Key:   id1.String() + "-relay-key",
+					Value: strPtr("relay-key-value"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1.String() + "-relay-key",
+					Value: strPtr("relay-key-value"),
+				},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: influxdb.SecretField{
+					Key:   id1.String() + "-url",
+					Value: strPtr("https://example.webhook.office.com/webhookb2/x"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1.String() + "-url",
+					Value: strPtr("https://example.webhook.office.com/webhookb2/x"),
+				},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		secretFields := c.src.SecretFields()
@@ -0,0 +1,83 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var _ influxdb.NotificationEndpoint = &Opsgenie{}
+
+const opsgenieAPIKeySuffix = "-api-key"
+
+// Opsgenie is the notification endpoint config of opsgenie.
+type Opsgenie struct {
+	Base
+	// URL is the alert API URL, https://api.opsgenie.com/v2/alerts when empty.
+	// Accounts in the EU region use https://api.eu.opsgenie.com/v2/alerts.
+	URL string `json:"url,omitempty"`
+	// APIKey is the key of an API integration, see https://docs.opsgenie.com/docs/api-integration
+	APIKey influxdb.SecretField `json:"apiKey"`
+	// Entity is the optional domain of the alerts, for example the name of the
+	// application or server they are about.
+	Entity string `json:"entity,omitempty"`
+}
+
+// BackfillSecretKeys fill back the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *Opsgenie) BackfillSecretKeys() {
+	if s.APIKey.Key == "" && s.APIKey.Value != nil {
+		s.APIKey.Key = s.idStr() + opsgenieAPIKeySuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s Opsgenie) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{
+		s.APIKey,
+	}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.APIKey.Key == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "opsgenie API key is invalid",
+		}
+	}
+	if s.URL != "" {
+		if _, err := url.Parse(s.URL); err != nil {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("opsgenie endpoint URL is invalid: %s", err.Error()),
+			}
+		}
+	}
+	return nil
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Opsgenie) Type() string {
+	return OpsgenieType
+}
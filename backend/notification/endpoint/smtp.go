@@ -0,0 +1,275 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/rand"
+)
+
+var _ influxdb.NotificationEndpoint = &SMTP{}
+
+const (
+	smtpUsernameSuffix = "-username"
+	smtpPasswordSuffix = "-password"
+	smtpRelayKeySuffix = "-relay-key"
+)
+
+const (
+	// DefaultSMTPPort is the port of SMTP endpoints that don't set one.
+	DefaultSMTPPort = 25
+
+	// DefaultSMTPRelayURL is the URL notification rule tasks reach the server at
+	// when an SMTP endpoint doesn't set one.
+	DefaultSMTPRelayURL = "http://localhost:8086"
+
+	// SMTPRelayKeyHeader is the header carrying the relay key of an SMTP endpoint.
+	SMTPRelayKeyHeader = "X-Influxdb-Relay-Key"
+)
+
+// SMTP is the notification endpoint config of an SMTP mail server.
+//
+// Flux has no way to send email, so the tasks of SMTP notification rules post
+// their messages to the email relay of the InfluxDB server, which delivers them
+// to the mail server. The relay authenticates the tasks with the relay key of
+// the endpoint, which is generated when the endpoint is created.
+type SMTP struct {
+	Base
+	// Host is the host name of the mail server.
+	Host string `json:"host"`
+	// Port is the port of the mail server, 25 when zero.
+	Port int `json:"port,omitempty"`
+	// From is the sender address of the emails.
+	From string `json:"from"`
+	// Username and Password are the optional credentials of the sender.
+	// Authentication is only attempted over TLS or with a local mail server.
+	Username influxdb.SecretField `json:"username"`
+	Password influxdb.SecretField `json:"password"`
+	// RelayURL is the URL of the InfluxDB server as reached from the
+	// notification rule tasks, DefaultSMTPRelayURL when empty.
+	RelayURL string `json:"relayURL,omitempty"`
+	// RelayKey authenticates the tasks posting to the email relay.
+	RelayKey influxdb.SecretField `json:"relayKey"`
+}
+
+// BackfillSecretKeys fill back the secret field key during the unmarshalling
+// if value of that secret field is not nil. A relay key is generated if the
+// endpoint has none.
+func (s *SMTP) BackfillSecretKeys() {
+	if s.Username.Key == "" && s.Username.Value != nil {
+		s.Username.Key = s.idStr() + smtpUsernameSuffix
+	}
+	if s.Password.Key == "" && s.Password.Value != nil {
+		s.Password.Key = s.idStr() + smtpPasswordSuffix
+	}
+	if s.RelayKey.Key == "" && s.RelayKey.Value == nil {
+		if key, err := rand.NewTokenGenerator(32).Token(); err == nil {
+			s.RelayKey.Value = &key
+		}
+	}
+	if s.RelayKey.Key == "" && s.RelayKey.Value != nil {
+		s.RelayKey.Key = s.idStr() + smtpRelayKeySuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s SMTP) SecretFields() []influxdb.SecretField {
+	arr := []influxdb.SecretField{}
+	if s.Username.Key != "" {
+		arr = append(arr, s.Username)
+	}
+	if s.Password.Key != "" {
+		arr = append(arr, s.Password)
+	}
+	if s.RelayKey.Key != "" {
+		arr = append(arr, s.RelayKey)
+	}
+	return arr
+}
+
+// Valid returns error if some configuration is invalid
+func (s SMTP) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.Host == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "smtp endpoint host must be provided",
+		}
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("smtp endpoint port %d is invalid", s.Port),
+		}
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("smtp endpoint from address is invalid: %s", err.Error()),
+		}
+	}
+	if s.RelayURL != "" {
+		if _, err := url.Parse(s.RelayURL); err != nil {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("smtp endpoint relay URL is invalid: %s", err.Error()),
+			}
+		}
+	}
+	if s.RelayKey.Key == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "smtp endpoint relay key is invalid",
+		}
+	}
+	return nil
+}
+
+// RelayEndpoint returns the URL of the email relay of the endpoint.
+func (s SMTP) RelayEndpoint() string {
+	u := s.RelayURL
+	if u == "" {
+		u = DefaultSMTPRelayURL
+	}
+	return strings.TrimSuffix(u, "/") + "/api/v2/notificationEndpoints/" + s.idStr() + "/email"
+}
+
+// Email is a message sent through an SMTP endpoint.
+type Email struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+}
+
+// Valid returns error if the email has no valid recipients.
+func (e Email) Valid() error {
+	if len(e.To) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "email must have at least one recipient",
+		}
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("email recipient %q is invalid: %s", to, err.Error()),
+			}
+		}
+	}
+	return nil
+}
+
+// Send delivers an email to the mail server of the endpoint, upgrading the
+// connection with STARTTLS when the server supports it. The username and
+// password are the values of the endpoint's secrets; no authentication is
+// attempted when the username is empty.
+func (s SMTP) Send(ctx context.Context, username, password string, e Email) error {
+	if err := e.Valid(); err != nil {
+		return err
+	}
+	port := s.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if username != "" {
+		if err := c.Auth(smtp.PlainAuth("", username, password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return err
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	to := make([]string, 0, len(e.To))
+	for _, rcpt := range e.To {
+		addr, _ := mail.ParseAddress(rcpt)
+		if err := c.Rcpt(addr.Address); err != nil {
+			return err
+		}
+		to = append(to, addr.String())
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	header := []string{
+		"From: " + from.String(),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", e.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	if _, err := fmt.Fprintf(w, "%s\n\n%s\n", strings.Join(header, "\n"), e.Body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+type smtpAlias SMTP
+
+// MarshalJSON implement json.Marshaler interface.
+func (s SMTP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			smtpAlias
+			Type string `json:"type"`
+		}{
+			smtpAlias: smtpAlias(s),
+			Type:      s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s SMTP) Type() string {
+	return SMTPType
+}
@@ -0,0 +1,163 @@
+package endpoint_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpStandIn is a minimal SMTP server accepting a single message.
+type smtpStandIn struct {
+	ln net.Listener
+
+	// auth is the PLAIN credentials the server received, if any.
+	auth string
+	from string
+	to   []string
+	data string
+	done chan struct{}
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpStandIn{ln: ln, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) hostPort(t *testing.T) (string, int) {
+	host, port, err := net.SplitHostPort(s.ln.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, p
+}
+
+func (s *smtpStandIn) serve() {
+	defer close(s.done)
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			fields := strings.Fields(line)
+			b, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			s.auth = string(b)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.to = append(s.to, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTP_Send(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	host, port := srv.hostPort(t)
+
+	e := endpoint.SMTP{
+		Base: goodBase,
+		Host: host,
+		Port: port,
+		From: "InfluxDB <influxdb@example.com>",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := e.Send(ctx, "user1", "password1", endpoint.Email{
+		To:      []string{"ops@example.com", "Jane <jane@example.com>"},
+		Subject: "crit: cpu check",
+		Body:    "cpu is high\n.\nreally",
+	})
+	require.NoError(t, err)
+	<-srv.done
+
+	require.Equal(t, "\x00user1\x00password1", srv.auth)
+	require.Equal(t, "MAIL FROM:<influxdb@example.com>", srv.from)
+	require.Equal(t, []string{"RCPT TO:<ops@example.com>", "RCPT TO:<jane@example.com>"}, srv.to)
+	require.Contains(t, srv.data, "From: \"InfluxDB\" <influxdb@example.com>\r\n")
+	require.Contains(t, srv.data, "To: <ops@example.com>, \"Jane\" <jane@example.com>\r\n")
+	require.Contains(t, srv.data, "Subject: crit: cpu check\r\n")
+	// Lines starting with a dot are escaped in transit.
+	require.True(t, strings.HasSuffix(srv.data, "\r\n\r\ncpu is high\r\n..\r\nreally\r\n"), srv.data)
+}
+
+func TestSMTP_SendInvalidRecipient(t *testing.T) {
+	e := endpoint.SMTP{Base: goodBase, Host: "127.0.0.1", From: "influxdb@example.com"}
+	err := e.Send(context.Background(), "", "", endpoint.Email{To: []string{"ops"}})
+	require.Error(t, err)
+
+	err = e.Send(context.Background(), "", "", endpoint.Email{})
+	require.Error(t, err)
+}
+
+func TestSMTP_RelayKey(t *testing.T) {
+	e := &endpoint.SMTP{Base: goodBase, Host: "smtp.example.com", From: "influxdb@example.com"}
+	e.BackfillSecretKeys()
+
+	// A relay key is generated for endpoints without one.
+	require.Equal(t, id1.String()+"-relay-key", e.RelayKey.Key)
+	require.NotNil(t, e.RelayKey.Value)
+	require.NotEmpty(t, *e.RelayKey.Value)
+	require.NoError(t, e.Valid())
+
+	// An existing relay key is kept.
+	e.RelayKey = influxdb.SecretField{Key: "existing-key"}
+	e.BackfillSecretKeys()
+	require.Equal(t, influxdb.SecretField{Key: "existing-key"}, e.RelayKey)
+
+	require.Equal(t, "http://localhost:8086/api/v2/notificationEndpoints/"+id1.String()+"/email", e.RelayEndpoint())
+	e.RelayURL = "https://influxdb.example.com/"
+	require.Equal(t, "https://influxdb.example.com/api/v2/notificationEndpoints/"+id1.String()+"/email", e.RelayEndpoint())
+}
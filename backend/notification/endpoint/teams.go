@@ -0,0 +1,70 @@
+package endpoint
+
+import (
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var _ influxdb.NotificationEndpoint = &Teams{}
+
+const teamsURLSuffix = "-url"
+
+// Teams is the notification endpoint config of a Microsoft Teams incoming webhook.
+type Teams struct {
+	Base
+	// URL is the incoming webhook URL of the channel, see
+	// https://docs.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/add-incoming-webhook
+	// It authorizes posting to the channel, so it is kept as a secret.
+	URL influxdb.SecretField `json:"url"`
+}
+
+// BackfillSecretKeys fill back the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *Teams) BackfillSecretKeys() {
+	if s.URL.Key == "" && s.URL.Value != nil {
+		s.URL.Key = s.idStr() + teamsURLSuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s Teams) SecretFields() []influxdb.SecretField {
+	arr := []influxdb.SecretField{}
+	if s.URL.Key != "" {
+		arr = append(arr, s.URL)
+	}
+	return arr
+}
+
+// Valid returns error if some configuration is invalid
+func (s Teams) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL.Key == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "empty teams webhook URL",
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Teams) MarshalJSON() ([]byte, error) {
+	type teamsAlias Teams
+	return json.Marshal(
+		struct {
+			teamsAlias
+			Type string `json:"type"`
+		}{
+			teamsAlias: teamsAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Teams) Type() string {
+	return TeamsType
+}
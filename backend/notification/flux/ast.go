@@ -29,6 +29,15 @@ func LessThan(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// LessThanEqual returns a less than or equal to *ast.BinaryExpression.
+func LessThanEqual(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.LessThanEqualOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Equal returns an equal to *ast.BinaryExpression.
 func Equal(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	return &ast.BinaryExpression{
@@ -38,6 +47,15 @@ func Equal(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// NotEqual returns a not equal to *ast.BinaryExpression.
+func NotEqual(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.NotEqualOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Subtract returns a subtraction *ast.BinaryExpression.
 func Subtract(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	return &ast.BinaryExpression{
@@ -56,6 +74,33 @@ func Add(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// Multiply returns a multiplication *ast.BinaryExpression.
+func Multiply(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.MultiplicationOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Divide returns a division *ast.BinaryExpression.
+func Divide(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.DivisionOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Modulo returns a modulo *ast.BinaryExpression.
+func Modulo(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.ModuloOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Member returns an *ast.MemberExpression where the key is p and the values is c.
 func Member(p, c string) *ast.MemberExpression {
 	return &ast.MemberExpression{
@@ -82,6 +127,14 @@ func Or(lhs, rhs ast.Expression) *ast.LogicalExpression {
 	}
 }
 
+// Not returns a not *ast.UnaryExpression.
+func Not(e ast.Expression) *ast.UnaryExpression {
+	return &ast.UnaryExpression{
+		Operator: ast.NotOperator,
+		Argument: e,
+	}
+}
+
 // If returns an *ast.ConditionalExpression
 func If(test, consequent, alternate ast.Expression) *ast.ConditionalExpression {
 	return &ast.ConditionalExpression{
@@ -0,0 +1,49 @@
+package preview
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ PreviewService = (*AuthedService)(nil)
+
+// AuthedService authorizes previews. The queries of previews are executed
+// with the authorizer on context, so the buckets they read are authorized
+// by the query service.
+type AuthedService struct {
+	s PreviewService
+}
+
+// NewAuthedService constructs an instance of an authorizing preview service.
+func NewAuthedService(s PreviewService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+// PreviewCheck checks to see if the authorizer on context has read access to the checks of the organization of the check.
+func (s *AuthedService) PreviewCheck(ctx context.Context, cp CheckPreview) (*Preview, error) {
+	if err := cp.Valid(); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.ChecksResourceType, cp.Check.GetOrgID()); err != nil {
+		return nil, err
+	}
+	return s.s.PreviewCheck(ctx, cp)
+}
+
+// PreviewNotificationRule checks to see if the authorizer on context has read access to the notification rules
+// of the organization of the rule, and to the endpoint of the rule.
+func (s *AuthedService) PreviewNotificationRule(ctx context.Context, np NotificationRulePreview) (*Preview, error) {
+	if err := np.Valid(); err != nil {
+		return nil, err
+	}
+	nr := np.NotificationRule
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, nr.GetOrgID()); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotificationEndpointResourceType, nr.GetEndpointID(), nr.GetOrgID()); err != nil {
+		return nil, err
+	}
+	return s.s.PreviewNotificationRule(ctx, np)
+}
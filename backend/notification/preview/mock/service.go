@@ -0,0 +1,39 @@
+// Package mock contains a mock implementation of the preview service.
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/notification/preview"
+)
+
+var _ preview.PreviewService = &PreviewService{}
+
+// PreviewService is a mock implementation of preview.PreviewService.
+type PreviewService struct {
+	PreviewCheckFn            func(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error)
+	PreviewNotificationRuleFn func(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error)
+}
+
+// NewPreviewService returns a mock PreviewService where its methods return
+// empty previews.
+func NewPreviewService() *PreviewService {
+	return &PreviewService{
+		PreviewCheckFn: func(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error) {
+			return &preview.Preview{Records: []preview.Record{}}, nil
+		},
+		PreviewNotificationRuleFn: func(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error) {
+			return &preview.Preview{Records: []preview.Record{}}, nil
+		},
+	}
+}
+
+// PreviewCheck calls PreviewCheckFn.
+func (s *PreviewService) PreviewCheck(ctx context.Context, cp preview.CheckPreview) (*preview.Preview, error) {
+	return s.PreviewCheckFn(ctx, cp)
+}
+
+// PreviewNotificationRule calls PreviewNotificationRuleFn.
+func (s *PreviewService) PreviewNotificationRule(ctx context.Context, np preview.NotificationRulePreview) (*preview.Preview, error) {
+	return s.PreviewNotificationRuleFn(ctx, np)
+}
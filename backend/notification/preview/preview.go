@@ -0,0 +1,132 @@
+// Package preview provides previews of checks and notification rules, which
+// execute their flux for every time they would have run within a range of
+// time, without writing to the _monitoring bucket or sending notifications,
+// and return the statuses or notifications they would have produced.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// MaxEvaluations is the maximum number of times a single preview executes
+// its check or notification rule.
+const MaxEvaluations = 1000
+
+// PreviewService previews checks and notification rules.
+type PreviewService interface {
+	// PreviewCheck returns the statuses the check would have written if it
+	// had run over the range of time of the preview.
+	PreviewCheck(ctx context.Context, cp CheckPreview) (*Preview, error)
+
+	// PreviewNotificationRule returns the notifications the rule would have
+	// sent if it had run over the range of time of the preview, from the
+	// statuses stored in the _monitoring bucket.
+	PreviewNotificationRule(ctx context.Context, np NotificationRulePreview) (*Preview, error)
+}
+
+// CheckPreview is the request to preview a check between Start and Stop.
+// The check doesn't need to exist, so that a check can be previewed while
+// it is edited.
+type CheckPreview struct {
+	Check influxdb.Check
+	Start time.Time
+	Stop  time.Time
+}
+
+// Valid returns an error if the check preview request is invalid.
+func (cp CheckPreview) Valid() error {
+	if cp.Check == nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview check is required",
+		}
+	}
+	return validRange(cp.Start, cp.Stop)
+}
+
+// NotificationRulePreview is the request to preview a notification rule
+// between Start and Stop. The rule doesn't need to exist, but its endpoint
+// does.
+type NotificationRulePreview struct {
+	NotificationRule influxdb.NotificationRule
+	Start            time.Time
+	Stop             time.Time
+}
+
+// Valid returns an error if the notification rule preview request is invalid.
+func (np NotificationRulePreview) Valid() error {
+	if np.NotificationRule == nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview notification rule is required",
+		}
+	}
+	return validRange(np.Start, np.Stop)
+}
+
+func validRange(start, stop time.Time) error {
+	if start.IsZero() || stop.IsZero() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview start and stop are required",
+		}
+	}
+	if !stop.After(start) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview stop must be after start",
+		}
+	}
+	return nil
+}
+
+// Record is a row of the output of a check or notification rule, by column.
+type Record map[string]interface{}
+
+// Preview is the output of a check or notification rule over a range of time.
+type Preview struct {
+	// Evaluations is the number of times the check or rule was executed.
+	Evaluations int      `json:"evaluations"`
+	Records     []Record `json:"records"`
+}
+
+// EvaluationTimes returns the times a check or notification rule running
+// every interval would have run at after start and until stop. Like the
+// task scheduler, the times are multiples of every.
+func EvaluationTimes(every time.Duration, start, stop time.Time) ([]time.Time, error) {
+	if every <= 0 {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview requires a positive interval",
+		}
+	}
+
+	next := start.Truncate(every)
+	if !next.After(start) {
+		next = next.Add(every)
+	}
+
+	var times []time.Time
+	for ; !next.After(stop); next = next.Add(every) {
+		if len(times) == MaxEvaluations {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("preview range exceeds the maximum of %d evaluations", MaxEvaluations),
+			}
+		}
+		times = append(times, next)
+	}
+
+	if len(times) == 0 {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview range contains no evaluation times",
+		}
+	}
+	return times, nil
+}
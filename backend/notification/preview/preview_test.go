@@ -0,0 +1,113 @@
+package preview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreview_Valid(t *testing.T) {
+	var (
+		start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		stop  = start.Add(time.Hour)
+	)
+
+	cases := []struct {
+		name string
+		src  interface{ Valid() error }
+		err  error
+	}{
+		{
+			name: "valid check",
+			src:  CheckPreview{Check: &check.Deadman{}, Start: start, Stop: stop},
+		},
+		{
+			name: "valid notification rule",
+			src:  NotificationRulePreview{NotificationRule: &rule.Slack{}, Start: start, Stop: stop},
+		},
+		{
+			name: "missing check",
+			src:  CheckPreview{Start: start, Stop: stop},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "preview check is required",
+			},
+		},
+		{
+			name: "missing notification rule",
+			src:  NotificationRulePreview{Start: start, Stop: stop},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "preview notification rule is required",
+			},
+		},
+		{
+			name: "missing stop",
+			src:  CheckPreview{Check: &check.Deadman{}, Start: start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "preview start and stop are required",
+			},
+		},
+		{
+			name: "stop before start",
+			src:  NotificationRulePreview{NotificationRule: &rule.Slack{}, Start: stop, Stop: start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "preview stop must be after start",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.err, c.src.Valid())
+		})
+	}
+}
+
+func TestEvaluationTimes(t *testing.T) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("aligned to every", func(t *testing.T) {
+		times, err := EvaluationTimes(time.Minute, start.Add(30*time.Second), start.Add(3*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			start.Add(time.Minute),
+			start.Add(2 * time.Minute),
+			start.Add(3 * time.Minute),
+		}, times)
+	})
+
+	t.Run("excludes start", func(t *testing.T) {
+		times, err := EvaluationTimes(time.Hour, start, start.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}, times)
+	})
+
+	t.Run("invalid every", func(t *testing.T) {
+		_, err := EvaluationTimes(0, start, start.Add(time.Hour))
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("no times", func(t *testing.T) {
+		_, err := EvaluationTimes(time.Hour, start.Add(time.Minute), start.Add(time.Minute+time.Second))
+		assert.Equal(t, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview range contains no evaluation times",
+		}, err)
+	})
+
+	t.Run("too many times", func(t *testing.T) {
+		_, err := EvaluationTimes(time.Second, start, start.Add((MaxEvaluations+1)*time.Second))
+		assert.Equal(t, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "preview range exceeds the maximum of 1000 evaluations",
+		}, err)
+	})
+}
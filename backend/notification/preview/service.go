@@ -0,0 +1,462 @@
+package preview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/jsonweb"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"go.uber.org/zap"
+)
+
+const monitorPackage = "influxdata/influxdb/monitor"
+
+// placeholderID identifies the checks and notification rules previewed
+// before they are created, in the records of their preview.
+const placeholderID = platform.ID(1)
+
+var _ PreviewService = (*Service)(nil)
+
+// Service is an implementation of PreviewService that executes the flux of
+// checks and notification rules with the query service. The flux is the one
+// of their task, rewritten so that statuses and notifications are returned
+// instead of written to the _monitoring bucket, and notifications aren't
+// sent to their endpoint.
+//
+// The flux of custom checks is written by users, so the previews of checks
+// calling the functions of the flux standard library that write or send
+// data are rejected (see readOnly). Functions with side effects that aren't
+// known to readOnly, such as those of third party packages, are executed by
+// the preview like by the task of the check.
+type Service struct {
+	log  *zap.Logger
+	qs   query.QueryService
+	lang fluxlang.FluxLanguageService
+	es   influxdb.NotificationEndpointService
+}
+
+// NewService constructs a preview service executing queries with qs. es is
+// used to look up the endpoint of notification rules.
+func NewService(log *zap.Logger, qs query.QueryService, lang fluxlang.FluxLanguageService, es influxdb.NotificationEndpointService) *Service {
+	return &Service{
+		log:  log,
+		qs:   qs,
+		lang: lang,
+		es:   es,
+	}
+}
+
+// PreviewCheck executes the check for every time it would have run between
+// the start and stop of the preview, and returns the statuses it would have
+// written.
+func (s *Service) PreviewCheck(ctx context.Context, cp CheckPreview) (*Preview, error) {
+	if err := cp.Valid(); err != nil {
+		return nil, err
+	}
+
+	chk := cp.Check
+	if !chk.GetID().Valid() {
+		chk.SetID(placeholderID)
+	}
+	if err := chk.Valid(s.lang); err != nil {
+		return nil, err
+	}
+
+	script, err := chk.GenerateFlux(s.lang)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "failed to generate check flux",
+			Err:  err,
+		}
+	}
+	f, err := s.parse(script)
+	if err != nil {
+		return nil, err
+	}
+	if err := readOnly(f); err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, chk.GetOrgID(), f, cp.Start, cp.Stop)
+}
+
+// PreviewNotificationRule executes the notification rule for every time it
+// would have run between the start and stop of the preview, and returns the
+// notifications it would have sent to its endpoint.
+func (s *Service) PreviewNotificationRule(ctx context.Context, np NotificationRulePreview) (*Preview, error) {
+	if err := np.Valid(); err != nil {
+		return nil, err
+	}
+
+	nr := np.NotificationRule
+	if !nr.GetID().Valid() {
+		nr.SetID(placeholderID)
+	}
+	if !nr.GetOwnerID().Valid() {
+		nr.SetOwnerID(placeholderID)
+	}
+	if err := nr.Valid(); err != nil {
+		return nil, err
+	}
+
+	edp, err := s.es.FindNotificationEndpointByID(ctx, nr.GetEndpointID())
+	if err != nil {
+		return nil, err
+	}
+	if edp.GetOrgID() != nr.GetOrgID() {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "notification rule endpoint must belong to the organization of the rule",
+		}
+	}
+
+	script, err := nr.GenerateFlux(edp)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "failed to generate notification rule flux",
+			Err:  err,
+		}
+	}
+	f, err := s.parse(script)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, nr.GetOrgID(), f, np.Start, np.Stop)
+}
+
+// parse returns the single file of script.
+func (s *Service) parse(script string) (*ast.File, error) {
+	pkg, err := query.Parse(s.lang, script)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkg.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(pkg.Files))
+	}
+	return pkg.Files[0], nil
+}
+
+// preview executes f for every time its task would have run between start
+// and stop, with the options of the monitor package persisting its output
+// replaced so that the output is returned instead.
+func (s *Service) preview(ctx context.Context, orgID platform.ID, f *ast.File, start, stop time.Time) (*Preview, error) {
+	times, err := EvaluationTimes(taskEvery(f), start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	dryRun(f)
+	script, err := astutil.Format(f)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := authorization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Preview{
+		Evaluations: len(times),
+		Records:     []Record{},
+	}
+	for _, now := range times {
+		records, err := s.query(ctx, auth, script, now)
+		if err != nil {
+			return nil, &errors.Error{
+				Msg: fmt.Sprintf("preview failed at %s", now.Format(time.RFC3339)),
+				Err: err,
+			}
+		}
+		p.Records = append(p.Records, records...)
+	}
+	return p, nil
+}
+
+// query executes script as if it was now, returning every row of its output.
+func (s *Service) query(ctx context.Context, auth *influxdb.Authorization, script string, now time.Time) ([]Record, error) {
+	request := &query.Request{
+		Authorization:  auth,
+		OrganizationID: auth.OrgID,
+		Compiler:       lang.FluxCompiler{Now: now, Query: script},
+	}
+	ittr, err := s.qs.Query(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer ittr.Release()
+
+	var records []Record
+	readTable := func(tbl flux.Table) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			cols := cr.Cols()
+			for i := 0; i < cr.Len(); i++ {
+				r := make(Record, len(cols))
+				for j, col := range cols {
+					r[col.Label] = unwrap(execute.ValueForRow(cr, i, j))
+				}
+				records = append(records, r)
+			}
+			return nil
+		})
+	}
+	for ittr.More() {
+		if err := ittr.Next().Tables().Do(readTable); err != nil {
+			return nil, err
+		}
+	}
+	if err := ittr.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func unwrap(v values.Value) interface{} {
+	u := values.Unwrap(v)
+	if t, ok := u.(values.Time); ok {
+		return t.Time()
+	}
+	return u
+}
+
+// authorization returns the authorization the queries of a preview in the
+// organization are executed with, which is the one of the authorizer on
+// context.
+func authorization(ctx context.Context, orgID platform.ID) (*influxdb.Authorization, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		return a, nil
+	case *influxdb.Session:
+		return a.EphemeralAuth(orgID), nil
+	case *jsonweb.Token:
+		return a.EphemeralAuth(orgID), nil
+	default:
+		return nil, influxdb.ErrAuthorizerNotSupported
+	}
+}
+
+// taskEvery returns the every of the task option of f, or 0 when it isn't
+// set.
+func taskEvery(f *ast.File) time.Duration {
+	for _, st := range f.Body {
+		option, ok := st.(*ast.OptionStatement)
+		if !ok {
+			continue
+		}
+		assign, ok := option.Assignment.(*ast.VariableAssignment)
+		if !ok || assign.ID.Name != "task" {
+			continue
+		}
+		obj, ok := assign.Init.(*ast.ObjectExpression)
+		if !ok {
+			return 0
+		}
+		for _, prop := range obj.Properties {
+			if lit, ok := prop.Value.(*ast.DurationLiteral); ok && prop.Key.Key() == "every" {
+				return notification.Duration(*lit).TimeDuration()
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// dryRun rewrites f so that the monitor package doesn't have side effects.
+// The options of the monitor package persisting statuses (write) and
+// notifications (log) are replaced with a function returning their tables,
+// and the endpoints of notifications are replaced with a function marking
+// the notifications as unsent.
+func dryRun(f *ast.File) {
+	monitor := ""
+	for _, imp := range f.Imports {
+		if imp.Path.Value != monitorPackage {
+			continue
+		}
+		monitor = "monitor"
+		if imp.As != nil {
+			monitor = imp.As.Name
+		}
+	}
+	if monitor == "" {
+		return
+	}
+
+	var passthrough []ast.Statement
+	for _, option := range []string{"write", "log"} {
+		passthrough = append(passthrough, &ast.OptionStatement{
+			Assignment: &ast.MemberAssignment{
+				Member: &ast.MemberExpression{
+					Object:   &ast.Identifier{Name: monitor},
+					Property: &ast.Identifier{Name: option},
+				},
+				Init: pipeFunction(&ast.Identifier{Name: "tables"}),
+			},
+		})
+	}
+	f.Body = append(passthrough, f.Body...)
+
+	unsent := pipeFunction(&ast.PipeExpression{
+		Argument: &ast.Identifier{Name: "tables"},
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{Name: "map"},
+			Arguments: []ast.Expression{&ast.ObjectExpression{
+				Properties: []*ast.Property{{
+					Key: &ast.Identifier{Name: "fn"},
+					Value: &ast.FunctionExpression{
+						Params: []*ast.Property{{Key: &ast.Identifier{Name: "r"}}},
+						Body: &ast.ObjectExpression{
+							With: &ast.Identifier{Name: "r"},
+							Properties: []*ast.Property{{
+								Key:   &ast.Identifier{Name: "_sent"},
+								Value: &ast.StringLiteral{Value: "false"},
+							}},
+						},
+					},
+				}},
+			}},
+		},
+	})
+	ast.Visit(f, func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok || !isMember(call.Callee, monitor, "notify") || len(call.Arguments) != 1 {
+			return
+		}
+		args, ok := call.Arguments[0].(*ast.ObjectExpression)
+		if !ok {
+			return
+		}
+		for _, prop := range args.Properties {
+			if prop.Key.Key() == "endpoint" {
+				prop.Value = unsent
+			}
+		}
+	})
+}
+
+// writeFunctions are the functions of the flux standard library that write
+// or send data, by package. The to function of the universe, which writes to
+// a bucket, is rejected by readOnly as well.
+var writeFunctions = map[string][]string{
+	"influxdata/influxdb":        {"to", "wideTo"},
+	"experimental":               {"to"},
+	"experimental/mqtt":          {"to", "publish"},
+	"experimental/http/requests": {"do", "get", "post"},
+	"http":                       {"post"},
+	"http/requests":              {"do", "get", "post"},
+	"kafka":                      {"to"},
+	"sql":                        {"to"},
+}
+
+// notificationPackages are the packages of the flux standard library that
+// send notifications, whose functions aren't used by checks.
+var notificationPackages = []string{
+	"contrib/bonitoo-io/alerta",
+	"contrib/bonitoo-io/servicenow",
+	"contrib/bonitoo-io/victorops",
+	"contrib/bonitoo-io/zenoss",
+	"contrib/chobbs/discord",
+	"contrib/rhajek/bigpanda",
+	"contrib/sranka/opsgenie",
+	"contrib/sranka/sensu",
+	"contrib/sranka/teams",
+	"contrib/sranka/telegram",
+	"contrib/sranka/webexteams",
+	"pagerduty",
+	"pushbullet",
+	"slack",
+}
+
+// readOnly returns an error if f imports a package sending notifications,
+// or refers to a function in writeFunctions. Executing f in a preview
+// wouldn't return the output of those functions, but write or send it.
+func readOnly(f *ast.File) error {
+	functions := map[string][]string{}
+	for _, imp := range f.Imports {
+		path := imp.Path.Value
+		for _, p := range notificationPackages {
+			if path == p {
+				return &errors.Error{
+					Code: errors.EInvalid,
+					Msg:  fmt.Sprintf("cannot preview a check importing package %q, which sends notifications", path),
+				}
+			}
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.As != nil {
+			name = imp.As.Name
+		}
+		functions[name] = writeFunctions[path]
+	}
+
+	var err error
+	ast.Visit(f, func(n ast.Node) {
+		if err != nil {
+			return
+		}
+		switch n := n.(type) {
+		case *ast.CallExpression:
+			if id, ok := n.Callee.(*ast.Identifier); ok && id.Name == "to" {
+				err = writeError("to")
+			}
+		case *ast.MemberExpression:
+			obj, ok := n.Object.(*ast.Identifier)
+			if !ok {
+				return
+			}
+			for _, fn := range functions[obj.Name] {
+				if n.Property.Key() == fn {
+					err = writeError(obj.Name + "." + fn)
+				}
+			}
+		}
+	})
+	return err
+}
+
+func writeError(fn string) error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  fmt.Sprintf("cannot preview a check calling %s, which writes or sends data", fn),
+	}
+}
+
+// pipeFunction returns the function (tables=<-) => body.
+func pipeFunction(body ast.Expression) *ast.FunctionExpression {
+	return &ast.FunctionExpression{
+		Params: []*ast.Property{{
+			Key:   &ast.Identifier{Name: "tables"},
+			Value: &ast.PipeLiteral{},
+		}},
+		Body: body,
+	}
+}
+
+// isMember returns whether e is the member property of the identifier obj,
+// as either obj.property or obj["property"].
+func isMember(e ast.Expression, obj, property string) bool {
+	m, ok := e.(*ast.MemberExpression)
+	if !ok {
+		return false
+	}
+	id, ok := m.Object.(*ast.Identifier)
+	return ok && id.Name == obj && m.Property.Key() == property
+}
@@ -0,0 +1,171 @@
+package preview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		every  time.Duration
+		want   string
+	}{
+		{
+			name: "check",
+			script: `import "influxdata/influxdb/monitor"
+
+data = from(bucket: "foo") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user")
+
+option task = {name: "moo", every: 1m, offset: 10s}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "threshold", tags: {}}
+crit = (r) => r["usage_user"] > 90.0
+messageFn = (r) => "whoa!"
+
+data |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+			every: time.Minute,
+			want: `import "influxdata/influxdb/monitor"
+
+option monitor.write = (tables=<-) => tables
+
+option monitor.log = (tables=<-) => tables
+
+data = from(bucket: "foo") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user")
+
+option task = {name: "moo", every: 1m, offset: 10s}
+
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "threshold", tags: {}}
+crit = (r) => r["usage_user"] > 90.0
+messageFn = (r) => "whoa!"
+
+data |> monitor["check"](data: check, messageFn: messageFn, crit: crit)
+`,
+		},
+		{
+			name: "notification rule",
+			script: `import "influxdata/influxdb/monitor"
+import "slack"
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack["endpoint"](url: "http://localhost:7777")
+notification = {_notification_rule_id: "0000000000000001", _notification_rule_name: "foo", _notification_endpoint_id: "0000000000000002", _notification_endpoint_name: "foo"}
+statuses = monitor["from"](start: -2h)
+crit = statuses |> filter(fn: (r) => r["_level"] == "crit")
+
+crit
+    |> monitor["notify"](data: notification, endpoint: slack_endpoint(mapFn: (r) => ({channel: "bar", text: "blah", color: "danger"})))
+`,
+			every: time.Hour,
+			want: `import "influxdata/influxdb/monitor"
+import "slack"
+
+option monitor.write = (tables=<-) => tables
+
+option monitor.log = (tables=<-) => tables
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack["endpoint"](url: "http://localhost:7777")
+notification = {_notification_rule_id: "0000000000000001", _notification_rule_name: "foo", _notification_endpoint_id: "0000000000000002", _notification_endpoint_name: "foo"}
+statuses = monitor["from"](start: -2h)
+crit = statuses |> filter(fn: (r) => r["_level"] == "crit")
+
+crit
+    |> monitor["notify"](data: notification, endpoint: (tables=<-) => tables |> map(fn: (r) => ({r with _sent: "false"})))
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := parser.ParseSource(tt.script).Files[0]
+			assert.Equal(t, tt.every, taskEvery(f))
+
+			dryRun(f)
+			s, err := astutil.Format(f)
+			require.NoError(t, err)
+			assert.Equal(t, itesting.FormatFluxString(t, tt.want), s)
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    error
+	}{
+		{
+			name: "check",
+			script: `import "influxdata/influxdb/monitor"
+import "experimental"
+
+data = from(bucket: "foo") |> range(start: -1m) |> experimental.group(columns: ["host"], mode: "extend")
+check = {_check_id: "000000000000000a", _check_name: "moo", _type: "custom", tags: {}}
+
+data |> monitor["check"](data: check, messageFn: (r) => "whoa!", crit: (r) => r._value > 90.0)
+`,
+		},
+		{
+			name: "to",
+			script: `from(bucket: "foo") |> range(start: -1m) |> to(bucket: "bar")
+`,
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "cannot preview a check calling to, which writes or sends data",
+			},
+		},
+		{
+			name: "experimental.to",
+			script: `import "experimental"
+
+from(bucket: "foo") |> range(start: -1m) |> experimental["to"](bucket: "bar")
+`,
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "cannot preview a check calling experimental.to, which writes or sends data",
+			},
+		},
+		{
+			name: "aliased http.post",
+			script: `import h "http"
+
+post = h.post
+from(bucket: "foo") |> range(start: -1m) |> map(fn: (r) => ({r with code: post(url: "http://localhost:7777")}))
+`,
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "cannot preview a check calling h.post, which writes or sends data",
+			},
+		},
+		{
+			name: "notification package",
+			script: `import "slack"
+
+from(bucket: "foo") |> range(start: -1m)
+`,
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `cannot preview a check importing package "slack", which sends notifications`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := parser.ParseSource(tt.script).Files[0]
+			assert.Equal(t, tt.err, readOnly(f))
+		})
+	}
+}
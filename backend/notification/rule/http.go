@@ -3,10 +3,14 @@ package rule
 import (
 	"encoding/json"
 	"fmt"
+	"net/textproto"
+	"sort"
+	"strings"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/ast/astutil"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
@@ -14,6 +18,14 @@ import (
 // HTTP is the notification rule config of http.
 type HTTP struct {
 	Base
+	// BodyTemplate replaces the default JSON body, which is the status
+	// record, when provided. It is written in BodyTemplateSyntax, which is
+	// either BodyTemplateGo (the default) or BodyTemplateFlux.
+	BodyTemplate       string `json:"bodyTemplate,omitempty"`
+	BodyTemplateSyntax string `json:"bodyTemplateSyntax,omitempty"`
+	// Headers are added to the request, overriding the default Content-Type.
+	// The Authorization header of the endpoint takes precedence.
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // GenerateFlux generates a flux script for the http notification rule.
@@ -37,6 +49,13 @@ func (s *HTTP) imports(e *endpoint.HTTP) []*ast.ImportDeclaration {
 		"json",
 		"experimental",
 	}
+	if s.BodyTemplate != "" {
+		packages = []string{
+			"influxdata/influxdb/monitor",
+			"http",
+			"experimental",
+		}
+	}
 
 	if e.AuthMethod == "bearer" || e.AuthMethod == "basic" {
 		packages = append(packages, "influxdata/influxdb/secrets")
@@ -59,11 +78,35 @@ func (s *HTTP) generateFluxASTBody(e *endpoint.HTTP) []ast.Statement {
 }
 
 func (s *HTTP) generateHeaders(e *endpoint.HTTP) ast.Statement {
+	contentType := "application/json"
+	custom := make(map[string]string)
+	for k, v := range s.Headers {
+		switch k = textproto.CanonicalMIMEHeaderKey(k); k {
+		case "Content-Type":
+			contentType = v
+		case "Authorization":
+			if e.AuthMethod == "bearer" || e.AuthMethod == "basic" {
+				continue
+			}
+			custom[k] = v
+		default:
+			custom[k] = v
+		}
+	}
+	keys := make([]string, 0, len(custom))
+	for k := range custom {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	props := []*ast.Property{
 		flux.Dictionary(
-			"Content-Type", flux.String("application/json"),
+			"Content-Type", flux.String(contentType),
 		),
 	}
+	for _, k := range keys {
+		props = append(props, flux.Dictionary(k, flux.String(custom[k])))
+	}
 
 	switch e.AuthMethod {
 	case "bearer":
@@ -118,6 +161,12 @@ func (s *HTTP) generateFluxASTNotifyPipe() ast.Statement {
 		flux.Member("json", "encode"),
 		flux.Object(flux.Property("v", flux.Identifier("body"))),
 	)
+	if s.BodyTemplate != "" {
+		endpointBody = flux.Call(
+			flux.Identifier("bytes"),
+			flux.Object(flux.Property("v", flux.Identifier("body"))),
+		)
+	}
 	headers := flux.Property("headers", flux.Identifier("headers"))
 
 	endpointProps := []*ast.Property{
@@ -142,6 +191,12 @@ func (s *HTTP) generateFluxASTNotifyPipe() ast.Statement {
 }
 
 func (s *HTTP) generateBody() ast.Statement {
+	if s.BodyTemplate != "" {
+		// the template is validated before the flux is generated
+		body, _ := compileBodyTemplate(s.BodyTemplateSyntax, s.BodyTemplate)
+		return flux.DefineVariable("body", body)
+	}
+
 	// {r with "_version": 1}
 	props := []*ast.Property{
 		flux.Property(
@@ -172,9 +227,50 @@ func (s HTTP) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
+	if s.BodyTemplate != "" {
+		if _, err := compileBodyTemplate(s.BodyTemplateSyntax, s.BodyTemplate); err != nil {
+			return err
+		}
+	} else if s.BodyTemplateSyntax != "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "body template syntax provided without a body template",
+		}
+	}
+	for k, v := range s.Headers {
+		if !validHeaderName(k) {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("invalid header name %q", k),
+			}
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("invalid value of header %q", k),
+			}
+		}
+	}
 	return nil
 }
 
+// validHeaderName reports whether name is a valid HTTP header field name,
+// which is a non empty token as defined by RFC 7230.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // Type returns the type of the rule config.
 func (s HTTP) Type() string {
 	return "http"
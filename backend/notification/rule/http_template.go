@@ -0,0 +1,175 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// Syntaxes of the body template of http notification rules.
+const (
+	// BodyTemplateGo is a Go text/template, with access to .CheckName, .Level,
+	// .Message, .Tags.<key> and .Fields.<key>, e.g. {{.CheckName}} is {{.Level}}.
+	BodyTemplateGo = "go"
+	// BodyTemplateFlux is a Flux string interpolation over the status record r,
+	// e.g. ${r._check_name} is ${r._level}.
+	BodyTemplateFlux = "flux"
+)
+
+// goTemplateColumns maps the fields of a Go body template to the columns of
+// the status record.
+var goTemplateColumns = map[string]string{
+	"CheckName": "_check_name",
+	"Level":     "_level",
+	"Message":   "_message",
+}
+
+// compileBodyTemplate compiles a body template into a flux string expression
+// that is evaluated against the status record r.
+func compileBodyTemplate(syntax, tmpl string) (ast.Expression, error) {
+	var (
+		parts []ast.StringExpressionPart
+		err   error
+	)
+	switch syntax {
+	case "", BodyTemplateGo:
+		parts, err = compileGoTemplate(tmpl)
+	case BodyTemplateFlux:
+		parts, err = compileFluxTemplate(tmpl)
+	default:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("invalid body template syntax %q; valid syntaxes are %s and %s", syntax, BodyTemplateGo, BodyTemplateFlux),
+		}
+	}
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("invalid body template: %v", err),
+		}
+	}
+
+	var text strings.Builder
+	for _, p := range parts {
+		t, ok := p.(*ast.TextPart)
+		if !ok {
+			return &ast.StringExpression{Parts: parts}, nil
+		}
+		text.WriteString(t.Value)
+	}
+	return flux.String(text.String()), nil
+}
+
+func compileGoTemplate(tmpl string) ([]ast.StringExpressionPart, error) {
+	t, err := template.New("body").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if t.Tree == nil {
+		return nil, nil
+	}
+
+	var parts []ast.StringExpressionPart
+	for _, node := range t.Tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			parts = append(parts, &ast.TextPart{Value: string(n.Text)})
+		case *parse.CommentNode:
+		case *parse.ActionNode:
+			column, err := goTemplateColumn(n)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, &ast.InterpolatedPart{Expression: flux.Member("r", column)})
+		default:
+			return nil, fmt.Errorf("unsupported template action %s; only fields may be referenced", node)
+		}
+	}
+	return parts, nil
+}
+
+// goTemplateColumn returns the column referenced by an action, which is
+// either a field such as {{.Level}} and {{.Tags.host}}, or an index such
+// as {{index .Tags "host"}}.
+func goTemplateColumn(n *parse.ActionNode) (string, error) {
+	unsupported := fmt.Errorf("unsupported template action %s; only fields may be referenced", n)
+	if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 {
+		return "", unsupported
+	}
+
+	var path []string
+	switch args := n.Pipe.Cmds[0].Args; len(args) {
+	case 1:
+		field, ok := args[0].(*parse.FieldNode)
+		if !ok {
+			return "", unsupported
+		}
+		path = field.Ident
+	case 3:
+		fn, ok := args[0].(*parse.IdentifierNode)
+		if !ok || fn.Ident != "index" {
+			return "", unsupported
+		}
+		field, ok := args[1].(*parse.FieldNode)
+		if !ok {
+			return "", unsupported
+		}
+		key, ok := args[2].(*parse.StringNode)
+		if !ok {
+			return "", unsupported
+		}
+		path = append(append(path, field.Ident...), key.Text)
+	default:
+		return "", unsupported
+	}
+
+	switch {
+	case len(path) == 1 && goTemplateColumns[path[0]] != "":
+		return goTemplateColumns[path[0]], nil
+	case len(path) == 2 && (path[0] == "Tags" || path[0] == "Fields"):
+		return path[1], nil
+	}
+	return "", fmt.Errorf("unknown template field .%s; valid fields are .CheckName, .Level, .Message, .Tags and .Fields", strings.Join(path, "."))
+}
+
+// fluxTemplateColumn matches the expressions of a flux body template, which
+// reference a column of r as r.column or r["column"].
+var fluxTemplateColumn = regexp.MustCompile(`^r(?:\.([A-Za-z_][A-Za-z0-9_]*)|\["([^"\\]+)"\])$`)
+
+func compileFluxTemplate(tmpl string) ([]ast.StringExpressionPart, error) {
+	var parts []ast.StringExpressionPart
+	for rest := tmpl; rest != ""; {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			parts = append(parts, &ast.TextPart{Value: rest})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, &ast.TextPart{Value: rest[:start]})
+		}
+		rest = rest[start+2:]
+
+		end := strings.Index(rest, "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated interpolation ${%s", rest)
+		}
+		expr := strings.TrimSpace(rest[:end])
+		m := fluxTemplateColumn.FindStringSubmatch(expr)
+		if m == nil {
+			return nil, fmt.Errorf("unsupported interpolation ${%s}; only columns of r may be referenced", expr)
+		}
+		column := m[1]
+		if column == "" {
+			column = m[2]
+		}
+		parts = append(parts, &ast.InterpolatedPart{Expression: flux.Member("r", column)})
+		rest = rest[end+1:]
+	}
+	return parts, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/andreyvit/diff"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/notification"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/rule"
@@ -314,3 +315,238 @@ all_statuses
 	require.NoError(t, err)
 	assert.Equal(t, want, f)
 }
+
+func TestHTTP_GenerateFlux_bodyTemplate(t *testing.T) {
+	want := itesting.FormatFluxString(t, `import "influxdata/influxdb/monitor"
+import "http"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+headers = {"Content-Type": "text/plain", "X-Api-Version": "2"}
+endpoint = http["endpoint"](url: "http://localhost:7777")
+notification = {
+    _notification_rule_id: "0000000000000001",
+    _notification_rule_name: "foo",
+    _notification_endpoint_id: "0000000000000002",
+    _notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses |> filter(fn: (r) => r["_level"] == "crit")
+all_statuses = crit |> filter(fn: (r) => r["_time"] >= experimental["subDuration"](from: now(), d: 1h))
+
+all_statuses
+    |> monitor["notify"](
+        data: notification,
+        endpoint:
+            endpoint(
+                mapFn: (r) => {
+                    body = "${r["_check_name"]} on ${r["host"]} is ${r["_level"]}: ${r["usage_user"]}"
+
+                    return {headers: headers, data: bytes(v: body)}
+                },
+            ),
+    )
+`)
+
+	id := platform.ID(2)
+	e := &endpoint.HTTP{
+		Base: endpoint.Base{
+			ID:   &id,
+			Name: "foo",
+		},
+		URL: "http://localhost:7777",
+	}
+
+	for _, syntax := range []struct {
+		name     string
+		syntax   string
+		template string
+	}{
+		{
+			name:     "go",
+			syntax:   rule.BodyTemplateGo,
+			template: `{{.CheckName}} on {{.Tags.host}} is {{.Level}}: {{index .Fields "usage_user"}}`,
+		},
+		{
+			name:     "flux",
+			syntax:   rule.BodyTemplateFlux,
+			template: `${r._check_name} on ${r.host} is ${r["_level"]}: ${r.usage_user}`,
+		},
+	} {
+		t.Run(syntax.name, func(t *testing.T) {
+			s := &rule.HTTP{
+				Base: rule.Base{
+					ID:         1,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					EndpointID: 2,
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+				},
+				BodyTemplate:       syntax.template,
+				BodyTemplateSyntax: syntax.syntax,
+				Headers: map[string]string{
+					"content-type":  "text/plain",
+					"x-api-version": "2",
+				},
+			}
+
+			f, err := s.GenerateFlux(e)
+			require.NoError(t, err)
+			assert.Equal(t, want, f)
+		})
+	}
+}
+
+func TestHTTP_Valid(t *testing.T) {
+	base := rule.Base{
+		ID:         1,
+		EndpointID: 3,
+		OwnerID:    4,
+		OrgID:      5,
+		Name:       "foo",
+		Every:      mustDuration("1h"),
+		StatusRules: []notification.StatusRule{
+			{
+				CurrentLevel: notification.Critical,
+			},
+		},
+	}
+	cases := []struct {
+		name string
+		rule *rule.HTTP
+		err  error
+	}{
+		{
+			name: "default body",
+			rule: &rule.HTTP{Base: base},
+		},
+		{
+			name: "valid go template and headers",
+			rule: &rule.HTTP{
+				Base:         base,
+				BodyTemplate: `{"text": "{{.CheckName}} is {{.Level}}: {{.Message}}"}{{/* comment */}}`,
+				Headers:      map[string]string{"X-Api-Key": "abc"},
+			},
+		},
+		{
+			name: "valid flux template",
+			rule: &rule.HTTP{
+				Base:               base,
+				BodyTemplate:       `${r._check_name} on ${ r["host"] }`,
+				BodyTemplateSyntax: rule.BodyTemplateFlux,
+			},
+		},
+		{
+			name: "unparsable go template",
+			rule: &rule.HTTP{
+				Base:         base,
+				BodyTemplate: `{{.CheckName`,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid body template: template: body:1: unclosed action",
+			},
+		},
+		{
+			name: "unknown go template field",
+			rule: &rule.HTTP{
+				Base:         base,
+				BodyTemplate: `{{.Check}}`,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid body template: unknown template field .Check; valid fields are .CheckName, .Level, .Message, .Tags and .Fields",
+			},
+		},
+		{
+			name: "unsupported go template action",
+			rule: &rule.HTTP{
+				Base:         base,
+				BodyTemplate: `{{if .Level}}alert{{end}}`,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid body template: unsupported template action {{if .Level}}alert{{end}}; only fields may be referenced",
+			},
+		},
+		{
+			name: "unsupported flux interpolation",
+			rule: &rule.HTTP{
+				Base:               base,
+				BodyTemplate:       `${now()}`,
+				BodyTemplateSyntax: rule.BodyTemplateFlux,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid body template: unsupported interpolation ${now()}; only columns of r may be referenced",
+			},
+		},
+		{
+			name: "unterminated flux interpolation",
+			rule: &rule.HTTP{
+				Base:               base,
+				BodyTemplate:       `${r._level`,
+				BodyTemplateSyntax: rule.BodyTemplateFlux,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid body template: unterminated interpolation ${r._level",
+			},
+		},
+		{
+			name: "invalid syntax",
+			rule: &rule.HTTP{
+				Base:               base,
+				BodyTemplate:       `blah`,
+				BodyTemplateSyntax: "mustache",
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `invalid body template syntax "mustache"; valid syntaxes are go and flux`,
+			},
+		},
+		{
+			name: "syntax without template",
+			rule: &rule.HTTP{
+				Base:               base,
+				BodyTemplateSyntax: rule.BodyTemplateGo,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "body template syntax provided without a body template",
+			},
+		},
+		{
+			name: "invalid header name",
+			rule: &rule.HTTP{
+				Base:    base,
+				Headers: map[string]string{"X Api Key": "abc"},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `invalid header name "X Api Key"`,
+			},
+		},
+		{
+			name: "invalid header value",
+			rule: &rule.HTTP{
+				Base:    base,
+				Headers: map[string]string{"X-Api-Key": "abc\r\nHost: evil"},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `invalid value of header "X-Api-Key"`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			itesting.ErrorsEqual(t, c.rule.Valid(), c.err)
+		})
+	}
+}
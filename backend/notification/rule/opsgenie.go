@@ -0,0 +1,176 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// opsgenieResponderTypes are the prefixes of the opsgenie responders,
+// see https://docs.opsgenie.com/docs/alert-api#create-alert
+var opsgenieResponderTypes = []string{"user:", "team:", "escalation:", "schedule:"}
+
+// Opsgenie is the notification rule config of opsgenie.
+type Opsgenie struct {
+	Base
+	MessageTemplate string `json:"messageTemplate"`
+	// Responders are the users, teams, escalations or schedules the alert is
+	// routed to, each prefixed with its type, for example "team:ops".
+	Responders []string `json:"responders,omitempty"`
+	// Tags are the tags of the alert.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// GenerateFlux generates a flux script for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	opsgenieEndpoint, ok := e.(*endpoint.Opsgenie)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Opsgenie endpoint", e.Type())
+	}
+	return astutil.Format(s.GenerateFluxAST(opsgenieEndpoint))
+}
+
+// GenerateFluxAST generates a flux AST for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFluxAST(e *endpoint.Opsgenie) *ast.File {
+	return flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "contrib/sranka/opsgenie", "influxdata/influxdb/secrets", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+}
+
+func (s *Opsgenie) generateFluxASTBody(e *endpoint.Opsgenie) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTSecrets(e))
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe())
+
+	return statements
+}
+
+func (s *Opsgenie) generateFluxASTSecrets(e *endpoint.Opsgenie) ast.Statement {
+	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.APIKey.Key))))
+
+	return flux.DefineVariable("opsgenie_secret", call)
+}
+
+func (s *Opsgenie) generateFluxASTEndpoint(e *endpoint.Opsgenie) ast.Statement {
+	props := []*ast.Property{}
+	if e.URL != "" {
+		props = append(props, flux.Property("url", flux.String(e.URL)))
+	}
+	props = append(props, flux.Property("apiKey", flux.Identifier("opsgenie_secret")))
+	if e.Entity != "" {
+		props = append(props, flux.Property("entity", flux.String(e.Entity)))
+	}
+	call := flux.Call(flux.Member("opsgenie", "endpoint"), flux.Object(props...))
+
+	return flux.DefineVariable("opsgenie_endpoint", call)
+}
+
+func (s *Opsgenie) generateFluxASTNotifyPipe() ast.Statement {
+	endpointProps := []*ast.Property{}
+	endpointProps = append(endpointProps, flux.Property("message", flux.String(s.MessageTemplate)))
+	// Alerts of the same check are deduplicated by opsgenie while open.
+	endpointProps = append(endpointProps, flux.Property("alias", flux.Member("r", "_check_id")))
+	endpointProps = append(endpointProps, flux.Property("description", flux.Member("r", "_message")))
+	endpointProps = append(endpointProps, flux.Property("priority", priorityFromLevel()))
+	endpointProps = append(endpointProps, flux.Property("responders", stringArray(s.Responders)))
+	endpointProps = append(endpointProps, flux.Property("tags", stringArray(s.Tags)))
+	endpointProps = append(endpointProps, flux.Property("actions", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("visibleTo", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("details", flux.String("{}")))
+	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("opsgenie_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+}
+
+// priorityFromLevel maps crit to P1, warn to P3 and other levels to P5.
+func priorityFromLevel() ast.Expression {
+	level := flux.Member("r", "_level")
+	return flux.If(
+		flux.Equal(level, flux.String("crit")),
+		flux.String("P1"),
+		flux.If(
+			flux.Equal(level, flux.String("warn")),
+			flux.String("P3"),
+			flux.String("P5"),
+		),
+	)
+}
+
+func stringArray(values []string) *ast.ArrayExpression {
+	exprs := make([]ast.Expression, 0, len(values))
+	for _, v := range values {
+		exprs = append(exprs, flux.String(v))
+	}
+	return flux.Array(exprs...)
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "opsgenie invalid message template",
+		}
+	}
+	for _, r := range s.Responders {
+		if !validOpsgenieResponder(r) {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("opsgenie responder %q must be prefixed with one of %s", r, strings.Join(opsgenieResponderTypes, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+func validOpsgenieResponder(r string) bool {
+	for _, prefix := range opsgenieResponderTypes {
+		if strings.HasPrefix(r, prefix) && len(r) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Type returns the type of the rule config.
+func (s Opsgenie) Type() string {
+	return "opsgenie"
+}
@@ -0,0 +1,445 @@
+package rule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// EscalationStep is a step of the escalation policy of a notification rule.
+// It notifies its endpoint once a series has been at its level for longer
+// than After, from a task of its own. The endpoint may be of another type
+// than the endpoint of the rule.
+type EscalationStep struct {
+	EndpointID platform.ID             `json:"endpointID"`
+	Level      notification.CheckLevel `json:"level"`
+	After      notification.Duration   `json:"after"`
+	// To are the recipient addresses of the emails of a step escalating to
+	// an SMTP endpoint of a rule of another type. Steps of SMTP rules
+	// escalating to SMTP endpoints mail the recipients of the rule.
+	To []string `json:"to,omitempty"`
+	// TaskID is the task notifying the endpoint of the step.
+	TaskID platform.ID `json:"taskID,omitempty"`
+}
+
+func (s EscalationStep) valid() error {
+	if !s.EndpointID.Valid() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "escalation step endpointID is invalid",
+		}
+	}
+	if s.Level == notification.Any {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "escalation step level must not be ANY",
+		}
+	}
+	if s.After.TimeDuration() <= 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "escalation step after must be larger than 0",
+		}
+	}
+	if len(s.To) > 0 {
+		if err := (endpoint.Email{To: s.To}).Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateEscalationFlux generates the flux script of the task of the
+// escalation step i of a notification rule, notifying the endpoint e of the
+// step. The script is generated by a rule of the type of e, see
+// escalationRule.
+func GenerateEscalationFlux(nr influxdb.NotificationRule, i int, e influxdb.NotificationEndpoint) (string, error) {
+	r, ok := nr.(interface{ base() *Base })
+	if !ok {
+		return "", fmt.Errorf("notification rule type %s does not support escalation", nr.Type())
+	}
+
+	b := r.base()
+	if i < 0 || i >= len(b.EscalationSteps) {
+		return "", fmt.Errorf("notification rule has no escalation step %d", i)
+	}
+
+	b.Escalating = &b.EscalationSteps[i]
+	defer func() { b.Escalating = nil }()
+
+	er, err := escalationRule(nr, *b, e)
+	if err != nil {
+		return "", err
+	}
+	return er.GenerateFlux(e)
+}
+
+// defaultEscalationMessageTemplate is the message of the notifications of
+// escalation steps of rules without a message template, like HTTP rules.
+const defaultEscalationMessageTemplate = "Notification Rule: ${ r._notification_rule_name } triggered by check: ${ r._check_name }: ${ r._message }"
+
+// escalationRule returns the rule notifying the endpoint e of the escalating
+// step of b, the base of nr. That is nr when e is of its type, or otherwise
+// a rule of the type of e with the base and message template of nr.
+func escalationRule(nr influxdb.NotificationRule, b Base, e influxdb.NotificationEndpoint) (influxdb.NotificationRule, error) {
+	if e.Type() == nr.Type() {
+		return nr, nil
+	}
+
+	msg := defaultEscalationMessageTemplate
+	switch r := nr.(type) {
+	case *Slack:
+		msg = r.MessageTemplate
+	case *PagerDuty:
+		msg = r.MessageTemplate
+	case *Telegram:
+		msg = r.MessageTemplate
+	case *SMTP:
+		msg = r.MessageTemplate
+	case *Teams:
+		msg = r.MessageTemplate
+	case *Opsgenie:
+		msg = r.MessageTemplate
+	}
+
+	switch e.(type) {
+	case *endpoint.Slack:
+		return &Slack{Base: b, MessageTemplate: msg}, nil
+	case *endpoint.PagerDuty:
+		return &PagerDuty{Base: b, MessageTemplate: msg}, nil
+	case *endpoint.HTTP:
+		return &HTTP{Base: b}, nil
+	case *endpoint.Telegram:
+		return &Telegram{Base: b, MessageTemplate: msg}, nil
+	case *endpoint.Teams:
+		return &Teams{Base: b, MessageTemplate: msg}, nil
+	case *endpoint.Opsgenie:
+		return &Opsgenie{Base: b, MessageTemplate: msg}, nil
+	case *endpoint.SMTP:
+		if len(b.Escalating.To) == 0 {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("escalation step to smtp endpoint %s requires recipients", e.GetID()),
+			}
+		}
+		return &SMTP{Base: b, To: b.Escalating.To, SubjectTemplate: msg, MessageTemplate: msg}, nil
+	default:
+		return nil, fmt.Errorf("notification endpoint type %s does not support escalation", e.Type())
+	}
+}
+
+func (b *Base) base() *Base {
+	return b
+}
+
+// GetEscalationSteps returns the escalation steps of the rule.
+func (b *Base) GetEscalationSteps() []EscalationStep {
+	return b.EscalationSteps
+}
+
+// SetEscalationTaskID sets the task ID of the escalation step i.
+func (b *Base) SetEscalationTaskID(i int, id platform.ID) {
+	b.EscalationSteps[i].TaskID = id
+}
+
+func (b Base) validPolicy() error {
+	seen := make(map[string]bool, len(b.GroupBy))
+	for _, k := range b.GroupBy {
+		if k == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Notification Rule groupBy keys can't be empty",
+			}
+		}
+		if seen[k] {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Notification Rule groupBy key %q is duplicated", k),
+			}
+		}
+		seen[k] = true
+	}
+	if b.DedupWindow != nil && b.DedupWindow.TimeDuration() <= 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Notification Rule dedupWindow must be larger than 0",
+		}
+	}
+	for _, s := range b.EscalationSteps {
+		if err := s.valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatePolicy appends the deduplication and grouping of the rule to the
+// pipe of its statuses. Deduplication drops the statuses of series that
+// were notified at the same level within the dedup window, as recorded by
+// the notifications of the rule in the _monitoring bucket.
+func (b *Base) generatePolicy(pipe ast.Expression) ([]ast.Statement, ast.Expression) {
+	var stmts []ast.Statement
+	var calls []*ast.CallExpression
+	if b.DedupWindow != nil {
+		stmts = append(stmts, b.generateDedupKey(), b.generateNotified())
+		calls = append(calls, b.generateDedupFilter())
+	}
+	if len(b.GroupBy) > 0 {
+		calls = append(calls, b.generateDigest()...)
+	}
+	if len(calls) == 0 {
+		return nil, pipe
+	}
+	return stmts, flux.Pipe(pipe, calls...)
+}
+
+// dedupColumns are the columns by which notifications are deduplicated:
+// the tag keys of a digest, or the check of a status otherwise.
+func (b *Base) dedupColumns() []string {
+	if len(b.GroupBy) > 0 {
+		return append(append([]string{}, b.GroupBy...), "_level")
+	}
+	return []string{"_check_id", "_level"}
+}
+
+func (b *Base) generateDedupKey() ast.Statement {
+	var key ast.Expression
+	for _, c := range b.dedupColumns() {
+		v := flux.Member("r", c)
+		if key == nil {
+			key = v
+			continue
+		}
+		key = flux.Add(flux.Add(key, flux.String(":")), v)
+	}
+
+	return flux.DefineVariable("dedup_key", flux.Function(flux.FunctionParams("r"), key))
+}
+
+func (b *Base) generateNotified() ast.Statement {
+	sent := flux.Function(
+		flux.FunctionParams("r"),
+		flux.And(
+			flux.Equal(flux.Member("r", "_notification_rule_id"), flux.String(b.ID.String())),
+			flux.Equal(flux.Member("r", "_sent"), flux.String("true")),
+		),
+	)
+	key := flux.Function(
+		flux.FunctionParams("r"),
+		flux.ObjectWith("r", flux.Property("_dedup_key", dedupKeyCall())),
+	)
+
+	pipe := flux.Pipe(
+		flux.Call(
+			flux.Member("monitor", "logs"),
+			flux.Object(
+				flux.Property("start", flux.Negative((*ast.DurationLiteral)(b.DedupWindow))),
+				flux.Property("fn", sent),
+			),
+		),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", key))),
+		flux.Call(flux.Identifier("group"), flux.Object()),
+		flux.Call(
+			flux.Identifier("findColumn"),
+			flux.Object(
+				flux.Property("fn", flux.Function(flux.FunctionParams("key"), flux.Bool(true))),
+				flux.Property("column", flux.String("_dedup_key")),
+			),
+		),
+	)
+
+	return flux.DefineVariable("notified", pipe)
+}
+
+func (b *Base) generateDedupFilter() *ast.CallExpression {
+	notNotified := flux.Not(flux.Call(
+		flux.Identifier("contains"),
+		flux.Object(
+			flux.Property("value", dedupKeyCall()),
+			flux.Property("set", flux.Identifier("notified")),
+		),
+	))
+
+	return flux.Call(
+		flux.Identifier("filter"),
+		flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), notNotified))),
+	)
+}
+
+func dedupKeyCall() *ast.CallExpression {
+	return flux.Call(flux.Identifier("dedup_key"), flux.Object(flux.Property("r", flux.Identifier("r"))))
+}
+
+// generateDigest groups the statuses by the tag keys of the rule and their
+// level into a single digest status per group, whose message joins the
+// messages of the statuses of the group.
+func (b *Base) generateDigest() []*ast.CallExpression {
+	var columns []ast.Expression
+	for _, k := range b.GroupBy {
+		columns = append(columns, flux.String(k))
+	}
+	columns = append(columns, flux.String("_level"))
+
+	acc := func(c string) ast.Expression { return flux.Member("accumulator", c) }
+	identity := flux.Object(
+		flux.Property("_check_id", flux.String("")),
+		flux.Property("_check_name", flux.String("")),
+		flux.Property("_source_measurement", flux.String("")),
+		flux.Property("_source_timestamp", flux.Integer(0)),
+		flux.Property("_time", flux.Call(flux.Identifier("time"), flux.Object(flux.Property("v", flux.Integer(0))))),
+		flux.Property("_message", flux.String("")),
+		flux.Property("_count", flux.Integer(0)),
+	)
+	fn := flux.Function(
+		flux.FunctionParams("r", "accumulator"),
+		flux.Object(
+			flux.Property("_check_id", flux.Member("r", "_check_id")),
+			flux.Property("_check_name", flux.Member("r", "_check_name")),
+			flux.Property("_source_measurement", flux.Member("r", "_source_measurement")),
+			flux.Property("_source_timestamp", flux.Member("r", "_source_timestamp")),
+			flux.Property("_time", flux.Member("r", "_time")),
+			flux.Property("_message", flux.If(
+				flux.Equal(acc("_count"), flux.Integer(0)),
+				flux.Member("r", "_message"),
+				flux.Add(flux.Add(acc("_message"), flux.String("\n")), flux.Member("r", "_message")),
+			)),
+			flux.Property("_count", flux.Add(acc("_count"), flux.Integer(1))),
+		),
+	)
+
+	return []*ast.CallExpression{
+		flux.Call(flux.Identifier("group"), flux.Object(flux.Property("columns", flux.Array(columns...)))),
+		flux.Call(flux.Identifier("reduce"), flux.Object(
+			flux.Property("identity", identity),
+			flux.Property("fn", fn),
+		)),
+	}
+}
+
+// generateEscalation generates the statuses of the escalation step of the
+// rule: the last statuses of the series that reached the duration of the
+// step at its level since the previous run of the task.
+func (b *Base) generateEscalation() []ast.Statement {
+	step := b.Escalating
+	level := strings.ToLower(step.Level.String())
+	now := flux.Call(flux.Identifier("now"), flux.Object())
+	toInt := func(e ast.Expression) ast.Expression {
+		return flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", e)))
+	}
+
+	escalatedAt := flux.Add(
+		flux.Subtract(toInt(flux.Member("r", "_time")), flux.Member("r", "_level_duration")),
+		flux.Integer(step.After.TimeDuration().Nanoseconds()),
+	)
+	since := toInt(flux.Call(
+		flux.Member("experimental", "subDuration"),
+		flux.Object(
+			flux.Property("from", now),
+			flux.Property("d", (*ast.DurationLiteral)(b.Every)),
+		),
+	))
+
+	pipe := flux.Pipe(
+		flux.Identifier("statuses"),
+		flux.Call(
+			flux.Identifier("stateDuration"),
+			flux.Object(
+				flux.Property("fn", flux.Function(
+					flux.FunctionParams("r"),
+					flux.Equal(flux.Member("r", "_level"), flux.String(level)),
+				)),
+				flux.Property("column", flux.String("_level_duration")),
+				flux.Property("unit", flux.Duration(1, "ns")),
+			),
+		),
+		flux.Call(flux.Identifier("last"), flux.Object(flux.Property("column", flux.String("_time")))),
+		flux.Call(
+			flux.Identifier("filter"),
+			flux.Object(flux.Property("fn", flux.Function(
+				flux.FunctionParams("r"),
+				&ast.BinaryExpression{
+					Operator: ast.GreaterThanEqualOperator,
+					Left:     flux.Member("r", "_level_duration"),
+					Right:    flux.Integer(0),
+				},
+			))),
+		),
+		flux.Call(
+			flux.Identifier("map"),
+			flux.Object(flux.Property("fn", flux.Function(
+				flux.FunctionParams("r"),
+				flux.ObjectWith("r", flux.Property("_escalated_at", escalatedAt)),
+			))),
+		),
+		flux.Call(
+			flux.Identifier("filter"),
+			flux.Object(flux.Property("fn", flux.Function(
+				flux.FunctionParams("r"),
+				flux.And(
+					&ast.BinaryExpression{
+						Operator: ast.GreaterThanEqualOperator,
+						Left:     flux.Member("r", "_escalated_at"),
+						Right:    since,
+					},
+					flux.LessThan(flux.Member("r", "_escalated_at"), toInt(now)),
+				),
+			))),
+		),
+	)
+
+	var all ast.Expression = pipe
+	if len(b.GroupBy) > 0 {
+		all = flux.Pipe(pipe, b.generateDigest()...)
+	}
+	return []ast.Statement{flux.DefineVariable("all_statuses", all)}
+}
+
+// escalationStart is how far back the statuses of an escalation step are
+// queried, so that the start of the level of a series escalated since the
+// previous run of the task is within the statuses.
+func (b *Base) escalationStart() *ast.DurationLiteral {
+	return durationLiteral(b.Escalating.After.TimeDuration() + 2*b.Every.TimeDuration())
+}
+
+// durationLiteral converts d to a flux duration literal.
+func durationLiteral(d time.Duration) *ast.DurationLiteral {
+	units := []struct {
+		unit string
+		dur  time.Duration
+	}{
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	}
+
+	lit := &ast.DurationLiteral{}
+	for _, u := range units {
+		if n := d / u.dur; n > 0 {
+			lit.Values = append(lit.Values, ast.Duration{Magnitude: int64(n), Unit: u.unit})
+			d -= n * u.dur
+		}
+	}
+	return lit
+}
+
+// durationString formats a duration the way it is written in flux.
+func durationString(d notification.Duration) string {
+	var sb strings.Builder
+	for _, v := range d.Values {
+		sb.WriteString(strconv.FormatInt(v.Magnitude, 10))
+		sb.WriteString(v.Unit)
+	}
+	return sb.String()
+}
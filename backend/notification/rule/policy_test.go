@@ -0,0 +1,448 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_GenerateFlux(t *testing.T) {
+	want := itesting.FormatFluxString(t, `import "influxdata/influxdb/monitor"
+import "slack"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack["endpoint"](url: "http://localhost:7777")
+notification = {
+    _notification_rule_id: "0000000000000001",
+    _notification_rule_name: "foo",
+    _notification_endpoint_id: "0000000000000002",
+    _notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses |> filter(fn: (r) => r["_level"] == "crit")
+dedup_key = (r) => r["host"] + ":" + r["_level"]
+notified =
+    monitor["logs"](start: -30m, fn: (r) => r["_notification_rule_id"] == "0000000000000001" and r["_sent"] == "true")
+        |> map(fn: (r) => ({r with _dedup_key: dedup_key(r: r)}))
+        |> group()
+        |> findColumn(fn: (key) => true, column: "_dedup_key")
+all_statuses =
+    crit
+        |> filter(fn: (r) => r["_time"] >= experimental["subDuration"](from: now(), d: 1h))
+        |> filter(fn: (r) => not contains(value: dedup_key(r: r), set: notified))
+        |> group(columns: ["host", "_level"])
+        |> reduce(
+            identity: {
+                _check_id: "",
+                _check_name: "",
+                _source_measurement: "",
+                _source_timestamp: 0,
+                _time: time(v: 0),
+                _message: "",
+                _count: 0,
+            },
+            fn: (r, accumulator) =>
+                ({
+                    _check_id: r["_check_id"],
+                    _check_name: r["_check_name"],
+                    _source_measurement: r["_source_measurement"],
+                    _source_timestamp: r["_source_timestamp"],
+                    _time: r["_time"],
+                    _message:
+                        if accumulator["_count"] == 0 then
+                            r["_message"]
+                        else
+                            accumulator["_message"] + "\n" + r["_message"],
+                    _count: accumulator["_count"] + 1,
+                }),
+        )
+
+all_statuses
+    |> monitor["notify"](
+        data: notification,
+        endpoint:
+            slack_endpoint(
+                mapFn: (r) =>
+                    ({
+                        channel: "bar",
+                        text: "${r._message}",
+                        color:
+                            if r["_level"] == "crit" then
+                                "danger"
+                            else if r["_level"] == "warn" then
+                                "warning"
+                            else
+                                "good",
+                    }),
+            ),
+    )
+`)
+
+	s := &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: "${r._message}",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+			GroupBy:     []string{"host"},
+			DedupWindow: mustDuration("30m"),
+		},
+	}
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		URL: "http://localhost:7777",
+	}
+
+	f, err := s.GenerateFlux(e)
+	require.NoError(t, err)
+	assert.Equal(t, want, f)
+}
+
+func TestGenerateEscalationFlux(t *testing.T) {
+	want := itesting.FormatFluxString(t, `import "influxdata/influxdb/monitor"
+import "slack"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo (escalation after 30m)", every: 1m}
+
+slack_endpoint = slack["endpoint"](url: "http://localhost:8888")
+notification = {
+    _notification_rule_id: "0000000000000001",
+    _notification_rule_name: "foo",
+    _notification_endpoint_id: "0000000000000003",
+    _notification_endpoint_name: "oncall",
+}
+statuses = monitor["from"](start: -32m)
+all_statuses =
+    statuses
+        |> stateDuration(fn: (r) => r["_level"] == "crit", column: "_level_duration", unit: 1ns)
+        |> last(column: "_time")
+        |> filter(fn: (r) => r["_level_duration"] >= 0)
+        |> map(fn: (r) => ({r with _escalated_at: int(v: r["_time"]) - r["_level_duration"] + 1800000000000}))
+        |> filter(
+            fn: (r) =>
+                r["_escalated_at"] >= int(v: experimental["subDuration"](from: now(), d: 1m))
+                    and
+                    r["_escalated_at"] < int(v: now()),
+        )
+
+all_statuses
+    |> monitor["notify"](
+        data: notification,
+        endpoint:
+            slack_endpoint(
+                mapFn: (r) =>
+                    ({
+                        channel: "bar",
+                        text: "blah",
+                        color:
+                            if r["_level"] == "crit" then
+                                "danger"
+                            else if r["_level"] == "warn" then
+                                "warning"
+                            else
+                                "good",
+                    }),
+            ),
+    )
+`)
+
+	s := &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: "blah",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1m"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+			DedupWindow: mustDuration("30m"),
+			EscalationSteps: []rule.EscalationStep{
+				{
+					EndpointID: 3,
+					Level:      notification.Critical,
+					After:      *mustDuration("30m"),
+				},
+			},
+		},
+	}
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:   idPtr(3),
+			Name: "oncall",
+		},
+		URL: "http://localhost:8888",
+	}
+
+	f, err := rule.GenerateEscalationFlux(s, 0, e)
+	require.NoError(t, err)
+	assert.Equal(t, want, f)
+	assert.Nil(t, s.Escalating)
+
+	_, err = rule.GenerateEscalationFlux(s, 1, e)
+	require.Error(t, err)
+}
+
+func TestGenerateEscalationFlux_OtherEndpointType(t *testing.T) {
+	want := itesting.FormatFluxString(t, `import "influxdata/influxdb/monitor"
+import "pagerduty"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo (escalation after 30m)", every: 1m}
+
+pagerduty_secret = secrets["get"](key: "pagerduty_token")
+pagerduty_endpoint = pagerduty["endpoint"]()
+notification = {
+    _notification_rule_id: "0000000000000001",
+    _notification_rule_name: "foo",
+    _notification_endpoint_id: "0000000000000003",
+    _notification_endpoint_name: "oncall",
+}
+statuses = monitor["from"](start: -32m)
+all_statuses =
+    statuses
+        |> stateDuration(fn: (r) => r["_level"] == "crit", column: "_level_duration", unit: 1ns)
+        |> last(column: "_time")
+        |> filter(fn: (r) => r["_level_duration"] >= 0)
+        |> map(fn: (r) => ({r with _escalated_at: int(v: r["_time"]) - r["_level_duration"] + 1800000000000}))
+        |> filter(
+            fn: (r) =>
+                r["_escalated_at"] >= int(v: experimental["subDuration"](from: now(), d: 1m))
+                    and
+                    r["_escalated_at"] < int(v: now()),
+        )
+
+all_statuses
+    |> monitor["notify"](
+        data: notification,
+        endpoint:
+            pagerduty_endpoint(
+                mapFn: (r) =>
+                    ({
+                        routingKey: pagerduty_secret,
+                        client: "influxdata",
+                        clientURL: "http://localhost:7777",
+                        class: r._check_name,
+                        group: r["_source_measurement"],
+                        severity: pagerduty["severityFromLevel"](level: r["_level"]),
+                        eventAction: pagerduty["actionFromLevel"](level: r["_level"]),
+                        source: notification["_notification_rule_name"],
+                        summary: r["_message"],
+                        timestamp: time(v: r["_source_timestamp"]),
+                    }),
+            ),
+    )
+`)
+
+	// A slack rule escalating to pagerduty.
+	s := &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: "blah",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1m"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+			DedupWindow: mustDuration("30m"),
+			EscalationSteps: []rule.EscalationStep{
+				{
+					EndpointID: 3,
+					Level:      notification.Critical,
+					After:      *mustDuration("30m"),
+				},
+			},
+		},
+	}
+	e := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			ID:   idPtr(3),
+			Name: "oncall",
+		},
+		ClientURL: "http://localhost:7777",
+		RoutingKey: influxdb.SecretField{
+			Key: "pagerduty_token",
+		},
+	}
+
+	f, err := rule.GenerateEscalationFlux(s, 0, e)
+	require.NoError(t, err)
+	assert.Equal(t, want, f)
+	assert.Nil(t, s.Escalating)
+}
+
+func TestGenerateEscalationFlux_SMTPRecipients(t *testing.T) {
+	s := &rule.Slack{
+		Channel: "bar",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1m"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+			EscalationSteps: []rule.EscalationStep{
+				{
+					EndpointID: 3,
+					Level:      notification.Critical,
+					After:      *mustDuration("30m"),
+				},
+			},
+		},
+	}
+	e := &endpoint.SMTP{Base: endpoint.Base{ID: idPtr(3), Name: "mail"}}
+
+	// A slack rule has no recipients to mail.
+	_, err := rule.GenerateEscalationFlux(s, 0, e)
+	assert.Equal(t, &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "escalation step to smtp endpoint 0000000000000003 requires recipients",
+	}, err)
+	assert.Nil(t, s.Escalating)
+}
+
+func TestPolicy_Valid(t *testing.T) {
+	base := func() rule.Base {
+		return rule.Base{
+			ID:         1,
+			EndpointID: 3,
+			OwnerID:    4,
+			OrgID:      5,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy func(b *rule.Base)
+		err    error
+	}{
+		{
+			name: "valid",
+			policy: func(b *rule.Base) {
+				b.GroupBy = []string{"host", "region"}
+				b.DedupWindow = mustDuration("30m")
+				b.EscalationSteps = []rule.EscalationStep{
+					{EndpointID: 6, Level: notification.Critical, After: *mustDuration("10m")},
+				}
+			},
+		},
+		{
+			name: "empty group by key",
+			policy: func(b *rule.Base) {
+				b.GroupBy = []string{""}
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Notification Rule groupBy keys can't be empty",
+			},
+		},
+		{
+			name: "duplicate group by key",
+			policy: func(b *rule.Base) {
+				b.GroupBy = []string{"host", "host"}
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `Notification Rule groupBy key "host" is duplicated`,
+			},
+		},
+		{
+			name: "zero dedup window",
+			policy: func(b *rule.Base) {
+				b.DedupWindow = mustDuration("0s")
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Notification Rule dedupWindow must be larger than 0",
+			},
+		},
+		{
+			name: "escalation step without endpoint",
+			policy: func(b *rule.Base) {
+				b.EscalationSteps = []rule.EscalationStep{
+					{Level: notification.Critical, After: *mustDuration("10m")},
+				}
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "escalation step endpointID is invalid",
+			},
+		},
+		{
+			name: "escalation step at any level",
+			policy: func(b *rule.Base) {
+				b.EscalationSteps = []rule.EscalationStep{
+					{EndpointID: 6, Level: notification.Any, After: *mustDuration("10m")},
+				}
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "escalation step level must not be ANY",
+			},
+		},
+		{
+			name: "escalation step without delay",
+			policy: func(b *rule.Base) {
+				b.EscalationSteps = []rule.EscalationStep{
+					{EndpointID: 6, Level: notification.Critical},
+				}
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "escalation step after must be larger than 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &rule.Slack{
+				Base:            base(),
+				Channel:         "bar",
+				MessageTemplate: "blah",
+			}
+			tt.policy(&s.Base)
+			itesting.ErrorsEqual(t, s.Valid(), tt.err)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/notification"
 	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/notification/silence"
 )
 
 var typeToRule = map[string](func() influxdb.NotificationRule){
@@ -19,6 +20,9 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"pagerduty": func() influxdb.NotificationRule { return &PagerDuty{} },
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
 	"telegram":  func() influxdb.NotificationRule { return &Telegram{} },
+	"smtp":      func() influxdb.NotificationRule { return &SMTP{} },
+	"teams":     func() influxdb.NotificationRule { return &Teams{} },
+	"opsgenie":  func() influxdb.NotificationRule { return &Opsgenie{} },
 }
 
 // UnmarshalJSON will convert
@@ -60,6 +64,20 @@ type Base struct {
 	RunbookLink string                    `json:"runbookLink"`
 	TagRules    []notification.TagRule    `json:"tagRules,omitempty"`
 	StatusRules []notification.StatusRule `json:"statusRules,omitempty"`
+	// Silences mute the statuses they match; they are not stored with the
+	// rule but set from the silences of the organization to generate flux.
+	Silences []*silence.Silence `json:"-"`
+	// GroupBy are the tag keys by which statuses are grouped into a single
+	// digest notification per group and level.
+	GroupBy []string `json:"groupBy,omitempty"`
+	// DedupWindow suppresses the notifications already sent at the same
+	// level within the window.
+	DedupWindow *notification.Duration `json:"dedupWindow,omitempty"`
+	// EscalationSteps notify further endpoints while a series stays at a level.
+	EscalationSteps []EscalationStep `json:"escalationSteps,omitempty"`
+	// Escalating is the escalation step the flux of the rule is generated
+	// for; it is not stored with the rule.
+	Escalating *EscalationStep `json:"-"`
 	*influxdb.Limit
 	influxdb.CRUDLog
 }
@@ -115,18 +133,25 @@ func (b Base) valid() error {
 		}
 	}
 
-	return nil
+	return b.validPolicy()
 }
 func (b *Base) generateFluxASTNotificationDefinition(e influxdb.NotificationEndpoint) ast.Statement {
 	ruleID := flux.Property("_notification_rule_id", flux.String(b.ID.String()))
 	ruleName := flux.Property("_notification_rule_name", flux.String(b.Name))
 	endpointID := flux.Property("_notification_endpoint_id", flux.String(b.EndpointID.String()))
+	if b.Escalating != nil {
+		endpointID = flux.Property("_notification_endpoint_id", flux.String(b.Escalating.EndpointID.String()))
+	}
 	endpointName := flux.Property("_notification_endpoint_name", flux.String(e.GetName()))
 
 	return flux.DefineVariable("notification", flux.Object(ruleID, ruleName, endpointID, endpointName))
 }
 
 func (b *Base) generateLevelChecks() []ast.Statement {
+	if b.Escalating != nil {
+		return b.generateEscalation()
+	}
+
 	stmts := []ast.Statement{}
 	tables := []ast.Expression{}
 	for _, r := range b.StatusRules {
@@ -185,7 +210,9 @@ func (b *Base) generateLevelChecks() []ast.Statement {
 		)
 	}
 
-	stmts = append(stmts, flux.DefineVariable("all_statuses", pipe))
+	policy, all := b.generatePolicy(pipe)
+	stmts = append(stmts, policy...)
+	stmts = append(stmts, flux.DefineVariable("all_statuses", all))
 
 	return stmts
 }
@@ -274,7 +301,11 @@ func increaseDur(d *ast.DurationLiteral) *ast.DurationLiteral {
 func (b *Base) generateTaskOption() ast.Statement {
 	props := []*ast.Property{}
 
-	props = append(props, flux.Property("name", flux.String(b.Name)))
+	name := b.Name
+	if b.Escalating != nil {
+		name = fmt.Sprintf("%s (escalation after %s)", b.Name, durationString(b.Escalating.After))
+	}
+	props = append(props, flux.Property("name", flux.String(name)))
 
 	if b.Every != nil {
 		// Make the windows overlap and filter records from previous queries.
@@ -292,14 +323,23 @@ func (b *Base) generateTaskOption() ast.Statement {
 func (b *Base) generateFluxASTStatuses() ast.Statement {
 	props := []*ast.Property{}
 
-	dur := (*ast.DurationLiteral)(b.Every)
-	props = append(props, flux.Property("start", flux.Negative(increaseDur(dur))))
+	start := increaseDur((*ast.DurationLiteral)(b.Every))
+	if b.Escalating != nil {
+		start = b.escalationStart()
+	}
+	props = append(props, flux.Property("start", flux.Negative(start)))
 
-	if len(b.TagRules) > 0 {
-		r := b.TagRules[0]
-		var body ast.Expression = r.GenerateFluxAST()
-		for _, r := range b.TagRules[1:] {
-			body = flux.And(body, r.GenerateFluxAST())
+	var conds []ast.Expression
+	for _, r := range b.TagRules {
+		conds = append(conds, r.GenerateFluxAST())
+	}
+	for _, s := range b.Silences {
+		conds = append(conds, flux.Not(s.GenerateFluxAST()))
+	}
+	if len(conds) > 0 {
+		body := conds[0]
+		for _, c := range conds[1:] {
+			body = flux.And(body, c)
 		}
 		props = append(props, flux.Property("fn", flux.Function(flux.FunctionParams("r"), body)))
 	}
@@ -329,14 +369,22 @@ func (b Base) GetTaskID() platform.ID {
 	return b.TaskID
 }
 
+// SetSilences sets the silences honoured by the flux of the rule.
+func (b *Base) SetSilences(silences []*silence.Silence) {
+	b.Silences = silences
+}
+
 // SetTaskID sets the task ID for a base.
 func (b *Base) SetTaskID(id platform.ID) {
 	b.TaskID = id
 }
 
-// ClearPrivateData clears the task ID from the base.
+// ClearPrivateData clears the task IDs from the base.
 func (b *Base) ClearPrivateData() {
 	b.TaskID = 0
+	for i := range b.EscalationSteps {
+		b.EscalationSteps[i].TaskID = 0
+	}
 }
 
 // MatchesTags returns true if the Rule matches all of the tags
@@ -357,6 +357,119 @@ func TestJSON(t *testing.T) {
 				MessageTemplate: "blah",
 			},
 		},
+		{
+			name: "email smtp",
+			src: &rule.SMTP{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				To:              []string{"ops@example.com"},
+				SubjectTemplate: "subject",
+				MessageTemplate: "blah",
+			},
+		},
+		{
+			name: "simple teams",
+			src: &rule.Teams{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				TitleTemplate:   "title",
+				MessageTemplate: "blah",
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &rule.Opsgenie{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				MessageTemplate: "blah",
+				Responders:      []string{"team:ops"},
+				Tags:            []string{"influxdb"},
+			},
+		},
+		{
+			name: "http with body template",
+			src: &rule.HTTP{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				BodyTemplate:       "{{.CheckName}} is {{.Level}}",
+				BodyTemplateSyntax: rule.BodyTemplateGo,
+				Headers:            map[string]string{"Content-Type": "text/plain"},
+			},
+		},
+		{
+			name: "slack with grouping, dedup and escalation",
+			src: &rule.Slack{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					GroupBy:     []string{"host"},
+					DedupWindow: mustDuration("30m"),
+					EscalationSteps: []rule.EscalationStep{
+						{
+							EndpointID: influxTesting.MustIDBase16(id2),
+							Level:      notification.Critical,
+							After:      *mustDuration("10m"),
+							TaskID:     influxTesting.MustIDBase16(id3),
+						},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Channel:         "channel1",
+				MessageTemplate: "msg1",
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
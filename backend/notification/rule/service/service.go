@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/pkg/pointer"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
@@ -42,19 +43,22 @@ type RuleService struct {
 	tasks     taskmodel.TaskService
 	orgs      influxdb.OrganizationService
 	endpoints influxdb.NotificationEndpointService
+	silences  silence.SilenceService
 
 	idGenerator   platform.IDGenerator
 	timeGenerator influxdb.TimeGenerator
 }
 
-// New constructs and configures a notification rule service
-func New(logger *zap.Logger, store kv.Store, tasks taskmodel.TaskService, orgs influxdb.OrganizationService, endpoints influxdb.NotificationEndpointService) (*RuleService, error) {
+// New constructs and configures a notification rule service. The tasks of
+// the rules honour the silences found with silences.
+func New(logger *zap.Logger, store kv.Store, tasks taskmodel.TaskService, orgs influxdb.OrganizationService, endpoints influxdb.NotificationEndpointService, silences silence.SilenceService) (*RuleService, error) {
 	s := &RuleService{
 		log:           logger,
 		kv:            store,
 		tasks:         tasks,
 		orgs:          orgs,
 		endpoints:     endpoints,
+		silences:      silences,
 		timeGenerator: influxdb.RealTimeGenerator{},
 		idGenerator:   snowflake.NewIDGenerator(),
 	}
@@ -111,6 +115,8 @@ func (s *RuleService) CreateNotificationRule(ctx context.Context, nr influxdb.No
 	// set notification rule ID
 	id := s.idGenerator.ID()
 	nr.SetID(id)
+	// task IDs are set from the tasks created below
+	nr.ClearPrivateData()
 
 	// set notification rule created / updated times
 	now := s.timeGenerator.Now()
@@ -118,6 +124,10 @@ func (s *RuleService) CreateNotificationRule(ctx context.Context, nr influxdb.No
 	nr.SetCreatedAt(now)
 	nr.SetUpdatedAt(now)
 
+	if err := s.validEscalationEndpoints(ctx, nr.NotificationRule); err != nil {
+		return err
+	}
+
 	// create backing task and set ID (in inactive state initially)
 	t, err := s.createNotificationTask(ctx, nr)
 	if err != nil {
@@ -126,20 +136,37 @@ func (s *RuleService) CreateNotificationRule(ctx context.Context, nr influxdb.No
 
 	nr.SetTaskID(t.ID)
 
+	// create the tasks of the escalation steps (in inactive state initially)
+	if err := s.updateEscalationTasks(ctx, nr.NotificationRule, nil, nil); err != nil {
+		s.deleteNotificationTasks(ctx, nr.NotificationRule)
+		return err
+	}
+
 	if err := s.kv.Update(ctx, func(tx kv.Tx) error {
 		return s.createNotificationRule(ctx, tx, nr, userID)
 	}); err != nil {
-		// remove associated task
-		if derr := s.tasks.DeleteTask(ctx, t.ID); derr != nil {
-			s.log.Error("failed to remove task for invalid notification rule", zap.Error(derr))
-		}
-
+		// remove associated tasks
+		s.deleteNotificationTasks(ctx, nr.NotificationRule)
 		return err
 	}
 
-	// set task to notification rule create status
-	_, err = s.tasks.UpdateTask(ctx, t.ID, taskmodel.TaskUpdate{Status: pointer.String(string(nr.Status))})
-	return err
+	// set tasks to notification rule create status
+	for _, id := range notificationTaskIDs(nr.NotificationRule) {
+		if _, err := s.tasks.UpdateTask(ctx, id, taskmodel.TaskUpdate{Status: pointer.String(string(nr.Status))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteNotificationTasks removes the tasks of a notification rule that
+// failed to be created.
+func (s *RuleService) deleteNotificationTasks(ctx context.Context, nr influxdb.NotificationRule) {
+	for _, id := range notificationTaskIDs(nr) {
+		if err := s.tasks.DeleteTask(ctx, id); err != nil {
+			s.log.Error("failed to remove task for invalid notification rule", zap.Error(err))
+		}
+	}
 }
 
 func (s *RuleService) createNotificationRule(ctx context.Context, tx kv.Tx, nr influxdb.NotificationRuleCreate, userID platform.ID) error {
@@ -160,6 +187,10 @@ func (s *RuleService) createNotificationTask(ctx context.Context, r influxdb.Not
 		return nil, err
 	}
 
+	if err := s.setSilences(ctx, r.NotificationRule); err != nil {
+		return nil, err
+	}
+
 	script, err := r.GenerateFlux(ep)
 	if err != nil {
 		return nil, err
@@ -206,11 +237,19 @@ func (s *RuleService) UpdateNotificationRule(ctx context.Context, id platform.ID
 		return nil, err
 	}
 
+	if err := s.validEscalationEndpoints(ctx, nr.NotificationRule); err != nil {
+		return nil, err
+	}
+
 	_, err = s.updateNotificationTask(ctx, nr, pointer.String(string(nr.Status)))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.updateEscalationTasks(ctx, nr.NotificationRule, escalationSteps(rule), pointer.String(string(nr.Status))); err != nil {
+		return nil, err
+	}
+
 	err = s.kv.Update(ctx, func(tx kv.Tx) error {
 		return s.putNotificationRule(ctx, tx, nr.NotificationRule)
 	})
@@ -224,6 +263,10 @@ func (s *RuleService) updateNotificationTask(ctx context.Context, r influxdb.Not
 		return nil, err
 	}
 
+	if err := s.setSilences(ctx, r); err != nil {
+		return nil, err
+	}
+
 	script, err := r.GenerateFlux(ep)
 	if err != nil {
 		return nil, err
@@ -243,6 +286,190 @@ func (s *RuleService) updateNotificationTask(ctx context.Context, r influxdb.Not
 	return t, nil
 }
 
+// setSilences sets the unexpired silences of the organization of a rule, so
+// that they are honoured by the flux of its task.
+func (s *RuleService) setSilences(ctx context.Context, r influxdb.NotificationRule) error {
+	nr, ok := r.(interface {
+		SetSilences([]*silence.Silence)
+	})
+	if !ok {
+		return nil
+	}
+
+	orgID := r.GetOrgID()
+	silences, err := s.silences.FindSilences(ctx, silence.Filter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+
+	now := s.timeGenerator.Now()
+	active := silences[:0]
+	for _, sil := range silences {
+		if !sil.Expired(now) {
+			active = append(active, sil)
+		}
+	}
+	nr.SetSilences(active)
+	return nil
+}
+
+// UpdateNotificationRuleTasks regenerates the tasks of the notification rules
+// of an organization, so that they honour its current silences.
+func (s *RuleService) UpdateNotificationRuleTasks(ctx context.Context, orgID platform.ID) error {
+	nrs, _, err := s.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+
+	for _, nr := range nrs {
+		t, err := s.updateNotificationTask(ctx, nr, nil)
+		if err != nil {
+			return err
+		}
+
+		// Escalation steps without a task get one with the status of the
+		// task of the rule. The rule is stored with the IDs of the new
+		// tasks, so that they aren't created again by the next update.
+		prev := escalationSteps(nr)
+		if err := s.updateEscalationTasks(ctx, nr, prev, pointer.String(t.Status)); err != nil {
+			return err
+		}
+		if !sameEscalationTasks(prev, escalationSteps(nr)) {
+			if err := s.kv.Update(ctx, func(tx kv.Tx) error {
+				return s.putNotificationRule(ctx, tx, nr)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sameEscalationTasks returns whether the escalation steps a and b have the
+// same tasks.
+func sameEscalationTasks(a, b []rule.EscalationStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].TaskID != b[i].TaskID {
+			return false
+		}
+	}
+	return true
+}
+
+// escalationRule is a notification rule with escalation steps, each of which
+// notifies its endpoint from a task of its own.
+type escalationRule interface {
+	GetEscalationSteps() []rule.EscalationStep
+	SetEscalationTaskID(i int, id platform.ID)
+}
+
+func escalationSteps(nr influxdb.NotificationRule) []rule.EscalationStep {
+	er, ok := nr.(escalationRule)
+	if !ok {
+		return nil
+	}
+	return append([]rule.EscalationStep(nil), er.GetEscalationSteps()...)
+}
+
+// notificationTaskIDs returns the IDs of the task of a notification rule and
+// of the tasks of its escalation steps.
+func notificationTaskIDs(nr influxdb.NotificationRule) []platform.ID {
+	var ids []platform.ID
+	if id := nr.GetTaskID(); id.Valid() {
+		ids = append(ids, id)
+	}
+	for _, step := range escalationSteps(nr) {
+		if step.TaskID.Valid() {
+			ids = append(ids, step.TaskID)
+		}
+	}
+	return ids
+}
+
+// validEscalationEndpoints checks that the endpoints of the escalation steps
+// of a rule exist in the organization of the rule, before any of the tasks of
+// the rule are created or updated. The endpoints may be of any type.
+func (s *RuleService) validEscalationEndpoints(ctx context.Context, nr influxdb.NotificationRule) error {
+	for _, step := range escalationSteps(nr) {
+		ep, err := s.endpoints.FindNotificationEndpointByID(ctx, step.EndpointID)
+		if err != nil {
+			return err
+		}
+		if ep.GetOrgID() != nr.GetOrgID() {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("escalation step endpoint %s is not in the organization of the notification rule", step.EndpointID),
+			}
+		}
+	}
+	return nil
+}
+
+// updateEscalationTasks creates or updates the tasks of the escalation steps
+// of a rule, reusing the tasks of its previous steps, and deletes the tasks
+// of the previous steps it no longer has.
+func (s *RuleService) updateEscalationTasks(ctx context.Context, nr influxdb.NotificationRule, prev []rule.EscalationStep, status *string) error {
+	er, ok := nr.(escalationRule)
+	if !ok {
+		return nil
+	}
+
+	steps := er.GetEscalationSteps()
+	for i, step := range steps {
+		ep, err := s.endpoints.FindNotificationEndpointByID(ctx, step.EndpointID)
+		if err != nil {
+			return err
+		}
+
+		script, err := rule.GenerateEscalationFlux(nr, i, ep)
+		if err != nil {
+			return err
+		}
+
+		if i < len(prev) && prev[i].TaskID.Valid() {
+			tu := taskmodel.TaskUpdate{
+				Flux:        &script,
+				Description: pointer.String(nr.GetDescription()),
+				Status:      status,
+			}
+			if _, err := s.tasks.UpdateTask(ctx, prev[i].TaskID, tu); err != nil {
+				return err
+			}
+			er.SetEscalationTaskID(i, prev[i].TaskID)
+			continue
+		}
+
+		tc := taskmodel.TaskCreate{
+			Type:           nr.Type(),
+			Flux:           script,
+			OwnerID:        nr.GetOwnerID(),
+			OrganizationID: nr.GetOrgID(),
+			Status:         string(influxdb.Inactive),
+		}
+		if status != nil {
+			tc.Status = *status
+		}
+		t, err := s.tasks.CreateTask(ctx, tc)
+		if err != nil {
+			return err
+		}
+		er.SetEscalationTaskID(i, t.ID)
+	}
+
+	for i := len(steps); i < len(prev); i++ {
+		if !prev[i].TaskID.Valid() {
+			continue
+		}
+		if err := s.tasks.DeleteTask(ctx, prev[i].TaskID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PatchNotificationRule updates a single  notification rule with changeset.
 // Returns the new notification rule state after update.
 func (s *RuleService) PatchNotificationRule(ctx context.Context, id platform.ID, upd influxdb.NotificationRuleUpdate) (influxdb.NotificationRule, error) {
@@ -273,6 +500,10 @@ func (s *RuleService) PatchNotificationRule(ctx context.Context, id platform.ID,
 		return nil, err
 	}
 
+	if err := s.updateEscalationTasks(ctx, nr, escalationSteps(nr), status); err != nil {
+		return nil, err
+	}
+
 	if err := s.kv.Update(ctx, func(tx kv.Tx) (err error) {
 		return s.putNotificationRule(ctx, tx, nr)
 	}); err != nil {
@@ -464,8 +695,10 @@ func (s *RuleService) DeleteNotificationRule(ctx context.Context, id platform.ID
 		return err
 	}
 
-	if err := s.tasks.DeleteTask(ctx, r.GetTaskID()); err != nil {
-		return err
+	for _, id := range notificationTaskIDs(r) {
+		if err := s.tasks.DeleteTask(ctx, id); err != nil {
+			return err
+		}
 	}
 
 	return s.kv.Update(ctx, func(tx kv.Tx) error {
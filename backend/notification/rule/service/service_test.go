@@ -9,12 +9,17 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	endpointservice "github.com/influxdata/influxdb/v2/notification/endpoint/service"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/secret"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/influxdata/influxdb/v2/tenant"
 	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
@@ -67,7 +72,7 @@ func initNotificationRuleStore(s kv.Store, f NotificationRuleFields, t *testing.
 	endpStore.TimeGenerator = f.TimeGenerator
 	endp := endpointservice.New(endpStore, secretSvc)
 
-	svc, err := New(logger, s, kvsvc, tenantSvc, endp)
+	svc, err := New(logger, s, kvsvc, tenantSvc, endp, silence.NewService(s))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -130,3 +135,75 @@ func withOrgID(store *tenant.Store, orgID platform.ID, fn func()) {
 
 	fn()
 }
+
+// TestRuleService_UpdateNotificationRuleTasks checks that the tasks created
+// for escalation steps without one are stored with the rule, so that later
+// updates don't create them again.
+func TestRuleService_UpdateNotificationRuleTasks(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		orgID  = platform.ID(10)
+		ruleID = platform.ID(100)
+	)
+
+	// the endpoint is created first, then the task of the rule
+	s, tasks, done := initInmemNotificationRuleStore(NotificationRuleFields{
+		IDGenerator: mock.NewIncrementingIDGenerator(1),
+		Orgs:        []*influxdb.Organization{{ID: orgID, Name: "org"}},
+		Endpoints: []influxdb.NotificationEndpoint{
+			&endpoint.Slack{
+				URL:  "http://localhost:7777",
+				Base: endpoint.Base{OrgID: &orgID, Name: "oncall", Status: influxdb.Active},
+			},
+		},
+		NotificationRules: []influxdb.NotificationRule{
+			&rule.Slack{
+				Base: rule.Base{
+					ID:         ruleID,
+					Name:       "foo",
+					OwnerID:    1,
+					OrgID:      orgID,
+					EndpointID: 1,
+					TaskID:     2,
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{CurrentLevel: notification.Critical},
+					},
+					EscalationSteps: []rule.EscalationStep{
+						{EndpointID: 1, Level: notification.Critical, After: *mustDuration("30m")},
+					},
+				},
+				Channel: "bar",
+			},
+		},
+		Tasks: []taskmodel.TaskCreate{
+			{
+				OrganizationID: orgID,
+				OwnerID:        1,
+				Flux:           `option task = {name: "foo", every: 1h} from(bucket: "b") |> range(start: -1h)`,
+				Status:         string(influxdb.Active),
+			},
+		},
+	}, t)
+	defer done()
+	svc := s.(*RuleService)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.UpdateNotificationRuleTasks(ctx, orgID))
+	}
+
+	nr, err := svc.FindNotificationRuleByID(ctx, ruleID)
+	require.NoError(t, err)
+	steps := escalationSteps(nr)
+	require.Len(t, steps, 1)
+	require.True(t, steps[0].TaskID.Valid())
+
+	// a single task of the escalation step was created, with the status of
+	// the task of the rule
+	ts, _, err := tasks.FindTasks(ctx, taskmodel.TaskFilter{OrganizationID: &orgID})
+	require.NoError(t, err)
+	assert.Len(t, ts, 2)
+	st, err := tasks.FindTaskByID(ctx, steps[0].TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, string(influxdb.Active), st.Status)
+}
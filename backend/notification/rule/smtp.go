@@ -0,0 +1,137 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// SMTP is the notification rule config of email sent through an SMTP endpoint.
+type SMTP struct {
+	Base
+	// To are the recipient addresses of the emails.
+	To              []string `json:"to"`
+	SubjectTemplate string   `json:"subjectTemplate"`
+	MessageTemplate string   `json:"messageTemplate"`
+}
+
+// GenerateFlux generates a flux script for the smtp notification rule.
+func (s *SMTP) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	smtpEndpoint, ok := e.(*endpoint.SMTP)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an SMTP endpoint", e.Type())
+	}
+	return astutil.Format(s.GenerateFluxAST(smtpEndpoint))
+}
+
+// GenerateFluxAST generates a flux AST for the smtp notification rule. The
+// emails are posted to the email relay of the endpoint, which sends them to
+// the mail server.
+func (s *SMTP) GenerateFluxAST(e *endpoint.SMTP) *ast.File {
+	return flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "http", "json", "influxdata/influxdb/secrets", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+}
+
+func (s *SMTP) generateFluxASTBody(e *endpoint.SMTP) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTSecrets(e))
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe())
+
+	return statements
+}
+
+func (s *SMTP) generateFluxASTSecrets(e *endpoint.SMTP) ast.Statement {
+	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.RelayKey.Key))))
+
+	return flux.DefineVariable("smtp_relay_key", call)
+}
+
+func (s *SMTP) generateFluxASTEndpoint(e *endpoint.SMTP) ast.Statement {
+	call := flux.Call(flux.Member("http", "endpoint"), flux.Object(flux.Property("url", flux.String(e.RelayEndpoint()))))
+
+	return flux.DefineVariable("smtp_endpoint", call)
+}
+
+func (s *SMTP) generateFluxASTNotifyPipe() ast.Statement {
+	body := flux.DefineVariable("body", flux.Object(
+		flux.Property("to", stringArray(s.To)),
+		flux.Property("subject", flux.String(s.SubjectTemplate)),
+		flux.Property("body", flux.String(s.MessageTemplate)),
+	))
+	headers := flux.Object(
+		flux.Dictionary("Content-Type", flux.String("application/json")),
+		flux.Dictionary(endpoint.SMTPRelayKeyHeader, flux.Identifier("smtp_relay_key")),
+	)
+	endpointBody := flux.Call(
+		flux.Member("json", "encode"),
+		flux.Object(flux.Property("v", flux.Identifier("body"))),
+	)
+	endpointFn := flux.FuncBlock(flux.FunctionParams("r"),
+		body,
+		&ast.ReturnStatement{
+			Argument: flux.Object(
+				flux.Property("headers", headers),
+				flux.Property("data", endpointBody),
+			),
+		},
+	)
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("smtp_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+}
+
+type smtpAlias SMTP
+
+// MarshalJSON implement json.Marshaler interface.
+func (s SMTP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			smtpAlias
+			Type string `json:"type"`
+		}{
+			smtpAlias: smtpAlias(s),
+			Type:      s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s SMTP) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if err := (endpoint.Email{To: s.To}).Valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "SMTP MessageTemplate is invalid",
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the rule config.
+func (s SMTP) Type() string {
+	return "smtp"
+}
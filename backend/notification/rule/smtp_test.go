@@ -0,0 +1,165 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+var _ influxdb.NotificationRule = &rule.SMTP{}
+
+func TestSMTP_GenerateFlux(t *testing.T) {
+	want := influxTesting.FormatFluxString(t, `import "influxdata/influxdb/monitor"
+import "http"
+import "json"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+smtp_relay_key = secrets["get"](key: "3-relay-key")
+smtp_endpoint = http["endpoint"](url: "http://influxdb:8086/api/v2/notificationEndpoints/0000000000000003/email")
+notification = {
+    _notification_rule_id: "0000000000000001",
+    _notification_rule_name: "foo",
+    _notification_endpoint_id: "0000000000000003",
+    _notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses |> filter(fn: (r) => r["_level"] == "crit")
+all_statuses = crit |> filter(fn: (r) => r["_time"] >= experimental["subDuration"](from: now(), d: 1h))
+
+all_statuses
+    |> monitor["notify"](
+        data: notification,
+        endpoint:
+            smtp_endpoint(
+                mapFn: (r) => {
+                    body = {to: ["ops@example.com", "jane@example.com"], subject: "cpu check", body: "blah"}
+
+                    return {
+                        headers: {"Content-Type": "application/json", "X-Influxdb-Relay-Key": smtp_relay_key},
+                        data: json["encode"](v: body),
+                    }
+                },
+            ),
+    )
+`)
+	s := &rule.SMTP{
+		To:              []string{"ops@example.com", "jane@example.com"},
+		SubjectTemplate: "cpu check",
+		MessageTemplate: "blah",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 3,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+		},
+	}
+	e := &endpoint.SMTP{
+		Base: endpoint.Base{
+			ID:   idPtr(3),
+			Name: "foo",
+		},
+		Host:     "smtp.example.com",
+		From:     "influxdb@example.com",
+		RelayURL: "http://influxdb:8086",
+		RelayKey: influxdb.SecretField{Key: "3-relay-key"},
+	}
+
+	f, err := s.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f != want {
+		t.Errorf("\n\nScripts did not match:\n\n%s", diff.LineDiff(f, want))
+	}
+
+	if _, err := s.GenerateFlux(&endpoint.Teams{}); err == nil {
+		t.Error("expected error generating flux for a teams endpoint")
+	}
+}
+
+func TestSMTP_Valid(t *testing.T) {
+	base := rule.Base{
+		ID:         1,
+		EndpointID: 3,
+		OwnerID:    4,
+		OrgID:      5,
+		Name:       "foo",
+		Every:      mustDuration("1h"),
+		StatusRules: []notification.StatusRule{
+			{
+				CurrentLevel: notification.Critical,
+			},
+		},
+		TagRules: []notification.TagRule{},
+	}
+	cases := []struct {
+		name string
+		rule *rule.SMTP
+		err  error
+	}{
+		{
+			name: "valid template",
+			rule: &rule.SMTP{
+				Base:            base,
+				To:              []string{"ops@example.com"},
+				MessageTemplate: "blah",
+			},
+			err: nil,
+		},
+		{
+			name: "missing recipients",
+			rule: &rule.SMTP{
+				Base:            base,
+				MessageTemplate: "blah",
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "email must have at least one recipient",
+			},
+		},
+		{
+			name: "invalid recipient",
+			rule: &rule.SMTP{
+				Base:            base,
+				To:              []string{"ops"},
+				MessageTemplate: "blah",
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `email recipient "ops" is invalid: mail: missing '@' or angle-addr`,
+			},
+		},
+		{
+			name: "missing MessageTemplate",
+			rule: &rule.SMTP{
+				Base: base,
+				To:   []string{"ops@example.com"},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "SMTP MessageTemplate is invalid",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.rule.Valid()
+			influxTesting.ErrorsEqual(t, got, c.err)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package silence
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixSilences = "/api/v2/silences"
+
+// Handler is the HTTP handler of the silence service.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc SilenceService
+}
+
+// NewHTTPHandler constructs the HTTP handler of the silence service.
+func NewHTTPHandler(log *zap.Logger, svc SilenceService) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostSilence)
+		r.Get("/", h.handleGetSilences)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetSilence)
+			r.Put("/", h.handlePutSilence)
+			r.Delete("/", h.handleDeleteSilence)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix of the routes of the handler.
+func (h *Handler) Prefix() string {
+	return prefixSilences
+}
+
+type silenceResponse struct {
+	Links map[string]string `json:"links"`
+	*Silence
+}
+
+func newSilenceResponse(s *Silence) silenceResponse {
+	return silenceResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", prefixSilences, s.ID),
+		},
+		Silence: s,
+	}
+}
+
+type silencesResponse struct {
+	Links    map[string]string `json:"links"`
+	Silences []silenceResponse `json:"silences"`
+}
+
+// handlePostSilence is the HTTP handler for the POST /api/v2/silences route.
+func (h *Handler) handlePostSilence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var s Silence
+	if err := h.api.DecodeJSON(r.Body, &s); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.CreateSilence(ctx, &s, auth.GetUserID()); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Silence created", zap.Stringer("silenceID", s.ID))
+
+	h.api.Respond(w, r, http.StatusCreated, newSilenceResponse(&s))
+}
+
+// handleGetSilences is the HTTP handler for the GET /api/v2/silences route.
+func (h *Handler) handleGetSilences(w http.ResponseWriter, r *http.Request) {
+	var filter Filter
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			h.api.Err(w, r, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			})
+			return
+		}
+		filter.OrgID = id
+	}
+	if checkID := qp.Get("checkID"); checkID != "" {
+		id, err := platform.IDFromString(checkID)
+		if err != nil {
+			h.api.Err(w, r, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "checkID is invalid",
+				Err:  err,
+			})
+			return
+		}
+		filter.CheckID = id
+	}
+
+	silences, err := h.svc.FindSilences(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := silencesResponse{
+		Links: map[string]string{
+			"self": prefixSilences,
+		},
+		Silences: make([]silenceResponse, 0, len(silences)),
+	}
+	for _, s := range silences {
+		res.Silences = append(res.Silences, newSilenceResponse(s))
+	}
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
+// handleGetSilence is the HTTP handler for the GET /api/v2/silences/:id route.
+func (h *Handler) handleGetSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	s, err := h.svc.FindSilenceByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newSilenceResponse(s))
+}
+
+// handlePutSilence is the HTTP handler for the PUT /api/v2/silences/:id route.
+func (h *Handler) handlePutSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var upd Silence
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	s, err := h.svc.UpdateSilence(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Silence updated", zap.Stringer("silenceID", s.ID))
+
+	h.api.Respond(w, r, http.StatusOK, newSilenceResponse(s))
+}
+
+// handleDeleteSilence is the HTTP handler for the DELETE /api/v2/silences/:id route.
+func (h *Handler) handleDeleteSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.DeleteSilence(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Silence deleted", zap.Stringer("silenceID", *id))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
@@ -0,0 +1,90 @@
+package silence
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+var _ SilenceService = (*AuthedService)(nil)
+
+// AuthedService authorizes access to silences. Silences are part of the
+// notification rules of an organization, so they require the permissions
+// of its notification rules.
+type AuthedService struct {
+	s SilenceService
+}
+
+// NewAuthedService constructs an instance of an authorizing silence service.
+func NewAuthedService(s SilenceService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+// FindSilenceByID checks to see if the authorizer on context has read access to the silence.
+func (s *AuthedService) FindSilenceByID(ctx context.Context, id platform.ID) (*Silence, error) {
+	sil, err := s.s.FindSilenceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, sil.OrgID); err != nil {
+		return nil, err
+	}
+	return sil, nil
+}
+
+// FindSilences returns the silences the authorizer on context has read access to.
+func (s *AuthedService) FindSilences(ctx context.Context, filter Filter) ([]*Silence, error) {
+	if filter.OrgID != nil {
+		if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, *filter.OrgID); err != nil {
+			return nil, err
+		}
+		return s.s.FindSilences(ctx, filter)
+	}
+
+	silences, err := s.s.FindSilences(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	authorized := silences[:0]
+	for _, sil := range silences {
+		if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, sil.OrgID); err != nil {
+			continue
+		}
+		authorized = append(authorized, sil)
+	}
+	return authorized, nil
+}
+
+// CreateSilence checks to see if the authorizer on context has write access to the notification rules of the organization.
+func (s *AuthedService) CreateSilence(ctx context.Context, sil *Silence, userID platform.ID) error {
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, sil.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateSilence(ctx, sil, userID)
+}
+
+// UpdateSilence checks to see if the authorizer on context has write access to the silence.
+func (s *AuthedService) UpdateSilence(ctx context.Context, id platform.ID, upd Silence) (*Silence, error) {
+	sil, err := s.s.FindSilenceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, sil.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateSilence(ctx, id, upd)
+}
+
+// DeleteSilence checks to see if the authorizer on context has write access to the silence.
+func (s *AuthedService) DeleteSilence(ctx context.Context, id platform.ID) error {
+	sil, err := s.s.FindSilenceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, sil.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteSilence(ctx, id)
+}
@@ -0,0 +1,60 @@
+package silence
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// RuleTaskUpdater regenerates the tasks of the notification rules of an organization.
+type RuleTaskUpdater interface {
+	UpdateNotificationRuleTasks(ctx context.Context, orgID platform.ID) error
+}
+
+var _ SilenceService = (*RuleSyncService)(nil)
+
+// RuleSyncService keeps the tasks of notification rules up to date with the
+// silences of their organization, since silences are compiled into the flux
+// of the tasks.
+type RuleSyncService struct {
+	SilenceService
+	rules RuleTaskUpdater
+}
+
+// NewRuleSyncService constructs a silence service that regenerates the tasks of
+// notification rules whenever the silences of their organization change.
+func NewRuleSyncService(s SilenceService, rules RuleTaskUpdater) *RuleSyncService {
+	return &RuleSyncService{
+		SilenceService: s,
+		rules:          rules,
+	}
+}
+
+// CreateSilence creates a silence and updates the tasks of the organization.
+func (s *RuleSyncService) CreateSilence(ctx context.Context, sil *Silence, userID platform.ID) error {
+	if err := s.SilenceService.CreateSilence(ctx, sil, userID); err != nil {
+		return err
+	}
+	return s.rules.UpdateNotificationRuleTasks(ctx, sil.OrgID)
+}
+
+// UpdateSilence updates a silence and updates the tasks of the organization.
+func (s *RuleSyncService) UpdateSilence(ctx context.Context, id platform.ID, upd Silence) (*Silence, error) {
+	sil, err := s.SilenceService.UpdateSilence(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	return sil, s.rules.UpdateNotificationRuleTasks(ctx, sil.OrgID)
+}
+
+// DeleteSilence removes a silence and updates the tasks of the organization.
+func (s *RuleSyncService) DeleteSilence(ctx context.Context, id platform.ID) error {
+	sil, err := s.SilenceService.FindSilenceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.SilenceService.DeleteSilence(ctx, id); err != nil {
+		return err
+	}
+	return s.rules.UpdateNotificationRuleTasks(ctx, sil.OrgID)
+}
@@ -0,0 +1,215 @@
+package silence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	silenceBucket = []byte("silencesv1")
+
+	// ErrSilenceNotFound is used when the silence is not found.
+	ErrSilenceNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "silence not found",
+	}
+
+	// ErrInvalidSilenceID is used when the service was provided an invalid ID format.
+	ErrInvalidSilenceID = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided silence ID has invalid format",
+	}
+)
+
+var _ SilenceService = (*Service)(nil)
+
+// Service is an implementation of SilenceService backed by the kv store.
+type Service struct {
+	kv kv.Store
+
+	IDGenerator   platform.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a silence service backed by store.
+func NewService(store kv.Store) *Service {
+	return &Service{
+		kv:            store,
+		IDGenerator:   snowflake.NewIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+func internalSilenceStoreError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal silence data error; Err: %v", err),
+		Op:   "kv/silence",
+	}
+}
+
+// FindSilenceByID returns a single silence by ID.
+func (s *Service) FindSilenceByID(ctx context.Context, id platform.ID) (*Silence, error) {
+	var sil *Silence
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		sil, err = s.findSilenceByID(tx, id)
+		return err
+	})
+	return sil, err
+}
+
+func (s *Service) findSilenceByID(tx kv.Tx, id platform.ID) (*Silence, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidSilenceID
+	}
+
+	b, err := tx.Bucket(silenceBucket)
+	if err != nil {
+		return nil, internalSilenceStoreError(err)
+	}
+
+	v, err := b.Get(encID)
+	if kv.IsNotFound(err) {
+		return nil, ErrSilenceNotFound
+	}
+	if err != nil {
+		return nil, internalSilenceStoreError(err)
+	}
+
+	var sil Silence
+	if err := json.Unmarshal(v, &sil); err != nil {
+		return nil, internalSilenceStoreError(err)
+	}
+	return &sil, nil
+}
+
+// FindSilences returns the silences that match filter.
+func (s *Service) FindSilences(ctx context.Context, filter Filter) ([]*Silence, error) {
+	silences := make([]*Silence, 0)
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(silenceBucket)
+		if err != nil {
+			return internalSilenceStoreError(err)
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return internalSilenceStoreError(err)
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var sil Silence
+			if err := json.Unmarshal(v, &sil); err != nil {
+				return internalSilenceStoreError(err)
+			}
+			if filter.OrgID != nil && sil.OrgID != *filter.OrgID {
+				continue
+			}
+			if filter.CheckID != nil && (sil.CheckID == nil || *sil.CheckID != *filter.CheckID) {
+				continue
+			}
+			silences = append(silences, &sil)
+		}
+		return cur.Err()
+	})
+	return silences, err
+}
+
+// CreateSilence creates a new silence created by userID, and sets sil.ID with the new identifier.
+func (s *Service) CreateSilence(ctx context.Context, sil *Silence, userID platform.ID) error {
+	if err := sil.Valid(); err != nil {
+		return err
+	}
+
+	now := s.TimeGenerator.Now()
+	sil.ID = s.IDGenerator.ID()
+	sil.CreatorID = userID
+	sil.CreatedAt = now
+	sil.UpdatedAt = now
+
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		return s.putSilence(tx, sil)
+	})
+}
+
+// UpdateSilence replaces the matchers, times and comment of a silence.
+func (s *Service) UpdateSilence(ctx context.Context, id platform.ID, upd Silence) (*Silence, error) {
+	var sil *Silence
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		existing, err := s.findSilenceByID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// ID, OrgID and CreatorID can not be updated
+		upd.ID = existing.ID
+		upd.OrgID = existing.OrgID
+		upd.CreatorID = existing.CreatorID
+		upd.CreatedAt = existing.CreatedAt
+		upd.UpdatedAt = s.TimeGenerator.Now()
+		if err := upd.Valid(); err != nil {
+			return err
+		}
+
+		sil = &upd
+		return s.putSilence(tx, sil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sil, nil
+}
+
+// DeleteSilence removes a silence by ID.
+func (s *Service) DeleteSilence(ctx context.Context, id platform.ID) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		if _, err := s.findSilenceByID(tx, id); err != nil {
+			return err
+		}
+
+		encID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidSilenceID
+		}
+
+		b, err := tx.Bucket(silenceBucket)
+		if err != nil {
+			return internalSilenceStoreError(err)
+		}
+		if err := b.Delete(encID); err != nil {
+			return internalSilenceStoreError(err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) putSilence(tx kv.Tx, sil *Silence) error {
+	encID, err := sil.ID.Encode()
+	if err != nil {
+		return ErrInvalidSilenceID
+	}
+
+	v, err := json.Marshal(sil)
+	if err != nil {
+		return internalSilenceStoreError(err)
+	}
+
+	b, err := tx.Bucket(silenceBucket)
+	if err != nil {
+		return internalSilenceStoreError(err)
+	}
+	if err := b.Put(encID, v); err != nil {
+		return internalSilenceStoreError(err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package silence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/silence"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	orgOneID = platform.ID(1)
+	orgTwoID = platform.ID(2)
+	checkID  = platform.ID(3)
+	userID   = platform.ID(4)
+)
+
+func newTestService(t *testing.T, now time.Time) *silence.Service {
+	t.Helper()
+
+	svc := silence.NewService(itesting.NewTestInmemStore(t))
+	svc.IDGenerator = mock.NewIncrementingIDGenerator(100)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	return svc
+}
+
+func TestService_CRUD(t *testing.T) {
+	var (
+		ctx = context.Background()
+		now = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+		end = now.Add(2 * time.Hour)
+	)
+	svc := newTestService(t, now)
+
+	first := &silence.Silence{
+		OrgID:   orgOneID,
+		CheckID: &checkID,
+		Levels:  []notification.CheckLevel{notification.Critical},
+		EndTime: &end,
+		Comment: "deploying",
+	}
+	require.NoError(t, svc.CreateSilence(ctx, first, userID))
+	assert.Equal(t, platform.ID(100), first.ID)
+	assert.Equal(t, userID, first.CreatorID)
+	assert.Equal(t, influxdb.CRUDLog{CreatedAt: now, UpdatedAt: now}, first.CRUDLog)
+
+	second := &silence.Silence{
+		OrgID:  orgTwoID,
+		Window: &silence.Window{Days: []string{"sunday"}, Start: "02:00", Duration: "1h"},
+	}
+	require.NoError(t, svc.CreateSilence(ctx, second, userID))
+
+	found, err := svc.FindSilenceByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.Comment, found.Comment)
+	assert.Equal(t, first.Levels, found.Levels)
+	assert.True(t, end.Equal(*found.EndTime))
+
+	silences, err := svc.FindSilences(ctx, silence.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, silences, 2)
+
+	silences, err = svc.FindSilences(ctx, silence.Filter{OrgID: &orgTwoID})
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+	assert.Equal(t, second.ID, silences[0].ID)
+
+	silences, err = svc.FindSilences(ctx, silence.Filter{CheckID: &checkID})
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+	assert.Equal(t, first.ID, silences[0].ID)
+
+	// the org and creator of a silence are not updated
+	updated, err := svc.UpdateSilence(ctx, first.ID, silence.Silence{
+		OrgID:   orgTwoID,
+		EndTime: &end,
+		Comment: "deploying again",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, orgOneID, updated.OrgID)
+	assert.Equal(t, userID, updated.CreatorID)
+	assert.Nil(t, updated.CheckID)
+	assert.Equal(t, "deploying again", updated.Comment)
+
+	_, err = svc.UpdateSilence(ctx, first.ID, silence.Silence{Comment: "forever"})
+	itesting.ErrorsEqual(t, err, &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "silence must have an end time or a maintenance window",
+	})
+
+	require.NoError(t, svc.DeleteSilence(ctx, first.ID))
+	_, err = svc.FindSilenceByID(ctx, first.ID)
+	itesting.ErrorsEqual(t, err, silence.ErrSilenceNotFound)
+	itesting.ErrorsEqual(t, svc.DeleteSilence(ctx, first.ID), silence.ErrSilenceNotFound)
+}
+
+func TestService_CreateSilence_invalid(t *testing.T) {
+	svc := newTestService(t, time.Now())
+
+	err := svc.CreateSilence(context.Background(), &silence.Silence{OrgID: orgOneID}, userID)
+	itesting.ErrorsEqual(t, err, &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "silence must have an end time or a maintenance window",
+	})
+
+	silences, err := svc.FindSilences(context.Background(), silence.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, silences)
+}
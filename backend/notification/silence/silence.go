@@ -0,0 +1,271 @@
+// Package silence provides silences, which mute the notifications of matching
+// statuses for a period of time or during recurring maintenance windows.
+package silence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// SilenceService manages silences.
+type SilenceService interface {
+	// FindSilenceByID returns a single silence by ID.
+	FindSilenceByID(ctx context.Context, id platform.ID) (*Silence, error)
+
+	// FindSilences returns the silences that match filter.
+	FindSilences(ctx context.Context, filter Filter) ([]*Silence, error)
+
+	// CreateSilence creates a new silence created by userID, and sets s.ID with the new identifier.
+	CreateSilence(ctx context.Context, s *Silence, userID platform.ID) error
+
+	// UpdateSilence replaces the matchers, times and comment of a silence.
+	UpdateSilence(ctx context.Context, id platform.ID, s Silence) (*Silence, error)
+
+	// DeleteSilence removes a silence by ID.
+	DeleteSilence(ctx context.Context, id platform.ID) error
+}
+
+// Silence mutes the notifications of the statuses that match all of its
+// matchers between StartTime and EndTime. When the silence has a Window,
+// it only mutes them during the recurring maintenance window.
+type Silence struct {
+	ID    platform.ID `json:"id,omitempty"`
+	OrgID platform.ID `json:"orgID"`
+
+	// CheckID, Tags and Levels match statuses; a silence without matchers
+	// matches every status of the organization.
+	CheckID *platform.ID              `json:"checkID,omitempty"`
+	Tags    []influxdb.Tag            `json:"tags,omitempty"`
+	Levels  []notification.CheckLevel `json:"levels,omitempty"`
+
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	Window    *Window    `json:"window,omitempty"`
+
+	CreatorID platform.ID `json:"creatorID,omitempty"`
+	Comment   string      `json:"comment,omitempty"`
+	influxdb.CRUDLog
+}
+
+// Window is a maintenance window that recurs on Days, from Start for Duration.
+// Times of day are in UTC.
+type Window struct {
+	// Days are the lowercase names of the week days the window starts on; the
+	// window starts every day when empty.
+	Days []string `json:"days,omitempty"`
+	// Start is the time of day the window starts, formatted as HH:MM.
+	Start string `json:"start"`
+	// Duration is the duration of the window, of at most 24h.
+	Duration string `json:"duration"`
+}
+
+// Filter restricts the silences returned by FindSilences.
+type Filter struct {
+	OrgID   *platform.ID
+	CheckID *platform.ID
+}
+
+// Expired returns whether the silence ended before now.
+func (s *Silence) Expired(now time.Time) bool {
+	return s.EndTime != nil && !s.EndTime.After(now)
+}
+
+// Valid returns an error if the silence is invalid.
+func (s *Silence) Valid() error {
+	if !s.OrgID.Valid() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "silence orgID is invalid",
+		}
+	}
+	if s.CheckID != nil && !s.CheckID.Valid() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "silence checkID is invalid",
+		}
+	}
+	for _, t := range s.Tags {
+		if t.Key == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence tag key must be provided",
+			}
+		}
+	}
+	for _, l := range s.Levels {
+		if l == notification.Any {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence level must be one of UNKNOWN, OK, INFO, WARN, CRIT",
+			}
+		}
+	}
+	if s.EndTime == nil && s.Window == nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "silence must have an end time or a maintenance window",
+		}
+	}
+	if s.StartTime != nil && s.EndTime != nil && !s.StartTime.Before(*s.EndTime) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "silence start time must be before its end time",
+		}
+	}
+	if s.Window != nil {
+		if err := s.Window.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var weekDays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Valid returns an error if the window is invalid.
+func (w *Window) Valid() error {
+	for _, d := range w.Days {
+		if _, ok := weekDays[d]; !ok {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("maintenance window day %q is invalid", d),
+			}
+		}
+	}
+	if _, err := time.Parse("15:04", w.Start); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("maintenance window start %q must be a time of day formatted as HH:MM", w.Start),
+		}
+	}
+	d, err := time.ParseDuration(w.Duration)
+	if err != nil || d <= 0 || d > 24*time.Hour || d%time.Minute != 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("maintenance window duration %q must be a whole number of minutes of at most 24h", w.Duration),
+		}
+	}
+	return nil
+}
+
+// minutes returns the minute of the day the window starts and ends. The end
+// is past 1440 when the window ends on the next day.
+func (w *Window) minutes() (start, end int64) {
+	t, _ := time.Parse("15:04", w.Start)
+	d, _ := time.ParseDuration(w.Duration)
+	start = int64(t.Hour()*60 + t.Minute())
+	return start, start + int64(d/time.Minute)
+}
+
+const minutesPerDay = 24 * 60
+
+// GenerateFluxAST generates the flux predicate of the statuses r matched by
+// the silence.
+func (s *Silence) GenerateFluxAST() ast.Expression {
+	var conds []ast.Expression
+	if s.CheckID != nil {
+		conds = append(conds, flux.Equal(flux.Member("r", "_check_id"), flux.String(s.CheckID.String())))
+	}
+	for _, t := range s.Tags {
+		conds = append(conds, flux.Equal(flux.Member("r", t.Key), flux.String(t.Value)))
+	}
+	if len(s.Levels) > 0 {
+		var levels []ast.Expression
+		for _, l := range s.Levels {
+			levels = append(levels, flux.Equal(flux.Member("r", "_level"), flux.String(strings.ToLower(l.String()))))
+		}
+		conds = append(conds, or(levels...))
+	}
+	if s.StartTime != nil {
+		conds = append(conds, &ast.BinaryExpression{
+			Operator: ast.GreaterThanEqualOperator,
+			Left:     flux.Member("r", "_time"),
+			Right:    &ast.DateTimeLiteral{Value: s.StartTime.UTC()},
+		})
+	}
+	if s.EndTime != nil {
+		conds = append(conds, flux.LessThan(flux.Member("r", "_time"), &ast.DateTimeLiteral{Value: s.EndTime.UTC()}))
+	}
+	if s.Window != nil {
+		conds = append(conds, s.Window.generateFluxAST())
+	}
+	if len(conds) == 0 {
+		return flux.Bool(true)
+	}
+	return and(conds...)
+}
+
+// generateFluxAST generates the flux predicate of the statuses r within the
+// window. The day and minute of r._time are computed from its nanoseconds
+// since the epoch, which was a thursday.
+func (w *Window) generateFluxAST() ast.Expression {
+	const nsPerDay, nsPerMinute = int64(24 * time.Hour), int64(time.Minute)
+
+	ns := flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", flux.Member("r", "_time"))))
+	minute := binary(ast.DivisionOperator, binary(ast.ModuloOperator, ns, flux.Integer(nsPerDay)), flux.Integer(nsPerMinute))
+	day := binary(ast.ModuloOperator, flux.Add(binary(ast.DivisionOperator, ns, flux.Integer(nsPerDay)), flux.Integer(4)), flux.Integer(7))
+
+	onDays := func(shift int) []ast.Expression {
+		if len(w.Days) == 0 {
+			return nil
+		}
+		var days []ast.Expression
+		for _, d := range w.Days {
+			wd := (int(weekDays[d]) + shift) % 7
+			days = append(days, flux.Equal(day, flux.Integer(int64(wd))))
+		}
+		return []ast.Expression{or(days...)}
+	}
+
+	start, end := w.minutes()
+	afterStart := binary(ast.GreaterThanEqualOperator, minute, flux.Integer(start))
+	if end <= minutesPerDay {
+		return and(append(onDays(0), afterStart, flux.LessThan(minute, flux.Integer(end)))...)
+	}
+	// the window ends on the next day
+	return or(
+		and(append(onDays(0), afterStart)...),
+		and(append(onDays(1), flux.LessThan(minute, flux.Integer(end-minutesPerDay)))...),
+	)
+}
+
+func binary(op ast.OperatorKind, lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: op,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+func and(exprs ...ast.Expression) ast.Expression {
+	e := exprs[0]
+	for _, next := range exprs[1:] {
+		e = flux.And(e, next)
+	}
+	return e
+}
+
+func or(exprs ...ast.Expression) ast.Expression {
+	e := exprs[0]
+	for _, next := range exprs[1:] {
+		e = flux.Or(e, next)
+	}
+	return e
+}
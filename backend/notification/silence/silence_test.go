@@ -0,0 +1,161 @@
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestSilence_Valid(t *testing.T) {
+	var (
+		orgID   = platform.ID(1)
+		checkID = platform.ID(2)
+		start   = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+		end     = start.Add(2 * time.Hour)
+	)
+
+	cases := []struct {
+		name string
+		src  Silence
+		err  error
+	}{
+		{
+			name: "valid with end time",
+			src: Silence{
+				OrgID:     orgID,
+				CheckID:   &checkID,
+				Tags:      []influxdb.Tag{{Key: "host", Value: "api-01"}},
+				Levels:    []notification.CheckLevel{notification.Critical},
+				StartTime: &start,
+				EndTime:   &end,
+			},
+		},
+		{
+			name: "valid with window",
+			src: Silence{
+				OrgID:  orgID,
+				Window: &Window{Days: []string{"saturday"}, Start: "22:30", Duration: "3h"},
+			},
+		},
+		{
+			name: "invalid orgID",
+			src:  Silence{EndTime: &end},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence orgID is invalid",
+			},
+		},
+		{
+			name: "invalid checkID",
+			src:  Silence{OrgID: orgID, CheckID: new(platform.ID), EndTime: &end},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence checkID is invalid",
+			},
+		},
+		{
+			name: "empty tag key",
+			src:  Silence{OrgID: orgID, Tags: []influxdb.Tag{{Value: "api-01"}}, EndTime: &end},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence tag key must be provided",
+			},
+		},
+		{
+			name: "any level",
+			src:  Silence{OrgID: orgID, Levels: []notification.CheckLevel{notification.Any}, EndTime: &end},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence level must be one of UNKNOWN, OK, INFO, WARN, CRIT",
+			},
+		},
+		{
+			name: "no end time or window",
+			src:  Silence{OrgID: orgID, StartTime: &start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence must have an end time or a maintenance window",
+			},
+		},
+		{
+			name: "start time after end time",
+			src:  Silence{OrgID: orgID, StartTime: &end, EndTime: &start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "silence start time must be before its end time",
+			},
+		},
+		{
+			name: "invalid window day",
+			src:  Silence{OrgID: orgID, Window: &Window{Days: []string{"someday"}, Start: "22:30", Duration: "3h"}},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `maintenance window day "someday" is invalid`,
+			},
+		},
+		{
+			name: "invalid window start",
+			src:  Silence{OrgID: orgID, Window: &Window{Start: "25:00", Duration: "3h"}},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `maintenance window start "25:00" must be a time of day formatted as HH:MM`,
+			},
+		},
+		{
+			name: "window longer than a day",
+			src:  Silence{OrgID: orgID, Window: &Window{Start: "22:30", Duration: "25h"}},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `maintenance window duration "25h" must be a whole number of minutes of at most 24h`,
+			},
+		},
+		{
+			name: "window duration with seconds",
+			src:  Silence{OrgID: orgID, Window: &Window{Start: "22:30", Duration: "90s"}},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `maintenance window duration "90s" must be a whole number of minutes of at most 24h`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			itesting.ErrorsEqual(t, c.src.Valid(), c.err)
+		})
+	}
+}
+
+func TestSilence_Expired(t *testing.T) {
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.False(t, (&Silence{}).Expired(now))
+	assert.False(t, (&Silence{EndTime: timePtr(now.Add(time.Minute))}).Expired(now))
+	assert.True(t, (&Silence{EndTime: timePtr(now)}).Expired(now))
+	assert.True(t, (&Silence{EndTime: timePtr(now.Add(-time.Minute))}).Expired(now))
+}
+
+func TestWindow_minutes(t *testing.T) {
+	cases := []struct {
+		window     Window
+		start, end int64
+	}{
+		{window: Window{Start: "00:00", Duration: "24h"}, start: 0, end: 1440},
+		{window: Window{Start: "09:15", Duration: "30m"}, start: 555, end: 585},
+		{window: Window{Start: "22:30", Duration: "3h"}, start: 1350, end: 1530},
+	}
+	for _, c := range cases {
+		start, end := c.window.minutes()
+		assert.Equal(t, c.start, start, c.window.Start)
+		assert.Equal(t, c.end, end, c.window.Start)
+	}
+}
@@ -0,0 +1,339 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/gather"
+	"github.com/influxdata/influxdb/v2/models"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types of OTLP/HTTP requests and responses.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Field keys of gauges and sums, matching those written by the Prometheus scraper.
+const (
+	GaugeField   = "gauge"
+	CounterField = "counter"
+	// DeltaField holds the value of sums with delta temporality, which is the
+	// change since the previous data point rather than a running total.
+	DeltaField = "delta"
+)
+
+// TemporalityTag tags histograms and exponential histograms with delta
+// temporality, whose counts are since the previous data point rather than
+// running totals, so they are never in the same series as cumulative ones.
+const TemporalityTag = "temporality"
+
+// ErrUnsupportedContentType is returned when a request is neither protocol buffers nor JSON.
+var ErrUnsupportedContentType = errors.New("unsupported content type; must be application/x-protobuf or application/json")
+
+// UnmarshalMetricsRequest decodes an export request encoded as contentType.
+func UnmarshalMetricsRequest(b []byte, contentType string) (*ExportMetricsServiceRequest, error) {
+	var req ExportMetricsServiceRequest
+	switch mediaType(contentType) {
+	case ContentTypeProtobuf:
+		if err := proto.Unmarshal(b, &req); err != nil {
+			return nil, err
+		}
+	case ContentTypeJSON:
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, &req); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedContentType
+	}
+	return &req, nil
+}
+
+// MarshalMetricsResponse encodes resp using the encoding of the request's contentType.
+func MarshalMetricsResponse(resp *ExportMetricsServiceResponse, contentType string) ([]byte, error) {
+	switch mediaType(contentType) {
+	case ContentTypeProtobuf:
+		return proto.Marshal(resp)
+	case ContentTypeJSON:
+		return protojson.Marshal(resp)
+	default:
+		return nil, ErrUnsupportedContentType
+	}
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// MetricsToPoints converts an export request to points. Each metric is written to a
+// measurement of the same name, tagged with its resource and data point attributes.
+//
+// Gauges and non-monotonic sums are written to the "gauge" field and monotonic sums to
+// the "counter" field, unless they have delta temporality, in which case they are
+// written to the "delta" field so that they are never mistaken for running totals.
+// Like the values of scraped metrics, they are always written as floats.
+// Histograms, exponential histograms and summaries use the same layout as Prometheus
+// metrics scraped in the bounds format: a single series per data point, with one field
+// per cumulative bucket upper bound or quantile plus the count and sum, so histograms
+// can be queried with Flux's histogramQuantile. Exponential histogram
+// buckets are converted to their explicit upper bounds. Data points without a
+// timestamp are written at now, and NaN values are dropped. Histograms with delta
+// temporality are tagged with "temporality" set to "delta".
+func MetricsToPoints(req *ExportMetricsServiceRequest, now time.Time) (models.Points, error) {
+	var c converter
+	for _, rm := range req.GetResourceMetrics() {
+		resource := attributesToTags(nil, rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				c.metric(m, resource, now)
+			}
+		}
+	}
+	return c.points, c.err
+}
+
+type converter struct {
+	points models.Points
+	err    error
+}
+
+func (c *converter) add(name string, tags map[string]string, fields models.Fields, ts time.Time) {
+	for k, v := range fields {
+		if f, ok := v.(float64); ok && math.IsNaN(f) {
+			delete(fields, k)
+		}
+	}
+	if c.err != nil || len(fields) == 0 {
+		return
+	}
+
+	p, err := models.NewPoint(name, models.NewTags(tags), fields, ts)
+	if err != nil {
+		c.err = fmt.Errorf("invalid metric %q: %w", name, err)
+		return
+	}
+	c.points = append(c.points, p)
+}
+
+func (c *converter) metric(m *Metric, resource map[string]string, now time.Time) {
+	name := m.GetName()
+	switch data := m.GetData().(type) {
+	case *Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			c.number(name, GaugeField, resource, dp, now)
+		}
+	case *Metric_Sum:
+		field := GaugeField
+		switch {
+		case isDelta(data.Sum.GetAggregationTemporality()):
+			field = DeltaField
+		case data.Sum.GetIsMonotonic():
+			field = CounterField
+		}
+		for _, dp := range data.Sum.GetDataPoints() {
+			c.number(name, field, resource, dp, now)
+		}
+	case *Metric_Histogram:
+		resource := temporality(resource, data.Histogram.GetAggregationTemporality())
+		for _, dp := range data.Histogram.GetDataPoints() {
+			c.histogram(name, resource, dp, now)
+		}
+	case *Metric_ExponentialHistogram:
+		resource := temporality(resource, data.ExponentialHistogram.GetAggregationTemporality())
+		for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+			c.exponentialHistogram(name, resource, dp, now)
+		}
+	case *Metric_Summary:
+		for _, dp := range data.Summary.GetDataPoints() {
+			c.summary(name, resource, dp, now)
+		}
+	}
+}
+
+func isDelta(t AggregationTemporality) bool {
+	return t == AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+}
+
+// temporality returns the resource tags of a histogram with t, which are
+// tagged with TemporalityTag when t is delta.
+func temporality(resource map[string]string, t AggregationTemporality) map[string]string {
+	if !isDelta(t) {
+		return resource
+	}
+	tags := make(map[string]string, len(resource)+1)
+	for k, v := range resource {
+		tags[k] = v
+	}
+	tags[TemporalityTag] = "delta"
+	return tags
+}
+
+func (c *converter) number(name, field string, resource map[string]string, dp *NumberDataPoint, now time.Time) {
+	var v interface{}
+	switch value := dp.GetValue().(type) {
+	case *NumberDataPoint_AsDouble:
+		v = value.AsDouble
+	case *NumberDataPoint_AsInt:
+		// Written as a float like the values of scraped metrics, so that a
+		// field never has both types.
+		v = float64(value.AsInt)
+	default:
+		return
+	}
+	c.add(name, attributesToTags(resource, dp.GetAttributes()), models.Fields{field: v}, timestamp(dp.GetTimeUnixNano(), now))
+}
+
+func (c *converter) histogram(name string, resource map[string]string, dp *HistogramDataPoint, now time.Time) {
+	fields := totals(dp.GetCount(), dp.Sum)
+	bounds := dp.GetExplicitBounds()
+
+	var cumulative uint64
+	for i, n := range dp.GetBucketCounts() {
+		cumulative += n
+		le := math.Inf(1)
+		if i < len(bounds) {
+			le = bounds[i]
+		}
+		fields[gather.FormatBound(le)] = float64(cumulative)
+	}
+
+	c.add(name, attributesToTags(resource, dp.GetAttributes()), fields, timestamp(dp.GetTimeUnixNano(), now))
+}
+
+func (c *converter) exponentialHistogram(name string, resource map[string]string, dp *ExponentialHistogramDataPoint, now time.Time) {
+	fields := totals(dp.GetCount(), dp.Sum)
+	base := math.Exp2(math.Exp2(-float64(dp.GetScale())))
+
+	// Negative buckets are emitted from the most negative upwards; the bucket at
+	// index i covers [-base^(i+1), -base^i).
+	var cumulative uint64
+	neg := dp.GetNegative()
+	for i := len(neg.GetBucketCounts()) - 1; i >= 0; i-- {
+		cumulative += neg.GetBucketCounts()[i]
+		le := -math.Pow(base, float64(neg.GetOffset()+int32(i)))
+		fields[gather.FormatBound(le)] = float64(cumulative)
+	}
+
+	if dp.GetZeroCount() > 0 || len(neg.GetBucketCounts()) > 0 {
+		cumulative += dp.GetZeroCount()
+		fields[gather.FormatBound(dp.GetZeroThreshold())] = float64(cumulative)
+	}
+
+	// The positive bucket at index i covers (base^i, base^(i+1)].
+	pos := dp.GetPositive()
+	for i, n := range pos.GetBucketCounts() {
+		cumulative += n
+		le := math.Pow(base, float64(pos.GetOffset()+int32(i)+1))
+		fields[gather.FormatBound(le)] = float64(cumulative)
+	}
+	fields[gather.FormatBound(math.Inf(1))] = float64(dp.GetCount())
+
+	c.add(name, attributesToTags(resource, dp.GetAttributes()), fields, timestamp(dp.GetTimeUnixNano(), now))
+}
+
+func (c *converter) summary(name string, resource map[string]string, dp *SummaryDataPoint, now time.Time) {
+	sum := dp.GetSum()
+	fields := totals(dp.GetCount(), &sum)
+	for _, q := range dp.GetQuantileValues() {
+		fields[gather.FormatBound(q.GetQuantile())] = q.GetValue()
+	}
+
+	c.add(name, attributesToTags(resource, dp.GetAttributes()), fields, timestamp(dp.GetTimeUnixNano(), now))
+}
+
+// totals returns the count and sum fields of a histogram or summary. The min
+// and max of histograms aren't written, since every other field than count
+// and sum is parsed as a bucket upper bound by histogramQuantile queries.
+func totals(count uint64, sum *float64) models.Fields {
+	fields := models.Fields{"count": float64(count)}
+	if sum != nil {
+		fields["sum"] = *sum
+	}
+	return fields
+}
+
+func timestamp(unixNano uint64, now time.Time) time.Time {
+	if unixNano == 0 {
+		return now
+	}
+	return time.Unix(0, int64(unixNano))
+}
+
+// attributesToTags returns a copy of base extended with attrs. Attributes without a
+// value are skipped.
+func attributesToTags(base map[string]string, attrs []*KeyValue) map[string]string {
+	tags := make(map[string]string, len(base)+len(attrs)+1)
+	for k, v := range base {
+		tags[k] = v
+	}
+	for _, kv := range attrs {
+		if v := anyValueString(kv.GetValue()); v != "" {
+			tags[kv.GetKey()] = v
+		}
+	}
+	return tags
+}
+
+func anyValueString(v *AnyValue) string {
+	switch value := v.GetValue().(type) {
+	case *AnyValue_StringValue:
+		return value.StringValue
+	case *AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'g', -1, 64)
+	case *AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	case *AnyValue_ArrayValue, *AnyValue_KvlistValue:
+		b, err := json.Marshal(anyValueInterface(v))
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	default:
+		return ""
+	}
+}
+
+func anyValueInterface(v *AnyValue) interface{} {
+	switch value := v.GetValue().(type) {
+	case *AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(value.ArrayValue.GetValues()))
+		for _, e := range value.ArrayValue.GetValues() {
+			values = append(values, anyValueInterface(e))
+		}
+		return values
+	case *AnyValue_KvlistValue:
+		values := make(map[string]interface{}, len(value.KvlistValue.GetValues()))
+		for _, kv := range value.KvlistValue.GetValues() {
+			values[kv.GetKey()] = anyValueInterface(kv.GetValue())
+		}
+		return values
+	case *AnyValue_StringValue:
+		return value.StringValue
+	case *AnyValue_BoolValue:
+		return value.BoolValue
+	case *AnyValue_IntValue:
+		return value.IntValue
+	case *AnyValue_DoubleValue:
+		return value.DoubleValue
+	case *AnyValue_BytesValue:
+		return value.BytesValue
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,231 @@
+package otlp_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/otlp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const ts = 1600000000000000000
+
+func stringAttr(k, v string) *otlp.KeyValue {
+	return &otlp.KeyValue{Key: k, Value: &otlp.AnyValue{Value: &otlp.AnyValue_StringValue{StringValue: v}}}
+}
+
+func request(metrics ...*otlp.Metric) *otlp.ExportMetricsServiceRequest {
+	return &otlp.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlp.ResourceMetrics{
+			{
+				Resource:     &otlp.Resource{Attributes: []*otlp.KeyValue{stringAttr("service.name", "api")}},
+				ScopeMetrics: []*otlp.ScopeMetrics{{Metrics: metrics}},
+			},
+		},
+	}
+}
+
+func pointStrings(points models.Points) []string {
+	var s []string
+	for _, p := range points {
+		s = append(s, p.String())
+	}
+	return s
+}
+
+func TestMetricsToPoints(t *testing.T) {
+	sum := 12.5
+	min, max := -1.5, 3.0 // not written, see totals
+
+	cases := []struct {
+		name   string
+		metric *otlp.Metric
+		exp    []string
+	}{
+		{
+			name: "gauge",
+			metric: &otlp.Metric{
+				Name: "temperature",
+				Data: &otlp.Metric_Gauge{Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{
+					{Attributes: []*otlp.KeyValue{stringAttr("room", "a")}, TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsDouble{AsDouble: 21.5}},
+					{TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsDouble{AsDouble: math.NaN()}},
+				}}},
+			},
+			exp: []string{
+				"temperature,room=a,service.name=api gauge=21.5 1600000000000000000",
+			},
+		},
+		{
+			name: "monotonic sum",
+			metric: &otlp.Metric{
+				Name: "requests",
+				Data: &otlp.Metric_Sum{Sum: &otlp.Sum{IsMonotonic: true, DataPoints: []*otlp.NumberDataPoint{
+					{TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsInt{AsInt: 42}},
+				}}},
+			},
+			exp: []string{
+				"requests,service.name=api counter=42 1600000000000000000",
+			},
+		},
+		{
+			name: "delta sum",
+			metric: &otlp.Metric{
+				Name: "requests",
+				Data: &otlp.Metric_Sum{Sum: &otlp.Sum{
+					IsMonotonic:            true,
+					AggregationTemporality: otlp.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+					DataPoints: []*otlp.NumberDataPoint{
+						{TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsInt{AsInt: 5}},
+					},
+				}},
+			},
+			exp: []string{
+				"requests,service.name=api delta=5 1600000000000000000",
+			},
+		},
+		{
+			name: "delta histogram",
+			metric: &otlp.Metric{
+				Name: "latency",
+				Data: &otlp.Metric_Histogram{Histogram: &otlp.Histogram{
+					AggregationTemporality: otlp.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+					DataPoints: []*otlp.HistogramDataPoint{
+						{TimeUnixNano: ts, Count: 3, ExplicitBounds: []float64{1}, BucketCounts: []uint64{2, 1}},
+					},
+				}},
+			},
+			exp: []string{
+				"latency,service.name=api,temporality=delta +Inf=3,1=2,count=3 1600000000000000000",
+			},
+		},
+		{
+			name: "non-monotonic sum",
+			metric: &otlp.Metric{
+				Name: "connections",
+				Data: &otlp.Metric_Sum{Sum: &otlp.Sum{DataPoints: []*otlp.NumberDataPoint{
+					{TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsInt{AsInt: 3}},
+				}}},
+			},
+			exp: []string{
+				"connections,service.name=api gauge=3 1600000000000000000",
+			},
+		},
+		{
+			name: "histogram",
+			metric: &otlp.Metric{
+				Name: "latency",
+				Data: &otlp.Metric_Histogram{Histogram: &otlp.Histogram{DataPoints: []*otlp.HistogramDataPoint{
+					{
+						TimeUnixNano:   ts,
+						Count:          6,
+						Sum:            &sum,
+						BucketCounts:   []uint64{3, 2, 1},
+						ExplicitBounds: []float64{0.1, 1},
+					},
+				}}},
+			},
+			exp: []string{
+				"latency,service.name=api +Inf=6,0.1=3,1=5,count=6,sum=12.5 1600000000000000000",
+			},
+		},
+		{
+			name: "exponential histogram",
+			metric: &otlp.Metric{
+				Name: "latency",
+				Data: &otlp.Metric_ExponentialHistogram{ExponentialHistogram: &otlp.ExponentialHistogram{DataPoints: []*otlp.ExponentialHistogramDataPoint{
+					{
+						TimeUnixNano: ts,
+						Count:        5,
+						Sum:          &sum,
+						Min:          &min,
+						Max:          &max,
+						Scale:        0,
+						ZeroCount:    1,
+						Positive:     &otlp.ExponentialHistogramDataPoint_Buckets{Offset: 0, BucketCounts: []uint64{1, 2}},
+						Negative:     &otlp.ExponentialHistogramDataPoint_Buckets{Offset: 0, BucketCounts: []uint64{1}},
+					},
+				}}},
+			},
+			exp: []string{
+				"latency,service.name=api +Inf=5,-1=1,0=2,2=3,4=5,count=5,sum=12.5 1600000000000000000",
+			},
+		},
+		{
+			name: "summary",
+			metric: &otlp.Metric{
+				Name: "rpc_duration",
+				Data: &otlp.Metric_Summary{Summary: &otlp.Summary{DataPoints: []*otlp.SummaryDataPoint{
+					{
+						TimeUnixNano: ts,
+						Count:        10,
+						Sum:          4,
+						QuantileValues: []*otlp.SummaryDataPoint_ValueAtQuantile{
+							{Quantile: 0.5, Value: 0.3},
+							{Quantile: 0.99, Value: 0.9},
+						},
+					},
+				}}},
+			},
+			exp: []string{
+				"rpc_duration,service.name=api 0.5=0.3,0.99=0.9,count=10,sum=4 1600000000000000000",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			points, err := otlp.MetricsToPoints(request(tc.metric), time.Unix(0, 0))
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, pointStrings(points))
+		})
+	}
+}
+
+func TestMetricsToPoints_DefaultTimestamp(t *testing.T) {
+	req := request(&otlp.Metric{
+		Name: "up",
+		Data: &otlp.Metric_Gauge{Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{
+			{Value: &otlp.NumberDataPoint_AsInt{AsInt: 1}},
+		}}},
+	})
+
+	points, err := otlp.MetricsToPoints(req, time.Unix(0, ts))
+	require.NoError(t, err)
+	require.Equal(t, []string{"up,service.name=api gauge=1 1600000000000000000"}, pointStrings(points))
+}
+
+func TestUnmarshalMetricsRequest(t *testing.T) {
+	exp := request(&otlp.Metric{
+		Name: "up",
+		Data: &otlp.Metric_Gauge{Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{
+			{TimeUnixNano: ts, Value: &otlp.NumberDataPoint_AsInt{AsInt: 1}},
+		}}},
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		b, err := proto.Marshal(exp)
+		require.NoError(t, err)
+
+		got, err := otlp.UnmarshalMetricsRequest(b, "application/x-protobuf")
+		require.NoError(t, err)
+		require.True(t, proto.Equal(exp, got))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		const body = `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},` +
+			`"scopeMetrics":[{"metrics":[{"name":"up","gauge":{"dataPoints":[{"timeUnixNano":"1600000000000000000","asInt":"1"}]}}]}]}],` +
+			`"unknownField":true}`
+
+		got, err := otlp.UnmarshalMetricsRequest([]byte(body), "application/json; charset=utf-8")
+		require.NoError(t, err)
+		require.True(t, proto.Equal(exp, got))
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		_, err := otlp.UnmarshalMetricsRequest(nil, "text/plain")
+		require.Equal(t, otlp.ErrUnsupportedContentType, err)
+	})
+}
@@ -0,0 +1,3 @@
+package otlp
+
+//go:generate protoc --go_out=. metrics.proto
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	ierrors "github.com/influxdata/influxdb/v2/kit/errors"
@@ -15,6 +16,7 @@ import (
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/rule"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/pkger/internal/wordplay"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
@@ -52,17 +54,22 @@ var kindPriorities = map[Kind]int{
 	KindLabel:                         1,
 	KindBucket:                        2,
 	KindCheck:                         3,
-	KindCheckDeadman:                  4,
-	KindCheckThreshold:                5,
-	KindNotificationEndpoint:          6,
-	KindNotificationEndpointHTTP:      7,
-	KindNotificationEndpointPagerDuty: 8,
-	KindNotificationEndpointSlack:     9,
-	KindNotificationRule:              10,
-	KindTask:                          11,
-	KindVariable:                      12,
-	KindDashboard:                     13,
-	KindTelegraf:                      14,
+	KindCheckAnomaly:                  4,
+	KindCheckDeadman:                  5,
+	KindCheckThreshold:                6,
+	KindNotificationEndpoint:          7,
+	KindNotificationEndpointHTTP:      8,
+	KindNotificationEndpointOpsgenie:  9,
+	KindNotificationEndpointPagerDuty: 10,
+	KindNotificationEndpointSMTP:      11,
+	KindNotificationEndpointSlack:     12,
+	KindNotificationEndpointTeams:     13,
+	KindNotificationRule:              14,
+	KindSilence:                       15,
+	KindTask:                          16,
+	KindVariable:                      17,
+	KindDashboard:                     18,
+	KindTelegraf:                      19,
 }
 
 type exportKey struct {
@@ -90,6 +97,7 @@ type resourceExporter struct {
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
 	ruleSVC     influxdb.NotificationRuleStore
+	silenceSVC  isilence.SilenceService
 	taskSVC     taskmodel.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
@@ -108,6 +116,7 @@ func newResourceExporter(svc *Service) *resourceExporter {
 		labelSVC:        svc.labelSVC,
 		endpointSVC:     svc.endpointSVC,
 		ruleSVC:         svc.ruleSVC,
+		silenceSVC:      svc.silenceSVC,
 		taskSVC:         svc.taskSVC,
 		teleSVC:         svc.teleSVC,
 		varSVC:          svc.varSVC,
@@ -240,7 +249,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		for _, bkt := range bkts {
 			mapResource(bkt.OrgID, bkt.ID, KindBucket, BucketToObject(r.Name, *bkt))
 		}
-	case r.Kind.is(KindCheck), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold):
+	case r.Kind.is(KindCheck), r.Kind.is(KindCheckAnomaly), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold):
 		filter := influxdb.CheckFilter{}
 		if r.ID != platform.ID(0) {
 			filter.ID = &r.ID
@@ -316,8 +325,11 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		}
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
+		r.Kind.is(KindNotificationEndpointOpsgenie),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
-		r.Kind.is(KindNotificationEndpointSlack):
+		r.Kind.is(KindNotificationEndpointSMTP),
+		r.Kind.is(KindNotificationEndpointSlack),
+		r.Kind.is(KindNotificationEndpointTeams):
 		var endpoints []influxdb.NotificationEndpoint
 
 		switch {
@@ -392,6 +404,33 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 
 			mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, NotificationRuleToObject(r.Name, endpointObjectName, rule))
 		}
+	case r.Kind.is(KindSilence):
+		// silences have no name, they can only be exported by ID.
+		if r.ID == platform.ID(0) {
+			return errors.New("silences can only be exported by ID")
+		}
+		sil, err := ex.silenceSVC.FindSilenceByID(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+
+		var checkObjectName string
+		if sil.CheckID != nil {
+			ch, err := ex.checkSVC.FindCheckByID(ctx, *sil.CheckID)
+			if err != nil {
+				return err
+			}
+
+			checkKey := newExportKey(ch.GetOrgID(), ch.GetID(), KindCheck, ch.GetName())
+			object, ok := ex.mObjects[checkKey]
+			if !ok {
+				mapResource(ch.GetOrgID(), ch.GetID(), KindCheck, CheckToObject("", ch))
+				object = ex.mObjects[checkKey]
+			}
+			checkObjectName = object.Name()
+		}
+
+		mapResource(sil.OrgID, sil.ID, KindSilence, SilenceToObject(r.Name, checkObjectName, *sil))
 	case r.Kind.is(KindTask):
 		switch {
 		case r.ID != platform.ID(0):
@@ -652,6 +691,16 @@ func CheckToObject(name string, ch influxdb.Check) Object {
 	}
 
 	switch cT := ch.(type) {
+	case *icheck.Anomaly:
+		o.Kind = KindCheckAnomaly
+		assignBase(cT.Base)
+		assignNonZeroStrings(o.Spec, map[string]string{fieldCheckMethod: cT.Method})
+		assignNonZeroFluxDurs(o.Spec, map[string]*notification.Duration{
+			fieldCheckLookback:    cT.Lookback,
+			fieldCheckSeasonality: cT.Seasonality,
+		})
+		o.Spec[fieldCheckDeviations] = cT.Deviations
+		o.Spec[fieldLevel] = cT.Level.String()
 	case *icheck.Deadman:
 		o.Kind = KindCheckDeadman
 		assignBase(cT.Base)
@@ -1288,6 +1337,34 @@ func NotificationEndpointToObject(name string, e influxdb.NotificationEndpoint)
 		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
 			fieldNotificationEndpointToken: actual.Token,
 		})
+	case *endpoint.SMTP:
+		o.Kind = KindNotificationEndpointSMTP
+		o.Spec[fieldNotificationEndpointHost] = actual.Host
+		o.Spec[fieldNotificationEndpointFrom] = actual.From
+		if actual.Port != 0 {
+			o.Spec[fieldNotificationEndpointPort] = actual.Port
+		}
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationEndpointRelayURL: actual.RelayURL,
+		})
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointPassword: actual.Password,
+			fieldNotificationEndpointUsername: actual.Username,
+		})
+	case *endpoint.Teams:
+		o.Kind = KindNotificationEndpointTeams
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointURL: actual.URL,
+		})
+	case *endpoint.Opsgenie:
+		o.Kind = KindNotificationEndpointOpsgenie
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationEndpointURL:    actual.URL,
+			fieldNotificationEndpointEntity: actual.Entity,
+		})
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointAPIKey: actual.APIKey,
+		})
 	}
 
 	return o
@@ -1341,6 +1418,13 @@ func NotificationRuleToObject(name, endpointPkgName string, iRule influxdb.Notif
 	switch t := iRule.(type) {
 	case *rule.HTTP:
 		assignBase(t.Base)
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationRuleBodyTemplate:       t.BodyTemplate,
+			fieldNotificationRuleBodyTemplateSyntax: t.BodyTemplateSyntax,
+		})
+		if len(t.Headers) > 0 {
+			o.Spec[fieldNotificationRuleHeaders] = t.Headers
+		}
 	case *rule.PagerDuty:
 		assignBase(t.Base)
 		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
@@ -1348,6 +1432,24 @@ func NotificationRuleToObject(name, endpointPkgName string, iRule influxdb.Notif
 		assignBase(t.Base)
 		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
 		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleChannel: t.Channel})
+	case *rule.SMTP:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		o.Spec[fieldNotificationRuleTo] = t.To
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleSubjectTemplate: t.SubjectTemplate})
+	case *rule.Teams:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleTitleTemplate: t.TitleTemplate})
+	case *rule.Opsgenie:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		if len(t.Responders) > 0 {
+			o.Spec[fieldNotificationRuleResponders] = t.Responders
+		}
+		if len(t.Tags) > 0 {
+			o.Spec[fieldNotificationRuleTags] = t.Tags
+		}
 	}
 
 	return o
@@ -1356,6 +1458,57 @@ func NotificationRuleToObject(name, endpointPkgName string, iRule influxdb.Notif
 // regex used to rip out the hard coded task option stuffs
 var taskFluxRegex = regexp.MustCompile(`option task = {(.|\n)*?}`)
 
+// SilenceToObject converts a silence.Silence to a pkger.Object.
+func SilenceToObject(name, checkMetaName string, sil isilence.Silence) Object {
+	o := newObject(KindSilence, name)
+	if name == "" {
+		delete(o.Spec, fieldName)
+	}
+	assignNonZeroStrings(o.Spec, map[string]string{
+		fieldSilenceCheckName: checkMetaName,
+		fieldSilenceComment:   sil.Comment,
+	})
+
+	if len(sil.Levels) > 0 {
+		levels := make([]string, 0, len(sil.Levels))
+		for _, l := range sil.Levels {
+			levels = append(levels, l.String())
+		}
+		o.Spec[fieldSilenceLevels] = levels
+	}
+
+	if len(sil.Tags) > 0 {
+		tags := make([]Resource, 0, len(sil.Tags))
+		for _, t := range sil.Tags {
+			tags = append(tags, Resource{
+				fieldKey:   t.Key,
+				fieldValue: t.Value,
+			})
+		}
+		o.Spec[fieldSilenceTags] = tags
+	}
+
+	if sil.StartTime != nil {
+		o.Spec[fieldSilenceStartTime] = sil.StartTime.UTC().Format(time.RFC3339)
+	}
+	if sil.EndTime != nil {
+		o.Spec[fieldSilenceEndTime] = sil.EndTime.UTC().Format(time.RFC3339)
+	}
+
+	if w := sil.Window; w != nil {
+		window := Resource{
+			fieldSilenceStart:    w.Start,
+			fieldSilenceDuration: w.Duration,
+		}
+		if len(w.Days) > 0 {
+			window[fieldSilenceDays] = w.Days
+		}
+		o.Spec[fieldSilenceWindow] = window
+	}
+
+	return o
+}
+
 // TaskToObject converts an influxdb.Task into a pkger.Object.
 func TaskToObject(name string, t taskmodel.Task) Object {
 	if name == "" {
@@ -1366,11 +1519,12 @@ func TaskToObject(name string, t taskmodel.Task) Object {
 
 	o := newObject(KindTask, name)
 	assignNonZeroStrings(o.Spec, map[string]string{
-		fieldTaskCron:    t.Cron,
-		fieldDescription: t.Description,
-		fieldEvery:       t.Every,
-		fieldOffset:      durToStr(t.Offset),
-		fieldQuery:       strings.TrimSpace(query),
+		fieldTaskCron:     t.Cron,
+		fieldTaskLocation: t.Location,
+		fieldDescription:  t.Description,
+		fieldEvery:        t.Every,
+		fieldOffset:       durToStr(t.Offset),
+		fieldQuery:        strings.TrimSpace(query),
 	})
 	return o
 }
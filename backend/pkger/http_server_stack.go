@@ -417,7 +417,7 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 	switch r.Kind {
 	case KindBucket:
 		linkResource = "buckets"
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		linkResource = "checks"
 	case KindDashboard:
 		linkResource = "dashboards"
@@ -425,11 +425,16 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 		linkResource = "labels"
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		linkResource = "notificationEndpoints"
 	case KindNotificationRule:
 		linkResource = "notificationRules"
+	case KindSilence:
+		linkResource = "silences"
 	case KindTask:
 		linkResource = "tasks"
 	case KindTelegraf:
@@ -497,6 +497,9 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Diff.NotificationRules == nil {
 		out.Diff.NotificationRules = []DiffNotificationRule{}
 	}
+	if out.Diff.Silences == nil {
+		out.Diff.Silences = []DiffSilence{}
+	}
 	if out.Diff.Tasks == nil {
 		out.Diff.Tasks = []DiffTask{}
 	}
@@ -531,6 +534,9 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Summary.NotificationRules == nil {
 		out.Summary.NotificationRules = []SummaryNotificationRule{}
 	}
+	if out.Summary.Silences == nil {
+		out.Summary.Silences = []SummarySilence{}
+	}
 	if out.Summary.Tasks == nil {
 		out.Summary.Tasks = []SummaryTask{}
 	}
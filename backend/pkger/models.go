@@ -11,6 +11,7 @@ import (
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 )
 
 // Package kind types.
@@ -18,16 +19,21 @@ const (
 	KindUnknown                       Kind = ""
 	KindBucket                        Kind = "Bucket"
 	KindCheck                         Kind = "Check"
+	KindCheckAnomaly                  Kind = "CheckAnomaly"
 	KindCheckDeadman                  Kind = "CheckDeadman"
 	KindCheckThreshold                Kind = "CheckThreshold"
 	KindDashboard                     Kind = "Dashboard"
 	KindLabel                         Kind = "Label"
 	KindNotificationEndpoint          Kind = "NotificationEndpoint"
 	KindNotificationEndpointHTTP      Kind = "NotificationEndpointHTTP"
+	KindNotificationEndpointOpsgenie  Kind = "NotificationEndpointOpsgenie"
 	KindNotificationEndpointPagerDuty Kind = "NotificationEndpointPagerDuty"
+	KindNotificationEndpointSMTP      Kind = "NotificationEndpointSMTP"
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
+	KindNotificationEndpointTeams     Kind = "NotificationEndpointTeams"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
+	KindSilence                       Kind = "Silence"
 	KindTask                          Kind = "Task"
 	KindTelegraf                      Kind = "Telegraf"
 	KindVariable                      Kind = "Variable"
@@ -45,15 +51,20 @@ func Kinds() []Kind {
 var kinds = map[Kind]bool{
 	KindBucket:                        true,
 	KindCheck:                         true,
+	KindCheckAnomaly:                  true,
 	KindCheckDeadman:                  true,
 	KindCheckThreshold:                true,
 	KindDashboard:                     true,
 	KindLabel:                         true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
+	KindNotificationEndpointOpsgenie:  true,
 	KindNotificationEndpointPagerDuty: true,
+	KindNotificationEndpointSMTP:      true,
 	KindNotificationEndpointSlack:     true,
+	KindNotificationEndpointTeams:     true,
 	KindNotificationRule:              true,
+	KindSilence:                       true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
 	KindVariable:                      true,
@@ -89,7 +100,7 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	switch k {
 	case KindBucket:
 		return influxdb.BucketsResourceType
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		return influxdb.ChecksResourceType
 	case KindDashboard:
 		return influxdb.DashboardsResourceType
@@ -97,10 +108,14 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		return influxdb.LabelsResourceType
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		return influxdb.NotificationEndpointResourceType
-	case KindNotificationRule:
+	case KindNotificationRule, KindSilence:
+		// silences are authorized as part of the notification rules they mute.
 		return influxdb.NotificationRuleResourceType
 	case KindTask:
 		return influxdb.TasksResourceType
@@ -162,6 +177,7 @@ type Diff struct {
 	LabelMappings         []DiffLabelMapping         `json:"labelMappings"`
 	NotificationEndpoints []DiffNotificationEndpoint `json:"notificationEndpoints"`
 	NotificationRules     []DiffNotificationRule     `json:"notificationRules"`
+	Silences              []DiffSilence              `json:"silences"`
 	Tasks                 []DiffTask                 `json:"tasks"`
 	Telegrafs             []DiffTelegraf             `json:"telegrafConfigs"`
 	Variables             []DiffVariable             `json:"variables"`
@@ -385,6 +401,14 @@ type (
 	}
 )
 
+// DiffSilence is a diff of an individual silence.
+type DiffSilence struct {
+	DiffIdentifier
+
+	New isilence.Silence  `json:"new"`
+	Old *isilence.Silence `json:"old"`
+}
+
 type (
 	// DiffTask is a diff of an individual task.
 	DiffTask struct {
@@ -400,6 +424,7 @@ type (
 		Cron        string          `json:"cron"`
 		Description string          `json:"description"`
 		Every       string          `json:"every"`
+		Location    string          `json:"location,omitempty"`
 		Offset      string          `json:"offset"`
 		Query       string          `json:"query"`
 		Status      influxdb.Status `json:"status"`
@@ -447,6 +472,7 @@ type Summary struct {
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
 	MissingEnvs           []string                      `json:"missingEnvRefs"`
 	MissingSecrets        []string                      `json:"missingSecrets"`
+	Silences              []SummarySilence              `json:"silences"`
 	Tasks                 []SummaryTask                 `json:"summaryTask"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
 	Variables             []SummaryVariable             `json:"variables"`
@@ -673,6 +699,13 @@ type SummaryReference struct {
 	DefaultValue interface{} `json:"defaultValue"`
 }
 
+// SummarySilence provides a summary of a silence.
+type SummarySilence struct {
+	SummaryIdentifier
+	CheckMetaName string           `json:"checkTemplateMetaName,omitempty"`
+	Silence       isilence.Silence `json:"silence"`
+}
+
 // SummaryTask provides a summary of a task.
 type SummaryTask struct {
 	SummaryIdentifier
@@ -681,6 +714,7 @@ type SummaryTask struct {
 	Cron        string          `json:"cron"`
 	Description string          `json:"description"`
 	Every       string          `json:"every"`
+	Location    string          `json:"location,omitempty"`
 	Offset      string          `json:"offset"`
 	Query       string          `json:"query"`
 	Status      influxdb.Status `json:"status"`
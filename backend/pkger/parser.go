@@ -22,7 +22,9 @@ import (
 	"github.com/influxdata/flux/ast/edit"
 	fluxurl "github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 	caperr "github.com/influxdata/influxdb/v2/pkg/errors"
 	"github.com/influxdata/influxdb/v2/pkg/fs"
 	"github.com/influxdata/influxdb/v2/pkg/jsonnet"
@@ -454,6 +456,7 @@ type Template struct {
 	mDashboards            map[string]*dashboard
 	mNotificationEndpoints map[string]*notificationEndpoint
 	mNotificationRules     map[string]*notificationRule
+	mSilences              map[string]*silence
 	mTasks                 map[string]*task
 	mTelegrafs             map[string]*telegraf
 	mVariables             map[string]*variable
@@ -517,6 +520,7 @@ func (p *Template) Summary() Summary {
 		Labels:                []SummaryLabel{},
 		MissingEnvs:           p.missingEnvRefs(),
 		MissingSecrets:        p.missingSecrets(),
+		Silences:              []SummarySilence{},
 		Tasks:                 []SummaryTask{},
 		TelegrafConfigs:       []SummaryTelegraf{},
 		Variables:             []SummaryVariable{},
@@ -548,6 +552,10 @@ func (p *Template) Summary() Summary {
 		sum.NotificationRules = append(sum.NotificationRules, r.summarize())
 	}
 
+	for _, s := range p.silences() {
+		sum.Silences = append(sum.Silences, s.summarize())
+	}
+
 	for _, t := range p.tasks() {
 		sum.Tasks = append(sum.Tasks, t.summarize())
 	}
@@ -592,7 +600,7 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 	case KindBucket:
 		_, ok := p.mBuckets[pkgName]
 		return ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		_, ok := p.mChecks[pkgName]
 		return ok
 	case KindLabel:
@@ -600,13 +608,19 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 		return ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		_, ok := p.mNotificationEndpoints[pkgName]
 		return ok
 	case KindNotificationRule:
 		_, ok := p.mNotificationRules[pkgName]
 		return ok
+	case KindSilence:
+		_, ok := p.mSilences[pkgName]
+		return ok
 	case KindTask:
 		_, ok := p.mTasks[pkgName]
 		return ok
@@ -814,6 +828,17 @@ func (p *Template) telegrafs() []*telegraf {
 	return teles
 }
 
+func (p *Template) silences() []*silence {
+	silences := make([]*silence, 0, len(p.mSilences))
+	for _, s := range p.mSilences {
+		silences = append(silences, s)
+	}
+
+	sort.Slice(silences, func(i, j int) bool { return silences[i].MetaName() < silences[j].MetaName() })
+
+	return silences
+}
+
 func (p *Template) variables() []*variable {
 	vars := make([]*variable, 0, len(p.mVariables))
 	for _, v := range p.mVariables {
@@ -887,6 +912,7 @@ func (p *Template) graphResources() error {
 		p.graphDashboards,
 		p.graphNotificationEndpoints,
 		p.graphNotificationRules,
+		p.graphSilences,
 		p.graphTasks,
 		p.graphTelegrafs,
 	}
@@ -995,6 +1021,7 @@ func (p *Template) graphChecks() *parseErr {
 	}{
 		{kind: KindCheckThreshold, checkKind: checkKindThreshold},
 		{kind: KindCheckDeadman, checkKind: checkKindDeadman},
+		{kind: KindCheckAnomaly, checkKind: checkKindAnomaly},
 	}
 	var pErr parseErr
 	for _, checkKind := range checkKinds {
@@ -1008,11 +1035,15 @@ func (p *Template) graphChecks() *parseErr {
 				kind:          checkKind.checkKind,
 				identity:      ident,
 				description:   o.Spec.stringShort(fieldDescription),
+				deviations:    o.Spec.float64Short(fieldCheckDeviations),
 				every:         o.Spec.durationShort(fieldEvery),
 				level:         o.Spec.stringShort(fieldLevel),
+				lookback:      o.Spec.durationShort(fieldCheckLookback),
+				method:        normStr(o.Spec.stringShort(fieldCheckMethod)),
 				offset:        o.Spec.durationShort(fieldOffset),
 				query:         strings.TrimSpace(o.Spec.stringShort(fieldQuery)),
 				reportZero:    o.Spec.boolShort(fieldCheckReportZero),
+				seasonality:   o.Spec.durationShort(fieldCheckSeasonality),
 				staleTime:     o.Spec.durationShort(fieldCheckStaleTime),
 				status:        normStr(o.Spec.stringShort(fieldStatus)),
 				statusMessage: o.Spec.stringShort(fieldCheckStatusMessageTemplate),
@@ -1119,6 +1150,18 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointSlack,
 			notificationKind: notificationKindSlack,
 		},
+		{
+			kind:             KindNotificationEndpointSMTP,
+			notificationKind: notificationKindSMTP,
+		},
+		{
+			kind:             KindNotificationEndpointTeams,
+			notificationKind: notificationKindTeams,
+		},
+		{
+			kind:             KindNotificationEndpointOpsgenie,
+			notificationKind: notificationKindOpsgenie,
+		},
 	}
 
 	var pErr parseErr
@@ -1132,14 +1175,21 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			endpoint := &notificationEndpoint{
 				kind:        nk.notificationKind,
 				identity:    ident,
+				apiKey:      o.Spec.references(fieldNotificationEndpointAPIKey),
 				description: o.Spec.stringShort(fieldDescription),
+				entity:      o.Spec.stringShort(fieldNotificationEndpointEntity),
+				from:        o.Spec.stringShort(fieldNotificationEndpointFrom),
+				host:        o.Spec.stringShort(fieldNotificationEndpointHost),
 				method:      strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationEndpointHTTPMethod))),
 				httpType:    normStr(o.Spec.stringShort(fieldType)),
 				password:    o.Spec.references(fieldNotificationEndpointPassword),
+				port:        o.Spec.intShort(fieldNotificationEndpointPort),
+				relayURL:    o.Spec.stringShort(fieldNotificationEndpointRelayURL),
 				routingKey:  o.Spec.references(fieldNotificationEndpointRoutingKey),
 				status:      normStr(o.Spec.stringShort(fieldStatus)),
 				token:       o.Spec.references(fieldNotificationEndpointToken),
 				url:         o.Spec.stringShort(fieldNotificationEndpointURL),
+				urlSecret:   o.Spec.references(fieldNotificationEndpointURL),
 				username:    o.Spec.references(fieldNotificationEndpointUsername),
 			}
 			failures := p.parseNestedLabels(o.Spec, func(l *label) error {
@@ -1152,9 +1202,11 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			p.setRefs(
 				endpoint.name,
 				endpoint.displayName,
+				endpoint.apiKey,
 				endpoint.password,
 				endpoint.routingKey,
 				endpoint.token,
+				endpoint.urlSecret,
 				endpoint.username,
 			)
 
@@ -1184,11 +1236,19 @@ func (p *Template) graphNotificationRules() *parseErr {
 			identity:     ident,
 			endpointName: p.getRefWithKnownEnvs(o.Spec, fieldNotificationRuleEndpointName),
 			description:  o.Spec.stringShort(fieldDescription),
+			bodySyntax:   o.Spec.stringShort(fieldNotificationRuleBodyTemplateSyntax),
+			bodyTmpl:     o.Spec.stringShort(fieldNotificationRuleBodyTemplate),
 			channel:      o.Spec.stringShort(fieldNotificationRuleChannel),
 			every:        o.Spec.durationShort(fieldEvery),
+			headers:      o.Spec.mapStrStr(fieldNotificationRuleHeaders),
 			msgTemplate:  o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
 			offset:       o.Spec.durationShort(fieldOffset),
+			responders:   o.Spec.slcStr(fieldNotificationRuleResponders),
 			status:       normStr(o.Spec.stringShort(fieldStatus)),
+			subject:      o.Spec.stringShort(fieldNotificationRuleSubjectTemplate),
+			tags:         o.Spec.slcStr(fieldNotificationRuleTags),
+			title:        o.Spec.stringShort(fieldNotificationRuleTitleTemplate),
+			to:           o.Spec.slcStr(fieldNotificationRuleTo),
 		}
 
 		for _, sRule := range o.Spec.slcResource(fieldNotificationRuleStatusRules) {
@@ -1221,6 +1281,68 @@ func (p *Template) graphNotificationRules() *parseErr {
 	})
 }
 
+func (p *Template) graphSilences() *parseErr {
+	p.mSilences = make(map[string]*silence)
+	tracker := p.trackNames(false)
+	return p.eachResource(KindSilence, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		s := &silence{
+			identity:  ident,
+			checkName: p.getRefWithKnownEnvs(o.Spec, fieldSilenceCheckName),
+			comment:   o.Spec.stringShort(fieldSilenceComment),
+		}
+		for _, lvl := range o.Spec.slcStr(fieldSilenceLevels) {
+			s.levels = append(s.levels, strings.TrimSpace(strings.ToUpper(lvl)))
+		}
+		for _, t := range o.Spec.slcResource(fieldSilenceTags) {
+			s.tags = append(s.tags, influxdb.Tag{
+				Key:   t.stringShort(fieldKey),
+				Value: t.stringShort(fieldValue),
+			})
+		}
+		if w, ok := ifaceToResource(o.Spec[fieldSilenceWindow]); ok {
+			s.window = &isilence.Window{
+				Start:    w.stringShort(fieldSilenceStart),
+				Duration: w.stringShort(fieldSilenceDuration),
+			}
+			for _, d := range w.slcStr(fieldSilenceDays) {
+				s.window.Days = append(s.window.Days, normStr(d))
+			}
+		}
+
+		var timeErrs []validationErr
+		for _, t := range []struct {
+			field string
+			dst   **time.Time
+		}{
+			{field: fieldSilenceStartTime, dst: &s.startTime},
+			{field: fieldSilenceEndTime, dst: &s.endTime},
+		} {
+			v, err := o.Spec.time(t.field)
+			if err != nil {
+				timeErrs = append(timeErrs, validationErr{
+					Field: t.field,
+					Msg:   err.Error(),
+				})
+				continue
+			}
+			*t.dst = v
+		}
+
+		if name := s.CheckMetaName(); name != "" {
+			s.associatedCheck = p.mChecks[name]
+		}
+
+		p.mSilences[s.MetaName()] = s
+		p.setRefs(s.name, s.displayName, s.checkName)
+		return s.valid(timeErrs...)
+	})
+}
+
 func (p *Template) graphTasks() *parseErr {
 	p.mTasks = make(map[string]*task)
 	tracker := p.trackNames(false)
@@ -1235,6 +1357,7 @@ func (p *Template) graphTasks() *parseErr {
 			cron:        o.Spec.stringShort(fieldTaskCron),
 			description: o.Spec.stringShort(fieldDescription),
 			every:       o.Spec.durationShort(fieldEvery),
+			location:    o.Spec.stringShort(fieldTaskLocation),
 			offset:      o.Spec.durationShort(fieldOffset),
 			status:      normStr(o.Spec.stringShort(fieldStatus)),
 		}
@@ -2049,6 +2172,26 @@ func (r Resource) durationShort(key string) time.Duration {
 	return dur
 }
 
+// time returns the RFC3339 timestamp of key, or nil when key is not set.
+func (r Resource) time(key string) (*time.Time, error) {
+	switch v := r[key].(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t := v.UTC()
+		return &t, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC3339 timestamp; got=%q", v)
+		}
+		t = t.UTC()
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("must be an RFC3339 timestamp; got=%v", v)
+	}
+}
+
 func (r Resource) float64(key string) (float64, bool) {
 	f, ok := r[key].(float64)
 	if ok {
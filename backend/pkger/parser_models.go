@@ -2,6 +2,8 @@ package pkger
 
 import (
 	"fmt"
+	"math"
+	"net/mail"
 	"net/url"
 	"regexp"
 	"sort"
@@ -14,10 +16,13 @@ import (
 	"github.com/influxdata/flux/ast/edit"
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb/v2"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/rule"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
+	"github.com/influxdata/influxdb/v2/task/options"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -201,11 +206,16 @@ type checkKind int
 const (
 	checkKindDeadman checkKind = iota + 1
 	checkKindThreshold
+	checkKindAnomaly
 )
 
 const (
 	fieldCheckAllValues             = "allValues"
+	fieldCheckDeviations            = "deviations"
+	fieldCheckLookback              = "lookback"
+	fieldCheckMethod                = "method"
 	fieldCheckReportZero            = "reportZero"
+	fieldCheckSeasonality           = "seasonality"
 	fieldCheckStaleTime             = "staleTime"
 	fieldCheckStatusMessageTemplate = "statusMessageTemplate"
 	fieldCheckTags                  = "tags"
@@ -220,11 +230,15 @@ type check struct {
 
 	kind          checkKind
 	description   string
+	deviations    float64
 	every         time.Duration
 	level         string
+	lookback      time.Duration
+	method        string
 	offset        time.Duration
 	query         string
 	reportZero    bool
+	seasonality   time.Duration
 	staleTime     time.Duration
 	status        string
 	statusMessage string
@@ -288,6 +302,16 @@ func (c *check) summarize() SummaryCheck {
 			StaleTime:  toNotificationDuration(c.staleTime),
 			TimeSince:  toNotificationDuration(c.timeSince),
 		}
+	case checkKindAnomaly:
+		sum.Kind = KindCheckAnomaly
+		sum.Check = &icheck.Anomaly{
+			Base:        base,
+			Method:      c.method,
+			Lookback:    toNotificationDuration(c.lookback),
+			Seasonality: toNotificationDuration(c.seasonality),
+			Deviations:  c.deviations,
+			Level:       notification.ParseCheckLevel(strings.ToUpper(c.level)),
+		}
 	}
 	return sum
 }
@@ -336,6 +360,33 @@ func (c *check) valid() []validationErr {
 				vErrs = append(vErrs, fail)
 			}
 		}
+	case checkKindAnomaly:
+		switch c.method {
+		case "", icheck.AnomalyMethodStdDev, icheck.AnomalyMethodMAD:
+		default:
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckMethod,
+				Msg:   fmt.Sprintf("must be 1 in [stddev, mad]; got=%q", c.method),
+			})
+		}
+		if c.lookback <= c.every {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckLookback,
+				Msg:   "duration value must be provided that is > every",
+			})
+		}
+		if c.deviations <= 0 {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckDeviations,
+				Msg:   "must be > 0",
+			})
+		}
+		if notification.ParseCheckLevel(strings.ToUpper(c.level)) == notification.Unknown {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldLevel,
+				Msg:   fmt.Sprintf("must be 1 in [CRIT, WARN, INFO, OK]; got=%q", c.level),
+			})
+		}
 	}
 
 	if len(vErrs) > 0 {
@@ -1600,14 +1651,20 @@ const (
 	notificationKindHTTP notificationEndpointKind = iota + 1
 	notificationKindPagerDuty
 	notificationKindSlack
+	notificationKindSMTP
+	notificationKindTeams
+	notificationKindOpsgenie
 )
 
 func (n notificationEndpointKind) String() string {
-	if n > 0 && n < 4 {
+	if n > 0 && n < 7 {
 		return [...]string{
 			endpoint.HTTPType,
 			endpoint.PagerDutyType,
 			endpoint.SlackType,
+			endpoint.SMTPType,
+			endpoint.TeamsType,
+			endpoint.OpsgenieType,
 		}[n-1]
 	}
 	return ""
@@ -1620,8 +1677,14 @@ const (
 )
 
 const (
+	fieldNotificationEndpointAPIKey     = "apiKey"
+	fieldNotificationEndpointEntity     = "entity"
+	fieldNotificationEndpointFrom       = "from"
+	fieldNotificationEndpointHost       = "host"
 	fieldNotificationEndpointHTTPMethod = "method"
 	fieldNotificationEndpointPassword   = "password"
+	fieldNotificationEndpointPort       = "port"
+	fieldNotificationEndpointRelayURL   = "relayURL"
 	fieldNotificationEndpointRoutingKey = "routingKey"
 	fieldNotificationEndpointToken      = "token"
 	fieldNotificationEndpointURL        = "url"
@@ -1632,14 +1695,21 @@ type notificationEndpoint struct {
 	identity
 
 	kind        notificationEndpointKind
+	apiKey      *references
 	description string
+	entity      string
+	from        string
+	host        string
 	method      string
 	password    *references
+	port        int
+	relayURL    string
 	routingKey  *references
 	status      string
 	token       *references
 	httpType    string
 	url         string
+	urlSecret   *references // the url of teams endpoints is a secret
 	username    *references
 
 	labels sortedLabels
@@ -1705,6 +1775,31 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 			URL:   n.url,
 			Token: n.token.SecretField(),
 		}
+	case notificationKindSMTP:
+		sum.Kind = KindNotificationEndpointSMTP
+		sum.NotificationEndpoint = &endpoint.SMTP{
+			Base:     base,
+			Host:     n.host,
+			Port:     n.port,
+			From:     n.from,
+			Username: n.username.SecretField(),
+			Password: n.password.SecretField(),
+			RelayURL: n.relayURL,
+		}
+	case notificationKindTeams:
+		sum.Kind = KindNotificationEndpointTeams
+		sum.NotificationEndpoint = &endpoint.Teams{
+			Base: base,
+			URL:  n.urlSecret.SecretField(),
+		}
+	case notificationKindOpsgenie:
+		sum.Kind = KindNotificationEndpointOpsgenie
+		sum.NotificationEndpoint = &endpoint.Opsgenie{
+			Base:   base,
+			URL:    n.url,
+			APIKey: n.apiKey.SecretField(),
+			Entity: n.entity,
+		}
 	}
 	return sum
 }
@@ -1733,11 +1828,23 @@ func (n *notificationEndpoint) valid() []validationErr {
 		failures = append(failures, err)
 	}
 
-	if _, err := url.Parse(n.url); err != nil || n.url == "" {
-		failures = append(failures, validationErr{
-			Field: fieldNotificationEndpointURL,
-			Msg:   "must be valid url",
-		})
+	switch n.kind {
+	case notificationKindSMTP, notificationKindTeams:
+		// smtp endpoints have no url and the url of teams endpoints is a secret
+	case notificationKindOpsgenie:
+		if _, err := url.Parse(n.url); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointURL,
+				Msg:   "must be valid url",
+			})
+		}
+	default:
+		if _, err := url.Parse(n.url); err != nil || n.url == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointURL,
+				Msg:   "must be valid url",
+			})
+		}
 	}
 
 	status := influxdb.Status(n.status)
@@ -1749,6 +1856,47 @@ func (n *notificationEndpoint) valid() []validationErr {
 	}
 
 	switch n.kind {
+	case notificationKindSMTP:
+		if n.host == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointHost,
+				Msg:   "must provide non empty string",
+			})
+		}
+		if _, err := mail.ParseAddress(n.from); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointFrom,
+				Msg:   "must be valid email address",
+			})
+		}
+		if n.port < 0 || n.port > math.MaxUint16 {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointPort,
+				Msg:   "must be valid port",
+			})
+		}
+		if n.relayURL != "" {
+			if _, err := url.Parse(n.relayURL); err != nil {
+				failures = append(failures, validationErr{
+					Field: fieldNotificationEndpointRelayURL,
+					Msg:   "must be valid url",
+				})
+			}
+		}
+	case notificationKindTeams:
+		if !n.urlSecret.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointURL,
+				Msg:   "must provide non empty string",
+			})
+		}
+	case notificationKindOpsgenie:
+		if !n.apiKey.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointAPIKey,
+				Msg:   "must provide non empty string",
+			})
+		}
 	case notificationKindPagerDuty:
 		if !n.routingKey.hasValue() {
 			failures = append(failures, validationErr{
@@ -1810,26 +1958,42 @@ func (n *notificationEndpoint) valid() []validationErr {
 }
 
 const (
-	fieldNotificationRuleChannel         = "channel"
-	fieldNotificationRuleCurrentLevel    = "currentLevel"
-	fieldNotificationRuleEndpointName    = "endpointName"
-	fieldNotificationRuleMessageTemplate = "messageTemplate"
-	fieldNotificationRulePreviousLevel   = "previousLevel"
-	fieldNotificationRuleStatusRules     = "statusRules"
-	fieldNotificationRuleTagRules        = "tagRules"
+	fieldNotificationRuleBodyTemplate       = "bodyTemplate"
+	fieldNotificationRuleBodyTemplateSyntax = "bodyTemplateSyntax"
+	fieldNotificationRuleChannel            = "channel"
+	fieldNotificationRuleCurrentLevel       = "currentLevel"
+	fieldNotificationRuleEndpointName       = "endpointName"
+	fieldNotificationRuleHeaders            = "headers"
+	fieldNotificationRuleMessageTemplate    = "messageTemplate"
+	fieldNotificationRulePreviousLevel      = "previousLevel"
+	fieldNotificationRuleResponders         = "responders"
+	fieldNotificationRuleStatusRules        = "statusRules"
+	fieldNotificationRuleSubjectTemplate    = "subjectTemplate"
+	fieldNotificationRuleTagRules           = "tagRules"
+	fieldNotificationRuleTags               = "tags"
+	fieldNotificationRuleTitleTemplate      = "titleTemplate"
+	fieldNotificationRuleTo                 = "to"
 )
 
 type notificationRule struct {
 	identity
 
+	bodyTmpl    string
+	bodySyntax  string
 	channel     string
 	description string
 	every       time.Duration
+	headers     map[string]string
 	msgTemplate string
 	offset      time.Duration
+	responders  []string
 	status      string
 	statusRules []struct{ curLvl, prevLvl string }
+	subject     string
 	tagRules    []struct{ k, v, op string }
+	tags        []string
+	title       string
+	to          []string
 
 	associatedEndpoint *notificationEndpoint
 	endpointName       *references
@@ -1921,7 +2085,12 @@ func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
 
 	switch r.associatedEndpoint.kind {
 	case notificationKindHTTP:
-		return &rule.HTTP{Base: base}
+		return &rule.HTTP{
+			Base:               base,
+			BodyTemplate:       r.bodyTmpl,
+			BodyTemplateSyntax: r.bodySyntax,
+			Headers:            r.headers,
+		}
 	case notificationKindPagerDuty:
 		return &rule.PagerDuty{
 			Base:            base,
@@ -1933,6 +2102,26 @@ func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
 			Channel:         r.channel,
 			MessageTemplate: r.msgTemplate,
 		}
+	case notificationKindSMTP:
+		return &rule.SMTP{
+			Base:            base,
+			To:              r.to,
+			SubjectTemplate: r.subject,
+			MessageTemplate: r.msgTemplate,
+		}
+	case notificationKindTeams:
+		return &rule.Teams{
+			Base:            base,
+			TitleTemplate:   r.title,
+			MessageTemplate: r.msgTemplate,
+		}
+	case notificationKindOpsgenie:
+		return &rule.Opsgenie{
+			Base:            base,
+			MessageTemplate: r.msgTemplate,
+			Responders:      r.responders,
+			Tags:            r.tags,
+		}
 	}
 	return nil
 }
@@ -2065,8 +2254,138 @@ func toSummaryTagRules(tagRules []struct{ k, v, op string }) []SummaryTagRule {
 }
 
 const (
-	fieldTaskCron = "cron"
-	fieldTask     = "task"
+	fieldSilenceCheckName = "checkName"
+	fieldSilenceComment   = "comment"
+	fieldSilenceDays      = "days"
+	fieldSilenceDuration  = "duration"
+	fieldSilenceEndTime   = "endTime"
+	fieldSilenceLevels    = "levels"
+	fieldSilenceStart     = "start"
+	fieldSilenceStartTime = "startTime"
+	fieldSilenceTags      = "tags"
+	fieldSilenceWindow    = "window"
+)
+
+type silence struct {
+	identity
+
+	checkName *references
+	comment   string
+	levels    []string
+	tags      []influxdb.Tag
+	startTime *time.Time
+	endTime   *time.Time
+	window    *isilence.Window
+
+	associatedCheck *check
+}
+
+// CheckMetaName returns the metadata name of the check the silence is
+// restricted to, if any.
+func (s *silence) CheckMetaName() string {
+	return s.checkName.String()
+}
+
+func (s *silence) summarize() SummarySilence {
+	return SummarySilence{
+		SummaryIdentifier: SummaryIdentifier{
+			Kind:          KindSilence,
+			MetaName:      s.MetaName(),
+			EnvReferences: s.summarizeReferences(),
+		},
+		CheckMetaName: s.CheckMetaName(),
+		Silence:       s.toInfluxSilence(),
+	}
+}
+
+// toInfluxSilence returns the silence without the organization and check,
+// which are only known when the silence is applied.
+func (s *silence) toInfluxSilence() isilence.Silence {
+	sil := isilence.Silence{
+		Tags:      s.tags,
+		StartTime: s.startTime,
+		EndTime:   s.endTime,
+		Window:    s.window,
+		Comment:   s.comment,
+	}
+	for _, l := range s.levels {
+		sil.Levels = append(sil.Levels, notification.ParseCheckLevel(l))
+	}
+	return sil
+}
+
+// valid validates the silence, along with the errors of parsing its times.
+func (s *silence) valid(timeErrs ...validationErr) []validationErr {
+	vErrs := timeErrs
+	if s.CheckMetaName() != "" && s.associatedCheck == nil {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldSilenceCheckName,
+			Msg:   fmt.Sprintf("check %q does not exist in pkg", s.CheckMetaName()),
+		})
+	}
+
+	var tagErrs []validationErr
+	for i, t := range s.tags {
+		if t.Key == "" {
+			tagErrs = append(tagErrs, validationErr{
+				Field: fieldKey,
+				Msg:   "must be provided",
+				Index: intPtr(i),
+			})
+		}
+	}
+	if len(tagErrs) > 0 {
+		vErrs = append(vErrs, validationErr{
+			Field:  fieldSilenceTags,
+			Nested: tagErrs,
+		})
+	}
+
+	for i, l := range s.levels {
+		lvl := notification.ParseCheckLevel(l)
+		if lvl == notification.Any || (lvl == notification.Unknown && l != notification.Unknown.String()) {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldSilenceLevels,
+				Msg:   fmt.Sprintf("must be 1 in [CRIT, WARN, INFO, OK, UNKNOWN]; got=%q", l),
+				Index: intPtr(i),
+			})
+		}
+	}
+
+	if s.endTime == nil && s.window == nil && len(timeErrs) == 0 {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldSilenceEndTime,
+			Msg:   "must provide an endTime or a window",
+		})
+	}
+	if s.startTime != nil && s.endTime != nil && !s.startTime.Before(*s.endTime) {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldSilenceStartTime,
+			Msg:   "must be before endTime",
+		})
+	}
+	if s.window != nil {
+		if err := s.window.Valid(); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldSilenceWindow,
+				Msg:   errors2.ErrorMessage(err),
+			})
+		}
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
+const (
+	fieldTaskCron     = "cron"
+	fieldTaskLocation = "location"
+	fieldTask         = "task"
 )
 
 type task struct {
@@ -2075,6 +2394,7 @@ type task struct {
 	cron        string
 	description string
 	every       time.Duration
+	location    string
 	offset      time.Duration
 	query       query
 	status      string
@@ -2101,6 +2421,7 @@ func (t *task) flux() string {
 	translator := taskFluxTranslation{
 		name:     t.Name(),
 		cron:     t.cron,
+		location: t.location,
 		every:    t.every,
 		offset:   t.offset,
 		rawQuery: t.query.DashboardQuery(),
@@ -2138,6 +2459,7 @@ func (t *task) summarize() SummaryTask {
 		Cron:        t.cron,
 		Description: t.description,
 		Every:       durToStr(t.every),
+		Location:    t.location,
 		Offset:      durToStr(t.offset),
 		Query:       t.query.DashboardQuery(),
 		Status:      t.Status(),
@@ -2165,6 +2487,20 @@ func (t *task) valid() []validationErr {
 		)
 	}
 
+	if t.location != "" {
+		if t.cron == "" {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldTaskLocation,
+				Msg:   "must only be provided with the cron field",
+			})
+		} else if _, err := options.LoadLocation(t.location); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldTaskLocation,
+				Msg:   err.Error(),
+			})
+		}
+	}
+
 	if t.query.Query == "" {
 		vErrs = append(vErrs, validationErr{
 			Field: fieldQuery,
@@ -2191,10 +2527,11 @@ func (t *task) valid() []validationErr {
 var fluxRegex = regexp.MustCompile(`import\s+\".*\"`)
 
 type taskFluxTranslation struct {
-	name   string
-	cron   string
-	every  time.Duration
-	offset time.Duration
+	name     string
+	cron     string
+	location string
+	every    time.Duration
+	offset   time.Duration
 
 	rawQuery string
 }
@@ -2230,6 +2567,9 @@ func (tft taskFluxTranslation) generateTaskOption() string {
 	if tft.cron != "" {
 		taskOpts = append(taskOpts, fmt.Sprintf("cron: %q", tft.cron))
 	}
+	if tft.location != "" {
+		taskOpts = append(taskOpts, fmt.Sprintf("location: %q", tft.location))
+	}
 	if tft.every > 0 {
 		taskOpts = append(taskOpts, fmt.Sprintf("every: %s", tft.every))
 	}
@@ -22,6 +22,7 @@ import (
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1000,6 +1001,49 @@ spec:
       name: label-1
     - kind: Label
       name: label-1
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly lookback within every",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckLookback},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 5m
+  lookback: 5m
+  deviations: 3
+  level: cRiT
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly invalid method",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckMethod},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 5m
+  lookback: 7d
+  method: iqr
+  deviations: 3
+  level: cRiT
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
 `,
 					},
 				},
@@ -2952,11 +2996,163 @@ spec:
 			})
 		})
 
+		t.Run("smtp, teams and opsgenie endpoints should be successful", func(t *testing.T) {
+			template := newParsedTemplate(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSMTP
+metadata:
+  name: smtp-notification-endpoint
+spec:
+  host: smtp.example.com
+  port: 587
+  from: influxdb@example.com
+  username: secret username
+  password:
+    secretRef:
+      key: smtp-password
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams-notification-endpoint
+spec:
+  url:
+    secretRef:
+      key: teams-url
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie-notification-endpoint
+spec:
+  url: https://api.eu.opsgenie.com/v2/alerts
+  apiKey: secret api key
+  entity: server1
+`), EncodingYAML)
+
+			expected := []SummaryNotificationEndpoint{
+				{
+					SummaryIdentifier: SummaryIdentifier{
+						Kind:          KindNotificationEndpointSMTP,
+						MetaName:      "smtp-notification-endpoint",
+						EnvReferences: []SummaryReference{},
+					},
+					NotificationEndpoint: &endpoint.SMTP{
+						Base: endpoint.Base{
+							Name:   "smtp-notification-endpoint",
+							Status: taskmodel.TaskStatusActive,
+						},
+						Host:     "smtp.example.com",
+						Port:     587,
+						From:     "influxdb@example.com",
+						Username: influxdb.SecretField{Value: strPtr("secret username")},
+						Password: influxdb.SecretField{Key: "smtp-password"},
+					},
+				},
+				{
+					SummaryIdentifier: SummaryIdentifier{
+						Kind:          KindNotificationEndpointTeams,
+						MetaName:      "teams-notification-endpoint",
+						EnvReferences: []SummaryReference{},
+					},
+					NotificationEndpoint: &endpoint.Teams{
+						Base: endpoint.Base{
+							Name:   "teams-notification-endpoint",
+							Status: taskmodel.TaskStatusActive,
+						},
+						URL: influxdb.SecretField{Key: "teams-url"},
+					},
+				},
+				{
+					SummaryIdentifier: SummaryIdentifier{
+						Kind:          KindNotificationEndpointOpsgenie,
+						MetaName:      "opsgenie-notification-endpoint",
+						EnvReferences: []SummaryReference{},
+					},
+					NotificationEndpoint: &endpoint.Opsgenie{
+						Base: endpoint.Base{
+							Name:   "opsgenie-notification-endpoint",
+							Status: taskmodel.TaskStatusActive,
+						},
+						URL:    "https://api.eu.opsgenie.com/v2/alerts",
+						APIKey: influxdb.SecretField{Value: strPtr("secret api key")},
+						Entity: "server1",
+					},
+				},
+			}
+
+			actual := template.Summary().NotificationEndpoints
+			require.Len(t, actual, len(expected))
+			for i, expected := range expected {
+				assert.Equal(t, expected.SummaryIdentifier, actual[i].SummaryIdentifier)
+				assert.Equal(t, expected.NotificationEndpoint, actual[i].NotificationEndpoint)
+			}
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
 				resErr testTemplateResourceError
 			}{
+				{
+					kind: KindNotificationEndpointSMTP,
+					resErr: testTemplateResourceError{
+						name:           "missing smtp host",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointHost},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSMTP
+metadata:
+  name: smtp-notification-endpoint
+spec:
+  from: influxdb@example.com
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointSMTP,
+					resErr: testTemplateResourceError{
+						name:           "invalid smtp from address",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointFrom},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSMTP
+metadata:
+  name: smtp-notification-endpoint
+spec:
+  host: smtp.example.com
+  from: influxdb
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTeams,
+					resErr: testTemplateResourceError{
+						name:           "missing teams url",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointURL},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams-notification-endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointOpsgenie,
+					resErr: testTemplateResourceError{
+						name:           "missing opsgenie api key",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointAPIKey},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie-notification-endpoint
+spec:
+`,
+					},
+				},
 				{
 					kind: KindNotificationEndpointSlack,
 					resErr: testTemplateResourceError{
@@ -3549,6 +3745,148 @@ spec:
 		})
 	})
 
+	t.Run("template with silences", func(t *testing.T) {
+		t.Run("with valid fields should produce summary", func(t *testing.T) {
+			testfileRunner(t, "testdata/silence", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.Silences, 2)
+
+				start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+				end := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+				actual := sum.Silences[0]
+				assert.Equal(t, KindSilence, actual.Kind)
+				assert.Equal(t, "deploy-silence", actual.MetaName)
+				assert.Empty(t, actual.CheckMetaName)
+				assert.Equal(t, "muting the deploy of the api hosts", actual.Silence.Comment)
+				assert.Equal(t, []influxdb.Tag{
+					{Key: "host", Value: "api-01"},
+					{Key: "region", Value: "us-west"},
+				}, actual.Silence.Tags)
+				assert.Equal(t, []notification.CheckLevel{notification.Warn, notification.Critical}, actual.Silence.Levels)
+				require.NotNil(t, actual.Silence.StartTime)
+				assert.True(t, start.Equal(*actual.Silence.StartTime))
+				require.NotNil(t, actual.Silence.EndTime)
+				assert.True(t, end.Equal(*actual.Silence.EndTime))
+				assert.Nil(t, actual.Silence.Window)
+
+				actual = sum.Silences[1]
+				assert.Equal(t, KindSilence, actual.Kind)
+				assert.Equal(t, "maintenance-window", actual.MetaName)
+				assert.Nil(t, actual.Silence.StartTime)
+				assert.Nil(t, actual.Silence.EndTime)
+				assert.Equal(t, &isilence.Window{
+					Days:     []string{"saturday", "sunday"},
+					Start:    "22:30",
+					Duration: "3h",
+				}, actual.Silence.Window)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testTemplateResourceError{
+				{
+					name:           "missing end time and window",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceEndTime},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  comment: forever
+`,
+				},
+				{
+					name:           "start time after end time",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceStartTime},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  startTime: "2021-03-01T12:00:00Z"
+  endTime: "2021-03-01T10:00:00Z"
+`,
+				},
+				{
+					name:           "invalid time",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceEndTime},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  endTime: tomorrow
+`,
+				},
+				{
+					name:           "invalid level",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceLevels},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  levels:
+    - ANY
+  endTime: "2021-03-01T10:00:00Z"
+`,
+				},
+				{
+					name:           "missing tag key",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceTags},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  tags:
+    - value: api-01
+  endTime: "2021-03-01T10:00:00Z"
+`,
+				},
+				{
+					name:           "invalid window",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceWindow},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  window:
+    days:
+      - someday
+    start: "22:30"
+    duration: 3h
+`,
+				},
+				{
+					name:           "check does not exist",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldSilenceCheckName},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Silence
+metadata:
+  name: silence-1
+spec:
+  checkName: check-1
+  endTime: "2021-03-01T10:00:00Z"
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testTemplateErrors(t, KindSilence, tt)
+			}
+		})
+	})
+
 	t.Run("template with tasks", func(t *testing.T) {
 		t.Run("happy path", func(t *testing.T) {
 			testfileRunner(t, "testdata/tasks", func(t *testing.T, template *Template) {
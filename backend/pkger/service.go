@@ -20,6 +20,7 @@ import (
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/rule"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/pkger/internal/wordplay"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/task/options"
@@ -165,6 +166,7 @@ type serviceOpt struct {
 	orgSVC      influxdb.OrganizationService
 	ruleSVC     influxdb.NotificationRuleStore
 	secretSVC   influxdb.SecretService
+	silenceSVC  isilence.SilenceService
 	taskSVC     taskmodel.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
@@ -277,6 +279,13 @@ func WithSecretSVC(secretSVC influxdb.SecretService) ServiceSetterFn {
 	}
 }
 
+// WithSilenceSVC sets the silence service.
+func WithSilenceSVC(silenceSVC isilence.SilenceService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.silenceSVC = silenceSVC
+	}
+}
+
 // WithTaskSVC sets the task service.
 func WithTaskSVC(taskSVC taskmodel.TaskService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -330,6 +339,7 @@ type Service struct {
 	orgSVC      influxdb.OrganizationService
 	ruleSVC     influxdb.NotificationRuleStore
 	secretSVC   influxdb.SecretService
+	silenceSVC  isilence.SilenceService
 	taskSVC     taskmodel.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
@@ -369,6 +379,7 @@ func NewService(opts ...ServiceSetterFn) *Service {
 		orgSVC:      opt.orgSVC,
 		ruleSVC:     opt.ruleSVC,
 		secretSVC:   opt.secretSVC,
+		silenceSVC:  opt.silenceSVC,
 		taskSVC:     opt.taskSVC,
 		teleSVC:     opt.teleSVC,
 		varSVC:      opt.varSVC,
@@ -853,6 +864,22 @@ func (s *Service) cloneOrgNotificationRules(ctx context.Context, orgID platform.
 	return resources, nil
 }
 
+func (s *Service) cloneOrgSilences(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
+	silences, err := s.silenceSVC.FindSilences(ctx, isilence.Filter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(silences))
+	for _, sil := range silences {
+		resources = append(resources, ResourceToClone{
+			Kind: KindSilence,
+			ID:   sil.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgTasks(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
 	tasks, err := s.getAllTasks(ctx, orgID)
 	if err != nil {
@@ -953,6 +980,10 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []resClone
 		KindTelegraf:             s.cloneOrgTelegrafs,
 		KindVariable:             s.cloneOrgVariables,
 	}
+	// silences are optional, they are only exported when the service manages them.
+	if s.silenceSVC != nil {
+		mKinds[KindSilence] = s.cloneOrgSilences
+	}
 
 	newResGen := func(resType influxdb.ResourceType, cloneFn cloneResFn) resClone {
 		return resClone{
@@ -1070,6 +1101,7 @@ func (s *Service) dryRun(ctx context.Context, orgID platform.ID, template *Templ
 	s.dryRunChecks(ctx, orgID, state.mChecks)
 	s.dryRunDashboards(ctx, orgID, state.mDashboards)
 	s.dryRunLabels(ctx, orgID, state.mLabels)
+	s.dryRunSilences(ctx, orgID, state.mSilences)
 	s.dryRunTasks(ctx, orgID, state.mTasks)
 	s.dryRunTelegrafConfigs(ctx, orgID, state.mTelegrafs)
 	s.dryRunVariables(ctx, orgID, state.mVariables)
@@ -1267,6 +1299,25 @@ func (s *Service) dryRunTelegrafConfigs(ctx context.Context, orgID platform.ID,
 	}
 }
 
+func (s *Service) dryRunSilences(ctx context.Context, orgID platform.ID, silences map[string]*stateSilence) {
+	if len(silences) == 0 {
+		return
+	}
+
+	// silences are not unique by name, so only the silences of a stack exist
+	existingSilences, _ := s.silenceSVC.FindSilences(ctx, isilence.Filter{OrgID: &orgID})
+	mIDs := make(map[platform.ID]*isilence.Silence)
+	for _, sil := range existingSilences {
+		mIDs[sil.ID] = sil
+	}
+
+	for _, sil := range silences {
+		if sil.ID() != 0 {
+			sil.existing = mIDs[sil.ID()]
+		}
+	}
+}
+
 func (s *Service) dryRunVariables(ctx context.Context, orgID platform.ID, vars map[string]*stateVariable) {
 	existingVars, _ := s.getAllPlatformVariables(ctx, orgID)
 
@@ -1508,11 +1559,14 @@ func ApplyWithResourceSkip(action ActionSkipResource) ApplyOptFn {
 			opt.ResourcesToSkip = make(map[ActionSkipResource]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSMTP,
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.ResourcesToSkip[action] = true
@@ -1526,11 +1580,14 @@ func ApplyWithKindSkip(action ActionSkipKind) ApplyOptFn {
 			opt.KindsToSkip = make(map[Kind]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSMTP,
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.KindsToSkip[action.Kind] = true
@@ -1677,6 +1734,12 @@ func (s *Service) applyState(ctx context.Context, coordinator *rollbackCoordinat
 		return err
 	}
 
+	// silences rely on their checks, and are applied after the rules so that the
+	// tasks of the rules are regenerated with the silences.
+	if err := coordinator.runTilEnd(ctx, orgID, userID, s.applySilences(ctx, userID, state.silences())); err != nil {
+		return internalErr(err)
+	}
+
 	// secondary resources
 	// this last grouping relies on the above 2 steps having completely successfully
 	secondary := []applier{
@@ -2598,8 +2661,9 @@ func (s *Service) applyTask(ctx context.Context, userID platform.ID, t *stateTas
 		newFlux := t.parserTask.flux()
 		newStatus := string(t.parserTask.Status())
 		opt := options.Options{
-			Name: t.parserTask.Name(),
-			Cron: t.parserTask.cron,
+			Name:     t.parserTask.Name(),
+			Cron:     t.parserTask.cron,
+			Location: t.parserTask.location,
 		}
 		if every := t.parserTask.every; every > 0 {
 			opt.Every.Parse(every.String())
@@ -2661,8 +2725,9 @@ func (s *Service) rollbackTasks(ctx context.Context, tasks []*stateTask) error {
 			t.existing = newTask
 		case StateStatusExists:
 			opt := options.Options{
-				Name: t.existing.Name,
-				Cron: t.existing.Cron,
+				Name:     t.existing.Name,
+				Cron:     t.existing.Cron,
+				Location: t.existing.Location,
 			}
 			if every := t.existing.Every; every != "" {
 				opt.Every.Parse(every)
@@ -2704,6 +2769,119 @@ func (s *Service) rollbackTasks(ctx context.Context, tasks []*stateTask) error {
 	return nil
 }
 
+func (s *Service) applySilences(ctx context.Context, userID platform.ID, silences []*stateSilence) applier {
+	const resource = "silence"
+
+	mutex := new(doMutex)
+	rollbackSilences := make([]*stateSilence, 0, len(silences))
+
+	createFn := func(ctx context.Context, i int, orgID, userID platform.ID) *applyErrBody {
+		var sil *stateSilence
+		mutex.Do(func() {
+			silences[i].orgID = orgID
+			sil = silences[i]
+		})
+		if !sil.shouldApply() {
+			return nil
+		}
+		if name := sil.parserSilence.CheckMetaName(); name != "" && sil.checkID() == 0 && !IsRemoval(sil.stateStatus) {
+			return &applyErrBody{
+				name: sil.parserSilence.MetaName(),
+				msg:  fmt.Sprintf("check %q of the silence was not applied", name),
+			}
+		}
+
+		influxSilence, err := s.applySilence(ctx, userID, sil)
+		if err != nil {
+			return &applyErrBody{
+				name: sil.parserSilence.MetaName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			silences[i].id = influxSilence.ID
+			rollbackSilences = append(rollbackSilences, silences[i])
+		})
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(silences),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn: func(_ platform.ID) error {
+				return s.rollbackSilences(ctx, userID, rollbackSilences)
+			},
+		},
+	}
+}
+
+func (s *Service) applySilence(ctx context.Context, userID platform.ID, sil *stateSilence) (isilence.Silence, error) {
+	switch {
+	case IsRemoval(sil.stateStatus):
+		if err := s.silenceSVC.DeleteSilence(ctx, sil.ID()); err != nil && errors2.ErrorCode(err) != errors2.ENotFound {
+			return isilence.Silence{}, applyFailErr("delete", sil.stateIdentity(), err)
+		}
+		if sil.existing == nil {
+			return isilence.Silence{}, nil
+		}
+		return *sil.existing, nil
+	case IsExisting(sil.stateStatus) && sil.existing != nil:
+		updated, err := s.silenceSVC.UpdateSilence(ctx, sil.ID(), sil.toInfluxSilence())
+		if err != nil {
+			return isilence.Silence{}, applyFailErr("update", sil.stateIdentity(), err)
+		}
+		return *updated, nil
+	default:
+		// when an existing silence (referenced in stack) has been deleted by a user
+		// then the resource is created anew to get it back to the expected state.
+		influxSilence := sil.toInfluxSilence()
+		if err := s.silenceSVC.CreateSilence(ctx, &influxSilence, userID); err != nil {
+			return isilence.Silence{}, applyFailErr("create", sil.stateIdentity(), err)
+		}
+		return influxSilence, nil
+	}
+}
+
+func (s *Service) rollbackSilences(ctx context.Context, userID platform.ID, silences []*stateSilence) error {
+	rollbackFn := func(sil *stateSilence) error {
+		var err error
+		switch {
+		case IsRemoval(sil.stateStatus):
+			if sil.existing == nil {
+				return nil
+			}
+			err = ierrors.Wrap(s.silenceSVC.CreateSilence(ctx, sil.existing, userID), "rolling back removed silence")
+		case IsExisting(sil.stateStatus):
+			if sil.existing == nil {
+				return nil
+			}
+			_, err = s.silenceSVC.UpdateSilence(ctx, sil.ID(), *sil.existing)
+			err = ierrors.Wrap(err, "rolling back updated silence")
+		default:
+			err = ierrors.Wrap(s.silenceSVC.DeleteSilence(ctx, sil.ID()), "rolling back created silence")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, sil := range silences {
+		if err := rollbackFn(sil); err != nil {
+			errs = append(errs, fmt.Sprintf("error for silence[%q]: %s", sil.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyTelegrafs(ctx context.Context, userID platform.ID, teles []*stateTelegraf) applier {
 	const resource = "telegrafs"
 
@@ -3228,6 +3406,17 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID platform.
 			),
 		})
 	}
+	for _, sil := range state.mSilences {
+		if IsRemoval(sil.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion: APIVersion,
+			ID:         sil.ID(),
+			Kind:       KindSilence,
+			MetaName:   sil.parserSilence.MetaName(),
+		})
+	}
 	for _, t := range state.mTasks {
 		if IsRemoval(t.stateStatus) || isRestrictedTask(t.existing) {
 			continue
@@ -3361,6 +3550,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID platform
 				res.Associations = newAss
 			}
 		}
+		for _, sil := range state.mSilences {
+			res, ok := existingResources[newKey(KindSilence, sil.parserSilence.MetaName())]
+			if ok && res.ID != sil.ID() && sil.existing != nil {
+				hasChanges = true
+				res.ID = sil.existing.ID
+			}
+		}
 		for _, t := range state.mTasks {
 			res, ok := existingResources[newKey(KindTask, t.parserTask.MetaName())]
 			if ok && res.ID != t.ID() {
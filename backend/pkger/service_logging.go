@@ -219,6 +219,7 @@ func (s *loggingMW) summaryLogFields(sum Summary) []zap.Field {
 		{key: "label_mappings", val: len(sum.LabelMappings)},
 		{key: "rules", val: len(sum.NotificationRules)},
 		{key: "secrets", val: len(sum.MissingSecrets)},
+		{key: "silences", val: len(sum.Silences)},
 		{key: "tasks", val: len(sum.Tasks)},
 		{key: "telegrafs", val: len(sum.TelegrafConfigs)},
 		{key: "variables", val: len(sum.Variables)},
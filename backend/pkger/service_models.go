@@ -7,6 +7,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/notification/rule"
+	isilence "github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 )
 
@@ -17,6 +18,7 @@ type stateCoordinator struct {
 	mEndpoints  map[string]*stateEndpoint
 	mLabels     map[string]*stateLabel
 	mRules      map[string]*stateRule
+	mSilences   map[string]*stateSilence
 	mTasks      map[string]*stateTask
 	mTelegrafs  map[string]*stateTelegraf
 	mVariables  map[string]*stateVariable
@@ -33,6 +35,7 @@ func newStateCoordinator(template *Template, acts resourceActions) *stateCoordin
 		mEndpoints:  make(map[string]*stateEndpoint),
 		mLabels:     make(map[string]*stateLabel),
 		mRules:      make(map[string]*stateRule),
+		mSilences:   make(map[string]*stateSilence),
 		mTasks:      make(map[string]*stateTask),
 		mTelegrafs:  make(map[string]*stateTelegraf),
 		mVariables:  make(map[string]*stateVariable),
@@ -101,6 +104,16 @@ func newStateCoordinator(template *Template, acts resourceActions) *stateCoordin
 			labelAssociations: state.templateToStateLabels(r.labels),
 		}
 	}
+	for _, sil := range template.silences() {
+		if acts.skipResource(KindSilence, sil.MetaName()) {
+			continue
+		}
+		state.mSilences[sil.MetaName()] = &stateSilence{
+			parserSilence:   sil,
+			stateStatus:     StateStatusNew,
+			associatedCheck: state.mChecks[sil.CheckMetaName()],
+		}
+	}
 	for _, task := range template.tasks() {
 		if acts.skipResource(KindTask, task.MetaName()) {
 			continue
@@ -183,6 +196,14 @@ func (s *stateCoordinator) rules() []*stateRule {
 	return out
 }
 
+func (s *stateCoordinator) silences() []*stateSilence {
+	out := make([]*stateSilence, 0, len(s.mSilences))
+	for _, sil := range s.mSilences {
+		out = append(out, sil)
+	}
+	return out
+}
+
 func (s *stateCoordinator) tasks() []*stateTask {
 	out := make([]*stateTask, 0, len(s.mTasks))
 	for _, t := range s.mTasks {
@@ -251,6 +272,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.NotificationRules[i].MetaName < diff.NotificationRules[j].MetaName
 	})
 
+	for _, sil := range s.mSilences {
+		diff.Silences = append(diff.Silences, sil.diffSilence())
+	}
+	sort.Slice(diff.Silences, func(i, j int) bool {
+		return diff.Silences[i].MetaName < diff.Silences[j].MetaName
+	})
+
 	for _, t := range s.mTasks {
 		diff.Tasks = append(diff.Tasks, t.diffTask())
 	}
@@ -361,6 +389,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.NotificationRules[i].MetaName < sum.NotificationRules[j].MetaName
 	})
 
+	for _, sil := range s.mSilences {
+		if IsRemoval(sil.stateStatus) {
+			continue
+		}
+		sum.Silences = append(sum.Silences, sil.summarize())
+	}
+	sort.Slice(sum.Silences, func(i, j int) bool {
+		return sum.Silences[i].MetaName < sum.Silences[j].MetaName
+	})
+
 	for _, t := range s.mTasks {
 		if IsRemoval(t.stateStatus) {
 			continue
@@ -510,7 +548,7 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 	case KindBucket:
 		v, ok := s.mBuckets[metaName]
 		return v, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		v, ok := s.mChecks[metaName]
 		return v, ok
 	case KindDashboard:
@@ -521,13 +559,19 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 		return v, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		v, ok := s.mEndpoints[metaName]
 		return v, ok
 	case KindNotificationRule:
 		v, ok := s.mRules[metaName]
 		return v, ok
+	case KindSilence:
+		v, ok := s.mSilences[metaName]
+		return v, ok
 	case KindTask:
 		v, ok := s.mTasks[metaName]
 		return v, ok
@@ -584,7 +628,7 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserBkt:   &bucket{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		s.mChecks[metaName] = &stateCheck{
 			id:          id,
 			parserCheck: &check{identity: newIdentity},
@@ -604,8 +648,11 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 		}
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		s.mEndpoints[metaName] = &stateEndpoint{
 			id:             id,
 			parserEndpoint: &notificationEndpoint{identity: newIdentity},
@@ -617,6 +664,12 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserRule:  &notificationRule{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
+	case KindSilence:
+		s.mSilences[metaName] = &stateSilence{
+			id:            id,
+			parserSilence: &silence{identity: newIdentity},
+			stateStatus:   StateStatusRemove,
+		}
 	case KindTask:
 		s.mTasks[metaName] = &stateTask{
 			id:          id,
@@ -646,7 +699,7 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		r, ok := s.mChecks[metaName]
 		return func(id platform.ID) {
 			r.id = id
@@ -666,8 +719,11 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 		}, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		r, ok := s.mEndpoints[metaName]
 		return func(id platform.ID) {
 			r.id = id
@@ -679,6 +735,12 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindSilence:
+		r, ok := s.mSilences[metaName]
+		return func(id platform.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindTask:
 		r, ok := s.mTasks[metaName]
 		return func(id platform.ID) {
@@ -1341,6 +1403,88 @@ func (r *stateRule) toInfluxRule() influxdb.NotificationRule {
 	return influxRule
 }
 
+type stateSilence struct {
+	id, orgID   platform.ID
+	stateStatus StateStatus
+
+	associatedCheck *stateCheck
+
+	parserSilence *silence
+	existing      *isilence.Silence
+}
+
+func (s *stateSilence) ID() platform.ID {
+	if !IsNew(s.stateStatus) && s.existing != nil {
+		return s.existing.ID
+	}
+	return s.id
+}
+
+func (s *stateSilence) checkID() platform.ID {
+	if s.associatedCheck != nil {
+		return s.associatedCheck.ID()
+	}
+	return 0
+}
+
+func (s *stateSilence) diffSilence() DiffSilence {
+	diff := DiffSilence{
+		DiffIdentifier: DiffIdentifier{
+			Kind:        KindSilence,
+			ID:          SafeID(s.ID()),
+			StateStatus: s.stateStatus,
+			MetaName:    s.parserSilence.MetaName(),
+		},
+		New: s.toInfluxSilence(),
+	}
+	if e := s.existing; e != nil {
+		old := *e
+		diff.Old = &old
+	}
+	return diff
+}
+
+func (s *stateSilence) resourceType() influxdb.ResourceType {
+	return KindSilence.ResourceType()
+}
+
+func (s *stateSilence) shouldApply() bool {
+	if IsRemoval(s.stateStatus) || s.existing == nil {
+		return true
+	}
+
+	// only the matchers, times and comment of a silence are applied
+	existing, sil := *s.existing, s.toInfluxSilence()
+	existing.ID, existing.OrgID, existing.CreatorID, existing.CRUDLog = sil.ID, sil.OrgID, sil.CreatorID, sil.CRUDLog
+	return !reflect.DeepEqual(existing, sil)
+}
+
+func (s *stateSilence) stateIdentity() stateIdentity {
+	return stateIdentity{
+		id:           s.ID(),
+		name:         s.parserSilence.Name(),
+		metaName:     s.parserSilence.MetaName(),
+		resourceType: s.resourceType(),
+		stateStatus:  s.stateStatus,
+	}
+}
+
+func (s *stateSilence) summarize() SummarySilence {
+	sum := s.parserSilence.summarize()
+	sum.Silence = s.toInfluxSilence()
+	return sum
+}
+
+func (s *stateSilence) toInfluxSilence() isilence.Silence {
+	sil := s.parserSilence.toInfluxSilence()
+	sil.ID = s.ID()
+	sil.OrgID = s.orgID
+	if checkID := s.checkID(); checkID != 0 {
+		sil.CheckID = &checkID
+	}
+	return sil
+}
+
 type stateTask struct {
 	id, orgID         platform.ID
 	stateStatus       StateStatus
@@ -1370,6 +1514,7 @@ func (t *stateTask) diffTask() DiffTask {
 			Cron:        t.parserTask.cron,
 			Description: t.parserTask.description,
 			Every:       durToStr(t.parserTask.every),
+			Location:    t.parserTask.location,
 			Offset:      durToStr(t.parserTask.offset),
 			Query:       t.parserTask.query.DashboardQuery(),
 			Status:      t.parserTask.Status(),
@@ -1385,6 +1530,7 @@ func (t *stateTask) diffTask() DiffTask {
 		Cron:        t.existing.Cron,
 		Description: t.existing.Description,
 		Every:       t.existing.Every,
+		Location:    t.existing.Location,
 		Offset:      t.existing.Offset.String(),
 		Query:       t.existing.Flux,
 		Status:      influxdb.Status(t.existing.Status),
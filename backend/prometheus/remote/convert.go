@@ -0,0 +1,283 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+const (
+	// MetricNameLabel is the label holding the metric name of a series. It is
+	// stored as the measurement name.
+	MetricNameLabel = "__name__"
+
+	// FieldName is the field holding sample values.
+	FieldName = "value"
+
+	measurementTagKey = "_measurement"
+	fieldTagKey       = "_field"
+)
+
+// ErrMissingMetricName is returned when a written series has no __name__ label.
+var ErrMissingMetricName = errors.New("time series has no __name__ label")
+
+// WriteRequestToPoints converts a remote write request to points. Each series is
+// written to a measurement named after its metric, with the remaining labels as
+// tags and samples as the "value" field. NaN samples, such as staleness markers,
+// cannot be stored and are dropped.
+func WriteRequestToPoints(req *WriteRequest) (models.Points, error) {
+	var n int
+	for _, ts := range req.GetTimeseries() {
+		n += len(ts.GetSamples())
+	}
+	points := make(models.Points, 0, n)
+
+	for _, ts := range req.GetTimeseries() {
+		var name string
+		tags := make(map[string]string, len(ts.GetLabels()))
+		for _, l := range ts.GetLabels() {
+			if l.GetName() == MetricNameLabel {
+				name = l.GetValue()
+				continue
+			}
+			tags[l.GetName()] = l.GetValue()
+		}
+		if name == "" {
+			return nil, ErrMissingMetricName
+		}
+
+		for _, s := range ts.GetSamples() {
+			if math.IsNaN(s.GetValue()) {
+				continue
+			}
+
+			fields := models.Fields{FieldName: s.GetValue()}
+			p, err := models.NewPoint(name, models.NewTags(tags), fields, time.Unix(0, s.GetTimestamp()*int64(time.Millisecond)))
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
+
+// PointsToWriteRequest converts points to a remote write request. Each numeric
+// field becomes a series with the point's tags as labels, named after the
+// measurement for the "value" field and "<measurement>_<field>" otherwise.
+// Boolean fields are written as 0 or 1; string fields cannot be represented and
+// are skipped.
+func PointsToWriteRequest(points []models.Point) (*WriteRequest, error) {
+	series := make(map[string]*TimeSeries)
+	var keys []string
+	for _, p := range points {
+		measurement := string(p.Name())
+		tags := p.Tags()
+		ts := p.Time().UnixNano() / int64(time.Millisecond)
+
+		iter := p.FieldIterator()
+		for iter.Next() {
+			var v float64
+			switch iter.Type() {
+			case models.Float:
+				f, err := iter.FloatValue()
+				if err != nil {
+					return nil, err
+				}
+				v = f
+			case models.Integer:
+				i, err := iter.IntegerValue()
+				if err != nil {
+					return nil, err
+				}
+				v = float64(i)
+			case models.Unsigned:
+				u, err := iter.UnsignedValue()
+				if err != nil {
+					return nil, err
+				}
+				v = float64(u)
+			case models.Boolean:
+				b, err := iter.BooleanValue()
+				if err != nil {
+					return nil, err
+				}
+				if b {
+					v = 1
+				}
+			default:
+				continue
+			}
+
+			name := measurement
+			if field := string(iter.FieldKey()); field != FieldName {
+				name += "_" + field
+			}
+			key := string(models.MakeKey([]byte(name), tags))
+			s, ok := series[key]
+			if !ok {
+				labels := make([]*Label, 0, len(tags)+1)
+				labels = append(labels, &Label{Name: MetricNameLabel, Value: name})
+				for _, t := range tags {
+					labels = append(labels, &Label{Name: string(t.Key), Value: string(t.Value)})
+				}
+				sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+				s = &TimeSeries{Labels: labels}
+				series[key] = s
+				keys = append(keys, key)
+			}
+			s.Samples = append(s.Samples, &Sample{Timestamp: ts, Value: v})
+		}
+	}
+
+	req := &WriteRequest{Timeseries: make([]*TimeSeries, 0, len(keys))}
+	for _, key := range keys {
+		req.Timeseries = append(req.Timeseries, series[key])
+	}
+	return req, nil
+}
+
+// QueryToPredicate converts the label matchers of a remote read query to a storage
+// predicate. Matchers on __name__ select the measurement and only the "value" field
+// is read.
+func QueryToPredicate(q *Query) (*datatypes.Predicate, error) {
+	root := comparisonNode(datatypes.Node_ComparisonEqual, models.FieldKeyTagKey, stringNode(FieldName))
+	for _, m := range q.GetMatchers() {
+		key := m.GetName()
+		if key == MetricNameLabel {
+			key = models.MeasurementTagKey
+		}
+
+		var n *datatypes.Node
+		switch m.GetType() {
+		case LabelMatcher_EQ:
+			n = comparisonNode(datatypes.Node_ComparisonEqual, key, stringNode(m.GetValue()))
+		case LabelMatcher_NEQ:
+			n = comparisonNode(datatypes.Node_ComparisonNotEqual, key, stringNode(m.GetValue()))
+		case LabelMatcher_RE:
+			n = comparisonNode(datatypes.Node_ComparisonRegex, key, regexNode(m.GetValue()))
+		case LabelMatcher_NRE:
+			n = comparisonNode(datatypes.Node_ComparisonNotRegex, key, regexNode(m.GetValue()))
+		default:
+			return nil, fmt.Errorf("unknown label matcher type %v", m.GetType())
+		}
+
+		root = &datatypes.Node{
+			NodeType: datatypes.Node_TypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.Node_LogicalAnd},
+			Children: []*datatypes.Node{root, n},
+		}
+	}
+	return &datatypes.Predicate{Root: root}, nil
+}
+
+// QueryToTimestampRange converts the inclusive millisecond range of a remote read
+// query to the half-open nanosecond range used by storage.
+func QueryToTimestampRange(q *Query) *datatypes.TimestampRange {
+	return &datatypes.TimestampRange{
+		Start: q.GetStartTimestampMs() * int64(time.Millisecond),
+		End:   (q.GetEndTimestampMs() + 1) * int64(time.Millisecond),
+	}
+}
+
+func comparisonNode(cmp datatypes.Node_Comparison, key string, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.Node_TypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: cmp},
+		Children: []*datatypes.Node{
+			{NodeType: datatypes.Node_TypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+			value,
+		},
+	}
+}
+
+func stringNode(s string) *datatypes.Node {
+	return &datatypes.Node{NodeType: datatypes.Node_TypeLiteral, Value: &datatypes.Node_StringValue{StringValue: s}}
+}
+
+// regexNode returns a regular expression literal anchored at both ends, as
+// Prometheus matchers must match the entire label value.
+func regexNode(re string) *datatypes.Node {
+	return &datatypes.Node{NodeType: datatypes.Node_TypeLiteral, Value: &datatypes.Node_RegexValue{RegexValue: "^(?:" + re + ")$"}}
+}
+
+// ResultSetToTimeSeries reads every series of rs, converting tags back to labels.
+// Series with a non-numeric value type are skipped.
+func ResultSetToTimeSeries(rs reads.ResultSet) ([]*TimeSeries, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	defer rs.Close()
+
+	var series []*TimeSeries
+	for rs.Next() {
+		cur := rs.Cursor()
+		if cur == nil {
+			continue
+		}
+
+		samples, err := readSamples(cur)
+		cur.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			continue
+		}
+
+		series = append(series, &TimeSeries{
+			Labels:  tagsToLabels(rs.Tags()),
+			Samples: samples,
+		})
+	}
+	return series, rs.Err()
+}
+
+func readSamples(cur cursors.Cursor) ([]*Sample, error) {
+	var samples []*Sample
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, &Sample{Timestamp: a.Timestamps[i] / int64(time.Millisecond), Value: a.Values[i]})
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, &Sample{Timestamp: a.Timestamps[i] / int64(time.Millisecond), Value: float64(a.Values[i])})
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, &Sample{Timestamp: a.Timestamps[i] / int64(time.Millisecond), Value: float64(a.Values[i])})
+			}
+		}
+	default:
+		return nil, nil
+	}
+	return samples, cur.Err()
+}
+
+func tagsToLabels(tags models.Tags) []*Label {
+	labels := make([]*Label, 0, len(tags))
+	for _, t := range tags {
+		switch string(t.Key) {
+		case measurementTagKey:
+			labels = append(labels, &Label{Name: MetricNameLabel, Value: string(t.Value)})
+		case fieldTagKey:
+		default:
+			labels = append(labels, &Label{Name: string(t.Key), Value: string(t.Value)})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
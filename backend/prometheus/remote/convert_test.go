@@ -0,0 +1,208 @@
+package remote_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRequestToPoints(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.Label{
+					{Name: "__name__", Value: "http_requests_total"},
+					{Name: "job", Value: "api"},
+					{Name: "code", Value: "200"},
+				},
+				Samples: []*remote.Sample{
+					{Timestamp: 1000, Value: 1},
+					{Timestamp: 2000, Value: math.NaN()},
+					{Timestamp: 3000, Value: 2.5},
+				},
+			},
+		},
+	}
+
+	points, err := remote.WriteRequestToPoints(req)
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range points {
+		got = append(got, p.String())
+	}
+	require.Equal(t, []string{
+		"http_requests_total,code=200,job=api value=1 1000000000",
+		"http_requests_total,code=200,job=api value=2.5 3000000000",
+	}, got)
+}
+
+func TestWriteRequestToPoints_MissingName(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels:  []*remote.Label{{Name: "job", Value: "api"}},
+				Samples: []*remote.Sample{{Timestamp: 1000, Value: 1}},
+			},
+		},
+	}
+
+	_, err := remote.WriteRequestToPoints(req)
+	require.Equal(t, remote.ErrMissingMetricName, err)
+}
+
+func TestPointsToWriteRequest(t *testing.T) {
+	points, err := models.ParsePointsString(`
+http_requests_total,job=api,code=200 value=1 1000000000
+http_requests_total,job=api,code=200 value=2i 2000000000
+cpu,host=a usage=0.5,up=true,msg="ok" 3000000000`)
+	require.NoError(t, err)
+
+	req, err := remote.PointsToWriteRequest(points)
+	require.NoError(t, err)
+	require.Equal(t, &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.Label{
+					{Name: "__name__", Value: "http_requests_total"},
+					{Name: "code", Value: "200"},
+					{Name: "job", Value: "api"},
+				},
+				Samples: []*remote.Sample{
+					{Timestamp: 1000, Value: 1},
+					{Timestamp: 2000, Value: 2},
+				},
+			},
+			{
+				Labels: []*remote.Label{
+					{Name: "__name__", Value: "cpu_usage"},
+					{Name: "host", Value: "a"},
+				},
+				Samples: []*remote.Sample{{Timestamp: 3000, Value: 0.5}},
+			},
+			{
+				Labels: []*remote.Label{
+					{Name: "__name__", Value: "cpu_up"},
+					{Name: "host", Value: "a"},
+				},
+				Samples: []*remote.Sample{{Timestamp: 3000, Value: 1}},
+			},
+		},
+	}, req)
+}
+
+func TestQueryToPredicate(t *testing.T) {
+	cases := []struct {
+		name     string
+		matchers []*remote.LabelMatcher
+		exp      string
+	}{
+		{
+			name: "no matchers",
+			exp:  "'\xff' = \"value\"",
+		},
+		{
+			name: "metric name and labels",
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+				{Type: remote.LabelMatcher_NEQ, Name: "job", Value: "api"},
+				{Type: remote.LabelMatcher_RE, Name: "instance", Value: "host-.*"},
+				{Type: remote.LabelMatcher_NRE, Name: "env", Value: "dev|test"},
+			},
+			exp: "'\xff' = \"value\" AND '\x00' = \"up\" AND 'job' != \"api\" AND 'instance' =~ /^(?:host-.*)$/ AND 'env' !~ /^(?:dev|test)$/",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pred, err := remote.QueryToPredicate(&remote.Query{Matchers: tc.matchers})
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, reads.PredicateToExprString(pred))
+		})
+	}
+}
+
+func TestQueryToTimestampRange(t *testing.T) {
+	r := remote.QueryToTimestampRange(&remote.Query{StartTimestampMs: 1000, EndTimestampMs: 2000})
+	require.Equal(t, int64(1000000000), r.GetStart())
+	require.Equal(t, int64(2001000000), r.GetEnd())
+}
+
+func TestResultSetToTimeSeries(t *testing.T) {
+	rs := &sliceResultSet{
+		series: []series{
+			{
+				tags: models.ParseTags([]byte("up,_measurement=up,_field=value,job=api")),
+				cur: &floatArrayCursor{a: &cursors.FloatArray{
+					Timestamps: []int64{1000000000, 2000000000},
+					Values:     []float64{1, 0},
+				}},
+			},
+			{
+				tags: models.ParseTags([]byte("up,_measurement=up,_field=value,job=db")),
+				cur:  &floatArrayCursor{a: cursors.NewFloatArrayLen(0)},
+			},
+		},
+	}
+
+	got, err := remote.ResultSetToTimeSeries(rs)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, []string{"__name__=up", "job=api"}, labelStrings(got[0].GetLabels()))
+	require.Len(t, got[0].GetSamples(), 2)
+	require.Equal(t, int64(1000), got[0].GetSamples()[0].GetTimestamp())
+	require.Equal(t, 1.0, got[0].GetSamples()[0].GetValue())
+	require.True(t, rs.closed)
+}
+
+func labelStrings(labels []*remote.Label) []string {
+	var s []string
+	for _, l := range labels {
+		s = append(s, l.GetName()+"="+l.GetValue())
+	}
+	return s
+}
+
+type series struct {
+	tags models.Tags
+	cur  cursors.Cursor
+}
+
+type sliceResultSet struct {
+	series []series
+	cur    series
+	closed bool
+}
+
+func (rs *sliceResultSet) Next() bool {
+	if len(rs.series) == 0 {
+		return false
+	}
+	rs.cur, rs.series = rs.series[0], rs.series[1:]
+	return true
+}
+
+func (rs *sliceResultSet) Cursor() cursors.Cursor     { return rs.cur.cur }
+func (rs *sliceResultSet) Tags() models.Tags          { return rs.cur.tags }
+func (rs *sliceResultSet) Close()                     { rs.closed = true }
+func (rs *sliceResultSet) Err() error                 { return nil }
+func (rs *sliceResultSet) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type floatArrayCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *floatArrayCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+func (c *floatArrayCursor) Close()                     {}
+func (c *floatArrayCursor) Err() error                 { return nil }
+func (c *floatArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
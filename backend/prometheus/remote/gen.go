@@ -0,0 +1,3 @@
+package remote
+
+//go:generate protoc --go_out=. remote.proto
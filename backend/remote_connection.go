@@ -1,9 +1,103 @@
 package influxdb
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// RemoteType is the kind of system a remote connection sends replicated data to.
+type RemoteType string
+
+const (
+	// RemoteTypeInfluxDB is another InfluxDB instance, written to via its v2 write API.
+	RemoteTypeInfluxDB RemoteType = "influxdb"
+	// RemoteTypeHTTP is a generic HTTP endpoint receiving each batch as a POST request.
+	RemoteTypeHTTP RemoteType = "http"
+	// RemoteTypeKafka is a Kafka-protocol broker receiving each point as a message. The remote
+	// URL of a Kafka remote is a comma-separated list of broker addresses.
+	RemoteTypeKafka RemoteType = "kafka"
 )
 
+// RemoteFormat is the encoding of the data sent to HTTP and Kafka remotes.
+type RemoteFormat string
+
+const (
+	// RemoteFormatLineProtocol sends gzipped line protocol to HTTP remotes, and a line per
+	// message to Kafka remotes.
+	RemoteFormatLineProtocol RemoteFormat = "lineprotocol"
+	// RemoteFormatJSON sends a JSON array of points to HTTP remotes, and a JSON point per
+	// message to Kafka remotes.
+	RemoteFormatJSON RemoteFormat = "json"
+	// RemoteFormatPrometheus sends a Prometheus remote write request to HTTP remotes.
+	RemoteFormatPrometheus RemoteFormat = "prometheus"
+)
+
+// validateRemoteSink checks that the remote type, format and topic of a remote connection fit together.
+func validateRemoteSink(typ RemoteType, format RemoteFormat, topic string) error {
+	invalid := func(msg string) error {
+		return &errors.Error{Code: errors.EInvalid, Msg: msg}
+	}
+
+	switch typ {
+	case "", RemoteTypeInfluxDB:
+		if format != "" {
+			return invalid("format is only supported by http and kafka remotes")
+		}
+	case RemoteTypeHTTP:
+		switch format {
+		case "", RemoteFormatLineProtocol, RemoteFormatJSON, RemoteFormatPrometheus:
+		default:
+			return invalid(fmt.Sprintf("invalid format %q for http remote", format))
+		}
+	case RemoteTypeKafka:
+		switch format {
+		case "", RemoteFormatLineProtocol, RemoteFormatJSON:
+		default:
+			return invalid(fmt.Sprintf("invalid format %q for kafka remote", format))
+		}
+		if topic == "" {
+			return invalid("kafka remotes require a topic")
+		}
+	default:
+		return invalid(fmt.Sprintf("invalid remote type %q", typ))
+	}
+
+	if topic != "" && typ != RemoteTypeKafka {
+		return invalid("topic is only supported by kafka remotes")
+	}
+	return nil
+}
+
+// validateRemoteTLS checks the TLS settings of a remote connection: the CA bundle must contain
+// PEM certificates, and the client certificate and key must be given together. A client
+// certificate and key given as values rather than as secret keys must form a key pair.
+func validateRemoteTLS(typ RemoteType, caCert string, serverName string, cert, key SecretField) error {
+	invalid := func(msg string) error {
+		return &errors.Error{Code: errors.EInvalid, Msg: msg}
+	}
+
+	hasCert, hasKey := cert.Key != "" || cert.Value != nil, key.Key != "" || key.Value != nil
+	if typ == RemoteTypeKafka && (caCert != "" || serverName != "" || hasCert || hasKey) {
+		return invalid("TLS settings are not supported by kafka remotes")
+	}
+	if caCert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(caCert)) {
+		return invalid("caCert must contain PEM-encoded certificates")
+	}
+	if hasCert != hasKey {
+		return invalid("clientCert and clientKey must be set together")
+	}
+	if cert.Value != nil && key.Value != nil {
+		if _, err := tls.X509KeyPair([]byte(*cert.Value), []byte(*key.Value)); err != nil {
+			return &errors.Error{Code: errors.EInvalid, Msg: "invalid client certificate", Err: err}
+		}
+	}
+	return nil
+}
+
 // RemoteConnection contains all info about a remote InfluxDB instance that should be returned to users.
 // Note that the auth token used by the request is *not* included here.
 type RemoteConnection struct {
@@ -14,6 +108,29 @@ type RemoteConnection struct {
 	RemoteURL        string       `json:"remoteURL" db:"remote_url"`
 	RemoteOrgID      *platform.ID `json:"remoteOrgID" db:"remote_org_id"`
 	AllowInsecureTLS bool         `json:"allowInsecureTLS" db:"allow_insecure_tls"`
+	Type             RemoteType   `json:"type" db:"remote_type"`
+	Format           RemoteFormat `json:"format,omitempty" db:"remote_format"`
+	Topic            string       `json:"topic,omitempty" db:"remote_topic"`
+	CACert           string       `json:"caCert,omitempty" db:"remote_ca_cert"`
+	ServerName       string       `json:"serverName,omitempty" db:"remote_server_name"`
+	// ClientCertSecret and ClientKeySecret are the keys of the organization's secrets holding the
+	// PEM-encoded client certificate and private key presented to the remote.
+	ClientCertSecret string `json:"clientCertSecret,omitempty" db:"client_cert_secret"`
+	ClientKeySecret  string `json:"clientKeySecret,omitempty" db:"client_key_secret"`
+	// RemoteInstanceID is the instance ID of the remote InfluxDB instance, see
+	// ReplicationOriginHeader.
+	RemoteInstanceID string `json:"remoteInstanceID,omitempty" db:"remote_instance_id"`
+}
+
+// ValidateSink checks that the type, format and topic of the remote connection fit together.
+func (rc *RemoteConnection) ValidateSink() error {
+	return validateRemoteSink(rc.Type, rc.Format, rc.Topic)
+}
+
+// ValidateTLS checks the TLS settings of the remote connection, when using the given client
+// certificate and key.
+func (rc *RemoteConnection) ValidateTLS(cert, key SecretField) error {
+	return validateRemoteTLS(rc.Type, rc.CACert, rc.ServerName, cert, key)
 }
 
 // RemoteConnectionListFilter is a selection filter for listing remote InfluxDB instances.
@@ -38,14 +155,46 @@ type CreateRemoteConnectionRequest struct {
 	RemoteToken      string       `json:"remoteAPIToken"`
 	RemoteOrgID      *platform.ID `json:"remoteOrgID"`
 	AllowInsecureTLS bool         `json:"allowInsecureTLS"`
+	Type             RemoteType   `json:"type,omitempty"`
+	Format           RemoteFormat `json:"format,omitempty"`
+	Topic            string       `json:"topic,omitempty"`
+
+	// CACert is a PEM bundle of the certificate authorities trusted to sign the remote's
+	// certificate, instead of the system's. ServerName overrides the name sent using SNI and
+	// checked against the remote's certificate.
+	CACert     string `json:"caCert,omitempty"`
+	ServerName string `json:"serverName,omitempty"`
+	// ClientCert and ClientKey are the PEM-encoded client certificate and private key presented
+	// to remotes requiring mutual TLS. Values are stored as secrets of the organization; a
+	// "secret: <key>" reference uses an existing secret instead.
+	ClientCert SecretField `json:"clientCert,omitempty"`
+	ClientKey  SecretField `json:"clientKey,omitempty"`
+	// RemoteInstanceID is the instance ID of the remote InfluxDB instance. Points replicated
+	// through the remote are never sent back to it.
+	RemoteInstanceID string `json:"remoteInstanceID,omitempty"`
+}
+
+func (r *CreateRemoteConnectionRequest) OK() error {
+	if err := validateRemoteSink(r.Type, r.Format, r.Topic); err != nil {
+		return err
+	}
+	return validateRemoteTLS(r.Type, r.CACert, r.ServerName, r.ClientCert, r.ClientKey)
 }
 
 // UpdateRemoteConnectionRequest contains a partial update to existing info about a remote InfluxDB instance.
 type UpdateRemoteConnectionRequest struct {
-	Name             *string      `json:"name,omitempty"`
-	Description      *string      `json:"description,omitempty"`
-	RemoteURL        *string      `json:"remoteURL,omitempty"`
-	RemoteToken      *string      `json:"remoteAPIToken,omitempty"`
-	RemoteOrgID      *platform.ID `json:"remoteOrgID,omitempty"`
-	AllowInsecureTLS *bool        `json:"allowInsecureTLS,omitempty"`
+	Name             *string       `json:"name,omitempty"`
+	Description      *string       `json:"description,omitempty"`
+	RemoteURL        *string       `json:"remoteURL,omitempty"`
+	RemoteToken      *string       `json:"remoteAPIToken,omitempty"`
+	RemoteOrgID      *platform.ID  `json:"remoteOrgID,omitempty"`
+	AllowInsecureTLS *bool         `json:"allowInsecureTLS,omitempty"`
+	Format           *RemoteFormat `json:"format,omitempty"`
+	Topic            *string       `json:"topic,omitempty"`
+	CACert           *string       `json:"caCert,omitempty"`
+	ServerName       *string       `json:"serverName,omitempty"`
+	// Setting both ClientCert and ClientKey to "" removes the client certificate.
+	ClientCert       *SecretField `json:"clientCert,omitempty"`
+	ClientKey        *SecretField `json:"clientKey,omitempty"`
+	RemoteInstanceID *string      `json:"remoteInstanceID,omitempty"`
 }
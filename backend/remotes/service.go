@@ -2,8 +2,11 @@ package remotes
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/influxdata/influxdb/v2"
@@ -20,20 +23,36 @@ var (
 	}
 )
 
-func NewService(store *sqlite.SqlStore) *service {
+// remoteColumns are the columns of the remotes table returned to users.
+var remoteColumns = []string{
+	"id", "org_id", "name", "description", "remote_url", "remote_org_id", "allow_insecure_tls",
+	"remote_type", "remote_format", "remote_topic",
+	"remote_ca_cert", "remote_server_name", "client_cert_secret", "client_key_secret",
+	"remote_instance_id",
+}
+
+func NewService(store *sqlite.SqlStore, secrets influxdb.SecretService) *service {
 	return &service{
 		store:       store,
+		secrets:     secrets,
 		idGenerator: snowflake.NewIDGenerator(),
 	}
 }
 
 type service struct {
 	store       *sqlite.SqlStore
+	secrets     influxdb.SecretService
 	idGenerator platform.IDGenerator
 }
 
+// clientCertSecretKeys returns the keys of the secrets holding the client certificate and key
+// of the remote with the given ID, when they are given as values rather than secret keys.
+func clientCertSecretKeys(id platform.ID) (string, string) {
+	return id.String() + "-client-cert", id.String() + "-client-key"
+}
+
 func (s service) ListRemoteConnections(ctx context.Context, filter influxdb.RemoteConnectionListFilter) (*influxdb.RemoteConnections, error) {
-	q := sq.Select("id", "org_id", "name", "description", "remote_url", "remote_org_id", "allow_insecure_tls").
+	q := sq.Select(remoteColumns...).
 		From("remotes").
 		Where(sq.Eq{"org_id": filter.OrgID})
 
@@ -58,12 +77,28 @@ func (s service) ListRemoteConnections(ctx context.Context, filter influxdb.Remo
 }
 
 func (s service) CreateRemoteConnection(ctx context.Context, request influxdb.CreateRemoteConnectionRequest) (*influxdb.RemoteConnection, error) {
+	if err := request.OK(); err != nil {
+		return nil, err
+	}
+	if request.Type == "" {
+		request.Type = influxdb.RemoteTypeInfluxDB
+	}
+	if request.Format == "" && request.Type != influxdb.RemoteTypeInfluxDB {
+		request.Format = influxdb.RemoteFormatLineProtocol
+	}
+
 	s.store.Mu.Lock()
 	defer s.store.Mu.Unlock()
 
+	id := s.idGenerator.ID()
+	certSecret, keySecret, err := s.putClientCert(ctx, request.OrgID, id, request.ClientCert, request.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+
 	q := sq.Insert("remotes").
 		SetMap(sq.Eq{
-			"id":                 s.idGenerator.ID(),
+			"id":                 id,
 			"org_id":             request.OrgID,
 			"name":               request.Name,
 			"description":        request.Description,
@@ -71,10 +106,18 @@ func (s service) CreateRemoteConnection(ctx context.Context, request influxdb.Cr
 			"remote_api_token":   request.RemoteToken,
 			"remote_org_id":      request.RemoteOrgID,
 			"allow_insecure_tls": request.AllowInsecureTLS,
+			"remote_type":        request.Type,
+			"remote_format":      request.Format,
+			"remote_topic":       request.Topic,
+			"remote_ca_cert":     request.CACert,
+			"remote_server_name": request.ServerName,
+			"client_cert_secret": certSecret,
+			"client_key_secret":  keySecret,
+			"remote_instance_id": request.RemoteInstanceID,
 			"created_at":         "datetime('now')",
 			"updated_at":         "datetime('now')",
 		}).
-		Suffix("RETURNING id, org_id, name, description, remote_url, remote_org_id, allow_insecure_tls")
+		Suffix("RETURNING " + strings.Join(remoteColumns, ", "))
 
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -83,13 +126,16 @@ func (s service) CreateRemoteConnection(ctx context.Context, request influxdb.Cr
 
 	var rc influxdb.RemoteConnection
 	if err := s.store.DB.GetContext(ctx, &rc, query, args...); err != nil {
+		if cleanupErr := s.deleteClientCert(ctx, request.OrgID, id, certSecret, keySecret); cleanupErr != nil {
+			return nil, fmt.Errorf("%v, and failed to delete client certificate secrets: %v", err, cleanupErr)
+		}
 		return nil, err
 	}
 	return &rc, nil
 }
 
 func (s service) GetRemoteConnection(ctx context.Context, id platform.ID) (*influxdb.RemoteConnection, error) {
-	q := sq.Select("id", "org_id", "name", "description", "remote_url", "remote_org_id", "allow_insecure_tls").
+	q := sq.Select(remoteColumns...).
 		From("remotes").
 		Where(sq.Eq{"id": id})
 
@@ -113,6 +159,62 @@ func (s service) UpdateRemoteConnection(ctx context.Context, id platform.ID, req
 	defer s.store.Mu.Unlock()
 
 	updates := sq.Eq{"updated_at": sq.Expr("datetime('now')")}
+
+	// Secrets of the previous client certificate which are no longer used after the update.
+	var staleSecrets []string
+	var orgID platform.ID
+
+	if request.Format != nil || request.Topic != nil || request.CACert != nil || request.ServerName != nil ||
+		request.ClientCert != nil || request.ClientKey != nil {
+		rc, err := s.GetRemoteConnection(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		orgID = rc.OrgID
+
+		if request.Format != nil {
+			rc.Format = *request.Format
+		}
+		if request.Topic != nil {
+			rc.Topic = *request.Topic
+		}
+		if err := rc.ValidateSink(); err != nil {
+			return nil, err
+		}
+
+		if request.CACert != nil {
+			rc.CACert = *request.CACert
+		}
+		if request.ServerName != nil {
+			rc.ServerName = *request.ServerName
+		}
+		cert, key := influxdb.SecretField{Key: rc.ClientCertSecret}, influxdb.SecretField{Key: rc.ClientKeySecret}
+		if request.ClientCert != nil {
+			cert = *request.ClientCert
+		}
+		if request.ClientKey != nil {
+			key = *request.ClientKey
+		}
+		if err := rc.ValidateTLS(cert, key); err != nil {
+			return nil, err
+		}
+
+		if request.ClientCert != nil || request.ClientKey != nil {
+			certSecret, keySecret, err := s.putClientCert(ctx, rc.OrgID, id, cert, key)
+			if err != nil {
+				return nil, err
+			}
+			updates["client_cert_secret"] = certSecret
+			updates["client_key_secret"] = keySecret
+			if rc.ClientCertSecret != certSecret {
+				staleSecrets = append(staleSecrets, rc.ClientCertSecret)
+			}
+			if rc.ClientKeySecret != keySecret {
+				staleSecrets = append(staleSecrets, rc.ClientKeySecret)
+			}
+		}
+	}
+
 	if request.AllowInsecureTLS != nil {
 		updates["allow_insecure_tls"] = *request.AllowInsecureTLS
 	}
@@ -131,9 +233,24 @@ func (s service) UpdateRemoteConnection(ctx context.Context, id platform.ID, req
 	if request.Description != nil {
 		updates["description"] = *request.Description
 	}
+	if request.Format != nil {
+		updates["remote_format"] = *request.Format
+	}
+	if request.Topic != nil {
+		updates["remote_topic"] = *request.Topic
+	}
+	if request.CACert != nil {
+		updates["remote_ca_cert"] = *request.CACert
+	}
+	if request.ServerName != nil {
+		updates["remote_server_name"] = *request.ServerName
+	}
+	if request.RemoteInstanceID != nil {
+		updates["remote_instance_id"] = *request.RemoteInstanceID
+	}
 
 	q := sq.Update("remotes").SetMap(updates).Where(sq.Eq{"id": id}).
-		Suffix("RETURNING id, org_id, name, description, remote_url, remote_org_id, allow_insecure_tls")
+		Suffix("RETURNING " + strings.Join(remoteColumns, ", "))
 
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -147,6 +264,10 @@ func (s service) UpdateRemoteConnection(ctx context.Context, id platform.ID, req
 		}
 		return nil, err
 	}
+
+	if err := s.deleteClientCert(ctx, orgID, id, staleSecrets...); err != nil {
+		return nil, err
+	}
 	return &rc, nil
 }
 
@@ -154,18 +275,91 @@ func (s service) DeleteRemoteConnection(ctx context.Context, id platform.ID) err
 	s.store.Mu.Lock()
 	defer s.store.Mu.Unlock()
 
-	q := sq.Delete("remotes").Where(sq.Eq{"id": id}).Suffix("RETURNING id")
+	q := sq.Delete("remotes").Where(sq.Eq{"id": id}).Suffix("RETURNING org_id, client_cert_secret, client_key_secret")
 	query, args, err := q.ToSql()
 	if err != nil {
 		return err
 	}
 
-	var d platform.ID
+	var d struct {
+		OrgID      platform.ID `db:"org_id"`
+		CertSecret string      `db:"client_cert_secret"`
+		KeySecret  string      `db:"client_key_secret"`
+	}
 	if err := s.store.DB.GetContext(ctx, &d, query, args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return errRemoteNotFound
 		}
 		return err
 	}
-	return nil
+	return s.deleteClientCert(ctx, d.OrgID, id, d.CertSecret, d.KeySecret)
+}
+
+// putClientCert stores the values of a remote's client certificate and key as secrets of the
+// organization, and returns the keys of the secrets holding them. Secret keys given instead of
+// values must refer to existing secrets. Either way, the certificate and key must form a pair.
+func (s service) putClientCert(ctx context.Context, orgID, id platform.ID, cert, key influxdb.SecretField) (string, string, error) {
+	if cert.Key == "" && cert.Value == nil {
+		return "", "", nil
+	}
+
+	certSecret, keySecret := clientCertSecretKeys(id)
+	secrets := make(map[string]string, 2)
+	if cert.Value != nil {
+		secrets[certSecret] = *cert.Value
+	} else {
+		certSecret = cert.Key
+	}
+	if key.Value != nil {
+		secrets[keySecret] = *key.Value
+	} else {
+		keySecret = key.Key
+	}
+
+	pem := make(map[string]string, 2)
+	for _, k := range []string{certSecret, keySecret} {
+		if v, ok := secrets[k]; ok {
+			pem[k] = v
+			continue
+		}
+		v, err := s.secrets.LoadSecret(ctx, orgID, k)
+		if err != nil {
+			return "", "", &ierrors.Error{
+				Code: ierrors.EInvalid,
+				Msg:  fmt.Sprintf("unable to load secret %q", k),
+				Err:  err,
+			}
+		}
+		pem[k] = v
+	}
+	if _, err := tls.X509KeyPair([]byte(pem[certSecret]), []byte(pem[keySecret])); err != nil {
+		return "", "", &ierrors.Error{
+			Code: ierrors.EInvalid,
+			Msg:  "invalid client certificate",
+			Err:  err,
+		}
+	}
+
+	if len(secrets) > 0 {
+		if err := s.secrets.PatchSecrets(ctx, orgID, secrets); err != nil {
+			return "", "", err
+		}
+	}
+	return certSecret, keySecret, nil
+}
+
+// deleteClientCert deletes those of the given secrets which were created to hold the client
+// certificate and key of the remote with the given ID. Secrets referred to by key are left alone.
+func (s service) deleteClientCert(ctx context.Context, orgID, id platform.ID, secrets ...string) error {
+	certSecret, keySecret := clientCertSecretKeys(id)
+	var keys []string
+	for _, k := range secrets {
+		if k != "" && (k == certSecret || k == keySecret) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.secrets.DeleteSecret(ctx, orgID, keys...)
 }
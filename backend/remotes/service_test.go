@@ -2,10 +2,20 @@ package remotes
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/sqlite"
 	"github.com/influxdata/influxdb/v2/sqlite/migrations"
@@ -26,6 +36,7 @@ var (
 		RemoteURL:        "https://influxdb.cloud",
 		RemoteOrgID:      &remoteID,
 		AllowInsecureTLS: true,
+		Type:             influxdb.RemoteTypeInfluxDB,
 	}
 	fakeToken = "abcdefghijklmnop"
 	createReq = influxdb.CreateRemoteConnectionRequest{
@@ -51,6 +62,7 @@ var (
 		RemoteURL:        connection.RemoteURL,
 		RemoteOrgID:      connection.RemoteOrgID,
 		AllowInsecureTLS: *updateReq.AllowInsecureTLS,
+		Type:             connection.Type,
 	}
 )
 
@@ -122,6 +134,171 @@ func TestUpdateNoop(t *testing.T) {
 	require.Equal(t, updated, got)
 }
 
+func TestCreateConnectionSinks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("http defaults to line protocol", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		req := createReq
+		req.Type = influxdb.RemoteTypeHTTP
+		created, err := svc.CreateRemoteConnection(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, influxdb.RemoteTypeHTTP, created.Type)
+		require.Equal(t, influxdb.RemoteFormatLineProtocol, created.Format)
+	})
+
+	t.Run("kafka", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		req := createReq
+		req.Type = influxdb.RemoteTypeKafka
+		req.Format = influxdb.RemoteFormatJSON
+		req.Topic = "metrics"
+		created, err := svc.CreateRemoteConnection(ctx, req)
+		require.NoError(t, err)
+
+		got, err := svc.GetRemoteConnection(ctx, created.ID)
+		require.NoError(t, err)
+		require.Equal(t, influxdb.RemoteTypeKafka, got.Type)
+		require.Equal(t, influxdb.RemoteFormatJSON, got.Format)
+		require.Equal(t, "metrics", got.Topic)
+
+		// The topic can't be removed from a kafka remote.
+		empty := ""
+		_, err = svc.UpdateRemoteConnection(ctx, created.ID, influxdb.UpdateRemoteConnectionRequest{Topic: &empty})
+		require.Error(t, err)
+
+		topic := "metrics-v2"
+		updated, err := svc.UpdateRemoteConnection(ctx, created.ID, influxdb.UpdateRemoteConnectionRequest{Topic: &topic})
+		require.NoError(t, err)
+		require.Equal(t, topic, updated.Topic)
+	})
+
+	for _, req := range []influxdb.CreateRemoteConnectionRequest{
+		{Type: "ftp"},
+		{Type: influxdb.RemoteTypeInfluxDB, Format: influxdb.RemoteFormatJSON},
+		{Type: influxdb.RemoteTypeHTTP, Topic: "metrics"},
+		{Type: influxdb.RemoteTypeKafka},
+		{Type: influxdb.RemoteTypeKafka, Format: influxdb.RemoteFormatPrometheus, Topic: "metrics"},
+	} {
+		req := req
+		t.Run("invalid "+string(req.Type), func(t *testing.T) {
+			t.Parallel()
+
+			svc := newTestService(t)
+			r := createReq
+			r.Type, r.Format, r.Topic = req.Type, req.Format, req.Topic
+			_, err := svc.CreateRemoteConnection(ctx, r)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConnectionClientCert(t *testing.T) {
+	t.Parallel()
+
+	cert, key := testClientCert(t)
+
+	t.Run("inline values are stored as secrets", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		secrets := newTestSecrets()
+		svc.secrets = secrets.service()
+
+		req := createReq
+		req.CACert = cert
+		req.ServerName = "influxdb.internal"
+		req.ClientCert = influxdb.SecretField{Value: &cert}
+		req.ClientKey = influxdb.SecretField{Value: &key}
+		created, err := svc.CreateRemoteConnection(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, cert, created.CACert)
+		require.Equal(t, "influxdb.internal", created.ServerName)
+		require.Equal(t, initID.String()+"-client-cert", created.ClientCertSecret)
+		require.Equal(t, initID.String()+"-client-key", created.ClientKeySecret)
+		require.Equal(t, map[string]string{
+			created.ClientCertSecret: cert,
+			created.ClientKeySecret:  key,
+		}, secrets.get(connection.OrgID))
+
+		// Removing the certificate deletes the secrets created for it.
+		empty := influxdb.SecretField{}
+		updated, err := svc.UpdateRemoteConnection(ctx, created.ID, influxdb.UpdateRemoteConnectionRequest{
+			ClientCert: &empty,
+			ClientKey:  &empty,
+		})
+		require.NoError(t, err)
+		require.Empty(t, updated.ClientCertSecret)
+		require.Empty(t, updated.ClientKeySecret)
+		require.Empty(t, secrets.get(connection.OrgID))
+	})
+
+	t.Run("secret keys are referenced", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		secrets := newTestSecrets()
+		secrets.set(connection.OrgID, map[string]string{"cert": cert, "key": key})
+		svc.secrets = secrets.service()
+
+		req := createReq
+		req.ClientCert = influxdb.SecretField{Key: "cert"}
+		req.ClientKey = influxdb.SecretField{Key: "key"}
+		created, err := svc.CreateRemoteConnection(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, "cert", created.ClientCertSecret)
+		require.Equal(t, "key", created.ClientKeySecret)
+
+		// Secrets which weren't created for the remote are left alone when it is deleted.
+		require.NoError(t, svc.DeleteRemoteConnection(ctx, created.ID))
+		require.Len(t, secrets.get(connection.OrgID), 2)
+	})
+
+	t.Run("generated secrets are deleted with the remote", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		secrets := newTestSecrets()
+		svc.secrets = secrets.service()
+
+		req := createReq
+		req.ClientCert = influxdb.SecretField{Value: &cert}
+		req.ClientKey = influxdb.SecretField{Value: &key}
+		created, err := svc.CreateRemoteConnection(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, secrets.get(connection.OrgID), 2)
+
+		require.NoError(t, svc.DeleteRemoteConnection(ctx, created.ID))
+		require.Empty(t, secrets.get(connection.OrgID))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		svc := newTestService(t)
+		secrets := newTestSecrets()
+		svc.secrets = secrets.service()
+
+		other, _ := testClientCert(t)
+		for _, req := range []influxdb.CreateRemoteConnectionRequest{
+			{ClientCert: influxdb.SecretField{Value: &cert}},
+			{ClientCert: influxdb.SecretField{Value: &other}, ClientKey: influxdb.SecretField{Value: &key}},
+			{ClientCert: influxdb.SecretField{Key: "missing"}, ClientKey: influxdb.SecretField{Key: "missing"}},
+			{CACert: "not a certificate"},
+		} {
+			r := createReq
+			r.CACert, r.ClientCert, r.ClientKey = req.CACert, req.ClientCert, req.ClientKey
+			_, err := svc.CreateRemoteConnection(ctx, r)
+			require.Equal(t, ierrors.EInvalid, ierrors.ErrorCode(err))
+		}
+		require.Empty(t, secrets.get(connection.OrgID))
+	})
+}
+
 func TestDeleteConnection(t *testing.T) {
 	t.Parallel()
 
@@ -224,3 +401,81 @@ func newTestService(t *testing.T) *service {
 
 	return &svc
 }
+
+// testSecrets is an in-memory store of the secrets of organizations.
+type testSecrets struct {
+	mu      sync.Mutex
+	secrets map[platform.ID]map[string]string
+}
+
+func newTestSecrets() *testSecrets {
+	return &testSecrets{secrets: make(map[platform.ID]map[string]string)}
+}
+
+func (s *testSecrets) get(orgID platform.ID) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := make(map[string]string, len(s.secrets[orgID]))
+	for k, v := range s.secrets[orgID] {
+		m[k] = v
+	}
+	return m
+}
+
+func (s *testSecrets) set(orgID platform.ID, m map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secrets[orgID] == nil {
+		s.secrets[orgID] = make(map[string]string)
+	}
+	for k, v := range m {
+		s.secrets[orgID][k] = v
+	}
+}
+
+func (s *testSecrets) service() *mock.SecretService {
+	return &mock.SecretService{
+		LoadSecretFn: func(_ context.Context, orgID platform.ID, k string) (string, error) {
+			v, ok := s.get(orgID)[k]
+			if !ok {
+				return "", &ierrors.Error{Code: ierrors.ENotFound, Msg: "secret not found"}
+			}
+			return v, nil
+		},
+		PatchSecretsFn: func(_ context.Context, orgID platform.ID, m map[string]string) error {
+			s.set(orgID, m)
+			return nil
+		},
+		DeleteSecretFn: func(_ context.Context, orgID platform.ID, ks ...string) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			for _, k := range ks {
+				delete(s.secrets[orgID], k)
+			}
+			return nil
+		},
+	}
+}
+
+// testClientCert returns a PEM-encoded self-signed certificate and its key.
+func testClientCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "replication"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(cert), string(key)
+}
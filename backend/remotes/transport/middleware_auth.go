@@ -43,6 +43,11 @@ func (a authCheckingService) CreateRemoteConnection(ctx context.Context, request
 	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.RemotesResourceType, request.OrgID); err != nil {
 		return nil, err
 	}
+	if request.ClientCert.Key != "" || request.ClientCert.Value != nil {
+		if err := authorizeClientCert(ctx, request.OrgID); err != nil {
+			return nil, err
+		}
+	}
 
 	return a.underlying.CreateRemoteConnection(ctx, request)
 }
@@ -66,6 +71,11 @@ func (a authCheckingService) UpdateRemoteConnection(ctx context.Context, id plat
 	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.RemotesResourceType, id, r.OrgID); err != nil {
 		return nil, err
 	}
+	if request.ClientCert != nil || request.ClientKey != nil {
+		if err := authorizeClientCert(ctx, r.OrgID); err != nil {
+			return nil, err
+		}
+	}
 	return a.underlying.UpdateRemoteConnection(ctx, id, request)
 }
 
@@ -79,3 +89,13 @@ func (a authCheckingService) DeleteRemoteConnection(ctx context.Context, id plat
 	}
 	return a.underlying.DeleteRemoteConnection(ctx, id)
 }
+
+// authorizeClientCert checks that the client certificate of a remote may be set. The certificate
+// and key are stored in, or loaded from, the secrets of the organization.
+func authorizeClientCert(ctx context.Context, orgID platform.ID) error {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return err
+	}
+	_, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, orgID)
+	return err
+}
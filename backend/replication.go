@@ -1,7 +1,13 @@
 package influxdb
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -20,21 +26,25 @@ var ErrMaxQueueSizeTooSmall = errors.Error{
 
 // Replication contains all info about a replication that should be returned to users.
 type Replication struct {
-	ID                       platform.ID  `json:"id" db:"id"`
-	OrgID                    platform.ID  `json:"orgID" db:"org_id"`
-	Name                     string       `json:"name" db:"name"`
-	Description              *string      `json:"description,omitempty" db:"description"`
-	RemoteID                 platform.ID  `json:"remoteID" db:"remote_id"`
-	LocalBucketID            platform.ID  `json:"localBucketID" db:"local_bucket_id"`
-	RemoteBucketID           *platform.ID `json:"remoteBucketID" db:"remote_bucket_id"`
-	RemoteBucketName         string       `json:"RemoteBucketName" db:"remote_bucket_name"`
-	MaxQueueSizeBytes        int64        `json:"maxQueueSizeBytes" db:"max_queue_size_bytes"`
-	CurrentQueueSizeBytes    int64        `json:"currentQueueSizeBytes"`
-	RemainingBytesToBeSynced int64        `json:"remainingBytesToBeSynced"`
-	LatestResponseCode       *int32       `json:"latestResponseCode,omitempty" db:"latest_response_code"`
-	LatestErrorMessage       *string      `json:"latestErrorMessage,omitempty" db:"latest_error_message"`
-	DropNonRetryableData     bool         `json:"dropNonRetryableData" db:"drop_non_retryable_data"`
-	MaxAgeSeconds            int64        `json:"maxAgeSeconds" db:"max_age_seconds"`
+	ID                       platform.ID          `json:"id" db:"id"`
+	OrgID                    platform.ID          `json:"orgID" db:"org_id"`
+	Name                     string               `json:"name" db:"name"`
+	Description              *string              `json:"description,omitempty" db:"description"`
+	RemoteID                 platform.ID          `json:"remoteID" db:"remote_id"`
+	LocalBucketID            platform.ID          `json:"localBucketID" db:"local_bucket_id"`
+	RemoteBucketID           *platform.ID         `json:"remoteBucketID" db:"remote_bucket_id"`
+	RemoteBucketName         string               `json:"RemoteBucketName" db:"remote_bucket_name"`
+	MaxQueueSizeBytes        int64                `json:"maxQueueSizeBytes" db:"max_queue_size_bytes"`
+	CurrentQueueSizeBytes    int64                `json:"currentQueueSizeBytes"`
+	RemainingBytesToBeSynced int64                `json:"remainingBytesToBeSynced"`
+	LatestResponseCode       *int32               `json:"latestResponseCode,omitempty" db:"latest_response_code"`
+	LatestErrorMessage       *string              `json:"latestErrorMessage,omitempty" db:"latest_error_message"`
+	DropNonRetryableData     bool                 `json:"dropNonRetryableData" db:"drop_non_retryable_data"`
+	MaxAgeSeconds            int64                `json:"maxAgeSeconds" db:"max_age_seconds"`
+	Filter                   *ReplicationFilter   `json:"filter,omitempty" db:"filter"`
+	Backfill                 *ReplicationBackfill `json:"backfill,omitempty"`
+	// Paused replications keep queueing written data, but don't send it to their remote.
+	Paused bool `json:"paused" db:"paused"`
 }
 
 // ReplicationListFilter is a selection filter for listing replications.
@@ -56,21 +66,77 @@ type TrackedReplication struct {
 	MaxAgeSeconds     int64
 	OrgID             platform.ID
 	LocalBucketID     platform.ID
+	Paused            bool
+}
+
+// ReplicationOriginHeader is the header of the writes sent by a replication to an InfluxDB remote
+// carrying the replication path of the points: the comma-separated instance IDs of the instances
+// they were replicated through, starting with the one they were first written to.
+//
+// Points written with this header are stored locally and enqueued for the replications of the
+// bucket they are written to, with the local instance added to their path, unless the local
+// instance is already on it. This lets data be replicated along chains of instances without
+// loops: points are never enqueued for, or sent to, a remote whose RemoteInstanceID is already
+// on their path, so two instances replicating the same bucket to each other send each point
+// only once. The remote connections of a loop must have the RemoteInstanceID of their remote
+// set for this; a remote without one is sent the points back, and stores them without
+// replicating them further. Instances without an instance ID can't be put on a path, so they
+// don't replicate points written with this header.
+//
+// The header is only accepted with authorizations that can write the replications of the
+// organization written to, so that other clients can't keep their writes from being replicated.
+//
+// Replication does not resolve conflicts between instances. Like any other writes, a replicated
+// point overwrites the fields of the point with the same series key and timestamp, so the last
+// write to reach an instance wins. Points of different instances are kept apart by the
+// _instance_id tag added to replicated points by the instance they were first written to.
+const ReplicationOriginHeader = "X-Influxdb-Replication-Origin"
+
+// ParseReplicationPath returns the instance IDs of a ReplicationOriginHeader.
+func ParseReplicationPath(header string) []string {
+	var ids []string
+	for _, id := range strings.Split(header, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// FormatReplicationPath returns the ReplicationOriginHeader of the instance IDs ids.
+func FormatReplicationPath(ids []string) string {
+	return strings.Join(ids, ",")
+}
+
+type replicationPathContextKey struct{}
+
+// ContextWithReplicationPath marks the points written with the returned context as replicated
+// through the instances with the given IDs.
+func ContextWithReplicationPath(ctx context.Context, ids []string) context.Context {
+	return context.WithValue(ctx, replicationPathContextKey{}, ids)
+}
+
+// ReplicationPathFromContext returns the IDs of the instances the points written with ctx were
+// replicated through, if any.
+func ReplicationPathFromContext(ctx context.Context) ([]string, bool) {
+	ids, ok := ctx.Value(replicationPathContextKey{}).([]string)
+	return ids, ok
 }
 
 // CreateReplicationRequest contains all info needed to establish a new replication
 // to a remote InfluxDB bucket.
 type CreateReplicationRequest struct {
-	OrgID                platform.ID `json:"orgID"`
-	Name                 string      `json:"name"`
-	Description          *string     `json:"description,omitempty"`
-	RemoteID             platform.ID `json:"remoteID"`
-	LocalBucketID        platform.ID `json:"localBucketID"`
-	RemoteBucketID       platform.ID `json:"remoteBucketID"`
-	RemoteBucketName     string      `json:"remoteBucketName"`
-	MaxQueueSizeBytes    int64       `json:"maxQueueSizeBytes,omitempty"`
-	DropNonRetryableData bool        `json:"dropNonRetryableData,omitempty"`
-	MaxAgeSeconds        int64       `json:"maxAgeSeconds,omitempty"`
+	OrgID                platform.ID        `json:"orgID"`
+	Name                 string             `json:"name"`
+	Description          *string            `json:"description,omitempty"`
+	RemoteID             platform.ID        `json:"remoteID"`
+	LocalBucketID        platform.ID        `json:"localBucketID"`
+	RemoteBucketID       platform.ID        `json:"remoteBucketID"`
+	RemoteBucketName     string             `json:"remoteBucketName"`
+	MaxQueueSizeBytes    int64              `json:"maxQueueSizeBytes,omitempty"`
+	DropNonRetryableData bool               `json:"dropNonRetryableData,omitempty"`
+	MaxAgeSeconds        int64              `json:"maxAgeSeconds,omitempty"`
+	Filter               *ReplicationFilter `json:"filter,omitempty"`
 }
 
 func (r *CreateReplicationRequest) OK() error {
@@ -78,30 +144,208 @@ func (r *CreateReplicationRequest) OK() error {
 		return &ErrMaxQueueSizeTooSmall
 	}
 
-	return nil
+	return r.Filter.Validate()
 }
 
 // UpdateReplicationRequest contains a partial update to existing info about a replication.
 type UpdateReplicationRequest struct {
-	Name                 *string      `json:"name,omitempty"`
-	Description          *string      `json:"description,omitempty"`
-	RemoteID             *platform.ID `json:"remoteID,omitempty"`
-	RemoteBucketID       *platform.ID `json:"remoteBucketID,omitempty"`
-	RemoteBucketName     *string      `json:"remoteBucketName,omitempty"`
-	MaxQueueSizeBytes    *int64       `json:"maxQueueSizeBytes,omitempty"`
-	DropNonRetryableData *bool        `json:"dropNonRetryableData,omitempty"`
-	MaxAgeSeconds        *int64       `json:"maxAgeSeconds,omitempty"`
+	Name                 *string            `json:"name,omitempty"`
+	Description          *string            `json:"description,omitempty"`
+	RemoteID             *platform.ID       `json:"remoteID,omitempty"`
+	RemoteBucketID       *platform.ID       `json:"remoteBucketID,omitempty"`
+	RemoteBucketName     *string            `json:"remoteBucketName,omitempty"`
+	MaxQueueSizeBytes    *int64             `json:"maxQueueSizeBytes,omitempty"`
+	DropNonRetryableData *bool              `json:"dropNonRetryableData,omitempty"`
+	MaxAgeSeconds        *int64             `json:"maxAgeSeconds,omitempty"`
+	Filter               *ReplicationFilter `json:"filter,omitempty"`
 }
 
 func (r *UpdateReplicationRequest) OK() error {
-	if r.MaxQueueSizeBytes == nil {
+	if r.MaxQueueSizeBytes != nil && *r.MaxQueueSizeBytes < MinReplicationMaxQueueSizeBytes {
+		return &ErrMaxQueueSizeTooSmall
+	}
+
+	return r.Filter.Validate()
+}
+
+// ReplicationBackfillRequest asks for the data already stored in a replication's local bucket
+// between Start (inclusive) and Stop (exclusive) to be enqueued for replication. A zero Stop
+// backfills up to the time of the request.
+//
+// MaxPointsPerSecond limits the rate at which points are enqueued, so that a backfill doesn't
+// starve the replication of new writes; zero uses DefaultReplicationBackfillPointsPerSecond.
+type ReplicationBackfillRequest struct {
+	Start              time.Time `json:"start"`
+	Stop               time.Time `json:"stop,omitempty"`
+	MaxPointsPerSecond int       `json:"maxPointsPerSecond,omitempty"`
+}
+
+const DefaultReplicationBackfillPointsPerSecond = 10000
+
+func (r *ReplicationBackfillRequest) OK() error {
+	if r.Start.IsZero() {
+		return &errors.Error{Code: errors.EInvalid, Msg: "backfill requires a start time"}
+	}
+	if !r.Stop.IsZero() && !r.Start.Before(r.Stop) {
+		return &errors.Error{Code: errors.EInvalid, Msg: "backfill start must be before stop"}
+	}
+	if r.MaxPointsPerSecond < 0 {
+		return &errors.Error{Code: errors.EInvalid, Msg: "maxPointsPerSecond must not be negative"}
+	}
+	return nil
+}
+
+type ReplicationBackfillStatus string
+
+const (
+	ReplicationBackfillRunning   ReplicationBackfillStatus = "running"
+	ReplicationBackfillCompleted ReplicationBackfillStatus = "completed"
+	ReplicationBackfillCanceled  ReplicationBackfillStatus = "canceled"
+	ReplicationBackfillFailed    ReplicationBackfillStatus = "failed"
+)
+
+// ReplicationBackfill reports the progress of the latest backfill of a replication. Progress is
+// the time up to which the requested range has been enqueued. Backfills are not persisted, and
+// one that is running when the server stops is not resumed.
+type ReplicationBackfill struct {
+	Status             ReplicationBackfillStatus `json:"status"`
+	Start              time.Time                 `json:"start"`
+	Stop               time.Time                 `json:"stop"`
+	Progress           time.Time                 `json:"progress"`
+	PointsQueued       int64                     `json:"pointsQueued"`
+	MaxPointsPerSecond int                       `json:"maxPointsPerSecond"`
+	StartedAt          time.Time                 `json:"startedAt"`
+	FinishedAt         *time.Time                `json:"finishedAt,omitempty"`
+	Error              string                    `json:"error,omitempty"`
+}
+
+// ReplicationQueueBatch is a batch of data waiting in a replication queue.
+type ReplicationQueueBatch struct {
+	// Data is the batch decoded to line protocol.
+	Data      string `json:"data"`
+	Points    int    `json:"points"`
+	SizeBytes int    `json:"sizeBytes"`
+}
+
+// ReplicationFilter restricts the points written to a replication's local bucket that are
+// enqueued for replication. A point is replicated when it is selected by Include (or Include
+// is not set) and is not selected by Exclude.
+//
+// When a predicate lists field keys, it narrows the fields of the points it selects rather
+// than the points themselves: Include keeps only the listed fields, and Exclude removes them.
+// Points left without any fields are not replicated.
+type ReplicationFilter struct {
+	Include *ReplicationPredicate `json:"include,omitempty"`
+	Exclude *ReplicationPredicate `json:"exclude,omitempty"`
+}
+
+// ReplicationPredicate selects points by measurement name and tags. A point is selected when
+// its measurement matches any of Measurements and its tags satisfy all of Tags; empty lists
+// select every point.
+//
+// Measurement names, tag values and field keys are matched as patterns using the syntax of
+// path.Match, e.g. "prod_*".
+type ReplicationPredicate struct {
+	Measurements []string                `json:"measurements,omitempty"`
+	Tags         []ReplicationTagMatcher `json:"tags,omitempty"`
+	Fields       []string                `json:"fields,omitempty"`
+}
+
+// ReplicationTagMatcher matches points having the tag Key with a value matching the Value pattern.
+type ReplicationTagMatcher struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// IsEmpty reports whether the filter replicates every point unchanged.
+func (f *ReplicationFilter) IsEmpty() bool {
+	return f == nil || (f.Include.isEmpty() && f.Exclude.isEmpty())
+}
+
+// Validate returns an error if the filter contains an invalid pattern or tag matcher.
+func (f *ReplicationFilter) Validate() error {
+	if f == nil {
 		return nil
 	}
+	if err := f.Include.validate("include"); err != nil {
+		return err
+	}
+	return f.Exclude.validate("exclude")
+}
 
-	if *r.MaxQueueSizeBytes < MinReplicationMaxQueueSizeBytes {
-		return &ErrMaxQueueSizeTooSmall
+// Value implements driver.Valuer, storing the filter as JSON. Empty filters are stored as NULL.
+func (f ReplicationFilter) Value() (driver.Value, error) {
+	if f.IsEmpty() {
+		return nil, nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner, reading a filter stored as JSON.
+func (f *ReplicationFilter) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = ReplicationFilter{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case []byte:
+		return json.Unmarshal(v, f)
+	default:
+		return fmt.Errorf("cannot scan %T into a replication filter", src)
+	}
+}
+
+func (p *ReplicationPredicate) isEmpty() bool {
+	return p == nil || (len(p.Measurements) == 0 && len(p.Tags) == 0 && len(p.Fields) == 0)
+}
+
+func (p *ReplicationPredicate) validate(name string) error {
+	if p == nil {
+		return nil
+	}
+	for _, m := range p.Measurements {
+		if err := validateReplicationPattern(name, "measurement", m); err != nil {
+			return err
+		}
 	}
+	for _, t := range p.Tags {
+		if t.Key == "" {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("invalid %s filter: tag matcher requires a key", name),
+			}
+		}
+		if err := validateReplicationPattern(name, "tag value", t.Value); err != nil {
+			return err
+		}
+	}
+	for _, k := range p.Fields {
+		if err := validateReplicationPattern(name, "field", k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func validateReplicationPattern(filter, kind, pattern string) error {
+	if pattern == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s filter: empty %s pattern", filter, kind),
+		}
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s filter: bad %s pattern %q", filter, kind, pattern),
+			Err:  err,
+		}
+	}
 	return nil
 }
 
@@ -115,4 +359,22 @@ type ReplicationHTTPConfig struct {
 	RemoteBucketID       *platform.ID `db:"remote_bucket_id"`
 	RemoteBucketName     string       `db:"remote_bucket_name"`
 	DropNonRetryableData bool         `db:"drop_non_retryable_data"`
+	RemoteType           RemoteType   `db:"remote_type"`
+	RemoteFormat         RemoteFormat `db:"remote_format"`
+	RemoteTopic          string       `db:"remote_topic"`
+	OrgID                platform.ID  `db:"org_id"`
+	RemoteCACert         string       `db:"remote_ca_cert"`
+	RemoteServerName     string       `db:"remote_server_name"`
+	ClientCertSecret     string       `db:"client_cert_secret"`
+	ClientKeySecret      string       `db:"client_key_secret"`
+	RemoteInstanceID     string       `db:"remote_instance_id"`
+
+	// ReplicationPath is the replication path of the batch being sent, ending with the local
+	// instance, sent to remotes in the ReplicationOriginHeader of writes.
+	ReplicationPath []string `db:"-"`
+
+	// ClientCert and ClientKey are the PEM-encoded client certificate and key of the remote,
+	// loaded from the secrets named by ClientCertSecret and ClientKeySecret.
+	ClientCert string `db:"-"`
+	ClientKey  string `db:"-"`
 }
@@ -0,0 +1,132 @@
+package influxdb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationFilterValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter *influxdb.ReplicationFilter
+		msg    string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "valid",
+			filter: &influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{
+					Measurements: []string{"prod_*"},
+					Tags:         []influxdb.ReplicationTagMatcher{{Key: "env", Value: "prod"}},
+				},
+				Exclude: &influxdb.ReplicationPredicate{
+					Fields: []string{"debug_*"},
+				},
+			},
+		},
+		{
+			name: "bad measurement pattern",
+			filter: &influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_["}},
+			},
+			msg: `invalid include filter: bad measurement pattern "prod_["`,
+		},
+		{
+			name: "empty field pattern",
+			filter: &influxdb.ReplicationFilter{
+				Exclude: &influxdb.ReplicationPredicate{Fields: []string{""}},
+			},
+			msg: "invalid exclude filter: empty field pattern",
+		},
+		{
+			name: "tag without key",
+			filter: &influxdb.ReplicationFilter{
+				Exclude: &influxdb.ReplicationPredicate{
+					Tags: []influxdb.ReplicationTagMatcher{{Value: "prod"}},
+				},
+			},
+			msg: "invalid exclude filter: tag matcher requires a key",
+		},
+		{
+			name: "tag without value",
+			filter: &influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{
+					Tags: []influxdb.ReplicationTagMatcher{{Key: "env"}},
+				},
+			},
+			msg: "invalid include filter: empty tag value pattern",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.filter.Validate()
+			if c.msg == "" {
+				require.NoError(t, err)
+				return
+			}
+			var ierr *errors.Error
+			require.ErrorAs(t, err, &ierr)
+			require.Equal(t, errors.EInvalid, ierr.Code)
+			require.Equal(t, c.msg, ierr.Msg)
+		})
+	}
+}
+
+func TestReplicationFilterValueScan(t *testing.T) {
+	filter := influxdb.ReplicationFilter{
+		Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_*"}},
+	}
+
+	v, err := filter.Value()
+	require.NoError(t, err)
+	require.Equal(t, `{"include":{"measurements":["prod_*"]}}`, v)
+
+	var got influxdb.ReplicationFilter
+	require.NoError(t, got.Scan(v))
+	require.Equal(t, filter, got)
+
+	// Empty filters are stored as NULL.
+	v, err = influxdb.ReplicationFilter{Include: &influxdb.ReplicationPredicate{}}.Value()
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestReplicationBackfillRequestOK(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, (&influxdb.ReplicationBackfillRequest{Start: start}).OK())
+	require.NoError(t, (&influxdb.ReplicationBackfillRequest{Start: start, Stop: start.Add(time.Hour), MaxPointsPerSecond: 10}).OK())
+
+	for _, req := range []influxdb.ReplicationBackfillRequest{
+		{},
+		{Start: start, Stop: start},
+		{Start: start, MaxPointsPerSecond: -1},
+	} {
+		err := req.OK()
+		require.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	}
+}
+
+func TestReplicationPathContext(t *testing.T) {
+	_, ok := influxdb.ReplicationPathFromContext(context.Background())
+	require.False(t, ok)
+
+	path, ok := influxdb.ReplicationPathFromContext(influxdb.ContextWithReplicationPath(context.Background(), []string{"site-a", "site-b"}))
+	require.True(t, ok)
+	require.Equal(t, []string{"site-a", "site-b"}, path)
+}
+
+func TestReplicationPath(t *testing.T) {
+	require.Nil(t, influxdb.ParseReplicationPath(""))
+	require.Equal(t, []string{"site-a"}, influxdb.ParseReplicationPath("site-a"))
+	require.Equal(t, []string{"site-a", "site-b"}, influxdb.ParseReplicationPath(" site-a,,site-b "))
+	require.Equal(t, "site-a,site-b", influxdb.FormatReplicationPath([]string{"site-a", "site-b"}))
+}
@@ -0,0 +1,419 @@
+package replications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/durablequeue"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// backfillWindow is the span of time read from the local bucket at once. Progress is
+	// reported at the end of each window.
+	backfillWindow = time.Hour
+
+	// backfillQueueFullWait is how long a backfill waits for the replication queue to drain
+	// when it is full.
+	backfillQueueFullWait = time.Second
+)
+
+func errBackfillRunning(id platform.ID) error {
+	return &ierrors.Error{
+		Code: ierrors.EConflict,
+		Msg:  fmt.Sprintf("a backfill of replication %q is already running", id),
+	}
+}
+
+func errNoBackfillRunning(id platform.ID) error {
+	return &ierrors.Error{
+		Code: ierrors.ENotFound,
+		Msg:  fmt.Sprintf("no backfill of replication %q is running", id),
+	}
+}
+
+// backfill tracks the latest backfill of a replication.
+type backfill struct {
+	mu     sync.Mutex
+	status influxdb.ReplicationBackfill
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Status returns a copy of the backfill's current status.
+func (b *backfill) Status() *influxdb.ReplicationBackfill {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := b.status
+	return &status
+}
+
+func (b *backfill) running() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status.Status == influxdb.ReplicationBackfillRunning
+}
+
+func (b *backfill) queued(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status.PointsQueued += int64(n)
+}
+
+func (b *backfill) progress(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status.Progress = t
+}
+
+func (b *backfill) finish(status influxdb.ReplicationBackfillStatus, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now().UTC()
+	b.status.Status = status
+	b.status.FinishedAt = &now
+	if err != nil {
+		b.status.Error = err.Error()
+	}
+}
+
+// BackfillReplication starts enqueueing the data stored in the replication's local bucket during
+// the requested time range. The backfill runs in the background; its progress is reported on the
+// returned replication, and it can be stopped with CancelReplicationBackfill.
+func (s *service) BackfillReplication(ctx context.Context, id platform.ID, request influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error) {
+	if err := request.OK(); err != nil {
+		return nil, err
+	}
+
+	r, err := s.store.GetReplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := influxdb.ReplicationBackfill{
+		Status:             influxdb.ReplicationBackfillRunning,
+		Start:              request.Start.UTC(),
+		Stop:               request.Stop.UTC(),
+		Progress:           request.Start.UTC(),
+		MaxPointsPerSecond: request.MaxPointsPerSecond,
+		StartedAt:          time.Now().UTC(),
+	}
+	if request.Stop.IsZero() {
+		status.Stop = status.StartedAt
+	}
+	if !status.Start.Before(status.Stop) {
+		return nil, &ierrors.Error{Code: ierrors.EInvalid, Msg: "backfill start must be in the past"}
+	}
+	if status.MaxPointsPerSecond == 0 {
+		status.MaxPointsPerSecond = influxdb.DefaultReplicationBackfillPointsPerSecond
+	}
+
+	s.backfillsMu.Lock()
+	if b, ok := s.backfills[id]; ok && b.running() {
+		s.backfillsMu.Unlock()
+		return nil, errBackfillRunning(id)
+	}
+	bctx, cancel := context.WithCancel(context.Background())
+	b := &backfill{status: status, cancel: cancel, done: make(chan struct{})}
+	if s.backfills == nil {
+		s.backfills = make(map[platform.ID]*backfill)
+	}
+	s.backfills[id] = b
+	s.backfillsMu.Unlock()
+
+	go s.runBackfill(bctx, r, b)
+
+	return s.GetReplication(ctx, id)
+}
+
+// CancelReplicationBackfill stops the running backfill of the replication. Data which was
+// already enqueued is still replicated.
+func (s *service) CancelReplicationBackfill(ctx context.Context, id platform.ID) error {
+	if _, err := s.store.GetReplication(ctx, id); err != nil {
+		return err
+	}
+
+	s.backfillsMu.Lock()
+	b, ok := s.backfills[id]
+	s.backfillsMu.Unlock()
+	if !ok || !b.running() {
+		return errNoBackfillRunning(id)
+	}
+
+	b.cancel()
+	<-b.done
+	return nil
+}
+
+// backfillStatus returns the status of the latest backfill of the replication, if any.
+func (s *service) backfillStatus(id platform.ID) *influxdb.ReplicationBackfill {
+	s.backfillsMu.Lock()
+	b, ok := s.backfills[id]
+	s.backfillsMu.Unlock()
+	if !ok {
+		return nil
+	}
+	return b.Status()
+}
+
+// stopBackfill cancels the backfill of a replication which is being deleted, and forgets it.
+func (s *service) stopBackfill(id platform.ID) {
+	s.backfillsMu.Lock()
+	b, ok := s.backfills[id]
+	delete(s.backfills, id)
+	s.backfillsMu.Unlock()
+	if ok {
+		b.cancel()
+		<-b.done
+	}
+}
+
+// stopBackfills cancels all running backfills.
+func (s *service) stopBackfills() {
+	s.backfillsMu.Lock()
+	bs := make([]*backfill, 0, len(s.backfills))
+	for _, b := range s.backfills {
+		bs = append(bs, b)
+	}
+	s.backfillsMu.Unlock()
+
+	for _, b := range bs {
+		b.cancel()
+		<-b.done
+	}
+}
+
+func (s *service) runBackfill(ctx context.Context, r *influxdb.Replication, b *backfill) {
+	defer close(b.done)
+	log := s.log.With(zap.String("replication_id", r.ID.String()))
+
+	err := s.backfill(ctx, r, b)
+	switch {
+	case ctx.Err() != nil:
+		b.finish(influxdb.ReplicationBackfillCanceled, nil)
+		log.Info("Replication backfill canceled")
+	case err != nil:
+		b.finish(influxdb.ReplicationBackfillFailed, err)
+		log.Error("Replication backfill failed", zap.Error(err))
+	default:
+		b.finish(influxdb.ReplicationBackfillCompleted, nil)
+		log.Info("Replication backfill completed", zap.Int64("points", b.Status().PointsQueued))
+	}
+}
+
+// backfill reads the backfill's time range from the local bucket one window at a time, and
+// enqueues the points at the backfill's rate.
+func (s *service) backfill(ctx context.Context, r *influxdb.Replication, b *backfill) error {
+	status := b.Status()
+
+	// Batches must fit within the limiter's burst, so a slow rate also means smaller batches.
+	batchSize := s.maxRemoteWritePointSize
+	if status.MaxPointsPerSecond < batchSize {
+		batchSize = status.MaxPointsPerSecond
+	}
+	limiter := rate.NewLimiter(rate.Limit(status.MaxPointsPerSecond), batchSize)
+
+	// Backfilled points are sent as if they were written locally.
+	var path []string
+	if s.instanceID != "" {
+		path = []string{s.instanceID}
+	}
+	enqueue := func(points []models.Point) error {
+		// Use the replication's current filter, in case it's updated during the backfill.
+		if f := s.filterFor(r.ID); f != nil {
+			var err error
+			if points, err = f.Filter(points); err != nil {
+				return err
+			}
+		}
+		if len(points) == 0 {
+			return nil
+		}
+
+		batches, err := s.serializeBatches(points, path)
+		if err != nil {
+			return err
+		}
+		for _, batch := range batches {
+			if err := limiter.WaitN(ctx, batch.numPoints); err != nil {
+				return err
+			}
+			if err := s.enqueueBackfill(ctx, r.ID, batch); err != nil {
+				return err
+			}
+			b.queued(batch.numPoints)
+		}
+		return nil
+	}
+
+	for start := status.Start; start.Before(status.Stop); {
+		end := start.Add(backfillWindow)
+		if end.After(status.Stop) {
+			end = status.Stop
+		}
+		if err := s.readBackfillWindow(ctx, r, start, end, batchSize, enqueue); err != nil {
+			return err
+		}
+		b.progress(end)
+		start = end
+	}
+	return nil
+}
+
+// enqueueBackfill enqueues a batch of backfilled data, waiting for the replication queue to
+// drain when it is full rather than dropping the data.
+func (s *service) enqueueBackfill(ctx context.Context, id platform.ID, b *batch) error {
+	for {
+		err := s.durableQueueManager.EnqueueData(id, b.data.Bytes(), b.numPoints)
+		if !errors.Is(err, durablequeue.ErrQueueFull) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backfillQueueFullWait):
+		}
+	}
+}
+
+// readBackfillWindow reads the points stored in the replication's local bucket between start
+// (inclusive) and end (exclusive), and passes them to fn in batches of at most batchSize.
+func (s *service) readBackfillWindow(ctx context.Context, r *influxdb.Replication, start, end time.Time, batchSize int, fn func([]models.Point) error) error {
+	src, err := anypb.New(s.readsStore.GetSource(uint64(r.OrgID), uint64(r.LocalBucketID)))
+	if err != nil {
+		return err
+	}
+	rs, err := s.readsStore.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+		ReadSource: src,
+		Range:      &datatypes.TimestampRange{Start: start.UnixNano(), End: end.UnixNano()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read local bucket %q: %w", r.LocalBucketID, err)
+	}
+	if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	points := make([]models.Point, 0, batchSize)
+	add := func(name string, tags models.Tags, field string, ts int64, v interface{}) error {
+		p, err := models.NewPoint(name, tags, models.Fields{field: v}, time.Unix(0, ts))
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+		if len(points) < batchSize {
+			return nil
+		}
+		err = fn(points)
+		points = make([]models.Point, 0, batchSize)
+		return err
+	}
+
+	for rs.Next() {
+		cur := rs.Cursor()
+		if cur == nil {
+			continue
+		}
+
+		var name, field string
+		tags := make(models.Tags, 0, len(rs.Tags()))
+		hasInstanceID := false
+		for _, t := range rs.Tags() {
+			switch {
+			case bytes.Equal(t.Key, models.MeasurementTagKeyBytes):
+				name = string(t.Value)
+			case bytes.Equal(t.Key, models.FieldKeyTagKeyBytes):
+				field = string(t.Value)
+			default:
+				hasInstanceID = hasInstanceID || string(t.Key) == "_instance_id"
+				tags = append(tags, t.Clone())
+			}
+		}
+		// Match the tagging of points replicated as they're written.
+		if s.instanceID != "" && !hasInstanceID {
+			tags = append(tags, models.NewTag([]byte("_instance_id"), []byte(s.instanceID)))
+			sort.Sort(tags)
+		}
+
+		err := readCursor(cur, func(ts int64, v interface{}) error {
+			return add(name, tags, field, ts, v)
+		})
+		cur.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+
+	if len(points) > 0 {
+		return fn(points)
+	}
+	return nil
+}
+
+// readCursor calls fn with the timestamp and value of every entry of the cursor.
+func readCursor(cur cursors.Cursor, fn func(ts int64, v interface{}) error) error {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				if err := fn(a.Timestamps[i], a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				if err := fn(a.Timestamps[i], a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				if err := fn(a.Timestamps[i], a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				if err := fn(a.Timestamps[i], a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				if err := fn(a.Timestamps[i], a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported cursor type %T", cur)
+	}
+	return cur.Err()
+}
@@ -0,0 +1,261 @@
+package replications
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/durablequeue"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+var backfillStart = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestBackfillReplication(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	svc.readsStore = backfillReadsStore(t)
+	expectGetReplication(mocks, replication1)
+
+	// The points stored in each hour of the range are enqueued in turn.
+	var enqueued []models.Point
+	mocks.durableQueueManager.EXPECT().
+		EnqueueData(replication1.ID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ platform.ID, data []byte, numPoints int) error {
+			enqueued = append(enqueued, decompressPoints(t, data)...)
+			return nil
+		}).Times(2)
+
+	r, err := svc.BackfillReplication(ctx, id1, influxdb.ReplicationBackfillRequest{
+		Start: backfillStart,
+		Stop:  backfillStart.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, influxdb.ReplicationBackfillRunning, r.Backfill.Status)
+	require.Equal(t, influxdb.DefaultReplicationBackfillPointsPerSecond, r.Backfill.MaxPointsPerSecond)
+
+	waitForBackfill(t, svc, id1)
+
+	want, err := models.ParsePointsString(`
+cpu,host=A usage=1.5 1640995200000000000
+cpu,host=B usage=2.5 1640995200000000000
+cpu,host=A usage=3.5 1640998800000000000
+cpu,host=B usage=4.5 1640998800000000000`)
+	require.NoError(t, err)
+	require.Equal(t, pointStrings(want), pointStrings(enqueued))
+
+	status := svc.backfillStatus(id1)
+	require.Equal(t, influxdb.ReplicationBackfillCompleted, status.Status)
+	require.Equal(t, backfillStart.Add(2*time.Hour), status.Progress)
+	require.Equal(t, int64(4), status.PointsQueued)
+	require.NotNil(t, status.FinishedAt)
+}
+
+func TestBackfillReplication_Filtered(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	svc.readsStore = backfillReadsStore(t)
+	svc.setFilter(id1, &influxdb.ReplicationFilter{
+		Exclude: &influxdb.ReplicationPredicate{
+			Tags: []influxdb.ReplicationTagMatcher{{Key: "host", Value: "B"}},
+		},
+	})
+	expectGetReplication(mocks, replication1)
+
+	var enqueued []models.Point
+	mocks.durableQueueManager.EXPECT().
+		EnqueueData(replication1.ID, gomock.Any(), 1).
+		DoAndReturn(func(_ platform.ID, data []byte, numPoints int) error {
+			enqueued = append(enqueued, decompressPoints(t, data)...)
+			return nil
+		})
+
+	_, err := svc.BackfillReplication(ctx, id1, influxdb.ReplicationBackfillRequest{
+		Start: backfillStart,
+		Stop:  backfillStart.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	waitForBackfill(t, svc, id1)
+
+	require.Equal(t, []string{"cpu,host=A usage=1.5 1640995200000000000"}, pointStrings(enqueued))
+	require.Equal(t, int64(1), svc.backfillStatus(id1).PointsQueued)
+}
+
+func TestBackfillReplication_Cancel(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	svc.readsStore = backfillReadsStore(t)
+	expectGetReplication(mocks, replication1)
+
+	// A full queue holds the backfill up until it's canceled.
+	full := make(chan struct{}, 1)
+	mocks.durableQueueManager.EXPECT().
+		EnqueueData(replication1.ID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(platform.ID, []byte, int) error {
+			select {
+			case full <- struct{}{}:
+			default:
+			}
+			return durablequeue.ErrQueueFull
+		}).MinTimes(1)
+
+	req := influxdb.ReplicationBackfillRequest{Start: backfillStart, Stop: backfillStart.Add(2 * time.Hour)}
+	_, err := svc.BackfillReplication(ctx, id1, req)
+	require.NoError(t, err)
+	<-full
+
+	// Only one backfill of a replication can run at a time.
+	_, err = svc.BackfillReplication(ctx, id1, req)
+	require.Equal(t, ierrors.EConflict, ierrors.ErrorCode(err))
+
+	require.NoError(t, svc.CancelReplicationBackfill(ctx, id1))
+	status := svc.backfillStatus(id1)
+	require.Equal(t, influxdb.ReplicationBackfillCanceled, status.Status)
+	require.Equal(t, backfillStart, status.Progress)
+	require.Zero(t, status.PointsQueued)
+
+	// There is nothing left to cancel.
+	require.Equal(t, ierrors.ENotFound, ierrors.ErrorCode(svc.CancelReplicationBackfill(ctx, id1)))
+}
+
+func TestBackfillReplication_Invalid(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newTestService(t)
+
+	_, err := svc.BackfillReplication(ctx, id1, influxdb.ReplicationBackfillRequest{
+		Start: backfillStart,
+		Stop:  backfillStart.Add(-time.Hour),
+	})
+	require.Equal(t, ierrors.EInvalid, ierrors.ErrorCode(err))
+}
+
+func expectGetReplication(mocks mocks, r influxdb.Replication) {
+	mocks.serviceStore.EXPECT().GetReplication(gomock.Any(), r.ID).
+		DoAndReturn(func(context.Context, platform.ID) (*influxdb.Replication, error) {
+			rr := r
+			return &rr, nil
+		}).AnyTimes()
+	mocks.durableQueueManager.EXPECT().CurrentQueueSizes([]platform.ID{r.ID}).
+		Return(map[platform.ID]int64{r.ID: 0}, nil).AnyTimes()
+	mocks.durableQueueManager.EXPECT().RemainingQueueSizes([]platform.ID{r.ID}).
+		Return(map[platform.ID]int64{r.ID: 0}, nil).AnyTimes()
+}
+
+func waitForBackfill(t *testing.T, svc *service, id platform.ID) {
+	t.Helper()
+
+	svc.backfillsMu.Lock()
+	b := svc.backfills[id]
+	svc.backfillsMu.Unlock()
+	require.NotNil(t, b)
+
+	select {
+	case <-b.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("backfill did not finish")
+	}
+}
+
+// backfillReadsStore returns a reads.Store with a float point for hosts A and B at the start
+// of every hour.
+func backfillReadsStore(t *testing.T) reads.Store {
+	return &mock.ReadsStore{
+		GetSourceFn: func(orgID, bucketID uint64) proto.Message {
+			require.Equal(t, uint64(replication1.OrgID), orgID)
+			require.Equal(t, uint64(replication1.LocalBucketID), bucketID)
+			return &datatypes.TimestampRange{}
+		},
+		ReadFilterFn: func(_ context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+			start, end := req.GetRange().GetStart(), req.GetRange().GetEnd()
+			hour := (start - backfillStart.UnixNano()) / int64(time.Hour)
+			rs := &backfillResultSet{}
+			for i, host := range []string{"A", "B"} {
+				ts := backfillStart.UnixNano() + hour*int64(time.Hour)
+				if ts < start || ts >= end {
+					continue
+				}
+				rs.series = append(rs.series, backfillSeries{
+					tags: models.NewTags(map[string]string{
+						models.MeasurementTagKey: "cpu",
+						"host":                   host,
+						models.FieldKeyTagKey:    "usage",
+					}),
+					values: &cursors.FloatArray{
+						Timestamps: []int64{ts},
+						Values:     []float64{float64(2*hour) + float64(i) + 1.5},
+					},
+				})
+			}
+			return rs, nil
+		},
+	}
+}
+
+type backfillSeries struct {
+	tags   models.Tags
+	values *cursors.FloatArray
+}
+
+type backfillResultSet struct {
+	series []backfillSeries
+	cur    backfillSeries
+}
+
+func (rs *backfillResultSet) Next() bool {
+	if len(rs.series) == 0 {
+		return false
+	}
+	rs.cur, rs.series = rs.series[0], rs.series[1:]
+	return true
+}
+
+func (rs *backfillResultSet) Cursor() cursors.Cursor     { return &floatCursor{a: rs.cur.values} }
+func (rs *backfillResultSet) Tags() models.Tags          { return rs.cur.tags }
+func (rs *backfillResultSet) Close()                     {}
+func (rs *backfillResultSet) Err() error                 { return nil }
+func (rs *backfillResultSet) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type floatCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *floatCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+func (c *floatCursor) Close()                     {}
+func (c *floatCursor) Err() error                 { return nil }
+func (c *floatCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func decompressPoints(t *testing.T, data []byte) []models.Point {
+	t.Helper()
+
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer gzr.Close()
+
+	lp, err := io.ReadAll(gzr)
+	require.NoError(t, err)
+	points, err := models.ParsePoints(lp)
+	require.NoError(t, err)
+	return points
+}
@@ -0,0 +1,106 @@
+package replications
+
+import (
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// pointFilter applies an influxdb.ReplicationFilter to the points written to a local bucket.
+type pointFilter struct {
+	include *influxdb.ReplicationPredicate
+	exclude *influxdb.ReplicationPredicate
+}
+
+// newPointFilter returns a pointFilter for f, or nil if f replicates every point unchanged.
+// The filter is expected to have been validated.
+func newPointFilter(f *influxdb.ReplicationFilter) *pointFilter {
+	if f.IsEmpty() {
+		return nil
+	}
+	return &pointFilter{include: f.Include, exclude: f.Exclude}
+}
+
+// Filter returns the points which should be replicated. Points whose fields are narrowed by
+// the filter are copied; the input slice is never modified.
+func (f *pointFilter) Filter(points []models.Point) ([]models.Point, error) {
+	filtered := make([]models.Point, 0, len(points))
+	for _, p := range points {
+		name := string(p.Name())
+		tags := p.Tags()
+
+		var keepFields, dropFields []string
+		if f.include != nil {
+			if !selects(f.include, name, tags) {
+				continue
+			}
+			keepFields = f.include.Fields
+		}
+		if f.exclude != nil && selects(f.exclude, name, tags) {
+			if len(f.exclude.Fields) == 0 {
+				continue
+			}
+			dropFields = f.exclude.Fields
+		}
+
+		if len(keepFields) == 0 && len(dropFields) == 0 {
+			filtered = append(filtered, p)
+			continue
+		}
+
+		// Fields returns the point's cached fields, so build a new map rather than modifying it.
+		all, err := p.Fields()
+		if err != nil {
+			return nil, err
+		}
+		fields := make(models.Fields, len(all))
+		for k, v := range all {
+			if (len(keepFields) > 0 && !matchesAny(keepFields, k)) || matchesAny(dropFields, k) {
+				continue
+			}
+			fields[k] = v
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == len(all) {
+			filtered = append(filtered, p)
+			continue
+		}
+
+		np, err := models.NewPoint(name, tags, fields, p.Time())
+		if err != nil {
+			return nil, err
+		}
+		filtered = append(filtered, np)
+	}
+	return filtered, nil
+}
+
+// selects reports whether the point's measurement matches any of the predicate's measurements,
+// and its tags satisfy all of the predicate's tag matchers.
+func selects(p *influxdb.ReplicationPredicate, name string, tags models.Tags) bool {
+	if len(p.Measurements) > 0 && !matchesAny(p.Measurements, name) {
+		return false
+	}
+	for _, m := range p.Tags {
+		v := tags.Get([]byte(m.Key))
+		if v == nil {
+			return false
+		}
+		if ok, _ := path.Match(m.Value, string(v)); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package replications
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointFilter(t *testing.T) {
+	t.Parallel()
+
+	points, err := models.ParsePointsString(`
+prod_cpu,env=prod,host=A usage=1.5,debug_id=7i 1000000000
+prod_cpu,env=staging,host=B usage=2.5,debug_id=8i 2000000000
+prod_mem,env=prod,host=A used=3i 3000000000
+dev_cpu,env=prod,host=A usage=4.5 4000000000
+prod_disk,env=prod,host=C debug_id=9i 5000000000`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter influxdb.ReplicationFilter
+		want   string
+	}{
+		{
+			name: "include measurements",
+			filter: influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_*"}},
+			},
+			want: `
+prod_cpu,env=prod,host=A usage=1.5,debug_id=7i 1000000000
+prod_cpu,env=staging,host=B usage=2.5,debug_id=8i 2000000000
+prod_mem,env=prod,host=A used=3i 3000000000
+prod_disk,env=prod,host=C debug_id=9i 5000000000`,
+		},
+		{
+			name: "include measurements and tags",
+			filter: influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{
+					Measurements: []string{"prod_cpu", "dev_cpu"},
+					Tags:         []influxdb.ReplicationTagMatcher{{Key: "env", Value: "prod"}},
+				},
+			},
+			want: `
+prod_cpu,env=prod,host=A usage=1.5,debug_id=7i 1000000000
+dev_cpu,env=prod,host=A usage=4.5 4000000000`,
+		},
+		{
+			name: "include fields",
+			filter: influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{
+					Measurements: []string{"*_cpu"},
+					Fields:       []string{"usage"},
+				},
+			},
+			want: `
+prod_cpu,env=prod,host=A usage=1.5 1000000000
+prod_cpu,env=staging,host=B usage=2.5 2000000000
+dev_cpu,env=prod,host=A usage=4.5 4000000000`,
+		},
+		{
+			name: "exclude tags",
+			filter: influxdb.ReplicationFilter{
+				Exclude: &influxdb.ReplicationPredicate{
+					Tags: []influxdb.ReplicationTagMatcher{{Key: "host", Value: "[AC]"}},
+				},
+			},
+			want: `
+prod_cpu,env=staging,host=B usage=2.5,debug_id=8i 2000000000`,
+		},
+		{
+			name: "exclude fields drops points left without fields",
+			filter: influxdb.ReplicationFilter{
+				Exclude: &influxdb.ReplicationPredicate{Fields: []string{"debug_*"}},
+			},
+			want: `
+prod_cpu,env=prod,host=A usage=1.5 1000000000
+prod_cpu,env=staging,host=B usage=2.5 2000000000
+prod_mem,env=prod,host=A used=3i 3000000000
+dev_cpu,env=prod,host=A usage=4.5 4000000000`,
+		},
+		{
+			name: "include and exclude",
+			filter: influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_*"}},
+				Exclude: &influxdb.ReplicationPredicate{
+					Tags: []influxdb.ReplicationTagMatcher{{Key: "env", Value: "staging"}},
+				},
+			},
+			want: `
+prod_cpu,env=prod,host=A usage=1.5,debug_id=7i 1000000000
+prod_mem,env=prod,host=A used=3i 3000000000
+prod_disk,env=prod,host=C debug_id=9i 5000000000`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPointFilter(&tt.filter)
+			require.NotNil(t, f)
+
+			got, err := f.Filter(points)
+			require.NoError(t, err)
+
+			want, err := models.ParsePointsString(tt.want)
+			require.NoError(t, err)
+			require.Equal(t, pointStrings(want), pointStrings(got))
+		})
+	}
+
+	// The input points must be left untouched.
+	fields, err := points[0].Fields()
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+}
+
+func TestNewPointFilter_Empty(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newPointFilter(nil))
+	require.Nil(t, newPointFilter(&influxdb.ReplicationFilter{}))
+	require.Nil(t, newPointFilter(&influxdb.ReplicationFilter{Exclude: &influxdb.ReplicationPredicate{}}))
+}
+
+func pointStrings(points []models.Point) []string {
+	s := make([]string, len(points))
+	for i, p := range points {
+		s[i] = p.String()
+	}
+	return s
+}
@@ -9,10 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/pkg/durablequeue"
 	"github.com/influxdata/influxdb/v2/replications/metrics"
 	"github.com/influxdata/influxdb/v2/replications/remotewrite"
@@ -23,8 +25,17 @@ const (
 	scannerAdvanceInterval = 10 * time.Second
 	purgeInterval          = 60 * time.Second
 	defaultMaxAge          = 7 * 24 * time.Hour // 1 week
+
+	// deadLetterDir is the directory, within the directory of a replication queue, of the queue
+	// holding the batches moved out of the way of the replication.
+	deadLetterDir = "deadletter"
 )
 
+var errQueueNotPaused = &ierrors.Error{
+	Code: ierrors.EConflict,
+	Msg:  "replication must be paused to change the head of its queue",
+}
+
 type remoteWriter interface {
 	Write(data []byte, attempt int) (time.Duration, error)
 }
@@ -42,6 +53,17 @@ type replicationQueue struct {
 	remoteWriter  remoteWriter
 	failedWrites  int
 	maxAge        time.Duration
+	maxSize       int64
+
+	// paused replication queues don't send their data. mu guards the queue and failedWrites,
+	// but isn't held during remote writes. Manual changes to the head of the queue bump
+	// generation, so that a send in progress discards its scanner instead of advancing it.
+	paused     atomic.Bool
+	mu         sync.Mutex
+	generation uint64
+
+	// deadLetter is opened when the first batch is moved to it.
+	deadLetter *durablequeue.Queue
 }
 
 type durableQueueManager struct {
@@ -51,14 +73,15 @@ type durableQueueManager struct {
 	mutex             sync.RWMutex
 	metrics           *metrics.ReplicationsMetrics
 	configStore       remotewrite.HttpConfigStore
+	instanceID        string
 }
 
 var errStartup = errors.New("startup tasks for replications durable queue management failed, see server logs for details")
 var errShutdown = errors.New("shutdown tasks for replications durable queues failed, see server logs for details")
 
 // NewDurableQueueManager creates a new durableQueueManager struct, for managing durable queues associated with
-// replication streams.
-func NewDurableQueueManager(log *zap.Logger, queuePath string, metrics *metrics.ReplicationsMetrics, configStore remotewrite.HttpConfigStore) *durableQueueManager {
+// replication streams. The instanceID identifies the local instance to the remotes.
+func NewDurableQueueManager(log *zap.Logger, queuePath string, metrics *metrics.ReplicationsMetrics, configStore remotewrite.HttpConfigStore, instanceID string) *durableQueueManager {
 	replicationQueues := make(map[platform.ID]*replicationQueue)
 
 	os.MkdirAll(queuePath, 0777)
@@ -69,6 +92,7 @@ func NewDurableQueueManager(log *zap.Logger, queuePath string, metrics *metrics.
 		queuePath:         queuePath,
 		metrics:           metrics,
 		configStore:       configStore,
+		instanceID:        instanceID,
 	}
 }
 
@@ -113,7 +137,7 @@ func (qm *durableQueueManager) InitializeQueue(replicationID platform.ID, maxQue
 	}
 
 	// Map new durable queue and scanner to its corresponding replication stream via replication ID
-	rq := qm.newReplicationQueue(replicationID, orgID, localBucketID, newQueue, maxAgeSeconds)
+	rq := qm.newReplicationQueue(replicationID, orgID, localBucketID, newQueue, maxAgeSeconds, maxQueueSizeBytes)
 	qm.replicationQueues[replicationID] = rq
 	rq.Open()
 
@@ -132,6 +156,11 @@ func (rq *replicationQueue) Close() error {
 	close(rq.receive)
 	close(rq.done)
 	rq.wg.Wait() // wait for goroutine to finish processing all messages
+	if rq.deadLetter != nil {
+		if err := rq.deadLetter.Close(); err != nil {
+			return err
+		}
+	}
 	return rq.queue.Close()
 }
 
@@ -178,7 +207,9 @@ func (rq *replicationQueue) run() {
 			retry.Reset(retryTime)
 		case <-purgeTicker.C:
 			if rq.maxAge != 0 {
+				rq.mu.Lock()
 				rq.queue.PurgeOlderThan(time.Now().Add(-rq.maxAge))
+				rq.mu.Unlock()
 			}
 		}
 	}
@@ -186,7 +217,17 @@ func (rq *replicationQueue) run() {
 
 // SendWrite processes data enqueued into the durablequeue.Queue.
 // SendWrite is responsible for processing all data in the queue at the time of calling.
+// rq.mu is only held while reading and advancing the queue, so that manual changes to the
+// queue aren't blocked by slow remotes.
 func (rq *replicationQueue) SendWrite() (waitForRetry time.Duration, shouldRetry bool) {
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+
+	// Resuming the queue restarts sending.
+	if rq.paused.Load() {
+		return 0, false
+	}
+
 	// Any error in creating the scanner should exit the loop in run()
 	// Either it is io.EOF indicating no data, or some other failure in making
 	// the Scanner object that we don't know how to handle.
@@ -197,6 +238,7 @@ func (rq *replicationQueue) SendWrite() (waitForRetry time.Duration, shouldRetry
 		}
 		return 0, false
 	}
+	generation := rq.generation
 
 	advanceScanner := func() error {
 		if _, err = scan.Advance(); err != nil {
@@ -223,7 +265,18 @@ func (rq *replicationQueue) SendWrite() (waitForRetry time.Duration, shouldRetry
 			rq.logger.Info("Segment read error.", zap.Error(scan.Err()))
 		}
 
-		if waitForRetry, err := rq.remoteWriter.Write(scan.Bytes(), rq.failedWrites); err != nil {
+		data, failedWrites := scan.Bytes(), rq.failedWrites
+		rq.mu.Unlock()
+		waitForRetry, err := rq.remoteWriter.Write(data, failedWrites)
+		rq.mu.Lock()
+
+		// The head of the queue was skipped or purged during the write, so the position of
+		// the scanner no longer applies; start again from the new head.
+		if rq.generation != generation {
+			return 0, true
+		}
+
+		if err != nil {
 			rq.failedWrites++
 			// We failed the remote write. Do not advance the scanner
 			rq.logger.Error("Error in replication stream", zap.Error(err), zap.Int("retries", rq.failedWrites))
@@ -233,6 +286,12 @@ func (rq *replicationQueue) SendWrite() (waitForRetry time.Duration, shouldRetry
 		// a successful write resets the number of failed write attempts to zero
 		rq.failedWrites = 0
 
+		// Stop after the write in progress when the queue is paused.
+		if rq.paused.Load() {
+			_ = advanceScanner()
+			return 0, false
+		}
+
 		// Advance the scanner periodically to prevent extended runs of local writes without updating the underlying queue
 		// position.
 		select {
@@ -292,9 +351,19 @@ func (qm *durableQueueManager) UpdateMaxQueueSize(replicationID platform.ID, max
 		return fmt.Errorf("durable queue not found for replication ID %q", replicationID)
 	}
 
-	if err := qm.replicationQueues[replicationID].queue.SetMaxSize(maxQueueSizeBytes); err != nil {
+	rq := qm.replicationQueues[replicationID]
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+
+	if err := rq.queue.SetMaxSize(maxQueueSizeBytes); err != nil {
 		return err
 	}
+	if rq.deadLetter != nil {
+		if err := rq.deadLetter.SetMaxSize(maxQueueSizeBytes); err != nil {
+			return err
+		}
+	}
+	rq.maxSize = maxQueueSizeBytes
 
 	return nil
 }
@@ -376,7 +445,8 @@ func (qm *durableQueueManager) StartReplicationQueues(trackedReplications map[pl
 						continue
 					}
 
-					qm.replicationQueues[id] = qm.newReplicationQueue(id, repl.OrgID, repl.LocalBucketID, queue, repl.MaxAgeSeconds)
+					qm.replicationQueues[id] = qm.newReplicationQueue(id, repl.OrgID, repl.LocalBucketID, queue, repl.MaxAgeSeconds, repl.MaxQueueSizeBytes)
+					qm.replicationQueues[id].paused.Store(repl.Paused)
 					qm.replicationQueues[id].Open()
 					qm.logger.Info("Opened replication stream", zap.String("id", id.String()), zap.String("path", queue.Dir()))
 				}
@@ -385,7 +455,8 @@ func (qm *durableQueueManager) StartReplicationQueues(trackedReplications map[pl
 				errOccurred = true
 			}
 		} else {
-			qm.replicationQueues[id] = qm.newReplicationQueue(id, repl.OrgID, repl.LocalBucketID, queue, repl.MaxAgeSeconds)
+			qm.replicationQueues[id] = qm.newReplicationQueue(id, repl.OrgID, repl.LocalBucketID, queue, repl.MaxAgeSeconds, repl.MaxQueueSizeBytes)
+			qm.replicationQueues[id].paused.Store(repl.Paused)
 			qm.replicationQueues[id].Open()
 			qm.logger.Info("Opened replication stream", zap.String("id", id.String()), zap.String("path", queue.Dir()))
 		}
@@ -479,7 +550,7 @@ func (qm *durableQueueManager) EnqueueData(replicationID platform.ID, data []byt
 	return nil
 }
 
-func (qm *durableQueueManager) newReplicationQueue(id platform.ID, orgID platform.ID, localBucketID platform.ID, queue *durablequeue.Queue, maxAgeSeconds int64) *replicationQueue {
+func (qm *durableQueueManager) newReplicationQueue(id platform.ID, orgID platform.ID, localBucketID platform.ID, queue *durablequeue.Queue, maxAgeSeconds int64, maxQueueSizeBytes int64) *replicationQueue {
 	logger := qm.logger.With(zap.String("replication_id", id.String()))
 	done := make(chan struct{})
 	// check for max age minimum
@@ -499,8 +570,9 @@ func (qm *durableQueueManager) newReplicationQueue(id platform.ID, orgID platfor
 		receive:       make(chan struct{}, 1),
 		logger:        logger,
 		metrics:       qm.metrics,
-		remoteWriter:  remotewrite.NewWriter(id, qm.configStore, qm.metrics, logger, done),
+		remoteWriter:  remotewrite.NewWriter(id, qm.configStore, qm.metrics, logger, done, qm.instanceID),
 		maxAge:        maxAgeTime,
+		maxSize:       maxQueueSizeBytes,
 	}
 }
 
@@ -516,3 +588,186 @@ func (qm *durableQueueManager) GetReplications(orgID platform.ID, localBucketID
 	}
 	return replications
 }
+
+// SetQueuePaused pauses or resumes sending the data of a replication queue. Pausing doesn't wait
+// for a write in progress, which completes but isn't followed by another.
+func (qm *durableQueueManager) SetQueuePaused(replicationID platform.ID, paused bool) error {
+	qm.mutex.RLock()
+	defer qm.mutex.RUnlock()
+
+	rq, ok := qm.replicationQueues[replicationID]
+	if !ok {
+		return fmt.Errorf("durable queue not found for replication ID %q", replicationID)
+	}
+
+	rq.paused.Store(paused)
+	rq.mu.Lock()
+	rq.failedWrites = 0
+	rq.mu.Unlock()
+
+	if !paused {
+		select {
+		case rq.receive <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// PeekQueue returns the batch at the head of a replication queue, or of its dead-letter queue.
+// It returns nil if the queue is empty.
+func (qm *durableQueueManager) PeekQueue(replicationID platform.ID, deadLetter bool) ([]byte, error) {
+	qm.mutex.RLock()
+	defer qm.mutex.RUnlock()
+
+	rq, ok := qm.replicationQueues[replicationID]
+	if !ok {
+		return nil, fmt.Errorf("durable queue not found for replication ID %q", replicationID)
+	}
+
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+
+	q := rq.queue
+	if deadLetter {
+		var err error
+		if q, err = rq.openDeadLetter(); err != nil {
+			return nil, err
+		}
+	}
+	batches, err := q.PeekN(1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+	return batches[0], nil
+}
+
+// SkipQueueHead removes the batch at the head of a paused replication queue, moving it to the
+// replication's dead-letter queue if deadLetter is set. It returns the removed batch, or nil if
+// the queue is empty. A batch still being written when the queue was paused may be delivered
+// even though it is skipped.
+func (qm *durableQueueManager) SkipQueueHead(replicationID platform.ID, deadLetter bool) ([]byte, error) {
+	qm.mutex.RLock()
+	defer qm.mutex.RUnlock()
+
+	rq, ok := qm.replicationQueues[replicationID]
+	if !ok {
+		return nil, fmt.Errorf("durable queue not found for replication ID %q", replicationID)
+	}
+
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+
+	// The head of a running queue may have been sent by the time it is skipped.
+	if !rq.paused.Load() {
+		return nil, errQueueNotPaused
+	}
+
+	batches, err := rq.queue.PeekN(1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	if deadLetter {
+		dlq, err := rq.openDeadLetter()
+		if err != nil {
+			return nil, err
+		}
+		if err := dlq.Append(batches[0]); err != nil {
+			return nil, err
+		}
+	}
+	if err := rq.queue.Advance(); err != nil {
+		return nil, err
+	}
+	rq.generation++
+	rq.failedWrites = 0
+	rq.metrics.Dequeue(rq.id, rq.queue.TotalBytes())
+
+	return batches[0], nil
+}
+
+// PurgeQueue removes all the data of a replication queue, or of its dead-letter queue.
+func (qm *durableQueueManager) PurgeQueue(replicationID platform.ID, deadLetter bool) error {
+	qm.mutex.RLock()
+	defer qm.mutex.RUnlock()
+
+	rq, ok := qm.replicationQueues[replicationID]
+	if !ok {
+		return fmt.Errorf("durable queue not found for replication ID %q", replicationID)
+	}
+
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+
+	if deadLetter {
+		dlq, err := rq.openDeadLetter()
+		if err != nil {
+			return err
+		}
+		return purge(dlq)
+	}
+
+	rq.generation++
+	if err := purge(rq.queue); err != nil {
+		return err
+	}
+	rq.failedWrites = 0
+	rq.metrics.Dequeue(rq.id, rq.queue.TotalBytes())
+	return nil
+}
+
+// openDeadLetter returns the dead-letter queue of the replication queue, opening it if needed.
+// The caller must hold rq.mu.
+func (rq *replicationQueue) openDeadLetter() (*durablequeue.Queue, error) {
+	if rq.deadLetter != nil {
+		return rq.deadLetter, nil
+	}
+
+	dir := filepath.Join(rq.queue.Dir(), deadLetterDir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	q, err := durablequeue.NewQueue(
+		dir,
+		rq.maxSize,
+		durablequeue.DefaultSegmentSize,
+		&durablequeue.SharedCount{},
+		durablequeue.MaxWritesPending,
+		func(bytes []byte) error {
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.Open(); err != nil {
+		return nil, err
+	}
+	rq.deadLetter = q
+	return q, nil
+}
+
+// purge removes all the data of a queue. Whole segments older than now are removed at once,
+// and the rest of the data a batch at a time.
+func purge(q *durablequeue.Queue) error {
+	if err := q.PurgeOlderThan(time.Now()); err != nil {
+		return err
+	}
+	for {
+		if _, err := q.Current(); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := q.Advance(); err != nil {
+			return err
+		}
+	}
+}
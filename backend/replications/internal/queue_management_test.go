@@ -339,7 +339,7 @@ func initQueueManager(t *testing.T) (string, *durableQueueManager) {
 	queuePath := filepath.Join(t.TempDir(), "replicationq")
 
 	logger := zaptest.NewLogger(t)
-	qm := NewDurableQueueManager(logger, queuePath, metrics.NewReplicationsMetrics(), replicationsMock.NewMockHttpConfigStore(nil))
+	qm := NewDurableQueueManager(logger, queuePath, metrics.NewReplicationsMetrics(), replicationsMock.NewMockHttpConfigStore(nil), "")
 
 	return queuePath, qm
 }
@@ -410,7 +410,7 @@ func TestEnqueueData(t *testing.T) {
 	queuePath := t.TempDir()
 
 	logger := zaptest.NewLogger(t)
-	qm := NewDurableQueueManager(logger, queuePath, metrics.NewReplicationsMetrics(), replicationsMock.NewMockHttpConfigStore(nil))
+	qm := NewDurableQueueManager(logger, queuePath, metrics.NewReplicationsMetrics(), replicationsMock.NewMockHttpConfigStore(nil), "")
 
 	require.NoError(t, qm.InitializeQueue(id1, maxQueueSizeBytes, orgID1, localBucketID1, 0))
 	require.DirExists(t, filepath.Join(queuePath, id1.String()))
@@ -747,3 +747,154 @@ func TestReplicationStartMissingQueue(t *testing.T) {
 	err = qm.replicationQueues[id1].queue.Remove()
 	require.Errorf(t, err, "queue is open")
 }
+
+func TestQueueManualControl(t *testing.T) {
+	t.Parallel()
+
+	path, qm := initQueueManager(t)
+	require.NoError(t, qm.InitializeQueue(id1, maxQueueSizeBytes, orgID1, localBucketID1, 0))
+	t.Cleanup(func() { shutdown(t, qm) })
+
+	rq := qm.replicationQueues[id1]
+	sent := make(chan string, 10)
+	rq.remoteWriter = &testRemoteWriter{writeFn: func(b []byte, _ int) (time.Duration, error) {
+		sent <- string(b)
+		return 0, nil
+	}}
+
+	// Nothing is sent while the queue is paused.
+	require.NoError(t, qm.SetQueuePaused(id1, true))
+	for _, data := range []string{"a", "b", "c"} {
+		require.NoError(t, qm.EnqueueData(id1, []byte(data), 1))
+	}
+
+	head, err := qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(head))
+
+	skipped, err := qm.SkipQueueHead(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(skipped))
+
+	skipped, err = qm.SkipQueueHead(id1, true)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(skipped))
+	require.DirExists(t, filepath.Join(path, id1.String(), deadLetterDir))
+
+	head, err = qm.PeekQueue(id1, true)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(head))
+	head, err = qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "c", string(head))
+	require.Empty(t, sent)
+
+	// Resuming sends the rest of the queue.
+	require.NoError(t, qm.SetQueuePaused(id1, false))
+	select {
+	case data := <-sent:
+		require.Equal(t, "c", data)
+	case <-time.After(10 * time.Second):
+		t.Fatal("queue was not resumed")
+	}
+
+	// The head of a running queue can't be skipped.
+	_, err = qm.SkipQueueHead(id1, false)
+	require.Equal(t, errQueueNotPaused, err)
+
+	require.NoError(t, qm.PurgeQueue(id1, true))
+	head, err = qm.PeekQueue(id1, true)
+	require.NoError(t, err)
+	require.Nil(t, head)
+}
+
+func TestQueueManualControlDuringWrite(t *testing.T) {
+	t.Parallel()
+
+	_, qm := initQueueManager(t)
+	require.NoError(t, qm.InitializeQueue(id1, maxQueueSizeBytes, orgID1, localBucketID1, 0))
+	t.Cleanup(func() { shutdown(t, qm) })
+
+	rq := qm.replicationQueues[id1]
+	sent := make(chan string, 10)
+	release := make(chan struct{})
+	rq.remoteWriter = &testRemoteWriter{writeFn: func(b []byte, _ int) (time.Duration, error) {
+		sent <- string(b)
+		<-release
+		return 0, nil
+	}}
+
+	require.NoError(t, qm.SetQueuePaused(id1, true))
+	for _, data := range []string{"a", "b"} {
+		require.NoError(t, qm.EnqueueData(id1, []byte(data), 1))
+	}
+	require.NoError(t, qm.SetQueuePaused(id1, false))
+	select {
+	case data := <-sent:
+		require.Equal(t, "a", data)
+	case <-time.After(10 * time.Second):
+		t.Fatal("queue was not resumed")
+	}
+
+	// The queue can be inspected and changed while the write of its head is blocked.
+	head, err := qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(head))
+	require.NoError(t, qm.SetQueuePaused(id1, true))
+	skipped, err := qm.SkipQueueHead(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(skipped))
+	head, err = qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(head))
+	close(release)
+
+	// The rest of the queue is sent once the blocked write completes.
+	require.NoError(t, qm.SetQueuePaused(id1, false))
+	select {
+	case data := <-sent:
+		require.Equal(t, "b", data)
+	case <-time.After(10 * time.Second):
+		t.Fatal("queue was not resumed")
+	}
+}
+
+func TestPurgeQueue(t *testing.T) {
+	t.Parallel()
+
+	_, qm := initQueueManager(t)
+	require.NoError(t, qm.InitializeQueue(id1, maxQueueSizeBytes, orgID1, localBucketID1, 0))
+	t.Cleanup(func() { shutdown(t, qm) })
+
+	require.NoError(t, qm.SetQueuePaused(id1, true))
+	for _, data := range []string{"a", "b", "c"} {
+		require.NoError(t, qm.EnqueueData(id1, []byte(data), 1))
+	}
+
+	require.NoError(t, qm.PurgeQueue(id1, false))
+	head, err := qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Nil(t, head)
+	sizes, err := qm.RemainingQueueSizes([]platform.ID{id1})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), sizes[id1])
+
+	// The queue is still usable after being purged.
+	require.NoError(t, qm.EnqueueData(id1, []byte("d"), 1))
+	head, err = qm.PeekQueue(id1, false)
+	require.NoError(t, err)
+	require.Equal(t, "d", string(head))
+}
+
+func TestStartReplicationQueuesPaused(t *testing.T) {
+	t.Parallel()
+
+	_, qm := initQueueManager(t)
+	trackedReplications := map[platform.ID]*influxdb.TrackedReplication{
+		id1: {MaxQueueSizeBytes: maxQueueSizeBytes, OrgID: orgID1, LocalBucketID: localBucketID1, Paused: true},
+	}
+	require.NoError(t, qm.StartReplicationQueues(trackedReplications))
+	t.Cleanup(func() { shutdown(t, qm) })
+
+	require.True(t, qm.replicationQueues[id1].paused.Load())
+}
@@ -34,11 +34,15 @@ func errRemoteNotFound(id platform.ID, cause error) error {
 
 type Store struct {
 	sqlStore *sqlite.SqlStore
+	secrets  influxdb.SecretService
 }
 
-func NewStore(sqlStore *sqlite.SqlStore) *Store {
+// NewStore returns a store of replications. The secrets service is used to load the client
+// certificates of remotes; it may be nil when no remote uses one.
+func NewStore(sqlStore *sqlite.SqlStore, secrets influxdb.SecretService) *Store {
 	return &Store{
 		sqlStore: sqlStore,
+		secrets:  secrets,
 	}
 }
 
@@ -55,7 +59,7 @@ func (s *Store) ListReplications(ctx context.Context, filter influxdb.Replicatio
 	q := sq.Select(
 		"id", "org_id", "name", "description", "remote_id", "local_bucket_id", "remote_bucket_id", "remote_bucket_name",
 		"max_queue_size_bytes", "latest_response_code", "latest_error_message", "drop_non_retryable_data",
-		"max_age_seconds").
+		"max_age_seconds", "filter", "paused").
 		From("replications")
 
 	if filter.OrgID.Valid() {
@@ -96,6 +100,7 @@ func (s *Store) CreateReplication(ctx context.Context, newID platform.ID, reques
 		"max_queue_size_bytes":    request.MaxQueueSizeBytes,
 		"drop_non_retryable_data": request.DropNonRetryableData,
 		"max_age_seconds":         request.MaxAgeSeconds,
+		"filter":                  request.Filter,
 		"created_at":              "datetime('now')",
 		"updated_at":              "datetime('now')",
 	}
@@ -112,7 +117,7 @@ func (s *Store) CreateReplication(ctx context.Context, newID platform.ID, reques
 
 	q := sq.Insert("replications").
 		SetMap(fields).
-		Suffix("RETURNING id, org_id, name, description, remote_id, local_bucket_id, remote_bucket_id, remote_bucket_name, max_queue_size_bytes, drop_non_retryable_data, max_age_seconds")
+		Suffix("RETURNING id, org_id, name, description, remote_id, local_bucket_id, remote_bucket_id, remote_bucket_name, max_queue_size_bytes, drop_non_retryable_data, max_age_seconds, filter, paused")
 
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -136,7 +141,7 @@ func (s *Store) GetReplication(ctx context.Context, id platform.ID) (*influxdb.R
 	q := sq.Select(
 		"id", "org_id", "name", "description", "remote_id", "local_bucket_id", "remote_bucket_id", "remote_bucket_name",
 		"max_queue_size_bytes", "latest_response_code", "latest_error_message", "drop_non_retryable_data",
-		"max_age_seconds").
+		"max_age_seconds", "filter", "paused").
 		From("replications").
 		Where(sq.Eq{"id": id})
 
@@ -183,9 +188,12 @@ func (s *Store) UpdateReplication(ctx context.Context, id platform.ID, request i
 	if request.MaxAgeSeconds != nil {
 		updates["max_age_seconds"] = *request.MaxAgeSeconds
 	}
+	if request.Filter != nil {
+		updates["filter"] = *request.Filter
+	}
 
 	q := sq.Update("replications").SetMap(updates).Where(sq.Eq{"id": id}).
-		Suffix("RETURNING id, org_id, name, description, remote_id, local_bucket_id, remote_bucket_id, remote_bucket_name, max_queue_size_bytes, drop_non_retryable_data, max_age_seconds")
+		Suffix("RETURNING id, org_id, name, description, remote_id, local_bucket_id, remote_bucket_id, remote_bucket_name, max_queue_size_bytes, drop_non_retryable_data, max_age_seconds, filter, paused")
 
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -231,6 +239,29 @@ func (s *Store) UpdateResponseInfo(ctx context.Context, id platform.ID, code int
 	return nil
 }
 
+// SetReplicationPaused sets whether a replication is paused. Caller is responsible for managing locks.
+func (s *Store) SetReplicationPaused(ctx context.Context, id platform.ID, paused bool) error {
+	q := sq.Update("replications").
+		SetMap(sq.Eq{"paused": paused, "updated_at": sq.Expr("datetime('now')")}).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING id")
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+
+	var d platform.ID
+	if err := s.sqlStore.DB.GetContext(ctx, &d, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errReplicationNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
 // DeleteReplication deletes a replication by ID from the database.  Caller is responsible for managing locks.
 func (s *Store) DeleteReplication(ctx context.Context, id platform.ID) error {
 	q := sq.Delete("replications").Where(sq.Eq{"id": id}).Suffix("RETURNING id")
@@ -268,7 +299,10 @@ func (s *Store) DeleteBucketReplications(ctx context.Context, localBucketID plat
 }
 
 func (s *Store) GetFullHTTPConfig(ctx context.Context, id platform.ID) (*influxdb.ReplicationHTTPConfig, error) {
-	q := sq.Select("c.remote_url", "c.remote_api_token", "c.remote_org_id", "c.allow_insecure_tls", "r.remote_bucket_id", "r.remote_bucket_name", "r.drop_non_retryable_data").
+	q := sq.Select("c.remote_url", "c.remote_api_token", "c.remote_org_id", "c.allow_insecure_tls", "c.remote_type", "c.remote_format",
+		"c.remote_topic", "c.org_id", "c.remote_ca_cert", "c.remote_server_name", "c.client_cert_secret", "c.client_key_secret",
+		"c.remote_instance_id",
+		"r.remote_bucket_id", "r.remote_bucket_name", "r.drop_non_retryable_data").
 		From("replications r").InnerJoin("remotes c ON r.remote_id = c.id AND r.id = ?", id)
 
 	query, args, err := q.ToSql()
@@ -283,11 +317,15 @@ func (s *Store) GetFullHTTPConfig(ctx context.Context, id platform.ID) (*influxd
 		}
 		return nil, err
 	}
+	if err := s.loadClientCert(ctx, &rc); err != nil {
+		return nil, err
+	}
 	return &rc, nil
 }
 
 func (s *Store) PopulateRemoteHTTPConfig(ctx context.Context, id platform.ID, target *influxdb.ReplicationHTTPConfig) error {
-	q := sq.Select("remote_url", "remote_api_token", "remote_org_id", "allow_insecure_tls").
+	q := sq.Select("remote_url", "remote_api_token", "remote_org_id", "allow_insecure_tls", "remote_type", "remote_format", "remote_topic",
+		"org_id", "remote_ca_cert", "remote_server_name", "client_cert_secret", "client_key_secret", "remote_instance_id").
 		From("remotes").Where(sq.Eq{"id": id})
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -301,5 +339,22 @@ func (s *Store) PopulateRemoteHTTPConfig(ctx context.Context, id platform.ID, ta
 		return err
 	}
 
+	return s.loadClientCert(ctx, target)
+}
+
+// loadClientCert loads the client certificate and key of the remote in conf from its secrets.
+func (s *Store) loadClientCert(ctx context.Context, conf *influxdb.ReplicationHTTPConfig) error {
+	conf.ClientCert, conf.ClientKey = "", ""
+	if conf.ClientCertSecret == "" || s.secrets == nil {
+		return nil
+	}
+
+	var err error
+	if conf.ClientCert, err = s.secrets.LoadSecret(ctx, conf.OrgID, conf.ClientCertSecret); err != nil {
+		return fmt.Errorf("failed to load client certificate of remote: %w", err)
+	}
+	if conf.ClientKey, err = s.secrets.LoadSecret(ctx, conf.OrgID, conf.ClientKeySecret); err != nil {
+		return fmt.Errorf("failed to load client key of remote: %w", err)
+	}
 	return nil
 }
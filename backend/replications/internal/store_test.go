@@ -10,6 +10,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/sqlite"
 	"github.com/influxdata/influxdb/v2/sqlite/migrations"
@@ -48,6 +49,8 @@ var (
 		RemoteOrgID:      idPointer(888888),
 		AllowInsecureTLS: true,
 		RemoteBucketID:   replication.RemoteBucketID,
+		RemoteType:       influxdb.RemoteTypeInfluxDB,
+		OrgID:            replication.OrgID,
 	}
 	newQueueSize = influxdb.MinReplicationMaxQueueSizeBytes
 	updateReq    = influxdb.UpdateReplicationRequest{
@@ -219,6 +222,48 @@ func TestUpdateAndGetReplication(t *testing.T) {
 	require.Equal(t, updatedReplication, *updated)
 }
 
+func TestCreateAndUpdateReplicationFilter(t *testing.T) {
+	t.Parallel()
+
+	testStore := newTestStore(t)
+
+	insertRemote(t, testStore, replication.RemoteID)
+
+	filter := &influxdb.ReplicationFilter{
+		Include: &influxdb.ReplicationPredicate{
+			Measurements: []string{"prod_*"},
+			Tags:         []influxdb.ReplicationTagMatcher{{Key: "env", Value: "prod"}},
+		},
+		Exclude: &influxdb.ReplicationPredicate{Fields: []string{"debug"}},
+	}
+
+	// Create a replication with a filter, and read it back.
+	req := createReq
+	req.Filter = filter
+	created, err := testStore.CreateReplication(ctx, initID, req)
+	require.NoError(t, err)
+	require.Equal(t, filter, created.Filter)
+
+	got, err := testStore.GetReplication(ctx, initID)
+	require.NoError(t, err)
+	require.Equal(t, filter, got.Filter)
+
+	// Updates without a filter leave it unchanged.
+	updated, err := testStore.UpdateReplication(ctx, initID, influxdb.UpdateReplicationRequest{MaxAgeSeconds: &replication.MaxAgeSeconds})
+	require.NoError(t, err)
+	require.Equal(t, filter, updated.Filter)
+
+	// Updating to an empty filter clears it.
+	updated, err = testStore.UpdateReplication(ctx, initID, influxdb.UpdateReplicationRequest{Filter: &influxdb.ReplicationFilter{}})
+	require.NoError(t, err)
+	require.Nil(t, updated.Filter)
+
+	listed, err := testStore.ListReplications(ctx, influxdb.ReplicationListFilter{OrgID: replication.OrgID})
+	require.NoError(t, err)
+	require.Len(t, listed.Replications, 1)
+	require.Nil(t, listed.Replications[0].Filter)
+}
+
 func TestUpdateResponseInfo(t *testing.T) {
 	t.Parallel()
 
@@ -250,6 +295,29 @@ func TestUpdateResponseInfo(t *testing.T) {
 	require.Equal(t, testMsg, *got.LatestErrorMessage)
 }
 
+func TestSetReplicationPaused(t *testing.T) {
+	t.Parallel()
+
+	testStore := newTestStore(t)
+	insertRemote(t, testStore, replication.RemoteID)
+
+	// Pausing a nonexistent ID fails.
+	require.Equal(t, errReplicationNotFound, testStore.SetReplicationPaused(ctx, initID, true))
+
+	_, err := testStore.CreateReplication(ctx, initID, createReq)
+	require.NoError(t, err)
+
+	require.NoError(t, testStore.SetReplicationPaused(ctx, initID, true))
+	got, err := testStore.GetReplication(ctx, initID)
+	require.NoError(t, err)
+	require.True(t, got.Paused)
+
+	require.NoError(t, testStore.SetReplicationPaused(ctx, initID, false))
+	listed, err := testStore.ListReplications(ctx, influxdb.ReplicationListFilter{OrgID: replication.OrgID})
+	require.NoError(t, err)
+	require.False(t, listed.Replications[0].Paused)
+}
+
 func TestUpdateMissingRemote(t *testing.T) {
 	t.Parallel()
 
@@ -517,26 +585,40 @@ func TestMigrateUpToRemotesNullRemoteOrg(t *testing.T) {
 	_, err := sqlStore.DB.Exec("PRAGMA foreign_keys = ON;")
 	require.NoError(t, err)
 
-	testStore := NewStore(sqlStore)
+	testStore := NewStore(sqlStore, nil)
 
 	insertRemote(t, testStore, replication.RemoteID)
 
-	req := createReq
-	req.RemoteBucketID = platform.ID(100)
-	_, err = testStore.CreateReplication(ctx, platform.ID(10), req)
-	require.NoError(t, err)
+	// Can't use CreateReplication because it expects the `filter` column to be there in this version of influx.
+	insert := func(id platform.ID, name string, remoteBucketID interface{}, remoteBucketName string) {
+		q := sq.Insert("replications").SetMap(sq.Eq{
+			"id":                      id,
+			"org_id":                  replication.OrgID,
+			"name":                    name,
+			"remote_id":               replication.RemoteID,
+			"local_bucket_id":         replication.LocalBucketID,
+			"remote_bucket_id":        remoteBucketID,
+			"remote_bucket_name":      remoteBucketName,
+			"max_queue_size_bytes":    replication.MaxQueueSizeBytes,
+			"max_age_seconds":         replication.MaxAgeSeconds,
+			"drop_non_retryable_data": false,
+			"created_at":              "datetime('now')",
+			"updated_at":              "datetime('now')",
+		})
+		query, args, err := q.ToSql()
+		require.NoError(t, err)
+		_, err = sqlStore.DB.Exec(query, args...)
+		require.NoError(t, err)
+	}
+	insert(platform.ID(10), createReq.Name, platform.ID(100), "")
+	insert(platform.ID(20), "namedrepl", nil, "testbucket")
 
-	req.RemoteBucketID = platform.ID(0)
-	req.RemoteBucketName = "testbucket"
-	req.Name = "namedrepl"
-	_, err = testStore.CreateReplication(ctx, platform.ID(20), req)
-	require.NoError(t, err)
+	require.NoError(t, sqliteMigrator.UpUntil(ctx, 8, migrations.AllUp))
+	require.NoError(t, sqliteMigrator.Up(ctx, migrations.AllUp))
 
 	replications, err := testStore.ListReplications(context.Background(), influxdb.ReplicationListFilter{OrgID: replication.OrgID})
 	require.NoError(t, err)
 	require.Equal(t, 2, len(replications.Replications))
-
-	require.NoError(t, sqliteMigrator.UpUntil(ctx, 8, migrations.AllUp))
 }
 
 func TestGetFullHTTPConfig(t *testing.T) {
@@ -559,6 +641,48 @@ func TestGetFullHTTPConfig(t *testing.T) {
 	require.Equal(t, httpConfig, *conf)
 }
 
+func TestGetFullHTTPConfig_ClientCert(t *testing.T) {
+	t.Parallel()
+
+	testStore := newTestStore(t)
+	testStore.secrets = &mock.SecretService{
+		LoadSecretFn: func(_ context.Context, orgID platform.ID, k string) (string, error) {
+			require.Equal(t, replication.OrgID, orgID)
+			return "pem of " + k, nil
+		},
+	}
+
+	insertRemote(t, testStore, replication.RemoteID)
+	_, err := testStore.sqlStore.DB.Exec(
+		"UPDATE remotes SET remote_ca_cert = 'ca', remote_server_name = 'influxdb.internal', client_cert_secret = 'cert', client_key_secret = 'key'")
+	require.NoError(t, err)
+	_, err = testStore.CreateReplication(ctx, initID, createReq)
+	require.NoError(t, err)
+
+	conf, err := testStore.GetFullHTTPConfig(ctx, initID)
+	require.NoError(t, err)
+	require.Equal(t, "ca", conf.RemoteCACert)
+	require.Equal(t, "influxdb.internal", conf.RemoteServerName)
+	require.Equal(t, "pem of cert", conf.ClientCert)
+	require.Equal(t, "pem of key", conf.ClientKey)
+}
+
+func TestGetFullHTTPConfig_RemoteInstanceID(t *testing.T) {
+	t.Parallel()
+
+	testStore := newTestStore(t)
+
+	insertRemote(t, testStore, replication.RemoteID)
+	_, err := testStore.sqlStore.DB.Exec("UPDATE remotes SET remote_instance_id = 'site-a'")
+	require.NoError(t, err)
+	_, err = testStore.CreateReplication(ctx, initID, createReq)
+	require.NoError(t, err)
+
+	conf, err := testStore.GetFullHTTPConfig(ctx, initID)
+	require.NoError(t, err)
+	require.Equal(t, "site-a", conf.RemoteInstanceID)
+}
+
 func TestPopulateRemoteHTTPConfig(t *testing.T) {
 	t.Parallel()
 
@@ -578,6 +702,8 @@ func TestPopulateRemoteHTTPConfig(t *testing.T) {
 		RemoteToken:      httpConfig.RemoteToken,
 		RemoteOrgID:      httpConfig.RemoteOrgID,
 		AllowInsecureTLS: httpConfig.AllowInsecureTLS,
+		RemoteType:       httpConfig.RemoteType,
+		OrgID:            httpConfig.OrgID,
 	}
 	insertRemote(t, testStore, replication.RemoteID)
 	err = testStore.PopulateRemoteHTTPConfig(ctx, replication.RemoteID, target)
@@ -595,7 +721,7 @@ func newTestStore(t *testing.T) *Store {
 	_, err := sqlStore.DB.Exec("PRAGMA foreign_keys = ON;")
 	require.NoError(t, err)
 
-	return NewStore(sqlStore)
+	return NewStore(sqlStore, nil)
 }
 
 func insertRemote(t *testing.T, store *Store, id platform.ID) {
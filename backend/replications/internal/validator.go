@@ -16,6 +16,6 @@ func NewValidator() *noopWriteValidator {
 type noopWriteValidator struct{}
 
 func (s noopWriteValidator) ValidateReplication(ctx context.Context, config *influxdb.ReplicationHTTPConfig) error {
-	_, err := remotewrite.PostWrite(ctx, config, []byte{}, remotewrite.DefaultTimeout)
+	_, err := remotewrite.DefaultSinks().Send(ctx, config, []byte{}, remotewrite.DefaultTimeout)
 	return err
 }
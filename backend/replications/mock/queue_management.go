@@ -120,6 +120,35 @@ func (mr *MockDurableQueueManagerMockRecorder) InitializeQueue(arg0, arg1, arg2,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitializeQueue", reflect.TypeOf((*MockDurableQueueManager)(nil).InitializeQueue), arg0, arg1, arg2, arg3, arg4)
 }
 
+// PeekQueue mocks base method.
+func (m *MockDurableQueueManager) PeekQueue(arg0 platform.ID, arg1 bool) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekQueue", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekQueue indicates an expected call of PeekQueue.
+func (mr *MockDurableQueueManagerMockRecorder) PeekQueue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekQueue", reflect.TypeOf((*MockDurableQueueManager)(nil).PeekQueue), arg0, arg1)
+}
+
+// PurgeQueue mocks base method.
+func (m *MockDurableQueueManager) PurgeQueue(arg0 platform.ID, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeQueue", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeQueue indicates an expected call of PurgeQueue.
+func (mr *MockDurableQueueManagerMockRecorder) PurgeQueue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeQueue", reflect.TypeOf((*MockDurableQueueManager)(nil).PurgeQueue), arg0, arg1)
+}
+
 // RemainingQueueSizes mocks base method.
 func (m *MockDurableQueueManager) RemainingQueueSizes(arg0 []platform.ID) (map[platform.ID]int64, error) {
 	m.ctrl.T.Helper()
@@ -135,6 +164,35 @@ func (mr *MockDurableQueueManagerMockRecorder) RemainingQueueSizes(arg0 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemainingQueueSizes", reflect.TypeOf((*MockDurableQueueManager)(nil).RemainingQueueSizes), arg0)
 }
 
+// SetQueuePaused mocks base method.
+func (m *MockDurableQueueManager) SetQueuePaused(arg0 platform.ID, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQueuePaused", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetQueuePaused indicates an expected call of SetQueuePaused.
+func (mr *MockDurableQueueManagerMockRecorder) SetQueuePaused(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQueuePaused", reflect.TypeOf((*MockDurableQueueManager)(nil).SetQueuePaused), arg0, arg1)
+}
+
+// SkipQueueHead mocks base method.
+func (m *MockDurableQueueManager) SkipQueueHead(arg0 platform.ID, arg1 bool) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SkipQueueHead", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SkipQueueHead indicates an expected call of SkipQueueHead.
+func (mr *MockDurableQueueManagerMockRecorder) SkipQueueHead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SkipQueueHead", reflect.TypeOf((*MockDurableQueueManager)(nil).SkipQueueHead), arg0, arg1)
+}
+
 // StartReplicationQueues mocks base method.
 func (m *MockDurableQueueManager) StartReplicationQueues(arg0 map[platform.ID]*influxdb.TrackedReplication) error {
 	m.ctrl.T.Helper()
@@ -36,6 +36,35 @@ func (m *MockReplicationService) EXPECT() *MockReplicationServiceMockRecorder {
 	return m.recorder
 }
 
+// BackfillReplication mocks base method.
+func (m *MockReplicationService) BackfillReplication(arg0 context.Context, arg1 platform.ID, arg2 influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillReplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*influxdb.Replication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillReplication indicates an expected call of BackfillReplication.
+func (mr *MockReplicationServiceMockRecorder) BackfillReplication(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillReplication", reflect.TypeOf((*MockReplicationService)(nil).BackfillReplication), arg0, arg1, arg2)
+}
+
+// CancelReplicationBackfill mocks base method.
+func (m *MockReplicationService) CancelReplicationBackfill(arg0 context.Context, arg1 platform.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReplicationBackfill", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelReplicationBackfill indicates an expected call of CancelReplicationBackfill.
+func (mr *MockReplicationServiceMockRecorder) CancelReplicationBackfill(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReplicationBackfill", reflect.TypeOf((*MockReplicationService)(nil).CancelReplicationBackfill), arg0, arg1)
+}
+
 // CreateReplication mocks base method.
 func (m *MockReplicationService) CreateReplication(arg0 context.Context, arg1 influxdb.CreateReplicationRequest) (*influxdb.Replication, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +124,80 @@ func (mr *MockReplicationServiceMockRecorder) ListReplications(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplications", reflect.TypeOf((*MockReplicationService)(nil).ListReplications), arg0, arg1)
 }
 
+// PauseReplication mocks base method.
+func (m *MockReplicationService) PauseReplication(arg0 context.Context, arg1 platform.ID) (*influxdb.Replication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseReplication", arg0, arg1)
+	ret0, _ := ret[0].(*influxdb.Replication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseReplication indicates an expected call of PauseReplication.
+func (mr *MockReplicationServiceMockRecorder) PauseReplication(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseReplication", reflect.TypeOf((*MockReplicationService)(nil).PauseReplication), arg0, arg1)
+}
+
+// PeekReplicationQueue mocks base method.
+func (m *MockReplicationService) PeekReplicationQueue(arg0 context.Context, arg1 platform.ID, arg2 bool) (*influxdb.ReplicationQueueBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekReplicationQueue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*influxdb.ReplicationQueueBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekReplicationQueue indicates an expected call of PeekReplicationQueue.
+func (mr *MockReplicationServiceMockRecorder) PeekReplicationQueue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekReplicationQueue", reflect.TypeOf((*MockReplicationService)(nil).PeekReplicationQueue), arg0, arg1, arg2)
+}
+
+// PurgeReplicationQueue mocks base method.
+func (m *MockReplicationService) PurgeReplicationQueue(arg0 context.Context, arg1 platform.ID, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeReplicationQueue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeReplicationQueue indicates an expected call of PurgeReplicationQueue.
+func (mr *MockReplicationServiceMockRecorder) PurgeReplicationQueue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeReplicationQueue", reflect.TypeOf((*MockReplicationService)(nil).PurgeReplicationQueue), arg0, arg1, arg2)
+}
+
+// ResumeReplication mocks base method.
+func (m *MockReplicationService) ResumeReplication(arg0 context.Context, arg1 platform.ID) (*influxdb.Replication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeReplication", arg0, arg1)
+	ret0, _ := ret[0].(*influxdb.Replication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeReplication indicates an expected call of ResumeReplication.
+func (mr *MockReplicationServiceMockRecorder) ResumeReplication(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeReplication", reflect.TypeOf((*MockReplicationService)(nil).ResumeReplication), arg0, arg1)
+}
+
+// SkipReplicationQueueHead mocks base method.
+func (m *MockReplicationService) SkipReplicationQueueHead(arg0 context.Context, arg1 platform.ID, arg2 bool) (*influxdb.ReplicationQueueBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SkipReplicationQueueHead", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*influxdb.ReplicationQueueBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SkipReplicationQueueHead indicates an expected call of SkipReplicationQueueHead.
+func (mr *MockReplicationServiceMockRecorder) SkipReplicationQueueHead(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SkipReplicationQueueHead", reflect.TypeOf((*MockReplicationService)(nil).SkipReplicationQueueHead), arg0, arg1, arg2)
+}
+
 // UpdateReplication mocks base method.
 func (m *MockReplicationService) UpdateReplication(arg0 context.Context, arg1 platform.ID, arg2 influxdb.UpdateReplicationRequest) (*influxdb.Replication, error) {
 	m.ctrl.T.Helper()
@@ -151,6 +151,20 @@ func (mr *MockServiceStoreMockRecorder) PopulateRemoteHTTPConfig(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopulateRemoteHTTPConfig", reflect.TypeOf((*MockServiceStore)(nil).PopulateRemoteHTTPConfig), arg0, arg1, arg2)
 }
 
+// SetReplicationPaused mocks base method.
+func (m *MockServiceStore) SetReplicationPaused(arg0 context.Context, arg1 platform.ID, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReplicationPaused", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReplicationPaused indicates an expected call of SetReplicationPaused.
+func (mr *MockServiceStoreMockRecorder) SetReplicationPaused(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReplicationPaused", reflect.TypeOf((*MockServiceStore)(nil).SetReplicationPaused), arg0, arg1, arg2)
+}
+
 // Unlock mocks base method.
 func (m *MockServiceStore) Unlock() {
 	m.ctrl.T.Helper()
@@ -0,0 +1,120 @@
+package replications
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+func errQueueEmpty(id platform.ID) error {
+	return &ierrors.Error{
+		Code: ierrors.ENotFound,
+		Msg:  fmt.Sprintf("queue of replication %q is empty", id),
+	}
+}
+
+// PauseReplication stops sending the queued data of the replication to its remote, after the
+// write in progress, if any. Written data is still queued. Replications stay paused across
+// restarts until they are resumed.
+func (s *service) PauseReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	if err := s.setPaused(ctx, id, true); err != nil {
+		return nil, err
+	}
+	return s.GetReplication(ctx, id)
+}
+
+// ResumeReplication restarts sending the queued data of the replication to its remote.
+func (s *service) ResumeReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	if err := s.setPaused(ctx, id, false); err != nil {
+		return nil, err
+	}
+	return s.GetReplication(ctx, id)
+}
+
+func (s *service) setPaused(ctx context.Context, id platform.ID, paused bool) error {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	if err := s.store.SetReplicationPaused(ctx, id, paused); err != nil {
+		return err
+	}
+	return s.durableQueueManager.SetQueuePaused(id, paused)
+}
+
+// PeekReplicationQueue returns the batch at the head of the replication's queue, which is the
+// next batch to be sent to the remote. With deadLetter set, it returns the oldest batch of the
+// replication's dead-letter queue instead.
+func (s *service) PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	if _, err := s.store.GetReplication(ctx, id); err != nil {
+		return nil, err
+	}
+
+	data, err := s.durableQueueManager.PeekQueue(id, deadLetter)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errQueueEmpty(id)
+	}
+	return decodeQueueBatch(data)
+}
+
+// SkipReplicationQueueHead removes the batch at the head of the replication's queue, so that the
+// replication moves on to the next batch when resumed. With deadLetter set, the batch is kept in
+// the replication's dead-letter queue. The replication must be paused. The removed batch is
+// returned.
+func (s *service) SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	if _, err := s.store.GetReplication(ctx, id); err != nil {
+		return nil, err
+	}
+
+	data, err := s.durableQueueManager.SkipQueueHead(id, deadLetter)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errQueueEmpty(id)
+	}
+	return decodeQueueBatch(data)
+}
+
+// PurgeReplicationQueue removes all the data from the replication's queue, or from its
+// dead-letter queue if deadLetter is set.
+func (s *service) PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) error {
+	if _, err := s.store.GetReplication(ctx, id); err != nil {
+		return err
+	}
+	return s.durableQueueManager.PurgeQueue(id, deadLetter)
+}
+
+// decodeQueueBatch decodes a batch of gzipped line protocol, as stored in replication queues.
+func decodeQueueBatch(data []byte) (*influxdb.ReplicationQueueBatch, error) {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	lp, err := io.ReadAll(gzr)
+	if err != nil {
+		return nil, err
+	}
+
+	var points int
+	for _, line := range bytes.Split(lp, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			points++
+		}
+	}
+	return &influxdb.ReplicationQueueBatch{
+		Data:      string(lp),
+		Points:    points,
+		SizeBytes: len(data),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package replications
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/influxdata/influxdb/v2"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseReplication(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	paused := replication1
+	paused.Paused = true
+
+	mocks.serviceStore.EXPECT().Lock()
+	mocks.serviceStore.EXPECT().Unlock()
+	mocks.serviceStore.EXPECT().SetReplicationPaused(gomock.Any(), id1, true).Return(nil)
+	mocks.durableQueueManager.EXPECT().SetQueuePaused(id1, true).Return(nil)
+	expectGetReplication(mocks, paused)
+
+	r, err := svc.PauseReplication(ctx, id1)
+	require.NoError(t, err)
+	require.True(t, r.Paused)
+}
+
+func TestPauseReplication_NotFound(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	notFound := &ierrors.Error{Code: ierrors.ENotFound}
+
+	mocks.serviceStore.EXPECT().Lock()
+	mocks.serviceStore.EXPECT().Unlock()
+	mocks.serviceStore.EXPECT().SetReplicationPaused(gomock.Any(), id1, false).Return(notFound)
+
+	_, err := svc.ResumeReplication(ctx, id1)
+	require.Equal(t, notFound, err)
+}
+
+func TestPeekReplicationQueue(t *testing.T) {
+	t.Parallel()
+
+	lp := "cpu,host=A usage=1.5 1000000000\ncpu,host=B usage=2.5 2000000000\n"
+	data := gzipLP(t, lp)
+
+	svc, mocks := newTestService(t)
+	expectGetReplication(mocks, replication1)
+	mocks.durableQueueManager.EXPECT().PeekQueue(id1, false).Return(data, nil)
+
+	batch, err := svc.PeekReplicationQueue(ctx, id1, false)
+	require.NoError(t, err)
+	require.Equal(t, &influxdb.ReplicationQueueBatch{Data: lp, Points: 2, SizeBytes: len(data)}, batch)
+
+	// Empty queues have no head.
+	mocks.durableQueueManager.EXPECT().PeekQueue(id1, true).Return(nil, nil)
+	_, err = svc.PeekReplicationQueue(ctx, id1, true)
+	require.Equal(t, ierrors.ENotFound, ierrors.ErrorCode(err))
+}
+
+func TestSkipReplicationQueueHead(t *testing.T) {
+	t.Parallel()
+
+	lp := "cpu,host=A usage=1.5 1000000000\n"
+	data := gzipLP(t, lp)
+
+	svc, mocks := newTestService(t)
+	expectGetReplication(mocks, replication1)
+	mocks.durableQueueManager.EXPECT().SkipQueueHead(id1, true).Return(data, nil)
+
+	batch, err := svc.SkipReplicationQueueHead(ctx, id1, true)
+	require.NoError(t, err)
+	require.Equal(t, lp, batch.Data)
+	require.Equal(t, 1, batch.Points)
+}
+
+func TestPurgeReplicationQueue(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	expectGetReplication(mocks, replication1)
+	mocks.durableQueueManager.EXPECT().PurgeQueue(id1, false).Return(nil)
+
+	require.NoError(t, svc.PurgeReplicationQueue(ctx, id1, false))
+}
+
+func gzipLP(t *testing.T, lp string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, err := gzw.Write([]byte(lp))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
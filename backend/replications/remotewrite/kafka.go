@@ -0,0 +1,106 @@
+package remotewrite
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaCluster is the part of the Kafka wire protocol used to produce messages.
+type kafkaCluster interface {
+	// LookupPartitions returns the partitions of topic and their leaders, as known by broker.
+	LookupPartitions(ctx context.Context, broker, topic string) ([]kafka.Partition, error)
+	// WriteMessages writes msgs to a partition of topic through its leader.
+	WriteMessages(ctx context.Context, leader kafka.Broker, topic string, partition int, msgs []kafka.Message) error
+}
+
+// kafkaProducer is a KafkaProducer connecting to the partition leaders of a topic for
+// every batch, so that no connections are held open between the writes of a replication.
+type kafkaProducer struct {
+	cluster kafkaCluster
+}
+
+// NewKafkaProducer returns a KafkaProducer using the Kafka wire protocol.
+func NewKafkaProducer() KafkaProducer {
+	const clientID = "influxdb-replication"
+	return &kafkaProducer{cluster: &dialCluster{clientID: clientID, dialer: &kafka.Dialer{ClientID: clientID}}}
+}
+
+func (p *kafkaProducer) Produce(ctx context.Context, brokers []string, topic string, msgs []KafkaMessage) error {
+	// Look up the topic's partitions through the first broker that answers.
+	var partitions []kafka.Partition
+	var err error
+	for _, broker := range brokers {
+		if partitions, err = p.cluster.LookupPartitions(ctx, broker, topic); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return kafka.UnknownTopicOrPartition
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(partitions))
+	leaders := make(map[int]kafka.Broker, len(partitions))
+	for i, part := range partitions {
+		ids[i] = part.ID
+		leaders[part.ID] = part.Leader
+	}
+	sort.Ints(ids)
+
+	// Assign the messages to partitions by key, the same way as other Kafka clients.
+	balancer := &kafka.Hash{}
+	batches := make(map[int][]kafka.Message)
+	for _, m := range msgs {
+		msg := kafka.Message{Key: m.Key, Value: m.Value}
+		id := balancer.Balance(msg, ids...)
+		batches[id] = append(batches[id], msg)
+	}
+
+	for id, batch := range batches {
+		if err := p.cluster.WriteMessages(ctx, leaders[id], topic, id, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dialCluster is a kafkaCluster dialing a new connection for every request.
+type dialCluster struct {
+	clientID string
+	dialer   *kafka.Dialer
+}
+
+func (c *dialCluster) LookupPartitions(ctx context.Context, broker, topic string) ([]kafka.Partition, error) {
+	return c.dialer.LookupPartitions(ctx, "tcp", broker, topic)
+}
+
+func (c *dialCluster) WriteMessages(ctx context.Context, leader kafka.Broker, topic string, partition int, msgs []kafka.Message) error {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", net.JoinHostPort(leader.Host, strconv.Itoa(leader.Port)))
+	if err != nil {
+		return err
+	}
+	conn := kafka.NewConnWith(nc, kafka.ConnConfig{ClientID: c.clientID, Topic: topic, Partition: partition})
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	_, err = conn.WriteMessages(msgs...)
+	return err
+}
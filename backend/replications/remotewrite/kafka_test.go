@@ -0,0 +1,126 @@
+package remotewrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster is a kafkaCluster recording the messages written to each partition.
+type fakeCluster struct {
+	// partitions are the partitions of every topic, by broker. Brokers without
+	// partitions fail lookups.
+	partitions map[string][]kafka.Partition
+	lookups    []string
+	written    map[int][]kafka.Message
+	leaders    map[int]kafka.Broker
+	writeErr   error
+}
+
+func (c *fakeCluster) LookupPartitions(_ context.Context, broker, topic string) ([]kafka.Partition, error) {
+	c.lookups = append(c.lookups, broker)
+	parts, ok := c.partitions[broker]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return parts, nil
+}
+
+func (c *fakeCluster) WriteMessages(_ context.Context, leader kafka.Broker, topic string, partition int, msgs []kafka.Message) error {
+	if c.writeErr != nil {
+		return c.writeErr
+	}
+	if c.written == nil {
+		c.written = make(map[int][]kafka.Message)
+		c.leaders = make(map[int]kafka.Broker)
+	}
+	c.written[partition] = append(c.written[partition], msgs...)
+	c.leaders[partition] = leader
+	return nil
+}
+
+func testPartitions() []kafka.Partition {
+	return []kafka.Partition{
+		{Topic: "metrics", ID: 0, Leader: kafka.Broker{Host: "kafka-1", Port: 9092, ID: 1}},
+		{Topic: "metrics", ID: 1, Leader: kafka.Broker{Host: "kafka-2", Port: 9092, ID: 2}},
+		{Topic: "metrics", ID: 2, Leader: kafka.Broker{Host: "kafka-1", Port: 9092, ID: 1}},
+	}
+}
+
+func TestKafkaProducer_Produce(t *testing.T) {
+	t.Parallel()
+
+	cluster := &fakeCluster{partitions: map[string][]kafka.Partition{"kafka-2:9092": testPartitions()}}
+	p := &kafkaProducer{cluster: cluster}
+
+	msgs := []KafkaMessage{
+		{Key: []byte("cpu,host=A"), Value: []byte("1")},
+		{Key: []byte("cpu,host=B"), Value: []byte("2")},
+		{Key: []byte("cpu,host=A"), Value: []byte("3")},
+		{Key: []byte("mem,host=A"), Value: []byte("4")},
+	}
+	require.NoError(t, p.Produce(context.Background(), []string{"kafka-1:9092", "kafka-2:9092"}, "metrics", msgs))
+
+	// The partitions are looked up through the first broker that answers.
+	require.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, cluster.lookups)
+
+	// Every message is written once, to the leader of its partition, and the
+	// messages of a key are written to the same partition in order.
+	partitionOf := make(map[string]int)
+	var values []string
+	for id, written := range cluster.written {
+		require.Equal(t, testPartitions()[id].Leader, cluster.leaders[id])
+		for _, m := range written {
+			if prev, ok := partitionOf[string(m.Key)]; ok {
+				require.Equal(t, prev, id, "messages of key %q written to different partitions", m.Key)
+			}
+			partitionOf[string(m.Key)] = id
+			values = append(values, string(m.Value))
+		}
+	}
+	require.ElementsMatch(t, []string{"1", "2", "3", "4"}, values)
+
+	var hostA []string
+	for _, m := range cluster.written[partitionOf["cpu,host=A"]] {
+		if string(m.Key) == "cpu,host=A" {
+			hostA = append(hostA, string(m.Value))
+		}
+	}
+	require.Equal(t, []string{"1", "3"}, hostA)
+}
+
+func TestKafkaProducer_ProduceErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no broker answers", func(t *testing.T) {
+		p := &kafkaProducer{cluster: &fakeCluster{}}
+		err := p.Produce(context.Background(), []string{"kafka-1:9092", "kafka-2:9092"}, "metrics", nil)
+		require.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		p := &kafkaProducer{cluster: &fakeCluster{partitions: map[string][]kafka.Partition{"kafka-1:9092": nil}}}
+		err := p.Produce(context.Background(), []string{"kafka-1:9092"}, "metrics", nil)
+		require.Equal(t, kafka.UnknownTopicOrPartition, err)
+	})
+
+	t.Run("write fails", func(t *testing.T) {
+		writeErr := errors.New("not leader for partition")
+		p := &kafkaProducer{cluster: &fakeCluster{
+			partitions: map[string][]kafka.Partition{"kafka-1:9092": testPartitions()},
+			writeErr:   writeErr,
+		}}
+		err := p.Produce(context.Background(), []string{"kafka-1:9092"}, "metrics", []KafkaMessage{{Key: []byte("cpu"), Value: []byte("1")}})
+		require.Equal(t, writeErr, err)
+	})
+
+	t.Run("validation writes nothing", func(t *testing.T) {
+		cluster := &fakeCluster{partitions: map[string][]kafka.Partition{"kafka-1:9092": testPartitions()}}
+		p := &kafkaProducer{cluster: cluster}
+		require.NoError(t, p.Produce(context.Background(), []string{"kafka-1:9092"}, "metrics", nil))
+		require.Empty(t, cluster.written)
+	})
+}
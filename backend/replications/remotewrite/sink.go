@@ -0,0 +1,330 @@
+package remotewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sink sends a batch of replicated data to a replication's remote. The data is gzipped line
+// protocol, as stored in the replication queue; an empty batch only checks that the remote
+// is reachable with the given configuration.
+//
+// A non-nil response is inspected by the writer for the status code and retry information,
+// even when an error is returned.
+type Sink interface {
+	Send(ctx context.Context, conf *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error)
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, conf *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error)
+
+func (f SinkFunc) Send(ctx context.Context, conf *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error) {
+	return f(ctx, conf, data, timeout)
+}
+
+// Sinks maps each remote type to the Sink used to send data to remotes of that type.
+type Sinks map[influxdb.RemoteType]Sink
+
+// DefaultSinks returns the sinks for every supported remote type.
+func DefaultSinks() Sinks {
+	return Sinks{
+		influxdb.RemoteTypeInfluxDB: SinkFunc(PostWrite),
+		influxdb.RemoteTypeHTTP:     &WebhookSink{},
+		influxdb.RemoteTypeKafka:    &KafkaSink{Producer: NewKafkaProducer()},
+	}
+}
+
+// Send sends data using the sink for the remote type of conf.
+func (s Sinks) Send(ctx context.Context, conf *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error) {
+	typ := conf.RemoteType
+	if typ == "" {
+		typ = influxdb.RemoteTypeInfluxDB
+	}
+	sink, ok := s[typ]
+	if !ok {
+		return nil, &ierrors.Error{
+			Code: ierrors.EInvalid,
+			Msg:  fmt.Sprintf("unsupported remote type %q", typ),
+		}
+	}
+	return sink.Send(ctx, conf, data, timeout)
+}
+
+// PostWebhook posts data to the URL of a generic HTTP remote, encoded in the remote's format.
+// Any 2xx response is a successful write. The remote's token, if any, is sent as a bearer token.
+//
+// PostWebhook doesn't reuse connections between calls; a WebhookSink should be used to send
+// the batches of a replication.
+func PostWebhook(ctx context.Context, config *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error) {
+	return (&WebhookSink{}).Send(ctx, config, data, timeout)
+}
+
+// WebhookSink posts data to generic HTTP remotes like PostWebhook, reusing the connections
+// to the remote across batches for as long as its TLS configuration doesn't change.
+type WebhookSink struct {
+	mu        sync.Mutex
+	tlsKey    webhookTLSKey
+	transport *http.Transport
+}
+
+// webhookTLSKey holds the fields of a replication's configuration used to build the TLS
+// configuration of its transport.
+type webhookTLSKey struct {
+	allowInsecureTLS bool
+	serverName       string
+	caCert           string
+	clientCert       string
+	clientKey        string
+}
+
+func (s *WebhookSink) Send(ctx context.Context, config *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error) {
+	body, header, err := encodeWebhookBody(config.RemoteFormat, data)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := s.transportFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.RemoteURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, invalidRemoteUrl(config.RemoteURL, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if len(config.ReplicationPath) > 0 {
+		req.Header.Set(influxdb.ReplicationOriginHeader, influxdb.FormatReplicationPath(config.ReplicationPath))
+	}
+	if config.RemoteToken != "" {
+		req.Header.Set("Authorization", "Bearer "+config.RemoteToken)
+	}
+
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res, &ierrors.Error{
+			Code: ierrors.EInvalid,
+			Msg:  fmt.Sprintf("invalid response code %d, must be 2xx", res.StatusCode),
+			Err:  ihttp.CheckError(res),
+		}
+	}
+	return res, nil
+}
+
+// transportFor returns the transport for the remote of config, replacing the current one
+// when the TLS configuration of the remote changed.
+func (s *WebhookSink) transportFor(config *influxdb.ReplicationHTTPConfig) (*http.Transport, error) {
+	key := webhookTLSKey{
+		allowInsecureTLS: config.AllowInsecureTLS,
+		serverName:       config.RemoteServerName,
+		caCert:           config.RemoteCACert,
+		clientCert:       config.ClientCert,
+		clientKey:        config.ClientKey,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.transport != nil && s.tlsKey == key {
+		return s.transport, nil
+	}
+
+	tlsConf, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if s.transport != nil {
+		s.transport.CloseIdleConnections()
+	}
+	s.tlsKey = key
+	s.transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConf,
+	}
+	return s.transport, nil
+}
+
+// encodeWebhookBody converts a batch of gzipped line protocol to the request body and headers
+// for the given format.
+func encodeWebhookBody(format influxdb.RemoteFormat, data []byte) ([]byte, http.Header, error) {
+	header := make(http.Header)
+	switch format {
+	case "", influxdb.RemoteFormatLineProtocol:
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		// Empty bodies, like those used for validation, aren't gzipped.
+		if len(data) > 0 {
+			header.Set("Content-Encoding", "gzip")
+		}
+		return data, header, nil
+
+	case influxdb.RemoteFormatJSON:
+		points, err := decodePoints(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		objs := make([]jsonPoint, 0, len(points))
+		for _, p := range points {
+			o, err := newJSONPoint(p)
+			if err != nil {
+				return nil, nil, err
+			}
+			objs = append(objs, o)
+		}
+		body, err := json.Marshal(objs)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", "application/json; charset=utf-8")
+		return body, header, nil
+
+	case influxdb.RemoteFormatPrometheus:
+		points, err := decodePoints(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		wr, err := remote.PointsToWriteRequest(points)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := proto.Marshal(wr)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", "application/x-protobuf")
+		header.Set("Content-Encoding", "snappy")
+		header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		return snappy.Encode(nil, b), header, nil
+
+	default:
+		return nil, nil, &ierrors.Error{
+			Code: ierrors.EInvalid,
+			Msg:  fmt.Sprintf("unsupported remote format %q", format),
+		}
+	}
+}
+
+// jsonPoint is the JSON encoding of a point sent to HTTP and Kafka remotes.
+type jsonPoint struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Timestamp   int64                  `json:"timestamp"`
+}
+
+func newJSONPoint(p models.Point) (jsonPoint, error) {
+	fields, err := p.Fields()
+	if err != nil {
+		return jsonPoint{}, err
+	}
+	return jsonPoint{
+		Measurement: string(p.Name()),
+		Tags:        p.Tags().Map(),
+		Fields:      fields,
+		Timestamp:   p.UnixNano(),
+	}, nil
+}
+
+// decodePoints parses a batch of gzipped line protocol. Empty batches have no points.
+func decodePoints(data []byte) ([]models.Point, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	lp, err := io.ReadAll(gzr)
+	if err != nil {
+		return nil, err
+	}
+	return models.ParsePoints(lp)
+}
+
+// KafkaMessage is a message published to a Kafka topic.
+type KafkaMessage struct {
+	Key   []byte
+	Value []byte
+}
+
+// KafkaProducer publishes messages to a topic of a Kafka-protocol cluster. Producing no
+// messages only checks that the topic can be reached through the brokers.
+type KafkaProducer interface {
+	Produce(ctx context.Context, brokers []string, topic string, msgs []KafkaMessage) error
+}
+
+// KafkaSink publishes each replicated point as a message to the topic of a Kafka remote,
+// keyed by its series key so that the points of a series stay ordered within a partition.
+type KafkaSink struct {
+	Producer KafkaProducer
+}
+
+func (s *KafkaSink) Send(ctx context.Context, config *influxdb.ReplicationHTTPConfig, data []byte, timeout time.Duration) (*http.Response, error) {
+	var brokers []string
+	for _, b := range strings.Split(config.RemoteURL, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, invalidRemoteUrl(config.RemoteURL, fmt.Errorf("no kafka brokers"))
+	}
+
+	points, err := decodePoints(data)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]KafkaMessage, 0, len(points))
+	for _, p := range points {
+		var value []byte
+		switch config.RemoteFormat {
+		case "", influxdb.RemoteFormatLineProtocol:
+			value = []byte(p.String())
+		case influxdb.RemoteFormatJSON:
+			o, err := newJSONPoint(p)
+			if err != nil {
+				return nil, err
+			}
+			if value, err = json.Marshal(o); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, &ierrors.Error{
+				Code: ierrors.EInvalid,
+				Msg:  fmt.Sprintf("unsupported remote format %q for kafka", config.RemoteFormat),
+			}
+		}
+		msgs = append(msgs, KafkaMessage{Key: p.Key(), Value: value})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := s.Producer.Produce(ctx, brokers, config.RemoteTopic, msgs); err != nil {
+		return nil, err
+	}
+	// Kafka has no HTTP response; report success like an InfluxDB remote would.
+	return &http.Response{StatusCode: http.StatusNoContent, Header: make(http.Header), Body: http.NoBody}, nil
+}
@@ -0,0 +1,286 @@
+package remotewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const testLP = "cpu,host=A usage=1.5 1000000000\ncpu,host=B usage=2.5 2000000000\n"
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, err := gzw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func TestPostWebhook(t *testing.T) {
+	t.Parallel()
+
+	data := gzipped(t, testLP)
+
+	tests := []struct {
+		format influxdb.RemoteFormat
+		check  func(t *testing.T, r *http.Request, body []byte)
+	}{
+		{
+			format: influxdb.RemoteFormatLineProtocol,
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+				require.Equal(t, data, body)
+			},
+		},
+		{
+			format: influxdb.RemoteFormatJSON,
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				require.Equal(t, "application/json; charset=utf-8", r.Header.Get("Content-Type"))
+				var got []jsonPoint
+				require.NoError(t, json.Unmarshal(body, &got))
+				require.Equal(t, []jsonPoint{
+					{Measurement: "cpu", Tags: map[string]string{"host": "A"}, Fields: map[string]interface{}{"usage": 1.5}, Timestamp: 1000000000},
+					{Measurement: "cpu", Tags: map[string]string{"host": "B"}, Fields: map[string]interface{}{"usage": 2.5}, Timestamp: 2000000000},
+				}, got)
+			},
+		},
+		{
+			format: influxdb.RemoteFormatPrometheus,
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+				b, err := snappy.Decode(nil, body)
+				require.NoError(t, err)
+				var wr remote.WriteRequest
+				require.NoError(t, proto.Unmarshal(b, &wr))
+				require.Len(t, wr.Timeseries, 2)
+				require.Equal(t, 1.5, wr.Timeseries[0].Samples[0].Value)
+				require.Equal(t, int64(1000), wr.Timeseries[0].Samples[0].Timestamp)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				tt.check(t, r, body)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer svr.Close()
+
+			conf := &influxdb.ReplicationHTTPConfig{
+				RemoteURL:    svr.URL,
+				RemoteToken:  "secret",
+				RemoteType:   influxdb.RemoteTypeHTTP,
+				RemoteFormat: tt.format,
+			}
+			res, err := PostWebhook(context.Background(), conf, data, time.Second)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusAccepted, res.StatusCode)
+		})
+	}
+
+	t.Run("non-2xx response", func(t *testing.T) {
+		t.Parallel()
+
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer svr.Close()
+
+		conf := &influxdb.ReplicationHTTPConfig{RemoteURL: svr.URL, RemoteType: influxdb.RemoteTypeHTTP}
+		res, err := PostWebhook(context.Background(), conf, data, time.Second)
+		require.Error(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	})
+}
+
+func TestWebhookSink_ReusesConnections(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var conns int
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	svr.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	svr.Start()
+	defer svr.Close()
+
+	sink := &WebhookSink{}
+	send := func(conf *influxdb.ReplicationHTTPConfig) {
+		res, err := sink.Send(context.Background(), conf, gzipped(t, testLP), time.Second)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		require.NoError(t, res.Body.Close())
+	}
+	newConns := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return conns
+	}
+
+	conf := &influxdb.ReplicationHTTPConfig{RemoteURL: svr.URL, RemoteType: influxdb.RemoteTypeHTTP}
+	send(conf)
+	send(conf)
+	require.Equal(t, 1, newConns())
+
+	// A change of the TLS configuration replaces the transport.
+	send(&influxdb.ReplicationHTTPConfig{RemoteURL: svr.URL, RemoteType: influxdb.RemoteTypeHTTP, AllowInsecureTLS: true})
+	require.Equal(t, 2, newConns())
+}
+
+func TestPostOriginHeader(t *testing.T) {
+	t.Parallel()
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "site-a,site-b", r.Header.Get(influxdb.ReplicationOriginHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer svr.Close()
+
+	for _, typ := range []influxdb.RemoteType{influxdb.RemoteTypeInfluxDB, influxdb.RemoteTypeHTTP} {
+		conf := &influxdb.ReplicationHTTPConfig{RemoteURL: svr.URL, RemoteType: typ, ReplicationPath: []string{"site-a", "site-b"}}
+		res, err := DefaultSinks().Send(context.Background(), conf, []byte{}, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+	}
+}
+
+type fakeProducer struct {
+	brokers []string
+	topic   string
+	msgs    []KafkaMessage
+	err     error
+}
+
+func (p *fakeProducer) Produce(_ context.Context, brokers []string, topic string, msgs []KafkaMessage) error {
+	p.brokers, p.topic, p.msgs = brokers, topic, msgs
+	return p.err
+}
+
+func TestKafkaSink(t *testing.T) {
+	t.Parallel()
+
+	data := gzipped(t, testLP)
+
+	t.Run("line protocol", func(t *testing.T) {
+		producer := &fakeProducer{}
+		sink := &KafkaSink{Producer: producer}
+		conf := &influxdb.ReplicationHTTPConfig{
+			RemoteURL:   "kafka-1:9092, kafka-2:9092",
+			RemoteType:  influxdb.RemoteTypeKafka,
+			RemoteTopic: "metrics",
+		}
+
+		res, err := sink.Send(context.Background(), conf, data, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, producer.brokers)
+		require.Equal(t, "metrics", producer.topic)
+		require.Equal(t, []KafkaMessage{
+			{Key: []byte("cpu,host=A"), Value: []byte("cpu,host=A usage=1.5 1000000000")},
+			{Key: []byte("cpu,host=B"), Value: []byte("cpu,host=B usage=2.5 2000000000")},
+		}, producer.msgs)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		producer := &fakeProducer{}
+		sink := &KafkaSink{Producer: producer}
+		conf := &influxdb.ReplicationHTTPConfig{
+			RemoteURL:    "kafka-1:9092",
+			RemoteType:   influxdb.RemoteTypeKafka,
+			RemoteFormat: influxdb.RemoteFormatJSON,
+			RemoteTopic:  "metrics",
+		}
+
+		_, err := sink.Send(context.Background(), conf, data, time.Second)
+		require.NoError(t, err)
+		require.Len(t, producer.msgs, 2)
+		require.JSONEq(t, `{"measurement":"cpu","tags":{"host":"A"},"fields":{"usage":1.5},"timestamp":1000000000}`, string(producer.msgs[0].Value))
+	})
+
+	t.Run("producer error", func(t *testing.T) {
+		producer := &fakeProducer{err: io.ErrUnexpectedEOF}
+		sink := &KafkaSink{Producer: producer}
+		conf := &influxdb.ReplicationHTTPConfig{RemoteURL: "kafka-1:9092", RemoteTopic: "metrics"}
+
+		res, err := sink.Send(context.Background(), conf, data, time.Second)
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+		require.Nil(t, res)
+	})
+
+	t.Run("no brokers", func(t *testing.T) {
+		sink := &KafkaSink{Producer: &fakeProducer{}}
+		conf := &influxdb.ReplicationHTTPConfig{RemoteURL: " , ", RemoteTopic: "metrics"}
+
+		_, err := sink.Send(context.Background(), conf, data, time.Second)
+		require.Error(t, err)
+	})
+}
+
+func TestSinksSend(t *testing.T) {
+	t.Parallel()
+
+	_, err := Sinks{}.Send(context.Background(), &influxdb.ReplicationHTTPConfig{RemoteType: "ftp"}, nil, time.Second)
+	require.Error(t, err)
+
+	var called bool
+	sinks := Sinks{influxdb.RemoteTypeInfluxDB: SinkFunc(func(context.Context, *influxdb.ReplicationHTTPConfig, []byte, time.Duration) (*http.Response, error) {
+		called = true
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})}
+	// Remotes created before remote types existed have no type, and are InfluxDB remotes.
+	_, err = sinks.Send(context.Background(), &influxdb.ReplicationHTTPConfig{}, nil, time.Second)
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestWriteToKafka(t *testing.T) {
+	t.Parallel()
+
+	w, configStore, _ := testWriter(t)
+	producer := &fakeProducer{}
+	w.sinks = Sinks{influxdb.RemoteTypeKafka: &KafkaSink{Producer: producer}}
+
+	conf := &influxdb.ReplicationHTTPConfig{
+		RemoteURL:   "kafka-1:9092",
+		RemoteType:  influxdb.RemoteTypeKafka,
+		RemoteTopic: "metrics",
+	}
+	configStore.EXPECT().GetFullHTTPConfig(gomock.Any(), testID).Return(conf, nil)
+	configStore.EXPECT().UpdateResponseInfo(gomock.Any(), testID, http.StatusNoContent, "").Return(nil)
+
+	_, err := w.Write(gzipped(t, testLP), 1)
+	require.NoError(t, err)
+	require.Len(t, producer.msgs, 2)
+}
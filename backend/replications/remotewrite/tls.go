@@ -0,0 +1,45 @@
+package remotewrite
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// tlsConfig returns the TLS configuration for connections to the remote of a replication: the
+// CA certificates trusted instead of the system's, the server name expected from the remote's
+// certificate, and the client certificate presented to the remote.
+func tlsConfig(config *influxdb.ReplicationHTTPConfig) (*tls.Config, error) {
+	conf := &tls.Config{
+		InsecureSkipVerify: config.AllowInsecureTLS,
+		ServerName:         config.RemoteServerName,
+	}
+
+	if config.RemoteCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.RemoteCACert)) {
+			return nil, &ierrors.Error{
+				Code: ierrors.EInvalid,
+				Msg:  "remote CA certificate must contain PEM-encoded certificates",
+			}
+		}
+		conf.RootCAs = pool
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCert), []byte(config.ClientKey))
+		if err != nil {
+			return nil, &ierrors.Error{
+				Code: ierrors.EInvalid,
+				Msg:  fmt.Sprintf("invalid client certificate for remote %q", config.RemoteURL),
+				Err:  err,
+			}
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,141 @@
+package remotewrite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostTLS(t *testing.T) {
+	t.Parallel()
+
+	clientCert, clientKey, clientX509 := testClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientX509)
+
+	// The server only accepts clients presenting the client certificate.
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	svr.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	svr.StartTLS()
+	t.Cleanup(svr.Close)
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svr.Certificate().Raw}))
+
+	for _, post := range []struct {
+		name string
+		fn   SinkFunc
+		typ  influxdb.RemoteType
+	}{
+		{name: "influxdb", fn: PostWrite, typ: influxdb.RemoteTypeInfluxDB},
+		{name: "http", fn: PostWebhook, typ: influxdb.RemoteTypeHTTP},
+	} {
+		post := post
+		t.Run(post.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &influxdb.ReplicationHTTPConfig{
+				RemoteURL:        svr.URL,
+				RemoteType:       post.typ,
+				RemoteCACert:     caCert,
+				RemoteServerName: "example.com",
+				ClientCert:       clientCert,
+				ClientKey:        clientKey,
+			}
+			res, err := post.fn(context.Background(), conf, []byte{}, time.Second)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+			// The server's certificate isn't valid for other names.
+			noSNI := *conf
+			noSNI.RemoteServerName = "influxdb.internal"
+			_, err = post.fn(context.Background(), &noSNI, []byte{}, time.Second)
+			require.Error(t, err)
+
+			// The server's certificate isn't trusted without the CA certificate.
+			noCA := *conf
+			noCA.RemoteCACert = ""
+			_, err = post.fn(context.Background(), &noCA, []byte{}, time.Second)
+			require.Error(t, err)
+
+			// The server rejects clients without a certificate.
+			noCert := *conf
+			noCert.ClientCert, noCert.ClientKey = "", ""
+			_, err = post.fn(context.Background(), &noCert, []byte{}, time.Second)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	cert, key, _ := testClientCert(t)
+
+	conf, err := tlsConfig(&influxdb.ReplicationHTTPConfig{
+		AllowInsecureTLS: true,
+		RemoteServerName: "influxdb.internal",
+		RemoteCACert:     cert,
+		ClientCert:       cert,
+		ClientKey:        key,
+	})
+	require.NoError(t, err)
+	require.True(t, conf.InsecureSkipVerify)
+	require.Equal(t, "influxdb.internal", conf.ServerName)
+	require.NotNil(t, conf.RootCAs)
+	require.Len(t, conf.Certificates, 1)
+
+	// Without a CA certificate, the system's are trusted.
+	conf, err = tlsConfig(&influxdb.ReplicationHTTPConfig{})
+	require.NoError(t, err)
+	require.Nil(t, conf.RootCAs)
+	require.Empty(t, conf.Certificates)
+
+	_, err = tlsConfig(&influxdb.ReplicationHTTPConfig{RemoteCACert: "not a certificate"})
+	require.Equal(t, ierrors.EInvalid, ierrors.ErrorCode(err))
+
+	_, err = tlsConfig(&influxdb.ReplicationHTTPConfig{ClientCert: cert})
+	require.Equal(t, ierrors.EInvalid, ierrors.ErrorCode(err))
+}
+
+// testClientCert returns a PEM-encoded self-signed client certificate and its key.
+func testClientCert(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "replication"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(cert), string(key), parsed
+}
@@ -1,6 +1,8 @@
 package remotewrite
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"math"
@@ -68,11 +70,15 @@ type writer struct {
 	maximumBackoffTime            time.Duration
 	maximumAttemptsForBackoffTime int
 	clientTimeout                 time.Duration
+	sinks                         Sinks
+	originID                      string
 	done                          chan struct{}
 	waitFunc                      waitFunc // used for testing
 }
 
-func NewWriter(replicationID platform.ID, store HttpConfigStore, metrics *metrics.ReplicationsMetrics, logger *zap.Logger, done chan struct{}) *writer {
+// NewWriter returns a writer sending the data of a replication to its remote. The originID is
+// the instance ID of the local instance, sent along with data written locally.
+func NewWriter(replicationID platform.ID, store HttpConfigStore, metrics *metrics.ReplicationsMetrics, logger *zap.Logger, done chan struct{}, originID string) *writer {
 	return &writer{
 		replicationID:                 replicationID,
 		configStore:                   store,
@@ -81,6 +87,8 @@ func NewWriter(replicationID platform.ID, store HttpConfigStore, metrics *metric
 		maximumBackoffTime:            maximumBackoffTime,
 		maximumAttemptsForBackoffTime: maximumAttempts,
 		clientTimeout:                 DefaultTimeout,
+		sinks:                         DefaultSinks(),
+		originID:                      originID,
 		done:                          done,
 		waitFunc: func(t time.Duration) <-chan time.Time {
 			return time.After(t)
@@ -88,6 +96,22 @@ func NewWriter(replicationID platform.ID, store HttpConfigStore, metrics *metric
 	}
 }
 
+// replicationPath returns the replication path stored in the gzip header of a batch. Batches
+// enqueued without a path, like those enqueued before paths were recorded, were written locally.
+func replicationPath(data []byte, originID string) []string {
+	if len(data) > 0 {
+		if gzr, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+			if path := influxdb.ParseReplicationPath(gzr.Comment); len(path) > 0 {
+				return path
+			}
+		}
+	}
+	if originID == "" {
+		return nil
+	}
+	return []string{originID}
+}
+
 func (w *writer) Write(data []byte, attempts int) (backoff time.Duration, err error) {
 	cancelOnce := &sync.Once{}
 	// Cancel any outstanding HTTP requests if the replicationQueue is closed.
@@ -111,8 +135,18 @@ func (w *writer) Write(data []byte, attempts int) (backoff time.Duration, err er
 	if err != nil {
 		return w.backoff(attempts), err
 	}
+	conf.ReplicationPath = replicationPath(data, w.originID)
 
-	res, postWriteErr := PostWrite(ctx, conf, data, w.clientTimeout)
+	// Batches enqueued before the remote's instance ID was set may have been replicated through
+	// the remote already; they are dropped rather than sent back to it.
+	for _, id := range conf.ReplicationPath {
+		if conf.RemoteInstanceID != "" && id == conf.RemoteInstanceID {
+			w.logger.Debug("dropped data replicated through the remote", zap.Int("bytes", len(data)))
+			return 0, nil
+		}
+	}
+
+	res, postWriteErr := w.sinks.Send(ctx, conf, data, w.clientTimeout)
 	res, msg, ok := normalizeResponse(res, postWriteErr)
 	if !ok {
 		// Update Response info:
@@ -211,8 +245,18 @@ func PostWrite(ctx context.Context, config *influxdb.ReplicationHTTPConfig, data
 		Token:            &config.RemoteToken,
 		AllowInsecureTLS: config.AllowInsecureTLS,
 	}
+	tlsConf, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	conf := api.NewAPIConfig(params)
 	conf.HTTPClient.Timeout = timeout
+	if len(config.ReplicationPath) > 0 {
+		conf.AddDefaultHeader(influxdb.ReplicationOriginHeader, influxdb.FormatReplicationPath(config.ReplicationPath))
+	}
+	if transport, ok := conf.HTTPClient.Transport.(*http.Transport); ok {
+		transport.TLSClientConfig = tlsConf
+	}
 	client := api.NewAPIClient(conf).WriteApi
 
 	var bucket string
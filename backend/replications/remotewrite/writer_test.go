@@ -1,6 +1,8 @@
 package remotewrite
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -35,7 +37,7 @@ func testWriter(t *testing.T) (*writer, *replicationsMock.MockHttpConfigStore, c
 	ctrl := gomock.NewController(t)
 	configStore := replicationsMock.NewMockHttpConfigStore(ctrl)
 	done := make(chan struct{})
-	w := NewWriter(testID, configStore, metrics.NewReplicationsMetrics(), zaptest.NewLogger(t), done)
+	w := NewWriter(testID, configStore, metrics.NewReplicationsMetrics(), zaptest.NewLogger(t), done, "")
 	return w, configStore, done
 }
 
@@ -127,6 +129,33 @@ func TestWrite(t *testing.T) {
 		require.NoError(t, actualErr)
 	})
 
+	t.Run("batch replicated through the remote", func(t *testing.T) {
+		var buf bytes.Buffer
+		gzw := gzip.NewWriter(&buf)
+		gzw.Comment = "site-a,site-b"
+		_, err := gzw.Write([]byte(testLP))
+		require.NoError(t, err)
+		require.NoError(t, gzw.Close())
+
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("batch was sent back to a remote on its path")
+		}))
+		defer svr.Close()
+
+		testConfig := &influxdb.ReplicationHTTPConfig{
+			RemoteURL:        svr.URL,
+			RemoteInstanceID: "site-a",
+		}
+
+		w, configStore, _ := testWriter(t)
+
+		// The batch is dropped without being sent, so no response info is recorded.
+		configStore.EXPECT().GetFullHTTPConfig(gomock.Any(), testID).Return(testConfig, nil)
+		backoff, actualErr := w.Write(buf.Bytes(), 0)
+		require.NoError(t, actualErr)
+		require.Zero(t, backoff)
+	})
+
 	t.Run("error updating response info", func(t *testing.T) {
 		wantErr := errors.New("o no")
 
@@ -528,3 +557,18 @@ func TestWaitTimeFromHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestReplicationPath(t *testing.T) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	gzw.Comment = "site-a,site-b"
+	_, err := gzw.Write([]byte(testLP))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	require.Equal(t, []string{"site-a", "site-b"}, replicationPath(buf.Bytes(), "site-b"))
+	// Batches without a path were written locally.
+	require.Equal(t, []string{"site-b"}, replicationPath(gzipped(t, testLP), "site-b"))
+	require.Equal(t, []string{"site-b"}, replicationPath(nil, "site-b"))
+	require.Nil(t, replicationPath(gzipped(t, testLP), ""))
+}
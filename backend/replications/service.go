@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/sqlite"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
@@ -38,15 +39,16 @@ func errLocalBucketNotFound(id platform.ID, cause error) error {
 	}
 }
 
-func NewService(sqlStore *sqlite.SqlStore, bktSvc BucketService, localWriter storage.PointsWriter, log *zap.Logger, enginePath string, instanceID string) (*service, *metrics.ReplicationsMetrics) {
+func NewService(sqlStore *sqlite.SqlStore, bktSvc BucketService, localWriter storage.PointsWriter, readsStore reads.Store, secrets influxdb.SecretService, log *zap.Logger, enginePath string, instanceID string) (*service, *metrics.ReplicationsMetrics) {
 	metrs := metrics.NewReplicationsMetrics()
-	store := internal.NewStore(sqlStore)
+	store := internal.NewStore(sqlStore, secrets)
 
 	return &service{
 		store:         store,
 		idGenerator:   snowflake.NewIDGenerator(),
 		bucketService: bktSvc,
 		localWriter:   localWriter,
+		readsStore:    readsStore,
 		validator:     internal.NewValidator(),
 		log:           log,
 		durableQueueManager: internal.NewDurableQueueManager(
@@ -54,6 +56,7 @@ func NewService(sqlStore *sqlite.SqlStore, bktSvc BucketService, localWriter sto
 			filepath.Join(enginePath, "replicationq"),
 			metrs,
 			store,
+			instanceID,
 		),
 		maxRemoteWriteBatchSize: maxRemoteWriteBatchSize,
 		maxRemoteWritePointSize: maxRemoteWritePointSize,
@@ -81,6 +84,10 @@ type DurableQueueManager interface {
 	CloseAll() error
 	EnqueueData(replicationID platform.ID, data []byte, numPoints int) error
 	GetReplications(orgId platform.ID, localBucketID platform.ID) []platform.ID
+	SetQueuePaused(replicationID platform.ID, paused bool) error
+	PeekQueue(replicationID platform.ID, deadLetter bool) ([]byte, error)
+	SkipQueueHead(replicationID platform.ID, deadLetter bool) ([]byte, error)
+	PurgeQueue(replicationID platform.ID, deadLetter bool) error
 }
 
 type ServiceStore interface {
@@ -94,6 +101,7 @@ type ServiceStore interface {
 	PopulateRemoteHTTPConfig(context.Context, platform.ID, *influxdb.ReplicationHTTPConfig) error
 	GetFullHTTPConfig(context.Context, platform.ID) (*influxdb.ReplicationHTTPConfig, error)
 	DeleteBucketReplications(context.Context, platform.ID) ([]platform.ID, error)
+	SetReplicationPaused(context.Context, platform.ID, bool) error
 }
 
 type service struct {
@@ -103,10 +111,19 @@ type service struct {
 	validator               ReplicationValidator
 	durableQueueManager     DurableQueueManager
 	localWriter             storage.PointsWriter
+	readsStore              reads.Store
 	log                     *zap.Logger
 	maxRemoteWriteBatchSize int
 	maxRemoteWritePointSize int
 	instanceID              string
+
+	// filters holds the point filters of the replications which have one.
+	filtersMu sync.RWMutex
+	filters   map[platform.ID]*pointFilter
+
+	// backfills holds the latest backfill of each replication which has been backfilled.
+	backfillsMu sync.Mutex
+	backfills   map[platform.ID]*backfill
 }
 
 func (s *service) ListReplications(ctx context.Context, filter influxdb.ReplicationListFilter) (*influxdb.Replications, error) {
@@ -136,6 +153,7 @@ func (s *service) ListReplications(ctx context.Context, filter influxdb.Replicat
 	}
 	for i := range rs.Replications {
 		rs.Replications[i].RemainingBytesToBeSynced = rsizes[rs.Replications[i].ID]
+		rs.Replications[i].Backfill = s.backfillStatus(rs.Replications[i].ID)
 	}
 
 	return rs, nil
@@ -152,6 +170,10 @@ func (s *service) CreateReplication(ctx context.Context, request influxdb.Create
 		return nil, fmt.Errorf("please supply one of: remoteBucketID, remoteBucketName")
 	}
 
+	if err := request.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
 	if _, err := s.bucketService.FindBucketByID(ctx, request.LocalBucketID); err != nil {
 		return nil, errLocalBucketNotFound(request.LocalBucketID, err)
 	}
@@ -169,11 +191,16 @@ func (s *service) CreateReplication(ctx context.Context, request influxdb.Create
 
 		return nil, err
 	}
+	s.setFilter(r.ID, r.Filter)
 
 	return r, nil
 }
 
 func (s *service) ValidateNewReplication(ctx context.Context, request influxdb.CreateReplicationRequest) error {
+	if err := request.Filter.Validate(); err != nil {
+		return err
+	}
+
 	if _, err := s.bucketService.FindBucketByID(ctx, request.LocalBucketID); err != nil {
 		return errLocalBucketNotFound(request.LocalBucketID, err)
 	}
@@ -209,11 +236,16 @@ func (s *service) GetReplication(ctx context.Context, id platform.ID) (*influxdb
 		return nil, err
 	}
 	r.RemainingBytesToBeSynced = rsizes[r.ID]
+	r.Backfill = s.backfillStatus(r.ID)
 
 	return r, nil
 }
 
 func (s *service) UpdateReplication(ctx context.Context, id platform.ID, request influxdb.UpdateReplicationRequest) (*influxdb.Replication, error) {
+	if err := request.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
 	s.store.Lock()
 	defer s.store.Unlock()
 
@@ -228,6 +260,7 @@ func (s *service) UpdateReplication(ctx context.Context, id platform.ID, request
 			return nil, err
 		}
 	}
+	s.setFilter(id, r.Filter)
 
 	sizes, err := s.durableQueueManager.CurrentQueueSizes([]platform.ID{r.ID})
 	if err != nil {
@@ -239,11 +272,16 @@ func (s *service) UpdateReplication(ctx context.Context, id platform.ID, request
 		return nil, err
 	}
 	r.RemainingBytesToBeSynced = rsizes[r.ID]
+	r.Backfill = s.backfillStatus(r.ID)
 
 	return r, nil
 }
 
 func (s *service) ValidateUpdatedReplication(ctx context.Context, id platform.ID, request influxdb.UpdateReplicationRequest) error {
+	if err := request.Filter.Validate(); err != nil {
+		return err
+	}
+
 	baseConfig, err := s.store.GetFullHTTPConfig(ctx, id)
 	if err != nil {
 		return err
@@ -275,6 +313,8 @@ func (s *service) DeleteReplication(ctx context.Context, id platform.ID) error {
 	if err := s.store.DeleteReplication(ctx, id); err != nil {
 		return err
 	}
+	s.setFilter(id, nil)
+	s.stopBackfill(id)
 
 	if err := s.durableQueueManager.DeleteQueue(id); err != nil {
 		return err
@@ -295,6 +335,8 @@ func (s *service) DeleteBucketReplications(ctx context.Context, localBucketID pl
 	errOccurred := false
 	deletedStrings := make([]string, 0, len(deletedIDs))
 	for _, id := range deletedIDs {
+		s.setFilter(id, nil)
+		s.stopBackfill(id)
 		if err := s.durableQueueManager.DeleteQueue(id); err != nil {
 			s.log.Error("durable queue remaining on disk after deletion failure", zap.Error(err), zap.String("id", id.String()))
 			errOccurred = true
@@ -333,6 +375,9 @@ type batch struct {
 	numPoints int
 }
 
+// WritePoints writes points to a local bucket, and enqueues them for the bucket's replications.
+// Points replicated from another instance are only written locally when they were already
+// replicated through this instance; see influxdb.ReplicationOriginHeader.
 func (s *service) WritePoints(ctx context.Context, orgID platform.ID, bucketID platform.ID, points []models.Point) error {
 	replications := s.durableQueueManager.GetReplications(orgID, bucketID)
 
@@ -341,15 +386,51 @@ func (s *service) WritePoints(ctx context.Context, orgID platform.ID, bucketID p
 		return s.localWriter.WritePoints(ctx, orgID, bucketID, points)
 	}
 
-	if s.instanceID != "" {
+	// Replicated points are forwarded along the path they came from, unless they already went
+	// through this instance. They keep the _instance_id tag of the instance they were first
+	// written to.
+	path, replicated := influxdb.ReplicationPathFromContext(ctx)
+	if replicated && (s.instanceID == "" || onReplicationPath(path, s.instanceID)) {
+		s.log.Debug("Not replicating points already replicated through this instance",
+			zap.String("path", influxdb.FormatReplicationPath(path)), zap.Int("points", len(points)))
+		return s.localWriter.WritePoints(ctx, orgID, bucketID, points)
+	}
+	if !replicated && s.instanceID != "" {
 		for i := range points {
 			points[i].AddTag("_instance_id", s.instanceID)
 		}
 	}
+	if s.instanceID != "" {
+		path = append(path[:len(path):len(path)], s.instanceID)
+	}
+	if replicated {
+		var err error
+		if replications, err = s.replicationsOffPath(ctx, replications, path); err != nil {
+			return err
+		}
+		if len(replications) == 0 {
+			return s.localWriter.WritePoints(ctx, orgID, bucketID, points)
+		}
+	}
+
+	// Apply the replications' filters up front, since reading a point's tags and fields
+	// is not safe while it is concurrently being written locally.
+	filtered := make(map[platform.ID][]models.Point)
+	for _, id := range replications {
+		f := s.filterFor(id)
+		if f == nil {
+			continue
+		}
+		ps, err := f.Filter(points)
+		if err != nil {
+			return fmt.Errorf("failed to filter points for replication %q: %w", id, err)
+		}
+		filtered[id] = ps
+	}
 
 	// Concurrently...
 	var egroup errgroup.Group
-	var batches []*batch
+	batches := make(map[platform.ID][]*batch, len(replications))
 
 	// 1. Write points to local TSM
 	egroup.Go(func() error {
@@ -358,43 +439,30 @@ func (s *service) WritePoints(ctx context.Context, orgID platform.ID, bucketID p
 	// 2. Serialize points to gzipped line protocol, to be enqueued for replication if the local write succeeds.
 	//    We gzip the LP to take up less room on disk. On the other end of the queue, we can send the gzip data
 	//    directly to the remote API without needing to decompress it.
+	//    Replications without a filter share the batches of the unfiltered points.
 	egroup.Go(func() error {
-		// Set up an initial batch
-		batches = append(batches, &batch{
-			data:      &bytes.Buffer{},
-			numPoints: 0,
-		})
-
-		currentBatchSize := 0
-		gzw := gzip.NewWriter(batches[0].data)
-
-		// Iterate through points and compress in batches
-		for count, p := range points {
-			// If current point will cause this batch to exceed max size, start a new batch for it first
-			if s.startNewBatch(currentBatchSize, p.StringSize(), count) {
-				batches = append(batches, &batch{
-					data:      &bytes.Buffer{},
-					numPoints: 0,
-				})
-
-				if err := gzw.Close(); err != nil {
-					return err
+		var unfiltered []*batch
+		for _, id := range replications {
+			ps, ok := filtered[id]
+			if !ok {
+				if unfiltered == nil {
+					b, err := s.serializeBatches(points, path)
+					if err != nil {
+						return err
+					}
+					unfiltered = b
 				}
-				currentBatchSize = 0
-				gzw = gzip.NewWriter(batches[len(batches)-1].data)
+				batches[id] = unfiltered
+				continue
 			}
-
-			// Compress point and append to buffer
-			if _, err := gzw.Write(append([]byte(p.PrecisionString("ns")), '\n')); err != nil {
-				_ = gzw.Close()
-				return fmt.Errorf("failed to serialize points for replication: %w", err)
+			if len(ps) == 0 {
+				continue
 			}
-
-			batches[len(batches)-1].numPoints += 1
-			currentBatchSize += p.StringSize()
-		}
-		if err := gzw.Close(); err != nil {
-			return err
+			b, err := s.serializeBatches(ps, path)
+			if err != nil {
+				return err
+			}
+			batches[id] = b
 		}
 		return nil
 	})
@@ -412,7 +480,7 @@ func (s *service) WritePoints(ctx context.Context, orgID platform.ID, bucketID p
 			defer wg.Done()
 
 			// Iterate through batches and enqueue each
-			for _, batch := range batches {
+			for _, batch := range batches[id] {
 				if err := s.durableQueueManager.EnqueueData(id, batch.data.Bytes(), batch.numPoints); err != nil {
 					s.log.Error("Failed to enqueue points for replication", zap.String("id", id.String()), zap.Error(err))
 				}
@@ -432,11 +500,13 @@ func (s *service) Open(ctx context.Context) error {
 
 	trackedReplicationsMap := make(map[platform.ID]*influxdb.TrackedReplication)
 	for _, r := range trackedReplications.Replications {
+		s.setFilter(r.ID, r.Filter)
 		trackedReplicationsMap[r.ID] = &influxdb.TrackedReplication{
 			MaxQueueSizeBytes: r.MaxQueueSizeBytes,
 			MaxAgeSeconds:     r.MaxAgeSeconds,
 			OrgID:             r.OrgID,
 			LocalBucketID:     r.LocalBucketID,
+			Paused:            r.Paused,
 		}
 	}
 
@@ -448,12 +518,115 @@ func (s *service) Open(ctx context.Context) error {
 }
 
 func (s *service) Close() error {
+	s.stopBackfills()
 	if err := s.durableQueueManager.CloseAll(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// serializeBatches serializes points to gzipped line protocol, split into batches no larger
+// than the remote write limits. The replication path of the points, ending with the local
+// instance, is stored in the comment of the gzip header of the batches, which is ignored by
+// remotes decompressing them.
+func (s *service) serializeBatches(points []models.Point, path []string) ([]*batch, error) {
+	// Set up an initial batch
+	batches := []*batch{{
+		data:      &bytes.Buffer{},
+		numPoints: 0,
+	}}
+
+	currentBatchSize := 0
+	gzw := gzip.NewWriter(batches[0].data)
+	gzw.Comment = influxdb.FormatReplicationPath(path)
+
+	// Iterate through points and compress in batches
+	for count, p := range points {
+		// If current point will cause this batch to exceed max size, start a new batch for it first
+		if s.startNewBatch(currentBatchSize, p.StringSize(), count) {
+			batches = append(batches, &batch{
+				data:      &bytes.Buffer{},
+				numPoints: 0,
+			})
+
+			if err := gzw.Close(); err != nil {
+				return nil, err
+			}
+			currentBatchSize = 0
+			gzw = gzip.NewWriter(batches[len(batches)-1].data)
+			gzw.Comment = influxdb.FormatReplicationPath(path)
+		}
+
+		// Compress point and append to buffer
+		if _, err := gzw.Write(append([]byte(p.PrecisionString("ns")), '\n')); err != nil {
+			_ = gzw.Close()
+			return nil, fmt.Errorf("failed to serialize points for replication: %w", err)
+		}
+
+		batches[len(batches)-1].numPoints += 1
+		currentBatchSize += p.StringSize()
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// replicationsOffPath returns the replications whose remote is not on the replication path of
+// replicated points, leaving out those which would send the points back to a remote they were
+// already replicated through.
+func (s *service) replicationsOffPath(ctx context.Context, replications []platform.ID, path []string) ([]platform.ID, error) {
+	ids := make([]platform.ID, 0, len(replications))
+	for _, id := range replications {
+		conf, err := s.store.GetFullHTTPConfig(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if conf.RemoteInstanceID != "" && onReplicationPath(path, conf.RemoteInstanceID) {
+			s.log.Debug("Not replicating points back to a remote they were replicated through",
+				zap.String("id", id.String()), zap.String("path", influxdb.FormatReplicationPath(path)))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// onReplicationPath returns whether the instance with the given ID is on path.
+func onReplicationPath(path []string, instanceID string) bool {
+	for _, id := range path {
+		if id == instanceID {
+			return true
+		}
+	}
+	return false
+}
+
+// setFilter records the point filter of the replication with the given ID, replacing any
+// existing filter. A nil or empty filter removes it.
+func (s *service) setFilter(id platform.ID, f *influxdb.ReplicationFilter) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	pf := newPointFilter(f)
+	if pf == nil {
+		delete(s.filters, id)
+		return
+	}
+	if s.filters == nil {
+		s.filters = make(map[platform.ID]*pointFilter)
+	}
+	s.filters[id] = pf
+}
+
+// filterFor returns the point filter of the replication with the given ID, or nil if all
+// points are replicated.
+func (s *service) filterFor(id platform.ID) *pointFilter {
+	s.filtersMu.RLock()
+	defer s.filtersMu.RUnlock()
+	return s.filters[id]
+}
+
 func (s *service) startNewBatch(currentSize, nextSize, pointCount int) bool {
 	return currentSize+nextSize > s.maxRemoteWriteBatchSize ||
 		pointCount > 0 && pointCount%s.maxRemoteWritePointSize == 0
@@ -327,6 +327,21 @@ func TestValidateNewReplication(t *testing.T) {
 	}
 }
 
+func TestValidateNewReplication_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newTestService(t)
+
+	req := createReq
+	req.Filter = &influxdb.ReplicationFilter{
+		Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_["}},
+	}
+
+	// The filter is rejected before any of the dependencies are consulted.
+	err := svc.ValidateNewReplication(ctx, req)
+	require.Equal(t, ierrors.EInvalid, ierrors.ErrorCode(err))
+}
+
 func TestGetReplication(t *testing.T) {
 	t.Parallel()
 
@@ -732,6 +747,115 @@ disk,host=C value=1.3 1000000000`)
 	require.NoError(t, svc.WritePoints(ctx, orgID, id1, points))
 }
 
+func TestWritePoints_Replicated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("forwarded along the path", func(t *testing.T) {
+		svc, mocks := newTestService(t)
+		svc.instanceID = "site-b"
+
+		mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication1.ID})
+
+		points, err := models.ParsePointsString(`cpu,host=A,_instance_id=site-a value=1.1 1000000000`)
+		require.NoError(t, err)
+
+		// Points replicated from another instance keep the instance ID they were first written
+		// with, and are enqueued with the local instance added to their path.
+		mocks.serviceStore.EXPECT().GetFullHTTPConfig(gomock.Any(), replication1.ID).
+			Return(&influxdb.ReplicationHTTPConfig{RemoteInstanceID: "site-c"}, nil)
+		mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+		mocks.durableQueueManager.EXPECT().
+			EnqueueData(replication1.ID, gomock.Any(), 1).
+			DoAndReturn(func(_ platform.ID, data []byte, _ int) error {
+				gzr, err := gzip.NewReader(bytes.NewReader(data))
+				require.NoError(t, err)
+				require.Equal(t, "site-a,site-b", gzr.Comment)
+				checkCompressedData(t, data, points)
+				return nil
+			})
+
+		rctx := influxdb.ContextWithReplicationPath(ctx, []string{"site-a"})
+		require.NoError(t, svc.WritePoints(rctx, orgID, id1, points))
+		require.Equal(t, "cpu,_instance_id=site-a,host=A value=1.1 1000000000", points[0].String())
+	})
+
+	t.Run("not sent back to a remote on the path", func(t *testing.T) {
+		svc, mocks := newTestService(t)
+		svc.instanceID = "site-b"
+
+		mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication1.ID, replication2.ID})
+
+		points, err := models.ParsePointsString(`cpu,host=A,_instance_id=site-a value=1.1 1000000000`)
+		require.NoError(t, err)
+
+		// site-a and site-b replicate to each other: the points came from site-a, so they are
+		// only enqueued for the replication to site-c.
+		mocks.serviceStore.EXPECT().GetFullHTTPConfig(gomock.Any(), replication1.ID).
+			Return(&influxdb.ReplicationHTTPConfig{RemoteInstanceID: "site-a"}, nil)
+		mocks.serviceStore.EXPECT().GetFullHTTPConfig(gomock.Any(), replication2.ID).
+			Return(&influxdb.ReplicationHTTPConfig{RemoteInstanceID: "site-c"}, nil)
+		mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+		mocks.durableQueueManager.EXPECT().
+			EnqueueData(replication2.ID, gomock.Any(), 1).
+			DoAndReturn(func(_ platform.ID, data []byte, _ int) error {
+				checkCompressedData(t, data, points)
+				return nil
+			})
+
+		rctx := influxdb.ContextWithReplicationPath(ctx, []string{"site-a"})
+		require.NoError(t, svc.WritePoints(rctx, orgID, id1, points))
+	})
+
+	t.Run("only remote on the path", func(t *testing.T) {
+		svc, mocks := newTestService(t)
+		svc.instanceID = "site-b"
+
+		mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication1.ID})
+
+		points, err := models.ParsePointsString(`cpu,host=A,_instance_id=site-a value=1.1 1000000000`)
+		require.NoError(t, err)
+
+		// Points replicated from the only remote of the bucket are only written locally.
+		mocks.serviceStore.EXPECT().GetFullHTTPConfig(gomock.Any(), replication1.ID).
+			Return(&influxdb.ReplicationHTTPConfig{RemoteInstanceID: "site-a"}, nil)
+		mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+
+		rctx := influxdb.ContextWithReplicationPath(ctx, []string{"site-a"})
+		require.NoError(t, svc.WritePoints(rctx, orgID, id1, points))
+	})
+
+	t.Run("already on the path", func(t *testing.T) {
+		svc, mocks := newTestService(t)
+		svc.instanceID = "site-a"
+
+		mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication1.ID})
+
+		points, err := models.ParsePointsString(`cpu,host=A,_instance_id=site-a value=1.1 1000000000`)
+		require.NoError(t, err)
+
+		// Points that went through this instance before are only written locally, ending the loop.
+		mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+
+		rctx := influxdb.ContextWithReplicationPath(ctx, []string{"site-a", "site-b"})
+		require.NoError(t, svc.WritePoints(rctx, orgID, id1, points))
+	})
+
+	t.Run("no instance ID", func(t *testing.T) {
+		svc, mocks := newTestService(t)
+
+		mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication1.ID})
+
+		points, err := models.ParsePointsString(`cpu,host=A,_instance_id=site-a value=1.1 1000000000`)
+		require.NoError(t, err)
+
+		// Instances without an ID can't be put on the path, so they can't forward points.
+		mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+
+		rctx := influxdb.ContextWithReplicationPath(ctx, []string{"site-a"})
+		require.NoError(t, svc.WritePoints(rctx, orgID, id1, points))
+	})
+}
+
 func TestWritePointsBatches(t *testing.T) {
 	t.Parallel()
 
@@ -871,6 +995,58 @@ disk,host=C,_instance_id=hello-edge value=1.3 1000000000`)
 
 }
 
+func TestWritePointsFiltered(t *testing.T) {
+	t.Parallel()
+
+	svc, mocks := newTestService(t)
+	svc.setFilter(replication2.ID, &influxdb.ReplicationFilter{
+		Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_*"}},
+	})
+	replications := []platform.ID{replication1.ID, replication2.ID}
+
+	mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return(replications)
+
+	const lp = `
+prod_cpu,host=A value=1.1 1000000000
+dev_cpu,host=A value=1.2 2000000000
+prod_mem,host=B value=1.3 3000000000`
+	points, err := models.ParsePointsString(lp)
+	require.NoError(t, err)
+	// Filtering caches the points' parsed tags, so compare against separately parsed points.
+	expectedPoints, err := models.ParsePointsString(lp)
+	require.NoError(t, err)
+	filteredPoints := []models.Point{expectedPoints[0], expectedPoints[2]}
+
+	// All points should be written to local TSM.
+	mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, points).Return(nil)
+
+	// The unfiltered replication gets every point, the filtered one only the prod_* measurements.
+	mocks.durableQueueManager.EXPECT().
+		EnqueueData(replication1.ID, gomock.Any(), len(points)).
+		DoAndReturn(func(_ platform.ID, data []byte, numPoints int) error {
+			checkCompressedData(t, data, expectedPoints)
+			return nil
+		})
+	mocks.durableQueueManager.EXPECT().
+		EnqueueData(replication2.ID, gomock.Any(), len(filteredPoints)).
+		DoAndReturn(func(_ platform.ID, data []byte, numPoints int) error {
+			checkCompressedData(t, data, filteredPoints)
+			return nil
+		})
+
+	require.NoError(t, svc.WritePoints(ctx, orgID, id1, points))
+
+	// Nothing is enqueued for a replication whose filter rejects every point.
+	mocks.durableQueueManager.EXPECT().GetReplications(orgID, id1).Return([]platform.ID{replication2.ID})
+	devPoints := points[1:2]
+	mocks.pointWriter.EXPECT().WritePoints(gomock.Any(), orgID, id1, devPoints).Return(nil)
+	require.NoError(t, svc.WritePoints(ctx, orgID, id1, devPoints))
+
+	// Removing the filter replicates everything again.
+	svc.setFilter(replication2.ID, nil)
+	require.Nil(t, svc.filterFor(replication2.ID))
+}
+
 func TestWritePoints_LocalFailure(t *testing.T) {
 	t.Parallel()
 
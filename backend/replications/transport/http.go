@@ -68,6 +68,31 @@ type ReplicationService interface {
 	// ValidateReplication checks that the replication with the given ID is still usable with its
 	// persisted settings.
 	ValidateReplication(context.Context, platform.ID) error
+
+	// BackfillReplication starts enqueueing the data already stored in the replication's local
+	// bucket during a time range.
+	BackfillReplication(context.Context, platform.ID, influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error)
+
+	// CancelReplicationBackfill stops the running backfill of the replication with the given ID.
+	CancelReplicationBackfill(context.Context, platform.ID) error
+
+	// PauseReplication stops sending the queued data of the replication with the given ID.
+	PauseReplication(context.Context, platform.ID) (*influxdb.Replication, error)
+
+	// ResumeReplication restarts sending the queued data of the replication with the given ID.
+	ResumeReplication(context.Context, platform.ID) (*influxdb.Replication, error)
+
+	// PeekReplicationQueue returns the batch at the head of the queue, or of the dead-letter
+	// queue, of the replication with the given ID.
+	PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error)
+
+	// SkipReplicationQueueHead removes the batch at the head of the queue of the paused
+	// replication with the given ID, optionally moving it to the dead-letter queue.
+	SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error)
+
+	// PurgeReplicationQueue removes all the data from the queue, or the dead-letter queue, of the
+	// replication with the given ID.
+	PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) error
 }
 
 type ReplicationHandler struct {
@@ -115,6 +140,14 @@ func newReplicationHandler(log *zap.Logger, svc ReplicationService) *Replication
 			r.Patch("/", h.handlePatchReplication)
 			r.Delete("/", h.handleDeleteReplication)
 			r.Post("/validate", h.handleValidateReplication)
+			r.Post("/backfill", h.handlePostReplicationBackfill)
+			r.Delete("/backfill", h.handleDeleteReplicationBackfill)
+			r.Post("/pause", h.handlePostReplicationPause)
+			r.Post("/resume", h.handlePostReplicationResume)
+			r.Delete("/queue", h.handleDeleteReplicationQueue)
+			r.Get("/queue/head", h.handleGetReplicationQueueHead)
+			r.Post("/queue/head/skip", h.handlePostReplicationQueueHeadSkip(false))
+			r.Post("/queue/head/deadletter", h.handlePostReplicationQueueHeadSkip(true))
 		})
 	})
 
@@ -275,3 +308,116 @@ func (h *ReplicationHandler) handleValidateReplication(w http.ResponseWriter, r
 	}
 	h.api.Respond(w, r, http.StatusNoContent, nil)
 }
+
+func (h *ReplicationHandler) handlePostReplicationBackfill(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	var req influxdb.ReplicationBackfillRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	replication, err := h.replicationsService.BackfillReplication(r.Context(), *id, req)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusAccepted, replication)
+}
+
+func (h *ReplicationHandler) handleDeleteReplicationBackfill(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	if err := h.replicationsService.CancelReplicationBackfill(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func (h *ReplicationHandler) handlePostReplicationPause(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	replication, err := h.replicationsService.PauseReplication(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, replication)
+}
+
+func (h *ReplicationHandler) handlePostReplicationResume(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	replication, err := h.replicationsService.ResumeReplication(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, replication)
+}
+
+func (h *ReplicationHandler) handleGetReplicationQueueHead(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	deadLetter := r.URL.Query().Get("deadLetter") == "true"
+	batch, err := h.replicationsService.PeekReplicationQueue(r.Context(), *id, deadLetter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, batch)
+}
+
+func (h *ReplicationHandler) handlePostReplicationQueueHeadSkip(deadLetter bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := platform.IDFromString(chi.URLParam(r, "id"))
+		if err != nil {
+			h.api.Err(w, r, errBadId)
+			return
+		}
+
+		batch, err := h.replicationsService.SkipReplicationQueueHead(r.Context(), *id, deadLetter)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		h.api.Respond(w, r, http.StatusOK, batch)
+	}
+}
+
+func (h *ReplicationHandler) handleDeleteReplicationQueue(w http.ResponseWriter, r *http.Request) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, errBadId)
+		return
+	}
+
+	deadLetter := r.URL.Query().Get("deadLetter") == "true"
+	if err := h.replicationsService.PurgeReplicationQueue(r.Context(), *id, deadLetter); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
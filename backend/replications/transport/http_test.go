@@ -6,10 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/replications/mock"
 	"github.com/stretchr/testify/assert"
 	tmock "github.com/stretchr/testify/mock"
@@ -309,6 +311,153 @@ func TestReplicationHandler(t *testing.T) {
 
 		doTestRequest(t, req, http.StatusBadRequest, true)
 	})
+
+	t.Run("create replication with filter", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		body := influxdb.CreateReplicationRequest{
+			OrgID:             testReplication.OrgID,
+			Name:              testReplication.Name,
+			RemoteID:          testReplication.RemoteID,
+			LocalBucketID:     testReplication.LocalBucketID,
+			RemoteBucketID:    *testReplication.RemoteBucketID,
+			MaxQueueSizeBytes: influxdb.DefaultReplicationMaxQueueSizeBytes,
+			Filter: &influxdb.ReplicationFilter{
+				Include: &influxdb.ReplicationPredicate{Measurements: []string{"prod_*"}},
+			},
+		}
+		filtered := testReplication
+		filtered.Filter = body.Filter
+
+		req := newTestRequest(t, "POST", ts.URL, &body)
+
+		svc.EXPECT().CreateReplication(gomock.Any(), body).Return(&filtered, nil)
+
+		res := doTestRequest(t, req, http.StatusCreated, true)
+
+		var got influxdb.Replication
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, filtered, got)
+	})
+
+	t.Run("invalid filter is rejected", func(t *testing.T) {
+		ts, _ := newTestServer(t)
+		defer ts.Close()
+
+		filter := &influxdb.ReplicationFilter{
+			Exclude: &influxdb.ReplicationPredicate{Tags: []influxdb.ReplicationTagMatcher{{Value: "prod"}}},
+		}
+		create := influxdb.CreateReplicationRequest{
+			OrgID:             testReplication.OrgID,
+			Name:              testReplication.Name,
+			RemoteID:          testReplication.RemoteID,
+			LocalBucketID:     testReplication.LocalBucketID,
+			RemoteBucketID:    *testReplication.RemoteBucketID,
+			MaxQueueSizeBytes: influxdb.DefaultReplicationMaxQueueSizeBytes,
+			Filter:            filter,
+		}
+		update := influxdb.UpdateReplicationRequest{Filter: filter}
+
+		doTestRequest(t, newTestRequest(t, "POST", ts.URL, &create), http.StatusBadRequest, true)
+		doTestRequest(t, newTestRequest(t, "PATCH", ts.URL+"/"+id.String(), &update), http.StatusBadRequest, true)
+	})
+
+	t.Run("backfill replication", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		body := influxdb.ReplicationBackfillRequest{
+			Start:              time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			MaxPointsPerSecond: 100,
+		}
+		backfilling := testReplication
+		backfilling.Backfill = &influxdb.ReplicationBackfill{
+			Status:             influxdb.ReplicationBackfillRunning,
+			Start:              body.Start,
+			Progress:           body.Start,
+			MaxPointsPerSecond: body.MaxPointsPerSecond,
+		}
+
+		svc.EXPECT().BackfillReplication(gomock.Any(), *id, body).Return(&backfilling, nil)
+		res := doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/backfill", &body), http.StatusAccepted, true)
+
+		var got influxdb.Replication
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, backfilling, got)
+
+		svc.EXPECT().CancelReplicationBackfill(gomock.Any(), *id).Return(nil)
+		doTestRequest(t, newTestRequest(t, "DELETE", ts.URL+"/"+id.String()+"/backfill", nil), http.StatusNoContent, false)
+	})
+
+	t.Run("invalid backfill is rejected", func(t *testing.T) {
+		ts, _ := newTestServer(t)
+		defer ts.Close()
+
+		body := influxdb.ReplicationBackfillRequest{
+			Start: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			Stop:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/backfill", &body), http.StatusBadRequest, true)
+	})
+}
+
+func TestReplicationQueueHandlers(t *testing.T) {
+	t.Run("pause and resume", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		paused := testReplication
+		paused.Paused = true
+		svc.EXPECT().PauseReplication(gomock.Any(), *id).Return(&paused, nil)
+		res := doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/pause", nil), http.StatusOK, true)
+
+		var got influxdb.Replication
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.True(t, got.Paused)
+
+		svc.EXPECT().ResumeReplication(gomock.Any(), *id).Return(&testReplication, nil)
+		doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/resume", nil), http.StatusOK, true)
+	})
+
+	t.Run("peek", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		batch := influxdb.ReplicationQueueBatch{Data: "cpu usage=1 1\n", Points: 1, SizeBytes: 40}
+		svc.EXPECT().PeekReplicationQueue(gomock.Any(), *id, false).Return(&batch, nil)
+		res := doTestRequest(t, newTestRequest(t, "GET", ts.URL+"/"+id.String()+"/queue/head", nil), http.StatusOK, true)
+
+		var got influxdb.ReplicationQueueBatch
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, batch, got)
+
+		svc.EXPECT().PeekReplicationQueue(gomock.Any(), *id, true).Return(nil, &errors.Error{Code: errors.ENotFound})
+		doTestRequest(t, newTestRequest(t, "GET", ts.URL+"/"+id.String()+"/queue/head?deadLetter=true", nil), http.StatusNotFound, true)
+	})
+
+	t.Run("skip and dead-letter", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		batch := influxdb.ReplicationQueueBatch{Data: "cpu usage=1 1\n", Points: 1, SizeBytes: 40}
+		svc.EXPECT().SkipReplicationQueueHead(gomock.Any(), *id, false).Return(&batch, nil)
+		doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/queue/head/skip", nil), http.StatusOK, true)
+
+		svc.EXPECT().SkipReplicationQueueHead(gomock.Any(), *id, true).Return(&batch, nil)
+		doTestRequest(t, newTestRequest(t, "POST", ts.URL+"/"+id.String()+"/queue/head/deadletter", nil), http.StatusOK, true)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		ts, svc := newTestServer(t)
+		defer ts.Close()
+
+		svc.EXPECT().PurgeReplicationQueue(gomock.Any(), *id, false).Return(nil)
+		doTestRequest(t, newTestRequest(t, "DELETE", ts.URL+"/"+id.String()+"/queue", nil), http.StatusNoContent, false)
+
+		svc.EXPECT().PurgeReplicationQueue(gomock.Any(), *id, true).Return(nil)
+		doTestRequest(t, newTestRequest(t, "DELETE", ts.URL+"/"+id.String()+"/queue?deadLetter=true", nil), http.StatusNoContent, false)
+	})
 }
 
 func newTestServer(t *testing.T) (*httptest.Server, *mock.MockReplicationService) {
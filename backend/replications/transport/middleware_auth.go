@@ -129,3 +129,77 @@ func (a authCheckingService) ValidateReplication(ctx context.Context, id platfor
 	}
 	return a.underlying.ValidateReplication(ctx, id)
 }
+
+func (a authCheckingService) BackfillReplication(ctx context.Context, id platform.ID, request influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error) {
+	if err := a.authWriteReplication(ctx, id); err != nil {
+		return nil, err
+	}
+	return a.underlying.BackfillReplication(ctx, id, request)
+}
+
+func (a authCheckingService) CancelReplicationBackfill(ctx context.Context, id platform.ID) error {
+	if err := a.authWriteReplication(ctx, id); err != nil {
+		return err
+	}
+	return a.underlying.CancelReplicationBackfill(ctx, id)
+}
+
+func (a authCheckingService) PauseReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	if err := a.authWriteReplication(ctx, id); err != nil {
+		return nil, err
+	}
+	return a.underlying.PauseReplication(ctx, id)
+}
+
+func (a authCheckingService) ResumeReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	if err := a.authWriteReplication(ctx, id); err != nil {
+		return nil, err
+	}
+	return a.underlying.ResumeReplication(ctx, id)
+}
+
+func (a authCheckingService) PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	// The queue holds data of the replication's local bucket.
+	r, err := a.underlying.GetReplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.ReplicationsResourceType, id, r.OrgID); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, r.LocalBucketID, r.OrgID); err != nil {
+		return nil, err
+	}
+	return a.underlying.PeekReplicationQueue(ctx, id, deadLetter)
+}
+
+func (a authCheckingService) SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	r, err := a.underlying.GetReplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.ReplicationsResourceType, id, r.OrgID); err != nil {
+		return nil, err
+	}
+	// The removed batch is returned.
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, r.LocalBucketID, r.OrgID); err != nil {
+		return nil, err
+	}
+	return a.underlying.SkipReplicationQueueHead(ctx, id, deadLetter)
+}
+
+func (a authCheckingService) PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) error {
+	if err := a.authWriteReplication(ctx, id); err != nil {
+		return err
+	}
+	return a.underlying.PurgeReplicationQueue(ctx, id, deadLetter)
+}
+
+func (a authCheckingService) authWriteReplication(ctx context.Context, id platform.ID) error {
+	r, err := a.underlying.GetReplication(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, _, err = authorizer.AuthorizeWrite(ctx, influxdb.ReplicationsResourceType, id, r.OrgID)
+	return err
+}
@@ -50,6 +50,34 @@ func (t telemetryService) ValidateReplication(ctx context.Context, id platform.I
 	return t.underlying.ValidateReplication(ctx, id)
 }
 
+func (t telemetryService) BackfillReplication(ctx context.Context, id platform.ID, request influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error) {
+	return t.underlying.BackfillReplication(ctx, id, request)
+}
+
+func (t telemetryService) CancelReplicationBackfill(ctx context.Context, id platform.ID) error {
+	return t.underlying.CancelReplicationBackfill(ctx, id)
+}
+
+func (t telemetryService) PauseReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	return t.underlying.PauseReplication(ctx, id)
+}
+
+func (t telemetryService) ResumeReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	return t.underlying.ResumeReplication(ctx, id)
+}
+
+func (t telemetryService) PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	return t.underlying.PeekReplicationQueue(ctx, id, deadLetter)
+}
+
+func (t telemetryService) SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	return t.underlying.SkipReplicationQueueHead(ctx, id, deadLetter)
+}
+
+func (t telemetryService) PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) error {
+	return t.underlying.PurgeReplicationQueue(ctx, id, deadLetter)
+}
+
 func (t telemetryService) CreateReplication(ctx context.Context, request influxdb.CreateReplicationRequest) (*influxdb.Replication, error) {
 	conn, err := t.underlying.CreateReplication(ctx, request)
 	if err != nil {
@@ -118,3 +118,87 @@ func (l loggingService) ValidateReplication(ctx context.Context, id platform.ID)
 	}(time.Now())
 	return l.underlying.ValidateReplication(ctx, id)
 }
+
+func (l loggingService) BackfillReplication(ctx context.Context, id platform.ID, request influxdb.ReplicationBackfillRequest) (r *influxdb.Replication, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to start replication backfill", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication backfill", dur)
+	}(time.Now())
+	return l.underlying.BackfillReplication(ctx, id, request)
+}
+
+func (l loggingService) CancelReplicationBackfill(ctx context.Context, id platform.ID) (err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to cancel replication backfill", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication backfill cancel", dur)
+	}(time.Now())
+	return l.underlying.CancelReplicationBackfill(ctx, id)
+}
+
+func (l loggingService) PauseReplication(ctx context.Context, id platform.ID) (r *influxdb.Replication, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to pause replication", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication pause", dur)
+	}(time.Now())
+	return l.underlying.PauseReplication(ctx, id)
+}
+
+func (l loggingService) ResumeReplication(ctx context.Context, id platform.ID) (r *influxdb.Replication, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to resume replication", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication resume", dur)
+	}(time.Now())
+	return l.underlying.ResumeReplication(ctx, id)
+}
+
+func (l loggingService) PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (b *influxdb.ReplicationQueueBatch, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to peek replication queue", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication queue peek", dur)
+	}(time.Now())
+	return l.underlying.PeekReplicationQueue(ctx, id, deadLetter)
+}
+
+func (l loggingService) SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (b *influxdb.ReplicationQueueBatch, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to skip replication queue head", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication queue head skip", dur)
+	}(time.Now())
+	return l.underlying.SkipReplicationQueueHead(ctx, id, deadLetter)
+}
+
+func (l loggingService) PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to purge replication queue", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("replication queue purge", dur)
+	}(time.Now())
+	return l.underlying.PurgeReplicationQueue(ctx, id, deadLetter)
+}
@@ -67,3 +67,43 @@ func (m metricsService) ValidateReplication(ctx context.Context, id platform.ID)
 	rec := m.rec.Record("validate_replication")
 	return rec(m.underlying.ValidateReplication(ctx, id))
 }
+
+func (m metricsService) BackfillReplication(ctx context.Context, id platform.ID, request influxdb.ReplicationBackfillRequest) (*influxdb.Replication, error) {
+	rec := m.rec.Record("backfill_replication")
+	r, err := m.underlying.BackfillReplication(ctx, id, request)
+	return r, rec(err)
+}
+
+func (m metricsService) CancelReplicationBackfill(ctx context.Context, id platform.ID) error {
+	rec := m.rec.Record("cancel_replication_backfill")
+	return rec(m.underlying.CancelReplicationBackfill(ctx, id))
+}
+
+func (m metricsService) PauseReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	rec := m.rec.Record("pause_replication")
+	r, err := m.underlying.PauseReplication(ctx, id)
+	return r, rec(err)
+}
+
+func (m metricsService) ResumeReplication(ctx context.Context, id platform.ID) (*influxdb.Replication, error) {
+	rec := m.rec.Record("resume_replication")
+	r, err := m.underlying.ResumeReplication(ctx, id)
+	return r, rec(err)
+}
+
+func (m metricsService) PeekReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	rec := m.rec.Record("peek_replication_queue")
+	b, err := m.underlying.PeekReplicationQueue(ctx, id, deadLetter)
+	return b, rec(err)
+}
+
+func (m metricsService) SkipReplicationQueueHead(ctx context.Context, id platform.ID, deadLetter bool) (*influxdb.ReplicationQueueBatch, error) {
+	rec := m.rec.Record("skip_replication_queue_head")
+	b, err := m.underlying.SkipReplicationQueueHead(ctx, id, deadLetter)
+	return b, rec(err)
+}
+
+func (m metricsService) PurgeReplicationQueue(ctx context.Context, id platform.ID, deadLetter bool) error {
+	rec := m.rec.Record("purge_replication_queue")
+	return rec(m.underlying.PurgeReplicationQueue(ctx, id, deadLetter))
+}
@@ -27,6 +27,9 @@ type ScraperTarget struct {
 	OrgID         platform.ID `json:"orgID,omitempty"`
 	BucketID      platform.ID `json:"bucketID,omitempty"`
 	AllowInsecure bool        `json:"allowInsecure,omitempty"`
+	// MetricFormat controls how histograms and summaries are stored. The zero
+	// value is ScraperMetricFormatFlat.
+	MetricFormat ScraperMetricFormat `json:"metricFormat,omitempty"`
 }
 
 // ScraperTargetStoreService defines the crud service for ScraperTarget.
@@ -64,3 +67,38 @@ func ValidScraperType(s string) bool {
 		return false
 	}
 }
+
+// ScraperMetricFormat defines how histograms and summaries are laid out when stored.
+type ScraperMetricFormat string
+
+// Scraper metric formats
+const (
+	// ScraperMetricFormatFlat stores each histogram or summary sample as a single
+	// series with one field per bucket upper bound or quantile.
+	ScraperMetricFormatFlat ScraperMetricFormat = "flat"
+	// ScraperMetricFormatBounds lays out samples like ScraperMetricFormatFlat,
+	// with a field per bucket or quantile, since there is no histogram field
+	// type to store a whole sample in. It only guarantees the shape Flux's
+	// histogramQuantile expects: histograms always include the +Inf bucket,
+	// the keys of bucket and quantile fields are formatted such that float()
+	// parses them back into bounds, and count and sum are the only other
+	// fields. The quantiles of histograms stored this way are computed with
+	// the following, grouping by the tags of the series as well:
+	//
+	//	|> filter(fn: (r) => r._field != "count" and r._field != "sum")
+	//	|> map(fn: (r) => ({r with le: float(v: r._field)}))
+	//	|> group(columns: ["_measurement", "_time"])
+	//	|> histogramQuantile(quantile: 0.99)
+	ScraperMetricFormatBounds ScraperMetricFormat = "bounds"
+)
+
+// Valid returns true if the metric format is known. The empty format is valid
+// and treated as ScraperMetricFormatFlat.
+func (f ScraperMetricFormat) Valid() bool {
+	switch f {
+	case "", ScraperMetricFormatFlat, ScraperMetricFormatBounds:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,2 @@
+-- Adds the optional JSON-encoded `filter` restricting which points are replicated.
+ALTER TABLE replications ADD COLUMN filter TEXT;
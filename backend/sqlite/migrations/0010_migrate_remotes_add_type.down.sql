@@ -0,0 +1,4 @@
+-- Removes the remote type, format and topic.
+ALTER TABLE remotes DROP COLUMN remote_type;
+ALTER TABLE remotes DROP COLUMN remote_format;
+ALTER TABLE remotes DROP COLUMN remote_topic;
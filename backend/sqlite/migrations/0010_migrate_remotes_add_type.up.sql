@@ -0,0 +1,5 @@
+-- Adds the type of system a remote sends replicated data to, and the format and topic used by
+-- non-InfluxDB remotes.
+ALTER TABLE remotes ADD COLUMN remote_type TEXT NOT NULL DEFAULT 'influxdb';
+ALTER TABLE remotes ADD COLUMN remote_format TEXT NOT NULL DEFAULT '';
+ALTER TABLE remotes ADD COLUMN remote_topic TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Removes the TLS settings of remotes.
+ALTER TABLE remotes DROP COLUMN remote_ca_cert;
+ALTER TABLE remotes DROP COLUMN remote_server_name;
+ALTER TABLE remotes DROP COLUMN client_cert_secret;
+ALTER TABLE remotes DROP COLUMN client_key_secret;
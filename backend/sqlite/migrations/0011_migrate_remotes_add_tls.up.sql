@@ -0,0 +1,6 @@
+-- Adds the CA bundle and SNI server name used to verify a remote's certificate, and the keys of
+-- the secrets holding the client certificate and key presented to the remote.
+ALTER TABLE remotes ADD COLUMN remote_ca_cert TEXT NOT NULL DEFAULT '';
+ALTER TABLE remotes ADD COLUMN remote_server_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE remotes ADD COLUMN client_cert_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE remotes ADD COLUMN client_key_secret TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Removes the `paused` column.
+ALTER TABLE replications DROP COLUMN paused;
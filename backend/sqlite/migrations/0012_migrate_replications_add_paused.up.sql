@@ -0,0 +1,2 @@
+-- Adds the `paused` flag stopping a replication from sending its queued data to its remote.
+ALTER TABLE replications ADD COLUMN paused BOOLEAN NOT NULL DEFAULT 0;
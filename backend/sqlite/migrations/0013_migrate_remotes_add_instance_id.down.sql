@@ -0,0 +1,2 @@
+-- Removes the instance IDs of remotes.
+ALTER TABLE remotes DROP COLUMN remote_instance_id;
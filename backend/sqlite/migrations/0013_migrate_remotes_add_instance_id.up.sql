@@ -0,0 +1,3 @@
+-- Adds the instance ID of a remote, used to keep replicated points from being sent back to a
+-- remote they were already replicated through.
+ALTER TABLE remotes ADD COLUMN remote_instance_id TEXT NOT NULL DEFAULT '';
@@ -31,6 +31,9 @@ const (
 	requestedAtField  = "requestedAt"
 	logField          = "logs"
 	fluxField         = "flux"
+	retryOfField      = "retryOf"
+	attemptField      = "attempt"
+	errorClassField   = "errorClass"
 
 	taskIDTag = "taskID"
 	statusTag = "status"
@@ -92,12 +95,31 @@ func (as *AnalyticalStorage) FindLogs(ctx context.Context, filter taskmodel.LogF
 		if err != nil {
 			return nil, 0, err
 		}
+		if filter.IsSearch() {
+			logs, err := filter.Search([]*taskmodel.Run{run})
+			if err != nil {
+				return nil, 0, taskmodel.ErrInvalidLogFilter(err)
+			}
+			return logs, len(logs), nil
+		}
 		for i := 0; i < len(run.Log); i++ {
 			logs = append(logs, &run.Log[i])
 		}
 		return logs, len(logs), nil
 	}
 
+	if filter.IsSearch() {
+		runs, err := as.findSearchedRuns(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		logs, err := filter.Search(runs)
+		if err != nil {
+			return nil, 0, taskmodel.ErrInvalidLogFilter(err)
+		}
+		return logs, len(logs), nil
+	}
+
 	// add historical logs to the transactional logs.
 	runs, n, err := as.FindRuns(ctx, taskmodel.RunFilter{Task: filter.Task})
 	if err != nil {
@@ -113,6 +135,67 @@ func (as *AnalyticalStorage) FindLogs(ctx context.Context, filter taskmodel.LogF
 	return logs, n, err
 }
 
+// findSearchedRuns returns the runs of a task scheduled between the AfterTime and BeforeTime
+// of a log filter, newest first. Runs are found a page at a time, each page bounded by the
+// oldest run of the one before; runs log at or after the time they are scheduled for, so
+// logs of runs scheduled before AfterTime are not searched.
+func (as *AnalyticalStorage) findSearchedRuns(ctx context.Context, filter taskmodel.LogFilter) ([]*taskmodel.Run, error) {
+	// validate the time bounds of the filter before they are used to find runs
+	if _, err := filter.Search(nil); err != nil {
+		return nil, taskmodel.ErrInvalidLogFilter(err)
+	}
+	var after, before time.Time
+	if filter.AfterTime != "" {
+		after, _ = time.Parse(time.RFC3339, filter.AfterTime)
+	}
+	if filter.BeforeTime != "" {
+		before, _ = time.Parse(time.RFC3339, filter.BeforeTime)
+	}
+
+	var (
+		runs []*taskmodel.Run
+		seen = make(map[platform.ID]bool)
+	)
+	for {
+		if !before.IsZero() && !before.After(after) {
+			return runs, nil
+		}
+		rf := taskmodel.RunFilter{Task: filter.Task, Limit: taskmodel.TaskMaxPageSize}
+		if !after.IsZero() {
+			rf.AfterTime = after.Format(time.RFC3339)
+		}
+		if !before.IsZero() {
+			rf.BeforeTime = before.Format(time.RFC3339)
+		}
+		page, _, err := as.FindRuns(ctx, rf)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			oldest time.Time
+			found  int
+		)
+		for _, r := range page {
+			if oldest.IsZero() || r.ScheduledFor.Before(oldest) {
+				oldest = r.ScheduledFor
+			}
+			if seen[r.ID] {
+				continue
+			}
+			seen[r.ID] = true
+			runs = append(runs, r)
+			found++
+		}
+		if len(page) < rf.Limit || found == 0 {
+			return runs, nil
+		}
+		// scheduled times are stored to the second and the bound is exclusive, so the
+		// next page starts with the second of the oldest run, whose runs are seen already.
+		before = oldest.Truncate(time.Second).Add(time.Second)
+	}
+}
+
 // FindRuns returns a list of runs that match a filter and the total count of returned runs.
 // First attempt to use the TaskService, then append additional analytical's runs to the list
 func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter taskmodel.RunFilter) ([]*taskmodel.Run, int, error) {
@@ -425,6 +508,19 @@ func (re *runReader) readRuns(cr flux.ColReader) error {
 				r.TraceID = cr.Strings(j).Value(i)
 			case traceSampledTag:
 				r.IsSampled = cr.Bools(j).Value(i)
+			case retryOfField:
+				if cr.Strings(j).Value(i) != "" {
+					id, err := platform.IDFromString(cr.Strings(j).Value(i))
+					if err != nil {
+						re.log.Info("Failed to parse retryOf", zap.Error(err))
+						continue
+					}
+					r.RetryOf = *id
+				}
+			case attemptField:
+				r.Attempt = cr.Ints(j).Value(i)
+			case errorClassField:
+				r.ErrorClass = cr.Strings(j).Value(i)
 			case finishedAtField:
 				finished, err := time.Parse(time.RFC3339Nano, cr.Strings(j).Value(i))
 				if err != nil {
@@ -73,18 +73,18 @@ func TestAnalyticalStore(t *testing.T) {
 			})
 
 			return &servicetest.System{
-					TaskControlService:         svcStack,
-					TaskService:                svcStack,
-					OrganizationService:        ts.OrganizationService,
-					UserService:                ts.UserService,
-					UserResourceMappingService: ts.UserResourceMappingService,
-					AuthorizationService:       authSvc,
-					Ctx:                        authCtx,
-					CallFinishRun:              true,
-				}, func() {
-					cancelFunc()
-					ab.Close(t)
-				}
+				TaskControlService:         svcStack,
+				TaskService:                svcStack,
+				OrganizationService:        ts.OrganizationService,
+				UserService:                ts.UserService,
+				UserResourceMappingService: ts.UserResourceMappingService,
+				AuthorizationService:       authSvc,
+				Ctx:                        authCtx,
+				CallFinishRun:              true,
+			}, func() {
+				cancelFunc()
+				ab.Close(t)
+			}
 		},
 	)
 }
@@ -149,6 +149,93 @@ func TestDeduplicateRuns(t *testing.T) {
 	}
 }
 
+func TestFindLogs_SearchPastFirstPage(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := inmem.NewKVStore()
+	if err := all.Up(context.Background(), logger, store); err != nil {
+		t.Fatal(err)
+	}
+
+	tenantStore := tenant.NewStore(store)
+	ts := tenant.NewService(tenantStore)
+
+	metaClient := meta.NewClient(meta.NewConfig(), store)
+	require.NoError(t, metaClient.Open())
+
+	_, err := metaClient.CreateDatabase(platform.ID(10).String())
+	require.NoError(t, err)
+
+	ab := newAnalyticalBackend(t, ts.OrganizationService, ts.BucketService, metaClient)
+	defer ab.Close(t)
+
+	// one more run than fits in a page, the oldest of which logs the match
+	var (
+		now   = time.Now().UTC().Truncate(time.Second)
+		count = taskmodel.TaskMaxPageSize + 1
+	)
+	runAt := func(i int) time.Time {
+		return now.Add(-time.Duration(i) * time.Minute)
+	}
+	mockTS := &mock.TaskService{
+		FindTaskByIDFn: func(context.Context, platform.ID) (*taskmodel.Task, error) {
+			return &taskmodel.Task{ID: 1, OrganizationID: 20}, nil
+		},
+		FindRunsFn: func(context.Context, taskmodel.RunFilter) ([]*taskmodel.Run, int, error) {
+			return nil, 0, nil
+		},
+	}
+	mockTCS := &mock.TaskControlService{
+		FinishRunFn: func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error) {
+			i := int(runID) - 1
+			msg := "ok"
+			if i == count-1 {
+				msg = "needle"
+			}
+			return &taskmodel.Run{
+				ID:           runID,
+				TaskID:       1,
+				Status:       "success",
+				ScheduledFor: runAt(i),
+				StartedAt:    runAt(i).Add(time.Second),
+				FinishedAt:   runAt(i).Add(2 * time.Second),
+				Log:          []taskmodel.Log{{RunID: runID, Time: runAt(i).Add(time.Second).Format(time.RFC3339Nano), Message: msg}},
+			}, nil
+		},
+	}
+	mockBS := mock.NewBucketService()
+
+	svcStack := backend.NewAnalyticalStorage(zaptest.NewLogger(t), mockTS, mockBS, mockTCS, ab.PointsWriter(), ab.QueryService())
+
+	for i := 0; i < count; i++ {
+		_, err := svcStack.FinishRun(context.Background(), 1, platform.ID(i+1))
+		require.NoError(t, err)
+	}
+
+	logs, n, err := svcStack.FindLogs(context.Background(), taskmodel.LogFilter{Task: 1, Match: "needle"})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, platform.ID(count), logs[0].RunID)
+
+	// the time bounds of the filter limit the runs searched
+	logs, n, err = svcStack.FindLogs(context.Background(), taskmodel.LogFilter{
+		Task:       1,
+		Match:      "needle",
+		AfterTime:  runAt(count).Format(time.RFC3339),
+		BeforeTime: runAt(count - 2).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, platform.ID(count), logs[0].RunID)
+
+	_, n, err = svcStack.FindLogs(context.Background(), taskmodel.LogFilter{
+		Task:      1,
+		Match:     "needle",
+		AfterTime: runAt(count - 2).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
 type analyticalBackend struct {
 	queryController *control.Controller
 	rootDir         string
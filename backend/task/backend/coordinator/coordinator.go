@@ -32,6 +32,9 @@ type Coordinator struct {
 	sch scheduler.Scheduler
 	ex  Executor
 
+	// dep schedules the tasks that depend on other tasks, when set.
+	dep scheduler.Scheduler
+
 	limit int
 }
 
@@ -63,12 +66,29 @@ func (t SchedulableTask) LastScheduled() time.Time {
 	return t.lsc
 }
 
+// Upstreams returns the IDs of the tasks the Task depends on
+func (t SchedulableTask) Upstreams() []scheduler.ID {
+	ids := make([]scheduler.ID, len(t.Task.DependsOn))
+	for i, id := range t.Task.DependsOn {
+		ids[i] = scheduler.ID(id)
+	}
+	return ids
+}
+
 func WithLimitOpt(i int) CoordinatorOption {
 	return func(c *Coordinator) {
 		c.limit = i
 	}
 }
 
+// WithDependencyScheduler sets the scheduler of the tasks that depend on
+// other tasks. Without it, those tasks run on their own schedule.
+func WithDependencyScheduler(s scheduler.Scheduler) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.dep = s
+	}
+}
+
 // NewSchedulableTask transforms an influxdb task to a schedulable task type
 func NewSchedulableTask(task *taskmodel.Task) (SchedulableTask, error) {
 
@@ -107,6 +127,18 @@ func NewCoordinator(log *zap.Logger, scheduler scheduler.Scheduler, executor Exe
 	return c
 }
 
+// schedulers returns the scheduler that schedules task, and the other
+// scheduler, which must not.
+func (c *Coordinator) schedulers(task *taskmodel.Task) (scheduler.Scheduler, scheduler.Scheduler) {
+	if c.dep == nil {
+		return c.sch, nil
+	}
+	if len(task.DependsOn) > 0 {
+		return c.dep, c.sch
+	}
+	return c.sch, c.dep
+}
+
 // TaskCreated asks the Scheduler to schedule the newly created task
 func (c *Coordinator) TaskCreated(ctx context.Context, task *taskmodel.Task) error {
 	t, err := NewSchedulableTask(task)
@@ -114,9 +146,10 @@ func (c *Coordinator) TaskCreated(ctx context.Context, task *taskmodel.Task) err
 	if err != nil {
 		return err
 	}
+	sch, _ := c.schedulers(task)
 	// func new schedulable task
 	// catch errors from offset and last scheduled
-	if err = c.sch.Schedule(t); err != nil {
+	if err = sch.Schedule(t); err != nil {
 		return err
 	}
 
@@ -136,13 +169,22 @@ func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *taskmodel.Task)
 		return nil
 	}
 
+	sch, other := c.schedulers(to)
+
 	// if disabling the task, release it before schedule update
 	if to.Status != from.Status && to.Status == string(taskmodel.TaskInactive) {
-		if err := c.sch.Release(sid); err != nil && err != taskmodel.ErrTaskNotClaimed {
+		if err := sch.Release(sid); err != nil && err != taskmodel.ErrTaskNotClaimed {
 			return err
 		}
 	} else {
-		if err := c.sch.Schedule(t); err != nil {
+		if err := sch.Schedule(t); err != nil {
+			return err
+		}
+	}
+
+	// release the task from the scheduler it moved away from when its dependencies changed
+	if other != nil {
+		if err := other.Release(sid); err != nil && err != taskmodel.ErrTaskNotClaimed {
 			return err
 		}
 	}
@@ -156,6 +198,11 @@ func (c *Coordinator) TaskDeleted(ctx context.Context, id platform.ID) error {
 	if err := c.sch.Release(tid); err != nil && err != taskmodel.ErrTaskNotClaimed {
 		return err
 	}
+	if c.dep != nil {
+		if err := c.dep.Release(tid); err != nil && err != taskmodel.ErrTaskNotClaimed {
+			return err
+		}
+	}
 
 	return nil
 }
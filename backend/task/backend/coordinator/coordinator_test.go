@@ -272,3 +272,67 @@ func Test_Coordinator_Scheduler_Methods(t *testing.T) {
 		})
 	}
 }
+
+func Test_Coordinator_DependencyScheduler(t *testing.T) {
+	var (
+		now = time.Now().UTC()
+
+		upstream   = &taskmodel.Task{ID: 1, Status: "active", CreatedAt: now, Every: "1h"}
+		downstream = &taskmodel.Task{ID: 2, Status: "active", CreatedAt: now, Every: "1h", DependsOn: []platform.ID{1}}
+		standalone = &taskmodel.Task{ID: 2, Status: "active", CreatedAt: now, Every: "1h"}
+
+		sch   = &schedulerC{}
+		dep   = &schedulerC{}
+		coord = NewCoordinator(zaptest.NewLogger(t), sch, &executorE{}, WithDependencyScheduler(dep))
+	)
+
+	schedulableUpstream, err := NewSchedulableTask(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedulableDownstream, err := NewSchedulableTask(downstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedulableStandalone, err := NewSchedulableTask(standalone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := schedulableDownstream.Upstreams(), []scheduler.ID{1}; !cmp.Equal(got, want) {
+		t.Errorf("expected upstreams %v, got %v", want, got)
+	}
+
+	if err := coord.TaskCreated(context.Background(), upstream); err != nil {
+		t.Fatal(err)
+	}
+	if err := coord.TaskCreated(context.Background(), downstream); err != nil {
+		t.Fatal(err)
+	}
+	// removing the dependencies of a task moves it back to its own schedule
+	if err := coord.TaskUpdated(context.Background(), downstream, standalone); err != nil {
+		t.Fatal(err)
+	}
+	if err := coord.TaskDeleted(context.Background(), standalone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []cmp.Option{
+		cmp.AllowUnexported(SchedulableTask{}),
+		cmpopts.IgnoreUnexported(scheduler.Schedule{}),
+	}
+	if diff := cmp.Diff([]interface{}{
+		scheduleCall{schedulableUpstream},
+		scheduleCall{schedulableStandalone},
+		releaseCallC{2},
+	}, sch.calls, opts...); diff != "" {
+		t.Errorf("unexpected scheduler contents %s", diff)
+	}
+	if diff := cmp.Diff([]interface{}{
+		scheduleCall{schedulableDownstream},
+		releaseCallC{2},
+		releaseCallC{2},
+	}, dep.calls, opts...); diff != "" {
+		t.Errorf("unexpected dependency scheduler contents %s", diff)
+	}
+}
@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+// RunSucceeded returns a scheduler.SucceededFunc that reports whether any
+// run of a task scheduled for a time succeeded, looking up its runs with ts.
+// A failed run that was retried successfully counts as succeeded.
+func RunSucceeded(ts taskmodel.TaskService) scheduler.SucceededFunc {
+	return func(ctx context.Context, id scheduler.ID, scheduledFor time.Time) (bool, error) {
+		runs, _, err := ts.FindRuns(ctx, taskmodel.RunFilter{
+			Task: platform.ID(id),
+			// the time filter excludes its bounds
+			AfterTime:  scheduledFor.Add(-time.Second).UTC().Format(time.RFC3339),
+			BeforeTime: scheduledFor.Add(time.Second).UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, r := range runs {
+			if r.ScheduledFor.Equal(scheduledFor) && r.Status == taskmodel.RunSuccess.String() {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
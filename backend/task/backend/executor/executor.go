@@ -13,12 +13,15 @@ import (
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/influxdb/v2"
 	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"go.uber.org/zap"
 )
@@ -58,11 +61,15 @@ func MultiLimit(limits ...LimitFunc) LimitFunc {
 // LimitFunc is a function the executor will use to
 type LimitFunc func(*taskmodel.Task, *taskmodel.Run) error
 
+// RunFinishedFunc is a function the executor calls after a run has finished with status.
+type RunFinishedFunc func(ctx context.Context, task *taskmodel.Task, run *taskmodel.Run, status taskmodel.RunStatus)
+
 type executorConfig struct {
 	maxWorkers             int
 	systemBuildCompiler    CompilerBuilderFunc
 	nonSystemBuildCompiler CompilerBuilderFunc
 	flagger                feature.Flagger
+	influxQLService        influxql.ProxyQueryService
 }
 
 type executorOption func(*executorConfig)
@@ -126,6 +133,14 @@ func WithFlagger(flagger feature.Flagger) executorOption {
 	}
 }
 
+// WithInfluxQLService is an Executor option that configures the service
+// InfluxQL tasks execute their query with. InfluxQL tasks fail without it.
+func WithInfluxQLService(iqls influxql.ProxyQueryService) executorOption {
+	return func(o *executorConfig) {
+		o.influxQLService = iqls
+	}
+}
+
 // NewExecutor creates a new task executor
 func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, ts taskmodel.TaskService, tcs backend.TaskControlService, opts ...executorOption) (*Executor, *ExecutorMetrics) {
 	cfg := &executorConfig{
@@ -144,11 +159,14 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		qs:  qs,
 		ps:  us,
 
+		iqls: cfg.influxQLService,
+
 		currentPromises:        sync.Map{},
 		futurePromises:         sync.Map{},
 		promiseQueue:           make(chan *promise, maxPromises),
 		workerLimit:            make(chan struct{}, cfg.maxWorkers),
-		limitFunc:              func(*taskmodel.Task, *taskmodel.Run) error { return nil }, // noop
+		limitFunc:              func(*taskmodel.Task, *taskmodel.Run) error { return nil },                     // noop
+		runFinishedFunc:        func(context.Context, *taskmodel.Task, *taskmodel.Run, taskmodel.RunStatus) {}, // noop
 		systemBuildCompiler:    cfg.systemBuildCompiler,
 		nonSystemBuildCompiler: cfg.nonSystemBuildCompiler,
 		flagger:                cfg.flagger,
@@ -175,6 +193,8 @@ type Executor struct {
 	qs query.QueryService
 	ps PermissionService
 
+	iqls influxql.ProxyQueryService
+
 	metrics *ExecutorMetrics
 
 	// currentPromises are all the promises we are made that have not been fulfilled
@@ -186,7 +206,8 @@ type Executor struct {
 	// keep a pool of promise's we have in queue
 	promiseQueue chan *promise
 
-	limitFunc LimitFunc
+	limitFunc       LimitFunc
+	runFinishedFunc RunFinishedFunc
 
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
@@ -248,6 +269,11 @@ func (e *Executor) SetLimitFunc(l LimitFunc) {
 	e.limitFunc = l
 }
 
+// SetRunFinishedFunc sets the func this task executor calls when a run finishes
+func (e *Executor) SetRunFinishedFunc(f RunFinishedFunc) {
+	e.runFinishedFunc = f
+}
+
 // Execute is a executor to satisfy the needs of tasks
 func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
 	_, err := e.PromisedExecute(ctx, id, scheduledFor, runAt)
@@ -345,6 +371,41 @@ func (e *Executor) ScheduleManualRun(ctx context.Context, id platform.ID, runID
 	return nil
 }
 
+// scheduleRetry creates a run retrying the failed run of p, to be executed
+// after delay.
+func (e *Executor) scheduleRetry(p *promise, delay time.Duration) (*taskmodel.Run, error) {
+	r, err := e.ts.RetryRun(p.ctx, p.task.ID, p.run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err = e.tcs.StartManualRun(p.ctx, p.task.ID, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.tcs.AddRunLog(p.ctx, p.task.ID, r.ID, time.Now().UTC(), fmt.Sprintf("Retry of run %s (attempt %d)", p.run.ID, r.Attempt)); err != nil {
+		e.log.Warn("error adding run log: ", zap.Error(err), zap.String("taskID", p.task.ID.String()), zap.String("runID", r.ID.String()))
+	}
+
+	// the retry must not be canceled along with the failed run
+	ctx, cancel := context.WithCancel(icontext.SetAuthorizer(context.Background(), p.auth))
+	rp := &promise{
+		run:        r,
+		task:       p.task,
+		auth:       p.auth,
+		createdAt:  time.Now().UTC(),
+		runAfter:   time.Now().Add(delay),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancelFunc: cancel,
+	}
+	e.metrics.retryRunsCounter.WithLabelValues(p.task.ID.String()).Inc()
+
+	e.futurePromises.Store(r.ID, rp)
+	return r, nil
+}
+
 func (e *Executor) ResumeCurrentRun(ctx context.Context, id platform.ID, runID platform.ID) (Promise, error) {
 	cr, err := e.tcs.CurrentlyRunning(ctx, id)
 	if err != nil {
@@ -472,6 +533,15 @@ func (e *Executor) processScheduledTasks() {
 	for range t {
 		e.futurePromises.Range(func(k any, v any) bool {
 			vv := v.(*promise)
+			if !vv.runAfter.IsZero() {
+				// retries are run once their backoff has elapsed
+				if !time.Now().Before(vv.runAfter) {
+					e.promiseQueue <- vv
+					e.futurePromises.Delete(k)
+					e.startWorker()
+				}
+				return true
+			}
 			if vv.run.ScheduledFor.Equal(time.Now()) || vv.run.ScheduledFor.Before(time.Now()) {
 				if vv.run.RunAt.IsZero() {
 					e.promiseQueue <- vv
@@ -616,6 +686,10 @@ func (w *worker) finish(p *promise, rs taskmodel.RunStatus, err error) {
 		}
 
 		p.err = err
+
+		if p.errClass != "" {
+			w.retry(p, err)
+		}
 	} else {
 		w.e.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
@@ -623,6 +697,54 @@ func (w *worker) finish(p *promise, rs taskmodel.RunStatus, err error) {
 	if _, err := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
 		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
+
+	w.e.runFinishedFunc(p.ctx, p.task, p.run, rs)
+}
+
+// fail finishes a failed run, whose error is of class. The run is retried if
+// the retry option of its task covers the class.
+func (w *worker) fail(p *promise, class string, err error) {
+	p.errClass = class
+	if err := w.e.tcs.UpdateRunErrorClass(p.ctx, p.task.ID, p.run.ID, class); err != nil {
+		w.e.log.Error("Failed to set run error class", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+	w.finish(p, taskmodel.RunFail, err)
+}
+
+// retry schedules a retry of a failed run, if the retry option of its task
+// allows another attempt of its scheduled time. It must be called before
+// the run is finished, so that the retry is linked to the run.
+func (w *worker) retry(p *promise, err error) {
+	if p.ctx.Err() != nil || backend.IsUnrecoverable(err) {
+		// canceled runs and unrecoverable errors are not retried
+		return
+	}
+
+	opts, oerr := options.FromScriptAST(fluxlang.DefaultService, p.task.Flux)
+	if oerr != nil {
+		return
+	}
+
+	attempts := p.run.Attempt
+	if attempts < 1 {
+		attempts = 1
+	}
+	if !opts.ShouldRetry(attempts, p.errClass) {
+		if attempts > 1 {
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Giving up after %d attempts", attempts))
+		}
+		return
+	}
+
+	delay := opts.RetryDelay(attempts)
+	r, rerr := w.e.scheduleRetry(p, delay)
+	if rerr != nil {
+		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Failed to retry run: %s", rerr.Error()))
+		w.e.log.Error("Failed to retry run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(rerr))
+		return
+	}
+
+	w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Retrying as run %s in %s (attempt %d of %d)", r.ID, delay, r.Attempt, *opts.Retry))
 }
 
 func (w *worker) executeQuery(p *promise) {
@@ -634,6 +756,11 @@ func (w *worker) executeQuery(p *promise) {
 
 	ctx = icontext.SetAuthorizer(ctx, p.auth)
 
+	if p.task.IsInfluxQL() {
+		w.executeInfluxQL(ctx, p)
+		return
+	}
+
 	buildCompiler := w.systemBuildCompiler
 	if p.task.Type != taskmodel.TaskSystemType {
 		buildCompiler = w.nonSystemBuildCompiler
@@ -656,7 +783,7 @@ func (w *worker) executeQuery(p *promise) {
 	it, err := w.e.qs.Query(ctx, req)
 	if err != nil {
 		// Assume the error should not be part of the runResult.
-		w.finish(p, taskmodel.RunFail, taskmodel.ErrQueryError(err))
+		w.fail(p, options.RetryOnQuery, taskmodel.ErrQueryError(err))
 		return
 	}
 
@@ -679,12 +806,12 @@ func (w *worker) executeQuery(p *promise) {
 	}
 
 	if runErr != nil {
-		w.finish(p, taskmodel.RunFail, taskmodel.ErrRunExecutionError(runErr))
+		w.fail(p, options.RetryOnExecution, taskmodel.ErrRunExecutionError(runErr))
 		return
 	}
 
 	if it.Err() != nil {
-		w.finish(p, taskmodel.RunFail, taskmodel.ErrResultIteratorError(it.Err()))
+		w.fail(p, options.RetryOnResult, taskmodel.ErrResultIteratorError(it.Err()))
 		return
 	}
 
@@ -715,9 +842,13 @@ type promise struct {
 
 	done chan struct{}
 	err  error
+	// errClass is the class of the error of a failed run.
+	errClass string
 
 	createdAt time.Time
 	startedAt time.Time
+	// runAfter is the time after which a retry is run.
+	runAfter time.Time
 
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
@@ -15,6 +16,8 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorization"
 	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/influxql"
+	iqlmock "github.com/influxdata/influxdb/v2/influxql/mock"
 	"github.com/influxdata/influxdb/v2/inmem"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/prom"
@@ -56,7 +59,7 @@ type tes struct {
 	tc      testCreds
 }
 
-func taskExecutorSystem(t *testing.T) tes {
+func taskExecutorSystem(t *testing.T, opts ...executorOption) tes {
 	var (
 		aqs = newFakeQueryService()
 		qs  = query.QueryServiceBridge{
@@ -87,7 +90,7 @@ func taskExecutorSystem(t *testing.T) tes {
 		})
 
 		tcs         = &taskControlService{TaskControlService: svc}
-		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, ps, svc, tcs)
+		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, ps, svc, tcs, opts...)
 	)
 	return tes{
 		svc:     aqs,
@@ -102,6 +105,7 @@ func taskExecutorSystem(t *testing.T) tes {
 func TestTaskExecutor(t *testing.T) {
 	t.Run("QuerySuccess", testQuerySuccess)
 	t.Run("QueryFailure", testQueryFailure)
+	t.Run("RetryFailure", testRetryFailure)
 	t.Run("ManualRun", testManualRun)
 	t.Run("ResumeRun", testResumingRun)
 	t.Run("WorkerLimit", testWorkerLimit)
@@ -109,6 +113,7 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("InfluxQL", testInfluxQL)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -215,6 +220,61 @@ func testQueryFailure(t *testing.T) {
 	}
 }
 
+func testRetryFailure(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtRetryTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	promiseID := platform.ID(promise.ID())
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("blargyblargblarg"))
+
+	<-promise.Done()
+
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	runs, err := tes.i.CurrentlyRunning(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 retry run, got %d", len(runs))
+	}
+
+	retry := runs[0]
+	if retry.RetryOf != promiseID {
+		t.Fatalf("expected retry of run %s, got %s", promiseID, retry.RetryOf)
+	}
+	if retry.Attempt != 2 {
+		t.Fatalf("expected attempt 2, got %d", retry.Attempt)
+	}
+	if !retry.ScheduledFor.Equal(time.Unix(126, 0).UTC()) {
+		t.Fatalf("expected retry to keep scheduled time, got %v", retry.ScheduledFor)
+	}
+
+	// the retry is the last attempt, so failing it again must not schedule another one
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("blargyblargblarg"))
+
+	require.Eventually(t, func() bool {
+		runs, err := tes.i.CurrentlyRunning(ctx, task.ID)
+		return err == nil && len(runs) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func testManualRun(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
@@ -539,6 +599,75 @@ func testErrorHandling(t *testing.T) {
 	*/
 }
 
+func testInfluxQL(t *testing.T) {
+	t.Parallel()
+
+	var (
+		reqs = make(chan *influxql.QueryRequest, 1)
+		resp = `{"results":[{"statement_id":0,"series":[{"name":"result","columns":["time","written"],"values":[["1970-01-01T00:00:00Z",42]]}]}]}`
+	)
+	iqls := &iqlmock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *influxql.QueryRequest) (influxql.Statistics, error) {
+			reqs <- req
+			_, err := io.WriteString(w, resp)
+			return influxql.Statistics{}, err
+		},
+	}
+	tes := taskExecutorSystem(t, WithInfluxQLService(iqls))
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{
+		OrganizationID: tes.tc.OrgID,
+		OwnerID:        tes.tc.Auth.GetUserID(),
+		Flux:           `option task = {name: "downsample", every: 1m}`,
+		Language:       taskmodel.TaskLanguageInfluxQL,
+		Query:          `SELECT mean(value) INTO cpu_1m FROM cpu WHERE time >= $now - 1m AND time < $now GROUP BY time(1m), *`,
+		Database:       "db0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(120, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	req := <-reqs
+	assert.Equal(t, task.Query, req.Query)
+	assert.Equal(t, "db0", req.DB)
+	assert.Equal(t, tes.tc.OrgID, req.OrganizationID)
+	assert.Equal(t, "1970-01-01T00:02:00Z", req.Params[taskmodel.InfluxQLNowParam])
+
+	var written bool
+	for _, l := range tes.tcs.run.Log {
+		if l.Message == "statement 0: wrote 42 points" {
+			written = true
+		}
+	}
+	if !written {
+		t.Fatalf("expected the points written in the run log, got %v", tes.tcs.run.Log)
+	}
+
+	// statement errors fail the run
+	resp = `{"results":[{"statement_id":0,"error":"retention policy not found: db0.autogen"}]}`
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(180, 0), time.Unix(186, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-promise.Done()
+	<-reqs
+
+	if got := promise.Error(); got == nil || !strings.Contains(got.Error(), "retention policy not found") {
+		t.Fatalf("expected statement error, got %v", got)
+	}
+}
+
 func TestPromiseFailure(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/influxql"
+	iqlquery "github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+// influxQLSource is the source of the InfluxQL queries of tasks.
+const influxQLSource = "tasks"
+
+// executeInfluxQL executes the InfluxQL query of the task of p against its
+// database and retention policy, with $now bound to the scheduled time of the
+// run. The messages of the statements and the points written by SELECT INTO
+// statements are added to the run log.
+func (w *worker) executeInfluxQL(ctx context.Context, p *promise) {
+	if w.e.iqls == nil {
+		w.finish(p, taskmodel.RunFail, taskmodel.ErrQueryError(errors.New("influxql tasks are not supported")))
+		return
+	}
+
+	req := &influxql.QueryRequest{
+		Authorization:  p.auth,
+		OrganizationID: p.task.OrganizationID,
+		DB:             p.task.Database,
+		RP:             p.task.RetentionPolicy,
+		Query:          p.task.Query,
+		Params: map[string]interface{}{
+			taskmodel.InfluxQLNowParam: p.run.ScheduledFor.UTC().Format(time.RFC3339Nano),
+		},
+		EncodingFormat: influxql.EncodingFormatJSON,
+		Source:         influxQLSource,
+	}
+
+	var buf bytes.Buffer
+	if _, err := w.e.iqls.Query(ctx, &buf, req); err != nil {
+		w.fail(p, options.RetryOnQuery, taskmodel.ErrQueryError(err))
+		return
+	}
+
+	// Statement errors are encoded in the response rather than returned.
+	var resp iqlquery.Response
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		w.fail(p, options.RetryOnResult, taskmodel.ErrResultIteratorError(err))
+		return
+	}
+
+	for _, res := range resp.Results {
+		for _, m := range res.Messages {
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("statement %d: %s: %s", res.StatementID, m.Level, m.Text))
+		}
+		if n, ok := influxQLWritten(res); ok {
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("statement %d: wrote %d points", res.StatementID, n))
+		}
+	}
+
+	if err := resp.Error(); err != nil {
+		w.fail(p, options.RetryOnExecution, taskmodel.ErrRunExecutionError(err))
+		return
+	}
+
+	w.finish(p, taskmodel.RunSuccess, nil)
+}
+
+// influxQLWritten returns the number of points written by a SELECT INTO
+// statement with result res, and whether res is the result of one.
+func influxQLWritten(res *iqlquery.Result) (int64, bool) {
+	if len(res.Series) != 1 {
+		return 0, false
+	}
+	row := res.Series[0]
+	if row.Name != "result" || len(row.Columns) != 2 || row.Columns[1] != "written" || len(row.Values) != 1 || len(row.Values[0]) != 2 {
+		return 0, false
+	}
+	switch n := row.Values[0][1].(type) {
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
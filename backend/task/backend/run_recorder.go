@@ -53,6 +53,13 @@ func (s *StoragePointsWriterRecorder) Record(ctx context.Context, bucketID platf
 	fields[fluxField] = run.Flux
 	fields[traceIDField] = run.TraceID
 	fields[traceSampledTag] = run.IsSampled
+	if run.RetryOf.Valid() {
+		fields[retryOfField] = run.RetryOf.String()
+		fields[attemptField] = run.Attempt
+	}
+	if run.ErrorClass != "" {
+		fields[errorClassField] = run.ErrorClass
+	}
 
 	startedAt := run.StartedAt
 	if startedAt.IsZero() {
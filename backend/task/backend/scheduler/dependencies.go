@@ -0,0 +1,282 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxRecentSuccesses is the number of successful scheduled times remembered
+// per upstream, so dependents can be triggered without looking up the runs
+// of their other upstreams. It is also the number of triggered scheduled
+// times remembered per dependent.
+const maxRecentSuccesses = 64
+
+// Dependent is a Schedulable that runs after its upstreams instead of on its
+// own schedule. It runs for a scheduled time of its schedule once each of
+// its upstreams has succeeded for that same time.
+type Dependent interface {
+	Schedulable
+
+	// Upstreams are the IDs of the Schedulables this Dependent runs after.
+	Upstreams() []ID
+}
+
+// SucceededFunc reports whether the run of id scheduled for scheduledFor succeeded.
+type SucceededFunc func(ctx context.Context, id ID, scheduledFor time.Time) (bool, error)
+
+// dependent tracks the scheduled times a Dependent was triggered for, so
+// that each is triggered once, in whatever order its upstreams succeed.
+// Scheduled times up to floor are never triggered: they ran before the
+// Dependent was scheduled, or are older than the triggered times remembered.
+type dependent struct {
+	schedule  Schedule
+	upstreams []ID
+	floor     time.Time
+	triggered []time.Time
+}
+
+// pending returns whether d is still to be triggered for scheduledFor.
+func (d *dependent) pending(scheduledFor time.Time) bool {
+	return d.schedule.Includes(scheduledFor) && scheduledFor.After(d.floor) && !containsTime(d.triggered, scheduledFor)
+}
+
+// trigger records that d was triggered for scheduledFor, and returns
+// whether scheduledFor is the latest time d was triggered for.
+func (d *dependent) trigger(scheduledFor time.Time) bool {
+	var dropped []time.Time
+	d.triggered, dropped = insertTime(d.triggered, scheduledFor)
+	for _, t := range dropped {
+		if t.After(d.floor) {
+			d.floor = t
+		}
+	}
+	return d.triggered[len(d.triggered)-1].Equal(scheduledFor)
+}
+
+// candidate is a Dependent that may be ready for a scheduled time, once
+// the upstreams in lookup are found to have succeeded for it.
+type candidate struct {
+	id     ID
+	d      *dependent
+	lookup []ID
+}
+
+// DependencyScheduler triggers Dependents when their upstreams succeed.
+// It is notified of successful runs by Succeeded, and executes the
+// Dependents that are ready for the same scheduled time with its Executor.
+type DependencyScheduler struct {
+	executor     Executor
+	checkpointer SchedulableService
+	succeeded    SucceededFunc
+	onErr        ErrorFunc
+
+	mu          sync.Mutex
+	dependents  map[ID]*dependent
+	downstreams map[ID]map[ID]struct{}
+	recent      map[ID][]time.Time
+	stopped     bool
+	wg          sync.WaitGroup
+}
+
+// NewDependencyScheduler returns a DependencyScheduler that executes
+// Dependents with executor and checkpoints them with checkpointer.
+// succeeded is used to look up upstream runs that finished before the
+// scheduler was notified of them, such as runs from before a restart.
+func NewDependencyScheduler(executor Executor, checkpointer SchedulableService, succeeded SucceededFunc, onErr ErrorFunc) *DependencyScheduler {
+	if onErr == nil {
+		onErr = func(_ context.Context, _ ID, _ time.Time, _ error) {}
+	}
+	return &DependencyScheduler{
+		executor:     executor,
+		checkpointer: checkpointer,
+		succeeded:    succeeded,
+		onErr:        onErr,
+		dependents:   map[ID]*dependent{},
+		downstreams:  map[ID]map[ID]struct{}{},
+		recent:       map[ID][]time.Time{},
+	}
+}
+
+// Schedule adds a Dependent to the scheduler, replacing it if it already exists.
+func (s *DependencyScheduler) Schedule(sch Schedulable) error {
+	d, ok := sch.(Dependent)
+	if !ok {
+		return errors.New("dependency scheduler can only schedule dependents")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.release(d.ID())
+	s.dependents[d.ID()] = &dependent{
+		schedule:  d.Schedule(),
+		upstreams: d.Upstreams(),
+		floor:     d.LastScheduled(),
+	}
+	for _, up := range d.Upstreams() {
+		if s.downstreams[up] == nil {
+			s.downstreams[up] = map[ID]struct{}{}
+		}
+		s.downstreams[up][d.ID()] = struct{}{}
+	}
+	return nil
+}
+
+// Release removes a Dependent from the scheduler.
+func (s *DependencyScheduler) Release(id ID) error {
+	s.mu.Lock()
+	s.release(id)
+	delete(s.recent, id)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *DependencyScheduler) release(id ID) {
+	d, ok := s.dependents[id]
+	if !ok {
+		return
+	}
+	for _, up := range d.upstreams {
+		delete(s.downstreams[up], id)
+		if len(s.downstreams[up]) == 0 {
+			delete(s.downstreams, up)
+		}
+	}
+	delete(s.dependents, id)
+}
+
+// Succeeded notifies the scheduler that the run of id scheduled for
+// scheduledFor succeeded, and triggers the Dependents of id whose
+// upstreams have all succeeded for scheduledFor. Dependents are triggered
+// for every scheduled time their upstreams succeed for, even when they
+// were already triggered for a later one.
+//
+// The runs of upstreams the scheduler wasn't notified of are looked up
+// without holding its lock, so that slow lookups don't block other
+// upstreams finishing.
+func (s *DependencyScheduler) Succeeded(ctx context.Context, id ID, scheduledFor time.Time) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.recordSuccess(id, scheduledFor)
+
+	var candidates []candidate
+	for down := range s.downstreams[id] {
+		d := s.dependents[down]
+		if !d.pending(scheduledFor) {
+			continue
+		}
+		c := candidate{id: down, d: d}
+		for _, up := range d.upstreams {
+			if !s.recentlySucceeded(up, scheduledFor) {
+				c.lookup = append(c.lookup, up)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range candidates {
+		ready, err := s.upstreamsSucceeded(ctx, c.lookup, scheduledFor)
+		if err != nil {
+			s.onErr(ctx, c.id, scheduledFor, err)
+			continue
+		}
+		if ready {
+			s.trigger(c, scheduledFor)
+		}
+	}
+}
+
+// upstreamsSucceeded returns whether every upstream in ids succeeded for
+// scheduledFor, recording their successes.
+func (s *DependencyScheduler) upstreamsSucceeded(ctx context.Context, ids []ID, scheduledFor time.Time) (bool, error) {
+	for _, up := range ids {
+		ok, err := s.succeeded(ctx, up, scheduledFor)
+		if err != nil || !ok {
+			return false, err
+		}
+		s.mu.Lock()
+		s.recordSuccess(up, scheduledFor)
+		s.mu.Unlock()
+	}
+	return true, nil
+}
+
+// trigger executes the Dependent of c for scheduledFor, unless it was
+// released, rescheduled or triggered for scheduledFor in the meantime.
+func (s *DependencyScheduler) trigger(c candidate, scheduledFor time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped || s.dependents[c.id] != c.d || !c.d.pending(scheduledFor) {
+		return
+	}
+	latest := c.d.trigger(scheduledFor)
+	s.wg.Add(1)
+	go s.execute(c.id, scheduledFor, latest)
+}
+
+func (s *DependencyScheduler) recentlySucceeded(id ID, scheduledFor time.Time) bool {
+	return containsTime(s.recent[id], scheduledFor)
+}
+
+// recordSuccess remembers scheduledFor as a success of id, keeping the
+// most recent times sorted.
+func (s *DependencyScheduler) recordSuccess(id ID, scheduledFor time.Time) {
+	s.recent[id], _ = insertTime(s.recent[id], scheduledFor)
+}
+
+// containsTime returns whether the sorted times contain t.
+func containsTime(times []time.Time, t time.Time) bool {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(t) })
+	return i < len(times) && times[i].Equal(t)
+}
+
+// insertTime inserts t into the sorted times, keeping at most the
+// maxRecentSuccesses latest times, and returns the times dropped to do so.
+func insertTime(times []time.Time, t time.Time) ([]time.Time, []time.Time) {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(t) })
+	if i < len(times) && times[i].Equal(t) {
+		return times, nil
+	}
+	times = append(times, time.Time{})
+	copy(times[i+1:], times[i:])
+	times[i] = t
+	if n := len(times) - maxRecentSuccesses; n > 0 {
+		dropped := append([]time.Time(nil), times[:n]...)
+		return times[n:], dropped
+	}
+	return times, nil
+}
+
+// execute executes id for scheduledFor, checkpointing it when scheduledFor
+// is the latest time it was triggered for, so that late scheduled times
+// don't move its checkpoint back.
+func (s *DependencyScheduler) execute(id ID, scheduledFor time.Time, checkpoint bool) {
+	defer s.wg.Done()
+
+	ctx := context.Background()
+	if err := s.executor.Execute(ctx, id, scheduledFor, time.Now().UTC()); err != nil {
+		s.onErr(ctx, id, scheduledFor, err)
+	}
+	if !checkpoint {
+		return
+	}
+	if err := s.checkpointer.UpdateLastScheduled(ctx, id, scheduledFor); err != nil {
+		s.onErr(ctx, id, scheduledFor, err)
+	}
+}
+
+// Stop stops triggering Dependents and waits for the executions in progress.
+func (s *DependencyScheduler) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.wg.Wait()
+}
@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockDependent struct {
+	mockSchedulable
+	upstreams []ID
+}
+
+func (d mockDependent) Upstreams() []ID {
+	return d.upstreams
+}
+
+type dependencyExecution struct {
+	id           ID
+	scheduledFor time.Time
+}
+
+func newTestDependencyScheduler(t *testing.T, succeeded SucceededFunc) (*DependencyScheduler, chan dependencyExecution) {
+	t.Helper()
+
+	ch := make(chan dependencyExecution, 10)
+	exe := &mockExecutor{fn: func(_ *sync.Mutex, _ context.Context, id ID, scheduledFor time.Time) {
+		ch <- dependencyExecution{id: id, scheduledFor: scheduledFor}
+	}}
+	if succeeded == nil {
+		succeeded = func(context.Context, ID, time.Time) (bool, error) { return false, nil }
+	}
+	s := NewDependencyScheduler(exe, &mockSchedulableService{}, succeeded, func(_ context.Context, _ ID, _ time.Time, err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	t.Cleanup(s.Stop)
+	return s, ch
+}
+
+func hourly(t *testing.T) Schedule {
+	t.Helper()
+
+	sch, _, err := NewSchedule("@every 1h", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func expectExecution(t *testing.T, ch chan dependencyExecution, expected dependencyExecution) {
+	t.Helper()
+
+	select {
+	case got := <-ch:
+		if got != expected {
+			t.Fatalf("expected execution of %d for %s, got %d for %s", expected.id, expected.scheduledFor, got.id, got.scheduledFor)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected execution of %d for %s", expected.id, expected.scheduledFor)
+	}
+}
+
+func expectNoExecution(t *testing.T, ch chan dependencyExecution) {
+	t.Helper()
+
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected execution of %d for %s", got.id, got.scheduledFor)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDependencyScheduler_Succeeded(t *testing.T) {
+	var (
+		s, ch = newTestDependencyScheduler(t, nil)
+		w1    = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+		w2    = w1.Add(time.Hour)
+	)
+
+	if err := s.Schedule(mockDependent{
+		mockSchedulable: mockSchedulable{id: 3, schedule: hourly(t)},
+		upstreams:       []ID{1, 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the dependent waits for all of its upstreams
+	s.Succeeded(context.Background(), 1, w1)
+	expectNoExecution(t, ch)
+
+	// an upstream succeeding for another window does not trigger it
+	s.Succeeded(context.Background(), 2, w2)
+	expectNoExecution(t, ch)
+
+	s.Succeeded(context.Background(), 2, w1)
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w1})
+
+	// a window is only triggered once
+	s.Succeeded(context.Background(), 1, w1)
+	expectNoExecution(t, ch)
+
+	// times that are not in the schedule of the dependent do not trigger it
+	s.Succeeded(context.Background(), 1, w2.Add(30*time.Minute))
+	s.Succeeded(context.Background(), 2, w2.Add(30*time.Minute))
+	expectNoExecution(t, ch)
+
+	s.Succeeded(context.Background(), 1, w2)
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w2})
+}
+
+func TestDependencyScheduler_SucceededFunc(t *testing.T) {
+	var (
+		w = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+
+		// upstream 1 succeeded before the scheduler was notified of it
+		s, ch = newTestDependencyScheduler(t, func(_ context.Context, id ID, scheduledFor time.Time) (bool, error) {
+			return id == 1 && scheduledFor.Equal(w), nil
+		})
+	)
+
+	if err := s.Schedule(mockDependent{
+		mockSchedulable: mockSchedulable{id: 3, schedule: hourly(t)},
+		upstreams:       []ID{1, 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Succeeded(context.Background(), 2, w)
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w})
+}
+
+func TestDependencyScheduler_LateWindow(t *testing.T) {
+	var (
+		s, ch = newTestDependencyScheduler(t, nil)
+		w1    = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+		w2    = w1.Add(time.Hour)
+	)
+
+	if err := s.Schedule(mockDependent{
+		mockSchedulable: mockSchedulable{id: 3, schedule: hourly(t)},
+		upstreams:       []ID{1, 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Succeeded(context.Background(), 1, w1)
+	s.Succeeded(context.Background(), 1, w2)
+	s.Succeeded(context.Background(), 2, w2)
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w2})
+
+	// an upstream finishing an earlier window late still triggers it
+	s.Succeeded(context.Background(), 2, w1)
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w1})
+
+	s.Succeeded(context.Background(), 2, w1)
+	expectNoExecution(t, ch)
+}
+
+func TestDependencyScheduler_SucceededFuncUnlocked(t *testing.T) {
+	var (
+		w       = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+		looking = make(chan struct{})
+		release = make(chan struct{})
+
+		// looking up upstream 1 blocks until released
+		s, ch = newTestDependencyScheduler(t, func(_ context.Context, id ID, scheduledFor time.Time) (bool, error) {
+			if id != 1 {
+				return false, nil
+			}
+			close(looking)
+			<-release
+			return true, nil
+		})
+	)
+
+	for _, d := range []mockDependent{
+		{mockSchedulable: mockSchedulable{id: 3, schedule: hourly(t)}, upstreams: []ID{1, 2}},
+		{mockSchedulable: mockSchedulable{id: 5, schedule: hourly(t)}, upstreams: []ID{4}},
+	} {
+		if err := s.Schedule(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Succeeded(context.Background(), 2, w)
+		close(done)
+	}()
+	<-looking
+
+	// other upstreams finishing aren't blocked by the lookup
+	s.Succeeded(context.Background(), 4, w)
+	expectExecution(t, ch, dependencyExecution{id: 5, scheduledFor: w})
+
+	close(release)
+	<-done
+	expectExecution(t, ch, dependencyExecution{id: 3, scheduledFor: w})
+}
+
+func TestDependencyScheduler_LastScheduled(t *testing.T) {
+	var (
+		s, ch = newTestDependencyScheduler(t, nil)
+		w     = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+	)
+
+	// windows up to the last scheduled time have already run
+	if err := s.Schedule(mockDependent{
+		mockSchedulable: mockSchedulable{id: 2, schedule: hourly(t), lastScheduled: w},
+		upstreams:       []ID{1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Succeeded(context.Background(), 1, w)
+	expectNoExecution(t, ch)
+
+	s.Succeeded(context.Background(), 1, w.Add(time.Hour))
+	expectExecution(t, ch, dependencyExecution{id: 2, scheduledFor: w.Add(time.Hour)})
+}
+
+func TestDependencyScheduler_Release(t *testing.T) {
+	var (
+		s, ch = newTestDependencyScheduler(t, nil)
+		w     = time.Date(2021, 3, 10, 1, 0, 0, 0, time.UTC)
+	)
+
+	if err := s.Schedule(mockDependent{
+		mockSchedulable: mockSchedulable{id: 2, schedule: hourly(t)},
+		upstreams:       []ID{1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(2); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Succeeded(context.Background(), 1, w)
+	expectNoExecution(t, ch)
+
+	if err := s.Schedule(mockSchedulable{id: 2, schedule: hourly(t)}); err == nil {
+		t.Fatal(errors.New("expected error scheduling a schedulable without upstreams"))
+	}
+}
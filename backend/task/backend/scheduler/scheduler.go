@@ -52,13 +52,27 @@ type SchedulableService interface {
 	UpdateLastScheduled(ctx context.Context, id ID, t time.Time) error
 }
 
+// NewSchedule parses a cron string, which may be prefixed with the location
+// it is evaluated in (see options.CronWithLocation), into a Schedule. It
+// returns lastScheduledAt aligned to the schedule.
 func NewSchedule(unparsed string, lastScheduledAt time.Time) (Schedule, time.Time, error) {
 	lastScheduledAt = lastScheduledAt.UTC().Truncate(time.Second)
+	location, unparsed := options.SplitCronLocation(unparsed)
 	c, err := cron.ParseUTC(unparsed)
 	if err != nil {
 		return Schedule{}, lastScheduledAt, err
 	}
 
+	if location != "" {
+		loc, err := options.LoadLocation(location)
+		if err != nil {
+			return Schedule{}, lastScheduledAt, err
+		}
+		if loc != time.UTC {
+			return Schedule{cron: c, loc: loc}, lastScheduledAt, nil
+		}
+	}
+
 	unparsed = strings.TrimSpace(unparsed)
 
 	// Align create to the hour/minute
@@ -68,35 +82,111 @@ func NewSchedule(unparsed string, lastScheduledAt time.Time) (Schedule, time.Tim
 		err := every.Parse(everyString)
 		if err != nil {
 			// We cannot align a invalid time
-			return Schedule{c}, lastScheduledAt, nil
+			return Schedule{cron: c}, lastScheduledAt, nil
 		}
 
 		// drop nanoseconds
 		lastScheduledAt = time.Unix(lastScheduledAt.UTC().Unix(), 0).UTC()
 		everyDur, err := every.DurationFrom(lastScheduledAt)
 		if err != nil {
-			return Schedule{c}, lastScheduledAt, nil
+			return Schedule{cron: c}, lastScheduledAt, nil
 		}
 
 		// and align
 		lastScheduledAt = lastScheduledAt.Truncate(everyDur).Truncate(time.Second)
+		return Schedule{cron: c, every: everyDur}, lastScheduledAt, nil
 	}
 
-	return Schedule{c}, lastScheduledAt, err
+	return Schedule{cron: c}, lastScheduledAt, err
 }
 
 // Schedule is an object a valid schedule of runs
 type Schedule struct {
 	cron cron.Parsed
+
+	// every is the alignment of @every schedules.
+	every time.Duration
+
+	// loc is the location cron is evaluated in, when it is not UTC.
+	loc *time.Location
 }
 
 // Next returns the next time after from that a schedule should trigger on.
+//
+// Schedules with a location trigger on the wall clock times of the location.
+// Wall clock times skipped by a daylight saving time transition trigger once,
+// at the end of the transition, and wall clock times that occur twice trigger
+// only on their first occurrence.
 func (s Schedule) Next(from time.Time) (time.Time, error) {
-	return cron.Parsed(s.cron).Next(from)
+	if s.loc == nil {
+		return cron.Parsed(s.cron).Next(from)
+	}
+
+	// evaluate the cron in UTC on the wall clock time of from in the location
+	wall := wallClock(from.In(s.loc))
+	for {
+		next, err := cron.Parsed(s.cron).Next(wall)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t := s.fromWall(next); t.After(from) {
+			return t.UTC(), nil
+		}
+		// the wall clock time occurred already, before a transition
+		wall = next
+	}
+}
+
+// wallClock returns the wall clock time of t as a UTC time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// fromWall returns the first time the wall clock time of wall occurs in the
+// location of s, or the end of the transition that skipped it.
+func (s Schedule) fromWall(wall time.Time) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, s.loc)
+	if tWall := wallClock(t); !tWall.Equal(wall) {
+		// skipped by a transition; t is on either side of the transition
+		start, end := t.ZoneBounds()
+		if tWall.After(wall) {
+			return start
+		}
+		return end
+	}
+	// of two occurrences time.Date may return the second, after a transition
+	// that turned the clock back
+	start, _ := t.ZoneBounds()
+	if start.IsZero() {
+		return t
+	}
+	_, offset := t.Zone()
+	_, prevOffset := start.Add(-time.Second).Zone()
+	if prevOffset > offset {
+		if earlier := t.Add(-time.Duration(prevOffset-offset) * time.Second); earlier.Before(start) {
+			return earlier
+		}
+	}
+	return t
+}
+
+// Includes returns whether the schedule triggers on t.
+func (s Schedule) Includes(t time.Time) bool {
+	if s.every > 0 {
+		return t.Equal(t.Truncate(s.every))
+	}
+	next, err := s.Next(t.Add(-time.Second))
+	return err == nil && next.Equal(t)
 }
 
 // ValidSchedule returns an error if the cron string is invalid.
 func ValidateSchedule(c string) error {
+	location, c := options.SplitCronLocation(c)
+	if location != "" {
+		if _, err := options.LoadLocation(location); err != nil {
+			return err
+		}
+	}
 	_, err := cron.ParseUTC(c)
 	return err
 }
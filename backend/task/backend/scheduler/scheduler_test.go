@@ -667,6 +667,12 @@ func mustCron(s string) Schedule {
 	return Schedule{cron: cr}
 }
 
+func mustEvery(s string, every time.Duration) Schedule {
+	sch := mustCron(s)
+	sch.every = every
+	return sch
+}
+
 func TestNewSchedule(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -686,14 +692,14 @@ func TestNewSchedule(t *testing.T) {
 			name:            "align to minute",
 			unparsed:        "@every 1m",
 			lastScheduledAt: time.Date(2016, 01, 01, 01, 10, 23, 1234567, time.UTC),
-			want:            mustCron("@every 1m"),
+			want:            mustEvery("@every 1m", time.Minute),
 			want1:           time.Date(2016, 01, 01, 01, 10, 0, 0, time.UTC),
 		},
 		{
 			name:            "align to minute with @every 7m",
 			unparsed:        "@every 7m",
 			lastScheduledAt: time.Date(2016, 01, 01, 01, 10, 23, 1234567, time.UTC),
-			want:            mustCron("@every 7m"),
+			want:            mustEvery("@every 7m", 7*time.Minute),
 			want1:           time.Date(2016, 01, 01, 01, 4, 0, 0, time.UTC),
 		},
 
@@ -701,14 +707,14 @@ func TestNewSchedule(t *testing.T) {
 			name:            "align to hour",
 			unparsed:        "@every 1h",
 			lastScheduledAt: time.Date(2016, 01, 01, 01, 10, 23, 1234567, time.UTC),
-			want:            mustCron("@every 1h"),
+			want:            mustEvery("@every 1h", time.Hour),
 			want1:           time.Date(2016, 01, 01, 01, 0, 0, 0, time.UTC),
 		},
 		{
 			name:            "align to hour @every 3h",
 			unparsed:        "@every 3h",
 			lastScheduledAt: time.Date(2016, 01, 01, 01, 10, 23, 1234567, time.UTC),
-			want:            mustCron("@every 3h"),
+			want:            mustEvery("@every 3h", 3*time.Hour),
 			want1:           time.Date(2016, 01, 01, 00, 0, 0, 0, time.UTC),
 		},
 	}
@@ -739,3 +745,120 @@ func mustParseLocation(tzname string) *time.Location {
 	}
 	return loc
 }
+
+func TestSchedule_Includes(t *testing.T) {
+	base := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		cron     string
+		t        time.Time
+		expected bool
+	}{
+		{name: "every aligned", cron: "@every 1h", t: base.Add(3 * time.Hour), expected: true},
+		{name: "every unaligned", cron: "@every 1h", t: base.Add(90 * time.Minute)},
+		{name: "cron match", cron: "0 6 * * *", t: base.Add(6 * time.Hour), expected: true},
+		{name: "cron mismatch", cron: "0 6 * * *", t: base.Add(7 * time.Hour)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sch, _, err := NewSchedule(tt.cron, base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sch.Includes(tt.t); got != tt.expected {
+				t.Errorf("expected Includes(%s) to be %t", tt.t, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextLocation(t *testing.T) {
+	berlin := mustParseLocation("Europe/Berlin")
+	newYork := mustParseLocation("America/New_York")
+
+	for _, tt := range []struct {
+		name     string
+		cron     string
+		from     time.Time
+		expected []time.Time
+	}{
+		{
+			name: "daily across transitions",
+			cron: "CRON_TZ=Europe/Berlin 0 6 * * *",
+			from: time.Date(2021, 3, 27, 0, 0, 0, 0, berlin),
+			expected: []time.Time{
+				time.Date(2021, 3, 27, 6, 0, 0, 0, berlin), // CET, 05:00 UTC
+				time.Date(2021, 3, 28, 6, 0, 0, 0, berlin), // CEST, 04:00 UTC
+			},
+		},
+		{
+			name: "skipped time runs at the end of the transition",
+			cron: "CRON_TZ=Europe/Berlin 30 2 * * *",
+			from: time.Date(2021, 3, 27, 3, 0, 0, 0, berlin),
+			expected: []time.Time{
+				time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC), // 03:00 CEST
+				time.Date(2021, 3, 29, 2, 30, 0, 0, berlin),
+			},
+		},
+		{
+			name: "skipped time runs at the end of the transition west of UTC",
+			cron: "CRON_TZ=America/New_York 30 2 * * *",
+			from: time.Date(2021, 3, 13, 3, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2021, 3, 14, 7, 0, 0, 0, time.UTC), // 03:00 EDT
+				time.Date(2021, 3, 15, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name: "repeated time runs on its first occurrence",
+			cron: "CRON_TZ=Europe/Berlin 30 * * * *",
+			from: time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC), // 02:00 CEST
+			expected: []time.Time{
+				time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+				time.Date(2021, 10, 31, 2, 30, 0, 0, time.UTC), // 03:30 CET
+			},
+		},
+		{
+			name: "repeated time runs on its first occurrence west of UTC",
+			cron: "CRON_TZ=America/New_York 30 * * * *",
+			from: time.Date(2021, 11, 7, 5, 0, 0, 0, time.UTC), // 01:00 EDT
+			expected: []time.Time{
+				time.Date(2021, 11, 7, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+				time.Date(2021, 11, 7, 7, 30, 0, 0, time.UTC), // 02:30 EST
+			},
+		},
+		{
+			name: "UTC location",
+			cron: "CRON_TZ=UTC 0 6 * * *",
+			from: time.Date(2021, 3, 27, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 27, 6, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 28, 6, 0, 0, 0, time.UTC),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sch, _, err := NewSchedule(tt.cron, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := tt.from
+			for _, expected := range tt.expected {
+				next, err := sch.Next(from)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !next.Equal(expected) {
+					t.Fatalf("expected next after %s to be %s, got %s", from.UTC(), expected.UTC(), next)
+				}
+				if !sch.Includes(next) {
+					t.Fatalf("expected schedule to include %s", next)
+				}
+				from = next
+			}
+		})
+	}
+
+	if _, _, err := NewSchedule("CRON_TZ=Mars/Olympus 0 6 * * *", time.Now()); err == nil {
+		t.Fatal("expected error for unknown location")
+	}
+}
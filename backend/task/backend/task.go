@@ -27,6 +27,9 @@ type TaskControlService interface {
 	// UpdateRunState sets the run state at the respective time.
 	UpdateRunState(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error
 
+	// UpdateRunErrorClass sets the class of the error of a failed run.
+	UpdateRunErrorClass(ctx context.Context, taskID, runID platform.ID, class string) error
+
 	// AddRunLog adds a log line to the run.
 	AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
 }
@@ -0,0 +1,170 @@
+// Package backfill provides backfills, which run a task for every time it
+// would have been scheduled for within a range of time.
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+const (
+	// DefaultConcurrency is the number of runs a backfill executes at a time
+	// when its concurrency is not set.
+	DefaultConcurrency = 1
+	// MaxConcurrency is the maximum number of runs a backfill may execute at a time.
+	MaxConcurrency = 10
+	// MaxRuns is the maximum number of runs of a single backfill.
+	MaxRuns = 10000
+)
+
+// Status is the status of a backfill.
+type Status string
+
+const (
+	StatusRunning  Status = "running"
+	StatusSuccess  Status = "success"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// BackfillService manages backfills.
+type BackfillService interface {
+	// FindBackfillByID returns a single backfill by ID.
+	FindBackfillByID(ctx context.Context, id platform.ID) (*Backfill, error)
+
+	// FindBackfills returns the backfills that match filter.
+	FindBackfills(ctx context.Context, filter Filter) ([]*Backfill, error)
+
+	// CreateBackfill creates a backfill of a task and starts executing its runs.
+	CreateBackfill(ctx context.Context, bc BackfillCreate) (*Backfill, error)
+
+	// CancelBackfill stops a running backfill, canceling the runs it is executing.
+	CancelBackfill(ctx context.Context, id platform.ID) (*Backfill, error)
+}
+
+// Backfill runs a task for every time it is scheduled for after Start and
+// until End, executing at most Concurrency runs at a time. Concurrency is at
+// most the concurrency of the task, whose scheduled runs count towards it
+// too.
+type Backfill struct {
+	ID          platform.ID `json:"id"`
+	TaskID      platform.ID `json:"taskID"`
+	OrgID       platform.ID `json:"orgID"`
+	Start       time.Time   `json:"start"`
+	End         time.Time   `json:"end"`
+	Concurrency int64       `json:"concurrency"`
+
+	Status Status `json:"status"`
+	// Error is the reason the backfill stopped before executing all its runs.
+	Error string `json:"error,omitempty"`
+
+	// Total is the number of runs of the backfill, of which Succeeded and
+	// Failed have finished.
+	Total     int64 `json:"total"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// BackfillCreate is the request to create a backfill.
+type BackfillCreate struct {
+	TaskID      platform.ID `json:"taskID"`
+	Start       time.Time   `json:"start"`
+	End         time.Time   `json:"end"`
+	Concurrency int64       `json:"concurrency,omitempty"`
+}
+
+// Filter restricts the backfills returned by FindBackfills.
+type Filter struct {
+	TaskID *platform.ID
+	OrgID  *platform.ID
+}
+
+// Valid returns an error if the backfill request is invalid.
+func (bc BackfillCreate) Valid() error {
+	if !bc.TaskID.Valid() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill taskID is invalid",
+		}
+	}
+	if bc.Start.IsZero() || bc.End.IsZero() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill start and end are required",
+		}
+	}
+	if !bc.End.After(bc.Start) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill end must be after start",
+		}
+	}
+	if bc.Concurrency < 0 || bc.Concurrency > MaxConcurrency {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("backfill concurrency must be between 1 and %d", MaxConcurrency),
+		}
+	}
+	return nil
+}
+
+// Done returns whether the backfill has stopped executing runs.
+func (b *Backfill) Done() bool {
+	return b.Status != StatusRunning
+}
+
+// ScheduledTimes returns the times t is scheduled for after start and until
+// end, which are the times the scheduler would have run t at if it had been
+// active for the whole range. Times whose run, delayed by the offset of t,
+// would be after now are left to the scheduler.
+func ScheduledTimes(t *taskmodel.Task, start, end, now time.Time) ([]time.Time, error) {
+	sch, from, err := scheduler.NewSchedule(t.EffectiveCron(), start)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "task schedule is invalid",
+			Err:  err,
+		}
+	}
+
+	var times []time.Time
+	for {
+		next, err := sch.Next(from)
+		if err != nil {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "task schedule is invalid",
+				Err:  err,
+			}
+		}
+		if next.After(end) || next.Add(t.Offset).After(now) {
+			break
+		}
+		if len(times) == MaxRuns {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("backfill range exceeds the maximum of %d runs", MaxRuns),
+			}
+		}
+		times = append(times, next)
+		from = next
+	}
+
+	if len(times) == 0 {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill range contains no scheduled times",
+		}
+	}
+	return times, nil
+}
@@ -0,0 +1,142 @@
+package backfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillCreate_Valid(t *testing.T) {
+	var (
+		start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		end   = start.Add(24 * time.Hour)
+	)
+
+	cases := []struct {
+		name string
+		src  BackfillCreate
+		err  error
+	}{
+		{
+			name: "valid",
+			src:  BackfillCreate{TaskID: 1, Start: start, End: end, Concurrency: 4},
+		},
+		{
+			name: "invalid taskID",
+			src:  BackfillCreate{Start: start, End: end},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "backfill taskID is invalid",
+			},
+		},
+		{
+			name: "missing end",
+			src:  BackfillCreate{TaskID: 1, Start: start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "backfill start and end are required",
+			},
+		},
+		{
+			name: "end before start",
+			src:  BackfillCreate{TaskID: 1, Start: end, End: start},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "backfill end must be after start",
+			},
+		},
+		{
+			name: "concurrency too large",
+			src:  BackfillCreate{TaskID: 1, Start: start, End: end, Concurrency: MaxConcurrency + 1},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "backfill concurrency must be between 1 and 10",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			itesting.ErrorsEqual(t, c.src.Valid(), c.err)
+		})
+	}
+}
+
+func TestScheduledTimes(t *testing.T) {
+	var (
+		start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		now   = time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	t.Run("every", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Every: "1h"}
+
+		times, err := ScheduledTimes(task, start, start.Add(3*time.Hour), now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			start.Add(time.Hour),
+			start.Add(2 * time.Hour),
+			start.Add(3 * time.Hour),
+		}, times)
+	})
+
+	t.Run("unaligned start", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Every: "1h"}
+
+		times, err := ScheduledTimes(task, start.Add(30*time.Minute), start.Add(150*time.Minute), now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			start.Add(time.Hour),
+			start.Add(2 * time.Hour),
+		}, times)
+	})
+
+	t.Run("cron", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Cron: "0 6 * * *"}
+
+		times, err := ScheduledTimes(task, start, start.Add(72*time.Hour), now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			start.Add(6 * time.Hour),
+			start.Add(30 * time.Hour),
+			start.Add(54 * time.Hour),
+		}, times)
+	})
+
+	t.Run("offset runs after now are left to the scheduler", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Every: "1h", Offset: 30 * time.Minute}
+
+		times, err := ScheduledTimes(task, start, start.Add(3*time.Hour), start.Add(150*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			start.Add(time.Hour),
+			start.Add(2 * time.Hour),
+		}, times)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Every: "1d"}
+
+		_, err := ScheduledTimes(task, start, start.Add(time.Hour), now)
+		itesting.ErrorsEqual(t, err, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill range contains no scheduled times",
+		})
+	})
+
+	t.Run("too many runs", func(t *testing.T) {
+		task := &taskmodel.Task{ID: platform.ID(1), Every: "1s"}
+
+		_, err := ScheduledTimes(task, start, start.Add(24*time.Hour), now)
+		itesting.ErrorsEqual(t, err, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "backfill range exceeds the maximum of 10000 runs",
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package backfill
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+var _ BackfillService = (*AuthedService)(nil)
+
+// AuthedService authorizes access to backfills. Backfills run their task,
+// so they require the permissions of the task.
+type AuthedService struct {
+	s  BackfillService
+	ts taskmodel.TaskService
+}
+
+// NewAuthedService constructs an instance of an authorizing backfill service.
+// ts is used to look up the organization of the task of new backfills.
+func NewAuthedService(s BackfillService, ts taskmodel.TaskService) *AuthedService {
+	return &AuthedService{s: s, ts: ts}
+}
+
+// FindBackfillByID checks to see if the authorizer on context has read access to the task of the backfill.
+func (s *AuthedService) FindBackfillByID(ctx context.Context, id platform.ID) (*Backfill, error) {
+	b, err := s.s.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.TasksResourceType, b.TaskID, b.OrgID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// FindBackfills returns the backfills of the tasks the authorizer on context has read access to.
+func (s *AuthedService) FindBackfills(ctx context.Context, filter Filter) ([]*Backfill, error) {
+	backfills, err := s.s.FindBackfills(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	authorized := backfills[:0]
+	for _, b := range backfills {
+		if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.TasksResourceType, b.TaskID, b.OrgID); err != nil {
+			continue
+		}
+		authorized = append(authorized, b)
+	}
+	return authorized, nil
+}
+
+// CreateBackfill checks to see if the authorizer on context has write access to the task.
+func (s *AuthedService) CreateBackfill(ctx context.Context, bc BackfillCreate) (*Backfill, error) {
+	// Unauthenticated task lookup, to identify the task's organization.
+	t, err := s.ts.FindTaskByID(ctx, bc.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.TasksResourceType, t.ID, t.OrganizationID); err != nil {
+		return nil, err
+	}
+	return s.s.CreateBackfill(ctx, bc)
+}
+
+// CancelBackfill checks to see if the authorizer on context has write access to the task of the backfill.
+func (s *AuthedService) CancelBackfill(ctx context.Context, id platform.ID) (*Backfill, error) {
+	b, err := s.s.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.TasksResourceType, b.TaskID, b.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.CancelBackfill(ctx, id)
+}
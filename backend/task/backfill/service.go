@@ -0,0 +1,445 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/task/backend/executor"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"go.uber.org/zap"
+)
+
+var (
+	backfillBucket = []byte("taskbackfillsv1")
+
+	// ErrBackfillNotFound is used when the backfill is not found.
+	ErrBackfillNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "backfill not found",
+	}
+
+	// ErrInvalidBackfillID is used when the service was provided an invalid ID format.
+	ErrInvalidBackfillID = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided backfill ID has invalid format",
+	}
+
+	// ErrBackfillDone is used when canceling a backfill that is no longer running.
+	ErrBackfillDone = &errors.Error{
+		Code: errors.EConflict,
+		Msg:  "backfill is not running",
+	}
+)
+
+// Executor is an abstraction of the task executor with only the functions needed to execute backfills.
+type Executor interface {
+	ManualRun(ctx context.Context, id platform.ID, runID platform.ID) (executor.Promise, error)
+}
+
+var _ BackfillService = (*Service)(nil)
+
+// Service is an implementation of BackfillService backed by the kv store.
+// Backfills are executed by the service itself rather than the scheduler,
+// but their runs are still limited by the executor to the concurrency of
+// the task, along with its scheduled runs. The concurrency of a backfill is
+// capped at the concurrency of its task.
+type Service struct {
+	log  *zap.Logger
+	kv   kv.Store
+	ts   taskmodel.TaskService
+	ex   Executor
+	lang fluxlang.FluxLanguageService
+
+	IDGenerator   platform.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+
+	mu      sync.Mutex
+	cancels map[platform.ID]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewService constructs a backfill service backed by store. The runs of
+// backfills are created with ts and executed by ex; ts must not schedule
+// the runs it creates itself. The options of tasks are parsed with lang.
+func NewService(log *zap.Logger, store kv.Store, ts taskmodel.TaskService, ex Executor, lang fluxlang.FluxLanguageService) *Service {
+	return &Service{
+		log:           log,
+		kv:            store,
+		ts:            ts,
+		ex:            ex,
+		lang:          lang,
+		IDGenerator:   snowflake.NewIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+		cancels:       make(map[platform.ID]context.CancelFunc),
+	}
+}
+
+func internalBackfillStoreError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal backfill data error; Err: %v", err),
+		Op:   "kv/backfill",
+	}
+}
+
+// Open fails the backfills that were still running when the service was
+// last stopped, since their runs were interrupted.
+func (s *Service) Open(ctx context.Context) error {
+	backfills, err := s.FindBackfills(ctx, Filter{})
+	if err != nil {
+		return err
+	}
+	for _, b := range backfills {
+		if b.Done() {
+			continue
+		}
+		if _, err := s.update(ctx, b.ID, func(b *Backfill) {
+			s.finish(b, StatusFailed, "backfill interrupted by restart")
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops executing backfills. They are failed when the service is opened again.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// FindBackfillByID returns a single backfill by ID.
+func (s *Service) FindBackfillByID(ctx context.Context, id platform.ID) (*Backfill, error) {
+	var b *Backfill
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		b, err = s.findBackfillByID(tx, id)
+		return err
+	})
+	return b, err
+}
+
+func (s *Service) findBackfillByID(tx kv.Tx, id platform.ID) (*Backfill, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidBackfillID
+	}
+
+	bkt, err := tx.Bucket(backfillBucket)
+	if err != nil {
+		return nil, internalBackfillStoreError(err)
+	}
+
+	v, err := bkt.Get(encID)
+	if kv.IsNotFound(err) {
+		return nil, ErrBackfillNotFound
+	}
+	if err != nil {
+		return nil, internalBackfillStoreError(err)
+	}
+
+	var b Backfill
+	if err := json.Unmarshal(v, &b); err != nil {
+		return nil, internalBackfillStoreError(err)
+	}
+	return &b, nil
+}
+
+// FindBackfills returns the backfills that match filter.
+func (s *Service) FindBackfills(ctx context.Context, filter Filter) ([]*Backfill, error) {
+	backfills := make([]*Backfill, 0)
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		bkt, err := tx.Bucket(backfillBucket)
+		if err != nil {
+			return internalBackfillStoreError(err)
+		}
+
+		cur, err := bkt.ForwardCursor(nil)
+		if err != nil {
+			return internalBackfillStoreError(err)
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var b Backfill
+			if err := json.Unmarshal(v, &b); err != nil {
+				return internalBackfillStoreError(err)
+			}
+			if filter.TaskID != nil && b.TaskID != *filter.TaskID {
+				continue
+			}
+			if filter.OrgID != nil && b.OrgID != *filter.OrgID {
+				continue
+			}
+			backfills = append(backfills, &b)
+		}
+		return cur.Err()
+	})
+	return backfills, err
+}
+
+// CreateBackfill creates a backfill of a task and starts executing its runs
+// in the background, with the authorizer on ctx.
+func (s *Service) CreateBackfill(ctx context.Context, bc BackfillCreate) (*Backfill, error) {
+	if err := bc.Valid(); err != nil {
+		return nil, err
+	}
+	if bc.Concurrency == 0 {
+		bc.Concurrency = DefaultConcurrency
+	}
+
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.ts.FindTaskByID(ctx, bc.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The executor doesn't execute more runs of the task at a time than its
+	// concurrency, so a backfill can't either. A single run at a time fits
+	// any task.
+	if bc.Concurrency > 1 {
+		opts, err := options.FromScriptAST(s.lang, t.Flux)
+		if err != nil {
+			return nil, taskmodel.ErrTaskOptionParse(err)
+		}
+		if opts.Concurrency != nil && bc.Concurrency > *opts.Concurrency {
+			bc.Concurrency = *opts.Concurrency
+		}
+	}
+
+	now := s.TimeGenerator.Now()
+	times, err := ScheduledTimes(t, bc.Start, bc.End, now)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backfill{
+		ID:          s.IDGenerator.ID(),
+		TaskID:      t.ID,
+		OrgID:       t.OrganizationID,
+		Start:       bc.Start.UTC(),
+		End:         bc.End.UTC(),
+		Concurrency: bc.Concurrency,
+		Status:      StatusRunning,
+		Total:       int64(len(times)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		return s.putBackfill(tx, b)
+	}); err != nil {
+		return nil, err
+	}
+
+	// the backfill must not be canceled along with the request that created it
+	runCtx, cancel := context.WithCancel(icontext.SetAuthorizer(context.Background(), auth))
+	s.mu.Lock()
+	s.cancels[b.ID] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(runCtx, *b, times)
+
+	return b, nil
+}
+
+// CancelBackfill stops a running backfill, canceling the runs it is executing.
+func (s *Service) CancelBackfill(ctx context.Context, id platform.ID) (*Backfill, error) {
+	b, err := s.update(ctx, id, func(b *Backfill) {
+		if !b.Done() {
+			s.finish(b, StatusCanceled, "")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != StatusCanceled {
+		return nil, ErrBackfillDone
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+	return b, nil
+}
+
+// run executes the runs of b for times, at most b.Concurrency at a time.
+func (s *Service) run(ctx context.Context, b Backfill, times []time.Time) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.mu.Lock()
+		delete(s.cancels, b.ID)
+		s.mu.Unlock()
+	}()
+
+	log := s.log.With(zap.String("backfillID", b.ID.String()), zap.String("taskID", b.TaskID.String()))
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		runErr  error
+		sem     = make(chan struct{}, b.Concurrency)
+	)
+
+loop:
+	for _, t := range times {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+
+		wg.Add(1)
+		go func(t time.Time) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := s.execute(ctx, b.TaskID, t)
+			if ctx.Err() != nil {
+				// runs interrupted by canceling the backfill are not counted
+				return
+			}
+			if err != nil {
+				log.Debug("Backfill run failed", zap.Time("scheduledFor", t), zap.Error(err))
+			}
+			if errors.ErrorCode(err) == errors.ENotFound {
+				// the task was deleted, so none of the remaining runs can succeed
+				errOnce.Do(func() {
+					runErr = err
+					cancel()
+				})
+				return
+			}
+
+			if _, uerr := s.update(context.Background(), b.ID, func(b *Backfill) {
+				if err != nil {
+					b.Failed++
+				} else {
+					b.Succeeded++
+				}
+			}); uerr != nil {
+				log.Error("Failed to record backfill progress", zap.Error(uerr))
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	if runErr == nil && ctx.Err() != nil {
+		// the backfill was canceled or the service closed
+		return
+	}
+
+	if _, err := s.update(context.Background(), b.ID, func(b *Backfill) {
+		if b.Done() {
+			return
+		}
+		switch {
+		case runErr != nil:
+			s.finish(b, StatusFailed, runErr.Error())
+		case b.Failed > 0:
+			s.finish(b, StatusFailed, fmt.Sprintf("%d of %d runs failed", b.Failed, b.Total))
+		default:
+			s.finish(b, StatusSuccess, "")
+		}
+	}); err != nil {
+		log.Error("Failed to finish backfill", zap.Error(err))
+	}
+}
+
+// execute runs the task for scheduledFor and waits for the run to finish.
+func (s *Service) execute(ctx context.Context, taskID platform.ID, scheduledFor time.Time) error {
+	r, err := s.ts.ForceRun(ctx, taskID, scheduledFor.Unix())
+	if err != nil {
+		return err
+	}
+
+	p, err := s.ex.ManualRun(ctx, taskID, r.ID)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-p.Done():
+		return p.Error()
+	case <-ctx.Done():
+		p.Cancel(context.Background())
+		<-p.Done()
+		return ctx.Err()
+	}
+}
+
+func (s *Service) finish(b *Backfill, status Status, msg string) {
+	now := s.TimeGenerator.Now()
+	b.Status = status
+	b.Error = msg
+	b.FinishedAt = &now
+}
+
+// update applies fn to the stored backfill with id and stores the result.
+func (s *Service) update(ctx context.Context, id platform.ID, fn func(b *Backfill)) (*Backfill, error) {
+	var b *Backfill
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		b, err = s.findBackfillByID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		fn(b)
+		b.UpdatedAt = s.TimeGenerator.Now()
+		return s.putBackfill(tx, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Service) putBackfill(tx kv.Tx, b *Backfill) error {
+	encID, err := b.ID.Encode()
+	if err != nil {
+		return ErrInvalidBackfillID
+	}
+
+	v, err := json.Marshal(b)
+	if err != nil {
+		return internalBackfillStoreError(err)
+	}
+
+	bkt, err := tx.Bucket(backfillBucket)
+	if err != nil {
+		return internalBackfillStoreError(err)
+	}
+	if err := bkt.Put(encID, v); err != nil {
+		return internalBackfillStoreError(err)
+	}
+	return nil
+}
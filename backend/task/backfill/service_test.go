@@ -0,0 +1,277 @@
+package backfill_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/task/backend/executor"
+	"github.com/influxdata/influxdb/v2/task/backfill"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	taskID = platform.ID(1)
+	orgID  = platform.ID(2)
+)
+
+type fakePromise struct {
+	id   platform.ID
+	done chan struct{}
+	err  error
+	once sync.Once
+}
+
+func (p *fakePromise) ID() platform.ID { return p.id }
+
+func (p *fakePromise) Cancel(ctx context.Context) {
+	p.finish(context.Canceled)
+}
+
+func (p *fakePromise) Done() <-chan struct{} { return p.done }
+
+func (p *fakePromise) Error() error { return p.err }
+
+func (p *fakePromise) finish(err error) {
+	p.once.Do(func() {
+		p.err = err
+		close(p.done)
+	})
+}
+
+// fakeExecutor finishes runs immediately with the error returned by fail
+// for their scheduled time, or holds them until canceled when hold is set.
+type fakeExecutor struct {
+	mu       sync.Mutex
+	runs     map[platform.ID]time.Time
+	promises []*fakePromise
+	fail     func(scheduledFor time.Time) error
+	hold     bool
+}
+
+func (e *fakeExecutor) ManualRun(ctx context.Context, id platform.ID, runID platform.ID) (executor.Promise, error) {
+	if _, err := icontext.GetAuthorizer(ctx); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := &fakePromise{id: runID, done: make(chan struct{})}
+	e.promises = append(e.promises, p)
+	if !e.hold {
+		var err error
+		if e.fail != nil {
+			err = e.fail(e.runs[runID])
+		}
+		p.finish(err)
+	}
+	return p, nil
+}
+
+func (e *fakeExecutor) running() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, p := range e.promises {
+		select {
+		case <-p.done:
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+func newTestService(t *testing.T, ex *fakeExecutor) *backfill.Service {
+	t.Helper()
+
+	ex.runs = make(map[platform.ID]time.Time)
+	ids := mock.NewIncrementingIDGenerator(100)
+
+	ts := mock.NewTaskService()
+	ts.FindTaskByIDFn = func(ctx context.Context, id platform.ID) (*taskmodel.Task, error) {
+		if id != taskID {
+			return nil, taskmodel.ErrTaskNotFound
+		}
+		return &taskmodel.Task{
+			ID:             taskID,
+			OrganizationID: orgID,
+			Every:          "1h",
+			Flux:           `option task = {name: "foo", every: 1h, concurrency: 2}`,
+		}, nil
+	}
+	ts.ForceRunFn = func(ctx context.Context, id platform.ID, scheduledFor int64) (*taskmodel.Run, error) {
+		ex.mu.Lock()
+		defer ex.mu.Unlock()
+		r := &taskmodel.Run{ID: ids.ID(), TaskID: id, ScheduledFor: time.Unix(scheduledFor, 0).UTC()}
+		ex.runs[r.ID] = r.ScheduledFor
+		return r, nil
+	}
+
+	svc := backfill.NewService(zaptest.NewLogger(t), itesting.NewTestInmemStore(t), ts, ex, fluxlang.DefaultService)
+	svc.IDGenerator = mock.NewIncrementingIDGenerator(1)
+	t.Cleanup(func() { svc.Close() })
+	return svc
+}
+
+func authCtx() context.Context {
+	return icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: 3, OrgID: orgID, Status: influxdb.Active})
+}
+
+func waitForBackfill(t *testing.T, svc *backfill.Service, id platform.ID) *backfill.Backfill {
+	t.Helper()
+
+	var b *backfill.Backfill
+	require.Eventually(t, func() bool {
+		var err error
+		b, err = svc.FindBackfillByID(context.Background(), id)
+		require.NoError(t, err)
+		return b.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+	return b
+}
+
+func TestService_CreateBackfill(t *testing.T) {
+	var (
+		ex    = &fakeExecutor{}
+		svc   = newTestService(t, ex)
+		start = time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	)
+
+	b, err := svc.CreateBackfill(authCtx(), backfill.BackfillCreate{
+		TaskID:      taskID,
+		Start:       start,
+		End:         start.Add(12 * time.Hour),
+		Concurrency: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, platform.ID(1), b.ID)
+	assert.Equal(t, orgID, b.OrgID)
+	assert.Equal(t, int64(12), b.Total)
+	// the concurrency of the backfill is capped at the concurrency of the task
+	assert.Equal(t, int64(2), b.Concurrency)
+
+	b = waitForBackfill(t, svc, b.ID)
+	assert.Equal(t, backfill.StatusSuccess, b.Status)
+	assert.Equal(t, int64(12), b.Succeeded)
+	assert.Equal(t, int64(0), b.Failed)
+	assert.NotNil(t, b.FinishedAt)
+
+	assert.Len(t, ex.runs, 12)
+
+	backfills, err := svc.FindBackfills(context.Background(), backfill.Filter{TaskID: &taskID})
+	require.NoError(t, err)
+	assert.Len(t, backfills, 1)
+
+	_, err = svc.CancelBackfill(context.Background(), b.ID)
+	assert.Equal(t, backfill.ErrBackfillDone, err)
+}
+
+func TestService_CreateBackfill_Failures(t *testing.T) {
+	var (
+		start = time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+		ex    = &fakeExecutor{
+			fail: func(scheduledFor time.Time) error {
+				if scheduledFor.Equal(start.Add(2 * time.Hour)) {
+					return errors.New("query failed")
+				}
+				return nil
+			},
+		}
+		svc = newTestService(t, ex)
+	)
+
+	b, err := svc.CreateBackfill(authCtx(), backfill.BackfillCreate{
+		TaskID: taskID,
+		Start:  start,
+		End:    start.Add(4 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	b = waitForBackfill(t, svc, b.ID)
+	assert.Equal(t, backfill.StatusFailed, b.Status)
+	assert.Equal(t, int64(3), b.Succeeded)
+	assert.Equal(t, int64(1), b.Failed)
+	assert.Equal(t, "1 of 4 runs failed", b.Error)
+
+	_, err = svc.CreateBackfill(authCtx(), backfill.BackfillCreate{
+		TaskID: platform.ID(9),
+		Start:  start,
+		End:    start.Add(4 * time.Hour),
+	})
+	assert.Equal(t, taskmodel.ErrTaskNotFound, err)
+}
+
+func TestService_CancelBackfill(t *testing.T) {
+	var (
+		ex    = &fakeExecutor{hold: true}
+		svc   = newTestService(t, ex)
+		start = time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	)
+
+	b, err := svc.CreateBackfill(authCtx(), backfill.BackfillCreate{
+		TaskID:      taskID,
+		Start:       start,
+		End:         start.Add(12 * time.Hour),
+		Concurrency: 2,
+	})
+	require.NoError(t, err)
+
+	// the backfill never executes more runs than its concurrency
+	require.Eventually(t, func() bool {
+		return ex.running() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	b, err = svc.CancelBackfill(context.Background(), b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backfill.StatusCanceled, b.Status)
+
+	// the runs it was executing are canceled with it
+	require.Eventually(t, func() bool {
+		return ex.running() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	b, err = svc.FindBackfillByID(context.Background(), b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backfill.StatusCanceled, b.Status)
+	assert.Equal(t, int64(0), b.Succeeded+b.Failed)
+	assert.Len(t, ex.promises, 2)
+}
+
+func TestService_Open(t *testing.T) {
+	var (
+		ex    = &fakeExecutor{hold: true}
+		svc   = newTestService(t, ex)
+		start = time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	)
+
+	b, err := svc.CreateBackfill(authCtx(), backfill.BackfillCreate{
+		TaskID: taskID,
+		Start:  start,
+		End:    start.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.Close())
+
+	// closing the service leaves the backfill running until it is opened again
+	b, err = svc.FindBackfillByID(context.Background(), b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backfill.StatusRunning, b.Status)
+
+	require.NoError(t, svc.Open(context.Background()))
+	b, err = svc.FindBackfillByID(context.Background(), b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backfill.StatusFailed, b.Status)
+	assert.Equal(t, "backfill interrupted by restart", b.Error)
+}
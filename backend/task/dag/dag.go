@@ -0,0 +1,309 @@
+// Package dag provides the pipelines formed by tasks that depend on other
+// tasks, and the status of their runs for each scheduled time.
+package dag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+const (
+	// DefaultWindows is the number of scheduled times returned when it is not set.
+	DefaultWindows = 10
+	// MaxWindows is the maximum number of scheduled times returned.
+	MaxWindows = 100
+
+	// StatusPending is the status of a task in a window it has no run for.
+	StatusPending = "pending"
+
+	// maxLookback is how far back windows are searched for, which is as
+	// long as the runs of tasks are kept.
+	maxLookback = 14 * 24 * time.Hour
+)
+
+// DAG is the pipeline a task belongs to: the task, the tasks it depends on
+// and the tasks that depend on it, transitively.
+type DAG struct {
+	TaskID platform.ID `json:"taskID"`
+	// Nodes are ordered so that tasks come after the tasks they depend on.
+	Nodes []Node `json:"nodes"`
+	// Windows are the most recent scheduled times of the task, newest first.
+	Windows []Window `json:"windows"`
+}
+
+// Node is a task of a DAG.
+type Node struct {
+	ID        platform.ID   `json:"id"`
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	DependsOn []platform.ID `json:"dependsOn,omitempty"`
+}
+
+// Window is the status of the tasks of a DAG for a scheduled time.
+type Window struct {
+	ScheduledFor time.Time    `json:"scheduledFor"`
+	Tasks        []TaskStatus `json:"tasks"`
+}
+
+// TaskStatus is the status of the run of a task for a window.
+type TaskStatus struct {
+	TaskID platform.ID  `json:"taskID"`
+	RunID  *platform.ID `json:"runID,omitempty"`
+	Status string       `json:"status"`
+}
+
+// Find returns the DAG of the task with id and the status of its tasks for
+// the windows most recent scheduled times before now. The tasks of the DAG
+// are looked up within the organization of the task with ts.
+func Find(ctx context.Context, ts taskmodel.TaskService, id platform.ID, windows int, now time.Time) (*DAG, error) {
+	if windows == 0 {
+		windows = DefaultWindows
+	}
+	if windows < 0 || windows > MaxWindows {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("windows must be between 1 and %d", MaxWindows),
+		}
+	}
+
+	t, err := ts.FindTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := findOrgTasks(ctx, ts, t.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	tasks[t.ID] = t
+
+	nodes := connected(tasks, t.ID)
+
+	times, err := ScheduledWindows(t, windows, now)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DAG{
+		TaskID:  t.ID,
+		Nodes:   make([]Node, 0, len(nodes)),
+		Windows: make([]Window, len(times)),
+	}
+	for i, tm := range times {
+		d.Windows[i] = Window{ScheduledFor: tm, Tasks: make([]TaskStatus, 0, len(nodes))}
+	}
+
+	for _, n := range nodes {
+		d.Nodes = append(d.Nodes, Node{
+			ID:        n.ID,
+			Name:      n.Name,
+			Status:    n.Status,
+			DependsOn: n.DependsOn,
+		})
+
+		runs, err := findWindowRuns(ctx, ts, n.ID, times)
+		if err != nil {
+			return nil, err
+		}
+		for i, tm := range times {
+			st := TaskStatus{TaskID: n.ID, Status: StatusPending}
+			if r, ok := runs[tm.Unix()]; ok {
+				rid := r.ID
+				st.RunID = &rid
+				st.Status = r.Status
+			}
+			d.Windows[i].Tasks = append(d.Windows[i].Tasks, st)
+		}
+	}
+	return d, nil
+}
+
+// findOrgTasks returns the tasks of the organization with orgID by ID.
+func findOrgTasks(ctx context.Context, ts taskmodel.TaskService, orgID platform.ID) (map[platform.ID]*taskmodel.Task, error) {
+	tasks := make(map[platform.ID]*taskmodel.Task)
+	filter := taskmodel.TaskFilter{OrganizationID: &orgID, Limit: taskmodel.TaskMaxPageSize}
+	for {
+		page, _, err := ts.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			tasks[t.ID] = t
+		}
+		if len(page) < filter.Limit {
+			return tasks, nil
+		}
+		after := page[len(page)-1].ID
+		filter.After = &after
+	}
+}
+
+// connected returns the tasks connected to the task with id through their
+// dependencies, ordered so that tasks come after the tasks they depend on.
+func connected(tasks map[platform.ID]*taskmodel.Task, id platform.ID) []*taskmodel.Task {
+	downstreams := make(map[platform.ID][]platform.ID)
+	for _, t := range tasks {
+		for _, up := range t.DependsOn {
+			downstreams[up] = append(downstreams[up], t.ID)
+		}
+	}
+
+	seen := map[platform.ID]bool{id: true}
+	queue := []platform.ID{id}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		var adjacent []platform.ID
+		if t, ok := tasks[next]; ok {
+			adjacent = append(adjacent, t.DependsOn...)
+		}
+		adjacent = append(adjacent, downstreams[next]...)
+		for _, a := range adjacent {
+			if _, ok := tasks[a]; ok && !seen[a] {
+				seen[a] = true
+				queue = append(queue, a)
+			}
+		}
+	}
+
+	ids := make([]platform.ID, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// order the tasks by the length of their longest chain of dependencies
+	depth := make(map[platform.ID]int, len(ids))
+	var depthOf func(id platform.ID, visiting map[platform.ID]bool) int
+	depthOf = func(id platform.ID, visiting map[platform.ID]bool) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		if visiting[id] {
+			// dependencies are validated to be acyclic, but a cycle must not recurse forever
+			return 0
+		}
+		visiting[id] = true
+		d := 0
+		for _, up := range tasks[id].DependsOn {
+			if seen[up] {
+				if ud := depthOf(up, visiting) + 1; ud > d {
+					d = ud
+				}
+			}
+		}
+		depth[id] = d
+		return d
+	}
+	for _, id := range ids {
+		depthOf(id, map[platform.ID]bool{})
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return depth[ids[i]] < depth[ids[j]] })
+
+	nodes := make([]*taskmodel.Task, len(ids))
+	for i, id := range ids {
+		nodes[i] = tasks[id]
+	}
+	return nodes
+}
+
+// findWindowRuns returns the runs of the task with id for times by the unix
+// time they are scheduled for. A successful run takes precedence over other
+// runs for the same time, such as the failed runs it retried.
+func findWindowRuns(ctx context.Context, ts taskmodel.TaskService, id platform.ID, times []time.Time) (map[int64]*taskmodel.Run, error) {
+	runs := make(map[int64]*taskmodel.Run)
+	if len(times) == 0 {
+		return runs, nil
+	}
+
+	found, _, err := ts.FindRuns(ctx, taskmodel.RunFilter{
+		Task: id,
+		// the time filter excludes its bounds
+		AfterTime:  times[len(times)-1].Add(-time.Second).UTC().Format(time.RFC3339),
+		BeforeTime: times[0].Add(time.Second).UTC().Format(time.RFC3339),
+		Limit:      taskmodel.TaskMaxPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range found {
+		key := r.ScheduledFor.Unix()
+		if prev, ok := runs[key]; ok && prev.Status == taskmodel.RunSuccess.String() {
+			continue
+		}
+		runs[key] = r
+	}
+	return runs, nil
+}
+
+// ScheduledWindows returns the n most recent times t is scheduled for at or
+// before now, newest first. Fewer times are returned when t was not
+// scheduled n times within the time runs are kept.
+func ScheduledWindows(t *taskmodel.Task, n int, now time.Time) ([]time.Time, error) {
+	invalid := func(err error) error {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "task schedule is invalid",
+			Err:  err,
+		}
+	}
+
+	// estimate the lookback from the interval between the next two times,
+	// and widen it until it covers n times
+	sch, from, err := scheduler.NewSchedule(t.EffectiveCron(), now)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	first, err := sch.Next(from)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	second, err := sch.Next(first)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	lookback := second.Sub(first) * time.Duration(n)
+
+	for {
+		if lookback > maxLookback {
+			lookback = maxLookback
+		}
+
+		sch, from, err := scheduler.NewSchedule(t.EffectiveCron(), now.Add(-lookback))
+		if err != nil {
+			return nil, invalid(err)
+		}
+		var times []time.Time
+		for {
+			next, err := sch.Next(from)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			if next.After(now) {
+				break
+			}
+			times = append(times, next)
+			from = next
+		}
+
+		if len(times) >= n || lookback == maxLookback {
+			if len(times) > n {
+				times = times[len(times)-n:]
+			}
+			for i, j := 0, len(times)-1; i < j; i, j = i+1, j-1 {
+				times[i], times[j] = times[j], times[i]
+			}
+			return times, nil
+		}
+		lookback *= 2
+	}
+}
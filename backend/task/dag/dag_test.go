@@ -0,0 +1,139 @@
+package dag_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/dag"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledWindows(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	t.Run("every", func(t *testing.T) {
+		task := &taskmodel.Task{ID: 1, Every: "1h"}
+
+		times, err := dag.ScheduledWindows(task, 3, now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 10, 10, 0, 0, 0, time.UTC),
+		}, times)
+	})
+
+	t.Run("irregular cron", func(t *testing.T) {
+		// weekdays only, so the interval between the next times underestimates the lookback
+		task := &taskmodel.Task{ID: 1, Cron: "0 6 * * 1-5"}
+
+		times, err := dag.ScheduledWindows(task, 4, time.Date(2021, 3, 15, 7, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			time.Date(2021, 3, 15, 6, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 12, 6, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 11, 6, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 10, 6, 0, 0, 0, time.UTC),
+		}, times)
+	})
+
+	t.Run("fewer times than requested within lookback", func(t *testing.T) {
+		task := &taskmodel.Task{ID: 1, Cron: "0 0 1 * *"}
+
+		times, err := dag.ScheduledWindows(task, 5, now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		}, times)
+	})
+}
+
+func TestFind(t *testing.T) {
+	var (
+		orgID = platform.ID(10)
+		now   = time.Date(2021, 3, 10, 12, 30, 0, 0, time.UTC)
+		w1    = time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+		w2    = time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC)
+
+		tasks = []*taskmodel.Task{
+			{ID: 1, OrganizationID: orgID, Name: "ingest", Status: "active", Every: "1h"},
+			{ID: 2, OrganizationID: orgID, Name: "downsample", Status: "active", Every: "1h", DependsOn: []platform.ID{1}},
+			{ID: 3, OrganizationID: orgID, Name: "report", Status: "active", Every: "1h", DependsOn: []platform.ID{1, 2}},
+			{ID: 4, OrganizationID: orgID, Name: "unrelated", Status: "active", Every: "1h"},
+		}
+		runs = map[platform.ID][]*taskmodel.Run{
+			1: {
+				{ID: 100, TaskID: 1, ScheduledFor: w1, Status: "success"},
+				{ID: 101, TaskID: 1, ScheduledFor: w2, Status: "success"},
+			},
+			2: {
+				{ID: 200, TaskID: 2, ScheduledFor: w1, Status: "started"},
+				{ID: 202, TaskID: 2, ScheduledFor: w2, Status: "success"},
+				// the failed run retried by run 202
+				{ID: 201, TaskID: 2, ScheduledFor: w2, Status: "failed"},
+			},
+		}
+	)
+
+	ts := mock.NewTaskService()
+	ts.FindTaskByIDFn = func(ctx context.Context, id platform.ID) (*taskmodel.Task, error) {
+		for _, t := range tasks {
+			if t.ID == id {
+				return t, nil
+			}
+		}
+		return nil, taskmodel.ErrTaskNotFound
+	}
+	ts.FindTasksFn = func(ctx context.Context, f taskmodel.TaskFilter) ([]*taskmodel.Task, int, error) {
+		return tasks, len(tasks), nil
+	}
+	ts.FindRunsFn = func(ctx context.Context, f taskmodel.RunFilter) ([]*taskmodel.Run, int, error) {
+		return runs[f.Task], len(runs[f.Task]), nil
+	}
+
+	d, err := dag.Find(context.Background(), ts, 2, 2, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, platform.ID(2), d.TaskID)
+	assert.Equal(t, []dag.Node{
+		{ID: 1, Name: "ingest", Status: "active"},
+		{ID: 2, Name: "downsample", Status: "active", DependsOn: []platform.ID{1}},
+		{ID: 3, Name: "report", Status: "active", DependsOn: []platform.ID{1, 2}},
+	}, d.Nodes)
+
+	id := func(i platform.ID) *platform.ID { return &i }
+	assert.Equal(t, []dag.Window{
+		{
+			ScheduledFor: w1,
+			Tasks: []dag.TaskStatus{
+				{TaskID: 1, RunID: id(100), Status: "success"},
+				{TaskID: 2, RunID: id(200), Status: "started"},
+				{TaskID: 3, Status: dag.StatusPending},
+			},
+		},
+		{
+			ScheduledFor: w2,
+			Tasks: []dag.TaskStatus{
+				{TaskID: 1, RunID: id(101), Status: "success"},
+				{TaskID: 2, RunID: id(202), Status: "success"},
+				{TaskID: 3, Status: dag.StatusPending},
+			},
+		},
+	}, d.Windows)
+
+	_, err = dag.Find(context.Background(), ts, 2, dag.MaxWindows+1, now)
+	itesting.ErrorsEqual(t, err, &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "windows must be between 1 and 100",
+	})
+
+	_, err = dag.Find(context.Background(), ts, 9, 0, now)
+	assert.Equal(t, taskmodel.ErrTaskNotFound, err)
+}
@@ -153,6 +153,19 @@ func (d *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID p
 	return nil
 }
 
+// UpdateRunErrorClass sets the class of the error of a failed run.
+func (d *TaskControlService) UpdateRunErrorClass(ctx context.Context, taskID, runID platform.ID, class string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	run, ok := d.runs[taskID][runID]
+	if !ok {
+		panic("run error class called without a run")
+	}
+	run.ErrorClass = class
+	return nil
+}
+
 // AddRunLog adds a log line to the run.
 func (d *TaskControlService) AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error {
 	d.mu.Lock()
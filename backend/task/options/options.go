@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	// the location option must be valid regardless of the time zone database of the host
+	_ "time/tzdata"
 
 	"github.com/influxdata/cron"
 	"github.com/influxdata/flux/ast"
@@ -20,6 +22,26 @@ import (
 const maxConcurrency = 100
 const maxRetry = 10
 
+// maxHistoryCount is the maximum number of runs the history option can keep,
+// which is as many as a single page of runs.
+const maxHistoryCount = 500
+
+// defaultRetryBackoff is the delay before the first retry of a failed run,
+// when the retry option does not specify a backoff.
+const defaultRetryBackoff = 10 * time.Second
+
+// Classes of run errors a failed run can be retried on.
+const (
+	// RetryOnQuery is the class of errors starting the query of a run.
+	RetryOnQuery = "query"
+	// RetryOnExecution is the class of errors executing the query of a run.
+	RetryOnExecution = "execution"
+	// RetryOnResult is the class of errors reading the results of a run.
+	RetryOnResult = "result"
+)
+
+var retryClasses = []string{RetryOnQuery, RetryOnExecution, RetryOnResult}
+
 // Options are the task-related options that can be specified in a Flux script.
 type Options struct {
 	// Name is a non optional name designator for each task.
@@ -28,6 +50,9 @@ type Options struct {
 	// Cron is a cron style time schedule that can be used in place of Every.
 	Cron string `json:"cron,omitempty"`
 
+	// Location is the IANA time zone Cron is evaluated in; UTC when empty.
+	Location string `json:"location,omitempty"`
+
 	// Every represents a fixed period to repeat execution.
 	// this can be unmarshaled from json as a string i.e.: "1d" will unmarshal as 1 day
 	Every Duration `json:"every,omitempty"`
@@ -38,7 +63,25 @@ type Options struct {
 
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
+	// Retry is the maximum number of attempts of a scheduled time, the first
+	// run included.
 	Retry *int64 `json:"retry,omitempty"`
+
+	// RetryBackoff is the delay before the first retry of a failed run, doubled
+	// before each further retry.
+	RetryBackoff *Duration `json:"retryBackoff,omitempty"`
+
+	// RetryOn are the classes of run errors a failed run is retried on; all
+	// classes when empty.
+	RetryOn []string `json:"retryOn,omitempty"`
+
+	// HistoryCount is the number of most recent runs kept in the run history;
+	// all runs within the retention of the system bucket when nil.
+	HistoryCount *int64 `json:"historyCount,omitempty"`
+
+	// HistoryAge is how long runs are kept in the run history; as long as the
+	// retention of the system bucket when nil.
+	HistoryAge *Duration `json:"historyAge,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -113,30 +156,51 @@ func (a *Duration) Add(t time.Time) (time.Time, error) {
 func (o *Options) Clear() {
 	o.Name = ""
 	o.Cron = ""
+	o.Location = ""
 	o.Every = Duration{}
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.RetryBackoff = nil
+	o.RetryOn = nil
+	o.HistoryCount = nil
+	o.HistoryAge = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
 func (o *Options) IsZero() bool {
 	return o.Name == "" &&
 		o.Cron == "" &&
+		o.Location == "" &&
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.RetryBackoff == nil &&
+		len(o.RetryOn) == 0 &&
+		o.HistoryCount == nil &&
+		o.HistoryAge == nil
 }
 
 // All the task option names we accept.
 const (
 	optName        = "name"
 	optCron        = "cron"
+	optLocation    = "location"
 	optEvery       = "every"
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optHistory     = "history"
+
+	// The properties of the object form of the retry option.
+	optRetryAttempts = "attempts"
+	optRetryBackoff  = "backoff"
+	optRetryOn       = "on"
+
+	// The properties of the history option.
+	optHistoryCount = "count"
+	optHistoryAge   = "age"
 )
 
 // FluxLanguageService is a service for interacting with flux code.
@@ -222,9 +286,11 @@ type extractFn func(*Options, *ast.ObjectExpression) error
 var taskOptionExtractors = []extractFn{
 	extractNameOption,
 	extractScheduleOptions,
+	extractLocationOption,
 	extractOffsetOption,
 	extractConcurrencyOption,
 	extractRetryOption,
+	extractHistoryOption,
 }
 
 func extractNameOption(opts *Options, objExpr *ast.ObjectExpression) error {
@@ -270,6 +336,21 @@ func extractScheduleOptions(opts *Options, objExpr *ast.ObjectExpression) error
 	return nil
 }
 
+func extractLocationOption(opts *Options, objExpr *ast.ObjectExpression) error {
+	locationExpr, err := edit.GetProperty(objExpr, optLocation)
+	if err != nil {
+		return nil
+	}
+
+	locationStr, ok := locationExpr.(*ast.StringLiteral)
+	if !ok {
+		return errParseTaskOptionField(optLocation)
+	}
+	opts.Location = ast.StringFromLiteral(locationStr)
+
+	return nil
+}
+
 func extractOffsetOption(opts *Options, objExpr *ast.ObjectExpression) error {
 	offsetExpr, offsetErr := edit.GetProperty(objExpr, optOffset)
 	if offsetErr != nil {
@@ -314,12 +395,91 @@ func extractRetryOption(opts *Options, objExpr *ast.ObjectExpression) error {
 		return nil
 	}
 
-	retryInt, ok := retryExpr.(*ast.IntegerLiteral)
-	if !ok {
+	switch retryExprV := retryExpr.(type) {
+	case *ast.IntegerLiteral:
+		val := ast.IntegerFromLiteral(retryExprV)
+		opts.Retry = &val
+	case *ast.ObjectExpression:
+		return extractRetryPolicy(opts, retryExprV)
+	default:
 		return errParseTaskOptionField(optRetry)
 	}
-	val := ast.IntegerFromLiteral(retryInt)
-	opts.Retry = &val
+
+	return nil
+}
+
+// extractRetryPolicy extracts the object form of the retry option, i.e.
+// retry: {attempts: 3, backoff: 30s, on: ["query", "execution"]}.
+func extractRetryPolicy(opts *Options, objExpr *ast.ObjectExpression) error {
+	for _, p := range objExpr.Properties {
+		field := optRetry + "." + p.Key.Key()
+		switch p.Key.Key() {
+		case optRetryAttempts:
+			attempts, ok := p.Value.(*ast.IntegerLiteral)
+			if !ok {
+				return errParseTaskOptionField(field)
+			}
+			val := ast.IntegerFromLiteral(attempts)
+			opts.Retry = &val
+		case optRetryBackoff:
+			backoff, ok := p.Value.(*ast.DurationLiteral)
+			if !ok {
+				return errParseTaskOptionField(field)
+			}
+			opts.RetryBackoff = &Duration{Node: *backoff}
+		case optRetryOn:
+			classes, ok := p.Value.(*ast.ArrayExpression)
+			if !ok {
+				return errParseTaskOptionField(field)
+			}
+			opts.RetryOn = nil
+			for _, e := range classes.Elements {
+				class, ok := e.(*ast.StringLiteral)
+				if !ok {
+					return errParseTaskOptionField(field)
+				}
+				opts.RetryOn = append(opts.RetryOn, ast.StringFromLiteral(class))
+			}
+		default:
+			return errParseTaskOptionField(field)
+		}
+	}
+
+	return nil
+}
+
+// extractHistoryOption extracts the retention of the run history,
+// i.e. history: {count: 100, age: 7d}.
+func extractHistoryOption(opts *Options, objExpr *ast.ObjectExpression) error {
+	historyExpr, err := edit.GetProperty(objExpr, optHistory)
+	if err != nil {
+		return nil
+	}
+
+	historyObj, ok := historyExpr.(*ast.ObjectExpression)
+	if !ok {
+		return errParseTaskOptionField(optHistory)
+	}
+	for _, p := range historyObj.Properties {
+		field := optHistory + "." + p.Key.Key()
+		switch p.Key.Key() {
+		case optHistoryCount:
+			count, ok := p.Value.(*ast.IntegerLiteral)
+			if !ok {
+				return errParseTaskOptionField(field)
+			}
+			val := ast.IntegerFromLiteral(count)
+			opts.HistoryCount = &val
+		case optHistoryAge:
+			age, ok := p.Value.(*ast.DurationLiteral)
+			if !ok {
+				return errParseTaskOptionField(field)
+			}
+			opts.HistoryAge = &Duration{Node: *age}
+		default:
+			return errParseTaskOptionField(field)
+		}
+	}
 
 	return nil
 }
@@ -353,6 +513,13 @@ func (o *Options) Validate() error {
 			errs = append(errs, "every option must be expressible as whole seconds")
 		}
 	}
+	if o.Location != "" {
+		if !cronPresent {
+			errs = append(errs, "location requires cron")
+		} else if _, err := LoadLocation(o.Location); err != nil {
+			errs = append(errs, "location invalid: "+err.Error())
+		}
+	}
 	if o.Offset != nil {
 		offset, err := o.Offset.DurationFrom(now)
 		if err != nil {
@@ -377,6 +544,36 @@ func (o *Options) Validate() error {
 			errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
 		}
 	}
+	if o.RetryBackoff != nil {
+		backoff, err := o.RetryBackoff.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if backoff < 0 {
+			errs = append(errs, "retry backoff must not be negative")
+		}
+	}
+	for _, class := range o.RetryOn {
+		if !isRetryClass(class) {
+			errs = append(errs, fmt.Sprintf("retry on %q invalid, must be one of %s", class, strings.Join(retryClasses, ", ")))
+		}
+	}
+	if o.HistoryCount != nil {
+		if *o.HistoryCount < 1 {
+			errs = append(errs, "history count must be at least 1")
+		} else if *o.HistoryCount > maxHistoryCount {
+			errs = append(errs, fmt.Sprintf("history count exceeded max of %d", maxHistoryCount))
+		}
+	}
+	if o.HistoryAge != nil {
+		age, err := o.HistoryAge.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if age < time.Hour {
+			errs = append(errs, "history age must be at least 1 hour")
+		}
+	}
 
 	if len(errs) == 0 {
 		return nil
@@ -385,8 +582,49 @@ func (o *Options) Validate() error {
 	return fmt.Errorf("invalid options: %s", strings.Join(errs, ", "))
 }
 
+func isRetryClass(class string) bool {
+	for _, c := range retryClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRetry returns whether a run failed with an error of class, after the
+// given number of attempts of its scheduled time, is to be retried.
+func (o *Options) ShouldRetry(attempts int64, class string) bool {
+	if o.Retry == nil || attempts >= *o.Retry {
+		return false
+	}
+	if len(o.RetryOn) == 0 {
+		return isRetryClass(class)
+	}
+	for _, c := range o.RetryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDelay returns the delay before retrying a run after the given number
+// of attempts of its scheduled time. The backoff doubles after each retry.
+// Do not use this if you haven't checked for validity already.
+func (o *Options) RetryDelay(attempts int64) time.Duration {
+	backoff := defaultRetryBackoff
+	if o.RetryBackoff != nil {
+		backoff, _ = o.RetryBackoff.DurationFrom(time.Now()) // we can ignore errors here because we have already checked for validity.
+	}
+	for i := int64(1); i < attempts; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
 // EffectiveCronString returns the effective cron string of the options.
-// If the cron option was specified, it is returned.
+// If the cron option was specified, it is returned, prefixed with its
+// location as in CronWithLocation.
 // If the every option was specified, it is converted into a cron string using "@every".
 // Otherwise, the empty string is returned.
 // The value of the offset option is not considered.
@@ -395,7 +633,7 @@ func (o *Options) Validate() error {
 // Do not use this if you haven't checked for validity already.
 func (o *Options) EffectiveCronString() string {
 	if o.Cron != "" {
-		return o.Cron
+		return CronWithLocation(o.Cron, o.Location)
 	}
 	every, _ := o.Every.DurationFrom(time.Now()) // we can ignore errors here because we have already checked for validity.
 	if every > 0 {
@@ -416,3 +654,40 @@ func parse(lang FluxLanguageService, source string) (*ast.Package, error) {
 	}
 	return lang.Parse(source)
 }
+
+// CronLocationPrefix prefixes a cron string with the location it is evaluated in,
+// i.e. "CRON_TZ=Europe/Berlin 0 6 * * *".
+const CronLocationPrefix = "CRON_TZ="
+
+// CronWithLocation returns cron prefixed with location, or cron itself when
+// location is empty or UTC.
+func CronWithLocation(cron, location string) string {
+	if location == "" || location == "UTC" {
+		return cron
+	}
+	return CronLocationPrefix + location + " " + cron
+}
+
+// SplitCronLocation splits a cron string that may be prefixed with a location
+// into the location and the cron string. The location is empty when there is
+// no prefix.
+func SplitCronLocation(s string) (location, cron string) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, CronLocationPrefix) {
+		return "", s
+	}
+	s = strings.TrimPrefix(s, CronLocationPrefix)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+	return s, ""
+}
+
+// LoadLocation returns the time zone with the IANA name, such as "Europe/Berlin".
+// The local time zone of the host is not accepted, since it is not portable.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
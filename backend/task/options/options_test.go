@@ -101,6 +101,38 @@ func TestFromScriptAST(t *testing.T) {
 		`,
 			exp: options.Options{Name: "name11", Every: *(options.MustParseDuration("1m")), Concurrency: pointer.Int64(1), Retry: pointer.Int64(1), Offset: options.MustParseDuration("1d")},
 		},
+		{script: `option task = {
+			name: "name12",
+			every: 1m,
+			retry: {attempts: 3, backoff: 30s, on: ["query", "execution"]},
+		}
+			from(bucket: "metrics")
+			|> range(start: -1m)
+		`,
+			exp: options.Options{Name: "name12", Every: *(options.MustParseDuration("1m")), Concurrency: pointer.Int64(1), Retry: pointer.Int64(3), RetryBackoff: options.MustParseDuration("30s"), RetryOn: []string{"query", "execution"}},
+		},
+		{script: `option task = {
+			name: "name13",
+			every: 1m,
+			retry: {attempts: 2, on: ["network"]},
+		}
+			from(bucket: "metrics")
+			|> range(start: -1m)
+		`, shouldErr: true},
+		{script: `option task = {
+			name: "name14",
+			every: 1m,
+			retry: {attempts: 2, backoff: "30s"},
+		}
+			from(bucket: "metrics")
+			|> range(start: -1m)
+		`, shouldErr: true},
+		{script: `option task = {name: "name15", cron: "0 6 * * *", location: "Europe/Berlin"}`,
+			exp: options.Options{Name: "name15", Cron: "0 6 * * *", Location: "Europe/Berlin", Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: `option task = {name: "name16", every: 1h, history: {count: 100, age: 7d}}`,
+			exp: options.Options{Name: "name16", Every: *(options.MustParseDuration("1h")), HistoryCount: pointer.Int64(100), HistoryAge: options.MustParseDuration("7d"), Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: `option task = {name: "name17", every: 1h, history: {runs: 100}}`, shouldErr: true},
+		{script: `option task = {name: "name18", every: 1h, history: 100}`, shouldErr: true},
 		{script: "option task = {name:\"test_task_smoke_name\", every:30s} from(bucket:\"test_tasks_smoke_bucket_source\") |> range(start: -1h) |> map(fn: (r) => ({r with _time: r._time, _value:r._value, t : \"quality_rocks\"}))|> to(bucket:\"test_tasks_smoke_bucket_dest\", orgID:\"3e73e749495d37d5\")",
 			exp: options.Options{Name: "test_task_smoke_name", Every: *(options.MustParseDuration("30s")), Retry: pointer.Int64(1), Concurrency: pointer.Int64(1)}, shouldErr: false}, // TODO(docmerlin): remove this once tasks fully supports all flux duration units.
 
@@ -192,6 +224,50 @@ func TestValidate(t *testing.T) {
 		t.Error("expected error for retry too large")
 	}
 
+	*bad = good
+	bad.RetryBackoff = options.MustParseDuration("-1m")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for negative retry backoff")
+	}
+
+	*bad = good
+	bad.RetryOn = []string{"network"}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for unknown retry class")
+	}
+
+	*bad = good
+	bad.Location = "Mars/Olympus_Mons"
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for unknown location")
+	}
+
+	*bad = good
+	bad.Cron = ""
+	bad.Every = *options.MustParseDuration("1m")
+	bad.Location = "Europe/Berlin"
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for location with every")
+	}
+
+	*bad = good
+	bad.HistoryCount = pointer.Int64(0)
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for 0 history count")
+	}
+
+	*bad = good
+	bad.HistoryCount = pointer.Int64(math.MaxInt64)
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for history count too large")
+	}
+
+	*bad = good
+	bad.HistoryAge = options.MustParseDuration("30m")
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for history age too short")
+	}
+
 	notbad := new(options.Options)
 	*notbad = good
 	notbad.Cron = ""
@@ -202,18 +278,67 @@ func TestValidate(t *testing.T) {
 
 }
 
+func TestShouldRetry(t *testing.T) {
+	o := options.Options{Retry: pointer.Int64(3)}
+	if !o.ShouldRetry(1, options.RetryOnQuery) {
+		t.Error("expected retry after first attempt")
+	}
+	if !o.ShouldRetry(2, options.RetryOnResult) {
+		t.Error("expected retry after second attempt")
+	}
+	if o.ShouldRetry(3, options.RetryOnQuery) {
+		t.Error("expected no retry once attempts are exhausted")
+	}
+	if o.ShouldRetry(1, "network") {
+		t.Error("expected no retry for unknown class")
+	}
+
+	o.RetryOn = []string{options.RetryOnExecution}
+	if o.ShouldRetry(1, options.RetryOnQuery) {
+		t.Error("expected no retry for class not in retry on")
+	}
+	if !o.ShouldRetry(1, options.RetryOnExecution) {
+		t.Error("expected retry for class in retry on")
+	}
+
+	if (&options.Options{}).ShouldRetry(0, options.RetryOnQuery) {
+		t.Error("expected no retry without retry option")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	o := options.Options{Retry: pointer.Int64(4)}
+	if d := o.RetryDelay(1); d != 10*time.Second {
+		t.Errorf("expected default backoff of 10s, got %v", d)
+	}
+
+	o.RetryBackoff = options.MustParseDuration("30s")
+	for attempts, exp := range map[int64]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+	} {
+		if d := o.RetryDelay(attempts); d != exp {
+			t.Errorf("expected delay of %v after %d attempts, got %v", exp, attempts, d)
+		}
+	}
+}
+
 func TestEffectiveCronString(t *testing.T) {
 	for _, c := range []struct {
 		c   string
 		e   options.Duration
+		loc string
 		exp string
 	}{
 		{c: "10 * * * *", exp: "10 * * * *"},
 		{e: *(options.MustParseDuration("10s")), exp: "@every 10s"},
 		{exp: ""},
 		{e: *(options.MustParseDuration("10d")), exp: "@every 10d"},
+		{c: "0 6 * * *", loc: "Europe/Berlin", exp: "CRON_TZ=Europe/Berlin 0 6 * * *"},
+		{c: "0 6 * * *", loc: "UTC", exp: "0 6 * * *"},
 	} {
-		o := options.Options{Cron: c.c, Every: c.e}
+		o := options.Options{Cron: c.c, Every: c.e, Location: c.loc}
 		got := o.EffectiveCronString()
 		if got != c.exp {
 			t.Fatalf("exp cron string %q, got %q for %v", c.exp, got, o)
@@ -0,0 +1,189 @@
+package runhistory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+// DefaultPruneInterval is how often the run history of tasks is pruned.
+const DefaultPruneInterval = time.Hour
+
+// runsMeasurement and taskIDTag are where runs are recorded in the system bucket.
+const (
+	runsMeasurement = "runs"
+	taskIDTag       = "taskID"
+)
+
+// Pruner deletes the runs recorded in the system bucket for tasks that are
+// beyond the retention of their run history, as set by the history option.
+type Pruner struct {
+	log      *zap.Logger
+	ts       taskmodel.TaskService
+	bs       influxdb.BucketService
+	ds       influxdb.DeleteService
+	interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPruner returns a Pruner that finds tasks and their runs with ts, and
+// deletes runs from the system bucket of their organization with ds.
+func NewPruner(log *zap.Logger, ts taskmodel.TaskService, bs influxdb.BucketService, ds influxdb.DeleteService, interval time.Duration) *Pruner {
+	if interval <= 0 {
+		interval = DefaultPruneInterval
+	}
+	return &Pruner{
+		log:      log,
+		ts:       ts,
+		bs:       bs,
+		ds:       ds,
+		interval: interval,
+	}
+}
+
+// Open starts pruning the run history of tasks every interval.
+func (p *Pruner) Open(ctx context.Context) error {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Prune(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+					p.log.Error("Failed to prune task run history", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops pruning and waits for a prune in progress.
+func (p *Pruner) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	return nil
+}
+
+// Prune prunes the run history of every task that has a retention.
+// Tasks that fail to be pruned are logged and do not stop the others.
+func (p *Pruner) Prune(ctx context.Context, now time.Time) error {
+	filter := taskmodel.TaskFilter{Limit: taskmodel.TaskMaxPageSize}
+	for {
+		tasks, _, err := p.ts.FindTasks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if t.HistoryCount == 0 && t.HistoryAge == "" {
+				continue
+			}
+			if err := p.PruneTask(ctx, t, now); err != nil {
+				p.log.Error("Failed to prune task run history", zap.String("taskID", t.ID.String()), zap.Error(err))
+			}
+		}
+		if len(tasks) < filter.Limit {
+			return nil
+		}
+		after := tasks[len(tasks)-1].ID
+		filter.After = &after
+	}
+}
+
+// PruneTask deletes the runs of t beyond the retention of its run history.
+func (p *Pruner) PruneTask(ctx context.Context, t *taskmodel.Task, now time.Time) error {
+	runs, _, err := p.ts.FindRuns(ctx, taskmodel.RunFilter{Task: t.ID, Limit: taskmodel.TaskMaxPageSize})
+	if err != nil {
+		return err
+	}
+
+	cutoff, ok, err := Cutoff(t, runs, now)
+	if err != nil || !ok {
+		return err
+	}
+
+	sb, err := p.bs.FindBucketByName(ctx, t.OrganizationID, influxdb.TasksSystemBucketName)
+	if err != nil {
+		return err
+	}
+
+	pred, err := predicate.New(predicate.TagRuleNode{
+		Tag:      influxdb.Tag{Key: taskIDTag, Value: t.ID.String()},
+		Operator: influxdb.Equal,
+	})
+	if err != nil {
+		return err
+	}
+	measurement := &influxql.BinaryExpr{
+		Op:  influxql.EQ,
+		LHS: &influxql.VarRef{Val: "_measurement"},
+		RHS: &influxql.StringLiteral{Val: runsMeasurement},
+	}
+	return p.ds.DeleteBucketRangePredicate(ctx, t.OrganizationID, sb.ID, models.MinNanoTime, cutoff.UnixNano(), pred, measurement)
+}
+
+// Cutoff returns the time the runs of t recorded at or before are beyond the
+// retention of its run history, given its most recent runs, and whether
+// there are runs to delete. Runs are recorded at the time they started.
+func Cutoff(t *taskmodel.Task, runs []*taskmodel.Run, now time.Time) (time.Time, bool, error) {
+	var finished []*taskmodel.Run
+	for _, r := range runs {
+		if !r.FinishedAt.IsZero() {
+			finished = append(finished, r)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].StartedAt.After(finished[j].StartedAt) })
+
+	var cutoff time.Time
+	if t.HistoryAge != "" {
+		var age options.Duration
+		if err := age.Parse(t.HistoryAge); err != nil {
+			return time.Time{}, false, err
+		}
+		d, err := age.DurationFrom(now)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		cutoff = now.Add(-d)
+	}
+	if t.HistoryCount > 0 && int64(len(finished)) > t.HistoryCount {
+		if c := finished[t.HistoryCount].StartedAt; c.After(cutoff) {
+			cutoff = c
+		}
+	}
+	if cutoff.IsZero() {
+		return time.Time{}, false, nil
+	}
+
+	// runs older than the ones found may be beyond the retention when a
+	// whole page was found
+	if len(runs) >= taskmodel.TaskMaxPageSize {
+		return cutoff, true, nil
+	}
+	for _, r := range finished {
+		if !r.StartedAt.After(cutoff) {
+			return cutoff, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
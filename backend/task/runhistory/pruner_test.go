@@ -0,0 +1,104 @@
+package runhistory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/task/runhistory"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"github.com/influxdata/influxql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCutoff(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// hourly runs, the most recent first
+	var runs []*taskmodel.Run
+	for i := 0; i < 5; i++ {
+		runs = append(runs, run("success", now.Add(-time.Duration(i)*time.Hour), time.Second))
+	}
+	// a run in progress is not counted
+	runs = append(runs, run("started", now, 0))
+
+	for _, c := range []struct {
+		name   string
+		task   *taskmodel.Task
+		cutoff time.Time
+		ok     bool
+	}{
+		{name: "no retention", task: &taskmodel.Task{}},
+		{name: "within count", task: &taskmodel.Task{HistoryCount: 5}},
+		{name: "within age", task: &taskmodel.Task{HistoryAge: "1d"}},
+		{name: "count", task: &taskmodel.Task{HistoryCount: 2}, cutoff: now.Add(-2 * time.Hour), ok: true},
+		{name: "age", task: &taskmodel.Task{HistoryAge: "150m"}, cutoff: now.Add(-150 * time.Minute), ok: true},
+		{name: "stricter of count and age", task: &taskmodel.Task{HistoryCount: 3, HistoryAge: "90m"}, cutoff: now.Add(-90 * time.Minute), ok: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cutoff, ok, err := runhistory.Cutoff(c.task, runs, now)
+			require.NoError(t, err)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.cutoff, cutoff)
+		})
+	}
+}
+
+func TestPruner_Prune(t *testing.T) {
+	var (
+		now      = time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+		orgID    = platform.ID(10)
+		bucketID = platform.ID(20)
+	)
+
+	ts := mock.NewTaskService()
+	ts.FindTasksFn = func(ctx context.Context, f taskmodel.TaskFilter) ([]*taskmodel.Task, int, error) {
+		return []*taskmodel.Task{
+			{ID: 1, OrganizationID: orgID, HistoryCount: 1},
+			{ID: 2, OrganizationID: orgID},
+		}, 2, nil
+	}
+	ts.FindRunsFn = func(ctx context.Context, f taskmodel.RunFilter) ([]*taskmodel.Run, int, error) {
+		return []*taskmodel.Run{
+			run("success", now.Add(-time.Hour), time.Second),
+			run("success", now.Add(-2*time.Hour), time.Second),
+		}, 2, nil
+	}
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByNameFn = func(ctx context.Context, id platform.ID, name string) (*influxdb.Bucket, error) {
+		assert.Equal(t, influxdb.TasksSystemBucketName, name)
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID}, nil
+	}
+
+	type deletion struct {
+		orgID, bucketID platform.ID
+		min, max        int64
+		measurement     string
+	}
+	var deletions []deletion
+	ds := mock.NewDeleteService()
+	ds.DeleteBucketRangePredicateF = func(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, measurement influxql.Expr) error {
+		assert.NotNil(t, pred)
+		deletions = append(deletions, deletion{orgID, bucketID, min, max, measurement.String()})
+		return nil
+	}
+
+	p := runhistory.NewPruner(zaptest.NewLogger(t), ts, bs, ds, time.Hour)
+	require.NoError(t, p.Prune(context.Background(), now))
+
+	// only the task with a retention is pruned, up to its second most recent run
+	assert.Equal(t, []deletion{{
+		orgID:       orgID,
+		bucketID:    bucketID,
+		min:         models.MinNanoTime,
+		max:         now.Add(-2 * time.Hour).UnixNano(),
+		measurement: "_measurement = 'runs'",
+	}}, deletions)
+}
@@ -0,0 +1,126 @@
+// Package runhistory provides statistics over the runs of tasks, and keeps
+// the runs recorded for tasks within the retention of their run history.
+package runhistory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+const (
+	// DefaultWindow is how far back the runs of a task are covered by its
+	// statistics when it is not set.
+	DefaultWindow = 7 * 24 * time.Hour
+	// MaxWindow is how far back the runs of a task can be covered by its
+	// statistics, which is as long as runs are kept.
+	MaxWindow = 14 * 24 * time.Hour
+)
+
+// Stats are statistics over the finished runs of a task.
+type Stats struct {
+	TaskID platform.ID
+	// Since is the earliest scheduled time of the runs covered.
+	Since time.Time
+
+	Runs      int
+	Succeeded int
+	Failed    int
+	Canceled  int
+	// SuccessRate is the ratio of succeeded runs to succeeded and failed
+	// runs, or 0 when there are none.
+	SuccessRate float64
+
+	// The durations are from the start to the finish of runs.
+	MeanDuration time.Duration
+	P50Duration  time.Duration
+	P95Duration  time.Duration
+	MaxDuration  time.Duration
+}
+
+// FindStats returns the statistics over the runs of the task with id that
+// were scheduled within window before now. At most a page of the most
+// recent runs is covered.
+func FindStats(ctx context.Context, ts taskmodel.TaskService, id platform.ID, window time.Duration, now time.Time) (*Stats, error) {
+	if window == 0 {
+		window = DefaultWindow
+	}
+	if window < 0 || window > MaxWindow {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("window must be positive and at most %s", MaxWindow),
+		}
+	}
+
+	since := now.Add(-window)
+	runs, _, err := ts.FindRuns(ctx, taskmodel.RunFilter{
+		Task:       id,
+		AfterTime:  since.UTC().Format(time.RFC3339),
+		BeforeTime: now.UTC().Format(time.RFC3339),
+		Limit:      taskmodel.TaskMaxPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewStats(runs)
+	s.TaskID = id
+	s.Since = since
+	return s, nil
+}
+
+// NewStats returns the statistics over the finished runs in runs.
+func NewStats(runs []*taskmodel.Run) *Stats {
+	s := &Stats{}
+
+	var durations []time.Duration
+	for _, r := range runs {
+		switch r.Status {
+		case taskmodel.RunSuccess.String():
+			s.Succeeded++
+		case taskmodel.RunFail.String():
+			s.Failed++
+		case taskmodel.RunCanceled.String():
+			s.Canceled++
+		default:
+			// runs in progress are not covered
+			continue
+		}
+		s.Runs++
+
+		if !r.StartedAt.IsZero() && r.FinishedAt.After(r.StartedAt) {
+			durations = append(durations, r.FinishedAt.Sub(r.StartedAt))
+		}
+	}
+
+	if n := s.Succeeded + s.Failed; n > 0 {
+		s.SuccessRate = float64(s.Succeeded) / float64(n)
+	}
+
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		s.MeanDuration = total / time.Duration(len(durations))
+		s.P50Duration = percentile(durations, 50)
+		s.P95Duration = percentile(durations, 95)
+		s.MaxDuration = durations[len(durations)-1]
+	}
+	return s
+}
+
+// percentile returns the p-th percentile of sorted by the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
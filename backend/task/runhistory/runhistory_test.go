@@ -0,0 +1,88 @@
+package runhistory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/runhistory"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(status string, started time.Time, d time.Duration) *taskmodel.Run {
+	r := &taskmodel.Run{Status: status, ScheduledFor: started, StartedAt: started}
+	if d > 0 {
+		r.FinishedAt = started.Add(d)
+	}
+	return r
+}
+
+func TestNewStats(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	var runs []*taskmodel.Run
+	for i := 1; i <= 18; i++ {
+		runs = append(runs, run("success", now, time.Duration(i)*time.Second))
+	}
+	runs = append(runs,
+		run("failed", now, 19*time.Second),
+		run("canceled", now, 20*time.Second),
+		// runs in progress are not covered
+		run("started", now, 0),
+	)
+
+	s := runhistory.NewStats(runs)
+	assert.Equal(t, &runhistory.Stats{
+		Runs:         20,
+		Succeeded:    18,
+		Failed:       1,
+		Canceled:     1,
+		SuccessRate:  18.0 / 19.0,
+		MeanDuration: 10500 * time.Millisecond,
+		P50Duration:  10 * time.Second,
+		P95Duration:  19 * time.Second,
+		MaxDuration:  20 * time.Second,
+	}, s)
+
+	assert.Equal(t, &runhistory.Stats{}, runhistory.NewStats(nil))
+}
+
+func TestFindStats(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	var filter taskmodel.RunFilter
+	ts := mock.NewTaskService()
+	ts.FindRunsFn = func(ctx context.Context, f taskmodel.RunFilter) ([]*taskmodel.Run, int, error) {
+		filter = f
+		return []*taskmodel.Run{
+			run("success", now.Add(-time.Hour), time.Second),
+			run("failed", now.Add(-2*time.Hour), 3*time.Second),
+		}, 2, nil
+	}
+
+	s, err := runhistory.FindStats(context.Background(), ts, 1, 24*time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, taskmodel.RunFilter{
+		Task:       1,
+		AfterTime:  "2021-03-09T12:00:00Z",
+		BeforeTime: "2021-03-10T12:00:00Z",
+		Limit:      taskmodel.TaskMaxPageSize,
+	}, filter)
+	assert.Equal(t, platform.ID(1), s.TaskID)
+	assert.Equal(t, now.Add(-24*time.Hour), s.Since)
+	assert.Equal(t, 2, s.Runs)
+	assert.Equal(t, 0.5, s.SuccessRate)
+	assert.Equal(t, 2*time.Second, s.MeanDuration)
+
+	_, err = runhistory.FindStats(context.Background(), ts, 1, runhistory.MaxWindow+time.Hour, now)
+	itesting.ErrorsEqual(t, err, &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "window must be positive and at most 336h0m0s",
+	})
+}
@@ -21,6 +21,7 @@ import (
 	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	influxdbmock "github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/options"
@@ -108,6 +109,11 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory, testCategory ...s
 					testTaskType(t, sys)
 				})
 
+				t.Run("Task Dependencies", func(t *testing.T) {
+					t.Parallel()
+					testTaskDependencies(t, sys)
+				})
+
 			})
 		case "analytical":
 			t.Run("AnalyticalTaskService", func(t *testing.T) {
@@ -1195,6 +1201,45 @@ func testTaskRuns(t *testing.T, sys *System) {
 		if diff := cmp.Diff(logs, exp); diff != "" {
 			t.Fatalf("unexpected log: -got/+want: %s", diff)
 		}
+
+		// Ensure logs can be searched by their message.
+		logs, _, err = sys.TaskService.FindLogs(sys.Ctx, taskmodel.LogFilter{
+			Task:  task.ID,
+			Match: "ENTRY 2",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(logs, []*taskmodel.Log{expLine2}); diff != "" {
+			t.Fatalf("unexpected log: -got/+want: %s", diff)
+		}
+
+		// Ensure logs can be searched by the error class of their run.
+		if err := sys.TaskControlService.UpdateRunErrorClass(sys.Ctx, task.ID, rc1.ID, "query"); err != nil {
+			t.Fatal(err)
+		}
+		logs, _, err = sys.TaskService.FindLogs(sys.Ctx, taskmodel.LogFilter{
+			Task:       task.ID,
+			ErrorClass: "query",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(logs, []*taskmodel.Log{expLine1}); diff != "" {
+			t.Fatalf("unexpected log: -got/+want: %s", diff)
+		}
+
+		// Ensure logs can be searched by the time they were logged.
+		logs, _, err = sys.TaskService.FindLogs(sys.Ctx, taskmodel.LogFilter{
+			Task:      task.ID,
+			AfterTime: log2Time.Add(time.Minute).Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 0 {
+			t.Fatalf("expected no logs after the last one, got %v", logs)
+		}
 	})
 }
 
@@ -1840,6 +1885,59 @@ from(bucket: "b")
 `
 )
 
+func testTaskDependencies(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	create := func(i int, dependsOn ...platform.ID) *taskmodel.Task {
+		t.Helper()
+
+		tsk, err := sys.TaskService.CreateTask(authorizedCtx, taskmodel.TaskCreate{
+			OrganizationID: cr.OrgID,
+			OwnerID:        cr.UserID,
+			Flux:           fmt.Sprintf(scriptFmt, i),
+			DependsOn:      dependsOn,
+		})
+		require.NoError(t, err)
+		return tsk
+	}
+
+	// raw -> rollup -> export
+	raw := create(0)
+	rollup := create(1, raw.ID)
+	export := create(2, rollup.ID, raw.ID)
+	assert.Equal(t, []platform.ID{rollup.ID, raw.ID}, export.DependsOn)
+
+	found, err := sys.TaskService.FindTaskByID(sys.Ctx, export.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []platform.ID{rollup.ID, raw.ID}, found.DependsOn)
+
+	// dependencies must exist
+	_, err = sys.TaskService.CreateTask(authorizedCtx, taskmodel.TaskCreate{
+		OrganizationID: cr.OrgID,
+		OwnerID:        cr.UserID,
+		Flux:           fmt.Sprintf(scriptFmt, 3),
+		DependsOn:      []platform.ID{platform.ID(1)},
+	})
+	assert.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+	// dependencies must not form a cycle, directly or transitively
+	for _, deps := range [][]platform.ID{{raw.ID}, {export.ID}} {
+		_, err = sys.TaskService.UpdateTask(authorizedCtx, raw.ID, taskmodel.TaskUpdate{DependsOn: &deps})
+		assert.Equal(t, taskmodel.ErrTaskDependencyCycle, err)
+	}
+
+	// tasks that other tasks depend on cannot be deleted
+	assert.Equal(t, taskmodel.ErrTaskHasDependents, sys.TaskService.DeleteTask(authorizedCtx, rollup.ID))
+
+	// removing the dependencies of a task releases its upstream tasks
+	none := []platform.ID{}
+	export, err = sys.TaskService.UpdateTask(authorizedCtx, export.ID, taskmodel.TaskUpdate{DependsOn: &none})
+	require.NoError(t, err)
+	assert.Nil(t, export.DependsOn)
+	require.NoError(t, sys.TaskService.DeleteTask(authorizedCtx, rollup.ID))
+}
+
 func testTaskType(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
@@ -0,0 +1,80 @@
+package taskmodel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxql"
+)
+
+const (
+	// TaskLanguageFlux is the language of tasks that execute their Flux script.
+	// Tasks without a language are Flux tasks.
+	TaskLanguageFlux = "flux"
+
+	// TaskLanguageInfluxQL is the language of tasks that execute an InfluxQL
+	// query against a database and retention policy mapped to a bucket.
+	// Their Flux script only sets the task options.
+	TaskLanguageInfluxQL = "influxql"
+
+	// InfluxQLNowParam is the bound parameter set to the scheduled time of
+	// the run in the query of InfluxQL tasks, as in `WHERE time >= $now - 1h`.
+	InfluxQLNowParam = "now"
+)
+
+// IsInfluxQL returns whether the task executes an InfluxQL query.
+func (t *Task) IsInfluxQL() bool {
+	return t.Language == TaskLanguageInfluxQL
+}
+
+// validateLanguage returns an error if language is not a task language.
+func validateLanguage(language string) error {
+	switch language {
+	case "", TaskLanguageFlux, TaskLanguageInfluxQL:
+		return nil
+	}
+	return fmt.Errorf("invalid task language: %q", language)
+}
+
+// ValidateInfluxQLQuery returns an error if q is not made of InfluxQL SELECT
+// statements, with $now as the only bound parameter.
+func ValidateInfluxQLQuery(q string) error {
+	p := influxql.NewParser(strings.NewReader(q))
+	p.SetParams(map[string]interface{}{
+		InfluxQLNowParam: time.Unix(0, 0).UTC().Format(time.RFC3339Nano),
+	})
+	query, err := p.ParseQuery()
+	if err != nil {
+		return fmt.Errorf("invalid influxql query: %s", err)
+	}
+	if len(query.Statements) == 0 {
+		return errors.New("missing query")
+	}
+	for _, stmt := range query.Statements {
+		if _, ok := stmt.(*influxql.SelectStatement); !ok {
+			return fmt.Errorf("invalid influxql query: only SELECT statements are supported, found %q", stmt.String())
+		}
+	}
+	return nil
+}
+
+// ValidateOptionsFlux returns an error if the Flux script f of an InfluxQL
+// task has statements other than options.
+func ValidateOptionsFlux(parser fluxlang.FluxLanguageService, f string) error {
+	pkg, err := safeParseSource(parser, f)
+	if err != nil {
+		return err
+	}
+	for _, file := range pkg.Files {
+		for _, stmt := range file.Body {
+			if _, ok := stmt.(*ast.OptionStatement); !ok {
+				return errors.New("the flux of an influxql task can only set options")
+			}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux/ast"
@@ -45,9 +46,17 @@ type Task struct {
 	Description     string                 `json:"description,omitempty"`
 	Status          string                 `json:"status"`
 	Flux            string                 `json:"flux"`
+	Language        string                 `json:"language,omitempty"`
+	Query           string                 `json:"query,omitempty"`
+	Database        string                 `json:"database,omitempty"`
+	RetentionPolicy string                 `json:"retentionPolicy,omitempty"`
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
+	Location        string                 `json:"location,omitempty"`
 	Offset          time.Duration          `json:"offset,omitempty"`
+	DependsOn       []platform.ID          `json:"dependsOn,omitempty"`
+	HistoryCount    int64                  `json:"historyCount,omitempty"`
+	HistoryAge      string                 `json:"historyAge,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	LatestSuccess   time.Time              `json:"latestSuccess,omitempty"`
@@ -60,13 +69,14 @@ type Task struct {
 }
 
 // EffectiveCron returns the effective cron string of the options.
-// If the cron option was specified, it is returned.
+// If the cron option was specified, it is returned, prefixed with the
+// location option as in options.CronWithLocation.
 // If the every option was specified, it is converted into a cron string using "@every".
 // Otherwise, the empty string is returned.
 // The value of the offset option is not considered.
 func (t *Task) EffectiveCron() string {
 	if t.Cron != "" {
-		return t.Cron
+		return options.CronWithLocation(t.Cron, t.Location)
 	}
 	if t.Every != "" {
 		return "@every " + t.Every
@@ -86,6 +96,9 @@ type Run struct {
 	FinishedAt   time.Time   `json:"finishedAt,omitempty"`  // FinishedAt is the time the executor finishes running the task
 	RequestedAt  time.Time   `json:"requestedAt,omitempty"` // RequestedAt is the time the coordinator told the scheduler to schedule the task
 	Log          []Log       `json:"log,omitempty"`
+	RetryOf      platform.ID `json:"retryOf,omitempty"`    // RetryOf is the run this run is a retry of
+	Attempt      int64       `json:"attempt,omitempty"`    // Attempt is the attempt of the scheduled time of a retry, the run it retries being attempt 1
+	ErrorClass   string      `json:"errorClass,omitempty"` // ErrorClass is the class of the error of a failed run, as in the retry option
 
 	TraceID   string `json:"traceID"`   // TraceID preserves the trace id
 	IsSampled bool   `json:"isSampled"` // IsSampled preserves whether this run was sampled
@@ -151,6 +164,18 @@ type TaskCreate struct {
 	Organization   string                 `json:"org,omitempty"`
 	OwnerID        platform.ID            `json:"-"`
 	Metadata       map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+
+	// DependsOn are the upstream tasks that must succeed for a scheduled time
+	// of the task before it runs for that time.
+	DependsOn []platform.ID `json:"dependsOn,omitempty"`
+
+	// Language is the language of the task, Flux when it is not set. InfluxQL
+	// tasks execute Query against Database and RetentionPolicy, and only set
+	// their options in Flux.
+	Language        string `json:"language,omitempty"`
+	Query           string `json:"query,omitempty"`
+	Database        string `json:"database,omitempty"`
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
@@ -162,7 +187,22 @@ func (t TaskCreate) Validate() error {
 	case t.Status != "" && t.Status != TaskStatusActive && t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", t.Status)
 	}
-	return nil
+	if err := validateLanguage(t.Language); err != nil {
+		return err
+	}
+	if t.Language != TaskLanguageInfluxQL {
+		if t.Query != "" || t.Database != "" || t.RetentionPolicy != "" {
+			return errors.New("query, database and retention policy are only supported by influxql tasks")
+		}
+		return nil
+	}
+	switch {
+	case t.Query == "":
+		return errors.New("missing query")
+	case t.Database == "":
+		return errors.New("missing database")
+	}
+	return ValidateInfluxQLQuery(t.Query)
 }
 
 // TaskUpdate represents updates to a task. Options updates override any options set in the Flux field.
@@ -171,6 +211,14 @@ type TaskUpdate struct {
 	Status      *string `json:"status,omitempty"`
 	Description *string `json:"description,omitempty"`
 
+	// DependsOn replaces the upstream tasks of the task; an empty list removes them.
+	DependsOn *[]platform.ID `json:"dependsOn,omitempty"`
+
+	// Query, Database and RetentionPolicy update InfluxQL tasks.
+	Query           *string `json:"query,omitempty"`
+	Database        *string `json:"database,omitempty"`
+	RetentionPolicy *string `json:"retentionPolicy,omitempty"`
+
 	// LatestCompleted us to set latest completed on startup to skip task catchup
 	LatestCompleted *time.Time             `json:"-"`
 	LatestScheduled *time.Time             `json:"-"`
@@ -195,6 +243,9 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 		// Cron is a cron style time schedule that can be used in place of Every.
 		Cron string `json:"cron,omitempty"`
 
+		// Location is the time zone Cron is evaluated in.
+		Location string `json:"location,omitempty"`
+
 		// Every represents a fixed period to repeat execution.
 		// It gets marshalled from a string duration, i.e.: "10s" is 10 seconds
 		Every options.Duration `json:"every,omitempty"`
@@ -206,6 +257,12 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		DependsOn *[]platform.ID `json:"dependsOn,omitempty"`
+
+		Query           *string `json:"query,omitempty"`
+		Database        *string `json:"database,omitempty"`
+		RetentionPolicy *string `json:"retentionPolicy,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -214,6 +271,7 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	t.Options.Name = jo.Name
 	t.Description = jo.Description
 	t.Options.Cron = jo.Cron
+	t.Options.Location = jo.Location
 	t.Options.Every = jo.Every
 	if jo.Offset != nil {
 		offset := *jo.Offset
@@ -221,6 +279,10 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	}
 	t.Options.Concurrency = jo.Concurrency
 	t.Options.Retry = jo.Retry
+	t.DependsOn = jo.DependsOn
+	t.Query = jo.Query
+	t.Database = jo.Database
+	t.RetentionPolicy = jo.RetentionPolicy
 	t.Flux = jo.Flux
 	t.Status = jo.Status
 	return nil
@@ -236,6 +298,9 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 		// Cron is a cron style time schedule that can be used in place of Every.
 		Cron string `json:"cron,omitempty"`
 
+		// Location is the time zone Cron is evaluated in.
+		Location string `json:"location,omitempty"`
+
 		// Every represents a fixed period to repeat execution.
 		Every options.Duration `json:"every,omitempty"`
 
@@ -245,9 +310,16 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		DependsOn *[]platform.ID `json:"dependsOn,omitempty"`
+
+		Query           *string `json:"query,omitempty"`
+		Database        *string `json:"database,omitempty"`
+		RetentionPolicy *string `json:"retentionPolicy,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
+	jo.Location = t.Options.Location
 	jo.Every = t.Options.Every
 	jo.Description = t.Description
 	if t.Options.Offset != nil {
@@ -256,6 +328,10 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 	}
 	jo.Concurrency = t.Options.Concurrency
 	jo.Retry = t.Options.Retry
+	jo.DependsOn = t.DependsOn
+	jo.Query = t.Query
+	jo.Database = t.Database
+	jo.RetentionPolicy = t.RetentionPolicy
 	jo.Flux = t.Flux
 	jo.Status = t.Status
 	return json.Marshal(jo)
@@ -273,11 +349,17 @@ func (t *TaskUpdate) Validate() error {
 		if _, err := time.ParseDuration(t.Options.Offset.String()); err != nil {
 			return fmt.Errorf("offset: %s, %s is invalid, the largest unit supported is h", t.Options.Offset.String(), err)
 		}
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero():
+	case t.Flux == nil && t.Status == nil && t.DependsOn == nil && t.Query == nil && t.Database == nil && t.RetentionPolicy == nil && t.Options.IsZero():
 		return errors.New("cannot update task without content")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
 	}
+	if t.Database != nil && *t.Database == "" {
+		return errors.New("missing database")
+	}
+	if t.Query != nil {
+		return ValidateInfluxQLQuery(*t.Query)
+	}
 	return nil
 }
 
@@ -334,6 +416,12 @@ func (t *TaskUpdate) updateFlux(parser fluxlang.FluxLanguageService, oldFlux str
 	if t.Options.Cron != "" {
 		op["cron"] = &ast.StringLiteral{Value: t.Options.Cron}
 	}
+	if t.Options.Location != "" {
+		op["location"] = &ast.StringLiteral{Value: t.Options.Location}
+	} else if !t.Options.Every.IsZero() {
+		// the location only applies to cron
+		toDelete["location"] = struct{}{}
+	}
 	if t.Options.Offset != nil {
 		if !t.Options.Offset.IsZero() {
 			op["offset"] = &t.Options.Offset.Node
@@ -351,12 +439,18 @@ func (t *TaskUpdate) updateFlux(parser fluxlang.FluxLanguageService, oldFlux str
 			if !ok {
 				return nil, fmt.Errorf("value is is %s, not an object expression", a.Init.Type())
 			}
+			// remove the keys to delete
+			props := obj.Properties[:0]
+			for _, p := range obj.Properties {
+				if _, ok := toDelete[p.Key.Key()]; !ok {
+					props = append(props, p)
+				}
+			}
+			obj.Properties = props
+
 			// modify in the keys and values that already are in the ast
-			for i, p := range obj.Properties {
+			for _, p := range obj.Properties {
 				k := p.Key.Key()
-				if _, ok := toDelete[k]; ok {
-					obj.Properties = append(obj.Properties[:i], obj.Properties[i+1:]...)
-				}
 				switch k {
 				case "name":
 					if name, ok := op["name"]; ok && t.Options.Name != "" {
@@ -377,6 +471,11 @@ func (t *TaskUpdate) updateFlux(parser fluxlang.FluxLanguageService, oldFlux str
 						p.Value = cron
 						p.Key = &ast.Identifier{Name: "cron"}
 					}
+				case "location":
+					if location, ok := op["location"]; ok {
+						delete(op, "location")
+						p.Value = location
+					}
 				case "cron":
 					if cron, ok := op["cron"]; ok && t.Options.Cron != "" {
 						delete(op, "cron")
@@ -473,6 +572,74 @@ type LogFilter struct {
 
 	// The optional Run ID limits logs to a single run.
 	Run *platform.ID
+
+	// The optional Status limits logs to the runs with the status.
+	Status string
+
+	// The optional ErrorClass limits logs to the failed runs whose error is
+	// of the class.
+	ErrorClass string
+
+	// The optional AfterTime and BeforeTime, in RFC3339, limit logs to the
+	// ones logged after and before them.
+	AfterTime  string
+	BeforeTime string
+
+	// The optional Match limits logs to the messages containing it, ignoring case.
+	Match string
+}
+
+// IsSearch reports whether the filter limits logs by more than their task and run.
+func (f LogFilter) IsSearch() bool {
+	return f.Status != "" || f.ErrorClass != "" || f.AfterTime != "" || f.BeforeTime != "" || f.Match != ""
+}
+
+// Search returns the logs of runs that match the filter, in the order of runs.
+// The task and run of the filter are not considered.
+func (f LogFilter) Search(runs []*Run) ([]*Log, error) {
+	var after, before time.Time
+	if f.AfterTime != "" {
+		t, err := time.Parse(time.RFC3339, f.AfterTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing after time: %s", err.Error())
+		}
+		after = t
+	}
+	if f.BeforeTime != "" {
+		t, err := time.Parse(time.RFC3339, f.BeforeTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing before time: %s", err.Error())
+		}
+		before = t
+	}
+	match := strings.ToLower(f.Match)
+
+	var logs []*Log
+	for _, r := range runs {
+		if f.Status != "" && r.Status != f.Status {
+			continue
+		}
+		if f.ErrorClass != "" && r.ErrorClass != f.ErrorClass {
+			continue
+		}
+		for i := range r.Log {
+			l := &r.Log[i]
+			if !after.IsZero() || !before.IsZero() {
+				t, err := time.Parse(time.RFC3339Nano, l.Time)
+				if err != nil {
+					continue
+				}
+				if (!after.IsZero() && !t.After(after)) || (!before.IsZero() && !t.Before(before)) {
+					continue
+				}
+			}
+			if match != "" && !strings.Contains(strings.ToLower(l.Message), match) {
+				continue
+			}
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
 }
 
 type TaskStatus string
@@ -3,6 +3,7 @@ package taskmodel
 import (
 	"fmt"
 
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 )
 
@@ -90,8 +91,28 @@ var (
 		Code: errors.EInvalid,
 		Msg:  "cannot create task with invalid ownerID",
 	}
+
+	// ErrTaskDependencyCycle is returned when the dependencies of a task would make it depend on itself.
+	ErrTaskDependencyCycle = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "task dependencies cannot form a cycle",
+	}
+
+	// ErrTaskHasDependents is returned when deleting a task that other tasks depend on.
+	ErrTaskHasDependents = &errors.Error{
+		Code: errors.EConflict,
+		Msg:  "task has dependent tasks",
+	}
 )
 
+// ErrTaskDependencyNotFound is returned when a task depends on a task that is not in its organization.
+func ErrTaskDependencyNotFound(id platform.ID) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  fmt.Sprintf("task dependency %s not found in the organization of the task", id),
+	}
+}
+
 // ErrFluxParseError is returned when an error is thrown by Flux.Parse in the task executor
 func ErrFluxParseError(err error) *errors.Error {
 	return &errors.Error{
@@ -160,6 +181,26 @@ func ErrTaskOptionParse(err error) *errors.Error {
 	}
 }
 
+// ErrInvalidLogFilter is returned when the times of a log filter cannot be parsed.
+func ErrInvalidLogFilter(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "invalid log filter",
+		Op:   "taskLogs",
+		Err:  err,
+	}
+}
+
+// ErrInvalidInfluxQLTask is returned when an InfluxQL task cannot be created or updated.
+func ErrInvalidInfluxQLTask(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "invalid influxql task",
+		Op:   "taskInfluxQL",
+		Err:  err,
+	}
+}
+
 func ErrRunExecutionError(err error) *errors.Error {
 	return &errors.Error{
 		Code: errors.EInternal,
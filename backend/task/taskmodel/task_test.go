@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	_ "github.com/influxdata/influxdb/v2/fluxinit/static"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/task/options"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
@@ -166,3 +167,91 @@ func TestParseRequestStillQueuedError(t *testing.T) {
 		t.Fatalf("%q should have parsed to %v, but got %v", validMsg, e, err)
 	}
 }
+
+func TestLogFilterSearch(t *testing.T) {
+	runs := []*taskmodel.Run{
+		{ID: 1, Status: "success", Log: []taskmodel.Log{
+			{RunID: 1, Time: "2021-03-10T12:00:01Z", Message: "Started task from script"},
+			{RunID: 1, Time: "2021-03-10T12:00:02Z", Message: "Completed(success)"},
+		}},
+		{ID: 2, Status: "failed", ErrorClass: "query", Log: []taskmodel.Log{
+			{RunID: 2, Time: "2021-03-10T13:00:01Z", Message: "Started task from script"},
+			{RunID: 2, Time: "2021-03-10T13:00:02Z", Message: "unexpected error from queryd: timeout"},
+		}},
+	}
+
+	for _, c := range []struct {
+		name   string
+		filter taskmodel.LogFilter
+		exp    []string
+	}{
+		{name: "all", exp: []string{
+			"Started task from script", "Completed(success)",
+			"Started task from script", "unexpected error from queryd: timeout",
+		}},
+		{name: "status", filter: taskmodel.LogFilter{Status: "success"}, exp: []string{"Started task from script", "Completed(success)"}},
+		{name: "error class", filter: taskmodel.LogFilter{ErrorClass: "query"}, exp: []string{"Started task from script", "unexpected error from queryd: timeout"}},
+		{name: "match ignores case", filter: taskmodel.LogFilter{Match: "TIMEOUT"}, exp: []string{"unexpected error from queryd: timeout"}},
+		{name: "time range", filter: taskmodel.LogFilter{AfterTime: "2021-03-10T12:00:01Z", BeforeTime: "2021-03-10T13:00:02Z"}, exp: []string{"Completed(success)", "Started task from script"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			logs, err := c.filter.Search(runs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, l := range logs {
+				got = append(got, l.Message)
+			}
+			if !cmp.Equal(got, c.exp) {
+				t.Fatal(cmp.Diff(got, c.exp))
+			}
+		})
+	}
+
+	if _, err := (taskmodel.LogFilter{AfterTime: "yesterday"}).Search(runs); err == nil {
+		t.Fatal("expected error for invalid after time")
+	}
+}
+
+func TestTaskCreateValidateInfluxQL(t *testing.T) {
+	orgID := platform.ID(1)
+	flux := `option task = {name: "downsample", every: 1h}`
+	query := `SELECT mean(value) INTO db0.autogen.cpu_1h FROM cpu WHERE time >= $now - 1h AND time < $now GROUP BY time(1h), *`
+
+	for _, c := range []struct {
+		name  string
+		tc    taskmodel.TaskCreate
+		valid bool
+	}{
+		{name: "flux", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux}, valid: true},
+		{name: "influxql", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Query: query, Database: "db0"}, valid: true},
+		{name: "invalid language", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: "sql"}},
+		{name: "query in flux task", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Query: query, Database: "db0"}},
+		{name: "missing query", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Database: "db0"}},
+		{name: "missing database", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Query: query}},
+		{name: "invalid query", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Query: "SELEC value FROM cpu", Database: "db0"}},
+		{name: "unknown parameter", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Query: "SELECT value FROM cpu WHERE time > $start", Database: "db0"}},
+		{name: "not a select", tc: taskmodel.TaskCreate{OrganizationID: orgID, Flux: flux, Language: taskmodel.TaskLanguageInfluxQL, Query: "DROP MEASUREMENT cpu", Database: "db0"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.tc.Validate()
+			if c.valid && err != nil {
+				t.Fatalf("expected task create to be valid: %s", err)
+			}
+			if !c.valid && err == nil {
+				t.Fatal("expected task create to be invalid")
+			}
+		})
+	}
+}
+
+func TestValidateOptionsFlux(t *testing.T) {
+	if err := taskmodel.ValidateOptionsFlux(fluxlang.DefaultService, `option task = {name: "a", every: 1h}`); err != nil {
+		t.Fatalf("expected options only flux to be valid: %s", err)
+	}
+	if err := taskmodel.ValidateOptionsFlux(fluxlang.DefaultService, `option task = {name: "a", every: 1h}
+from(bucket: "b") |> range(start: -1h)`); err == nil {
+		t.Fatal("expected flux with a query to be invalid")
+	}
+}
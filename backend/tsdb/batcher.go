@@ -0,0 +1,145 @@
+package tsdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// PointBatcher accepts Points and will emit a batch of those points when either
+// a) the batch reaches a certain size, or b) a certain time passes.
+//
+// The input channel is buffered to hold a number of pending batches. Once it is
+// full, sends on In block until batches have been consumed from Out, applying
+// back-pressure to the producer.
+type PointBatcher struct {
+	size     int
+	duration time.Duration
+
+	stop  chan struct{}
+	in    chan models.Point
+	out   chan []models.Point
+	flush chan struct{}
+
+	wg *sync.WaitGroup
+}
+
+// NewPointBatcher returns a new PointBatcher. sz is the batching size,
+// bp is the maximum number of batches that may be pending. d is the time
+// after which a batch will be emitted after the first point is received
+// for the batch, regardless of its size.
+func NewPointBatcher(sz int, bp int, d time.Duration) *PointBatcher {
+	return &PointBatcher{
+		size:     sz,
+		duration: d,
+		stop:     make(chan struct{}),
+		in:       make(chan models.Point, bp*sz),
+		out:      make(chan []models.Point),
+		flush:    make(chan struct{}),
+	}
+}
+
+// Start starts the batching process. Returns the in and out channels for points
+// and point-batches respectively.
+func (b *PointBatcher) Start() {
+	// Already running?
+	if b.wg != nil {
+		return
+	}
+
+	var timer *time.Timer
+	var batch []models.Point
+	var timerCh <-chan time.Time
+
+	emit := func() {
+		b.out <- batch
+		batch = nil
+	}
+
+	b.wg = &sync.WaitGroup{}
+	b.wg.Add(1)
+
+	go func() {
+		defer b.wg.Done()
+		for {
+			select {
+			case <-b.stop:
+				// Batch the points still queued on the input channel, so
+				// that no point sent before Stop is dropped.
+				for len(b.in) > 0 {
+					if batch == nil {
+						batch = make([]models.Point, 0, b.size)
+					}
+					batch = append(batch, <-b.in)
+					if len(batch) >= b.size {
+						emit()
+					}
+				}
+				if len(batch) > 0 {
+					emit()
+				}
+				return
+			case p := <-b.in:
+				if batch == nil {
+					batch = make([]models.Point, 0, b.size)
+					if b.duration > 0 {
+						timer = time.NewTimer(b.duration)
+						timerCh = timer.C
+					}
+				}
+
+				batch = append(batch, p)
+				if len(batch) >= b.size { // 0 means send immediately.
+					emit()
+					timerCh = nil
+				}
+
+			case <-b.flush:
+				if len(batch) > 0 {
+					emit()
+					timerCh = nil
+				}
+
+			case <-timerCh:
+				if len(batch) > 0 {
+					emit()
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the batching process. Points already sent on In are emitted in
+// final batches, so Out must still be read until Stop returns. Stop waits for
+// the batching routine to stop before returning.
+func (b *PointBatcher) Stop() {
+	// If not running, nothing to stop.
+	if b.wg == nil {
+		return
+	}
+
+	close(b.stop)
+	b.wg.Wait()
+}
+
+// In returns the channel to which points should be written.
+func (b *PointBatcher) In() chan<- models.Point {
+	return b.in
+}
+
+// Out returns the channel from which batches should be read.
+func (b *PointBatcher) Out() <-chan []models.Point {
+	return b.out
+}
+
+// Flush instructs the batcher to emit any pending points in a batch, regardless of batch size.
+// If there are no pending points, no batch is emitted.
+func (b *PointBatcher) Flush() {
+	b.flush <- struct{}{}
+}
+
+// Pending returns the number of points waiting to be batched.
+func (b *PointBatcher) Pending() int {
+	return len(b.in)
+}
@@ -0,0 +1,148 @@
+package tsdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// TestBatch_Size ensures that a batcher generates a batch when the size threshold is reached.
+func TestBatch_Size(t *testing.T) {
+	batchSize := 5
+	batcher := tsdb.NewPointBatcher(batchSize, 0, time.Hour)
+	if batcher == nil {
+		t.Fatal("failed to create batcher for size test")
+	}
+
+	batcher.Start()
+	defer batcher.Stop()
+
+	var p models.Point
+	go func() {
+		for i := 0; i < batchSize; i++ {
+			batcher.In() <- p
+		}
+	}()
+	batch := <-batcher.Out()
+	if len(batch) != batchSize {
+		t.Errorf("received batch has incorrect length exp %d, got %d", batchSize, len(batch))
+	}
+}
+
+// TestBatch_Timeout ensures that a batcher generates a batch when the timeout triggers.
+func TestBatch_Timeout(t *testing.T) {
+	batchSize := 5
+	batcher := tsdb.NewPointBatcher(batchSize+1, 0, 100*time.Millisecond)
+	if batcher == nil {
+		t.Fatal("failed to create batcher for timeout test")
+	}
+
+	batcher.Start()
+	defer batcher.Stop()
+
+	var p models.Point
+	go func() {
+		for i := 0; i < batchSize; i++ {
+			batcher.In() <- p
+		}
+	}()
+	batch := <-batcher.Out()
+	if len(batch) != batchSize {
+		t.Errorf("received batch has incorrect length exp %d, got %d", batchSize, len(batch))
+	}
+}
+
+// TestBatch_Flush ensures that a batcher generates a batch when flushed
+func TestBatch_Flush(t *testing.T) {
+	batchSize := 2
+	batcher := tsdb.NewPointBatcher(batchSize, 0, time.Hour)
+	if batcher == nil {
+		t.Fatal("failed to create batcher for flush test")
+	}
+
+	batcher.Start()
+	defer batcher.Stop()
+
+	var p models.Point
+	go func() {
+		batcher.In() <- p
+		batcher.Flush()
+	}()
+	batch := <-batcher.Out()
+	if len(batch) != 1 {
+		t.Errorf("received batch has incorrect length exp %d, got %d", 1, len(batch))
+	}
+}
+
+// TestBatch_MultipleBatches ensures that a batcher correctly processes multiple batches.
+func TestBatch_MultipleBatches(t *testing.T) {
+	batchSize := 2
+	batcher := tsdb.NewPointBatcher(batchSize, 1, 100*time.Millisecond)
+	if batcher == nil {
+		t.Fatal("failed to create batcher for size test")
+	}
+
+	batcher.Start()
+	defer batcher.Stop()
+
+	var p models.Point
+	var b []models.Point
+
+	batcher.In() <- p
+	batcher.In() <- p
+	batcher.In() <- p // Should be flushed via timeout.
+
+	b = <-batcher.Out() // Batch threshold reached.
+	if len(b) != batchSize {
+		t.Errorf("received batch (size) has incorrect length exp %d, got %d", batchSize, len(b))
+	}
+
+	b = <-batcher.Out() // Timeout.
+	if len(b) != 1 {
+		t.Errorf("received batch (timeout) has incorrect length exp %d, got %d", 1, len(b))
+	}
+}
+
+// TestBatch_StopQueued ensures that points still queued when a batcher is
+// stopped are emitted.
+func TestBatch_StopQueued(t *testing.T) {
+	batchSize := 2
+	batcher := tsdb.NewPointBatcher(batchSize, 2, time.Hour)
+	if batcher == nil {
+		t.Fatal("failed to create batcher for stop test")
+	}
+
+	batcher.Start()
+
+	// The first batch blocks the batcher until it is read, leaving the
+	// other points queued.
+	var p models.Point
+	for i := 0; i < 5; i++ {
+		batcher.In() <- p
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		batcher.Stop()
+		close(stopped)
+	}()
+
+	var n int
+	for {
+		select {
+		case b := <-batcher.Out():
+			if len(b) > batchSize {
+				t.Errorf("received batch exceeds batch size %d, got %d", batchSize, len(b))
+			}
+			n += len(b)
+			continue
+		case <-stopped:
+		}
+		break
+	}
+	if n != 5 {
+		t.Errorf("received incorrect number of points exp %d, got %d", 5, n)
+	}
+}
@@ -69,8 +69,29 @@ const (
 	// partition snapshot compactions that can run at one time.
 	// A value of 0 results in runtime.GOMAXPROCS(0).
 	DefaultSeriesFileMaxConcurrentSnapshotCompactions = 0
+
+	// DefaultStringCodec is the codec used to compress string blocks in new TSM files.
+	DefaultStringCodec = StringCodecSnappy
+)
+
+// Names of the codecs available for compressing TSM string blocks.
+const (
+	// StringCodecSnappy compresses the length-prefixed strings of a block with snappy.
+	StringCodecSnappy = "snappy"
+
+	// StringCodecZstd compresses the length-prefixed strings of a block with zstd.
+	StringCodecZstd = "zstd"
+
+	// StringCodecDictionary stores each distinct string of a block once, followed by
+	// simple8b packed references. Blocks with too many distinct values fall back to snappy.
+	StringCodecDictionary = "dictionary"
 )
 
+// StringCodecs returns the names of all supported string block codecs.
+func StringCodecs() []string {
+	return []string{StringCodecSnappy, StringCodecZstd, StringCodecDictionary}
+}
+
 // Config holds the configuration for the tsbd package.
 type Config struct {
 	Dir    string `toml:"dir"`
@@ -149,6 +170,13 @@ type Config struct {
 	// been found to be problematic in some cases. It may help users who have
 	// slow disks.
 	TSMWillNeed bool `toml:"tsm-use-madv-willneed"`
+
+	// TSMStringCodec is the codec used to compress string blocks written by snapshots
+	// and compactions. Existing blocks are transcoded when they are next fully compacted.
+	TSMStringCodec string `toml:"tsm-string-codec"`
+
+	// TSMBucketStringCodecs overrides TSMStringCodec for individual buckets, keyed by bucket ID.
+	TSMBucketStringCodecs map[string]string `toml:"tsm-bucket-string-codecs"`
 }
 
 // NewConfig returns the default configuration for tsdb.
@@ -177,9 +205,22 @@ func NewConfig() Config {
 
 		TraceLoggingEnabled: false,
 		TSMWillNeed:         false,
+
+		TSMStringCodec: DefaultStringCodec,
 	}
 }
 
+// StringCodecFor returns the name of the string block codec to use for the given bucket.
+func (c Config) StringCodecFor(bucket string) string {
+	if codec, ok := c.TSMBucketStringCodecs[bucket]; ok {
+		return codec
+	}
+	if c.TSMStringCodec == "" {
+		return DefaultStringCodec
+	}
+	return c.TSMStringCodec
+}
+
 // Validate validates the configuration hold by c.
 func (c *Config) Validate() error {
 	if c.Dir == "" {
@@ -200,6 +241,15 @@ func (c *Config) Validate() error {
 		return errors.New("series-file-max-concurrent-compactions must be non-negative")
 	}
 
+	if c.TSMStringCodec != "" && !validStringCodec(c.TSMStringCodec) {
+		return fmt.Errorf("unrecognized tsm-string-codec %s", c.TSMStringCodec)
+	}
+	for bucket, codec := range c.TSMBucketStringCodecs {
+		if !validStringCodec(codec) {
+			return fmt.Errorf("unrecognized tsm-bucket-string-codecs codec %s for bucket %s", codec, bucket)
+		}
+	}
+
 	valid := false
 	for _, e := range RegisteredEngines() {
 		if e == c.Engine {
@@ -224,3 +274,12 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+func validStringCodec(name string) bool {
+	for _, codec := range StringCodecs() {
+		if codec == name {
+			return true
+		}
+	}
+	return false
+}
@@ -62,6 +62,33 @@ func TestConfig_Validate_Error(t *testing.T) {
 	if err := c.Validate(); err == nil || err.Error() != "series-id-set-cache-size must be non-negative" {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	c.SeriesIDSetCacheSize = tsdb.DefaultSeriesIDSetCacheSize
+	c.TSMStringCodec = "lz4"
+	if err := c.Validate(); err == nil || err.Error() != "unrecognized tsm-string-codec lz4" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.TSMStringCodec = tsdb.StringCodecZstd
+	c.TSMBucketStringCodecs = map[string]string{"0000000000000001": "lz4"}
+	if err := c.Validate(); err == nil || err.Error() != "unrecognized tsm-bucket-string-codecs codec lz4 for bucket 0000000000000001" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_StringCodecFor(t *testing.T) {
+	c := tsdb.NewConfig()
+	_, err := toml.Decode(`
+tsm-string-codec = "zstd"
+
+[tsm-bucket-string-codecs]
+"0000000000000001" = "dictionary"
+`, &c)
+	require.NoError(t, err)
+
+	require.Equal(t, tsdb.StringCodecDictionary, c.StringCodecFor("0000000000000001"))
+	require.Equal(t, tsdb.StringCodecZstd, c.StringCodecFor("0000000000000002"))
+	require.Equal(t, tsdb.StringCodecSnappy, tsdb.NewConfig().StringCodecFor("0000000000000002"))
 }
 
 func TestConfig_ByteSizes(t *testing.T) {
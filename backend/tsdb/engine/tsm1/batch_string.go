@@ -78,20 +78,21 @@ func StringArrayEncodeAll(src []string, b []byte) ([]byte, error) {
 }
 
 func StringArrayDecodeAll(b []byte, dst []string) ([]string, error) {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
-	if len(b) > 0 {
-		var err error
-		// it is important that to note that `snappy.Decode` always returns
-		// a newly allocated slice as the final strings reference this slice
-		// directly.
-		b, err = snappy.Decode(nil, b[1:])
-		if err != nil {
-			return []string{}, fmt.Errorf("failed to decode string block: %v", err.Error())
-		}
-	} else {
+	// First byte stores the encoding type.
+	if len(b) == 0 {
 		return []string{}, nil
 	}
+	if b[0]>>4 == stringDictionary {
+		return stringArrayDecodeAllDictionary(b, dst)
+	}
+
+	// it is important that to note that decodeStringData always returns
+	// a newly allocated slice as the final strings reference this slice
+	// directly.
+	b, err := decodeStringData(b)
+	if err != nil {
+		return []string{}, err
+	}
 
 	var (
 		i, l int
@@ -128,7 +128,7 @@ func (k *tsmBatchKeyIterator) combineFloat(dedup bool) blocks {
 			continue
 		}
 
-		if count < k.size {
+		if count < k.size || k.needsTranscode(k.blocks[i].b) {
 			break
 		}
 
@@ -149,7 +149,7 @@ func (k *tsmBatchKeyIterator) combineFloat(dedup bool) blocks {
 	}
 
 	// if we only have 1 blocks left, just append it as is and avoid decoding/recoding
-	if i == len(k.blocks)-1 {
+	if i == len(k.blocks)-1 && !k.needsTranscode(k.blocks[i].b) {
 		if !k.blocks[i].read() {
 			k.merged = append(k.merged, k.blocks[i])
 		}
@@ -352,7 +352,7 @@ func (k *tsmBatchKeyIterator) combineInteger(dedup bool) blocks {
 			continue
 		}
 
-		if count < k.size {
+		if count < k.size || k.needsTranscode(k.blocks[i].b) {
 			break
 		}
 
@@ -373,7 +373,7 @@ func (k *tsmBatchKeyIterator) combineInteger(dedup bool) blocks {
 	}
 
 	// if we only have 1 blocks left, just append it as is and avoid decoding/recoding
-	if i == len(k.blocks)-1 {
+	if i == len(k.blocks)-1 && !k.needsTranscode(k.blocks[i].b) {
 		if !k.blocks[i].read() {
 			k.merged = append(k.merged, k.blocks[i])
 		}
@@ -576,7 +576,7 @@ func (k *tsmBatchKeyIterator) combineUnsigned(dedup bool) blocks {
 			continue
 		}
 
-		if count < k.size {
+		if count < k.size || k.needsTranscode(k.blocks[i].b) {
 			break
 		}
 
@@ -597,7 +597,7 @@ func (k *tsmBatchKeyIterator) combineUnsigned(dedup bool) blocks {
 	}
 
 	// if we only have 1 blocks left, just append it as is and avoid decoding/recoding
-	if i == len(k.blocks)-1 {
+	if i == len(k.blocks)-1 && !k.needsTranscode(k.blocks[i].b) {
 		if !k.blocks[i].read() {
 			k.merged = append(k.merged, k.blocks[i])
 		}
@@ -800,7 +800,7 @@ func (k *tsmBatchKeyIterator) combineString(dedup bool) blocks {
 			continue
 		}
 
-		if count < k.size {
+		if count < k.size || k.needsTranscode(k.blocks[i].b) {
 			break
 		}
 
@@ -821,7 +821,7 @@ func (k *tsmBatchKeyIterator) combineString(dedup bool) blocks {
 	}
 
 	// if we only have 1 blocks left, just append it as is and avoid decoding/recoding
-	if i == len(k.blocks)-1 {
+	if i == len(k.blocks)-1 && !k.needsTranscode(k.blocks[i].b) {
 		if !k.blocks[i].read() {
 			k.merged = append(k.merged, k.blocks[i])
 		}
@@ -870,7 +870,7 @@ func (k *tsmBatchKeyIterator) chunkString(dst blocks) blocks {
 		minTime, maxTime := values.Timestamps[0], values.Timestamps[len(values.Timestamps)-1]
 		values.Values = k.mergedStringValues.Values[:k.size]
 
-		cb, err := EncodeStringArrayBlock(&values, nil) // TODO(edd): pool this buffer
+		cb, err := EncodeStringArrayBlockWith(k.stringCodec, &values, nil) // TODO(edd): pool this buffer
 		if err != nil {
 			k.handleEncodeError(err, "string")
 			return nil
@@ -890,7 +890,7 @@ func (k *tsmBatchKeyIterator) chunkString(dst blocks) blocks {
 	// Re-encode the remaining values into the last block
 	if k.mergedStringValues.Len() > 0 {
 		minTime, maxTime := k.mergedStringValues.Timestamps[0], k.mergedStringValues.Timestamps[len(k.mergedStringValues.Timestamps)-1]
-		cb, err := EncodeStringArrayBlock(k.mergedStringValues, nil) // TODO(edd): pool this buffer
+		cb, err := EncodeStringArrayBlockWith(k.stringCodec, k.mergedStringValues, nil) // TODO(edd): pool this buffer
 		if err != nil {
 			k.handleEncodeError(err, "string")
 			return nil
@@ -1024,7 +1024,7 @@ func (k *tsmBatchKeyIterator) combineBoolean(dedup bool) blocks {
 			continue
 		}
 
-		if count < k.size {
+		if count < k.size || k.needsTranscode(k.blocks[i].b) {
 			break
 		}
 
@@ -1045,7 +1045,7 @@ func (k *tsmBatchKeyIterator) combineBoolean(dedup bool) blocks {
 	}
 
 	// if we only have 1 blocks left, just append it as is and avoid decoding/recoding
-	if i == len(k.blocks)-1 {
+	if i == len(k.blocks)-1 && !k.needsTranscode(k.blocks[i].b) {
 		if !k.blocks[i].read() {
 			k.merged = append(k.merged, k.blocks[i])
 		}
@@ -732,6 +732,10 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// StringCodec is the codec used to compress string blocks.  Blocks using a
+	// different codec are transcoded when they are rewritten by a full compaction.
+	StringCodec StringCodec
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -757,6 +761,7 @@ func NewCompactor() *Compactor {
 	return &Compactor{
 		formatFileName: DefaultFormatFileName,
 		parseFileName:  DefaultParseFileName,
+		StringCodec:    StringCodecSnappy,
 	}
 }
 
@@ -883,7 +888,7 @@ func (c *Compactor) WriteSnapshot(cache *Cache, logger *zap.Logger) ([]string, e
 	resC := make(chan res, concurrency)
 	for i := 0; i < concurrency; i++ {
 		go func(sp *Cache) {
-			iter := NewCacheKeyIterator(sp, tsdb.DefaultMaxPointsPerBlock, intC)
+			iter := newCacheKeyIterator(sp, tsdb.DefaultMaxPointsPerBlock, c.StringCodec, intC)
 			files, err := c.writeNewFiles(c.FileStore.NextGeneration(), 0, nil, iter, throttle, logger)
 			resC <- res{files: files, err: err}
 
@@ -976,7 +981,7 @@ func (c *Compactor) compact(fast bool, tsmFiles []string, logger *zap.Logger) ([
 		return nil, nil
 	}
 
-	tsm, err := NewTSMBatchKeyIterator(size, fast, DefaultMaxSavedErrors, intC, tsmFiles, trs...)
+	tsm, err := newTSMBatchKeyIterator(size, fast, DefaultMaxSavedErrors, c.StringCodec, intC, tsmFiles, trs...)
 	if err != nil {
 		return nil, err
 	}
@@ -1407,6 +1412,10 @@ type tsmBatchKeyIterator struct {
 	mergedBooleanValues  *tsdb.BooleanArray
 	mergedStringValues   *tsdb.StringArray
 
+	// stringCodec is the codec used to encode string blocks.  Full string blocks
+	// using another codec are decoded and re-encoded rather than copied as is.
+	stringCodec StringCodec
+
 	// merged are encoded blocks that have been combined or used as is
 	// without decode
 	merged    blocks
@@ -1438,6 +1447,10 @@ func (t *tsmBatchKeyIterator) AppendError(err error) bool {
 // NewTSMBatchKeyIterator returns a new TSM key iterator from readers.
 // size indicates the maximum number of values to encode in a single block.
 func NewTSMBatchKeyIterator(size int, fast bool, maxErrors int, interrupt chan struct{}, tsmFiles []string, readers ...*TSMReader) (KeyIterator, error) {
+	return newTSMBatchKeyIterator(size, fast, maxErrors, StringCodecSnappy, interrupt, tsmFiles, readers...)
+}
+
+func newTSMBatchKeyIterator(size int, fast bool, maxErrors int, stringCodec StringCodec, interrupt chan struct{}, tsmFiles []string, readers ...*TSMReader) (KeyIterator, error) {
 	var iter []*BlockIterator
 	for _, r := range readers {
 		iter = append(iter, r.BlockIterator())
@@ -1458,6 +1471,7 @@ func NewTSMBatchKeyIterator(size int, fast bool, maxErrors int, interrupt chan s
 		mergedUnsignedValues: &tsdb.UnsignedArray{},
 		mergedBooleanValues:  &tsdb.BooleanArray{},
 		mergedStringValues:   &tsdb.StringArray{},
+		stringCodec:          stringCodec,
 		interrupt:            interrupt,
 		maxErrors:            maxErrors,
 	}, nil
@@ -1646,6 +1660,16 @@ func (k *tsmBatchKeyIterator) merge() {
 	}
 }
 
+// needsTranscode returns true if b is a string block encoded with a codec other
+// than the one configured for the iterator.
+func (k *tsmBatchKeyIterator) needsTranscode(b []byte) bool {
+	if len(b) == 0 || b[0] != BlockString {
+		return false
+	}
+	codec, err := stringBlockCodec(b)
+	return err == nil && codec != k.stringCodec
+}
+
 func (k *tsmBatchKeyIterator) handleEncodeError(err error, typ string) {
 	k.AppendError(errBlockRead{k.currentTsm, fmt.Errorf("encode error: unable to compress block type %s for key '%s': %w", typ, k.key, err)})
 }
@@ -1696,9 +1720,10 @@ func (k *tsmBatchKeyIterator) Err() error {
 }
 
 type cacheKeyIterator struct {
-	cache *Cache
-	size  int
-	order [][]byte
+	cache       *Cache
+	size        int
+	stringCodec StringCodec
+	order       [][]byte
 
 	i         int
 	blocks    [][]cacheBlock
@@ -1716,6 +1741,10 @@ type cacheBlock struct {
 
 // NewCacheKeyIterator returns a new KeyIterator from a Cache.
 func NewCacheKeyIterator(cache *Cache, size int, interrupt chan struct{}) KeyIterator {
+	return newCacheKeyIterator(cache, size, StringCodecSnappy, interrupt)
+}
+
+func newCacheKeyIterator(cache *Cache, size int, stringCodec StringCodec, interrupt chan struct{}) KeyIterator {
 	keys := cache.Keys()
 
 	chans := make([]chan struct{}, len(keys))
@@ -1724,13 +1753,14 @@ func NewCacheKeyIterator(cache *Cache, size int, interrupt chan struct{}) KeyIte
 	}
 
 	cki := &cacheKeyIterator{
-		i:         -1,
-		size:      size,
-		cache:     cache,
-		order:     keys,
-		ready:     chans,
-		blocks:    make([][]cacheBlock, len(keys)),
-		interrupt: interrupt,
+		i:           -1,
+		size:        size,
+		stringCodec: stringCodec,
+		cache:       cache,
+		order:       keys,
+		ready:       chans,
+		blocks:      make([][]cacheBlock, len(keys)),
+		interrupt:   interrupt,
 	}
 	go cki.encode()
 	return cki
@@ -1760,6 +1790,7 @@ func (c *cacheKeyIterator) encode() {
 			benc := getBooleanEncoder(tsdb.DefaultMaxPointsPerBlock)
 			uenc := getUnsignedEncoder(tsdb.DefaultMaxPointsPerBlock)
 			senc := getStringEncoder(tsdb.DefaultMaxPointsPerBlock)
+			senc.SetCodec(c.stringCodec)
 			ienc := getIntegerEncoder(tsdb.DefaultMaxPointsPerBlock)
 
 			defer putTimeEncoder(tenc)
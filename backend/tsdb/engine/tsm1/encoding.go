@@ -1032,6 +1032,7 @@ func putFloatEncoder(enc *FloatEncoder) { floatEncoderPool.Put(enc) }
 func getStringEncoder(sz int) StringEncoder {
 	x := stringEncoderPool.Get(sz).(StringEncoder)
 	x.Reset()
+	x.SetCodec(StringCodecSnappy)
 	return x
 }
 func putStringEncoder(enc StringEncoder) { stringEncoderPool.Put(enc) }
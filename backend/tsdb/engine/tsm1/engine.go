@@ -154,6 +154,10 @@ type Engine struct {
 
 	// muDigest ensures only one goroutine can generate a digest at a time.
 	muDigest sync.RWMutex
+
+	// stringCodecErr is the error parsing the string codec configured for the
+	// bucket of the engine, returned by Open.
+	stringCodecErr error
 }
 
 // NewEngine returns a new instance of Engine.
@@ -184,6 +188,12 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 	c.Dir = path
 	c.FileStore = fs
 	c.RateLimit = opt.CompactionThroughputLimiter
+	codec, stringCodecErr := ParseStringCodec(opt.Config.StringCodecFor(etags.Bucket))
+	if stringCodecErr != nil {
+		stringCodecErr = fmt.Errorf("invalid string codec for bucket %s: %w", etags.Bucket, stringCodecErr)
+	} else {
+		c.StringCodec = codec
+	}
 
 	var planner CompactionPlanner = NewDefaultPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration))
 	if opt.CompactionPlannerCreator != nil {
@@ -220,6 +230,7 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 		stats:                         stats,
 		compactionLimiter:             opt.CompactionLimiter,
 		seriesIDSets:                  opt.SeriesIDSets,
+		stringCodecErr:                stringCodecErr,
 	}
 
 	// Feature flag to enable per-series type checking, by default this is off and
@@ -728,6 +739,10 @@ func (e *Engine) DiskSize() int64 {
 
 // Open opens and initializes the engine.
 func (e *Engine) Open(ctx context.Context) error {
+	if e.stringCodecErr != nil {
+		return e.stringCodecErr
+	}
+
 	if err := os.MkdirAll(e.path, 0777); err != nil {
 		return err
 	}
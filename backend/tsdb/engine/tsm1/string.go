@@ -12,7 +12,8 @@ import (
 	"github.com/golang/snappy"
 )
 
-// Note: an uncompressed format is not yet implemented.
+// Note: an uncompressed format is not yet implemented.  Alternate codecs are
+// described in string_codec.go.
 
 // stringCompressedSnappy is a compressed encoding using Snappy compression
 const stringCompressedSnappy = 1
@@ -21,21 +22,35 @@ const stringCompressedSnappy = 1
 type StringEncoder struct {
 	// The encoded bytes
 	bytes []byte
+
+	// The codec used to compress the encoded bytes
+	codec StringCodec
+
+	// The written strings, retained only for the dictionary codec
+	values []string
 }
 
 // NewStringEncoder returns a new StringEncoder with an initial buffer ready to hold sz bytes.
 func NewStringEncoder(sz int) StringEncoder {
 	return StringEncoder{
 		bytes: make([]byte, 0, sz),
+		codec: StringCodecSnappy,
 	}
 }
 
+// SetCodec sets the codec used to compress the strings.  The codec is retained
+// across calls to Reset.
+func (e *StringEncoder) SetCodec(codec StringCodec) {
+	e.codec = codec
+}
+
 // Flush is no-op
 func (e *StringEncoder) Flush() {}
 
 // Reset sets the encoder back to its initial state.
 func (e *StringEncoder) Reset() {
 	e.bytes = e.bytes[:0]
+	e.values = e.values[:0]
 }
 
 // Write encodes s to the underlying buffer.
@@ -47,12 +62,25 @@ func (e *StringEncoder) Write(s string) {
 
 	// Append the string bytes
 	e.bytes = append(e.bytes, s...)
+
+	if e.codec == StringCodecDictionary {
+		e.values = append(e.values, s)
+	}
 }
 
 // Bytes returns a copy of the underlying buffer.
 func (e *StringEncoder) Bytes() ([]byte, error) {
+	switch e.codec {
+	case StringCodecZstd:
+		return zstdEncoder.EncodeAll(e.bytes, []byte{stringCompressedZstd << 4}), nil
+	case StringCodecDictionary:
+		if len(e.values) > 0 {
+			return StringArrayEncodeAllWith(StringCodecDictionary, e.values, nil)
+		}
+	}
+
 	// Compress the currently appended bytes using snappy and prefix with
-	// a 1 byte header for future extension
+	// a 1 byte header identifying the codec
 	data := snappy.Encode(nil, e.bytes)
 	return append([]byte{stringCompressedSnappy << 4}, data...), nil
 }
@@ -68,15 +96,10 @@ type StringDecoder struct {
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
-	var data []byte
-	if len(b) > 0 {
-		var err error
-		data, err = snappy.Decode(nil, b[1:])
-		if err != nil {
-			return fmt.Errorf("failed to decode string block: %v", err.Error())
-		}
+	// First byte stores the encoding type.
+	data, err := decodeStringData(b)
+	if err != nil {
+		return err
 	}
 
 	e.b = data
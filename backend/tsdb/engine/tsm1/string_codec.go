@@ -0,0 +1,323 @@
+package tsm1
+
+// String blocks carry a 1 byte header whose upper 4 bits identify the codec used to
+// compress the values.  Decoders dispatch on that header, so blocks written with
+// different codecs can be mixed within a single TSM file and across files.  The
+// codec used for new blocks is chosen per engine (and therefore per bucket) and
+// compactions transcode blocks written with a different codec.
+//
+// The snappy and zstd codecs compress the same uncompressed representation: each
+// string prefixed with its uvarint encoded length.
+//
+// The dictionary codec is intended for low-cardinality fields.  Each distinct string
+// is written once, followed by one simple8b packed reference into the dictionary per
+// value:
+//
+//	┌────────┬──────────────────┬─────────────────────────┬───────────────────────┐
+//	│ header │ dictionary count │ dictionary entries      │ references            │
+//	│ 1 byte │ uvarint          │ uvarint length + string │ simple8b, 8 byte words│
+//	└────────┴──────────────────┴─────────────────────────┴───────────────────────┘
+//
+// Blocks with too many distinct values to benefit from a dictionary are written using
+// snappy instead.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2/pkg/encoding/simple8b"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// stringCompressedZstd is a compressed encoding using zstd compression
+	stringCompressedZstd = 2
+
+	// stringDictionary is an encoding storing each distinct string once, followed by
+	// simple8b packed references.
+	stringDictionary = 3
+)
+
+// StringCodec identifies the compression scheme of the values in a string block.
+type StringCodec byte
+
+const (
+	// StringCodecSnappy compresses string values using snappy. It is the default.
+	StringCodecSnappy StringCodec = stringCompressedSnappy
+
+	// StringCodecZstd compresses string values using zstd.
+	StringCodecZstd StringCodec = stringCompressedZstd
+
+	// StringCodecDictionary dictionary encodes low-cardinality string values.
+	StringCodecDictionary StringCodec = stringDictionary
+)
+
+var (
+	errStringDictionaryShortBuffer   = errors.New("StringArrayDecodeAll: short dictionary buffer")
+	errStringDictionaryInvalidLength = errors.New("StringArrayDecodeAll: invalid dictionary entry length")
+	errStringDictionaryInvalidIndex  = errors.New("StringArrayDecodeAll: dictionary reference out of range")
+)
+
+var (
+	// zstdEncoder and zstdDecoder are safe for concurrent use via EncodeAll and
+	// DecodeAll, so a single instance of each is shared by all blocks.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// ParseStringCodec returns the StringCodec for name, which is one of the names
+// returned by tsdb.StringCodecs.
+func ParseStringCodec(name string) (StringCodec, error) {
+	switch name {
+	case tsdb.StringCodecSnappy, "":
+		return StringCodecSnappy, nil
+	case tsdb.StringCodecZstd:
+		return StringCodecZstd, nil
+	case tsdb.StringCodecDictionary:
+		return StringCodecDictionary, nil
+	default:
+		return 0, fmt.Errorf("unknown string codec %q", name)
+	}
+}
+
+// String returns the name of the codec.
+func (c StringCodec) String() string {
+	switch c {
+	case StringCodecSnappy:
+		return tsdb.StringCodecSnappy
+	case StringCodecZstd:
+		return tsdb.StringCodecZstd
+	case StringCodecDictionary:
+		return tsdb.StringCodecDictionary
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// stringBlockCodec returns the codec used to encode the values of a string block.
+func stringBlockCodec(block []byte) (StringCodec, error) {
+	if len(block) == 0 || block[0] != BlockString {
+		return 0, fmt.Errorf("stringBlockCodec: not a string block")
+	}
+
+	_, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return 0, err
+	}
+	if len(vb) == 0 {
+		return StringCodecSnappy, nil
+	}
+	return StringCodec(vb[0] >> 4), nil
+}
+
+// StringArrayEncodeAllWith encodes src into b using codec, returning b and any
+// error encountered. The returned slice may be of a different length and capacity to b.
+func StringArrayEncodeAllWith(codec StringCodec, src []string, b []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return StringArrayEncodeAll(src, b)
+	}
+
+	switch codec {
+	case StringCodecSnappy:
+		return StringArrayEncodeAll(src, b)
+	case StringCodecZstd:
+		return stringArrayEncodeAllZstd(src, b)
+	case StringCodecDictionary:
+		return stringArrayEncodeAllDictionary(src, b)
+	default:
+		return b[:0], fmt.Errorf("StringArrayEncodeAll: unknown string codec %d", codec)
+	}
+}
+
+// EncodeStringArrayBlockWith encodes a as a string block whose values are compressed
+// using codec.
+func EncodeStringArrayBlockWith(codec StringCodec, a *tsdb.StringArray, b []byte) ([]byte, error) {
+	if a.Len() == 0 {
+		return nil, nil
+	}
+
+	vb, err := StringArrayEncodeAllWith(codec, a.Values, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tb, err := TimeArrayEncodeAll(a.Timestamps, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return packBlock(b, BlockString, tb, vb), nil
+}
+
+func stringArrayEncodeAllZstd(src []string, b []byte) ([]byte, error) {
+	srcSz, err := stringArrayEncodedSize(src)
+	if err != nil {
+		return b[:0], err
+	}
+
+	data := make([]byte, 0, srcSz)
+	data = appendLengthPrefixedStrings(data, src)
+
+	b = append(b[:0], stringCompressedZstd<<4)
+	return zstdEncoder.EncodeAll(data, b), nil
+}
+
+func stringArrayEncodeAllDictionary(src []string, b []byte) ([]byte, error) {
+	// A dictionary only pays off when values repeat; otherwise snappy does better.
+	index := make(map[string]uint64)
+	refs := make([]uint64, len(src))
+	var dict []string
+	for i, s := range src {
+		ref, ok := index[s]
+		if !ok {
+			if len(dict) >= len(src)/2 {
+				return StringArrayEncodeAll(src, b)
+			}
+			ref = uint64(len(dict))
+			index[s] = ref
+			dict = append(dict, s)
+		}
+		refs[i] = ref
+	}
+
+	packed, err := simple8b.EncodeAll(refs)
+	if err != nil {
+		return b[:0], err
+	}
+
+	dictSz, err := stringArrayEncodedSize(dict)
+	if err != nil {
+		return b[:0], err
+	}
+
+	sz := 1 + binary.MaxVarintLen64 + dictSz + len(packed)*8
+	if cap(b) < sz {
+		b = make([]byte, 0, sz)
+	}
+	b = append(b[:0], stringDictionary<<4)
+	b = binary.AppendUvarint(b, uint64(len(dict)))
+	b = appendLengthPrefixedStrings(b, dict)
+	for _, v := range packed {
+		b = binary.BigEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+// stringArrayEncodedSize returns the maximum number of bytes needed to store src
+// as length-prefixed strings.
+func stringArrayEncodedSize(src []string) (int, error) {
+	srcSz64 := int64(2 + len(src)*binary.MaxVarintLen32) // strings shouldn't be longer than 64kb
+	for i := range src {
+		srcSz64 += int64(len(src[i]))
+	}
+
+	// 32-bit systems
+	if srcSz64 > int64(^uint32(0)) {
+		return 0, ErrStringArrayEncodeTooLarge
+	}
+	return int(srcSz64), nil
+}
+
+func appendLengthPrefixedStrings(b []byte, src []string) []byte {
+	for _, s := range src {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	return b
+}
+
+// decodeStringData returns the length-prefixed strings stored in a string block.
+// The returned slice is always newly allocated, as decoded strings reference it directly.
+func decodeStringData(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	switch b[0] >> 4 {
+	case stringCompressedSnappy:
+		data, err := snappy.Decode(nil, b[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err.Error())
+		}
+		return data, nil
+	case stringCompressedZstd:
+		data, err := zstdDecoder.DecodeAll(b[1:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err.Error())
+		}
+		return data, nil
+	case stringDictionary:
+		values, err := stringArrayDecodeAllDictionary(b, nil)
+		if err != nil {
+			return nil, err
+		}
+		var sz int
+		for _, s := range values {
+			sz += binary.MaxVarintLen64 + len(s)
+		}
+		return appendLengthPrefixedStrings(make([]byte, 0, sz), values), nil
+	default:
+		return nil, fmt.Errorf("failed to decode string block: unknown encoding %d", b[0]>>4)
+	}
+}
+
+func stringArrayDecodeAllDictionary(b []byte, dst []string) ([]string, error) {
+	b = b[1:]
+	dictN, n := binary.Uvarint(b)
+	if n <= 0 {
+		return []string{}, errStringDictionaryInvalidLength
+	}
+	b = b[n:]
+
+	// Find the end of the dictionary so it can be copied out of the block; decoded
+	// strings reference the copy directly and blocks may be backed by an mmap.
+	var end int
+	for i := uint64(0); i < dictN; i++ {
+		length, n := binary.Uvarint(b[end:])
+		if n <= 0 {
+			return []string{}, errStringDictionaryInvalidLength
+		}
+		end += n + int(length)
+		if end > len(b) || end < 0 {
+			return []string{}, errStringDictionaryShortBuffer
+		}
+	}
+	data := make([]byte, end)
+	copy(data, b[:end])
+	refs := b[end:]
+
+	dict := make([]string, 0, dictN)
+	for i := 0; i < len(data); {
+		length, n := binary.Uvarint(data[i:])
+		s := data[i+n : i+n+int(length)]
+		dict = append(dict, *(*string)(unsafe.Pointer(&s)))
+		i += n + int(length)
+	}
+
+	count, err := simple8b.CountBytes(refs)
+	if err != nil {
+		return []string{}, err
+	}
+
+	buf := make([]uint64, count)
+	if _, err := simple8b.DecodeBytesBigEndian(buf, refs); err != nil {
+		return []string{}, err
+	}
+
+	if cap(dst) < count {
+		dst = make([]string, count)
+	} else {
+		dst = dst[:count]
+	}
+	for i, ref := range buf {
+		if ref >= uint64(len(dict)) {
+			return []string{}, errStringDictionaryInvalidIndex
+		}
+		dst[i] = dict[ref]
+	}
+	return dst, nil
+}
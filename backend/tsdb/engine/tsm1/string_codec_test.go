@@ -0,0 +1,311 @@
+package tsm1
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/internal/testutil"
+	"github.com/influxdata/influxdb/v2/pkg/encoding/simple8b"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var stringCodecs = []StringCodec{StringCodecSnappy, StringCodecZstd, StringCodecDictionary}
+
+func lowCardinalityStrings(n int) []string {
+	levels := []string{"ok", "info", "warn", "crit"}
+	src := make([]string, n)
+	for i := range src {
+		src[i] = levels[i%len(levels)]
+	}
+	return src
+}
+
+func highCardinalityStrings(n int) []string {
+	src := make([]string, n)
+	for i := range src {
+		src[i] = uuid.TimeUUID().String()
+	}
+	return src
+}
+
+func TestParseStringCodec(t *testing.T) {
+	for _, name := range tsdb.StringCodecs() {
+		codec, err := ParseStringCodec(name)
+		require.NoError(t, err)
+		require.Equal(t, name, codec.String())
+	}
+
+	_, err := ParseStringCodec("lz4")
+	require.Error(t, err)
+}
+
+func TestEngine_Open_InvalidStringCodec(t *testing.T) {
+	dir := t.TempDir()
+	bucketPath := filepath.Join(dir, "0000000000000001", "autogen", "1")
+
+	opt := tsdb.NewEngineOptions()
+	opt.Config.TSMBucketStringCodecs = map[string]string{"0000000000000001": "lz4"}
+	e := NewEngine(1, nil, bucketPath, filepath.Join(dir, "wal"), nil, opt).(*Engine)
+
+	err := e.Open(context.Background())
+	require.EqualError(t, err, `invalid string codec for bucket 0000000000000001: unknown string codec "lz4"`)
+}
+
+func TestStringArrayEncodeAllWith_RoundTrip(t *testing.T) {
+	inputs := map[string][]string{
+		"empty":            nil,
+		"single":           {"v1"},
+		"low cardinality":  lowCardinalityStrings(1000),
+		"high cardinality": highCardinalityStrings(1000),
+	}
+
+	for _, codec := range stringCodecs {
+		for name, src := range inputs {
+			t.Run(fmt.Sprintf("%s/%s", codec, name), func(t *testing.T) {
+				b, err := StringArrayEncodeAllWith(codec, src, nil)
+				require.NoError(t, err)
+
+				got, err := StringArrayDecodeAll(b, nil)
+				require.NoError(t, err)
+				if !cmp.Equal(got, src, cmp.Comparer(func(a, b []string) bool { return len(a) == 0 && len(b) == 0 || cmp.Equal(a, b) })) {
+					t.Fatalf("unexpected values -got/+exp\n%s", cmp.Diff(got, src))
+				}
+
+				var dec StringDecoder
+				require.NoError(t, dec.SetBytes(b))
+				var i int
+				for dec.Next() {
+					require.Equal(t, src[i], dec.Read())
+					i++
+				}
+				require.NoError(t, dec.Error())
+				require.Equal(t, len(src), i)
+			})
+		}
+	}
+}
+
+func TestStringArrayEncodeAllWith_Header(t *testing.T) {
+	b, err := StringArrayEncodeAllWith(StringCodecZstd, lowCardinalityStrings(10), nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(stringCompressedZstd), b[0]>>4)
+
+	b, err = StringArrayEncodeAllWith(StringCodecDictionary, lowCardinalityStrings(10), nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(stringDictionary), b[0]>>4)
+
+	// Too many distinct values for a dictionary to help falls back to snappy.
+	b, err = StringArrayEncodeAllWith(StringCodecDictionary, highCardinalityStrings(10), nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(stringCompressedSnappy), b[0]>>4)
+}
+
+func TestStringEncoder_Codecs(t *testing.T) {
+	src := lowCardinalityStrings(100)
+	for _, codec := range stringCodecs {
+		t.Run(codec.String(), func(t *testing.T) {
+			enc := NewStringEncoder(1024)
+			enc.SetCodec(codec)
+			for _, s := range src {
+				enc.Write(s)
+			}
+			b, err := enc.Bytes()
+			require.NoError(t, err)
+			require.Equal(t, byte(codec), b[0]>>4)
+
+			got, err := StringArrayDecodeAll(b, nil)
+			require.NoError(t, err)
+			require.Equal(t, src, got)
+
+			// The codec survives a reset.
+			enc.Reset()
+			enc.Write("v1")
+			enc.Write("v1")
+			b, err = enc.Bytes()
+			require.NoError(t, err)
+			require.Equal(t, byte(codec), b[0]>>4)
+		})
+	}
+}
+
+func TestStringArrayDecodeAll_DictionaryCorrupt(t *testing.T) {
+	b, err := StringArrayEncodeAllWith(StringCodecDictionary, lowCardinalityStrings(100), nil)
+	require.NoError(t, err)
+
+	// A single entry dictionary referenced by index 5.
+	refs, err := simple8b.EncodeAll([]uint64{5})
+	require.NoError(t, err)
+	badRef := []byte{stringDictionary << 4, 1, 1, 'a'}
+	badRef = binary.BigEndian.AppendUint64(badRef, refs[0])
+
+	cases := map[string][]byte{
+		"truncated dictionary": b[:4],
+		"missing count":        b[:1],
+		"bad reference":        badRef,
+	}
+	for name, corrupt := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := StringArrayDecodeAll(corrupt, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+type transcodeFileStore struct {
+	readers []*TSMReader
+}
+
+func (fs *transcodeFileStore) NextGeneration() int { return 1 }
+
+func (fs *transcodeFileStore) TSMReader(path string) (*TSMReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewTSMReader(f)
+	if err != nil {
+		return nil, err
+	}
+	fs.readers = append(fs.readers, r)
+	r.Ref()
+	return r, nil
+}
+
+func (fs *transcodeFileStore) Close() {
+	for _, r := range fs.readers {
+		r.Close()
+	}
+}
+
+func TestCompactor_CompactFull_TranscodesStringBlocks(t *testing.T) {
+	dir := t.TempDir()
+
+	// Write a full snappy encoded string block followed by a float block, which
+	// must be left untouched.
+	key := []byte("cpu,host=A#!~#status")
+	var values []Value
+	for i, s := range lowCardinalityStrings(tsdb.DefaultMaxPointsPerBlock) {
+		values = append(values, NewStringValue(int64(i), s))
+	}
+
+	name := filepath.Join(dir, DefaultFormatFileName(1, 1)+"."+TSMFileExtension)
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	w, err := NewTSMWriter(f)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(key, values))
+	require.NoError(t, w.Write([]byte("cpu,host=A#!~#value"), Values{NewFloatValue(1, 1.5)}))
+	require.NoError(t, w.WriteIndex())
+	require.NoError(t, w.Close())
+
+	fs := &transcodeFileStore{}
+	t.Cleanup(fs.Close)
+
+	compactor := NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.StringCodec = StringCodecDictionary
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{name}, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	r, err := fs.TSMReader(files[0])
+	require.NoError(t, err)
+	defer r.Unref()
+
+	iter := r.BlockIterator()
+	var strings int
+	for iter.Next() {
+		_, _, _, typ, _, b, err := iter.Read()
+		require.NoError(t, err)
+		if typ != BlockString {
+			continue
+		}
+		strings++
+
+		codec, err := stringBlockCodec(b)
+		require.NoError(t, err)
+		require.Equal(t, StringCodecDictionary, codec)
+
+		var got tsdb.StringArray
+		require.NoError(t, DecodeStringArrayBlock(b, &got))
+		require.Equal(t, tsdb.DefaultMaxPointsPerBlock, got.Len())
+		require.Equal(t, values[0].(StringValue).RawValue(), got.Values[0])
+	}
+	require.Equal(t, 1, strings)
+}
+
+func BenchmarkStringArrayEncodeAllWith(b *testing.B) {
+	inputs := []struct {
+		name string
+		src  []string
+	}{
+		{"low cardinality", lowCardinalityStrings(1000)},
+		{"high cardinality", highCardinalityStrings(1000)},
+		{"sentences", func() []string {
+			src := make([]string, 1000)
+			for i := range src {
+				src[i] = testutil.MakeSentence(10)
+			}
+			return src
+		}()},
+	}
+
+	for _, in := range inputs {
+		for _, codec := range stringCodecs {
+			b.Run(fmt.Sprintf("%s/%s", in.name, codec), func(b *testing.B) {
+				var buf []byte
+				var err error
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if buf, err = StringArrayEncodeAllWith(codec, in.src, buf); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(buf)), "bytes/block")
+			})
+		}
+	}
+}
+
+func BenchmarkStringArrayDecodeAllWith(b *testing.B) {
+	inputs := []struct {
+		name string
+		src  []string
+	}{
+		{"low cardinality", lowCardinalityStrings(1000)},
+		{"high cardinality", highCardinalityStrings(1000)},
+	}
+
+	for _, in := range inputs {
+		for _, codec := range stringCodecs {
+			buf, err := StringArrayEncodeAllWith(codec, in.src, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.Run(fmt.Sprintf("%s/%s", in.name, codec), func(b *testing.B) {
+				b.SetBytes(int64(len(buf)))
+				b.ReportAllocs()
+
+				dst := make([]string, len(in.src))
+				for i := 0; i < b.N; i++ {
+					if dst, err = StringArrayDecodeAll(buf, dst); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/influxdb/v2/authorizer"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/tracing"
@@ -37,6 +38,10 @@ type StatementExecutor struct {
 
 	DBRP influxdb.DBRPMappingService
 
+	// IntoWriter writes the points selected by SELECT INTO statements.
+	// SELECT INTO is not supported when it is not set.
+	IntoWriter IntoWriter
+
 	// Select statement limits
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
@@ -252,11 +257,34 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 	defer em.Close()
 
 	// Emit rows to the results channel.
+	var writeN int64
 	var emitted bool
 
+	var into *intoWriter
 	if stmt.Target != nil {
-		// SELECT INTO is unsupported
-		return iql.ErrNotImplemented("SELECT INTO")
+		if e.IntoWriter == nil {
+			// SELECT INTO is unsupported
+			return iql.ErrNotImplemented("SELECT INTO")
+		}
+
+		mapping, err := e.getTargetRP(ctx, stmt.Target.Measurement, ectx)
+		if err != nil {
+			return err
+		}
+
+		// Require write for SELECT INTO queries
+		_, _, err = authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, mapping.BucketID, ectx.OrgID)
+		if err != nil {
+			return ectx.Send(ctx, &query.Result{
+				Err: fmt.Errorf("insufficient permissions"),
+			})
+		}
+
+		into = &intoWriter{
+			w:        e.IntoWriter,
+			orgID:    ectx.OrgID,
+			bucketID: mapping.BucketID,
+		}
 	}
 
 	for {
@@ -271,6 +299,16 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 			break
 		}
 
+		// Write points back into the target bucket for INTO statements.
+		if into != nil {
+			n, err := into.writeRow(ctx, stmt, row)
+			if err != nil {
+				return err
+			}
+			writeN += n
+			continue
+		}
+
 		result := &query.Result{
 			Series:  []*models.Row{row},
 			Partial: partial,
@@ -284,6 +322,21 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 		emitted = true
 	}
 
+	// Flush remaining points and emit write count if an INTO statement.
+	if into != nil {
+		if err := into.flush(ctx); err != nil {
+			return err
+		}
+
+		return ectx.Send(ctx, &query.Result{
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), writeN}},
+			}},
+		})
+	}
+
 	// Always emit at least one result.
 	if !emitted {
 		return ectx.Send(ctx, &query.Result{
@@ -366,6 +419,24 @@ func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, e
 	return mappings[0], nil
 }
 
+// getTargetRP returns the mapping of the database and retention policy of the
+// target of a SELECT INTO statement, which have been set by normalization.
+func (e *StatementExecutor) getTargetRP(ctx context.Context, m *influxql.Measurement, ectx *query.ExecutionContext) (*influxdb.DBRPMapping, error) {
+	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilter{
+		OrgID:           &ectx.OrgID,
+		Database:        &m.Database,
+		RetentionPolicy: &m.RetentionPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("retention policy not found: %s.%s", m.Database, m.RetentionPolicy)
+	} else if n != 1 {
+		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	}
+	return mappings[0], nil
+}
+
 func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
 	mapping, err := e.getDefaultRP(ctx, database, ectx)
 	if err != nil {
@@ -855,6 +926,105 @@ type TSDBStore interface {
 
 var _ TSDBStore = LocalTSDBStore{}
 
+// IntoWriter writes points into a bucket.
+type IntoWriter interface {
+	WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error
+}
+
+// intoBufferSize is how many points of a SELECT INTO statement are buffered
+// before they are written.
+const intoBufferSize = 10000
+
+// intoWriter buffers the points of the rows emitted by a SELECT INTO
+// statement and writes them into the bucket of its target.
+type intoWriter struct {
+	w        IntoWriter
+	orgID    platform.ID
+	bucketID platform.ID
+	buf      []models.Point
+}
+
+// writeRow converts row into points and buffers them, returning how many
+// points were converted.
+func (w *intoWriter) writeRow(ctx context.Context, stmt *influxql.SelectStatement, row *models.Row) (int64, error) {
+	// Targets with a blank name are written to the measurement the row came from.
+	name := stmt.Target.Measurement.Name
+	if name == "" {
+		name = row.Name
+	}
+
+	points, err := convertRowToPoints(name, row)
+	if err != nil {
+		return 0, err
+	}
+
+	w.buf = append(w.buf, points...)
+	if len(w.buf) >= intoBufferSize {
+		if err := w.flush(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(points)), nil
+}
+
+// flush writes the buffered points.
+func (w *intoWriter) flush(ctx context.Context) error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if err := w.w.WritePoints(ctx, w.orgID, w.bucketID, w.buf); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// convertRowToPoints will convert a query result Row into Points that can be written back in.
+func convertRowToPoints(measurementName string, row *models.Row) ([]models.Point, error) {
+	// figure out which parts of the result are the time and which are the fields
+	timeIndex := -1
+	fieldIndexes := make(map[string]int)
+	for i, c := range row.Columns {
+		if c == "time" {
+			timeIndex = i
+		} else {
+			fieldIndexes[c] = i
+		}
+	}
+
+	if timeIndex == -1 {
+		return nil, errors.New("error finding time index in result")
+	}
+
+	points := make([]models.Point, 0, len(row.Values))
+	for _, v := range row.Values {
+		vals := make(map[string]interface{})
+		for fieldName, fieldIndex := range fieldIndexes {
+			val := v[fieldIndex]
+			// NullFloat represents float numbers that cannot be written back
+			// (like NaN) but does not equal nil, so check for it as well.
+			if val != nil && val != query.NullFloat {
+				vals[fieldName] = val
+			}
+		}
+
+		t, ok := v[timeIndex].(time.Time)
+		if !ok {
+			continue
+		}
+
+		p, err := models.NewPoint(measurementName, models.NewTags(row.Tags), vals, t)
+		if err != nil {
+			// Drop points that can't be stored
+			continue
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
 // LocalTSDBStore embeds a tsdb.Store and implements IteratorCreator
 // to satisfy the TSDBStore interface.
 type LocalTSDBStore struct {
@@ -103,6 +103,117 @@ func TestQueryExecutor_ExecuteQuery_SelectStatement(t *testing.T) {
 	}
 }
 
+// Ensure query executor writes the results of a SELECT INTO statement into the bucket of its target.
+func TestQueryExecutor_ExecuteQuery_SelectIntoStatement(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffee)
+	empty := ""
+	db, rp := "db1", "rp1"
+
+	cases := []struct {
+		name        string
+		permissions []influxdb.Permission
+		exp         []*query.Result
+		expWritten  int
+	}{
+		{
+			name: "write permission",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID},
+			}},
+			exp: []*query.Result{{
+				StatementID: 0,
+				Series: []*models.Row{{
+					Name:    "result",
+					Columns: []string{"time", "written"},
+					Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+				}},
+			}},
+			expWritten: 2,
+		},
+		{
+			name: "no write permission",
+			exp: []*query.Result{{
+				StatementID: 0,
+				Err:         errors.New("insufficient permissions"),
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dbrp := mocks.NewMockDBRPMappingService(ctrl)
+			dbrp.EXPECT().
+				FindMany(gomock.Any(), influxdb.DBRPMappingFilter{OrgID: &orgID, Database: &empty, RetentionPolicy: &empty}).
+				Return([]*influxdb.DBRPMapping{{}}, 1, nil)
+			dbrp.EXPECT().
+				FindMany(gomock.Any(), influxdb.DBRPMappingFilter{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+				Return([]*influxdb.DBRPMapping{{Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: bucketID}}, 1, nil)
+
+			e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+
+			var written []models.Point
+			e.StatementExecutor.IntoWriter = intoWriterFunc(func(_ context.Context, o, b platform.ID, points []models.Point) error {
+				if o != orgID || b != bucketID {
+					t.Fatalf("unexpected write to org %s bucket %s", o, b)
+				}
+				written = append(written, points...)
+				return nil
+			})
+
+			e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+				return []meta.ShardGroupInfo{
+					{ID: 1, Shards: []meta.ShardInfo{
+						{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+					}},
+				}, nil
+			}
+			e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+				var sh MockShard
+				sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+					return &FloatIterator{Points: []query.FloatPoint{
+						{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+						{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+					}}, nil
+				}
+				sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+					return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+				}
+				return &sh
+			}
+
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				ID:          orgID,
+				OrgID:       orgID,
+				Status:      influxdb.Active,
+				Permissions: tc.permissions,
+			})
+
+			if a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM cpu`, "db0", 0, orgID)); !reflect.DeepEqual(a, tc.exp) {
+				t.Fatalf("unexpected results: %s", spew.Sdump(a))
+			}
+			if len(written) != tc.expWritten {
+				t.Fatalf("unexpected number of points written: %d", len(written))
+			}
+			for _, p := range written {
+				if string(p.Name()) != "cpu_copy" {
+					t.Fatalf("unexpected measurement written: %s", p.Name())
+				}
+			}
+		})
+	}
+}
+
+type intoWriterFunc func(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error
+
+func (f intoWriterFunc) WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error {
+	return f(ctx, orgID, bucketID, points)
+}
+
 // Ensure query executor can enforce a maximum bucket selection count.
 func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	ctrl := gomock.NewController(t)
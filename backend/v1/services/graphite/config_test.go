@@ -0,0 +1,119 @@
+package graphite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/v2/v1/services/graphite"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c graphite.Config
+	if _, err := toml.Decode(`
+enabled = true
+bind-address = ":8080"
+protocol = "udp"
+bucket-id = "00000000000000ab"
+batch-size = 100
+batch-pending = 77
+batch-timeout = "1s"
+templates = ["servers.* .host.measurement*"]
+tags = ["region=us-east"]
+separator = "_"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected graphite enabled: %v", c.Enabled)
+	} else if c.BindAddress != ":8080" {
+		t.Fatalf("unexpected graphite bind address: %s", c.BindAddress)
+	} else if c.Protocol != "udp" {
+		t.Fatalf("unexpected graphite protocol: %s", c.Protocol)
+	} else if c.BucketID != 0xab {
+		t.Fatalf("unexpected graphite bucket id: %s", c.BucketID)
+	} else if c.BatchSize != 100 {
+		t.Fatalf("unexpected graphite batch size: %d", c.BatchSize)
+	} else if c.BatchPending != 77 {
+		t.Fatalf("unexpected graphite batch pending: %d", c.BatchPending)
+	} else if time.Duration(c.BatchTimeout) != time.Second {
+		t.Fatalf("unexpected graphite batch timeout: %v", c.BatchTimeout)
+	} else if len(c.Templates) != 1 || c.Templates[0] != "servers.* .host.measurement*" {
+		t.Fatalf("unexpected graphite templates setting: %v", c.Templates)
+	} else if len(c.Tags) != 1 || c.Tags[0] != "region=us-east" {
+		t.Fatalf("unexpected graphite tags setting: %v", c.Tags)
+	} else if c.Separator != "_" {
+		t.Fatalf("unexpected graphite separator setting: %s", c.Separator)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() graphite.Config {
+		c := graphite.NewConfig()
+		c.Enabled = true
+		c.BucketID = 0xab
+		return c
+	}
+
+	c := valid()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	// A disabled listener is never validated.
+	c = graphite.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *graphite.Config)
+		err    string
+	}{
+		{
+			name:   "protocol",
+			modify: func(c *graphite.Config) { c.Protocol = "sctp" },
+			err:    `invalid protocol "sctp", must be tcp or udp`,
+		},
+		{
+			name:   "bucket",
+			modify: func(c *graphite.Config) { c.BucketID = 0 },
+			err:    "a valid bucket-id is required",
+		},
+		{
+			name:   "template without measurement",
+			modify: func(c *graphite.Config) { c.Templates = []string{"host.field"} },
+			err:    "no measurement in template `host.field`",
+		},
+		{
+			name: "duplicate filter",
+			modify: func(c *graphite.Config) {
+				c.Templates = []string{"servers.* measurement", "servers.* measurement.host"}
+			},
+			err: "duplicate filter 'servers.*' found at position: 1",
+		},
+		{
+			name:   "invalid filter",
+			modify: func(c *graphite.Config) { c.Templates = []string{"servers.a* measurement"} },
+			err:    "invalid filter wildcard section: servers.a*",
+		},
+		{
+			name:   "invalid tag",
+			modify: func(c *graphite.Config) { c.Tags = []string{"region"} },
+			err:    "invalid template tags: 'region'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(&c)
+			if err := c.Validate(); err == nil || err.Error() != tt.err {
+				t.Fatalf("unexpected validation error: got %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
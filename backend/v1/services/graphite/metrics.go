@@ -0,0 +1,98 @@
+package graphite
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// listenerMetrics are the metrics of a single Graphite listener, labelled by its
+// protocol and bind address.
+type listenerMetrics struct {
+	PointsReceived prometheus.Counter
+	BytesReceived  prometheus.Counter
+	ParseErrors    prometheus.Counter
+	PointsWritten  prometheus.Counter
+	BatchesWritten prometheus.Counter
+	WriteErrors    prometheus.Counter
+	Connections    prometheus.Gauge
+	PendingPoints  prometheus.GaugeFunc
+}
+
+func newListenerMetrics(protocol, bindAddress string, pending func() float64) *listenerMetrics {
+	const namespace = "graphite"
+	const subsystem = "listener"
+
+	labels := prometheus.Labels{"protocol": protocol, "bind_address": bindAddress}
+
+	return &listenerMetrics{
+		PointsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_received_total",
+			Help:        "Number of points successfully parsed by the listener",
+			ConstLabels: labels,
+		}),
+		BytesReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "bytes_received_total",
+			Help:        "Number of bytes received by the listener",
+			ConstLabels: labels,
+		}),
+		ParseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "parse_errors_total",
+			Help:        "Number of lines the listener failed to parse",
+			ConstLabels: labels,
+		}),
+		PointsWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_written_total",
+			Help:        "Number of points written to the target bucket",
+			ConstLabels: labels,
+		}),
+		BatchesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "batches_written_total",
+			Help:        "Number of batches written to the target bucket",
+			ConstLabels: labels,
+		}),
+		WriteErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "write_errors_total",
+			Help:        "Number of batches that failed to be written to the target bucket",
+			ConstLabels: labels,
+		}),
+		Connections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "connections_active",
+			Help:        "Number of open TCP connections",
+			ConstLabels: labels,
+		}),
+		PendingPoints: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_pending",
+			Help:        "Number of points waiting to be batched and written; readers block once the pending batches are full",
+			ConstLabels: labels,
+		}, pending),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *listenerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.PointsReceived,
+		m.BytesReceived,
+		m.ParseErrors,
+		m.PointsWritten,
+		m.BatchesWritten,
+		m.WriteErrors,
+		m.Connections,
+		m.PendingPoints,
+	}
+}
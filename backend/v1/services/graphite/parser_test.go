@@ -0,0 +1,190 @@
+package graphite_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/v1/services/graphite"
+)
+
+func TestParser_Parse(t *testing.T) {
+	testTime := time.Now().Round(time.Second)
+	epochTime := testTime.Unix()
+	strTime := strconv.FormatInt(epochTime, 10)
+
+	var tests = []struct {
+		test        string
+		input       string
+		measurement string
+		tags        map[string]string
+		value       float64
+		time        time.Time
+		template    string
+		err         string
+	}{
+		{
+			test:        "normal case",
+			input:       `cpu.foo.bar 50 ` + strTime,
+			template:    "measurement.foo.bar",
+			measurement: "cpu",
+			tags: map[string]string{
+				"foo": "foo",
+				"bar": "bar",
+			},
+			value: 50,
+			time:  testTime,
+		},
+		{
+			test:        "metric only with float value",
+			input:       `cpu 50.554 ` + strTime,
+			measurement: "cpu",
+			template:    "measurement",
+			value:       50.554,
+			time:        testTime,
+		},
+		{
+			test:     "missing metric",
+			input:    `1419972457825`,
+			template: "measurement",
+			err:      `received "1419972457825" which doesn't have required fields`,
+		},
+		{
+			test:     "should error parsing invalid float",
+			input:    `cpu 50.554z 1419972457825`,
+			template: "measurement",
+			err:      `field "cpu" value: strconv.ParseFloat: parsing "50.554z": invalid syntax`,
+		},
+		{
+			test:     "should error parsing invalid int",
+			input:    `cpu 50z 1419972457825`,
+			template: "measurement",
+			err:      `field "cpu" value: strconv.ParseFloat: parsing "50z": invalid syntax`,
+		},
+		{
+			test:     "should error parsing invalid time",
+			input:    `cpu 50.554 14199724z57825`,
+			template: "measurement",
+			err:      `field "cpu" time: strconv.ParseFloat: parsing "14199724z57825": invalid syntax`,
+		},
+		{
+			test:     "measurement* and field* (invalid)",
+			input:    `prod.us-west.server01.cpu_load.10 11.50 1435077219`,
+			template: "env.zone.host.measurement*.field*",
+			err:      `either 'field*' or 'measurement*' can be used in each template (but not both together): "env.zone.host.measurement*.field*"`,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := graphite.NewParser([]string{test.template}, nil)
+		if err == nil {
+			var point models.Point
+			point, err = p.Parse(test.input)
+			if err == nil {
+				if string(point.Name()) != test.measurement {
+					t.Fatalf("%s: name mismatch.  expected %v, got %v", test.test, test.measurement, string(point.Name()))
+				}
+				if len(point.Tags()) != len(test.tags) {
+					t.Fatalf("%s: tags len mismatch.  expected %d, got %d", test.test, len(test.tags), len(point.Tags()))
+				}
+				fields, err := point.Fields()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if f := fields["value"].(float64); f != test.value {
+					t.Fatalf("%s: floatValue value mismatch.  expected %v, got %v", test.test, test.value, f)
+				}
+				if point.Time().Unix() != test.time.UTC().Unix() {
+					t.Fatalf("%s: time mismatch.  expected %v, got %v", test.test, test.time.UTC(), point.Time().UTC())
+				}
+			}
+		}
+		if errstr(err) != test.err {
+			t.Fatalf("%s: err mismatch.  expected %v, got %v", test.test, test.err, err)
+		}
+	}
+}
+
+func TestParser_Templates(t *testing.T) {
+	templates := []string{
+		"*.*.* .wrong.measurement*",
+		"servers.* .host.measurement*",
+		"servers.localhost .host.measurement*",
+		"*.localhost .host.measurement*",
+		"sensors.* measurement.host.field* region=us-west",
+		"measurement.host.measurement",
+	}
+
+	var tests = []struct {
+		input string
+		exp   string
+	}{
+		{
+			// Exact match on both filter parts.
+			input: "servers.localhost.cpu_load 11 1435077219",
+			exp:   "cpu_load,host=localhost value=11 1435077219000000000",
+		},
+		{
+			// Wildcard filter, greedy measurement.
+			input: "servers.server01.cpu.load 12 1435077219",
+			exp:   "cpu.load,host=server01 value=12 1435077219000000000",
+		},
+		{
+			// Greedy field with template tags.
+			input: "sensors.node1.temp.c 21.5 1435077219",
+			exp:   "sensors,host=node1,region=us-west temp.c=21.5 1435077219000000000",
+		},
+		{
+			// Least specific filter.
+			input: "app.web01.requests 5 1435077219",
+			exp:   "requests,wrong=web01 value=5 1435077219000000000",
+		},
+		{
+			// No filter matches, so the template without a filter is used.
+			input: "app.web01 5 1435077219",
+			exp:   "app,host=web01 value=5 1435077219000000000",
+		},
+	}
+
+	p, err := graphite.NewParser(templates, models.NewTags(map[string]string{"dc": "1"}))
+	if err != nil {
+		t.Fatalf("unexpected error creating parser: %v", err)
+	}
+
+	for _, test := range tests {
+		point, err := p.Parse(test.input)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.input, err)
+		}
+
+		// The parser's default tags are added to every point.
+		exp, err := models.ParsePointsString(test.exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp[0].AddTag("dc", "1")
+		if got, want := point.String(), exp[0].String(); got != want {
+			t.Errorf("%q: point mismatch.\n got: %s\nwant: %s", test.input, got, want)
+		}
+	}
+}
+
+func TestParser_NaN(t *testing.T) {
+	p, err := graphite.NewParser(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Parse("servers.localhost.cpu_load NaN 1435077219")
+	if _, ok := err.(*graphite.UnsupportedValueError); !ok {
+		t.Fatalf("expected *UnsupportedValueError, got %v", err)
+	}
+}
+
+func errstr(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,366 @@
+// Package graphite provides a service for InfluxDB to ingest data via the graphite protocol.
+package graphite // import "github.com/influxdata/influxdb/v2/v1/services/graphite"
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const udpBufferSize = 65536
+
+// Service represents a Graphite service. Received lines are parsed into points
+// using the configured templates and written in batches to a single bucket.
+type Service struct {
+	bindAddress   string
+	protocol      string
+	bucketID      platform.ID
+	batchSize     int
+	batchPending  int
+	batchTimeout  time.Duration
+	udpReadBuffer int
+
+	parser  *Parser
+	batcher *tsdb.PointBatcher
+	metrics *listenerMetrics
+
+	mu      sync.Mutex
+	ln      net.Listener
+	addr    net.Addr
+	udpConn *net.UDPConn
+	conns   map[net.Conn]struct{}
+	closed  bool
+
+	readers sync.WaitGroup
+	writer  sync.WaitGroup
+	done    chan struct{}
+	cancel  context.CancelFunc
+
+	Logger *zap.Logger
+
+	PointsWriter interface {
+		WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error
+	}
+	BucketService interface {
+		FindBucketByID(ctx context.Context, id platform.ID) (*influxdb.Bucket, error)
+	}
+}
+
+// NewService returns an instance of the Graphite service.
+func NewService(c Config) (*Service, error) {
+	// Use defaults where necessary.
+	d := c.WithDefaults()
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	parser, err := NewParserWithOptions(Options{
+		Templates:   d.Templates,
+		DefaultTags: d.DefaultTags(),
+		Separator:   d.Separator,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		bindAddress:   d.BindAddress,
+		protocol:      d.Protocol,
+		bucketID:      d.BucketID,
+		batchSize:     d.BatchSize,
+		batchPending:  d.BatchPending,
+		batchTimeout:  time.Duration(d.BatchTimeout),
+		udpReadBuffer: d.UDPReadBuffer,
+		parser:        parser,
+		conns:         make(map[net.Conn]struct{}),
+		Logger:        zap.NewNop(),
+	}
+	s.metrics = newListenerMetrics(s.protocol, s.bindAddress, s.pending)
+	return s, nil
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "graphite"), zap.String("addr", s.bindAddress))
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
+
+// Open starts the Graphite input processing data.
+func (s *Service) Open(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+
+	s.Logger.Info("Starting graphite service",
+		zap.String("protocol", s.protocol),
+		zap.Stringer("bucket_id", s.bucketID),
+		zap.Int("batch_size", s.batchSize),
+		logger.DurationLiteral("batch_timeout", s.batchTimeout))
+
+	// The batcher must be running before the listeners start queueing points.
+	s.closed = false
+	s.batcher = tsdb.NewPointBatcher(s.batchSize, s.batchPending, s.batchTimeout)
+	s.batcher.Start()
+
+	var err error
+	switch strings.ToLower(s.protocol) {
+	case "tcp":
+		s.addr, err = s.openTCPServer()
+	case "udp":
+		s.addr, err = s.openUDPServer()
+	default:
+		err = fmt.Errorf("unrecognized Graphite input protocol %s", s.protocol)
+	}
+	if err != nil {
+		s.batcher.Stop()
+		return err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	s.writer.Add(1)
+	go s.processBatches(ctx, s.batcher, s.done)
+
+	s.Logger.Info("Listening", zap.String("protocol", s.protocol), zap.Stringer("addr", s.addr))
+	return nil
+}
+
+// Close stops all data processing on the Graphite input. Points already received
+// are written before Close returns.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.cancel == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	if s.ln != nil {
+		s.ln.Close()
+	}
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	// Stop the readers before the batcher, which emits the final batch, and then the
+	// writer once everything has been written.
+	s.readers.Wait()
+	s.batcher.Stop()
+	close(s.done)
+	s.writer.Wait()
+	s.cancel()
+
+	s.mu.Lock()
+	s.cancel = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// Addr returns the address the Service binds to.
+func (s *Service) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// openTCPServer opens the Graphite input in TCP mode and starts processing data.
+func (s *Service) openTCPServer() (net.Addr, error) {
+	ln, err := net.Listen("tcp", s.bindAddress)
+	if err != nil {
+		return nil, err
+	}
+	s.ln = ln
+
+	s.readers.Add(1)
+	go func() {
+		defer s.readers.Done()
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				s.Logger.Info("Graphite TCP listener closed")
+				return
+			} else if err != nil {
+				s.Logger.Info("Error accepting TCP connection", zap.Error(err))
+				continue
+			}
+
+			if !s.trackConn(conn) {
+				conn.Close()
+				return
+			}
+			s.readers.Add(1)
+			go s.handleTCPConnection(conn)
+		}
+	}()
+	return ln.Addr(), nil
+}
+
+// trackConn records conn as open, unless the service is closing.
+func (s *Service) trackConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+// handleTCPConnection services an individual TCP connection for the Graphite input.
+func (s *Service) handleTCPConnection(conn net.Conn) {
+	defer s.readers.Done()
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.metrics.Connections.Dec()
+	}()
+	s.metrics.Connections.Inc()
+
+	reader := bufio.NewReader(conn)
+	for {
+		// Read up to the next newline.
+		buf, err := reader.ReadBytes('\n')
+		if len(buf) > 0 {
+			s.metrics.BytesReceived.Add(float64(len(buf)))
+			s.handleLine(string(buf))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// openUDPServer opens the Graphite input in UDP mode and starts processing incoming data.
+func (s *Service) openUDPServer() (net.Addr, error) {
+	addr, err := net.ResolveUDPAddr("udp", s.bindAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	s.udpConn, err = net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.udpReadBuffer != 0 {
+		if err := s.udpConn.SetReadBuffer(s.udpReadBuffer); err != nil {
+			s.udpConn.Close()
+			return nil, fmt.Errorf("unable to set UDP read buffer to %d: %s", s.udpReadBuffer, err)
+		}
+	}
+
+	buf := make([]byte, udpBufferSize)
+	s.readers.Add(1)
+	go func() {
+		defer s.readers.Done()
+		for {
+			n, _, err := s.udpConn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+
+			s.metrics.BytesReceived.Add(float64(n))
+			for _, line := range strings.Split(string(buf[:n]), "\n") {
+				s.handleLine(line)
+			}
+		}
+	}()
+	return s.udpConn.LocalAddr(), nil
+}
+
+// handleLine parses a single line and queues the resulting point. Sending the
+// point blocks while the pending batches are full, applying back-pressure to the
+// connection.
+func (s *Service) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	// Parse it.
+	point, err := s.parser.Parse(line)
+	if err != nil {
+		switch err := err.(type) {
+		case *UnsupportedValueError:
+			// Graphite ignores NaN values with no error.
+			if math.IsNaN(err.Value) {
+				return
+			}
+		}
+		s.metrics.ParseErrors.Inc()
+		s.Logger.Debug("Unable to parse line", zap.String("line", line), zap.Error(err))
+		return
+	}
+	s.metrics.PointsReceived.Inc()
+
+	s.batcher.In() <- point
+}
+
+// pending returns the number of points waiting to be batched.
+func (s *Service) pending() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.batcher == nil {
+		return 0
+	}
+	return float64(s.batcher.Pending())
+}
+
+// processBatches continually drains the given batcher and writes the batches to the
+// target bucket until done is closed.
+func (s *Service) processBatches(ctx context.Context, batcher *tsdb.PointBatcher, done <-chan struct{}) {
+	defer s.writer.Done()
+	for {
+		select {
+		case batch := <-batcher.Out():
+			s.writeBatch(ctx, batch)
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *Service) writeBatch(ctx context.Context, batch []models.Point) {
+	b, err := s.BucketService.FindBucketByID(ctx, s.bucketID)
+	if err != nil {
+		s.metrics.WriteErrors.Inc()
+		s.Logger.Info("Failed to find target bucket", zap.Stringer("bucket_id", s.bucketID), zap.Error(err))
+		return
+	}
+
+	if err := s.PointsWriter.WritePoints(ctx, b.OrgID, b.ID, batch); err != nil {
+		s.metrics.WriteErrors.Inc()
+		s.Logger.Info("Failed to write point batch", zap.Int("batch_size", len(batch)), zap.Error(err))
+		return
+	}
+	s.metrics.BatchesWritten.Inc()
+	s.metrics.PointsWritten.Add(float64(len(batch)))
+}
@@ -0,0 +1,140 @@
+package graphite_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/v1/services/graphite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    platform.ID = 0x0a
+	bucketID platform.ID = 0x0b
+)
+
+func TestService_TCP(t *testing.T) {
+	testService(t, "tcp")
+}
+
+func TestService_UDP(t *testing.T) {
+	testService(t, "udp")
+}
+
+func testService(t *testing.T, protocol string) {
+	c := graphite.NewConfig()
+	c.Enabled = true
+	c.BindAddress = "127.0.0.1:0"
+	c.Protocol = protocol
+	c.BucketID = bucketID
+	c.BatchSize = 10
+	c.BatchTimeout = toml.Duration(10 * time.Millisecond)
+	c.Templates = []string{"measurement.host"}
+
+	s, err := graphite.NewService(c)
+	require.NoError(t, err)
+	s.WithLogger(zaptest.NewLogger(t))
+
+	pw := newPointsWriter()
+	s.PointsWriter = pw
+	s.BucketService = &bucketService{}
+
+	require.NoError(t, s.Open(context.Background()))
+	defer s.Close()
+
+	conn, err := net.Dial(protocol, s.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.server01 23.456 1435077219\nmem.server01 NaN 1435077219\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	select {
+	case points := <-pw.points:
+		require.Len(t, points, 1)
+		require.Equal(t, "cpu,host=server01 value=23.456 1435077219000000000", points[0].String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for points")
+	}
+
+	require.NoError(t, s.Close())
+}
+
+func TestService_CloseFlushesPending(t *testing.T) {
+	c := graphite.NewConfig()
+	c.Enabled = true
+	c.BindAddress = "127.0.0.1:0"
+	c.BucketID = bucketID
+	c.BatchTimeout = toml.Duration(time.Hour)
+
+	s, err := graphite.NewService(c)
+	require.NoError(t, err)
+
+	pw := newPointsWriter()
+	s.PointsWriter = pw
+	s.BucketService = &bucketService{}
+	require.NoError(t, s.Open(context.Background()))
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu 1 1435077219\n"))
+	require.NoError(t, err)
+
+	// Wait for the line to be read before closing the service.
+	require.Eventually(t, func() bool {
+		return counterValue(t, s, "graphite_listener_points_received_total") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	go s.Close()
+	select {
+	case points := <-pw.points:
+		require.Len(t, points, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for points")
+	}
+	conn.Close()
+}
+
+// counterValue returns the value of the service's counter named name.
+func counterValue(t *testing.T, s *graphite.Service, name string) float64 {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(s.PrometheusCollectors()...)
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	for _, mf := range mfs {
+		if mf.GetName() == name {
+			return mf.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+type pointsWriter struct {
+	points chan []models.Point
+}
+
+func newPointsWriter() *pointsWriter {
+	return &pointsWriter{points: make(chan []models.Point, 10)}
+}
+
+func (w *pointsWriter) WritePoints(_ context.Context, org, bucket platform.ID, points []models.Point) error {
+	if org != orgID || bucket != bucketID {
+		panic("unexpected bucket")
+	}
+	w.points <- points
+	return nil
+}
+
+type bucketService struct{}
+
+func (*bucketService) FindBucketByID(_ context.Context, id platform.ID) (*influxdb.Bucket, error) {
+	return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+}
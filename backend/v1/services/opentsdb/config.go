@@ -0,0 +1,77 @@
+package opentsdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/toml"
+)
+
+const (
+	// DefaultBindAddress is the default address that the service binds to.
+	DefaultBindAddress = ":4242"
+
+	// DefaultBatchSize is the default OpenTSDB batch size.
+	DefaultBatchSize = 1000
+
+	// DefaultBatchTimeout is the default OpenTSDB batch timeout.
+	DefaultBatchTimeout = time.Second
+
+	// DefaultBatchPending is the default number of batches that can be in the queue.
+	DefaultBatchPending = 5
+)
+
+// Config represents the configuration of the OpenTSDB service.
+type Config struct {
+	Enabled      bool          `toml:"enabled"`
+	BindAddress  string        `toml:"bind-address"`
+	BucketID     platform.ID   `toml:"bucket-id"`
+	BatchSize    int           `toml:"batch-size"`
+	BatchPending int           `toml:"batch-pending"`
+	BatchTimeout toml.Duration `toml:"batch-timeout"`
+}
+
+// NewConfig returns a new config for the service.
+func NewConfig() Config {
+	return Config{
+		BindAddress:  DefaultBindAddress,
+		BatchSize:    DefaultBatchSize,
+		BatchPending: DefaultBatchPending,
+		BatchTimeout: toml.Duration(DefaultBatchTimeout),
+	}
+}
+
+// WithDefaults takes the given config and returns a new config with any required
+// default values set.
+func (c *Config) WithDefaults() *Config {
+	d := *c
+	if d.BindAddress == "" {
+		d.BindAddress = DefaultBindAddress
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = DefaultBatchSize
+	}
+	if d.BatchPending == 0 {
+		d.BatchPending = DefaultBatchPending
+	}
+	if d.BatchTimeout == 0 {
+		d.BatchTimeout = toml.Duration(DefaultBatchTimeout)
+	}
+	return &d
+}
+
+// Validate returns an error if the Config is invalid.
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if !c.BucketID.Valid() {
+		return errors.New("a valid bucket-id is required")
+	}
+	if c.BatchSize < 0 || c.BatchPending < 0 || c.BatchTimeout < 0 {
+		return errors.New("batch-size, batch-pending and batch-timeout must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package opentsdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+// Handler is an http.Handler for the OpenTSDB service.
+type Handler struct {
+	metrics *listenerMetrics
+	queue   func(models.Point)
+	Logger  *zap.Logger
+}
+
+// ServeHTTP handles an HTTP request of the OpenTSDB REST API.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/metadata/put":
+		w.WriteHeader(http.StatusNoContent)
+	case "/api/put":
+		h.servePut(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// servePut implements OpenTSDB's HTTP /api/put endpoint.
+func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	h.metrics.HTTPRequests.Inc()
+
+	// Require POST method.
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Wrap reader if it's gzip encoded.
+	var br io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "could not read gzip, "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		br = zr
+	}
+
+	// Decode JSON data into slice of points. The body holds either a single
+	// point or an array of points.
+	data, err := io.ReadAll(br)
+	if err != nil {
+		http.Error(w, "could not read body, "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var dps []point
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &dps)
+	} else {
+		dps = make([]point, 1)
+		err = json.Unmarshal(data, &dps[0])
+	}
+	if err != nil {
+		http.Error(w, "json decode error", http.StatusBadRequest)
+		return
+	}
+
+	// Convert points into TSDB points.
+	for i := range dps {
+		p := dps[i]
+
+		pt, err := models.NewPoint(p.Metric, models.NewTags(p.Tags), map[string]interface{}{"value": p.Value}, p.time())
+		if err != nil {
+			h.metrics.ParseErrors.Inc()
+			h.Logger.Debug("Dropping point", zap.String("metric", p.Metric), zap.Error(err))
+			continue
+		}
+		h.metrics.PointsReceived.Inc()
+		h.queue(pt)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// point represents a single data point in an OpenTSDB put request. A request body
+// may contain a single point or an array of points.
+type point struct {
+	Metric string            `json:"metric"`
+	Time   int64             `json:"timestamp"`
+	Value  float64           `json:"value"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+// time converts the point's timestamp, given in seconds or milliseconds, to a
+// time.Time.
+func (p *point) time() time.Time {
+	return timestamp(p.Time)
+}
+
+// timestamp converts an OpenTSDB timestamp to a time.Time. Timestamps of more
+// than 10 digits are in milliseconds, all others in seconds.
+func timestamp(ts int64) time.Time {
+	if ts < 10000000000 {
+		return time.Unix(ts, 0)
+	}
+	return time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond))
+}
+
+// chanListener represents a listener that receives connections through a channel.
+type chanListener struct {
+	addr   net.Addr
+	ch     chan net.Conn
+	done   chan struct{}
+	closed int32
+}
+
+// newChanListener returns a new instance of chanListener.
+func newChanListener(addr net.Addr) *chanListener {
+	return &chanListener{
+		addr: addr,
+		ch:   make(chan net.Conn),
+		done: make(chan struct{}),
+	}
+}
+
+func (ln *chanListener) Accept() (net.Conn, error) {
+	errClosed := errors.New("network connection closed")
+	select {
+	case <-ln.done:
+		return nil, errClosed
+	case conn, ok := <-ln.ch:
+		if !ok {
+			return nil, errClosed
+		}
+		return conn, nil
+	}
+}
+
+// Close closes the connection channel.
+func (ln *chanListener) Close() error {
+	if atomic.CompareAndSwapInt32(&ln.closed, 0, 1) {
+		close(ln.done)
+	}
+	return nil
+}
+
+// Addr returns the network address of the listener.
+func (ln *chanListener) Addr() net.Addr { return ln.addr }
+
+// readerConn represents a net.Conn with an assignable reader.
+type readerConn struct {
+	net.Conn
+	r io.Reader
+}
+
+// Read implements the io.Reader interface.
+func (conn *readerConn) Read(b []byte) (n int, err error) { return conn.r.Read(b) }
@@ -0,0 +1,107 @@
+package opentsdb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// listenerMetrics are the metrics of a single OpenTSDB listener, labelled by its
+// bind address.
+type listenerMetrics struct {
+	PointsReceived prometheus.Counter
+	BytesReceived  prometheus.Counter
+	ParseErrors    prometheus.Counter
+	PointsWritten  prometheus.Counter
+	BatchesWritten prometheus.Counter
+	WriteErrors    prometheus.Counter
+	HTTPRequests   prometheus.Counter
+	Connections    prometheus.Gauge
+	PendingPoints  prometheus.GaugeFunc
+}
+
+func newListenerMetrics(bindAddress string, pending func() float64) *listenerMetrics {
+	const namespace = "opentsdb"
+	const subsystem = "listener"
+
+	labels := prometheus.Labels{"bind_address": bindAddress}
+
+	return &listenerMetrics{
+		PointsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_received_total",
+			Help:        "Number of points successfully parsed by the listener",
+			ConstLabels: labels,
+		}),
+		BytesReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "bytes_received_total",
+			Help:        "Number of telnet bytes received by the listener",
+			ConstLabels: labels,
+		}),
+		ParseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "parse_errors_total",
+			Help:        "Number of telnet lines and HTTP data points the listener failed to parse",
+			ConstLabels: labels,
+		}),
+		PointsWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_written_total",
+			Help:        "Number of points written to the target bucket",
+			ConstLabels: labels,
+		}),
+		BatchesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "batches_written_total",
+			Help:        "Number of batches written to the target bucket",
+			ConstLabels: labels,
+		}),
+		WriteErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "write_errors_total",
+			Help:        "Number of batches that failed to be written to the target bucket",
+			ConstLabels: labels,
+		}),
+		HTTPRequests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "http_requests_total",
+			Help:        "Number of HTTP put requests received by the listener",
+			ConstLabels: labels,
+		}),
+		Connections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "connections_active",
+			Help:        "Number of open telnet connections",
+			ConstLabels: labels,
+		}),
+		PendingPoints: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "points_pending",
+			Help:        "Number of points waiting to be batched and written; readers block once the pending batches are full",
+			ConstLabels: labels,
+		}, pending),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *listenerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.PointsReceived,
+		m.BytesReceived,
+		m.ParseErrors,
+		m.PointsWritten,
+		m.BatchesWritten,
+		m.WriteErrors,
+		m.HTTPRequests,
+		m.Connections,
+		m.PendingPoints,
+	}
+}
@@ -0,0 +1,394 @@
+// Package opentsdb provides a service for InfluxDB to ingest data via the opentsdb protocol.
+package opentsdb // import "github.com/influxdata/influxdb/v2/v1/services/opentsdb"
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// httpMethodPrefixes are the first bytes of HTTP requests, used to tell them
+// apart from telnet commands on the shared listener.
+var httpMethodPrefixes = []string{"GET ", "POST", "PUT ", "HEAD", "DELE", "OPTI", "PATC"}
+
+// Service manages the listener and handler for an OpenTSDB endpoint. Telnet
+// "put" commands and HTTP requests to /api/put are served on the same port and
+// written in batches to a single bucket.
+type Service struct {
+	bindAddress  string
+	bucketID     platform.ID
+	batchSize    int
+	batchPending int
+	batchTimeout time.Duration
+
+	batcher *tsdb.PointBatcher
+	metrics *listenerMetrics
+
+	mu     sync.Mutex
+	ln     net.Listener  // main listener
+	httpln *chanListener // http channel-based listener
+	server *http.Server
+	addr   net.Addr
+	conns  map[net.Conn]struct{}
+	closed bool
+
+	readers sync.WaitGroup
+	writer  sync.WaitGroup
+	done    chan struct{}
+	cancel  context.CancelFunc
+
+	Logger *zap.Logger
+
+	PointsWriter interface {
+		WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error
+	}
+	BucketService interface {
+		FindBucketByID(ctx context.Context, id platform.ID) (*influxdb.Bucket, error)
+	}
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) (*Service, error) {
+	// Use defaults where necessary.
+	d := c.WithDefaults()
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		bindAddress:  d.BindAddress,
+		bucketID:     d.BucketID,
+		batchSize:    d.BatchSize,
+		batchPending: d.BatchPending,
+		batchTimeout: time.Duration(d.BatchTimeout),
+		conns:        make(map[net.Conn]struct{}),
+		Logger:       zap.NewNop(),
+	}
+	s.metrics = newListenerMetrics(s.bindAddress, s.pending)
+	return s, nil
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.Logger = log.With(zap.String("service", "opentsdb"), zap.String("addr", s.bindAddress))
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
+
+// Open starts the service.
+func (s *Service) Open(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+
+	s.Logger.Info("Starting OpenTSDB service",
+		zap.Stringer("bucket_id", s.bucketID),
+		zap.Int("batch_size", s.batchSize),
+		logger.DurationLiteral("batch_timeout", s.batchTimeout))
+
+	ln, err := net.Listen("tcp", s.bindAddress)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	s.addr = ln.Addr()
+	s.closed = false
+
+	// The batcher must be running before the listeners start queueing points.
+	s.batcher = tsdb.NewPointBatcher(s.batchSize, s.batchPending, s.batchTimeout)
+	s.batcher.Start()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	s.writer.Add(1)
+	go s.processBatches(ctx, s.batcher, s.done)
+
+	// Serve HTTP requests handed over by the main listener.
+	s.httpln = newChanListener(s.addr)
+	s.server = &http.Server{
+		Handler: &Handler{
+			metrics: s.metrics,
+			queue:   s.queue,
+			Logger:  s.Logger,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go s.server.Serve(s.httpln)
+
+	s.readers.Add(1)
+	go s.serve(ln)
+
+	s.Logger.Info("Listening on TCP", zap.Stringer("addr", s.addr))
+	return nil
+}
+
+// Close stops the service. Points already received are written before Close
+// returns.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.cancel == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	s.ln.Close()
+	s.httpln.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	// Stop the readers before the batcher, which emits the final batch, and then the
+	// writer once everything has been written.
+	s.readers.Wait()
+	if err := s.server.Shutdown(context.Background()); err != nil {
+		s.Logger.Info("Failed to shut down HTTP server", zap.Error(err))
+	}
+	s.batcher.Stop()
+	close(s.done)
+	s.writer.Wait()
+	s.cancel()
+
+	s.mu.Lock()
+	s.cancel = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// Addr returns the listener's address.
+func (s *Service) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// serve serves the handler from the listener.
+func (s *Service) serve(ln net.Listener) {
+	defer s.readers.Done()
+
+	for {
+		// Wait for next connection.
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			s.Logger.Info("OpenTSDB TCP listener closed")
+			return
+		} else if err != nil {
+			s.Logger.Info("Error accepting OpenTSDB", zap.Error(err))
+			continue
+		}
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			return
+		}
+
+		// Handle connection in separate goroutine.
+		s.readers.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// trackConn records conn as open, unless the service is closing.
+func (s *Service) trackConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Service) untrackConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+// handleConn processes conn. This is run in a separate goroutine.
+func (s *Service) handleConn(conn net.Conn) {
+	defer s.readers.Done()
+
+	// Read header into buffer to check if it's HTTP.
+	r := bufio.NewReader(conn)
+	buf, err := r.Peek(4)
+	if err != nil {
+		s.untrackConn(conn)
+		conn.Close()
+		return
+	}
+
+	// If this looks like an HTTP request, hand the connection over to the HTTP
+	// server, which owns it from then on.
+	if isHTTP(buf) {
+		s.untrackConn(conn)
+		select {
+		case s.httpln.ch <- &readerConn{Conn: conn, r: r}:
+		case <-s.httpln.done:
+			conn.Close()
+		}
+		return
+	}
+
+	s.handleTelnetConn(conn, r)
+}
+
+func isHTTP(buf []byte) bool {
+	for _, prefix := range httpMethodPrefixes {
+		if string(buf) == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTelnetConn accepts OpenTSDB's telnet protocol.
+// Each telnet command consists of a line of the form:
+//
+//	put sys.cpu.user 1356998400 42.5 host=webserver01 cpu=0
+func (s *Service) handleTelnetConn(conn net.Conn, r *bufio.Reader) {
+	defer func() {
+		conn.Close()
+		s.untrackConn(conn)
+		s.metrics.Connections.Dec()
+	}()
+	s.metrics.Connections.Inc()
+
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			s.metrics.BytesReceived.Add(float64(len(line)))
+			s.handleTelnetLine(conn, strings.TrimSpace(line))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Service) handleTelnetLine(conn net.Conn, line string) {
+	inputStrs := strings.Fields(line)
+	if len(inputStrs) == 0 {
+		return
+	}
+
+	if len(inputStrs) == 1 && inputStrs[0] == "version" {
+		if _, err := conn.Write([]byte("InfluxDB TSDB proxy\n")); err != nil {
+			s.Logger.Debug("Failed to write version response", zap.Error(err))
+		}
+		return
+	}
+
+	if len(inputStrs) < 4 || inputStrs[0] != "put" {
+		s.metrics.ParseErrors.Inc()
+		s.Logger.Debug("Malformed line", zap.String("line", line))
+		return
+	}
+
+	measurement := inputStrs[1]
+	tsStr := inputStrs[2]
+	valueStr := inputStrs[3]
+	tagStrs := inputStrs[4:]
+
+	tags := make(map[string]string)
+	for _, t := range tagStrs {
+		parts := strings.SplitN(t, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			s.metrics.ParseErrors.Inc()
+			s.Logger.Debug("Malformed tag data", zap.String("tag", t), zap.String("line", line))
+			return
+		}
+		tags[parts[0]] = parts[1]
+	}
+
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		s.metrics.ParseErrors.Inc()
+		s.Logger.Debug("Malformed time", zap.String("time", tsStr), zap.String("line", line))
+		return
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		s.metrics.ParseErrors.Inc()
+		s.Logger.Debug("Bad float", zap.String("value", valueStr), zap.String("line", line))
+		return
+	}
+
+	pt, err := models.NewPoint(measurement, models.NewTags(tags), models.Fields{"value": value}, timestamp(ts))
+	if err != nil {
+		s.metrics.ParseErrors.Inc()
+		s.Logger.Debug("Bad point", zap.String("line", line), zap.Error(err))
+		return
+	}
+	s.metrics.PointsReceived.Inc()
+	s.queue(pt)
+}
+
+// queue sends pt to the batcher. It blocks while the pending batches are full,
+// applying back-pressure to the connection.
+func (s *Service) queue(pt models.Point) {
+	s.batcher.In() <- pt
+}
+
+// pending returns the number of points waiting to be batched.
+func (s *Service) pending() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.batcher == nil {
+		return 0
+	}
+	return float64(s.batcher.Pending())
+}
+
+// processBatches continually drains the given batcher and writes the batches to the
+// target bucket until done is closed.
+func (s *Service) processBatches(ctx context.Context, batcher *tsdb.PointBatcher, done <-chan struct{}) {
+	defer s.writer.Done()
+	for {
+		select {
+		case batch := <-batcher.Out():
+			s.writeBatch(ctx, batch)
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *Service) writeBatch(ctx context.Context, batch []models.Point) {
+	b, err := s.BucketService.FindBucketByID(ctx, s.bucketID)
+	if err != nil {
+		s.metrics.WriteErrors.Inc()
+		s.Logger.Info("Failed to find target bucket", zap.Stringer("bucket_id", s.bucketID), zap.Error(err))
+		return
+	}
+
+	if err := s.PointsWriter.WritePoints(ctx, b.OrgID, b.ID, batch); err != nil {
+		s.metrics.WriteErrors.Inc()
+		s.Logger.Info("Failed to write point batch", zap.Int("batch_size", len(batch)), zap.Error(err))
+		return
+	}
+	s.metrics.BatchesWritten.Inc()
+	s.metrics.PointsWritten.Add(float64(len(batch)))
+}
@@ -0,0 +1,138 @@
+package opentsdb_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/v1/services/opentsdb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    platform.ID = 0x0a
+	bucketID platform.ID = 0x0b
+)
+
+func TestService_Telnet(t *testing.T) {
+	s, pw := newService(t)
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("version\n"))
+	require.NoError(t, err)
+	line := make([]byte, len("InfluxDB TSDB proxy\n"))
+	_, err = io.ReadFull(conn, line)
+	require.NoError(t, err)
+	require.Equal(t, "InfluxDB TSDB proxy\n", string(line))
+
+	_, err = conn.Write([]byte("put sys.cpu.user 1356998400 42.5 host=webserver01 cpu=0\n" +
+		"put sys.cpu.user 1356998400500 41 host=webserver01 cpu=1\n" +
+		"put sys.cpu.user bad 41\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"sys.cpu.user,cpu=0,host=webserver01 value=42.5 1356998400000000000",
+		"sys.cpu.user,cpu=1,host=webserver01 value=41 1356998400500000000",
+	}, pw.wait(t, 2))
+}
+
+func TestService_HTTP(t *testing.T) {
+	s, pw := newService(t)
+
+	resp, err := http.Post("http://"+s.Addr().String()+"/api/put", "application/json", strings.NewReader(`[
+		{"metric": "sys.cpu.nice", "timestamp": 1346846400, "value": 18, "tags": {"host": "web01", "dc": "lga"}},
+		{"metric": "sys.cpu.nice", "timestamp": 1346846400000, "value": 9, "tags": {"host": "web02", "dc": "lga"}}
+	]`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post("http://"+s.Addr().String()+"/api/put", "application/json", strings.NewReader(
+		`{"metric": "sys.cpu.nice", "timestamp": 1346846401, "value": 1, "tags": {"host": "web03"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post("http://"+s.Addr().String()+"/api/put", "application/json", strings.NewReader(`{`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Equal(t, []string{
+		"sys.cpu.nice,dc=lga,host=web01 value=18 1346846400000000000",
+		"sys.cpu.nice,dc=lga,host=web02 value=9 1346846400000000000",
+		"sys.cpu.nice,host=web03 value=1 1346846401000000000",
+	}, pw.wait(t, 3))
+}
+
+func newService(t *testing.T) (*opentsdb.Service, *pointsWriter) {
+	t.Helper()
+
+	c := opentsdb.NewConfig()
+	c.Enabled = true
+	c.BindAddress = "127.0.0.1:0"
+	c.BucketID = bucketID
+	c.BatchSize = 10
+	c.BatchTimeout = toml.Duration(10 * time.Millisecond)
+
+	s, err := opentsdb.NewService(c)
+	require.NoError(t, err)
+	s.WithLogger(zaptest.NewLogger(t))
+
+	pw := &pointsWriter{points: make(chan []models.Point, 10)}
+	s.PointsWriter = pw
+	s.BucketService = &bucketService{}
+
+	require.NoError(t, s.Open(context.Background()))
+	t.Cleanup(func() { require.NoError(t, s.Close()) })
+	return s, pw
+}
+
+type pointsWriter struct {
+	points chan []models.Point
+}
+
+func (w *pointsWriter) WritePoints(_ context.Context, org, bucket platform.ID, points []models.Point) error {
+	if org != orgID || bucket != bucketID {
+		panic("unexpected bucket")
+	}
+	w.points <- points
+	return nil
+}
+
+// wait returns the sorted string representations of the next n written points.
+func (w *pointsWriter) wait(t *testing.T, n int) []string {
+	t.Helper()
+	var got []string
+	for len(got) < n {
+		select {
+		case points := <-w.points:
+			for _, p := range points {
+				got = append(got, p.String())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for points, got %v", got)
+		}
+	}
+	sort.Strings(got)
+	return got
+}
+
+type bucketService struct{}
+
+func (*bucketService) FindBucketByID(_ context.Context, id platform.ID) (*influxdb.Bucket, error) {
+	return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+}